@@ -9,10 +9,13 @@ import (
 	"syscall"
 	"time"
 
+	"libro-reclamaciones/internal/cifrado"
 	"libro-reclamaciones/internal/config"
 	"libro-reclamaciones/internal/db"
 	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/repo"
 	"libro-reclamaciones/internal/router"
+	"libro-reclamaciones/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -33,6 +36,24 @@ func main() {
 	log.Printf("✓ CockroachDB conectado: %s:%s/%s",
 		cfg.Cockroach.Host, cfg.Cockroach.Port, cfg.Cockroach.DBName)
 
+	// 2b. Storage de adjuntos (disco local o S3-compatible)
+	almacenamiento, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Error inicializando storage de adjuntos: %v", err)
+	}
+	log.Printf("✓ Storage de adjuntos: %s", cfg.Storage.Driver)
+
+	// 2c. Cifrado de secretos de tenants (tokens de WhatsApp, integraciones)
+	secretos, err := cifrado.New(cfg.Cifrado, repo.NewClaveDatosRepo(cockroach.DB()))
+	if err != nil {
+		log.Fatalf("Error inicializando cifrado de secretos: %v", err)
+	}
+	if secretos != nil {
+		log.Printf("✓ Secretos de tenants cifrados (clave maestra v%d)", secretos.VersionMaestra())
+	} else {
+		log.Println("[WARN] SECRETS_MASTER_KEY vacío: los secretos de tenants se guardan en claro")
+	}
+
 	// 3. Configurar Gin
 	if !cfg.Server.IsDevelopment() {
 		gin.SetMode(gin.ReleaseMode)
@@ -58,7 +79,14 @@ func main() {
 	})
 
 	// 6. Registrar rutas
-	router.RegisterRoutes(r, cfg, cockroach.DB())
+	sched := router.RegisterRoutes(r, cfg, cockroach.DB(), almacenamiento, secretos)
+
+	// 6b. Scheduler de jobs periódicos
+	if cfg.Scheduler.Enabled {
+		sched.Start(context.Background())
+	} else {
+		log.Println("Scheduler desactivado (SCHEDULER_ENABLED=false)")
+	}
 
 	// 7. Iniciar servidor con graceful shutdown
 	srv := &http.Server{
@@ -88,5 +116,6 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Error apagando servidor: %v", err)
 	}
+	sched.Stop()
 	log.Println("✓ Servidor apagado correctamente")
 }
\ No newline at end of file
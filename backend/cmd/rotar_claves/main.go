@@ -0,0 +1,125 @@
+// rotar_claves rota las claves del cifrado de secretos de tenants y deja
+// todos los secretos cifrados con la clave de datos activa de su tenant.
+//
+//	go run ./cmd/rotar_claves                       # solo cifra lo que sigue en claro
+//	go run ./cmd/rotar_claves -maestra              # re-envuelve las DEK con SECRETS_MASTER_KEY
+//	go run ./cmd/rotar_claves -datos                # DEK nueva para cada tenant con secretos
+//	go run ./cmd/rotar_claves -datos -tenant <uuid> # DEK nueva para un tenant
+//
+// Al terminar -maestra, la clave anterior puede salir de
+// SECRETS_PREVIOUS_MASTER_KEYS. Sale con código 1 si algún tenant falla.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"libro-reclamaciones/internal/cifrado"
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/db"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	maestraFlag := flag.Bool("maestra", false, "re-envolver las claves de datos con la clave maestra activa")
+	datosFlag := flag.Bool("datos", false, "crear una clave de datos nueva por tenant y re-cifrar sus secretos")
+	tenantFlag := flag.String("tenant", "", "tenant_id a procesar (vacío = todos)")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error cargando configuración: %v", err)
+	}
+
+	cockroach, err := db.NewCockroachDB(cfg.Cockroach)
+	if err != nil {
+		log.Fatalf("Error conectando a CockroachDB: %v", err)
+	}
+	defer cockroach.Close()
+
+	secretos, err := cifrado.New(cfg.Cifrado, repo.NewClaveDatosRepo(cockroach.DB()))
+	if err != nil {
+		log.Fatalf("Error inicializando cifrado: %v", err)
+	}
+	if secretos == nil {
+		log.Fatalf("SECRETS_MASTER_KEY no configurada")
+	}
+	ctx := context.Background()
+
+	if *maestraFlag {
+		n, err := secretos.RotarMaestra(ctx)
+		if err != nil {
+			log.Fatalf("Error rotando clave maestra (%d re-envueltas): %v", n, err)
+		}
+		fmt.Printf("✓ %d claves de datos re-envueltas con la clave maestra v%d\n", n, secretos.VersionMaestra())
+	}
+
+	// Todo repo que guarde secretos de tenants se registra aquí
+	recifradores := map[string]cifrado.Recifrador{
+		"canales_whatsapp": repo.NewCanalWhatsAppRepo(cockroach.DB(), secretos),
+	}
+
+	tenants, err := tenantsAProcesar(ctx, recifradores, *tenantFlag)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	fallidos := 0
+	for _, tenantID := range tenants {
+		if *datosFlag {
+			version, err := secretos.RotarClaveDatos(ctx, tenantID)
+			if err != nil {
+				fallidos++
+				fmt.Printf("✗ %s rotando clave de datos: %v\n", tenantID, err)
+				continue
+			}
+			fmt.Printf("✓ %s clave de datos v%d\n", tenantID, version)
+		}
+		for nombre, r := range recifradores {
+			n, err := r.RecifrarSecretos(ctx, tenantID)
+			if err != nil {
+				fallidos++
+				fmt.Printf("✗ %s %s: %v\n", tenantID, nombre, err)
+				continue
+			}
+			fmt.Printf("✓ %s %-18s %d registros re-cifrados\n", tenantID, nombre, n)
+		}
+	}
+
+	fmt.Printf("\n%d tenants procesados, %d errores\n", len(tenants), fallidos)
+	if fallidos > 0 {
+		os.Exit(1)
+	}
+}
+
+// tenantsAProcesar une los tenants con secretos de todos los repos, o solo el indicado.
+func tenantsAProcesar(ctx context.Context, recifradores map[string]cifrado.Recifrador, tenant string) ([]uuid.UUID, error) {
+	if tenant != "" {
+		id, err := uuid.Parse(tenant)
+		if err != nil {
+			return nil, fmt.Errorf("tenant_id inválido: %w", err)
+		}
+		return []uuid.UUID{id}, nil
+	}
+
+	vistos := map[uuid.UUID]bool{}
+	var tenants []uuid.UUID
+	for nombre, r := range recifradores {
+		ids, err := r.TenantsConSecretos(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listando tenants de %s: %w", nombre, err)
+		}
+		for _, id := range ids {
+			if !vistos[id] {
+				vistos[id] = true
+				tenants = append(tenants, id)
+			}
+		}
+	}
+	return tenants, nil
+}
@@ -0,0 +1,84 @@
+// verificar_cadena recorre la cadena de hash de reclamos e historial y reporta
+// el primer eslabón roto de cada tenant.
+//
+//	go run ./cmd/verificar_cadena                 # todos los tenants
+//	go run ./cmd/verificar_cadena -tenant <uuid>  # un tenant
+//
+// Sale con código 1 si alguna cadena está rota.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/db"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/google/uuid"
+)
+
+func main() {
+	tenantFlag := flag.String("tenant", "", "tenant_id a verificar (vacío = todos)")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error cargando configuración: %v", err)
+	}
+
+	cockroach, err := db.NewCockroachDB(cfg.Cockroach)
+	if err != nil {
+		log.Fatalf("Error conectando a CockroachDB: %v", err)
+	}
+	defer cockroach.Close()
+
+	svc := service.NewIntegridadService(repo.NewIntegridadRepo(cockroach.DB()))
+	ctx := context.Background()
+
+	var resultados []model.VerificacionIntegridad
+	if *tenantFlag != "" {
+		tenantID, err := uuid.Parse(*tenantFlag)
+		if err != nil {
+			log.Fatalf("tenant_id inválido: %v", err)
+		}
+		v, err := svc.Verificar(ctx, tenantID)
+		if err != nil {
+			log.Fatalf("Error verificando: %v", err)
+		}
+		resultados = append(resultados, *v)
+	} else {
+		resultados, err = svc.VerificarTodos(ctx)
+		if err != nil {
+			log.Fatalf("Error verificando: %v", err)
+		}
+	}
+
+	rotas := 0
+	for _, v := range resultados {
+		if !v.Integra {
+			rotas++
+		}
+		imprimirCadena(v.TenantID, v.Reclamos)
+		imprimirCadena(v.TenantID, v.Historial)
+	}
+
+	fmt.Printf("\n%d tenants verificados, %d con cadena rota\n", len(resultados), rotas)
+	if rotas > 0 {
+		os.Exit(1)
+	}
+}
+
+func imprimirCadena(tenantID uuid.UUID, c model.VerificacionCadena) {
+	if c.Integra {
+		fmt.Printf("✓ %s %-18s %d eslabones (%d sin cadena)\n", tenantID, c.Cadena, c.Eslabones, c.SinCadena)
+		return
+	}
+	fmt.Printf("✗ %s %-18s quiebre en secuencia %d (id %s): %s — %d eslabones válidos antes\n",
+		tenantID, c.Cadena, c.Quiebre.Secuencia, c.Quiebre.ID, c.Quiebre.Motivo, c.Eslabones)
+}
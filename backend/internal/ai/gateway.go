@@ -15,19 +15,28 @@ import (
 // Interfaz común para cualquier proveedor de IA
 // ──────────────────────────────────────────────
 
+// Message mensaje de la conversación. Role es "user", "assistant" o "tool".
+// En una ronda de herramientas el mensaje "assistant" lleva las invocaciones
+// en ToolCalls y cada resultado vuelve como un mensaje "tool" con el ID y el
+// nombre de la invocación a la que responde.
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string     `json:"tool_call_id,omitempty"`
+	ToolName   string     `json:"tool_name,omitempty"`
 }
 
 type ChatRequest struct {
 	SystemPrompt string
 	Messages     []Message
 	MaxTokens    int
+	Tools        []Tool // opcional: herramientas que el modelo puede invocar
 }
 
 type ChatResponse struct {
 	Content      string
+	ToolCalls    []ToolCall // invocaciones pedidas por el modelo (vacío si solo respondió texto)
 	PromptTokens int
 	OutputTokens int
 	Provider     string
@@ -35,6 +44,11 @@ type ChatResponse struct {
 
 type Provider interface {
 	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
+	// ChatStream genera la respuesta incrementalmente: invoca onDelta con cada
+	// fragmento de texto y retorna la respuesta completa con el conteo de tokens.
+	// Si onDelta retorna error se corta la generación. Ante un error a mitad de
+	// camino retorna lo generado hasta ese punto junto con el error.
+	ChatStream(ctx context.Context, req ChatRequest, onDelta func(texto string) error) (*ChatResponse, error)
 	Name() string
 }
 
@@ -175,27 +189,24 @@ type OllamaProvider struct {
 
 func (p *OllamaProvider) Name() string { return "ollama/" + p.model }
 
-func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
-	msgs := make([]map[string]string, 0, len(req.Messages)+1)
-	if req.SystemPrompt != "" {
-		msgs = append(msgs, map[string]string{
-			"role":    "system",
-			"content": req.SystemPrompt,
-		})
-	}
-	for _, m := range req.Messages {
-		msgs = append(msgs, map[string]string{
-			"role":    m.Role,
-			"content": m.Content,
-		})
-	}
+// cuerpo arma el request de /api/chat.
+func (p *OllamaProvider) cuerpo(req ChatRequest) map[string]interface{} {
+	msgs := mensajesFormatoOpenAI(req.SystemPrompt, req.Messages, false)
 
 	body := map[string]interface{}{
 		"model":    p.model,
 		"messages": msgs,
 		"stream":   false,
 	}
+	if len(req.Tools) > 0 {
+		body["tools"] = toolsFormatoOpenAI(req.Tools)
+	}
 
+	return body
+}
+
+func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	body := p.cuerpo(req)
 	jsonBody, _ := json.Marshal(body)
 
 	url := p.baseURL + "/api/chat"
@@ -220,8 +231,14 @@ func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 
 	var result struct {
 		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
+			Role      string `json:"role"`
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Function struct {
+					Name      string          `json:"name"`
+					Arguments json.RawMessage `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
 		} `json:"message"`
 		PromptEvalCount int `json:"prompt_eval_count"`
 		EvalCount       int `json:"eval_count"`
@@ -230,8 +247,18 @@ func (p *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		return nil, fmt.Errorf("ollama: parse error: %w", err)
 	}
 
+	var toolCalls []ToolCall
+	for i, tc := range result.Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        fmt.Sprintf("ollama-%d", i),
+			Name:      tc.Function.Name,
+			Arguments: tc.Function.Arguments,
+		})
+	}
+
 	return &ChatResponse{
 		Content:      result.Message.Content,
+		ToolCalls:    toolCalls,
 		PromptTokens: result.PromptEvalCount,
 		OutputTokens: result.EvalCount,
 		Provider:     "ollama/" + p.model,
@@ -249,22 +276,14 @@ type AnthropicProvider struct {
 
 func (p *AnthropicProvider) Name() string { return "anthropic" }
 
-func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+// cuerpo arma el request de /v1/messages.
+func (p *AnthropicProvider) cuerpo(req ChatRequest) map[string]interface{} {
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = 4096
 	}
 
-	msgs := make([]map[string]string, 0, len(req.Messages))
-	for _, m := range req.Messages {
-		if m.Role == "system" {
-			continue
-		}
-		msgs = append(msgs, map[string]string{
-			"role":    m.Role,
-			"content": m.Content,
-		})
-	}
+	msgs := mensajesFormatoAnthropic(req.Messages)
 
 	body := map[string]interface{}{
 		"model":      p.model,
@@ -274,7 +293,23 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRes
 	if req.SystemPrompt != "" {
 		body["system"] = req.SystemPrompt
 	}
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]interface{}, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			tools = append(tools, map[string]interface{}{
+				"name":         t.Name,
+				"description":  t.Description,
+				"input_schema": schemaParametros(t),
+			})
+		}
+		body["tools"] = tools
+	}
+
+	return body
+}
 
+func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	body := p.cuerpo(req)
 	jsonBody, _ := json.Marshal(body)
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(jsonBody))
@@ -300,8 +335,11 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRes
 
 	var result struct {
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			ID    string          `json:"id"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
 		} `json:"content"`
 		Usage struct {
 			InputTokens  int `json:"input_tokens"`
@@ -313,14 +351,19 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRes
 	}
 
 	text := ""
+	var toolCalls []ToolCall
 	for _, c := range result.Content {
-		if c.Type == "text" {
+		switch c.Type {
+		case "text":
 			text += c.Text
+		case "tool_use":
+			toolCalls = append(toolCalls, ToolCall{ID: c.ID, Name: c.Name, Arguments: c.Input})
 		}
 	}
 
 	return &ChatResponse{
 		Content:      text,
+		ToolCalls:    toolCalls,
 		PromptTokens: result.Usage.InputTokens,
 		OutputTokens: result.Usage.OutputTokens,
 		Provider:     "anthropic",
@@ -344,32 +387,29 @@ func (p *OpenAIProvider) Name() string {
 	return "openai/" + p.model
 }
 
-func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+// cuerpo arma el request de /chat/completions.
+func (p *OpenAIProvider) cuerpo(req ChatRequest) map[string]interface{} {
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = 4096
 	}
 
-	msgs := make([]map[string]string, 0, len(req.Messages)+1)
-	if req.SystemPrompt != "" {
-		msgs = append(msgs, map[string]string{
-			"role":    "system",
-			"content": req.SystemPrompt,
-		})
-	}
-	for _, m := range req.Messages {
-		msgs = append(msgs, map[string]string{
-			"role":    m.Role,
-			"content": m.Content,
-		})
-	}
+	msgs := mensajesFormatoOpenAI(req.SystemPrompt, req.Messages, true)
 
 	body := map[string]interface{}{
 		"model":      p.model,
 		"max_tokens": maxTokens,
 		"messages":   msgs,
 	}
+	if len(req.Tools) > 0 {
+		body["tools"] = toolsFormatoOpenAI(req.Tools)
+	}
+
+	return body
+}
 
+func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	body := p.cuerpo(req)
 	jsonBody, _ := json.Marshal(body)
 
 	baseURL := p.baseURL
@@ -401,7 +441,14 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	var result struct {
 		Choices []struct {
 			Message struct {
-				Content string `json:"content"`
+				Content   string `json:"content"`
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"` // JSON serializado como texto
+					} `json:"function"`
+				} `json:"tool_calls"`
 			} `json:"message"`
 		} `json:"choices"`
 		Usage struct {
@@ -414,12 +461,21 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	}
 
 	text := ""
+	var toolCalls []ToolCall
 	if len(result.Choices) > 0 {
 		text = result.Choices[0].Message.Content
+		for _, tc := range result.Choices[0].Message.ToolCalls {
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        tc.ID,
+				Name:      tc.Function.Name,
+				Arguments: json.RawMessage(tc.Function.Arguments),
+			})
+		}
 	}
 
 	return &ChatResponse{
 		Content:      text,
+		ToolCalls:    toolCalls,
 		PromptTokens: result.Usage.PromptTokens,
 		OutputTokens: result.Usage.CompletionTokens,
 		Provider:     p.Name(),
@@ -437,26 +493,14 @@ type GoogleProvider struct {
 
 func (p *GoogleProvider) Name() string { return "google/" + p.model }
 
-func (p *GoogleProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+// cuerpo arma el request de generateContent.
+func (p *GoogleProvider) cuerpo(req ChatRequest) map[string]interface{} {
 	maxTokens := req.MaxTokens
 	if maxTokens == 0 {
 		maxTokens = 4096
 	}
 
-	contents := make([]map[string]interface{}, 0)
-
-	for _, m := range req.Messages {
-		role := m.Role
-		if role == "assistant" {
-			role = "model"
-		}
-		contents = append(contents, map[string]interface{}{
-			"role": role,
-			"parts": []map[string]string{
-				{"text": m.Content},
-			},
-		})
-	}
+	contents := contenidosFormatoGoogle(req.Messages)
 
 	body := map[string]interface{}{
 		"contents": contents,
@@ -472,7 +516,25 @@ func (p *GoogleProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 			},
 		}
 	}
+	if len(req.Tools) > 0 {
+		declaraciones := make([]map[string]interface{}, 0, len(req.Tools))
+		for _, t := range req.Tools {
+			declaraciones = append(declaraciones, map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  schemaParametros(t),
+			})
+		}
+		body["tools"] = []map[string]interface{}{
+			{"functionDeclarations": declaraciones},
+		}
+	}
 
+	return body
+}
+
+func (p *GoogleProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	body := p.cuerpo(req)
 	jsonBody, _ := json.Marshal(body)
 
 	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", p.model, p.apiKey)
@@ -500,7 +562,11 @@ func (p *GoogleProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		Candidates []struct {
 			Content struct {
 				Parts []struct {
-					Text string `json:"text"`
+					Text         string `json:"text"`
+					FunctionCall *struct {
+						Name string          `json:"name"`
+						Args json.RawMessage `json:"args"`
+					} `json:"functionCall"`
 				} `json:"parts"`
 			} `json:"content"`
 		} `json:"candidates"`
@@ -514,14 +580,41 @@ func (p *GoogleProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	}
 
 	text := ""
-	if len(result.Candidates) > 0 && len(result.Candidates[0].Content.Parts) > 0 {
-		text = result.Candidates[0].Content.Parts[0].Text
+	var toolCalls []ToolCall
+	if len(result.Candidates) > 0 {
+		for i, part := range result.Candidates[0].Content.Parts {
+			text += part.Text
+			if part.FunctionCall != nil {
+				toolCalls = append(toolCalls, ToolCall{
+					ID:        fmt.Sprintf("google-%d", i),
+					Name:      part.FunctionCall.Name,
+					Arguments: part.FunctionCall.Args,
+				})
+			}
+		}
 	}
 
 	return &ChatResponse{
 		Content:      text,
+		ToolCalls:    toolCalls,
 		PromptTokens: result.UsageMetadata.PromptTokenCount,
 		OutputTokens: result.UsageMetadata.CandidatesTokenCount,
 		Provider:     "google/" + p.model,
 	}, nil
-}
\ No newline at end of file
+}
+
+// toolsFormatoOpenAI herramientas en el formato de OpenAI (también lo usa Ollama).
+func toolsFormatoOpenAI(tools []Tool) []map[string]interface{} {
+	res := make([]map[string]interface{}, 0, len(tools))
+	for _, t := range tools {
+		res = append(res, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  schemaParametros(t),
+			},
+		})
+	}
+	return res
+}
@@ -0,0 +1,425 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ──────────────────────────────────────────────
+// Streaming — respuestas incrementales por proveedor
+// ──────────────────────────────────────────────
+// Las invocaciones de herramientas llegan fragmentadas (OpenAI, Anthropic)
+// o completas (Ollama, Google); se acumulan y se entregan en ToolCalls al
+// terminar el stream. Solo el texto se emite por onDelta.
+//
+// El cliente HTTP no tiene timeout propio; la duración la controla el ctx
+// del llamador (un timeout fijo cortaría respuestas largas a la mitad).
+
+var clienteStream = &http.Client{}
+
+// tamanoMaxLinea límite de una línea SSE/NDJSON (los fragmentos son chicos,
+// pero el último evento puede traer metadatos de uso).
+const tamanoMaxLinea = 1 << 20
+
+// postStream envía el request y retorna el body abierto si la respuesta es 200.
+func postStream(ctx context.Context, proveedor, url string, body map[string]interface{}, headers map[string]string) (io.ReadCloser, error) {
+	jsonBody, _ := json.Marshal(body)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", proveedor, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := clienteStream.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: request failed: %w", proveedor, err)
+	}
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: HTTP %d: %s", proveedor, resp.StatusCode, string(respBody))
+	}
+	return resp.Body, nil
+}
+
+// leerLineas invoca fn con cada línea no vacía del body.
+func leerLineas(body io.Reader, fn func(linea []byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), tamanoMaxLinea)
+	for scanner.Scan() {
+		linea := bytes.TrimSpace(scanner.Bytes())
+		if len(linea) == 0 {
+			continue
+		}
+		if err := fn(linea); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// leerSSE invoca fn con el payload de cada línea "data:" de un stream SSE.
+// Termina al recibir "[DONE]" (OpenAI).
+func leerSSE(body io.Reader, fn func(data []byte) error) error {
+	errFin := errors.New("fin")
+	err := leerLineas(body, func(linea []byte) error {
+		if !bytes.HasPrefix(linea, []byte("data:")) {
+			return nil // event:, id:, comentarios
+		}
+		data := bytes.TrimSpace(linea[len("data:"):])
+		if string(data) == "[DONE]" {
+			return errFin
+		}
+		return fn(data)
+	})
+	if errors.Is(err, errFin) {
+		return nil
+	}
+	return err
+}
+
+// ── Ollama: NDJSON, una línea por fragmento ──
+
+func (p *OllamaProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(texto string) error) (*ChatResponse, error) {
+	body := p.cuerpo(req)
+	body["stream"] = true
+
+	stream, err := postStream(ctx, "ollama", p.baseURL+"/api/chat", body, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	res := &ChatResponse{Provider: "ollama/" + p.model}
+	var texto strings.Builder
+	err = leerLineas(stream, func(linea []byte) error {
+		var chunk struct {
+			Message struct {
+				Content   string `json:"content"`
+				ToolCalls []struct {
+					Function struct {
+						Name      string          `json:"name"`
+						Arguments json.RawMessage `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+			Done            bool   `json:"done"`
+			Error           string `json:"error"`
+			PromptEvalCount int    `json:"prompt_eval_count"`
+			EvalCount       int    `json:"eval_count"`
+		}
+		if err := json.Unmarshal(linea, &chunk); err != nil {
+			return fmt.Errorf("ollama: parse error: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if chunk.Done {
+			res.PromptTokens = chunk.PromptEvalCount
+			res.OutputTokens = chunk.EvalCount
+		}
+		for _, tc := range chunk.Message.ToolCalls {
+			res.ToolCalls = append(res.ToolCalls, ToolCall{
+				ID:        fmt.Sprintf("ollama-%d", len(res.ToolCalls)),
+				Name:      tc.Function.Name,
+				Arguments: tc.Function.Arguments,
+			})
+		}
+		return emitir(&texto, chunk.Message.Content, onDelta)
+	})
+	res.Content = texto.String()
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// ── Anthropic: SSE con eventos message_start / content_block_delta / message_delta ──
+
+func (p *AnthropicProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(texto string) error) (*ChatResponse, error) {
+	body := p.cuerpo(req)
+	body["stream"] = true
+
+	stream, err := postStream(ctx, "anthropic", "https://api.anthropic.com/v1/messages", body, map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": "2023-06-01",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	res := &ChatResponse{Provider: "anthropic"}
+	var texto strings.Builder
+	var tools acumuladorTools
+	err = leerSSE(stream, func(data []byte) error {
+		var evento struct {
+			Type    string `json:"type"`
+			Index   int    `json:"index"`
+			Message struct {
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			ContentBlock struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"content_block"`
+			Delta struct {
+				Type        string `json:"type"`
+				Text        string `json:"text"`
+				PartialJSON string `json:"partial_json"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(data, &evento); err != nil {
+			return fmt.Errorf("anthropic: parse error: %w", err)
+		}
+		switch evento.Type {
+		case "message_start":
+			res.PromptTokens = evento.Message.Usage.InputTokens
+		case "content_block_start":
+			if evento.ContentBlock.Type == "tool_use" {
+				tools.agregar(evento.Index, evento.ContentBlock.ID, evento.ContentBlock.Name, "")
+			}
+		case "content_block_delta":
+			switch evento.Delta.Type {
+			case "text_delta":
+				return emitir(&texto, evento.Delta.Text, onDelta)
+			case "input_json_delta":
+				tools.agregar(evento.Index, "", "", evento.Delta.PartialJSON)
+			}
+		case "message_delta":
+			res.OutputTokens = evento.Usage.OutputTokens
+		case "error":
+			return fmt.Errorf("anthropic: %s", evento.Error.Message)
+		}
+		return nil
+	})
+	res.Content = texto.String()
+	res.ToolCalls = tools.llamadas()
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// ── OpenAI (y compatibles): SSE con choices[].delta, uso en el último chunk ──
+
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(texto string) error) (*ChatResponse, error) {
+	body := p.cuerpo(req)
+	body["stream"] = true
+	body["stream_options"] = map[string]interface{}{"include_usage": true}
+
+	baseURL := p.baseURL
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+
+	stream, err := postStream(ctx, "openai", baseURL+"/chat/completions", body, map[string]string{
+		"Authorization": "Bearer " + p.apiKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	res := &ChatResponse{Provider: p.Name()}
+	var texto strings.Builder
+	var tools acumuladorTools
+	err = leerSSE(stream, func(data []byte) error {
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content   string `json:"content"`
+					ToolCalls []struct {
+						Index    int    `json:"index"`
+						ID       string `json:"id"`
+						Function struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+			Usage *struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("openai: parse error: %w", err)
+		}
+		if chunk.Usage != nil {
+			res.PromptTokens = chunk.Usage.PromptTokens
+			res.OutputTokens = chunk.Usage.CompletionTokens
+		}
+		if len(chunk.Choices) > 0 {
+			for _, tc := range chunk.Choices[0].Delta.ToolCalls {
+				tools.agregar(tc.Index, tc.ID, tc.Function.Name, tc.Function.Arguments)
+			}
+			return emitir(&texto, chunk.Choices[0].Delta.Content, onDelta)
+		}
+		return nil
+	})
+	res.Content = texto.String()
+	res.ToolCalls = tools.llamadas()
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// ── Google: streamGenerateContent con alt=sse ──
+
+func (p *GoogleProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(texto string) error) (*ChatResponse, error) {
+	body := p.cuerpo(req)
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", p.model, p.apiKey)
+	stream, err := postStream(ctx, "google", url, body, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	res := &ChatResponse{Provider: "google/" + p.model}
+	var texto strings.Builder
+	err = leerSSE(stream, func(data []byte) error {
+		var chunk struct {
+			Candidates []struct {
+				Content struct {
+					Parts []struct {
+						Text         string `json:"text"`
+						FunctionCall *struct {
+							Name string          `json:"name"`
+							Args json.RawMessage `json:"args"`
+						} `json:"functionCall"`
+					} `json:"parts"`
+				} `json:"content"`
+			} `json:"candidates"`
+			UsageMetadata struct {
+				PromptTokenCount     int `json:"promptTokenCount"`
+				CandidatesTokenCount int `json:"candidatesTokenCount"`
+			} `json:"usageMetadata"`
+		}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("google: parse error: %w", err)
+		}
+		// El uso es acumulado: el último chunk trae el total
+		if chunk.UsageMetadata.PromptTokenCount > 0 {
+			res.PromptTokens = chunk.UsageMetadata.PromptTokenCount
+			res.OutputTokens = chunk.UsageMetadata.CandidatesTokenCount
+		}
+		if len(chunk.Candidates) > 0 {
+			for _, part := range chunk.Candidates[0].Content.Parts {
+				if part.FunctionCall != nil {
+					res.ToolCalls = append(res.ToolCalls, ToolCall{
+						ID:        fmt.Sprintf("google-%d", len(res.ToolCalls)),
+						Name:      part.FunctionCall.Name,
+						Arguments: part.FunctionCall.Args,
+					})
+				}
+				if err := emitir(&texto, part.Text, onDelta); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	res.Content = texto.String()
+	if err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+// ── Fallback: solo cambia de proveedor si el primario falla antes de emitir ──
+
+func (p *FallbackProvider) ChatStream(ctx context.Context, req ChatRequest, onDelta func(texto string) error) (*ChatResponse, error) {
+	emitido := false
+	resp, err := p.primary.ChatStream(ctx, req, func(texto string) error {
+		emitido = true
+		return onDelta(texto)
+	})
+	if err == nil || emitido || ctx.Err() != nil {
+		return resp, err
+	}
+
+	log.Printf("[WARN] ai: stream del provider primario '%s' falló: %v — intentando fallback '%s'",
+		p.primary.Name(), err, p.fallback.Name())
+
+	fbResp, fbErr := p.fallback.ChatStream(ctx, req, onDelta)
+	if fbErr != nil {
+		return fbResp, fmt.Errorf("ai: ambos proveedores fallaron.\n  Primario (%s): %v\n  Fallback (%s): %v",
+			p.primary.Name(), err, p.fallback.Name(), fbErr)
+	}
+	return fbResp, nil
+}
+
+// emitir acumula el fragmento y lo entrega al llamador (ignora fragmentos vacíos).
+func emitir(texto *strings.Builder, fragmento string, onDelta func(string) error) error {
+	if fragmento == "" {
+		return nil
+	}
+	texto.WriteString(fragmento)
+	return onDelta(fragmento)
+}
+
+// acumuladorTools arma las invocaciones que llegan fragmentadas por índice:
+// el primer fragmento trae id y nombre, los siguientes pedazos de argumentos.
+type acumuladorTools struct {
+	orden []int
+	tools map[int]*ToolCall
+	args  map[int]*strings.Builder
+}
+
+func (a *acumuladorTools) agregar(indice int, id, nombre, fragmentoArgs string) {
+	if a.tools == nil {
+		a.tools = make(map[int]*ToolCall)
+		a.args = make(map[int]*strings.Builder)
+	}
+	tc, existe := a.tools[indice]
+	if !existe {
+		tc = &ToolCall{}
+		a.tools[indice] = tc
+		a.args[indice] = &strings.Builder{}
+		a.orden = append(a.orden, indice)
+	}
+	if id != "" {
+		tc.ID = id
+	}
+	if nombre != "" {
+		tc.Name = nombre
+	}
+	a.args[indice].WriteString(fragmentoArgs)
+}
+
+func (a *acumuladorTools) llamadas() []ToolCall {
+	var res []ToolCall
+	for _, i := range a.orden {
+		tc := *a.tools[i]
+		if args := a.args[i].String(); args != "" {
+			tc.Arguments = json.RawMessage(args)
+		}
+		res = append(res, tc)
+	}
+	return res
+}
@@ -0,0 +1,351 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ──────────────────────────────────────────────
+// Tool calling — herramientas que el modelo puede invocar
+// ──────────────────────────────────────────────
+
+// Tool herramienta ofrecida al modelo. Parameters describe los argumentos
+// con el subconjunto de JSON Schema que entienden los cuatro proveedores.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  *Schema
+}
+
+// ToolCall invocación de una herramienta devuelta por el modelo.
+// Arguments es el objeto JSON tal cual lo generó el modelo (sin validar).
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments json.RawMessage
+}
+
+// Schema subconjunto de JSON Schema: objetos planos con propiedades string,
+// integer, number o boolean, enum y límites de longitud/patrón para strings.
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+}
+
+// BuscarTool retorna la herramienta con ese nombre, o nil.
+func BuscarTool(tools []Tool, nombre string) *Tool {
+	for i := range tools {
+		if tools[i].Name == nombre {
+			return &tools[i]
+		}
+	}
+	return nil
+}
+
+// Decodificar valida los argumentos contra el schema de la herramienta y los
+// deserializa en dst. Los errores describen el campo para poder registrarlos.
+func (t *Tool) Decodificar(args json.RawMessage, dst interface{}) error {
+	if t.Parameters != nil {
+		if err := t.Parameters.Validar(args); err != nil {
+			return fmt.Errorf("ai: argumentos de %s: %w", t.Name, err)
+		}
+	}
+	if err := json.Unmarshal(args, dst); err != nil {
+		return fmt.Errorf("ai: argumentos de %s: %w", t.Name, err)
+	}
+	return nil
+}
+
+// DecodificarRespuesta toma los argumentos de la invocación de t en resp o,
+// si el modelo respondió en texto, el objeto JSON contenido en él.
+func (t *Tool) DecodificarRespuesta(resp *ChatResponse, dst interface{}) error {
+	for _, tc := range resp.ToolCalls {
+		if tc.Name == t.Name {
+			return t.Decodificar(tc.Arguments, dst)
+		}
+	}
+
+	inicio, fin := strings.Index(resp.Content, "{"), strings.LastIndex(resp.Content, "}")
+	if inicio < 0 || fin < inicio {
+		return fmt.Errorf("ai: la respuesta no invoca %s ni contiene un objeto JSON", t.Name)
+	}
+	return t.Decodificar(json.RawMessage(resp.Content[inicio:fin+1]), dst)
+}
+
+// Validar comprueba que data sea un objeto JSON que cumpla el schema.
+func (s *Schema) Validar(data json.RawMessage) error {
+	if len(data) == 0 {
+		data = json.RawMessage(`{}`)
+	}
+	var valor interface{}
+	if err := json.Unmarshal(data, &valor); err != nil {
+		return fmt.Errorf("JSON inválido: %w", err)
+	}
+	return s.validarValor("", valor)
+}
+
+func (s *Schema) validarValor(campo string, valor interface{}) error {
+	nombre := campo
+	if nombre == "" {
+		nombre = "raíz"
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := valor.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: se esperaba un objeto", nombre)
+		}
+		for _, req := range s.Required {
+			if v, existe := obj[req]; !existe || v == nil {
+				return fmt.Errorf("%s: campo requerido", prefijar(campo, req))
+			}
+		}
+		claves := make([]string, 0, len(obj))
+		for k := range obj {
+			claves = append(claves, k)
+		}
+		sort.Strings(claves)
+		for _, k := range claves {
+			prop, existe := s.Properties[k]
+			if !existe {
+				return fmt.Errorf("%s: campo no permitido", prefijar(campo, k))
+			}
+			if obj[k] == nil {
+				continue
+			}
+			if err := prop.validarValor(prefijar(campo, k), obj[k]); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := valor.(string)
+		if !ok {
+			return fmt.Errorf("%s: se esperaba un texto", nombre)
+		}
+		n := utf8.RuneCountInString(str)
+		if s.MinLength != nil && n < *s.MinLength {
+			return fmt.Errorf("%s: mínimo %d caracteres", nombre, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			return fmt.Errorf("%s: máximo %d caracteres", nombre, *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return fmt.Errorf("%s: patrón inválido en el schema: %w", nombre, err)
+			}
+			if !re.MatchString(str) {
+				return fmt.Errorf("%s: formato inválido", nombre)
+			}
+		}
+		if len(s.Enum) > 0 {
+			for _, e := range s.Enum {
+				if str == e {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s: valor %q no permitido", nombre, str)
+		}
+
+	case "integer":
+		num, ok := valor.(float64)
+		if !ok || num != float64(int64(num)) {
+			return fmt.Errorf("%s: se esperaba un entero", nombre)
+		}
+
+	case "number":
+		if _, ok := valor.(float64); !ok {
+			return fmt.Errorf("%s: se esperaba un número", nombre)
+		}
+
+	case "boolean":
+		if _, ok := valor.(bool); !ok {
+			return fmt.Errorf("%s: se esperaba true/false", nombre)
+		}
+
+	default:
+		return fmt.Errorf("%s: tipo %q no soportado", nombre, s.Type)
+	}
+	return nil
+}
+
+func prefijar(campo, clave string) string {
+	if campo == "" {
+		return clave
+	}
+	return campo + "." + clave
+}
+
+// schemaParametros schema a enviar al proveedor; nunca nil (objeto vacío).
+func schemaParametros(t Tool) *Schema {
+	if t.Parameters == nil {
+		return &Schema{Type: "object", Properties: map[string]*Schema{}}
+	}
+	return t.Parameters
+}
+
+// ──────────────────────────────────────────────
+// Rondas de herramientas — formato de los mensajes por proveedor
+// ──────────────────────────────────────────────
+
+// argumentosObjeto los proveedores esperan un objeto aunque el modelo no
+// haya enviado argumentos.
+func argumentosObjeto(args json.RawMessage) json.RawMessage {
+	if len(args) == 0 {
+		return json.RawMessage(`{}`)
+	}
+	return args
+}
+
+// mensajesFormatoOpenAI mensajes para OpenAI y Ollama. OpenAI espera los
+// argumentos de cada invocación serializados como texto; Ollama, como objeto.
+func mensajesFormatoOpenAI(systemPrompt string, mensajes []Message, argumentosComoTexto bool) []map[string]interface{} {
+	msgs := make([]map[string]interface{}, 0, len(mensajes)+1)
+	if systemPrompt != "" {
+		msgs = append(msgs, map[string]interface{}{
+			"role":    "system",
+			"content": systemPrompt,
+		})
+	}
+	for _, m := range mensajes {
+		msg := map[string]interface{}{
+			"role":    m.Role,
+			"content": m.Content,
+		}
+		if m.Role == "tool" {
+			msg["tool_call_id"] = m.ToolCallID
+			if !argumentosComoTexto {
+				msg["tool_name"] = m.ToolName // Ollama asocia el resultado por nombre
+			}
+		}
+		if len(m.ToolCalls) > 0 {
+			llamadas := make([]map[string]interface{}, 0, len(m.ToolCalls))
+			for _, tc := range m.ToolCalls {
+				var args interface{} = argumentosObjeto(tc.Arguments)
+				if argumentosComoTexto {
+					args = string(argumentosObjeto(tc.Arguments))
+				}
+				llamadas = append(llamadas, map[string]interface{}{
+					"id":   tc.ID,
+					"type": "function",
+					"function": map[string]interface{}{
+						"name":      tc.Name,
+						"arguments": args,
+					},
+				})
+			}
+			msg["tool_calls"] = llamadas
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// mensajesFormatoAnthropic las invocaciones van como bloques tool_use del
+// assistant y los resultados como bloques tool_result de un único mensaje
+// user (los resultados consecutivos se agrupan).
+func mensajesFormatoAnthropic(mensajes []Message) []map[string]interface{} {
+	msgs := make([]map[string]interface{}, 0, len(mensajes))
+	var resultados []map[string]interface{}
+
+	cerrarResultados := func() {
+		if len(resultados) > 0 {
+			msgs = append(msgs, map[string]interface{}{"role": "user", "content": resultados})
+			resultados = nil
+		}
+	}
+
+	for _, m := range mensajes {
+		switch {
+		case m.Role == "system":
+			continue
+		case m.Role == "tool":
+			resultados = append(resultados, map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": m.ToolCallID,
+				"content":     m.Content,
+			})
+			continue
+		}
+		cerrarResultados()
+
+		if len(m.ToolCalls) == 0 {
+			msgs = append(msgs, map[string]interface{}{"role": m.Role, "content": m.Content})
+			continue
+		}
+		bloques := make([]map[string]interface{}, 0, len(m.ToolCalls)+1)
+		if m.Content != "" {
+			bloques = append(bloques, map[string]interface{}{"type": "text", "text": m.Content})
+		}
+		for _, tc := range m.ToolCalls {
+			bloques = append(bloques, map[string]interface{}{
+				"type":  "tool_use",
+				"id":    tc.ID,
+				"name":  tc.Name,
+				"input": argumentosObjeto(tc.Arguments),
+			})
+		}
+		msgs = append(msgs, map[string]interface{}{"role": m.Role, "content": bloques})
+	}
+	cerrarResultados()
+	return msgs
+}
+
+// contenidosFormatoGoogle Gemini identifica las invocaciones por nombre:
+// functionCall en el turno del modelo y functionResponse en el siguiente.
+func contenidosFormatoGoogle(mensajes []Message) []map[string]interface{} {
+	contents := make([]map[string]interface{}, 0, len(mensajes))
+	var respuestas []map[string]interface{}
+
+	cerrarRespuestas := func() {
+		if len(respuestas) > 0 {
+			contents = append(contents, map[string]interface{}{"role": "user", "parts": respuestas})
+			respuestas = nil
+		}
+	}
+
+	for _, m := range mensajes {
+		if m.Role == "tool" {
+			respuestas = append(respuestas, map[string]interface{}{
+				"functionResponse": map[string]interface{}{
+					"name":     m.ToolName,
+					"response": map[string]interface{}{"resultado": m.Content},
+				},
+			})
+			continue
+		}
+		cerrarRespuestas()
+
+		role := m.Role
+		if role == "assistant" {
+			role = "model"
+		}
+		parts := make([]map[string]interface{}, 0, len(m.ToolCalls)+1)
+		if m.Content != "" || len(m.ToolCalls) == 0 {
+			parts = append(parts, map[string]interface{}{"text": m.Content})
+		}
+		for _, tc := range m.ToolCalls {
+			parts = append(parts, map[string]interface{}{
+				"functionCall": map[string]interface{}{
+					"name": tc.Name,
+					"args": argumentosObjeto(tc.Arguments),
+				},
+			})
+		}
+		contents = append(contents, map[string]interface{}{"role": role, "parts": parts})
+	}
+	cerrarRespuestas()
+	return contents
+}
@@ -0,0 +1,91 @@
+package ai
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAITool_Decodificar(t *testing.T) {
+	minimo := 3
+	tool := Tool{
+		Name: "solicitar_asesor",
+		Parameters: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"nombre":    {Type: "string", MinLength: &minimo},
+				"documento": {Type: "string", Enum: []string{"DNI", "CE"}},
+			},
+			Required: []string{"nombre"},
+		},
+	}
+
+	casos := []struct {
+		args  string
+		valid bool
+	}{
+		{`{"nombre":"Rosa","documento":"DNI"}`, true},
+		{`{"nombre":"Rosa"}`, true},
+		{`{"documento":"DNI"}`, false},               // falta requerido
+		{`{"nombre":"Ro"}`, false},                   // minLength
+		{`{"nombre":"Rosa","documento":"X"}`, false}, // fuera del enum
+		{`{"nombre":"Rosa","extra":1}`, false},       // campo no declarado
+		{`{"nombre":42}`, false},                     // tipo incorrecto
+		{`no es json`, false},
+	}
+
+	for _, c := range casos {
+		var dst struct {
+			Nombre string `json:"nombre"`
+		}
+		err := tool.Decodificar(json.RawMessage(c.args), &dst)
+		if (err == nil) != c.valid {
+			t.Errorf("Decodificar(%s): err = %v, want válido = %v", c.args, err, c.valid)
+		}
+	}
+}
+
+// TestAITool_DecodificarRespuesta prefiere la invocación de la herramienta y,
+// si el modelo respondió en texto, lee el objeto JSON del contenido.
+func TestAITool_DecodificarRespuesta(t *testing.T) {
+	tool := Tool{
+		Name: "clasificar_reclamo",
+		Parameters: &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{"urgencia": {Type: "string", Enum: []string{"BAJA", "ALTA"}}},
+			Required:   []string{"urgencia"},
+		},
+	}
+
+	casos := []struct {
+		nombre string
+		resp   ChatResponse
+		want   string
+	}{
+		{"tool call", ChatResponse{
+			Content:   `{"urgencia":"BAJA"}`,
+			ToolCalls: []ToolCall{{Name: "clasificar_reclamo", Arguments: json.RawMessage(`{"urgencia":"ALTA"}`)}},
+		}, "ALTA"},
+		{"JSON en texto", ChatResponse{Content: "Resultado:\n```json\n{\"urgencia\":\"BAJA\"}\n```"}, "BAJA"},
+		{"otra herramienta", ChatResponse{
+			ToolCalls: []ToolCall{{Name: "otra", Arguments: json.RawMessage(`{"urgencia":"ALTA"}`)}},
+		}, ""},
+		{"sin JSON", ChatResponse{Content: "No puedo clasificarlo"}, ""},
+		{"fuera del enum", ChatResponse{Content: `{"urgencia":"MEDIA"}`}, ""},
+	}
+
+	for _, c := range casos {
+		var dst struct {
+			Urgencia string `json:"urgencia"`
+		}
+		err := tool.DecodificarRespuesta(&c.resp, &dst)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("%s: err = nil, want error", c.nombre)
+			}
+			continue
+		}
+		if err != nil || dst.Urgencia != c.want {
+			t.Errorf("%s: urgencia = %q, err = %v, want %q", c.nombre, dst.Urgencia, err, c.want)
+		}
+	}
+}
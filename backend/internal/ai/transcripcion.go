@@ -0,0 +1,164 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ──────────────────────────────────────────────
+// Transcripción de audio (speech-to-text)
+// ──────────────────────────────────────────────
+
+// Transcriptor convierte audio (p. ej. notas de voz de WhatsApp) en texto.
+// Retorna un ChatResponse para medir la transcripción en la misma cuota de
+// tokens que el chat: Content es el texto transcrito.
+type Transcriptor interface {
+	Transcribir(ctx context.Context, audio []byte, mimeType string) (*ChatResponse, error)
+	Name() string
+}
+
+// tokensPorSegundoAudio equivalencia para proveedores que cobran por duración
+// (whisper-1 reporta segundos, no tokens).
+const tokensPorSegundoAudio = 10
+
+// bytesPorSegundoOpus tamaño aproximado de un segundo de nota de voz (opus
+// ~16 kbps), para estimar la duración si el proveedor no reporta el uso.
+const bytesPorSegundoOpus = 2000
+
+// NewTranscriptor crea el proveedor de transcripción configurado. Usa la misma
+// GatewayConfig que los proveedores de chat.
+func NewTranscriptor(cfg GatewayConfig) (Transcriptor, error) {
+	switch cfg.Provider {
+	case "openai":
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("ai: API key requerida para transcripción openai")
+		}
+		model := cfg.Model
+		if model == "" {
+			model = "whisper-1"
+		}
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		return &OpenAITranscriptor{apiKey: cfg.APIKey, model: model, baseURL: strings.TrimRight(baseURL, "/")}, nil
+
+	default:
+		return nil, fmt.Errorf("ai: proveedor de transcripción desconocido '%s'. Use: openai", cfg.Provider)
+	}
+}
+
+// ──────────────────────────────────────────────
+// OPENAI (Whisper + compatibles: Groq, etc.)
+// ──────────────────────────────────────────────
+
+type OpenAITranscriptor struct {
+	apiKey  string
+	model   string
+	baseURL string
+}
+
+func (t *OpenAITranscriptor) Name() string {
+	if t.baseURL != "https://api.openai.com/v1" {
+		return "openai-compatible/" + t.model
+	}
+	return "openai/" + t.model
+}
+
+// Transcribir envía el audio a /audio/transcriptions. El formato se deduce de
+// la extensión del archivo, por eso se nombra según el MIME.
+func (t *OpenAITranscriptor) Transcribir(ctx context.Context, audio []byte, mimeType string) (*ChatResponse, error) {
+	var cuerpo bytes.Buffer
+	w := multipart.NewWriter(&cuerpo)
+	_ = w.WriteField("model", t.model)
+	_ = w.WriteField("language", "es")
+	_ = w.WriteField("response_format", "json")
+	parte, err := w.CreateFormFile("file", "audio"+extensionAudio(mimeType))
+	if err != nil {
+		return nil, fmt.Errorf("openai stt: %w", err)
+	}
+	if _, err := parte.Write(audio); err != nil {
+		return nil, fmt.Errorf("openai stt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("openai stt: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", t.baseURL+"/audio/transcriptions", &cuerpo)
+	if err != nil {
+		return nil, fmt.Errorf("openai stt: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", w.FormDataContentType())
+	httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("openai stt: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("openai stt: HTTP %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		Text  string `json:"text"`
+		Usage *struct {
+			Type         string  `json:"type"`
+			InputTokens  int     `json:"input_tokens"`
+			OutputTokens int     `json:"output_tokens"`
+			Seconds      float64 `json:"seconds"`
+		} `json:"usage"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("openai stt: parse error: %w", err)
+	}
+
+	out := &ChatResponse{Content: strings.TrimSpace(result.Text), Provider: t.Name()}
+	switch {
+	case result.Usage != nil && result.Usage.Type == "tokens":
+		out.PromptTokens, out.OutputTokens = result.Usage.InputTokens, result.Usage.OutputTokens
+	case result.Usage != nil && result.Usage.Seconds > 0:
+		out.PromptTokens = int(result.Usage.Seconds*tokensPorSegundoAudio + 0.5)
+	default:
+		// Compatibles sin usage (Groq, etc.): se estima por el tamaño del audio
+		out.PromptTokens = (len(audio)/bytesPorSegundoOpus + 1) * tokensPorSegundoAudio
+	}
+	if out.OutputTokens == 0 {
+		out.OutputTokens = (utf8.RuneCountInString(out.Content) + 3) / 4
+	}
+	return out, nil
+}
+
+// extensionAudio extensión que Whisper reconoce para el MIME recibido. Las
+// notas de voz de WhatsApp llegan como audio/ogg (opus).
+func extensionAudio(mimeType string) string {
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	switch strings.TrimSpace(strings.ToLower(mimeType)) {
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/m4a", "audio/x-m4a", "audio/aac":
+		return ".m4a"
+	case "audio/wav", "audio/x-wav":
+		return ".wav"
+	case "audio/webm":
+		return ".webm"
+	case "audio/flac":
+		return ".flac"
+	default:
+		return ".ogg"
+	}
+}
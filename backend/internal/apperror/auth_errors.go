@@ -20,6 +20,9 @@ var (
 	ErrSedeNoPermitida = New(403, "SEDE_NOT_ALLOWED",
 		"No tienes acceso a los reclamos de esta sede.")
 
+	ErrSoloOperadorPlataforma = New(403, "PLATFORM_OPERATOR_ONLY",
+		"Solo el equipo que opera la plataforma puede acceder.")
+
 	// API Keys (chatbots)
 	ErrAPIKeyInvalida = New(401, "API_KEY_INVALID",
 		"API key inválida o expirada.")
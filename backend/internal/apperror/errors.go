@@ -41,3 +41,115 @@ var (
 	ErrForbidden  = New(403, "FORBIDDEN", "No tienes permiso para esta acción")
 	ErrConflict   = New(409, "CONFLICT", "El recurso ya existe")
 )
+
+// Errores del scheduler.
+var (
+	ErrJobEnCurso = New(409, "JOB_RUNNING",
+		"El job ya se está ejecutando. Intenta cuando termine.")
+)
+
+// Errores de adjuntos.
+var (
+	ErrArchivoMuyGrande = New(413, "FILE_TOO_LARGE",
+		"El archivo %s supera el máximo de %d MB.")
+	ErrArchivoTipoNoPermitido = New(415, "FILE_TYPE_NOT_ALLOWED",
+		"El archivo %s no es de un tipo permitido (PDF, imágenes, Word, Excel o texto).")
+	ErrDemasiadosArchivos = New(400, "TOO_MANY_FILES",
+		"Puedes adjuntar máximo %d archivos.")
+)
+
+// Errores del flujo de estados del reclamo.
+var (
+	ErrTransicionEstado = New(409, "INVALID_STATE_TRANSITION",
+		"No se puede pasar un reclamo de %s a %s.")
+	ErrReaperturaNoPermitida = New(409, "REOPEN_NOT_ALLOWED",
+		"Solo se pueden reabrir reclamos resueltos o cerrados.")
+	ErrReaperturaVencida = New(403, "REOPEN_WINDOW_EXPIRED",
+		"El plazo para reabrir este reclamo venció.")
+	ErrDocumentoNoCoincide = New(403, "DOCUMENT_MISMATCH",
+		"El número de documento no coincide con el del reclamo.")
+)
+
+// Errores del código de reclamo.
+var (
+	ErrCodigoReclamoMalEscrito = New(404, "CLAIM_CODE_TYPO",
+		"El código de reclamo no es válido. Revisa que lo hayas copiado completo y sin errores.")
+)
+
+// Errores de búsqueda.
+var (
+	ErrCursorInvalido = New(400, "CURSOR_INVALID",
+		"El cursor de paginación es inválido o no corresponde al orden solicitado.")
+	ErrOrdenInvalido = New(400, "SORT_INVALID",
+		"Orden no soportado: %s. Usa fecha_registro, fecha_limite o codigo.")
+)
+
+// Errores del reporte de uso de IA.
+var (
+	ErrRangoFechasInvalido = New(400, "DATE_RANGE_INVALID",
+		"El rango de fechas es inválido: %s")
+)
+
+// Errores de las acciones propuestas por el asistente IA.
+var (
+	ErrAccionNoPendiente = New(409, "ACTION_NOT_PENDING",
+		"La acción ya fue resuelta o venció. Pide al asistente que la proponga de nuevo.")
+)
+
+// Errores de la sugerencia de respuesta con IA.
+var (
+	ErrIANoDisponible = New(503, "AI_UNAVAILABLE",
+		"El asistente de IA no está disponible en este momento. Intenta de nuevo en unos minutos.")
+	ErrSugerenciaInvalida = New(502, "AI_DRAFT_INVALID",
+		"La IA no pudo redactar una sugerencia válida. Intenta de nuevo.")
+)
+
+// Errores de la taxonomía de categorías de reclamos.
+var (
+	ErrCategoriaInvalida = New(400, "CATEGORY_INVALID",
+		"Categoría inválida: %s")
+	ErrCategoriaDuplicada = New(409, "CATEGORY_DUPLICATE",
+		"Ya existe una categoría con ese nombre")
+	ErrDemasiadasCategorias = New(400, "CATEGORY_LIMIT",
+		"Se permiten como máximo %d categorías activas")
+)
+
+// Errores de duplicados y vínculos entre reclamos.
+var (
+	ErrVinculoMismoReclamo = New(400, "LINK_SAME_CLAIM",
+		"Un reclamo no se puede vincular consigo mismo.")
+	ErrFusionNoPermitida = New(409, "MERGE_NOT_ALLOWED",
+		"No se puede fusionar: %s está %s. Solo se fusiona un reclamo PENDIENTE o EN_PROCESO en otro que siga abierto.")
+	ErrReaperturaFusionado = New(409, "REOPEN_MERGED",
+		"Este reclamo se fusionó con otro y se atiende en ese reclamo; no se puede reabrir.")
+)
+
+// Errores del portal del consumidor.
+var (
+	ErrContactoInvalido = New(400, "CONTACT_INVALID",
+		"Ingresa un email o un teléfono válido.")
+	ErrDemasiadosCodigos = New(429, "ACCESS_CODE_RATE_LIMIT",
+		"Solicitaste demasiados códigos. Intenta de nuevo en unos minutos.")
+	ErrCodigoAccesoInvalido = New(401, "ACCESS_CODE_INVALID",
+		"El código es incorrecto o venció. Solicita uno nuevo.")
+	ErrAccesoWhatsAppNoDisponible = New(400, "WHATSAPP_ACCESS_UNAVAILABLE",
+		"Esta empresa no envía códigos por WhatsApp. Ingresa con tu email.")
+	ErrAccesoConsumidorRequerido = New(401, "CONSUMER_AUTH_REQUIRED",
+		"Ingresa al portal con tu email o teléfono para ver los mensajes y respuestas de este reclamo.")
+)
+
+// Errores del webhook de WhatsApp.
+var (
+	ErrFirmaWebhookAusente = New(401, "WEBHOOK_SIGNATURE_MISSING",
+		"Falta la firma X-Hub-Signature-256 del webhook.")
+	ErrFirmaWebhookInvalida = New(401, "WEBHOOK_SIGNATURE_INVALID",
+		"La firma del webhook no es válida.")
+)
+
+// Errores de las plantillas de notificación por WhatsApp.
+var (
+	ErrPlantillaWhatsAppInvalida = New(400, "WHATSAPP_TEMPLATE_INVALID",
+		"Plantilla de WhatsApp inválida: %s")
+	ErrPlantillaWhatsAppDuplicada = New(409, "WHATSAPP_TEMPLATE_DUPLICATE",
+		"Ya existe una plantilla de WhatsApp para ese tipo de notificación")
+)
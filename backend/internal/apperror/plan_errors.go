@@ -14,6 +14,9 @@ var (
 	ErrPlanLimitChatbots = New(403, "PLAN_LIMIT_CHATBOTS",
 		"Tu plan permite máximo %d chatbots. Mejora tu plan.")
 
+	ErrPlanLimitStorage = New(403, "PLAN_LIMIT_STORAGE",
+		"Tu plan permite máximo %d MB de adjuntos. Mejora tu plan.")
+
 	ErrPlanSinChatbot = New(403, "PLAN_NO_CHATBOT",
 		"Tu plan no incluye chatbots. Mejora a IRON o GOLD.")
 
@@ -0,0 +1,113 @@
+// Package cifrado cifra en reposo los secretos de los tenants (tokens de
+// WhatsApp y demás credenciales de integraciones) con envelope encryption:
+//
+//   - Claves maestras (KEK): vienen de la configuración, con versión. Nunca
+//     se guardan en la BD.
+//   - Claves de datos (DEK): una activa por tenant, generada al azar y
+//     guardada envuelta (cifrada) con la clave maestra.
+//   - Cada secreto se cifra con la DEK de su tenant (AES-256-GCM).
+//
+// Rotar la clave maestra solo re-envuelve las DEK; rotar la DEK de un tenant
+// obliga a re-cifrar sus secretos (ver cmd/rotar_claves).
+package cifrado
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// TamanoClave AES-256.
+const TamanoClave = 32
+
+// Llavero claves maestras por versión. Se envuelve siempre con la activa; las
+// anteriores solo se usan para desenvolver DEK que todavía no se rotaron.
+type Llavero struct {
+	activa int
+	claves map[int][]byte
+}
+
+func NewLlavero(activa int, claves map[int][]byte) (*Llavero, error) {
+	if _, ok := claves[activa]; !ok {
+		return nil, fmt.Errorf("cifrado.NewLlavero: no hay clave maestra para la versión activa %d", activa)
+	}
+	copia := make(map[int][]byte, len(claves))
+	for v, k := range claves {
+		if len(k) != TamanoClave {
+			return nil, fmt.Errorf("cifrado.NewLlavero: la clave maestra v%d tiene %d bytes, se requieren %d", v, len(k), TamanoClave)
+		}
+		copia[v] = append([]byte(nil), k...)
+	}
+	return &Llavero{activa: activa, claves: copia}, nil
+}
+
+// VersionActiva versión de la clave maestra con la que se envuelven las DEK nuevas.
+func (l *Llavero) VersionActiva() int {
+	return l.activa
+}
+
+// Envolver cifra una DEK con la clave maestra activa. aad ata el resultado a
+// su dueño (tenant y versión): una DEK envuelta copiada a otro tenant no abre.
+func (l *Llavero) Envolver(dek, aad []byte) ([]byte, int, error) {
+	sellada, err := sellar(l.claves[l.activa], dek, aad)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cifrado.Envolver: %w", err)
+	}
+	return sellada, l.activa, nil
+}
+
+// Desenvolver recupera una DEK envuelta con la versión indicada.
+func (l *Llavero) Desenvolver(envuelta []byte, version int, aad []byte) ([]byte, error) {
+	kek, ok := l.claves[version]
+	if !ok {
+		return nil, fmt.Errorf("cifrado.Desenvolver: clave maestra v%d no configurada", version)
+	}
+	dek, err := abrir(kek, envuelta, aad)
+	if err != nil {
+		return nil, fmt.Errorf("cifrado.Desenvolver v%d: %w", version, err)
+	}
+	return dek, nil
+}
+
+// NuevaClave genera una clave aleatoria de 256 bits.
+func NuevaClave() ([]byte, error) {
+	k := make([]byte, TamanoClave)
+	if _, err := rand.Read(k); err != nil {
+		return nil, fmt.Errorf("cifrado.NuevaClave: %w", err)
+	}
+	return k, nil
+}
+
+// sellar AES-256-GCM: nonce || texto cifrado || tag.
+func sellar(clave, texto, aad []byte) ([]byte, error) {
+	gcm, err := nuevoGCM(clave)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, texto, aad), nil
+}
+
+func abrir(clave, sellado, aad []byte) ([]byte, error) {
+	gcm, err := nuevoGCM(clave)
+	if err != nil {
+		return nil, err
+	}
+	if len(sellado) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, fmt.Errorf("texto cifrado truncado")
+	}
+	nonce, cifrado := sellado[:gcm.NonceSize()], sellado[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, cifrado, aad)
+}
+
+func nuevoGCM(clave []byte) (cipher.AEAD, error) {
+	bloque, err := aes.NewCipher(clave)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(bloque)
+}
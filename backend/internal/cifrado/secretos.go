@@ -0,0 +1,331 @@
+package cifrado
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// prefijoSecreto marca un valor cifrado: enc:v1:<version_dek>:<base64>.
+// Lo que no empieza así es texto plano anterior al cifrado.
+const prefijoSecreto = "enc:v1:"
+
+// ttlClaveActiva cuánto se confía en la DEK activa en caché. Tras una rotación
+// hecha desde otro proceso, esta réplica sigue cifrando con la anterior como
+// mucho este tiempo (la anterior se conserva, así que sigue descifrando).
+const ttlClaveActiva = 5 * time.Minute
+
+// AlmacenClaves persistencia de las DEK envueltas (repo.ClaveDatosRepo).
+type AlmacenClaves interface {
+	Activa(ctx context.Context, tenantID uuid.UUID) (*model.ClaveDatosTenant, error)
+	GetByVersion(ctx context.Context, tenantID uuid.UUID, version int) (*model.ClaveDatosTenant, error)
+	UltimaVersion(ctx context.Context, tenantID uuid.UUID) (int, error)
+	CrearPrimera(ctx context.Context, c *model.ClaveDatosTenant) (bool, error)
+	Rotar(ctx context.Context, c *model.ClaveDatosTenant) error
+	EnvueltasConOtraMaestra(ctx context.Context, versionMaestra int) ([]model.ClaveDatosTenant, error)
+	Reenvolver(ctx context.Context, c *model.ClaveDatosTenant) error
+}
+
+// Recifrador lo implementa cada repo que guarda secretos cifrados, para que
+// cmd/rotar_claves los pase a la DEK activa (o cifre los que siguen en claro).
+type Recifrador interface {
+	TenantsConSecretos(ctx context.Context) ([]uuid.UUID, error)
+	RecifrarSecretos(ctx context.Context, tenantID uuid.UUID) (int, error)
+}
+
+type claveCache struct {
+	tenantID uuid.UUID
+	version  int
+}
+
+type activaCache struct {
+	version int
+	hasta   time.Time
+}
+
+// Secretos cifra y descifra secretos de tenants. Las DEK desenvueltas se
+// guardan en memoria: una versión de DEK nunca cambia de valor.
+type Secretos struct {
+	llavero *Llavero
+	almacen AlmacenClaves
+
+	mu      sync.Mutex
+	deks    map[claveCache][]byte
+	activas map[uuid.UUID]activaCache
+}
+
+// New arma Secretos con las claves maestras de la configuración. Sin clave
+// maestra retorna nil: los secretos se guardan en claro (solo desarrollo).
+func New(cfg config.CifradoConfig, almacen AlmacenClaves) (*Secretos, error) {
+	if !cfg.Habilitado() {
+		return nil, nil
+	}
+	llavero, err := NewLlavero(cfg.VersionActiva, cfg.Claves)
+	if err != nil {
+		return nil, err
+	}
+	return NewSecretos(llavero, almacen), nil
+}
+
+func NewSecretos(llavero *Llavero, almacen AlmacenClaves) *Secretos {
+	return &Secretos{
+		llavero: llavero,
+		almacen: almacen,
+		deks:    make(map[claveCache][]byte),
+		activas: make(map[uuid.UUID]activaCache),
+	}
+}
+
+// VersionMaestra versión de la clave maestra activa.
+func (s *Secretos) VersionMaestra() int {
+	return s.llavero.VersionActiva()
+}
+
+// EstaCifrado indica si el valor tiene el formato de un secreto cifrado.
+func EstaCifrado(valor string) bool {
+	return strings.HasPrefix(valor, prefijoSecreto)
+}
+
+// Cifrar cifra un secreto con la DEK activa del tenant (la crea la primera
+// vez). campo es la columna donde se guarda: el secreto solo abre ahí. Un
+// valor vacío se guarda vacío: en varias columnas vacío tiene significado
+// (app_secret vacío = secreto global).
+func (s *Secretos) Cifrar(ctx context.Context, tenantID uuid.UUID, campo, texto string) (string, error) {
+	if texto == "" {
+		return "", nil
+	}
+	version, dek, err := s.dekActiva(ctx, tenantID)
+	if err != nil {
+		return "", fmt.Errorf("cifrado.Cifrar: %w", err)
+	}
+	sellado, err := sellar(dek, []byte(texto), aadSecreto(tenantID, campo))
+	if err != nil {
+		return "", fmt.Errorf("cifrado.Cifrar: %w", err)
+	}
+	return prefijoSecreto + strconv.Itoa(version) + ":" + base64.StdEncoding.EncodeToString(sellado), nil
+}
+
+// Descifrar recupera un secreto del tenant guardado en campo. Los valores en
+// claro (anteriores al cifrado) se retornan tal cual.
+func (s *Secretos) Descifrar(ctx context.Context, tenantID uuid.UUID, campo, valor string) (string, error) {
+	if !EstaCifrado(valor) {
+		return valor, nil
+	}
+	version, sellado, err := partirSecreto(valor)
+	if err != nil {
+		return "", fmt.Errorf("cifrado.Descifrar: %w", err)
+	}
+	dek, err := s.dek(ctx, tenantID, version)
+	if err != nil {
+		return "", fmt.Errorf("cifrado.Descifrar: %w", err)
+	}
+	texto, err := abrir(dek, sellado, aadSecreto(tenantID, campo))
+	if err != nil {
+		return "", fmt.Errorf("cifrado.Descifrar: secreto alterado, de otro tenant o de otra columna: %w", err)
+	}
+	return string(texto), nil
+}
+
+// Vigente indica si el valor ya está cifrado con la DEK activa del tenant.
+// Los vacíos cuentan como vigentes: no hay nada que re-cifrar.
+func (s *Secretos) Vigente(ctx context.Context, tenantID uuid.UUID, valor string) (bool, error) {
+	if valor == "" {
+		return true, nil
+	}
+	if !EstaCifrado(valor) {
+		return false, nil
+	}
+	version, _, err := partirSecreto(valor)
+	if err != nil {
+		return false, fmt.Errorf("cifrado.Vigente: %w", err)
+	}
+	activa, _, err := s.dekActiva(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("cifrado.Vigente: %w", err)
+	}
+	return version == activa, nil
+}
+
+// RotarClaveDatos crea una DEK nueva para el tenant y la deja activa. Los
+// secretos existentes siguen abriendo con la anterior hasta re-cifrarlos.
+func (s *Secretos) RotarClaveDatos(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	ultima, err := s.almacen.UltimaVersion(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("cifrado.RotarClaveDatos: %w", err)
+	}
+	c, dek, err := s.nuevaDEK(tenantID, ultima+1)
+	if err != nil {
+		return 0, fmt.Errorf("cifrado.RotarClaveDatos: %w", err)
+	}
+	if err := s.almacen.Rotar(ctx, c); err != nil {
+		return 0, fmt.Errorf("cifrado.RotarClaveDatos: %w", err)
+	}
+
+	s.mu.Lock()
+	s.deks[claveCache{tenantID, c.Version}] = dek
+	s.activas[tenantID] = activaCache{version: c.Version, hasta: time.Now().Add(ttlClaveActiva)}
+	s.mu.Unlock()
+	return c.Version, nil
+}
+
+// RotarMaestra re-envuelve con la clave maestra activa todas las DEK que aún
+// usan otra versión. Los secretos no cambian. Retorna cuántas re-envolvió.
+func (s *Secretos) RotarMaestra(ctx context.Context) (int, error) {
+	claves, err := s.almacen.EnvueltasConOtraMaestra(ctx, s.llavero.VersionActiva())
+	if err != nil {
+		return 0, fmt.Errorf("cifrado.RotarMaestra: %w", err)
+	}
+	for i, c := range claves {
+		aad := aadClaveDatos(c.TenantID, c.Version)
+		dek, err := s.llavero.Desenvolver(c.ClaveCifrada, c.VersionMaestra, aad)
+		if err != nil {
+			return i, fmt.Errorf("cifrado.RotarMaestra tenant %s v%d: %w", c.TenantID, c.Version, err)
+		}
+		c.ClaveCifrada, c.VersionMaestra, err = s.llavero.Envolver(dek, aad)
+		if err != nil {
+			return i, fmt.Errorf("cifrado.RotarMaestra: %w", err)
+		}
+		if err := s.almacen.Reenvolver(ctx, &c); err != nil {
+			return i, fmt.Errorf("cifrado.RotarMaestra: %w", err)
+		}
+	}
+	return len(claves), nil
+}
+
+// dekActiva versión y valor de la DEK activa del tenant; la crea si no tiene.
+func (s *Secretos) dekActiva(ctx context.Context, tenantID uuid.UUID) (int, []byte, error) {
+	s.mu.Lock()
+	a, ok := s.activas[tenantID]
+	dek := s.deks[claveCache{tenantID, a.version}]
+	s.mu.Unlock()
+	if ok && dek != nil && time.Now().Before(a.hasta) {
+		return a.version, dek, nil
+	}
+
+	c, err := s.almacen.Activa(ctx, tenantID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c == nil {
+		c, err = s.crearPrimera(ctx, tenantID)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	dek, err = s.desenvolver(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	s.mu.Lock()
+	s.activas[tenantID] = activaCache{version: c.Version, hasta: time.Now().Add(ttlClaveActiva)}
+	s.mu.Unlock()
+	return c.Version, dek, nil
+}
+
+// crearPrimera genera la DEK v1 del tenant. Si otra réplica se adelantó, usa la suya.
+func (s *Secretos) crearPrimera(ctx context.Context, tenantID uuid.UUID) (*model.ClaveDatosTenant, error) {
+	c, _, err := s.nuevaDEK(tenantID, 1)
+	if err != nil {
+		return nil, err
+	}
+	creada, err := s.almacen.CrearPrimera(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if creada {
+		return c, nil
+	}
+	c, err = s.almacen.Activa(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("tenant %s sin clave de datos activa", tenantID)
+	}
+	return c, nil
+}
+
+// dek DEK de una versión concreta (para descifrar secretos anteriores a una rotación).
+func (s *Secretos) dek(ctx context.Context, tenantID uuid.UUID, version int) ([]byte, error) {
+	s.mu.Lock()
+	dek := s.deks[claveCache{tenantID, version}]
+	s.mu.Unlock()
+	if dek != nil {
+		return dek, nil
+	}
+
+	c, err := s.almacen.GetByVersion(ctx, tenantID, version)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("tenant %s sin clave de datos v%d", tenantID, version)
+	}
+	return s.desenvolver(c)
+}
+
+func (s *Secretos) desenvolver(c *model.ClaveDatosTenant) ([]byte, error) {
+	dek, err := s.llavero.Desenvolver(c.ClaveCifrada, c.VersionMaestra, aadClaveDatos(c.TenantID, c.Version))
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.deks[claveCache{c.TenantID, c.Version}] = dek
+	s.mu.Unlock()
+	return dek, nil
+}
+
+func (s *Secretos) nuevaDEK(tenantID uuid.UUID, version int) (*model.ClaveDatosTenant, []byte, error) {
+	dek, err := NuevaClave()
+	if err != nil {
+		return nil, nil, err
+	}
+	envuelta, versionMaestra, err := s.llavero.Envolver(dek, aadClaveDatos(tenantID, version))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &model.ClaveDatosTenant{
+		TenantID:       tenantID,
+		Version:        version,
+		ClaveCifrada:   envuelta,
+		VersionMaestra: versionMaestra,
+		Activa:         true,
+	}, dek, nil
+}
+
+// aadSecreto ata el secreto a su tenant y columna: copiado a otra fila de
+// otro tenant o a otra columna (p. ej. verify_token en access_token) no abre.
+func aadSecreto(tenantID uuid.UUID, campo string) []byte {
+	return []byte(tenantID.String() + ":" + campo)
+}
+
+// aadClaveDatos ata la DEK envuelta a su tenant y versión.
+func aadClaveDatos(tenantID uuid.UUID, version int) []byte {
+	return []byte(tenantID.String() + ":" + strconv.Itoa(version))
+}
+
+func partirSecreto(valor string) (int, []byte, error) {
+	version, datos, ok := strings.Cut(strings.TrimPrefix(valor, prefijoSecreto), ":")
+	if !ok {
+		return 0, nil, fmt.Errorf("secreto con formato inválido")
+	}
+	v, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, nil, fmt.Errorf("versión de clave inválida %q", version)
+	}
+	sellado, err := base64.StdEncoding.DecodeString(datos)
+	if err != nil {
+		return 0, nil, fmt.Errorf("secreto con base64 inválido: %w", err)
+	}
+	return v, sellado, nil
+}
@@ -1,26 +1,32 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 )
 
 // Config raíz — agrupa todas las configuraciones del sistema.
 type Config struct {
-	Server    ServerConfig
-	Cockroach CockroachConfig
-	JWT       JWTConfig
-	APIKey    APIKeyConfig
-	RateLimit RateLimitConfig
-	CORS      CORSConfig
-	SMTP      SMTPConfig
-	AI        AIConfig
-	WhatsApp  WhatsAppConfig
+	Server     ServerConfig
+	Cockroach  CockroachConfig
+	JWT        JWTConfig
+	APIKey     APIKeyConfig
+	RateLimit  RateLimitConfig
+	CORS       CORSConfig
+	SMTP       SMTPConfig
+	AI         AIConfig
+	WhatsApp   WhatsAppConfig
+	Scheduler  SchedulerConfig
+	Storage    StorageConfig
+	Cifrado    CifradoConfig
+	Plataforma PlataformaConfig
 }
 
 type ServerConfig struct {
@@ -78,16 +84,72 @@ type AIConfig struct {
 	FallbackAPIKey   string
 	FallbackModel    string
 	FallbackBaseURL  string
+
+	// Transcripción de notas de voz de WhatsApp (speech-to-text). Vacío = sin audio
+	STTProvider string // "openai" (Whisper o APIs compatibles: Groq, etc.)
+	STTAPIKey   string
+	STTModel    string
+	STTBaseURL  string
 }
 
 // WhatsAppConfig configuración global de WhatsApp Business Cloud API.
 // Los tokens y phone_id por tenant ahora viven en la tabla canales_whatsapp.
 // Solo se conserva VerifyToken como token global para la verificación inicial del webhook por Meta.
+// AppSecret firma los webhooks de la app de Meta de la plataforma; los canales
+// conectados a otra app guardan su propio app_secret.
 type WhatsAppConfig struct {
 	VerifyToken string // Token global para verificación del webhook con Meta
+	AppSecret   string // Secreto de la app de Meta para validar X-Hub-Signature-256
 	Enabled     bool   // Si el módulo WhatsApp está habilitado
 }
 
+// SchedulerConfig jobs periódicos en proceso (vencimientos, alertas SLA, etc.).
+// Con varias réplicas todas pueden tenerlo activo: el lock en CockroachDB
+// garantiza que cada corrida se ejecute una sola vez.
+type SchedulerConfig struct {
+	Enabled     bool
+	ZonaHoraria string // Zona de las expresiones cron
+}
+
+// StorageConfig almacenamiento de adjuntos (reclamos, mensajes, respuestas).
+// Driver "local" guarda en disco; "s3" usa cualquier endpoint S3-compatible
+// (AWS, MinIO, Cloudflare R2, DigitalOcean Spaces).
+type StorageConfig struct {
+	Driver       string // local | s3
+	LocalDir     string
+	MaxArchivoMB int // tamaño máximo por archivo
+	MaxArchivos  int // archivos por request
+
+	S3Endpoint  string // ej: https://s3.us-east-1.amazonaws.com
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3PathStyle bool // true para MinIO y la mayoría de compatibles
+}
+
+// CifradoConfig claves maestras para cifrar en reposo los secretos de los
+// tenants (tokens de WhatsApp, credenciales de integraciones). La activa
+// envuelve las claves de datos nuevas; las anteriores solo se conservan hasta
+// correr cmd/rotar_claves -maestra.
+type CifradoConfig struct {
+	VersionActiva int
+	Claves        map[int][]byte // versión → clave de 32 bytes; vacío = secretos en claro
+}
+
+// PlataformaConfig operación del SaaS por encima de los tenants. Los ADMIN de
+// los tenants operadores (el del equipo que opera la plataforma) ven los jobs
+// del scheduler y las métricas globales del webhook; los ADMIN de los
+// clientes no. Vacío = nadie accede a esas rutas.
+type PlataformaConfig struct {
+	TenantsOperadores []uuid.UUID
+}
+
+// Habilitado indica si hay clave maestra configurada.
+func (c CifradoConfig) Habilitado() bool {
+	return len(c.Claves) > 0
+}
+
 // DSN retorna el connection string para CockroachDB.
 func (c CockroachConfig) DSN() string {
 	if c.Password != "" {
@@ -160,12 +222,47 @@ func Load() (*Config, error) {
 			FallbackAPIKey:   env("AI_FALLBACK_API_KEY", ""),
 			FallbackModel:    env("AI_FALLBACK_MODEL", ""),
 			FallbackBaseURL:  env("AI_FALLBACK_BASE_URL", ""),
+
+			STTProvider: env("AI_STT_PROVIDER", ""),
+			STTAPIKey:   env("AI_STT_API_KEY", ""),
+			STTModel:    env("AI_STT_MODEL", ""),
+			STTBaseURL:  env("AI_STT_BASE_URL", ""),
 		},
 		WhatsApp: WhatsAppConfig{
 			VerifyToken: verifyToken,
+			AppSecret:   env("WHATSAPP_APP_SECRET", ""),
 			Enabled:     verifyToken != "",
 		},
+		Scheduler: SchedulerConfig{
+			Enabled:     env("SCHEDULER_ENABLED", "true") == "true",
+			ZonaHoraria: env("SCHEDULER_TIMEZONE", "America/Lima"),
+		},
+		Storage: StorageConfig{
+			Driver:       env("STORAGE_DRIVER", "local"),
+			LocalDir:     env("STORAGE_LOCAL_DIR", "./data/adjuntos"),
+			MaxArchivoMB: envInt("STORAGE_MAX_FILE_MB", 10),
+			MaxArchivos:  envInt("STORAGE_MAX_FILES", 5),
+
+			S3Endpoint:  env("S3_ENDPOINT", ""),
+			S3Region:    env("S3_REGION", "us-east-1"),
+			S3Bucket:    env("S3_BUCKET", ""),
+			S3AccessKey: env("S3_ACCESS_KEY", ""),
+			S3SecretKey: env("S3_SECRET_KEY", ""),
+			S3PathStyle: env("S3_PATH_STYLE", "true") == "true",
+		},
+	}
+
+	cifrado, err := cargarCifrado()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Cifrado = cifrado
+
+	plataforma, err := cargarPlataforma()
+	if err != nil {
+		return nil, err
 	}
+	cfg.Plataforma = plataforma
 
 	if err := cfg.validate(); err != nil {
 		return nil, err
@@ -185,9 +282,82 @@ func (c *Config) validate() error {
 	if c.Cockroach.DBName == "" {
 		return fmt.Errorf("CRDB_DATABASE es obligatorio")
 	}
+	switch c.Storage.Driver {
+	case "local":
+	case "s3":
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" ||
+			c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			return fmt.Errorf("STORAGE_DRIVER=s3 requiere S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY y S3_SECRET_KEY")
+		}
+	default:
+		return fmt.Errorf("STORAGE_DRIVER inválido: %q (local | s3)", c.Storage.Driver)
+	}
+	if !c.Cifrado.Habilitado() && !c.Server.IsDevelopment() {
+		return fmt.Errorf("SECRETS_MASTER_KEY es obligatorio fuera de desarrollo")
+	}
 	return nil
 }
 
+// cargarCifrado lee SECRETS_MASTER_KEY (base64 de 32 bytes) con su versión y
+// las claves anteriores aún necesarias, en SECRETS_PREVIOUS_MASTER_KEYS
+// como "version:base64,...".
+func cargarCifrado() (CifradoConfig, error) {
+	c := CifradoConfig{
+		VersionActiva: envInt("SECRETS_MASTER_KEY_VERSION", 1),
+		Claves:        map[int][]byte{},
+	}
+	activa := env("SECRETS_MASTER_KEY", "")
+	if activa == "" {
+		return c, nil
+	}
+	clave, err := decodificarClaveMaestra(activa)
+	if err != nil {
+		return c, fmt.Errorf("SECRETS_MASTER_KEY: %w", err)
+	}
+	c.Claves[c.VersionActiva] = clave
+
+	for _, par := range envSlice("SECRETS_PREVIOUS_MASTER_KEYS", nil) {
+		version, valor, ok := strings.Cut(par, ":")
+		v, err := strconv.Atoi(strings.TrimSpace(version))
+		if !ok || err != nil {
+			return c, fmt.Errorf("SECRETS_PREVIOUS_MASTER_KEYS: se espera version:base64 separadas por coma")
+		}
+		if _, dup := c.Claves[v]; dup {
+			return c, fmt.Errorf("SECRETS_PREVIOUS_MASTER_KEYS: versión %d repetida", v)
+		}
+		clave, err := decodificarClaveMaestra(valor)
+		if err != nil {
+			return c, fmt.Errorf("SECRETS_PREVIOUS_MASTER_KEYS v%d: %w", v, err)
+		}
+		c.Claves[v] = clave
+	}
+	return c, nil
+}
+
+// cargarPlataforma lee PLATFORM_OPERATOR_TENANTS: tenant_id separados por coma.
+func cargarPlataforma() (PlataformaConfig, error) {
+	var c PlataformaConfig
+	for _, valor := range envSlice("PLATFORM_OPERATOR_TENANTS", nil) {
+		id, err := uuid.Parse(valor)
+		if err != nil {
+			return c, fmt.Errorf("PLATFORM_OPERATOR_TENANTS: %q no es un tenant_id válido", valor)
+		}
+		c.TenantsOperadores = append(c.TenantsOperadores, id)
+	}
+	return c, nil
+}
+
+func decodificarClaveMaestra(valor string) ([]byte, error) {
+	clave, err := base64.StdEncoding.DecodeString(strings.TrimSpace(valor))
+	if err != nil {
+		return nil, fmt.Errorf("base64 inválido")
+	}
+	if len(clave) != 32 {
+		return nil, fmt.Errorf("debe tener 32 bytes (openssl rand -base64 32), tiene %d", len(clave))
+	}
+	return clave, nil
+}
+
 // --- Helpers DRY para leer env vars ---
 
 func env(key, fallback string) string {
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdjuntoController struct {
+	adjuntoService *service.AdjuntoService
+	tenantService  *service.TenantService
+	reclamoService *service.ReclamoService
+}
+
+func NewAdjuntoController(adjuntoService *service.AdjuntoService, tenantService *service.TenantService, reclamoService *service.ReclamoService) *AdjuntoController {
+	return &AdjuntoController{
+		adjuntoService: adjuntoService,
+		tenantService:  tenantService,
+		reclamoService: reclamoService,
+	}
+}
+
+// GetByReclamo GET /api/v1/reclamos/:id/adjuntos
+func (ctrl *AdjuntoController) GetByReclamo(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	adjuntos, err := ctrl.adjuntoService.ListarPorReclamo(c.Request.Context(), tenantID, reclamoID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	if adjuntos == nil {
+		adjuntos = []model.Adjunto{}
+	}
+	helper.Success(c, adjuntos)
+}
+
+// Descargar GET /api/v1/adjuntos/:id
+func (ctrl *AdjuntoController) Descargar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de adjunto inválido")
+		return
+	}
+
+	adj, err := ctrl.adjuntoService.Obtener(c.Request.Context(), tenantID, id)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	ctrl.servir(c, adj)
+}
+
+// DescargarPublico GET /libro/:slug/seguimiento/:codigo/adjuntos/:id
+// Solo sirve adjuntos que pertenecen al reclamo del código consultado, al
+// consumidor dueño del reclamo (token del portal).
+func (ctrl *AdjuntoController) DescargarPublico(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.Error(c, apperror.ErrNotFound)
+		return
+	}
+
+	tenant, err := ctrl.tenantService.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamo, err := ctrl.reclamoService.GetByCodigoPublico(c.Request.Context(), tenant.TenantID, c.Param("codigo"))
+	if err != nil || reclamo == nil {
+		helper.Error(c, apperror.ErrNotFound)
+		return
+	}
+	if err := service.VerificarAccesoConsumidor(helper.GetIdentidadConsumidor(c), reclamo); err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	adj, err := ctrl.adjuntoService.Obtener(c.Request.Context(), tenant.TenantID, id)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	if adj.ReclamoID != reclamo.ID {
+		helper.Error(c, apperror.ErrNotFound)
+		return
+	}
+	ctrl.servir(c, adj)
+}
+
+func (ctrl *AdjuntoController) servir(c *gin.Context, adj *model.Adjunto) {
+	rc, err := ctrl.adjuntoService.Abrir(c.Request.Context(), adj)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	defer rc.Close()
+
+	disposicion := "attachment"
+	if strings.HasPrefix(adj.MimeType, "image/") || adj.MimeType == "application/pdf" {
+		disposicion = "inline"
+	}
+	c.DataFromReader(http.StatusOK, adj.TamanoBytes, adj.MimeType, rc, map[string]string{
+		"Content-Disposition":    fmt.Sprintf("%s; filename*=UTF-8''%s", disposicion, url.PathEscape(adj.NombreOriginal)),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, max-age=300",
+	})
+}
+
+// leerAdjuntos lee los archivos del campo multipart indicado y los valida.
+// Si el request no es multipart retorna nil (JSON sin adjuntos).
+func leerAdjuntos(c *gin.Context, adjuntoService *service.AdjuntoService, campo string, maxArchivos int) ([]service.ArchivoSubido, error) {
+	if !strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		return nil, nil
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, adjuntoService.LimiteRequest())
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, apperror.New(413, "REQUEST_TOO_LARGE", "Los archivos adjuntos superan el tamaño permitido")
+		}
+		return nil, apperror.ErrBadRequest
+	}
+	return adjuntoService.Preparar(form.File[campo], maxArchivos)
+}
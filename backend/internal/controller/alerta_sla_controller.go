@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertaSLAController struct {
+	alertaService *service.AlertaSLAService
+}
+
+func NewAlertaSLAController(alertaService *service.AlertaSLAService) *AlertaSLAController {
+	return &AlertaSLAController{alertaService: alertaService}
+}
+
+// GetConfig GET /api/v1/alertas-sla
+func (ctrl *AlertaSLAController) GetConfig(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	cfg, err := ctrl.alertaService.ObtenerConfig(c.Request.Context(), tenantID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, cfg)
+}
+
+// UpdateConfig PUT /api/v1/alertas-sla
+func (ctrl *AlertaSLAController) UpdateConfig(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	var req dto.UpdateAlertasSLARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "Datos de alertas inválidos")
+		return
+	}
+
+	cfg := &model.ConfigAlertasSLA{
+		TenantID:          tenantID,
+		Activo:            req.Activo,
+		DiasAviso:         req.DiasAviso,
+		ModoResumen:       req.ModoResumen,
+		NotificarAsignado: req.NotificarAsignado,
+		NotificarSede:     req.NotificarSede,
+		NotificarEmpresa:  req.NotificarEmpresa,
+	}
+
+	if err := ctrl.alertaService.ActualizarConfig(c.Request.Context(), cfg); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, cfg)
+}
@@ -2,10 +2,12 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"libro-reclamaciones/internal/apperror"
 	"libro-reclamaciones/internal/helper"
 	"libro-reclamaciones/internal/service"
 
@@ -27,17 +29,123 @@ func NewAssistantController(assistantService *service.AssistantService) *Assista
 // ──────────────────────────────────────────────────────────────────────────────
 
 func (ctrl *AssistantController) Chat(c *gin.Context) {
-	tenantID, err := helper.GetTenantID(c)
+	alcance, convID, mensaje, ok := ctrl.leerMensajeChat(c)
+	if !ok {
+		return
+	}
+
+	// Timeout de 120s para Ollama local
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	result, err := ctrl.assistantService.Chat(ctx, alcance, convID, mensaje)
 	if err != nil {
-		helper.Error(c, err)
+		fmt.Printf("[ERROR Assistant] %v\n", err)
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			helper.Error(c, appErr) // cuota de tokens agotada
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "El asistente no está disponible en este momento",
+			"detalle": err.Error(),
+		})
 		return
 	}
-	userID, err := helper.GetUserID(c)
+
+	helper.Success(c, gin.H{
+		"response":        result.Response,
+		"prompt_tokens":   result.PromptTokens,
+		"output_tokens":   result.OutputTokens,
+		"provider":        result.Provider,
+		"conversacion_id": result.ConversacionID,
+
+		"acciones_pendientes": result.AccionesPendientes,
+	})
+}
+
+// ──────────────────────────────────────────────────────────────────────────────
+// ChatStream POST /api/v1/assistant/chat/stream
+// Igual que Chat pero responde con Server-Sent Events mientras la IA genera:
+//   event: inicio → {"conversacion_id"}
+//   event: delta  → {"texto"}             (uno por fragmento)
+//   event: fin    → {"prompt_tokens", "output_tokens", "provider", "conversacion_id", "acciones_pendientes"}
+//   event: error  → {"error"}             (el stream se cierra)
+// Los errores de validación (conversación ajena, límite de mensajes, cuota
+// de tokens agotada) se responden como JSON normal, antes de abrir el stream.
+// ──────────────────────────────────────────────────────────────────────────────
+
+func (ctrl *AssistantController) ChatStream(c *gin.Context) {
+	alcance, convID, mensaje, ok := ctrl.leerMensajeChat(c)
+	if !ok {
+		return
+	}
+
+	// Atado al request: si el cliente se desconecta se corta la generación
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 120*time.Second)
+	defer cancel()
+
+	turno, err := ctrl.assistantService.IniciarTurno(ctx, alcance, convID, mensaje)
 	if err != nil {
-		helper.Error(c, err)
+		fmt.Printf("[ERROR Assistant] %v\n", err)
+		if err.Error() == "conversacion_no_encontrada" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversación no encontrada"})
+			return
+		}
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			helper.Error(c, appErr)
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "El asistente no está disponible en este momento",
+			"detalle": err.Error(),
+		})
 		return
 	}
 
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx: no bufferear el stream
+	c.Status(http.StatusOK)
+
+	enviar := func(evento string, data interface{}) error {
+		c.SSEvent(evento, data)
+		c.Writer.Flush()
+		return ctx.Err()
+	}
+
+	_ = enviar("inicio", gin.H{"conversacion_id": turno.ConversacionID.String()})
+
+	result, err := ctrl.assistantService.ChatStream(ctx, turno, func(texto string) error {
+		return enviar("delta", gin.H{"texto": texto})
+	})
+	if err != nil {
+		fmt.Printf("[ERROR Assistant] stream: %v\n", err)
+		_ = enviar("error", gin.H{"error": "El asistente no está disponible en este momento"})
+		return
+	}
+
+	_ = enviar("fin", gin.H{
+		"prompt_tokens":   result.PromptTokens,
+		"output_tokens":   result.OutputTokens,
+		"provider":        result.Provider,
+		"conversacion_id": result.ConversacionID,
+
+		"acciones_pendientes": result.AccionesPendientes,
+	})
+}
+
+// leerMensajeChat valida el body de Chat/ChatStream. Si retorna ok=false ya respondió.
+func (ctrl *AssistantController) leerMensajeChat(c *gin.Context) (alcance service.AlcanceAsistente, convID uuid.UUID, mensaje string, ok bool) {
+	alcance, ok = ctrl.leerAlcance(c)
+	if !ok {
+		return
+	}
+	ok = false
+	var err error
+
 	const limiteCaracteresMensaje = 1000
 
 	var req struct {
@@ -55,7 +163,6 @@ func (ctrl *AssistantController) Chat(c *gin.Context) {
 	}
 
 	// Parsear conversacion_id (uuid.Nil si viene vacío = crear nueva)
-	var convID uuid.UUID
 	if req.ConversacionID != "" {
 		convID, err = uuid.Parse(req.ConversacionID)
 		if err != nil {
@@ -64,27 +171,78 @@ func (ctrl *AssistantController) Chat(c *gin.Context) {
 		}
 	}
 
-	// Timeout de 120s para Ollama local
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	return alcance, convID, req.Message, true
+}
 
-	result, err := ctrl.assistantService.Chat(ctx, tenantID, userID, convID, req.Message)
+// leerAlcance usuario autenticado con su rol y sede: las herramientas del
+// asistente solo ven y modifican lo que el usuario podría desde el panel.
+func (ctrl *AssistantController) leerAlcance(c *gin.Context) (alcance service.AlcanceAsistente, ok bool) {
+	tenantID, err := helper.GetTenantID(c)
 	if err != nil {
-		fmt.Printf("[ERROR Assistant] %v\n", err)
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error":   "El asistente no está disponible en este momento",
-			"detalle": err.Error(),
-		})
+		helper.Error(c, err)
+		return
+	}
+	userID, err := helper.GetUserID(c)
+	if err != nil {
+		helper.Error(c, err)
 		return
 	}
+	return service.AlcanceAsistente{
+		TenantID:  tenantID,
+		UsuarioID: userID,
+		Rol:       helper.GetUserRole(c),
+		SedeID:    helper.GetUserSedeID(c),
+	}, true
+}
 
-	helper.Success(c, gin.H{
-		"response":        result.Response,
-		"prompt_tokens":   result.PromptTokens,
-		"output_tokens":   result.OutputTokens,
-		"provider":        result.Provider,
-		"conversacion_id": result.ConversacionID,
-	})
+// ──────────────────────────────────────────────────────────────────────────────
+// ConfirmarAccion POST /api/v1/assistant/acciones/:id/confirmar
+// Ejecuta una acción propuesta por el asistente (asignar, cambiar estado,
+// responder). Se revalidan permisos y estado del reclamo antes de ejecutar.
+// ──────────────────────────────────────────────────────────────────────────────
+
+func (ctrl *AssistantController) ConfirmarAccion(c *gin.Context) {
+	alcance, ok := ctrl.leerAlcance(c)
+	if !ok {
+		return
+	}
+	accionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de acción inválido")
+		return
+	}
+
+	accion, err := ctrl.assistantService.ConfirmarAccion(c.Request.Context(), alcance, accionID, helper.GetClientIP(c))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	helper.Success(c, accion)
+}
+
+// ──────────────────────────────────────────────────────────────────────────────
+// CancelarAccion POST /api/v1/assistant/acciones/:id/cancelar
+// Descarta una acción propuesta sin ejecutarla.
+// ──────────────────────────────────────────────────────────────────────────────
+
+func (ctrl *AssistantController) CancelarAccion(c *gin.Context) {
+	alcance, ok := ctrl.leerAlcance(c)
+	if !ok {
+		return
+	}
+	accionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de acción inválido")
+		return
+	}
+
+	if err := ctrl.assistantService.CancelarAccion(c.Request.Context(), alcance, accionID); err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	helper.Success(c, gin.H{"message": "Acción cancelada"})
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
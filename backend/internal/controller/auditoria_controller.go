@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditoriaController struct {
+	auditoriaService *service.AuditoriaService
+}
+
+func NewAuditoriaController(auditoriaService *service.AuditoriaService) *AuditoriaController {
+	return &AuditoriaController{auditoriaService: auditoriaService}
+}
+
+// Listar GET /api/v1/auditoria
+// Filtros: usuario_id, entidad, entidad_id, accion, fecha_desde, fecha_hasta (YYYY-MM-DD).
+func (ctrl *AuditoriaController) Listar(c *gin.Context) {
+	filtros, ok := filtrosAuditoria(c)
+	if !ok {
+		return
+	}
+	pag := helper.ParsePagination(c)
+
+	registros, total, err := ctrl.auditoriaService.Listar(c.Request.Context(), filtros, pag)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, dto.NewPaginatedResponse(registros, total, pag.Page, pag.PerPage))
+}
+
+// Exportar GET /api/v1/auditoria/exportar — mismos filtros que Listar, en CSV.
+func (ctrl *AuditoriaController) Exportar(c *gin.Context) {
+	filtros, ok := filtrosAuditoria(c)
+	if !ok {
+		return
+	}
+
+	csvBytes, err := ctrl.auditoriaService.ExportarCSV(c.Request.Context(), filtros)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	nombreArchivo := fmt.Sprintf("Auditoria_%s.csv", time.Now().Format("20060102_150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", nombreArchivo))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", csvBytes)
+}
+
+// filtrosAuditoria arma los filtros desde la query. Responde 400 si son inválidos.
+func filtrosAuditoria(c *gin.Context) (repo.FiltrosAuditoria, bool) {
+	var filtros repo.FiltrosAuditoria
+
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return filtros, false
+	}
+	filtros.TenantID = tenantID
+
+	if v := c.Query("usuario_id"); v != "" {
+		usuarioID, err := uuid.Parse(v)
+		if err != nil {
+			helper.ValidationError(c, "usuario_id inválido")
+			return filtros, false
+		}
+		filtros.UsuarioID = &usuarioID
+	}
+	filtros.Entidad = strings.ToUpper(c.Query("entidad"))
+	filtros.Accion = strings.ToUpper(c.Query("accion"))
+	filtros.EntidadID = c.Query("entidad_id")
+
+	if desde := c.Query("fecha_desde"); desde != "" {
+		t, err := time.Parse("2006-01-02", desde)
+		if err != nil {
+			helper.ValidationError(c, "fecha_desde inválida (YYYY-MM-DD)")
+			return filtros, false
+		}
+		filtros.FechaDesde = &t
+	}
+	if hasta := c.Query("fecha_hasta"); hasta != "" {
+		t, err := time.Parse("2006-01-02", hasta)
+		if err != nil {
+			helper.ValidationError(c, "fecha_hasta inválida (YYYY-MM-DD)")
+			return filtros, false
+		}
+		fin := t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+		filtros.FechaHasta = &fin
+	}
+	return filtros, true
+}
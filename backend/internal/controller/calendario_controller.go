@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"time"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CalendarioController struct {
+	calendarioService *service.CalendarioService
+}
+
+func NewCalendarioController(calendarioService *service.CalendarioService) *CalendarioController {
+	return &CalendarioController{calendarioService: calendarioService}
+}
+
+// GetConfig GET /api/v1/calendario
+func (ctrl *CalendarioController) GetConfig(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	cfg, err := ctrl.calendarioService.ObtenerConfig(c.Request.Context(), tenantID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, cfg)
+}
+
+// UpdateConfig PUT /api/v1/calendario
+func (ctrl *CalendarioController) UpdateConfig(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	var req dto.UpdateCalendarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "Datos de calendario inválidos")
+		return
+	}
+	if req.ZonaHoraria == "" {
+		req.ZonaHoraria = helper.ZonaHorariaLima
+	}
+
+	cfg := &model.CalendarioConfig{
+		TenantID:                  tenantID,
+		DiasHabiles:               req.DiasHabiles,
+		ZonaHoraria:               req.ZonaHoraria,
+		DiasNoLaborables:          req.DiasNoLaborables,
+		IncluirFeriadosNacionales: req.IncluirFeriadosNacionales,
+	}
+
+	if err := ctrl.calendarioService.ActualizarConfig(c.Request.Context(), cfg); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, cfg)
+}
+
+// GetFeriados GET /api/v1/calendario/feriados
+func (ctrl *CalendarioController) GetFeriados(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	feriados, err := ctrl.calendarioService.ListarFeriados(c.Request.Context(), tenantID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, feriados)
+}
+
+// CreateFeriado POST /api/v1/calendario/feriados
+func (ctrl *CalendarioController) CreateFeriado(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	var req dto.CreateFeriadoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "fecha y descripcion son obligatorios")
+		return
+	}
+
+	fecha, err := time.Parse("2006-01-02", req.Fecha)
+	if err != nil {
+		helper.ValidationError(c, "fecha inválida (formato YYYY-MM-DD)")
+		return
+	}
+
+	feriado := &model.Feriado{
+		TenantModel: model.TenantModel{TenantID: tenantID},
+		Fecha:       fecha,
+		Descripcion: req.Descripcion,
+		Recurrente:  req.Recurrente,
+	}
+
+	if err := ctrl.calendarioService.CrearFeriado(c.Request.Context(), feriado); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Created(c, feriado)
+}
+
+// DeleteFeriado DELETE /api/v1/calendario/feriados/:id
+func (ctrl *CalendarioController) DeleteFeriado(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	feriadoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de feriado inválido")
+		return
+	}
+
+	if err := ctrl.calendarioService.EliminarFeriado(c.Request.Context(), tenantID, feriadoID); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.NoContent(c)
+}
+
+// Recalcular POST /api/v1/calendario/recalcular
+// Recalcula de inmediato la fecha límite de los reclamos abiertos.
+func (ctrl *CalendarioController) Recalcular(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	n, err := ctrl.calendarioService.RecalcularFechasLimite(c.Request.Context(), tenantID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, gin.H{"reclamos_actualizados": n})
+}
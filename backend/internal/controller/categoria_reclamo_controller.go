@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CategoriaReclamoController struct {
+	categoriaService *service.CategoriaReclamoService
+}
+
+func NewCategoriaReclamoController(categoriaService *service.CategoriaReclamoService) *CategoriaReclamoController {
+	return &CategoriaReclamoController{categoriaService: categoriaService}
+}
+
+// Listar GET /api/v1/categorias-reclamo?activas=true
+func (ctrl *CategoriaReclamoController) Listar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	categorias, err := ctrl.categoriaService.Listar(c.Request.Context(), tenantID, c.Query("activas") == "true")
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, categorias)
+}
+
+// Crear POST /api/v1/categorias-reclamo
+func (ctrl *CategoriaReclamoController) Crear(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	var req dto.CategoriaReclamoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "nombre es obligatorio")
+		return
+	}
+
+	categoria, err := ctrl.categoriaService.Crear(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Created(c, categoria)
+}
+
+// Actualizar PUT /api/v1/categorias-reclamo/:id
+// Renombrar no cambia los reclamos ya clasificados; activo=false la retira
+// de las próximas clasificaciones.
+func (ctrl *CategoriaReclamoController) Actualizar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de categoría inválido")
+		return
+	}
+
+	var req dto.CategoriaReclamoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "nombre es obligatorio")
+		return
+	}
+
+	categoria, err := ctrl.categoriaService.Actualizar(c.Request.Context(), tenantID, id, &req)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, categoria)
+}
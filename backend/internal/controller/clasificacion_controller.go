@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ClasificacionController struct {
+	clasificacionService *service.ClasificacionService
+}
+
+func NewClasificacionController(clasificacionService *service.ClasificacionService) *ClasificacionController {
+	return &ClasificacionController{clasificacionService: clasificacionService}
+}
+
+// Reclasificar POST /api/v1/reclamos/:id/clasificar
+// Encola el reclamo para que el job lo vuelva a clasificar; responde de
+// inmediato y el resultado aparece en el reclamo al terminar.
+func (ctrl *ClasificacionController) Reclasificar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	if err := ctrl.clasificacionService.Reclasificar(c.Request.Context(), tenantID, reclamoID, helper.GetUserSedeID(c)); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.NoContent(c)
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DuplicadoController struct {
+	duplicadoService *service.DuplicadoService
+}
+
+func NewDuplicadoController(duplicadoService *service.DuplicadoService) *DuplicadoController {
+	return &DuplicadoController{duplicadoService: duplicadoService}
+}
+
+// Listar GET /api/v1/reclamos/:id/duplicados
+// Duplicados probables detectados al registrar y vínculos/fusiones del reclamo.
+func (ctrl *DuplicadoController) Listar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	relaciones, err := ctrl.duplicadoService.Listar(c.Request.Context(), tenantID, reclamoID, helper.GetUserSedeID(c))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, relaciones)
+}
+
+// HistorialConsumidor GET /api/v1/reclamos/:id/consumidor
+// Otros reclamos del mismo consumidor (documento, email o teléfono).
+func (ctrl *DuplicadoController) HistorialConsumidor(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	reclamos, err := ctrl.duplicadoService.HistorialConsumidor(c.Request.Context(), tenantID, reclamoID, helper.GetUserSedeID(c))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, reclamos)
+}
+
+// Vincular POST /api/v1/reclamos/:id/vincular
+func (ctrl *DuplicadoController) Vincular(c *gin.Context) {
+	ctrl.resolver(c, func(p parRelacion) error {
+		return ctrl.duplicadoService.Vincular(c.Request.Context(), p.tenantID, p.reclamoID, p.otroID, p.userID, helper.GetUserSedeID(c), helper.GetClientIP(c))
+	}, "Reclamos vinculados")
+}
+
+// Fusionar POST /api/v1/reclamos/:id/fusionar
+// Cierra :id y lo deja atendido en reclamo_id.
+func (ctrl *DuplicadoController) Fusionar(c *gin.Context) {
+	ctrl.resolver(c, func(p parRelacion) error {
+		return ctrl.duplicadoService.Fusionar(c.Request.Context(), p.tenantID, p.reclamoID, p.otroID, p.userID, helper.GetUserSedeID(c), helper.GetClientIP(c))
+	}, "Reclamo fusionado")
+}
+
+// Descartar POST /api/v1/reclamos/:id/descartar-duplicado
+func (ctrl *DuplicadoController) Descartar(c *gin.Context) {
+	ctrl.resolver(c, func(p parRelacion) error {
+		return ctrl.duplicadoService.Descartar(c.Request.Context(), p.tenantID, p.reclamoID, p.otroID, p.userID, helper.GetUserSedeID(c))
+	}, "Duplicado descartado")
+}
+
+type parRelacion struct {
+	tenantID, userID, reclamoID, otroID uuid.UUID
+}
+
+// resolver lee :id y reclamo_id del body y ejecuta la acción sobre el par.
+func (ctrl *DuplicadoController) resolver(c *gin.Context, accion func(parRelacion) error, mensaje string) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	userID, _ := helper.GetUserID(c)
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	var req dto.RelacionReclamoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "reclamo_id es obligatorio")
+		return
+	}
+	otroID, err := uuid.Parse(req.ReclamoID)
+	if err != nil {
+		helper.ValidationError(c, "reclamo_id inválido")
+		return
+	}
+
+	if err := accion(parRelacion{tenantID: tenantID, userID: userID, reclamoID: reclamoID, otroID: otroID}); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, gin.H{"message": mensaje})
+}
@@ -16,6 +16,7 @@ import (
 type ExportarControlador struct {
 	reclamoService        *service.ReclamoService
 	tenantService         *service.TenantService
+	respuestaService      *service.RespuestaService
 	exportarPDFServicio   *service.ExportarPDFServicio
 	exportarExcelServicio *service.ExportarExcelServicio
 }
@@ -23,12 +24,14 @@ type ExportarControlador struct {
 func NuevoExportarControlador(
 	reclamoService *service.ReclamoService,
 	tenantService *service.TenantService,
+	respuestaService *service.RespuestaService,
 	exportarPDFServicio *service.ExportarPDFServicio,
 	exportarExcelServicio *service.ExportarExcelServicio,
 ) *ExportarControlador {
 	return &ExportarControlador{
 		reclamoService:        reclamoService,
 		tenantService:         tenantService,
+		respuestaService:      respuestaService,
 		exportarPDFServicio:   exportarPDFServicio,
 		exportarExcelServicio: exportarExcelServicio,
 	}
@@ -84,6 +87,56 @@ func (ctrl *ExportarControlador) ExportarExcel(c *gin.Context) {
 	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", excelBytes)
 }
 
+// ExportarHojaReclamo GET /api/v1/reclamos/:id/pdf
+// Hoja de reclamación individual, con la última respuesta si ya existe.
+func (ctrl *ExportarControlador) ExportarHojaReclamo(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	reclamo, err := ctrl.reclamoService.GetByID(c.Request.Context(), tenantID, reclamoID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	pdfBytes, err := generarHojaConRespuesta(c, ctrl.respuestaService, ctrl.exportarPDFServicio, reclamo)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	enviarHojaPDF(c, reclamo.CodigoReclamo, pdfBytes)
+}
+
+// generarHojaConRespuesta arma la hoja incluyendo la respuesta oficial más
+// reciente (GetByReclamo ordena de la más nueva a la más antigua).
+func generarHojaConRespuesta(c *gin.Context, respuestaService *service.RespuestaService, pdfServicio *service.ExportarPDFServicio, reclamo *model.Reclamo) ([]byte, error) {
+	var ultima *model.Respuesta
+	respuestas, err := respuestaService.GetByReclamo(c.Request.Context(), reclamo.TenantID, reclamo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(respuestas) > 0 {
+		ultima = &respuestas[0]
+	}
+	return pdfServicio.GenerarHojaReclamacion(reclamo, ultima)
+}
+
+func enviarHojaPDF(c *gin.Context, codigo string, pdfBytes []byte) {
+	nombreArchivo := fmt.Sprintf("Hoja_Reclamacion_%s.pdf", codigo)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", nombreArchivo))
+	c.Data(http.StatusOK, "application/pdf", pdfBytes)
+}
+
 // obtenerDatosExportacion obtiene reclamos filtrados y datos del tenant.
 func (ctrl *ExportarControlador) obtenerDatosExportacion(c *gin.Context, tenantID uuid.UUID) ([]model.Reclamo, string, string, error) {
 	filtros := repo.FiltrosExportacion{TenantID: tenantID}
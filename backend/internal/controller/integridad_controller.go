@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IntegridadController struct {
+	integridadService *service.IntegridadService
+}
+
+func NewIntegridadController(integridadService *service.IntegridadService) *IntegridadController {
+	return &IntegridadController{integridadService: integridadService}
+}
+
+// Verificar GET /api/v1/admin/integridad/verificar
+func (ctrl *IntegridadController) Verificar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	resultado, err := ctrl.integridadService.Verificar(c.Request.Context(), tenantID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, resultado)
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobController struct {
+	sched   *scheduler.Scheduler
+	jobRepo *repo.JobRepo
+}
+
+func NewJobController(sched *scheduler.Scheduler, jobRepo *repo.JobRepo) *JobController {
+	return &JobController{sched: sched, jobRepo: jobRepo}
+}
+
+// GetAll GET /api/v1/admin/jobs
+func (ctrl *JobController) GetAll(c *gin.Context) {
+	jobs, err := ctrl.sched.Jobs(c.Request.Context())
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, jobs)
+}
+
+// GetEjecuciones GET /api/v1/admin/jobs/:nombre/ejecuciones?limit=20
+func (ctrl *JobController) GetEjecuciones(c *gin.Context) {
+	nombre := c.Param("nombre")
+	if !ctrl.sched.Existe(nombre) {
+		helper.Error(c, apperror.ErrNotFound)
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	ejecuciones, err := ctrl.jobRepo.ListarEjecuciones(c.Request.Context(), nombre, limit)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, ejecuciones)
+}
+
+// Ejecutar POST /api/v1/admin/jobs/:nombre/ejecutar
+// Dispara el job de inmediato; corre en segundo plano.
+func (ctrl *JobController) Ejecutar(c *gin.Context) {
+	ejec, err := ctrl.sched.EjecutarAhora(c.Param("nombre"))
+	switch {
+	case errors.Is(err, scheduler.ErrJobNoExiste):
+		helper.Error(c, apperror.ErrNotFound)
+		return
+	case errors.Is(err, scheduler.ErrJobEnCurso):
+		helper.Error(c, apperror.ErrJobEnCurso)
+		return
+	case err != nil:
+		helper.Error(c, err)
+		return
+	}
+	helper.Created(c, ejec)
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificacionController struct {
+	outboxService *service.OutboxService
+}
+
+func NewNotificacionController(outboxService *service.OutboxService) *NotificacionController {
+	return &NotificacionController{outboxService: outboxService}
+}
+
+// GetByReclamo GET /api/v1/reclamos/:id/notificaciones
+func (ctrl *NotificacionController) GetByReclamo(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	items, err := ctrl.outboxService.ListarPorReclamo(c.Request.Context(), tenantID, reclamoID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, items)
+}
+
+// Reintentar POST /api/v1/notificaciones/:id/reintentar
+func (ctrl *NotificacionController) Reintentar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de notificación inválido")
+		return
+	}
+
+	if err := ctrl.outboxService.Reintentar(c.Request.Context(), tenantID, id); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, gin.H{"message": "Notificación reencolada"})
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PlantillaWhatsAppController struct {
+	plantillaService *service.PlantillaWhatsAppService
+}
+
+func NewPlantillaWhatsAppController(plantillaService *service.PlantillaWhatsAppService) *PlantillaWhatsAppController {
+	return &PlantillaWhatsAppController{plantillaService: plantillaService}
+}
+
+// Listar GET /api/v1/plantillas-whatsapp
+func (ctrl *PlantillaWhatsAppController) Listar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	plantillas, err := ctrl.plantillaService.Listar(c.Request.Context(), tenantID)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, plantillas)
+}
+
+// Crear POST /api/v1/plantillas-whatsapp
+func (ctrl *PlantillaWhatsAppController) Crear(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	var req dto.PlantillaWhatsAppRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "tipo y nombre son obligatorios")
+		return
+	}
+
+	plantilla, err := ctrl.plantillaService.Crear(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Created(c, plantilla)
+}
+
+// Actualizar PUT /api/v1/plantillas-whatsapp/:id
+// activo=false deja de notificar ese tipo por WhatsApp sin perder la configuración.
+func (ctrl *PlantillaWhatsAppController) Actualizar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de plantilla inválido")
+		return
+	}
+
+	var req dto.PlantillaWhatsAppRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "tipo y nombre son obligatorios")
+		return
+	}
+
+	plantilla, err := ctrl.plantillaService.Actualizar(c.Request.Context(), tenantID, id, &req)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, plantilla)
+}
+
+// Eliminar DELETE /api/v1/plantillas-whatsapp/:id
+func (ctrl *PlantillaWhatsAppController) Eliminar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de plantilla inválido")
+		return
+	}
+
+	if err := ctrl.plantillaService.Eliminar(c.Request.Context(), tenantID, id); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.NoContent(c)
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PortalConsumidorController struct {
+	portalService *service.PortalConsumidorService
+	tenantService *service.TenantService
+}
+
+func NewPortalConsumidorController(
+	portalService *service.PortalConsumidorService,
+	tenantService *service.TenantService,
+) *PortalConsumidorController {
+	return &PortalConsumidorController{
+		portalService: portalService,
+		tenantService: tenantService,
+	}
+}
+
+// SolicitarCodigo POST /libro/:slug/portal/acceso
+// Responde lo mismo tenga o no reclamos el consumidor.
+func (ctrl *PortalConsumidorController) SolicitarCodigo(c *gin.Context) {
+	var req dto.SolicitarCodigoPortalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "Ingresa tu email o tu teléfono")
+		return
+	}
+
+	tenant, err := ctrl.tenantService.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := ctrl.portalService.Identidad(tenant.TenantID, req.Email, req.Telefono)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	if err := ctrl.portalService.SolicitarCodigo(c.Request.Context(), tenant, id, helper.GetClientIP(c)); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, gin.H{"message": "Si tienes reclamos registrados con ese dato, te enviamos un código de acceso."})
+}
+
+// Verificar POST /libro/:slug/portal/acceso/verificar
+// Canjea el código por el token del portal.
+func (ctrl *PortalConsumidorController) Verificar(c *gin.Context) {
+	var req dto.VerificarCodigoPortalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "El código es obligatorio")
+		return
+	}
+
+	tenant, err := ctrl.tenantService.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id, err := ctrl.portalService.Identidad(tenant.TenantID, req.Email, req.Telefono)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	sesion, err := ctrl.portalService.Verificar(c.Request.Context(), id, req.Codigo)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, sesion)
+}
+
+// ListarReclamos GET /libro/:slug/portal/reclamos
+// Todos los reclamos del consumidor con el tenant. El detalle, la respuesta,
+// los mensajes y adjuntos se consultan en /seguimiento/:codigo con el mismo token.
+func (ctrl *PortalConsumidorController) ListarReclamos(c *gin.Context) {
+	tenant, err := ctrl.tenantService.GetBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	id := helper.GetIdentidadConsumidor(c)
+	if id == nil || id.TenantID != tenant.TenantID {
+		helper.Error(c, apperror.ErrTokenInvalido)
+		return
+	}
+
+	reclamos, err := ctrl.portalService.Reclamos(c.Request.Context(), *id)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	items := make([]dto.ReclamoPortalItem, 0, len(reclamos))
+	for _, r := range reclamos {
+		items = append(items, dto.ReclamoPortalItem{
+			CodigoReclamo: r.CodigoReclamo,
+			TipoSolicitud: r.TipoSolicitud,
+			Estado:        r.Estado,
+			CanalOrigen:   r.CanalOrigen,
+			SedeNombre:    r.SedeNombre.String,
+			FechaRegistro: r.FechaRegistro,
+		})
+	}
+	helper.Success(c, items)
+}
@@ -1,6 +1,10 @@
 package controller
 
 import (
+    "encoding/json"
+    "strings"
+    "time"
+
     "libro-reclamaciones/internal/apperror"
     "libro-reclamaciones/internal/helper"
     "libro-reclamaciones/internal/model"
@@ -16,6 +20,8 @@ type PublicController struct {
     sedeService      *service.SedeService
     mensajeService   *service.MensajeService
     respuestaService *service.RespuestaService
+    adjuntoService   *service.AdjuntoService
+    pdfServicio      *service.ExportarPDFServicio
 }
 
 func NewPublicController(
@@ -24,6 +30,8 @@ func NewPublicController(
     sedeService *service.SedeService,
     mensajeService *service.MensajeService,
     respuestaService *service.RespuestaService,
+    adjuntoService *service.AdjuntoService,
+    pdfServicio *service.ExportarPDFServicio,
 ) *PublicController {
     return &PublicController{
         reclamoService:   reclamoService,
@@ -31,6 +39,8 @@ func NewPublicController(
         sedeService:      sedeService,
         mensajeService:   mensajeService,
         respuestaService: respuestaService,
+        adjuntoService:   adjuntoService,
+        pdfServicio:      pdfServicio,
     }
 }
 
@@ -81,11 +91,24 @@ func (ctrl *PublicController) GetSedes(c *gin.Context) {
 }
 
 // CrearReclamo POST /libro/:slug/reclamos
+// Acepta JSON, o multipart/form-data con el JSON del formulario en el campo
+// "datos" y los adjuntos en "archivos".
 func (ctrl *PublicController) CrearReclamo(c *gin.Context) {
     slug := c.Param("slug")
 
+    archivos, err := leerAdjuntos(c, ctrl.adjuntoService, "archivos", 0)
+    if err != nil {
+        helper.Error(c, err)
+        return
+    }
+
     var req dto.CreateReclamoRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
+    if c.ContentType() == "multipart/form-data" {
+        err = json.Unmarshal([]byte(c.PostForm("datos")), &req)
+    } else {
+        err = c.ShouldBindJSON(&req)
+    }
+    if err != nil {
         helper.ValidationError(c, "Completa todos los campos obligatorios del formulario")
         return
     }
@@ -96,6 +119,7 @@ func (ctrl *PublicController) CrearReclamo(c *gin.Context) {
         req,
         helper.GetClientIP(c),
         c.GetHeader("User-Agent"),
+        archivos,
     )
     if err != nil {
         helper.Error(c, err)
@@ -126,16 +150,29 @@ func (ctrl *PublicController) ConsultarSeguimiento(c *gin.Context) {
         helper.Error(c, err)
         return
     }
-   if reclamo == nil {
-		helper.Error(c, apperror.New(404, "NOT_FOUND", "Reclamo no encontrado"))
-		return
-	}
+    if reclamo == nil {
+        // Con dígito verificador se distingue un código mal copiado de uno inexistente
+        if strings.HasSuffix(tenant.FormatoCodigo.String, helper.MarcadorDigito) && !helper.DigitoVerificadorValido(strings.TrimSpace(codigo)) {
+            helper.Error(c, apperror.ErrCodigoReclamoMalEscrito)
+            return
+        }
+        helper.Error(c, apperror.New(404, "NOT_FOUND", "Reclamo no encontrado"))
+        return
+    }
 
-    // Buscar respuesta oficial
-    respuestas, _ := ctrl.respuestaService.GetByReclamo(c.Request.Context(), tenant.TenantID, reclamo.ID)
+    // La respuesta oficial solo se muestra al consumidor dueño del reclamo
+    verificado := service.VerificarAccesoConsumidor(helper.GetIdentidadConsumidor(c), reclamo) == nil
     var respuestaOficial string
-    if len(respuestas) > 0 {
-        respuestaOficial = respuestas[len(respuestas)-1].RespuestaEmpresa
+    if verificado {
+        respuestas, err := ctrl.respuestaService.GetByReclamo(c.Request.Context(), tenant.TenantID, reclamo.ID)
+        if err != nil {
+            helper.Error(c, err)
+            return
+        }
+        // Ordenadas de la más reciente a la más antigua
+        if len(respuestas) > 0 {
+            respuestaOficial = respuestas[0].RespuestaEmpresa
+        }
     }
 
     response := dto.ReclamoTrackingResponse{
@@ -147,15 +184,84 @@ func (ctrl *PublicController) ConsultarSeguimiento(c *gin.Context) {
         TipoSolicitud:        reclamo.TipoSolicitud,
         DescripcionBien:      reclamo.DescripcionBien,
         RespuestaEmpresa:     respuestaOficial,
+        ConsumidorVerificado: verificado,
     }
     if reclamo.FechaRespuesta.Valid {
         response.FechaRespuesta = &reclamo.FechaRespuesta.Time
     }
+    hasta, puedeReabrir, err := ctrl.reclamoService.PlazoReaperturaSeguimiento(c.Request.Context(), tenant, reclamo)
+    if err != nil {
+        helper.Error(c, err)
+        return
+    }
+    if puedeReabrir && time.Now().Before(hasta) {
+        response.PuedeReabrir = true
+        response.ReabrirHasta = &hasta
+    }
 
     helper.Success(c, response)
 }
 
+// ReabrirPublico POST /libro/:slug/seguimiento/:codigo/reabrir
+// El consumidor confirma su número de documento y explica por qué no está conforme.
+func (ctrl *PublicController) ReabrirPublico(c *gin.Context) {
+    slug := c.Param("slug")
+    codigo := c.Param("codigo")
+
+    var req dto.ReabrirPublicoRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        helper.ValidationError(c, "numero_documento y motivo son obligatorios")
+        return
+    }
+
+    tenant, err := ctrl.tenantService.GetBySlug(c.Request.Context(), slug)
+    if err != nil {
+        helper.Error(c, err)
+        return
+    }
+
+    if err := ctrl.reclamoService.ReabrirPublico(
+        c.Request.Context(), tenant, codigo, req.NumeroDocumento, req.Motivo, helper.GetClientIP(c),
+    ); err != nil {
+        helper.Error(c, err)
+        return
+    }
+    helper.Success(c, gin.H{"message": "Tu reclamo fue reabierto. Recibirás una nueva respuesta dentro del plazo legal."})
+}
+
+// DescargarHojaPublica GET /libro/:slug/seguimiento/:codigo/pdf
+// Requiere el token del portal del consumidor dueño del reclamo.
+func (ctrl *PublicController) DescargarHojaPublica(c *gin.Context) {
+    slug := c.Param("slug")
+    codigo := c.Param("codigo")
+
+    tenant, err := ctrl.tenantService.GetBySlug(c.Request.Context(), slug)
+    if err != nil {
+        helper.Error(c, err)
+        return
+    }
+
+    reclamo, err := ctrl.reclamoService.GetByCodigoPublico(c.Request.Context(), tenant.TenantID, codigo)
+    if err != nil || reclamo == nil {
+        helper.Error(c, apperror.ErrNotFound)
+        return
+    }
+    if err := service.VerificarAccesoConsumidor(helper.GetIdentidadConsumidor(c), reclamo); err != nil {
+        helper.Error(c, err)
+        return
+    }
+
+    pdfBytes, err := generarHojaConRespuesta(c, ctrl.respuestaService, ctrl.pdfServicio, reclamo)
+    if err != nil {
+        helper.Error(c, err)
+        return
+    }
+
+    enviarHojaPDF(c, reclamo.CodigoReclamo, pdfBytes)
+}
+
 // ListarMensajesPublico GET /libro/:slug/seguimiento/:codigo/mensajes
+// Requiere el token del portal del consumidor dueño del reclamo.
 func (ctrl *PublicController) ListarMensajesPublico(c *gin.Context) {
     slug := c.Param("slug")
     codigo := c.Param("codigo")
@@ -171,6 +277,10 @@ func (ctrl *PublicController) ListarMensajesPublico(c *gin.Context) {
         helper.Error(c, apperror.ErrNotFound)
         return
     }
+    if err := service.VerificarAccesoConsumidor(helper.GetIdentidadConsumidor(c), reclamo); err != nil {
+        helper.Error(c, err)
+        return
+    }
 
     mensajes, err := ctrl.mensajeService.GetByReclamo(c.Request.Context(), tenant.TenantID, reclamo.ID)
     if err != nil {
@@ -182,11 +292,20 @@ func (ctrl *PublicController) ListarMensajesPublico(c *gin.Context) {
 }
 
 // EnviarMensajePublico POST /libro/:slug/seguimiento/:codigo/mensajes
+// Acepta JSON o multipart/form-data con un archivo en el campo "archivo".
+// Requiere el token del portal del consumidor dueño del reclamo.
 func (ctrl *PublicController) EnviarMensajePublico(c *gin.Context) {
     slug := c.Param("slug")
     codigo := c.Param("codigo")
+
+    archivos, err := leerAdjuntos(c, ctrl.adjuntoService, "archivo", 1)
+    if err != nil {
+        helper.Error(c, err)
+        return
+    }
+
     var req dto.PublicMessageRequest
-    if err := c.ShouldBindJSON(&req); err != nil {
+    if err := c.ShouldBind(&req); err != nil {
         helper.ValidationError(c, "Mensaje requerido")
         return
     }
@@ -202,8 +321,17 @@ func (ctrl *PublicController) EnviarMensajePublico(c *gin.Context) {
         helper.Error(c, apperror.ErrNotFound)
         return
     }
+    if err := service.VerificarAccesoConsumidor(helper.GetIdentidadConsumidor(c), reclamo); err != nil {
+        helper.Error(c, err)
+        return
+    }
 
-    msg, err := ctrl.mensajeService.CrearPublico(c.Request.Context(), tenant.TenantID, reclamo.ID, req.Mensaje, req.ArchivoURL, req.ArchivoNombre)
+    var msg *model.Mensaje
+    if len(archivos) > 0 {
+        msg, err = ctrl.mensajeService.CrearPublicoConAdjunto(c.Request.Context(), tenant, reclamo, req.Mensaje, archivos[0])
+    } else {
+        msg, err = ctrl.mensajeService.CrearPublico(c.Request.Context(), tenant.TenantID, reclamo.ID, req.Mensaje, req.ArchivoURL, req.ArchivoNombre)
+    }
     if err != nil {
         helper.Error(c, err)
         return
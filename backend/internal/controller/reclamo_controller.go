@@ -1,10 +1,12 @@
 package controller
 
 import (
-
+	"strings"
 	"time"
+
 	"libro-reclamaciones/internal/helper"
 	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/repo"
 	"libro-reclamaciones/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -77,6 +79,91 @@ func (ctrl *ReclamoController) GetAll(c *gin.Context) {
 	helper.Success(c, dto.NewPaginatedResponse(reclamos, total, pag.Page, pag.PerPage))
 }
 
+// Buscar GET /api/v1/reclamos/buscar
+// Query: q (nombre, DNI, código, nº de pedido o palabras del relato), facetas
+// multivalor estado, tipo_solicitud, canal_origen, sede_id, atendido_por
+// (UUID o SIN_ASIGNAR), prioridad, categoria, sentimiento, urgencia
+// (clasificación IA); fecha_desde, fecha_hasta; orden
+// (fecha_registro|fecha_limite|codigo), dir (asc|desc), cursor y per_page.
+func (ctrl *ReclamoController) Buscar(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	filtros := repo.FiltrosBusqueda{
+		TenantID:       tenantID,
+		Texto:          c.Query("q"),
+		Estados:        valoresQuery(c, "estado"),
+		TiposSolicitud: valoresQuery(c, "tipo_solicitud"),
+		Canales:        valoresQuery(c, "canal_origen"),
+		Prioridades:    valoresQuery(c, "prioridad"),
+		Categorias:     valoresQuery(c, "categoria"),
+		Sentimientos:   valoresQuery(c, "sentimiento"),
+		Urgencias:      valoresQuery(c, "urgencia"),
+		Orden:          c.Query("orden"),
+		Asc:            strings.EqualFold(c.Query("dir"), "asc"),
+		Cursor:         c.Query("cursor"),
+		Limite:         helper.ParsePagination(c).PerPage,
+	}
+
+	for _, v := range valoresQuery(c, "sede_id") {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			helper.ValidationError(c, "sede_id inválido")
+			return
+		}
+		filtros.SedeIDs = append(filtros.SedeIDs, id)
+	}
+	for _, v := range valoresQuery(c, "atendido_por") {
+		if strings.EqualFold(v, repo.SinAsignar) {
+			filtros.SinAsignar = true
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			helper.ValidationError(c, "atendido_por inválido")
+			return
+		}
+		filtros.AtendidoPor = append(filtros.AtendidoPor, id)
+	}
+	// SOPORTE con sede asignada: forzar su sede (no puede ver otras, ni en las facetas)
+	filtros.SedeForzada = helper.GetUserSedeID(c)
+
+	if desde := c.Query("fecha_desde"); desde != "" {
+		if t, err := time.Parse("2006-01-02", desde); err == nil {
+			filtros.FechaDesde = &t
+		}
+	}
+	if hasta := c.Query("fecha_hasta"); hasta != "" {
+		if t, err := time.Parse("2006-01-02", hasta); err == nil {
+			fin := t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+			filtros.FechaHasta = &fin
+		}
+	}
+
+	resultado, err := ctrl.reclamoService.Buscar(c.Request.Context(), filtros)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, resultado)
+}
+
+// valoresQuery lee un parámetro multivalor: ?estado=A&estado=B o ?estado=A,B.
+func valoresQuery(c *gin.Context, key string) []string {
+	var valores []string
+	for _, v := range c.QueryArray(key) {
+		for _, p := range strings.Split(v, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				valores = append(valores, p)
+			}
+		}
+	}
+	return valores
+}
+
 func inicioDelDiaReclamo(t time.Time) time.Time {
 	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
@@ -134,6 +221,36 @@ func (ctrl *ReclamoController) CambiarEstado(c *gin.Context) {
 	helper.Success(c, gin.H{"message": "Estado actualizado"})
 }
 
+// Reabrir POST /api/v1/reclamos/:id/reabrir
+func (ctrl *ReclamoController) Reabrir(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	userID, _ := helper.GetUserID(c)
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	var req dto.ReabrirReclamoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.ValidationError(c, "motivo es obligatorio")
+		return
+	}
+
+	if err := ctrl.reclamoService.Reabrir(
+		c.Request.Context(), tenantID, reclamoID, userID, helper.GetUserSedeID(c), req.Motivo, helper.GetClientIP(c),
+	); err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, gin.H{"message": "Reclamo reabierto"})
+}
+
 // Asignar POST /api/v1/reclamos/:id/asignar
 func (ctrl *ReclamoController) Asignar(c *gin.Context) {
 	tenantID, err := helper.GetTenantID(c)
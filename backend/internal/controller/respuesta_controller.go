@@ -11,10 +11,11 @@ import (
 
 type RespuestaController struct {
 	respuestaService *service.RespuestaService
+	adjuntoService   *service.AdjuntoService
 }
 
-func NewRespuestaController(respuestaService *service.RespuestaService) *RespuestaController {
-	return &RespuestaController{respuestaService: respuestaService}
+func NewRespuestaController(respuestaService *service.RespuestaService, adjuntoService *service.AdjuntoService) *RespuestaController {
+	return &RespuestaController{respuestaService: respuestaService, adjuntoService: adjuntoService}
 }
 
 // GetByReclamo GET /api/v1/reclamos/:id/respuestas
@@ -40,6 +41,7 @@ func (ctrl *RespuestaController) GetByReclamo(c *gin.Context) {
 }
 
 // Create POST /api/v1/reclamos/:id/respuestas
+// Acepta JSON o multipart/form-data con adjuntos en el campo "archivos".
 func (ctrl *RespuestaController) Create(c *gin.Context) {
 	tenantID, err := helper.GetTenantID(c)
 	if err != nil {
@@ -54,8 +56,14 @@ func (ctrl *RespuestaController) Create(c *gin.Context) {
 		return
 	}
 
+	archivos, err := leerAdjuntos(c, ctrl.adjuntoService, "archivos", 0)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
 	var req dto.CreateRespuestaRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBind(&req); err != nil {
 		helper.ValidationError(c, "respuesta_empresa es obligatorio")
 		return
 	}
@@ -63,7 +71,7 @@ func (ctrl *RespuestaController) Create(c *gin.Context) {
 	resp, err := ctrl.respuestaService.Crear(
 		c.Request.Context(), tenantID, reclamoID, userID,
 		req.RespuestaEmpresa, req.AccionTomada, req.CompensacionOfrecida,
-		req.CargoResponsable, helper.GetClientIP(c),
+		req.CargoResponsable, helper.GetClientIP(c), archivos,
 	)
 	if err != nil {
 		helper.Error(c, err)
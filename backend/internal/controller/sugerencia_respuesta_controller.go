@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SugerenciaRespuestaController struct {
+	sugerenciaService *service.SugerenciaRespuestaService
+}
+
+func NewSugerenciaRespuestaController(sugerenciaService *service.SugerenciaRespuestaService) *SugerenciaRespuestaController {
+	return &SugerenciaRespuestaController{sugerenciaService: sugerenciaService}
+}
+
+// Sugerir POST /api/v1/reclamos/:id/respuestas/sugerencia
+// Borrador de respuesta redactado por la IA. No se guarda: el panel lo
+// muestra editable y el usuario lo envía con POST /:id/respuestas.
+func (ctrl *SugerenciaRespuestaController) Sugerir(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	reclamoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		helper.ValidationError(c, "ID de reclamo inválido")
+		return
+	}
+
+	// Mismo margen que el asistente (Ollama local puede tardar)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 120*time.Second)
+	defer cancel()
+
+	sugerencia, err := ctrl.sugerenciaService.Sugerir(ctx, tenantID, reclamoID, helper.GetUserSedeID(c))
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, sugerencia)
+}
@@ -3,6 +3,7 @@
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"libro-reclamaciones/internal/helper"
 	"libro-reclamaciones/internal/model"
 	"libro-reclamaciones/internal/service"
@@ -36,23 +37,25 @@ func (ctrl *TenantController) Get(c *gin.Context) {
 
 // updateTenantRequest define la estructura JSON esperada del frontend
 type updateTenantRequest struct {
-	RazonSocial         string `json:"razon_social" binding:"required"`
-	RUC                 string `json:"ruc" binding:"required"`
-	NombreComercial     string `json:"nombre_comercial"`
-	DireccionLegal      string `json:"direccion_legal"`
-	Departamento        string `json:"departamento"`
-	Provincia           string `json:"provincia"`
-	Distrito            string `json:"distrito"`
-	Telefono            string `json:"telefono"`
-	EmailContacto       string `json:"email_contacto"`
-	SitioWeb            string `json:"sitio_web"`
-	ColorPrimario       string `json:"color_primario"`
-	PlazoRespuestaDias  int    `json:"plazo_respuesta_dias"`
-	NotificarWhatsapp   bool   `json:"notificar_whatsapp"`
-	NotificarEmail      bool   `json:"notificar_email"`
-	LogoURL             string `json:"logo_url"`             // <--- Faltaba esto
-	MensajeConfirmacion string `json:"mensaje_confirmacion"` // <--- Faltaba esto
-	Version             int    `json:"version" binding:"required"`
+	RazonSocial         string  `json:"razon_social" binding:"required"`
+	RUC                 string  `json:"ruc" binding:"required"`
+	NombreComercial     string  `json:"nombre_comercial"`
+	DireccionLegal      string  `json:"direccion_legal"`
+	Departamento        string  `json:"departamento"`
+	Provincia           string  `json:"provincia"`
+	Distrito            string  `json:"distrito"`
+	Telefono            string  `json:"telefono"`
+	EmailContacto       string  `json:"email_contacto"`
+	SitioWeb            string  `json:"sitio_web"`
+	ColorPrimario       string  `json:"color_primario"`
+	PlazoRespuestaDias  int     `json:"plazo_respuesta_dias"`
+	NotificarWhatsapp   bool    `json:"notificar_whatsapp"`
+	NotificarEmail      bool    `json:"notificar_email"`
+	LogoURL             string  `json:"logo_url"`             // <--- Faltaba esto
+	MensajeConfirmacion string  `json:"mensaje_confirmacion"` // <--- Faltaba esto
+	DiasReapertura      *int    `json:"dias_reapertura"`      // nil = se mantiene el valor actual
+	FormatoCodigo       *string `json:"formato_codigo"`       // nil = se mantiene; "" = formato por defecto
+	Version             int     `json:"version" binding:"required"`
 }
 
 // Update PUT /api/v1/tenant
@@ -97,6 +100,38 @@ func (ctrl *TenantController) Update(c *gin.Context) {
 		Version:            req.Version,
 	}
 
+	var actual *model.Tenant
+	if req.DiasReapertura == nil || req.FormatoCodigo == nil {
+		actual, err = ctrl.tenantService.GetByTenantID(c.Request.Context(), tenantID)
+		if err != nil {
+			helper.Error(c, err)
+			return
+		}
+	}
+
+	if req.DiasReapertura != nil {
+		if *req.DiasReapertura < 0 || *req.DiasReapertura > 365 {
+			helper.ValidationError(c, "dias_reapertura debe estar entre 0 y 365")
+			return
+		}
+		tenant.DiasReapertura = *req.DiasReapertura
+	} else {
+		tenant.DiasReapertura = actual.DiasReapertura
+	}
+
+	if req.FormatoCodigo != nil {
+		formato := strings.ToUpper(strings.TrimSpace(*req.FormatoCodigo))
+		if formato != "" {
+			if err := helper.ValidarFormatoCodigo(formato); err != nil {
+				helper.ValidationError(c, "formato_codigo inválido: "+err.Error())
+				return
+			}
+		}
+		tenant.FormatoCodigo = model.NullString{NullString: sql.NullString{String: formato, Valid: formato != ""}}
+	} else {
+		tenant.FormatoCodigo = actual.FormatoCodigo
+	}
+
 	if err := ctrl.tenantService.Update(c.Request.Context(), tenant); err != nil {
 		helper.Error(c, err)
 		return
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"time"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsoIAController struct {
+	usoIAService *service.UsoIAService
+}
+
+func NewUsoIAController(usoIAService *service.UsoIAService) *UsoIAController {
+	return &UsoIAController{usoIAService: usoIAService}
+}
+
+// Reporte GET /api/v1/uso-ia
+// Filtros: fecha_desde, fecha_hasta (YYYY-MM-DD). Por defecto el mes en curso.
+func (ctrl *UsoIAController) Reporte(c *gin.Context) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+
+	hoy := time.Now().UTC().Truncate(24 * time.Hour)
+	desde := time.Date(hoy.Year(), hoy.Month(), 1, 0, 0, 0, 0, time.UTC)
+	hasta := hoy
+
+	if v := c.Query("fecha_desde"); v != "" {
+		if desde, err = time.Parse("2006-01-02", v); err != nil {
+			helper.ValidationError(c, "fecha_desde inválida (YYYY-MM-DD)")
+			return
+		}
+	}
+	if v := c.Query("fecha_hasta"); v != "" {
+		if hasta, err = time.Parse("2006-01-02", v); err != nil {
+			helper.ValidationError(c, "fecha_hasta inválida (YYYY-MM-DD)")
+			return
+		}
+	}
+
+	reporte, err := ctrl.usoIAService.Reporte(c.Request.Context(), tenantID, desde, hasta)
+	if err != nil {
+		helper.Error(c, err)
+		return
+	}
+	helper.Success(c, reporte)
+}
@@ -33,6 +33,7 @@ type crearCanalWARequest struct {
 	DisplayPhone  string  `json:"display_phone"`
 	AccessToken   string  `json:"access_token" binding:"required"`
 	VerifyToken   string  `json:"verify_token"`
+	AppSecret     string  `json:"app_secret"` // solo si el número es de otra app de Meta
 	NombreCanal   string  `json:"nombre_canal"`
 	ChatbotID     *string `json:"chatbot_id"` // UUID string o null
 }
@@ -42,6 +43,7 @@ type actualizarCanalWARequest struct {
 	DisplayPhone  string  `json:"display_phone"`
 	AccessToken   string  `json:"access_token"`
 	VerifyToken   string  `json:"verify_token"`
+	AppSecret     string  `json:"app_secret"`
 	NombreCanal   string  `json:"nombre_canal"`
 	ChatbotID     *string `json:"chatbot_id"` // UUID string o null
 	Activo        bool    `json:"activo"`
@@ -58,6 +60,7 @@ type canalWAResponse struct {
 	// Indicamos si tiene token configurado sin exponer el valor
 	TieneAccessToken bool   `json:"tiene_access_token"`
 	TieneVerifyToken bool   `json:"tiene_verify_token"`
+	TieneAppSecret   bool   `json:"tiene_app_secret"`
 	FechaCreacion    string `json:"fecha_creacion"`
 }
 
@@ -70,6 +73,7 @@ func mapCanalToResponse(c model.CanalWhatsApp) canalWAResponse {
 		Activo:           c.Activo,
 		TieneAccessToken: c.AccessToken != "",
 		TieneVerifyToken: c.VerifyToken != "",
+		TieneAppSecret:   c.AppSecret != "",
 		FechaCreacion:    c.FechaCreacion.Format("2006-01-02T15:04:05Z07:00"),
 	}
 	if c.ChatbotID.Valid {
@@ -189,6 +193,7 @@ func (ctrl *WhatsAppConfigController) Create(c *gin.Context) {
 		DisplayPhone:  req.DisplayPhone,
 		AccessToken:   req.AccessToken,
 		VerifyToken:   req.VerifyToken,
+		AppSecret:     req.AppSecret,
 		NombreCanal:   nombreCanal,
 		ChatbotID:     chatbotID,
 	}
@@ -257,6 +262,9 @@ func (ctrl *WhatsAppConfigController) Update(c *gin.Context) {
 	if req.VerifyToken != "" {
 		existing.VerifyToken = req.VerifyToken
 	}
+	if req.AppSecret != "" {
+		existing.AppSecret = req.AppSecret
+	}
 	existing.NombreCanal = nombreCanal
 	existing.ChatbotID = chatbotID
 	existing.Activo = req.Activo
@@ -1,15 +1,18 @@
 package controller
 
 import (
-	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
 	"libro-reclamaciones/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -19,15 +22,24 @@ import (
 type WhatsAppController struct {
 	configuracion   config.WhatsAppConfig
 	whatsappService *service.WhatsAppService
+	webhookService  *service.WebhookWhatsAppService
+	colaService     *service.ColaWhatsAppService
+	outboxService   *service.OutboxService
 }
 
 func NewWhatsAppController(
 	configuracion config.WhatsAppConfig,
 	whatsappService *service.WhatsAppService,
+	webhookService *service.WebhookWhatsAppService,
+	colaService *service.ColaWhatsAppService,
+	outboxService *service.OutboxService,
 ) *WhatsAppController {
 	return &WhatsAppController{
 		configuracion:   configuracion,
 		whatsappService: whatsappService,
+		webhookService:  webhookService,
+		colaService:     colaService,
+		outboxService:   outboxService,
 	}
 }
 
@@ -38,6 +50,23 @@ type payloadWebhookMeta struct {
 	Entry  []entradaWebhook `json:"entry"`
 }
 
+// phoneNumberIDs números del payload (sin repetir), para validar la firma con
+// el secreto de cada canal.
+func (p payloadWebhookMeta) phoneNumberIDs() []string {
+	var ids []string
+	vistos := make(map[string]bool)
+	for _, entrada := range p.Entry {
+		for _, cambio := range entrada.Changes {
+			id := cambio.Value.Metadata.PhoneNumberID
+			if id != "" && !vistos[id] {
+				vistos[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
 type entradaWebhook struct {
 	ID      string          `json:"id"`
 	Changes []cambioWebhook `json:"changes"`
@@ -52,7 +81,7 @@ type valorCambio struct {
 	MessagingProduct string            `json:"messaging_product"`
 	Metadata         metadataTelefono  `json:"metadata"`
 	Messages         []mensajeEntrante `json:"messages"`
-	Statuses         []interface{}     `json:"statuses"`
+	Statuses         []estadoMensaje   `json:"statuses"`
 }
 
 type metadataTelefono struct {
@@ -61,17 +90,91 @@ type metadataTelefono struct {
 }
 
 type mensajeEntrante struct {
-	From      string        `json:"from"`
-	ID        string        `json:"id"`
-	Timestamp string        `json:"timestamp"`
-	Type      string        `json:"type"`
-	Text      *textoMensaje `json:"text,omitempty"`
+	From        string              `json:"from"`
+	ID          string              `json:"id"`
+	Timestamp   string              `json:"timestamp"`
+	Type        string              `json:"type"`
+	Text        *textoMensaje       `json:"text,omitempty"`
+	Image       *mediaMensaje       `json:"image,omitempty"`
+	Document    *mediaMensaje       `json:"document,omitempty"`
+	Audio       *mediaMensaje       `json:"audio,omitempty"`
+	Location    *ubicacionMensaje   `json:"location,omitempty"`
+	Interactive *interactivoMensaje `json:"interactive,omitempty"`
+	Button      *botonMensaje       `json:"button,omitempty"`
 }
 
 type textoMensaje struct {
 	Body string `json:"body"`
 }
 
+// mediaMensaje imagen, documento o audio. El archivo se descarga con el ID.
+type mediaMensaje struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Filename string `json:"filename,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Voice    bool   `json:"voice,omitempty"`
+}
+
+type ubicacionMensaje struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// interactivoMensaje respuesta a botones (button_reply) o listas (list_reply).
+type interactivoMensaje struct {
+	Type        string         `json:"type"`
+	ButtonReply *opcionElegida `json:"button_reply,omitempty"`
+	ListReply   *opcionElegida `json:"list_reply,omitempty"`
+}
+
+type opcionElegida struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// botonMensaje respuesta rápida a una plantilla.
+type botonMensaje struct {
+	Payload string `json:"payload"`
+	Text    string `json:"text"`
+}
+
+// estadoMensaje entrega de un mensaje enviado: sent, delivered, read o failed.
+type estadoMensaje struct {
+	ID          string        `json:"id"`
+	Status      string        `json:"status"`
+	Timestamp   string        `json:"timestamp"`
+	RecipientID string        `json:"recipient_id"`
+	Errors      []errorEstado `json:"errors,omitempty"`
+}
+
+type errorEstado struct {
+	Code      int    `json:"code"`
+	Title     string `json:"title"`
+	Message   string `json:"message"`
+	ErrorData struct {
+		Details string `json:"details"`
+	} `json:"error_data"`
+}
+
+// detalle motivo legible de un estado failed ("" si no hay errores).
+func (e estadoMensaje) detalle() string {
+	if len(e.Errors) == 0 {
+		return ""
+	}
+	err := e.Errors[0]
+	texto := err.ErrorData.Details
+	if texto == "" {
+		texto = err.Message
+	}
+	if texto == "" {
+		texto = err.Title
+	}
+	return fmt.Sprintf("%d: %s", err.Code, texto)
+}
+
 // ── GET /webhook/whatsapp — Verificación del webhook por Meta ───────────────
 
 func (ctrl *WhatsAppController) VerificarWebhook(c *gin.Context) {
@@ -92,20 +195,57 @@ func (ctrl *WhatsAppController) VerificarWebhook(c *gin.Context) {
 // ── POST /webhook/whatsapp — Recepción de mensajes entrantes ────────────────
 
 func (ctrl *WhatsAppController) RecibirMensajeEntrante(c *gin.Context) {
-	defer c.JSON(http.StatusOK, gin.H{"status": "ok"})
-
 	cuerpo, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		fmt.Printf("[WhatsApp] Error leyendo body: %v\n", err)
+		c.JSON(http.StatusBadRequest, gin.H{"status": "error"})
 		return
 	}
 
+	// Sin JSON válido no hay números: solo puede validar el secreto global
 	var payload payloadWebhookMeta
-	if err := json.Unmarshal(cuerpo, &payload); err != nil {
-		fmt.Printf("[WhatsApp] Error parseando JSON: %v\n", err)
+	errJSON := json.Unmarshal(cuerpo, &payload)
+
+	// ── FIRMA DE META: nada se procesa sin X-Hub-Signature-256 válida ──
+	ctx, cancelar := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	err = ctrl.webhookService.VerificarFirma(ctx, cuerpo, c.GetHeader(service.CabeceraFirmaMeta), payload.phoneNumberIDs())
+	cancelar()
+	if err != nil {
+		fmt.Printf("[WhatsApp] Webhook rechazado desde %s: %v\n", c.ClientIP(), err)
+		helper.Error(c, err)
 		return
 	}
 
+	if errJSON != nil {
+		fmt.Printf("[WhatsApp] Error parseando JSON: %v\n", errJSON)
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
+
+	// Se encola y se responde de inmediato: la IA corre en los workers de la
+	// cola. Si no se pudo encolar se responde 500 para que Meta reintente; los
+	// mensajes ya encolados se ignoran en el reintento.
+	ctx, cancelar = context.WithTimeout(c.Request.Context(), 10*time.Second)
+	encolados, err := ctrl.encolarPayload(ctx, payload)
+	if err == nil {
+		// Los estados son idempotentes: si Meta reintenta se aplican de nuevo
+		err = ctrl.registrarEstados(ctx, payload)
+	}
+	cancelar()
+	if encolados > 0 {
+		ctrl.colaService.Despertar()
+	}
+	if err != nil {
+		fmt.Printf("[WhatsApp] Error procesando webhook: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "error"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// encolarPayload resuelve el canal de cada número y encola sus mensajes.
+func (ctrl *WhatsAppController) encolarPayload(ctx context.Context, payload payloadWebhookMeta) (int, error) {
+	encolados := 0
 	for _, entrada := range payload.Entry {
 		for _, cambio := range entrada.Changes {
 			if len(cambio.Value.Messages) == 0 {
@@ -115,13 +255,9 @@ func (ctrl *WhatsAppController) RecibirMensajeEntrante(c *gin.Context) {
 			phoneNumberID := cambio.Value.Metadata.PhoneNumberID
 
 			// ── RESOLUCIÓN DINÁMICA DEL TENANT ──
-			ctx, cancelar := context.WithTimeout(context.Background(), 5*time.Second)
 			canalResuelto, err := ctrl.whatsappService.ResolverCanalPorPhoneNumberID(ctx, phoneNumberID)
-			cancelar()
-
 			if err != nil {
-				fmt.Printf("[WhatsApp] Error resolviendo canal para phone_number_id=%s: %v\n", phoneNumberID, err)
-				continue
+				return encolados, err
 			}
 
 			if canalResuelto == nil {
@@ -129,86 +265,131 @@ func (ctrl *WhatsAppController) RecibirMensajeEntrante(c *gin.Context) {
 				continue
 			}
 
-			fmt.Printf("[WhatsApp] Canal resuelto → tenant=%s phone=%s chatbot=%v\n",
-				canalResuelto.TenantID, canalResuelto.PhoneID, canalResuelto.ChatbotID)
-
-			// ── PROCESAR CADA MENSAJE ──
 			for _, mensaje := range cambio.Value.Messages {
-				if mensaje.Type != "text" || mensaje.Text == nil {
-					ctrl.enviarMensajeDeTexto(
-						canalResuelto.PhoneID, canalResuelto.AccessToken,
-						mensaje.From,
-						"Por ahora solo puedo procesar mensajes de texto. "+
-							"Envíame tu consulta escrita y te ayudaré. 📝",
-					)
+				// ── IDEMPOTENCIA: Meta reintenta el mismo wamid ──
+				nuevo, err := ctrl.webhookService.RegistrarMensaje(ctx, canalResuelto, mensaje.ID)
+				if err != nil {
+					return encolados, err
+				}
+				if !nuevo {
+					fmt.Printf("[WhatsApp] Mensaje %s ya recibido — reintento ignorado\n", mensaje.ID)
 					continue
 				}
 
-				fmt.Printf("[WhatsApp] Mensaje de %s: %s\n", mensaje.From, mensaje.Text.Body)
-
-				// ── PROCESAR CON IA + MEMORIA (timeout largo para IA) ──
-				ctx, cancelar := context.WithTimeout(context.Background(), 30*time.Second)
-				respuesta := ctrl.whatsappService.ProcesarMensaje(
-					ctx,
-					canalResuelto, // ahora pasa el canal completo (incluye ChatbotID)
-					mensaje.From,
-					mensaje.Text.Body,
-				)
-				cancelar()
-
-				if respuesta != "" {
-					ctrl.enviarMensajeDeTexto(
-						canalResuelto.PhoneID, canalResuelto.AccessToken,
-						mensaje.From, respuesta,
-					)
+				entrante := nuevoMensajeEntrante(canalResuelto, mensaje)
+				if _, err := ctrl.colaService.Encolar(ctx, entrante); err != nil {
+					// Sin encolar no debe quedar como recibido, o el reintento de Meta se ignoraría
+					if errLiberar := ctrl.webhookService.LiberarMensaje(context.WithoutCancel(ctx), canalResuelto, mensaje.ID); errLiberar != nil {
+						fmt.Printf("[ERROR] WhatsApp LiberarMensaje %s: %v\n", mensaje.ID, errLiberar)
+					}
+					return encolados, err
 				}
+				encolados++
 			}
 		}
 	}
+	return encolados, nil
 }
 
-// ── Envío de mensaje vía WhatsApp Cloud API ─────────────────────────────────
+// registrarEstados aplica los estados de entrega (enviado, entregado, leído,
+// fallido) a las notificaciones del outbox que salieron por WhatsApp.
+func (ctrl *WhatsAppController) registrarEstados(ctx context.Context, payload payloadWebhookMeta) error {
+	for _, entrada := range payload.Entry {
+		for _, cambio := range entrada.Changes {
+			phoneNumberID := cambio.Value.Metadata.PhoneNumberID
+			if len(cambio.Value.Statuses) == 0 || phoneNumberID == "" || ctrl.outboxService == nil {
+				continue
+			}
 
-func (ctrl *WhatsAppController) enviarMensajeDeTexto(phoneID, accessToken, destinatario, texto string) {
-	urlAPI := fmt.Sprintf("https://graph.facebook.com/v22.0/%s/messages", phoneID)
+			canalResuelto, err := ctrl.whatsappService.ResolverCanalPorPhoneNumberID(ctx, phoneNumberID)
+			if err != nil {
+				return err
+			}
+			if canalResuelto == nil {
+				continue
+			}
 
-	cuerpoJSON := map[string]interface{}{
-		"messaging_product": "whatsapp",
-		"to":                destinatario,
-		"type":              "text",
-		"text": map[string]string{
-			"body": texto,
-		},
+			for _, estado := range cambio.Value.Statuses {
+				fecha := time.Now()
+				if seg, err := strconv.ParseInt(estado.Timestamp, 10, 64); err == nil && seg > 0 {
+					fecha = time.Unix(seg, 0)
+				}
+				if err := ctrl.outboxService.RegistrarEstadoWhatsApp(ctx, canalResuelto.TenantID, estado.ID, estado.Status, fecha, estado.detalle()); err != nil {
+					return err
+				}
+			}
+		}
 	}
+	return nil
+}
 
-	datosJSON, err := json.Marshal(cuerpoJSON)
-	if err != nil {
-		fmt.Printf("[WhatsApp] Error serializando payload: %v\n", err)
-		return
+// nuevoMensajeEntrante arma el registro de la cola a partir del mensaje de Meta.
+func nuevoMensajeEntrante(canal *service.CanalResuelto, mensaje mensajeEntrante) *model.MensajeWhatsAppEntrante {
+	entrante := &model.MensajeWhatsAppEntrante{
+		TenantModel:   model.TenantModel{TenantID: canal.TenantID},
+		PhoneNumberID: canal.PhoneID,
+		MensajeID:     mensaje.ID,
+		Telefono:      mensaje.From,
+		Tipo:          mensaje.Type,
+		FechaMensaje:  time.Now(),
+	}
+	if mensaje.Text != nil {
+		entrante.Texto = model.NullString{NullString: sql.NullString{String: mensaje.Text.Body, Valid: true}}
 	}
+	entrante.Contenido = contenidoMensaje(mensaje)
+	if seg, err := strconv.ParseInt(mensaje.Timestamp, 10, 64); err == nil && seg > 0 {
+		entrante.FechaMensaje = time.Unix(seg, 0)
+	}
+	return entrante
+}
 
-	peticion, err := http.NewRequest("POST", urlAPI, bytes.NewBuffer(datosJSON))
-	if err != nil {
-		fmt.Printf("[WhatsApp] Error creando request: %v\n", err)
-		return
+// contenidoMensaje datos de los mensajes que no son texto (nil si no hay).
+func contenidoMensaje(mensaje mensajeEntrante) *model.ContenidoWhatsApp {
+	media := mensaje.Image
+	switch {
+	case mensaje.Document != nil:
+		media = mensaje.Document
+	case mensaje.Audio != nil:
+		media = mensaje.Audio
 	}
 
-	peticion.Header.Set("Content-Type", "application/json")
-	peticion.Header.Set("Authorization", "Bearer "+accessToken)
+	switch {
+	case media != nil:
+		return &model.ContenidoWhatsApp{
+			MediaID: media.ID,
+			MIME:    media.MimeType,
+			Nombre:  media.Filename,
+			Caption: media.Caption,
+			Voz:     media.Voice,
+		}
 
-	cliente := &http.Client{Timeout: 15 * time.Second}
-	respuesta, err := cliente.Do(peticion)
-	if err != nil {
-		fmt.Printf("[WhatsApp] Error enviando mensaje a %s: %v\n", destinatario, err)
-		return
-	}
-	defer respuesta.Body.Close()
+	case mensaje.Location != nil:
+		return &model.ContenidoWhatsApp{
+			Latitud:   mensaje.Location.Latitude,
+			Longitud:  mensaje.Location.Longitude,
+			Lugar:     mensaje.Location.Name,
+			Direccion: mensaje.Location.Address,
+		}
 
-	if respuesta.StatusCode != http.StatusOK {
-		cuerpoRespuesta, _ := io.ReadAll(respuesta.Body)
-		fmt.Printf("[WhatsApp] Meta respondió %d: %s\n", respuesta.StatusCode, string(cuerpoRespuesta))
-		return
+	case mensaje.Interactive != nil:
+		opcion := mensaje.Interactive.ButtonReply
+		if opcion == nil {
+			opcion = mensaje.Interactive.ListReply
+		}
+		if opcion == nil {
+			return nil
+		}
+		return &model.ContenidoWhatsApp{OpcionID: opcion.ID, OpcionTitulo: opcion.Title}
+
+	case mensaje.Button != nil:
+		return &model.ContenidoWhatsApp{OpcionID: mensaje.Button.Payload, OpcionTitulo: mensaje.Button.Text}
 	}
+	return nil
+}
 
-	fmt.Printf("[WhatsApp] Mensaje enviado a %s\n", destinatario)
-}
\ No newline at end of file
+// ── GET /api/v1/admin/webhooks/whatsapp/metricas ───────────────────────────
+
+// Metricas webhooks aceptados y rechazados (firma, duplicados) de esta réplica.
+func (ctrl *WhatsAppController) Metricas(c *gin.Context) {
+	helper.Success(c, ctrl.webhookService.Metricas())
+}
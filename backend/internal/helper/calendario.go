@@ -0,0 +1,223 @@
+package helper
+
+import (
+	"time"
+)
+
+// ZonaHorariaLima es la zona horaria oficial para contar plazos INDECOPI.
+const ZonaHorariaLima = "America/Lima"
+
+// CalendarioSLA cuenta plazos de respuesta en días hábiles.
+// Excluye fines de semana (configurables) y feriados nacionales + del tenant.
+// Es inmutable una vez construido: se puede compartir entre goroutines.
+type CalendarioSLA struct {
+	ubicacion    *time.Location
+	diasHabiles  bool                  // false = cuenta días calendario (comportamiento anterior)
+	noLaborables map[time.Weekday]bool // ej: sábado y domingo
+	nacionales   bool                  // incluir feriados nacionales del Perú
+	feriados     map[string]bool       // "2006-01-02" → feriado puntual del tenant
+	recurrentes  map[string]bool       // "01-02" → feriado que se repite cada año
+}
+
+// OpcionesCalendario parámetros para construir un CalendarioSLA.
+type OpcionesCalendario struct {
+	ZonaHoraria               string
+	DiasHabiles               bool
+	DiasNoLaborables          []time.Weekday
+	IncluirFeriadosNacionales bool
+	Feriados                  []time.Time // feriados puntuales (solo importa la fecha)
+	FeriadosRecurrentes       []time.Time // feriados anuales (solo importa mes y día)
+}
+
+// NewCalendarioSLA construye un calendario a partir de las opciones.
+func NewCalendarioSLA(op OpcionesCalendario) *CalendarioSLA {
+	c := &CalendarioSLA{
+		ubicacion:    CargarZonaHoraria(op.ZonaHoraria),
+		diasHabiles:  op.DiasHabiles,
+		noLaborables: make(map[time.Weekday]bool, len(op.DiasNoLaborables)),
+		nacionales:   op.IncluirFeriadosNacionales,
+		feriados:     make(map[string]bool, len(op.Feriados)),
+		recurrentes:  make(map[string]bool, len(op.FeriadosRecurrentes)),
+	}
+	for _, d := range op.DiasNoLaborables {
+		c.noLaborables[d] = true
+	}
+	for _, f := range op.Feriados {
+		c.feriados[f.Format("2006-01-02")] = true
+	}
+	for _, f := range op.FeriadosRecurrentes {
+		c.recurrentes[f.Format("01-02")] = true
+	}
+	return c
+}
+
+// CalendarioPorDefecto retorna el calendario legal estándar:
+// días hábiles, Lima, sábado/domingo no laborables y feriados nacionales.
+func CalendarioPorDefecto() *CalendarioSLA {
+	return NewCalendarioSLA(OpcionesCalendario{
+		ZonaHoraria:               ZonaHorariaLima,
+		DiasHabiles:               true,
+		DiasNoLaborables:          []time.Weekday{time.Saturday, time.Sunday},
+		IncluirFeriadosNacionales: true,
+	})
+}
+
+// CargarZonaHoraria carga una zona IANA. Si el sistema no trae tzdata,
+// usa UTC-5 fijo para Lima (Perú no aplica horario de verano).
+func CargarZonaHoraria(nombre string) *time.Location {
+	if nombre == "" {
+		nombre = ZonaHorariaLima
+	}
+	if loc, err := time.LoadLocation(nombre); err == nil {
+		return loc
+	}
+	if nombre == ZonaHorariaLima {
+		return time.FixedZone("PET", -5*60*60)
+	}
+	return time.UTC
+}
+
+// Ubicacion retorna la zona horaria del calendario.
+func (c *CalendarioSLA) Ubicacion() *time.Location {
+	return c.ubicacion
+}
+
+// CuentaDiasHabiles indica si el calendario cuenta días hábiles.
+func (c *CalendarioSLA) CuentaDiasHabiles() bool {
+	return c.diasHabiles
+}
+
+// EsHabil indica si la fecha (en la zona del calendario) es día hábil.
+func (c *CalendarioSLA) EsHabil(t time.Time) bool {
+	d := c.fecha(t)
+	if c.noLaborables[d.Weekday()] {
+		return false
+	}
+	return !c.EsFeriado(d)
+}
+
+// EsFeriado indica si la fecha es feriado (nacional o del tenant).
+func (c *CalendarioSLA) EsFeriado(t time.Time) bool {
+	d := c.fecha(t)
+	if c.feriados[d.Format("2006-01-02")] || c.recurrentes[d.Format("01-02")] {
+		return true
+	}
+	return c.nacionales && EsFeriadoNacional(d)
+}
+
+// CalcularFechaLimite suma el plazo al día de registro.
+// En modo hábil, el día de registro no cuenta: el plazo empieza el siguiente día hábil.
+func (c *CalendarioSLA) CalcularFechaLimite(fechaRegistro time.Time, plazoDias int) time.Time {
+	d := c.fecha(fechaRegistro)
+	if !c.diasHabiles {
+		return d.AddDate(0, 0, plazoDias)
+	}
+	for sumados := 0; sumados < plazoDias; {
+		d = d.AddDate(0, 0, 1)
+		if c.EsHabil(d) {
+			sumados++
+		}
+	}
+	return d
+}
+
+// DiasRestantes cuenta los días (hábiles o calendario) entre hoy y la fecha límite.
+// fechaLimite se interpreta como fecha (columna DATE): no se convierte de zona.
+// Negativo si ya venció.
+func (c *CalendarioSLA) DiasRestantes(fechaLimite, ahora time.Time) int {
+	hoy := c.fecha(ahora)
+	limite := time.Date(fechaLimite.Year(), fechaLimite.Month(), fechaLimite.Day(), 0, 0, 0, 0, c.ubicacion)
+	if !c.diasHabiles {
+		return int(limite.Sub(hoy).Hours() / 24)
+	}
+	if limite.Equal(hoy) {
+		return 0
+	}
+	desde, hasta, signo := hoy, limite, 1
+	if limite.Before(hoy) {
+		desde, hasta, signo = limite, hoy, -1
+	}
+	dias := 0
+	for d := desde.AddDate(0, 0, 1); !d.After(hasta); d = d.AddDate(0, 0, 1) {
+		if c.EsHabil(d) {
+			dias++
+		}
+	}
+	// Venció en fin de semana/feriado: igual cuenta como vencido.
+	if signo < 0 && dias == 0 {
+		dias = 1
+	}
+	return signo * dias
+}
+
+// Prioridad clasifica un reclamo según los días restantes del calendario.
+func (c *CalendarioSLA) Prioridad(fechaLimite time.Time, estado string, ahora time.Time) string {
+	return PrioridadPorDias(c.DiasRestantes(fechaLimite, ahora), estado, DiasUmbralUrgente)
+}
+
+// fecha normaliza un instante a la medianoche local del calendario.
+func (c *CalendarioSLA) fecha(t time.Time) time.Time {
+	l := t.In(c.ubicacion)
+	return time.Date(l.Year(), l.Month(), l.Day(), 0, 0, 0, 0, c.ubicacion)
+}
+
+// ─── Feriados nacionales del Perú ───────────────────────────────────────────
+
+type feriadoFijo struct {
+	mes   time.Month
+	dia   int
+	desde int // primer año en que aplica (0 = siempre)
+}
+
+// feriadosNacionalesFijos feriados de fecha fija (D.L. 713 y leyes posteriores).
+var feriadosNacionalesFijos = []feriadoFijo{
+	{time.January, 1, 0},     // Año Nuevo
+	{time.May, 1, 0},         // Día del Trabajo
+	{time.June, 7, 2023},     // Batalla de Arica y Día de la Bandera
+	{time.June, 29, 0},       // San Pedro y San Pablo
+	{time.July, 23, 2023},    // Día de la Fuerza Aérea del Perú
+	{time.July, 28, 0},       // Fiestas Patrias
+	{time.July, 29, 0},       // Fiestas Patrias
+	{time.August, 6, 2024},   // Batalla de Junín
+	{time.August, 30, 0},     // Santa Rosa de Lima
+	{time.October, 8, 0},     // Combate de Angamos
+	{time.November, 1, 0},    // Todos los Santos
+	{time.December, 8, 0},    // Inmaculada Concepción
+	{time.December, 9, 2022}, // Batalla de Ayacucho
+	{time.December, 25, 0},   // Navidad
+}
+
+// EsFeriadoNacional indica si la fecha es feriado nacional en el Perú,
+// incluyendo Jueves y Viernes Santo.
+func EsFeriadoNacional(t time.Time) bool {
+	anio, mes, dia := t.Date()
+	for _, f := range feriadosNacionalesFijos {
+		if f.mes == mes && f.dia == dia && anio >= f.desde {
+			return true
+		}
+	}
+	pascua := domingoDePascua(anio)
+	jueves := pascua.AddDate(0, 0, -3)
+	viernes := pascua.AddDate(0, 0, -2)
+	return (mes == jueves.Month() && dia == jueves.Day()) ||
+		(mes == viernes.Month() && dia == viernes.Day())
+}
+
+// domingoDePascua calcula el Domingo de Pascua (algoritmo anónimo gregoriano).
+func domingoDePascua(anio int) time.Time {
+	a := anio % 19
+	b := anio / 100
+	c := anio % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	mes := (h + l - 7*m + 114) / 31
+	dia := (h+l-7*m+114)%31 + 1
+	return time.Date(anio, time.Month(mes), dia, 0, 0, 0, 0, time.UTC)
+}
@@ -1,27 +1,142 @@
 package helper
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"math/big"
+	"strconv"
 	"strings"
 	"time"
 )
 
-// GenerateCodigoReclamo genera un código único para un reclamo.
-// Formato: {AÑO}-{SLUG_TENANT}-{SLUG_SEDE}-{TIMESTAMP_SHORT}
-// Ejemplo: 2026-POLLREY-MIR-A3F5B2
-//
-// No usa secuencias de DB (anti-pattern en CockroachDB).
-// Usa timestamp en base36 para unicidad sin coordinación.
-func GenerateCodigoReclamo(tenantSlug, sedeSlug string) string {
-	year := time.Now().Year()
-	shortTenant := sanitizeSlug(tenantSlug, 8)
-	shortSede := sanitizeSlug(sedeSlug, 4)
-	uniquePart := generateShortID()
-
-	if shortSede != "" {
-		return fmt.Sprintf("%d-%s-%s-%s", year, shortTenant, shortSede, uniquePart)
-	}
-	return fmt.Sprintf("%d-%s-%s", year, shortTenant, uniquePart)
+// Marcadores del formato de código de reclamo configurable por tenant.
+const (
+	MarcadorAnio      = "{AAAA}"      // año de registro
+	MarcadorTenant    = "{TENANT}"    // slug del tenant (máx. 8)
+	MarcadorSede      = "{SEDE}"      // slug de la sede (máx. 4); se omite sin sede
+	MarcadorAleatorio = "{ALEATORIO}" // parte aleatoria, obligatoria
+	MarcadorDigito    = "{DV}"        // dígito verificador, opcional y al final
+)
+
+// FormatoCodigoPorDefecto formato histórico: 2026-POLLREY-MIR-7KQ9XM4T.
+const FormatoCodigoPorDefecto = MarcadorAnio + "-" + MarcadorTenant + "-" + MarcadorSede + "-" + MarcadorAleatorio
+
+const (
+	// alfabetoAleatorio sin 0/O, 1/I/L ni U para que el código se dicte y
+	// transcriba sin confusiones.
+	alfabetoAleatorio = "23456789ABCDEFGHJKMNPQRSTVWXYZ"
+	longitudAleatorio = 8 // 30^8 ≈ 6,5·10^11 combinaciones por tenant y prefijo
+	maxLongitudCodigo = 40
+)
+
+// parecidosVerificador caracteres fuera de alfabetoAleatorio (solo aparecen
+// en el año y los slugs) y el carácter del alfabeto por el que cuentan en el
+// dígito verificador: confundirlos al dictar no cambia el dígito.
+var parecidosVerificador = strings.NewReplacer("0", "Q", "O", "Q", "1", "J", "I", "J", "L", "J", "U", "V")
+
+// GenerateCodigoReclamo genera un código de reclamo según el formato del
+// tenant (vacío = FormatoCodigoPorDefecto). La parte aleatoria sale de
+// crypto/rand: no se puede deducir un código a partir de otro ni de la hora
+// de registro. La unicidad se verifica contra la BD al registrar.
+func GenerateCodigoReclamo(formato, tenantSlug, sedeSlug string) (string, error) {
+	if formato == "" {
+		formato = FormatoCodigoPorDefecto
+	}
+	aleatorio, err := generateShortID()
+	if err != nil {
+		return "", err
+	}
+
+	codigo := strings.NewReplacer(
+		MarcadorAnio, strconv.Itoa(time.Now().Year()),
+		MarcadorTenant, sanitizeSlug(tenantSlug, 8),
+		MarcadorSede, sanitizeSlug(sedeSlug, 4),
+		MarcadorAleatorio, aleatorio,
+	).Replace(formato)
+
+	// Sin sede (o slug vacío) no quedan separadores dobles ni en los extremos
+	for strings.Contains(codigo, "--") {
+		codigo = strings.ReplaceAll(codigo, "--", "-")
+	}
+
+	if strings.HasSuffix(codigo, MarcadorDigito) {
+		base := strings.TrimSuffix(codigo, MarcadorDigito)
+		codigo = base + string(DigitoVerificador(base))
+	}
+	return strings.Trim(codigo, "-"), nil
+}
+
+// ValidarFormatoCodigo verifica un formato de código: solo marcadores,
+// letras mayúsculas, dígitos y guiones; {ALEATORIO} obligatorio, cada
+// marcador una vez, {DV} al final y un código resultante de hasta 40
+// caracteres.
+func ValidarFormatoCodigo(formato string) error {
+	if !strings.Contains(formato, MarcadorAleatorio) {
+		return errors.New("debe incluir " + MarcadorAleatorio)
+	}
+	if strings.Contains(formato, MarcadorDigito) && !strings.HasSuffix(formato, MarcadorDigito) {
+		return errors.New(MarcadorDigito + " debe ir al final")
+	}
+
+	resto, longitud := formato, 0
+	for _, m := range []struct {
+		marcador string
+		largo    int
+	}{
+		{MarcadorAnio, 4}, {MarcadorTenant, 8}, {MarcadorSede, 4},
+		{MarcadorAleatorio, longitudAleatorio}, {MarcadorDigito, 1},
+	} {
+		switch strings.Count(resto, m.marcador) {
+		case 0:
+		case 1:
+			resto = strings.Replace(resto, m.marcador, "", 1)
+			longitud += m.largo
+		default:
+			return fmt.Errorf("%s aparece más de una vez", m.marcador)
+		}
+	}
+
+	for _, r := range resto {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("carácter no permitido: %q (usa mayúsculas, dígitos, guiones y marcadores)", r)
+		}
+	}
+	if longitud += len(resto); longitud > maxLongitudCodigo {
+		return fmt.Errorf("el código resultante puede tener hasta %d caracteres y este llega a %d", maxLongitudCodigo, longitud)
+	}
+	return nil
+}
+
+// DigitoVerificador carácter de control ISO 7064 MOD 31,30 sobre las letras y
+// dígitos de s (los guiones se ignoran). Usa el mismo alfabeto sin ambiguos
+// que la parte aleatoria, así que el dígito tampoco se confunde al dictarlo.
+// Detecta cualquier carácter cambiado y la mayoría de transposiciones.
+func DigitoVerificador(s string) byte {
+	const m = len(alfabetoAleatorio)
+	p := m
+	for _, r := range parecidosVerificador.Replace(strings.ToUpper(s)) {
+		v := strings.IndexRune(alfabetoAleatorio, r)
+		if v < 0 {
+			continue
+		}
+		p = (p + v) % m
+		if p == 0 {
+			p = m
+		}
+		p = (p * 2) % (m + 1)
+	}
+	return alfabetoAleatorio[(m+1-p)%m]
+}
+
+// DigitoVerificadorValido indica si el último carácter del código es el
+// dígito verificador del resto. Solo aplica a formatos que terminan en {DV}.
+func DigitoVerificadorValido(codigo string) bool {
+	if len(codigo) < 2 {
+		return false
+	}
+	n := len(codigo) - 1
+	return strings.ToUpper(codigo[n:])[0] == DigitoVerificador(codigo[:n])
 }
 
 // sanitizeSlug convierte un slug a mayúsculas y lo recorta.
@@ -33,16 +148,16 @@ func sanitizeSlug(slug string, maxLen int) string {
 	return clean
 }
 
-// generateShortID genera un ID corto basado en timestamp + random.
-// Usa nanosegundos en base36 para obtener 6 caracteres únicos.
-func generateShortID() string {
-	now := time.Now().UnixNano()
-	// Tomamos los últimos 6 dígitos del timestamp en base36
-	const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	result := make([]byte, 6)
-	for i := 5; i >= 0; i-- {
-		result[i] = chars[now%36]
-		now /= 36
-	}
-	return string(result)
+// generateShortID parte aleatoria del código con crypto/rand.
+func generateShortID() (string, error) {
+	base := big.NewInt(int64(len(alfabetoAleatorio)))
+	result := make([]byte, longitudAleatorio)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, base)
+		if err != nil {
+			return "", fmt.Errorf("code_generator: %w", err)
+		}
+		result[i] = alfabetoAleatorio[n.Int64()]
+	}
+	return string(result), nil
 }
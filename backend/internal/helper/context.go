@@ -3,6 +3,8 @@ package helper
 import (
 	"errors"
 
+	"libro-reclamaciones/internal/model"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -16,6 +18,8 @@ const (
 	CtxAPIKeyID  = "api_key_id"
 	CtxSedeID    = "sede_id"
 	CtxIPAddress = "ip_address"
+
+	CtxConsumidor = "consumidor"
 )
 
 // GetTenantID extrae el tenant_id del contexto. Falla si no existe.
@@ -55,6 +59,20 @@ func GetUserSedeID(c *gin.Context) *uuid.UUID {
 	return &id
 }
 
+// GetIdentidadConsumidor extrae el consumidor autenticado en el portal.
+// Retorna nil si el request no trae un token de consumidor válido.
+func GetIdentidadConsumidor(c *gin.Context) *model.IdentidadConsumidor {
+	val, exists := c.Get(CtxConsumidor)
+	if !exists {
+		return nil
+	}
+	id, ok := val.(model.IdentidadConsumidor)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
 // GetClientIP retorna la IP real del cliente.
 func GetClientIP(c *gin.Context) string {
 	return c.ClientIP()
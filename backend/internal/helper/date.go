@@ -2,31 +2,39 @@ package helper
 
 import "time"
 
+// DiasUmbralUrgente días restantes a partir de los cuales un reclamo es URGENTE.
+const DiasUmbralUrgente = 3
+
+// calendarioDefecto calendario legal (días hábiles, Lima, feriados nacionales).
+var calendarioDefecto = CalendarioPorDefecto()
+
 // CalcularFechaLimite calcula la fecha límite de respuesta.
-// fecha_registro + plazo_respuesta_dias (días calendario).
+// fecha_registro + plazo_respuesta_dias (días hábiles, calendario por defecto).
+// Para usar el calendario del tenant ver CalendarioSLA.CalcularFechaLimite.
 func CalcularFechaLimite(fechaRegistro time.Time, plazoDias int) time.Time {
-	return fechaRegistro.AddDate(0, 0, plazoDias)
+	return calendarioDefecto.CalcularFechaLimite(fechaRegistro, plazoDias)
 }
 
-// DiasRestantes calcula cuántos días quedan para responder.
+// DiasRestantes calcula cuántos días hábiles quedan para responder.
 // Negativo si ya venció.
 func DiasRestantes(fechaLimite time.Time) int {
-	today := time.Now().Truncate(24 * time.Hour)
-	limite := fechaLimite.Truncate(24 * time.Hour)
-	diff := limite.Sub(today)
-	return int(diff.Hours() / 24)
+	return calendarioDefecto.DiasRestantes(fechaLimite, time.Now())
 }
 
 // Prioridad calcula la prioridad de un reclamo basado en días restantes.
 func Prioridad(fechaLimite time.Time, estado string) string {
+	return calendarioDefecto.Prioridad(fechaLimite, estado, time.Now())
+}
+
+// PrioridadPorDias clasifica según días restantes ya calculados.
+func PrioridadPorDias(dias int, estado string, umbralUrgente int) string {
 	if estado == "RESUELTO" || estado == "CERRADO" {
 		return "COMPLETADO"
 	}
-	dias := DiasRestantes(fechaLimite)
 	switch {
 	case dias < 0:
 		return "VENCIDO"
-	case dias <= 3:
+	case dias <= umbralUrgente:
 		return "URGENTE"
 	default:
 		return "EN_TIEMPO"
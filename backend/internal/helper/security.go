@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
@@ -68,4 +69,26 @@ func GenerateAPIKey(prefix, environment string) (string, string, error) {
 	keyPrefix := fullKey[:prefixLen]
 
 	return fullKey, keyPrefix, nil
-}
\ No newline at end of file
+}
+
+// --- WEBHOOKS DE META (HMAC-SHA256) ---
+
+// PrefijoFirmaMeta prefijo del header X-Hub-Signature-256.
+const PrefijoFirmaMeta = "sha256="
+
+// FirmarWebhookMeta firma un body como lo hace Meta: "sha256=" + HMAC-SHA256
+// del body en hex con el secreto de la app.
+func FirmarWebhookMeta(cuerpo []byte, secreto string) string {
+	mac := hmac.New(sha256.New, []byte(secreto))
+	mac.Write(cuerpo)
+	return PrefijoFirmaMeta + hex.EncodeToString(mac.Sum(nil))
+}
+
+// FirmaMetaValida compara en tiempo constante el header X-Hub-Signature-256
+// con la firma del body. Un secreto vacío nunca valida.
+func FirmaMetaValida(cuerpo []byte, firma, secreto string) bool {
+	if secreto == "" || !strings.HasPrefix(firma, PrefijoFirmaMeta) {
+		return false
+	}
+	return hmac.Equal([]byte(strings.ToLower(firma)), []byte(FirmarWebhookMeta(cuerpo, secreto)))
+}
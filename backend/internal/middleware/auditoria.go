@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Auditor registra una entrada de auditoría con el estado antes/después.
+// Lo implementa service.AuditoriaService (interfaz para evitar el ciclo de imports).
+type Auditor interface {
+	Registrar(ctx context.Context, a *model.Auditoria, antes, despues interface{}) error
+}
+
+// CargadorAuditoria lee el estado actual de la entidad. id puede venir vacío
+// (entidades únicas por tenant como la configuración o la suscripción).
+type CargadorAuditoria func(c *gin.Context, id string) (interface{}, error)
+
+// ReglaAuditoria describe qué se audita en una ruta.
+type ReglaAuditoria struct {
+	Entidad string
+	Accion  string
+	Param   string            // parámetro de ruta con el ID ("" = sin ID; en creaciones se usa data.id de la respuesta)
+	Cargar  CargadorAuditoria // nil = no se captura estado
+}
+
+// Auditoria registra la acción en auditoria_admin cuando el handler responde 2xx.
+// Captura el estado antes de ejecutar el handler y vuelve a leerlo después,
+// de modo que Detalles guarde solo lo que cambió. Si la ruta no trae el ID
+// (creación), se toma data.id de la respuesta. Los errores de auditoría solo
+// se loguean: nunca hacen fallar la operación ya ejecutada.
+func Auditoria(auditor Auditor, regla ReglaAuditoria) gin.HandlerFunc {
+	creacion := regla.Accion == model.AuditCrear || regla.Accion == model.AuditGenerarAPIKey
+
+	return func(c *gin.Context) {
+		entidadID := ""
+		if regla.Param != "" && !creacion {
+			entidadID = c.Param(regla.Param)
+		}
+
+		var antes interface{}
+		if regla.Cargar != nil && !creacion {
+			antes, _ = regla.Cargar(c, entidadID)
+		}
+
+		w := &respuestaCapturada{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() < 200 || w.Status() >= 300 {
+			return
+		}
+
+		if creacion {
+			entidadID = idDeRespuesta(w.body.Bytes())
+		}
+
+		var despues interface{}
+		if regla.Cargar != nil && (entidadID != "" || !creacion) {
+			despues, _ = regla.Cargar(c, entidadID)
+		}
+		if entidadID == "" {
+			entidadID = idDeEstado(despues, antes)
+		}
+
+		tenantID, err := helper.GetTenantID(c)
+		if err != nil {
+			return
+		}
+		userID, err := helper.GetUserID(c)
+		if err != nil {
+			return
+		}
+
+		a := &model.Auditoria{
+			TenantModel: model.TenantModel{TenantID: tenantID},
+			UsuarioID:   userID,
+			Accion:      regla.Accion,
+			Entidad:     regla.Entidad,
+			EntidadID:   model.NullString{NullString: sql.NullString{String: entidadID, Valid: entidadID != ""}},
+			IPAddress:   model.NullString{NullString: sql.NullString{String: helper.GetClientIP(c), Valid: true}},
+		}
+		if err := auditor.Registrar(c.Request.Context(), a, antes, despues); err != nil {
+			fmt.Printf("[WARN] auditoría %s %s: %v\n", regla.Entidad, regla.Accion, err)
+		}
+	}
+}
+
+// Auditar arma el middleware de auditoría de una ruta a partir de la entidad,
+// la acción y el parámetro de ruta con el ID.
+type Auditar func(entidad, accion, param string) gin.HandlerFunc
+
+// NuevoAuditar retorna un Auditar que usa el cargador registrado para cada entidad.
+func NuevoAuditar(auditor Auditor, cargadores map[string]CargadorAuditoria) Auditar {
+	return func(entidad, accion, param string) gin.HandlerFunc {
+		return Auditoria(auditor, ReglaAuditoria{
+			Entidad: entidad,
+			Accion:  accion,
+			Param:   param,
+			Cargar:  cargadores[entidad],
+		})
+	}
+}
+
+// respuestaCapturada copia el cuerpo de la respuesta para extraer el ID creado.
+type respuestaCapturada struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *respuestaCapturada) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *respuestaCapturada) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idDeRespuesta extrae data.id de una respuesta estándar (helper.Success/Created).
+func idDeRespuesta(body []byte) string {
+	var resp struct {
+		Data struct {
+			ID interface{} `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Data.ID == nil {
+		return ""
+	}
+	return fmt.Sprint(resp.Data.ID)
+}
+
+// idDeEstado toma el campo id del primer estado no vacío.
+func idDeEstado(estados ...interface{}) string {
+	for _, e := range estados {
+		if e == nil {
+			continue
+		}
+		if rv := reflect.ValueOf(e); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			continue
+		}
+		b, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		var m struct {
+			ID interface{} `json:"id"`
+		}
+		if json.Unmarshal(b, &m) == nil && m.ID != nil {
+			return fmt.Sprint(m.ID)
+		}
+	}
+	return ""
+}
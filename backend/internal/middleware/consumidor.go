@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// audienciaConsumidor distingue el token del portal del de los usuarios del
+// panel: AuthMiddleware lo rechaza (no trae user_id) y este middleware
+// rechaza los tokens del panel (no traen la audiencia).
+const audienciaConsumidor = "consumidor"
+
+// ConsumidorClaims estructura del JWT del portal del consumidor.
+type ConsumidorClaims struct {
+	TenantID string `json:"tenant_id"`
+	Canal    string `json:"canal"`
+	Destino  string `json:"destino"`
+	jwt.RegisteredClaims
+}
+
+// GenerateConsumidorToken crea el JWT del consumidor que verificó su código.
+func GenerateConsumidorToken(id model.IdentidadConsumidor, duracion time.Duration, jwtCfg config.JWTConfig) (string, error) {
+	now := time.Now()
+	claims := ConsumidorClaims{
+		TenantID: id.TenantID.String(),
+		Canal:    id.Canal,
+		Destino:  id.Destino,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{audienciaConsumidor},
+			ExpiresAt: jwt.NewNumericDate(now.Add(duracion)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtCfg.Secret))
+}
+
+// ConsumidorAuthMiddleware exige el token del portal del consumidor e inyecta
+// su identidad. El controller verifica que el tenant sea el del slug.
+func ConsumidorAuthMiddleware(jwtCfg config.JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := extractBearerToken(c)
+		if token == "" {
+			helper.Error(c, apperror.ErrTokenRequerido)
+			c.Abort()
+			return
+		}
+		if !cargarConsumidor(c, token, jwtCfg.Secret) {
+			helper.Error(c, apperror.ErrTokenInvalido)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ConsumidorOpcionalMiddleware inyecta la identidad si el request trae un
+// token de consumidor válido; si no, sigue como anónimo.
+func ConsumidorOpcionalMiddleware(jwtCfg config.JWTConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := extractBearerToken(c); token != "" {
+			cargarConsumidor(c, token, jwtCfg.Secret)
+		}
+		c.Next()
+	}
+}
+
+func cargarConsumidor(c *gin.Context, tokenStr, secret string) bool {
+	token, err := jwt.ParseWithClaims(tokenStr, &ConsumidorClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithAudience(audienciaConsumidor), jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return false
+	}
+
+	claims, ok := token.Claims.(*ConsumidorClaims)
+	if !ok || !token.Valid || claims.Destino == "" {
+		return false
+	}
+	tenantID, err := uuid.Parse(claims.TenantID)
+	if err != nil {
+		return false
+	}
+
+	helper.SetContext(c, helper.CtxConsumidor, model.IdentidadConsumidor{
+		TenantID: tenantID,
+		Canal:    claims.Canal,
+		Destino:  claims.Destino,
+	})
+	return true
+}
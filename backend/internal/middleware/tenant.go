@@ -5,8 +5,10 @@ import (
 
 	"libro-reclamaciones/internal/apperror"
 	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 // TenantMiddleware verifica que el tenant exista y esté activo.
@@ -59,3 +61,23 @@ func RoleMiddleware(allowedRoles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OperadorPlataformaMiddleware solo deja pasar a los ADMIN de los tenants que
+// operan la plataforma (config.PlataformaConfig). Para rutas con datos o
+// acciones de todos los tenants, que un ADMIN de un cliente no debe ver.
+func OperadorPlataformaMiddleware(tenantsOperadores []uuid.UUID) gin.HandlerFunc {
+	operadores := make(map[uuid.UUID]bool, len(tenantsOperadores))
+	for _, id := range tenantsOperadores {
+		operadores[id] = true
+	}
+
+	return func(c *gin.Context) {
+		tenantID, err := helper.GetTenantID(c)
+		if err != nil || !operadores[tenantID] || helper.GetUserRole(c) != model.RolAdmin {
+			helper.Error(c, apperror.ErrSoloOperadorPlataforma)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
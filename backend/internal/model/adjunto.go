@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Adjunto metadatos de un archivo subido. El contenido está en el storage.
+type Adjunto struct {
+	TenantModel
+	ReclamoID uuid.UUID `json:"reclamo_id" db:"reclamo_id"`
+	Entidad   string    `json:"entidad" db:"entidad"`
+	EntidadID NullUUID  `json:"entidad_id" db:"entidad_id"`
+
+	NombreOriginal string `json:"nombre_original" db:"nombre_original"`
+	Clave          string `json:"-" db:"clave"`
+	MimeType       string `json:"mime_type" db:"mime_type"`
+	TamanoBytes    int64  `json:"tamano_bytes" db:"tamano_bytes"`
+	SHA256         string `json:"sha256" db:"sha256"`
+
+	Origen    string   `json:"origen" db:"origen"`
+	SubidoPor NullUUID `json:"subido_por" db:"subido_por"`
+
+	FechaCreacion time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+}
+
+// Entidades a las que se adjunta un archivo.
+const (
+	AdjuntoEntidadReclamo   = "RECLAMO"
+	AdjuntoEntidadMensaje   = "MENSAJE"
+	AdjuntoEntidadRespuesta = "RESPUESTA"
+)
+
+// Origen de la subida.
+const (
+	AdjuntoOrigenPublico = "PUBLICO"
+	AdjuntoOrigenPanel   = "PANEL"
+)
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConfigAlertasSLA umbrales y destinatarios de las alertas SLA de un tenant.
+type ConfigAlertasSLA struct {
+	TenantID uuid.UUID `json:"tenant_id" db:"tenant_id"`
+
+	Activo      bool `json:"activo" db:"activo"`
+	DiasAviso   int  `json:"dias_aviso" db:"dias_aviso"`     // días hábiles restantes → URGENTE
+	ModoResumen bool `json:"modo_resumen" db:"modo_resumen"` // un email por destinatario
+
+	NotificarAsignado bool `json:"notificar_asignado" db:"notificar_asignado"`
+	NotificarSede     bool `json:"notificar_sede" db:"notificar_sede"`
+	NotificarEmpresa  bool `json:"notificar_empresa" db:"notificar_empresa"`
+
+	FechaActualizacion time.Time `json:"fecha_actualizacion" db:"fecha_actualizacion"`
+}
+
+// ConfigAlertasSLAPorDefecto valores usados si el tenant no configuró alertas.
+func ConfigAlertasSLAPorDefecto(tenantID uuid.UUID) *ConfigAlertasSLA {
+	return &ConfigAlertasSLA{
+		TenantID:          tenantID,
+		Activo:            true,
+		DiasAviso:         3,
+		ModoResumen:       true,
+		NotificarAsignado: true,
+		NotificarSede:     true,
+		NotificarEmpresa:  true,
+	}
+}
+
+// Tipos de alerta SLA.
+const (
+	AlertaSLAUrgente = "URGENTE"
+	AlertaSLAVencido = "VENCIDO"
+)
+
+// AlertaSLA un reclamo que requiere aviso.
+type AlertaSLA struct {
+	ReclamoID     uuid.UUID
+	Codigo        string
+	Consumidor    string
+	TipoSolicitud string
+	SedeNombre    string
+	Tipo          string // URGENTE | VENCIDO
+	FechaLimite   time.Time
+	DiasRestantes int
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AsistenteAccion acción de escritura propuesta por el asistente IA.
+// No se ejecuta hasta que el usuario que la pidió la confirma.
+type AsistenteAccion struct {
+	TenantModel
+	UsuarioID      uuid.UUID       `json:"usuario_id" db:"usuario_id"`
+	ConversacionID uuid.UUID       `json:"conversacion_id" db:"conversacion_id"`
+	ReclamoID      uuid.UUID       `json:"reclamo_id" db:"reclamo_id"`
+	Herramienta    string          `json:"herramienta" db:"herramienta"`
+	Argumentos     json.RawMessage `json:"argumentos" db:"argumentos"`
+	Resumen        string          `json:"resumen" db:"resumen"`
+	Estado         string          `json:"estado" db:"estado"`
+	Resultado      NullString      `json:"resultado" db:"resultado"`
+
+	FechaCreacion   time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaResolucion NullTime  `json:"fecha_resolucion" db:"fecha_resolucion"`
+}
+
+// Estados de una acción propuesta.
+const (
+	AccionAsistentePendiente = "PENDIENTE"
+	AccionAsistenteEjecutada = "EJECUTADA"
+	AccionAsistenteCancelada = "CANCELADA"
+	AccionAsistenteFallida   = "FALLIDA"
+)
+
+// VigenciaAccionAsistente tiempo para confirmar una acción propuesta.
+const VigenciaAccionAsistente = 30 * time.Minute
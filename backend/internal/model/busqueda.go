@@ -0,0 +1,30 @@
+package model
+
+// Facetas de la búsqueda de reclamos.
+const (
+	FacetaEstado        = "estado"
+	FacetaTipoSolicitud = "tipo_solicitud"
+	FacetaCanalOrigen   = "canal_origen"
+	FacetaSede          = "sede"
+	FacetaAtendidoPor   = "atendido_por"
+	FacetaPrioridad     = "prioridad"   // COMPLETADO, VENCIDO, URGENTE, EN_TIEMPO (según fecha límite)
+	FacetaCategoria     = "categoria"   // clasificación IA (taxonomía del tenant)
+	FacetaSentimiento   = "sentimiento" // clasificación IA
+	FacetaUrgencia      = "urgencia"    // clasificación IA (percibida en el relato)
+)
+
+// BusquedaReclamos resultado de una búsqueda con cursor.
+// SiguienteCursor vacío indica que no hay más resultados.
+type BusquedaReclamos struct {
+	Data            []Reclamo                `json:"data"`
+	Facetas         map[string][]FacetaValor `json:"facetas"`
+	SiguienteCursor string                   `json:"siguiente_cursor,omitempty"`
+}
+
+// FacetaValor conteo de un valor de faceta. Nombre es la etiqueta legible
+// cuando Valor es un ID (sede, usuario asignado).
+type FacetaValor struct {
+	Valor  string `json:"valor"`
+	Nombre string `json:"nombre,omitempty"`
+	Total  int    `json:"total"`
+}
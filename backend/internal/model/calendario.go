@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarioConfig reglas de días hábiles del tenant para el plazo de respuesta.
+// Si el tenant no tiene fila en calendarios_sla se usa el calendario legal por defecto.
+type CalendarioConfig struct {
+	TenantID                  uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	DiasHabiles               bool      `json:"dias_habiles" db:"dias_habiles"`
+	ZonaHoraria               string    `json:"zona_horaria" db:"zona_horaria"`
+	DiasNoLaborables          []int     `json:"dias_no_laborables" db:"dias_no_laborables"` // 0=domingo … 6=sábado
+	IncluirFeriadosNacionales bool      `json:"incluir_feriados_nacionales" db:"incluir_feriados_nacionales"`
+	Version                   int       `json:"version" db:"version"`
+
+	Timestamps
+}
+
+// Feriado día no laborable propio del tenant.
+type Feriado struct {
+	TenantModel
+
+	Fecha       time.Time `json:"fecha" db:"fecha"`
+	Descripcion string    `json:"descripcion" db:"descripcion"`
+	Recurrente  bool      `json:"recurrente" db:"recurrente"` // se repite cada año
+
+	FechaCreacion time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+}
+
+// CalendarioConfigPorDefecto configuración legal estándar (INDECOPI).
+func CalendarioConfigPorDefecto(tenantID uuid.UUID) *CalendarioConfig {
+	return &CalendarioConfig{
+		TenantID:                  tenantID,
+		DiasHabiles:               true,
+		ZonaHoraria:               "America/Lima",
+		DiasNoLaborables:          []int{int(time.Sunday), int(time.Saturday)},
+		IncluirFeriadosNacionales: true,
+	}
+}
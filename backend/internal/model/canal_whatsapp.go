@@ -13,6 +13,7 @@ type CanalWhatsApp struct {
 	DisplayPhone  string   `json:"display_phone" db:"display_phone"`
 	AccessToken   string   `json:"-" db:"access_token"`  // Nunca se serializa a JSON
 	VerifyToken   string   `json:"-" db:"verify_token"`   // Nunca se serializa a JSON
+	AppSecret     string   `json:"-" db:"app_secret"`     // Secreto de la app de Meta ('' = global)
 	NombreCanal   string   `json:"nombre_canal" db:"nombre_canal"`
 	ChatbotID     NullUUID `json:"chatbot_id" db:"chatbot_id"` // FK a chatbots — define prompt/modelo/temperatura
 	Activo        bool     `json:"activo" db:"activo"`
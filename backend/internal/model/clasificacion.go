@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CategoriaReclamo categoría de la taxonomía del tenant para la clasificación IA.
+type CategoriaReclamo struct {
+	TenantModel
+	Nombre             string     `json:"nombre" db:"nombre"`
+	Descripcion        NullString `json:"descripcion" db:"descripcion"`
+	Activo             bool       `json:"activo" db:"activo"`
+	FechaCreacion      time.Time  `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaActualizacion time.Time  `json:"fecha_actualizacion" db:"fecha_actualizacion"`
+}
+
+// Estados de la clasificación IA de un reclamo.
+const (
+	ClasificacionPendiente   = "PENDIENTE"
+	ClasificacionClasificado = "CLASIFICADO"
+	ClasificacionFallido     = "FALLIDO"
+)
+
+// Sentimientos del relato.
+const (
+	SentimientoNegativo = "NEGATIVO"
+	SentimientoNeutro   = "NEUTRO"
+	SentimientoPositivo = "POSITIVO"
+)
+
+// Urgencia percibida en el relato (distinta de la prioridad por fecha límite).
+const (
+	UrgenciaBaja    = "BAJA"
+	UrgenciaMedia   = "MEDIA"
+	UrgenciaAlta    = "ALTA"
+	UrgenciaCritica = "CRITICA"
+)
+
+// ReclamoPorClasificar datos que el worker envía a la IA.
+type ReclamoPorClasificar struct {
+	TenantID             uuid.UUID
+	ID                   uuid.UUID
+	CodigoReclamo        string
+	TipoSolicitud        string
+	AreaQueja            NullString
+	DescripcionBien      string
+	DescripcionSituacion NullString
+	DetalleReclamo       string
+	PedidoConsumidor     string
+	MontoReclamado       NullFloat64
+	Intentos             int
+}
+
+// ResultadoClasificacion respuesta de la IA ya validada.
+type ResultadoClasificacion struct {
+	Categoria   string // vacío = sin categoría
+	Sentimiento string
+	Urgencia    string
+	Resumen     string
+}
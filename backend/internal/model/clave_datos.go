@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClaveDatosTenant clave de datos (DEK) de un tenant, envuelta con la clave
+// maestra VersionMaestra. Nunca se guarda ni se serializa en claro.
+type ClaveDatosTenant struct {
+	TenantID           uuid.UUID `json:"tenant_id" db:"tenant_id"`
+	Version            int       `json:"version" db:"version"`
+	ClaveCifrada       []byte    `json:"-" db:"clave_cifrada"`
+	VersionMaestra     int       `json:"version_maestra" db:"version_maestra"`
+	Activa             bool      `json:"activa" db:"activa"`
+	FechaCreacion      time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaActualizacion time.Time `json:"fecha_actualizacion" db:"fecha_actualizacion"`
+}
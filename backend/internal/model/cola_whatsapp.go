@@ -0,0 +1,73 @@
+package model
+
+import "time"
+
+// MensajeWhatsAppEntrante un mensaje recibido por el webhook, pendiente o ya
+// procesado por los workers de la cola.
+type MensajeWhatsAppEntrante struct {
+	TenantModel
+	PhoneNumberID string             `json:"phone_number_id" db:"phone_number_id"`
+	MensajeID     string             `json:"mensaje_id" db:"mensaje_id"`
+	Telefono      string             `json:"telefono" db:"telefono"`
+	Tipo          string             `json:"tipo" db:"tipo"`
+	Texto         NullString         `json:"texto" db:"texto"`
+	Contenido     *ContenidoWhatsApp `json:"contenido,omitempty" db:"contenido"`
+	FechaMensaje  time.Time          `json:"fecha_mensaje" db:"fecha_mensaje"`
+
+	Estado         string     `json:"estado" db:"estado"`
+	Intentos       int        `json:"intentos" db:"intentos"`
+	MaxIntentos    int        `json:"max_intentos" db:"max_intentos"`
+	ProximoIntento time.Time  `json:"proximo_intento" db:"proximo_intento"`
+	Respuesta      NullString `json:"respuesta" db:"respuesta"`
+	UltimoError    NullString `json:"ultimo_error" db:"ultimo_error"`
+
+	FechaCreacion time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaProceso  NullTime  `json:"fecha_proceso" db:"fecha_proceso"`
+}
+
+// ContenidoWhatsApp datos de un mensaje que no es texto: archivo (imagen,
+// documento, audio), ubicación compartida u opción elegida en botones/listas.
+type ContenidoWhatsApp struct {
+	MediaID string `json:"media_id,omitempty"`
+	MIME    string `json:"mime,omitempty"`
+	Nombre  string `json:"nombre,omitempty"` // filename de los documentos
+	Caption string `json:"caption,omitempty"`
+	Voz     bool   `json:"voz,omitempty"` // audio grabado como nota de voz
+
+	Latitud   float64 `json:"latitud,omitempty"`
+	Longitud  float64 `json:"longitud,omitempty"`
+	Lugar     string  `json:"lugar,omitempty"`
+	Direccion string  `json:"direccion,omitempty"`
+
+	OpcionID     string `json:"opcion_id,omitempty"`
+	OpcionTitulo string `json:"opcion_titulo,omitempty"`
+}
+
+// Tipos de mensaje entrante de la Cloud API que procesa el bot.
+const (
+	WhatsAppTipoTexto       = "text"
+	WhatsAppTipoImagen      = "image"
+	WhatsAppTipoDocumento   = "document"
+	WhatsAppTipoAudio       = "audio"
+	WhatsAppTipoUbicacion   = "location"
+	WhatsAppTipoInteractivo = "interactive"
+	WhatsAppTipoBoton       = "button" // respuesta rápida de una plantilla
+)
+
+// EvidenciaWhatsApp archivo que el cliente envió durante la conversación,
+// pendiente de adjuntar al reclamo. Solo guarda la referencia de Meta: el
+// archivo se descarga al registrar.
+type EvidenciaWhatsApp struct {
+	MediaID      string    `json:"media_id"`
+	MIME         string    `json:"mime"`
+	Nombre       string    `json:"nombre"`
+	FechaRecibio time.Time `json:"fecha_recibio"`
+}
+
+// Estados de la cola de mensajes entrantes.
+const (
+	EntrantePendiente  = "PENDIENTE"
+	EntranteProcesando = "PROCESANDO"
+	EntranteProcesado  = "PROCESADO"
+	EntranteFallido    = "FALLIDO"
+)
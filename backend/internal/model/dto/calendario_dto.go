@@ -0,0 +1,26 @@
+package dto
+
+// UpdateCalendarioRequest — PUT /api/v1/calendario
+type UpdateCalendarioRequest struct {
+	DiasHabiles               bool   `json:"dias_habiles"`
+	ZonaHoraria               string `json:"zona_horaria"`       // default America/Lima
+	DiasNoLaborables          []int  `json:"dias_no_laborables"` // 0=domingo … 6=sábado
+	IncluirFeriadosNacionales bool   `json:"incluir_feriados_nacionales"`
+}
+
+// CreateFeriadoRequest — POST /api/v1/calendario/feriados
+type CreateFeriadoRequest struct {
+	Fecha       string `json:"fecha" binding:"required"` // YYYY-MM-DD
+	Descripcion string `json:"descripcion" binding:"required"`
+	Recurrente  bool   `json:"recurrente"`
+}
+
+// UpdateAlertasSLARequest — PUT /api/v1/alertas-sla
+type UpdateAlertasSLARequest struct {
+	Activo            bool `json:"activo"`
+	DiasAviso         int  `json:"dias_aviso"`   // días hábiles restantes para avisar (0-30)
+	ModoResumen       bool `json:"modo_resumen"` // un solo email por destinatario
+	NotificarAsignado bool `json:"notificar_asignado"`
+	NotificarSede     bool `json:"notificar_sede"`
+	NotificarEmpresa  bool `json:"notificar_empresa"`
+}
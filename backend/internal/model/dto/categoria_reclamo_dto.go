@@ -0,0 +1,9 @@
+package dto
+
+// CategoriaReclamoRequest — POST/PUT /api/v1/categorias-reclamo
+// Activo es puntero para distinguir "no enviado" (se mantiene) de false.
+type CategoriaReclamoRequest struct {
+	Nombre      string `json:"nombre" binding:"required"`
+	Descripcion string `json:"descripcion"`
+	Activo      *bool  `json:"activo"`
+}
@@ -12,6 +12,17 @@ type DashboardMetricas struct {
 	Ultimos7Dias            int     `json:"ultimos_7_dias"`
 	EsteMes                 int     `json:"este_mes"`
 	PromedioDiasResolucion  float64 `json:"promedio_dias_resolucion"`
+
+	// Dimensiones de la clasificación IA (SIN_CLASIFICAR = aún sin valor)
+	PorCategoria   []DimensionConteo `json:"por_categoria"`
+	PorSentimiento []DimensionConteo `json:"por_sentimiento"`
+	PorUrgencia    []DimensionConteo `json:"por_urgencia"`
+}
+
+// DimensionConteo reclamos por valor de una dimensión del dashboard.
+type DimensionConteo struct {
+	Valor string `json:"valor"`
+	Total int    `json:"total"`
 }
 
 type DashboardFilters struct {
@@ -0,0 +1,12 @@
+package dto
+
+// PlantillaWhatsAppRequest — POST/PUT /api/v1/plantillas-whatsapp
+// Variables en el orden de los parámetros {{1}}, {{2}}, ... del cuerpo.
+// Activo es puntero para distinguir "no enviado" (se mantiene) de false.
+type PlantillaWhatsAppRequest struct {
+	Tipo      string   `json:"tipo" binding:"required"`
+	Nombre    string   `json:"nombre" binding:"required"`
+	Idioma    string   `json:"idioma"`
+	Variables []string `json:"variables"`
+	Activo    *bool    `json:"activo"`
+}
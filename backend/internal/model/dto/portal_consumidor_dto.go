@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// SolicitarCodigoPortalRequest — POST /libro/:slug/portal/acceso
+// Se envía el email o el teléfono, no ambos.
+type SolicitarCodigoPortalRequest struct {
+	Email    string `json:"email"`
+	Telefono string `json:"telefono"`
+}
+
+// VerificarCodigoPortalRequest — POST /libro/:slug/portal/acceso/verificar
+type VerificarCodigoPortalRequest struct {
+	Email    string `json:"email"`
+	Telefono string `json:"telefono"`
+	Codigo   string `json:"codigo" binding:"required"`
+}
+
+// ReclamoPortalItem reclamo del consumidor en el listado del portal.
+type ReclamoPortalItem struct {
+	CodigoReclamo string    `json:"codigo_reclamo"`
+	TipoSolicitud string    `json:"tipo_solicitud"`
+	Estado        string    `json:"estado"`
+	CanalOrigen   string    `json:"canal_origen"`
+	SedeNombre    string    `json:"sede_nombre,omitempty"`
+	FechaRegistro time.Time `json:"fecha_registro"`
+}
@@ -41,6 +41,10 @@ type CreateReclamoRequest struct {
 	// Firma
 	FirmaDigital string `json:"firma_digital"`
 
+	// Conformidad: acepta_copia = enviar la hoja de reclamación al email
+	AceptaTerminos bool `json:"acepta_terminos"`
+	AceptaCopia    bool `json:"acepta_copia"`
+
 	// Sede (desde la URL pública)
 	SedeSlug string `json:"sede_slug"`
 }
@@ -62,10 +66,27 @@ type ReclamoListItem struct {
 
 // UpdateEstadoRequest cambio de estado.
 type UpdateEstadoRequest struct {
-	Estado     string `json:"estado" validate:"required,oneof=PENDIENTE EN_PROCESO RESUELTO CERRADO"`
+	Estado     string `json:"estado" validate:"required,oneof=PENDIENTE EN_PROCESO RESUELTO CERRADO RECHAZADO"`
 	Comentario string `json:"comentario"`
 }
 
+// ReabrirReclamoRequest reapertura desde el panel.
+type ReabrirReclamoRequest struct {
+	Motivo string `json:"motivo" binding:"required"`
+}
+
+// RelacionReclamoRequest vincular, fusionar o descartar un duplicado:
+// reclamo_id es el otro reclamo del par (en una fusión, el que se mantiene).
+type RelacionReclamoRequest struct {
+	ReclamoID string `json:"reclamo_id" binding:"required"`
+}
+
+// ReabrirPublicoRequest reapertura por el consumidor desde el seguimiento.
+type ReabrirPublicoRequest struct {
+	NumeroDocumento string `json:"numero_documento" binding:"required"`
+	Motivo          string `json:"motivo" binding:"required"`
+}
+
 // AsignarReclamoRequest asignación a un admin.
 type AsignarReclamoRequest struct {
 	AdminID uuid.UUID `json:"admin_id" validate:"required"`
@@ -82,11 +103,18 @@ type ReclamoTrackingResponse struct {
 	TipoSolicitud        string     `json:"tipo_solicitud"`
 	DescripcionBien      string     `json:"descripcion_bien"`
 	RespuestaEmpresa     string     `json:"respuesta_empresa,omitempty"`
+	PuedeReabrir         bool       `json:"puede_reabrir"`
+	ReabrirHasta         *time.Time `json:"reabrir_hasta,omitempty"`
+
+	// true si el request trae el token del portal del consumidor dueño del
+	// reclamo; sin él no se incluye la respuesta ni se accede a los mensajes
+	ConsumidorVerificado bool `json:"consumidor_verificado"`
 }
 
 // PublicMessageRequest mensaje enviado desde el seguimiento.
+// Acepta JSON o multipart/form-data (con el archivo en el campo "archivo").
 type PublicMessageRequest struct {
-	Mensaje       string `json:"mensaje" binding:"required"`
-	ArchivoURL    string `json:"archivo_url"`
-	ArchivoNombre string `json:"archivo_nombre"`
+	Mensaje       string `json:"mensaje" form:"mensaje" binding:"required"`
+	ArchivoURL    string `json:"archivo_url" form:"archivo_url"`
+	ArchivoNombre string `json:"archivo_nombre" form:"archivo_nombre"`
 }
\ No newline at end of file
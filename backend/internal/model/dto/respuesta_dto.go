@@ -1,8 +1,9 @@
 package dto
 
+// CreateRespuestaRequest acepta JSON o multipart/form-data (archivos en el campo "archivos").
 type CreateRespuestaRequest struct {
-	RespuestaEmpresa     string `json:"respuesta_empresa" binding:"required"`
-	AccionTomada         string `json:"accion_tomada"`
-	CompensacionOfrecida string `json:"compensacion_ofrecida"`
-	CargoResponsable     string `json:"cargo_responsable"`
+	RespuestaEmpresa     string `json:"respuesta_empresa" form:"respuesta_empresa" binding:"required"`
+	AccionTomada         string `json:"accion_tomada" form:"accion_tomada"`
+	CompensacionOfrecida string `json:"compensacion_ofrecida" form:"compensacion_ofrecida"`
+	CargoResponsable     string `json:"cargo_responsable" form:"cargo_responsable"`
 }
\ No newline at end of file
@@ -52,6 +52,11 @@ type Historial struct {
 	IPAddress      NullString `json:"ip_address" db:"ip_address"`
 
 	FechaAccion time.Time `json:"fecha_accion" db:"fecha_accion"`
+
+	// Cadena de integridad (se calcula al insertar, ver repo.HashHistorial)
+	CadenaSecuencia NullInt64  `json:"cadena_secuencia" db:"cadena_secuencia"`
+	HashAnterior    NullString `json:"hash_anterior" db:"hash_anterior"`
+	Hash            NullString `json:"hash" db:"hash"`
 }
 
 // Tipos de acción en historial.
@@ -63,6 +68,8 @@ const (
 	AccionNotificacion    = "NOTIFICACION"
 	AccionReapertura      = "REAPERTURA"
 	AccionChatbotRespuesta = "CHATBOT_RESPUESTA"
+	AccionVinculacion     = "VINCULACION"
+	AccionFusion          = "FUSION"
 )
 
 // --- MENSAJE SEGUIMIENTO ---
@@ -116,6 +123,9 @@ type Auditoria struct {
 	IPAddress NullString      `json:"ip_address" db:"ip_address"`
 
 	Fecha time.Time `json:"fecha" db:"fecha"`
+
+	// Campo transiente (JOIN con usuarios_admin en listados)
+	NombreUsuario string `json:"nombre_usuario,omitempty" db:"-"`
 }
 
 // Entidades auditables.
@@ -130,4 +140,20 @@ const (
 	EntidadAPIKey      = "API_KEY"
 	EntidadSuscripcion = "SUSCRIPCION"
 	EntidadPlan        = "PLAN"
+	EntidadCategoria   = "CATEGORIA_RECLAMO"
+)
+
+// Acciones auditables.
+const (
+	AuditCrear            = "CREAR"
+	AuditActualizar       = "ACTUALIZAR"
+	AuditEliminar         = "ELIMINAR"
+	AuditActivar          = "ACTIVAR"
+	AuditDesactivar       = "DESACTIVAR"
+	AuditConfigurar       = "CONFIGURAR"
+	AuditCambiarPassword  = "CAMBIAR_PASSWORD"
+	AuditResetearPassword = "RESETEAR_PASSWORD"
+	AuditGenerarAPIKey    = "GENERAR_API_KEY"
+	AuditRevocarAPIKey    = "REVOCAR_API_KEY"
+	AuditCambiarPlan      = "CAMBIAR_PLAN"
 )
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cadenas de integridad por tenant.
+const (
+	CadenaReclamos  = "reclamos"
+	CadenaHistorial = "historial_reclamos"
+)
+
+// HashGenesis hash_anterior del primer eslabón de cada cadena.
+const HashGenesis = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// VerificacionIntegridad resultado de recorrer las cadenas de un tenant.
+type VerificacionIntegridad struct {
+	TenantID          uuid.UUID          `json:"tenant_id"`
+	Integra           bool               `json:"integra"`
+	Reclamos          VerificacionCadena `json:"reclamos"`
+	Historial         VerificacionCadena `json:"historial"`
+	FechaVerificacion time.Time          `json:"fecha_verificacion"`
+}
+
+// VerificacionCadena resultado de una cadena. Quiebre es el primer eslabón roto.
+type VerificacionCadena struct {
+	Cadena    string         `json:"cadena"`
+	Eslabones int            `json:"eslabones"`
+	SinCadena int            `json:"sin_cadena"` // filas anteriores a la cadena (sin hash)
+	Integra   bool           `json:"integra"`
+	Quiebre   *QuiebreCadena `json:"quiebre,omitempty"`
+}
+
+// QuiebreCadena primer eslabón que no coincide.
+type QuiebreCadena struct {
+	Secuencia int64     `json:"secuencia"`
+	ID        uuid.UUID `json:"id"`
+	Motivo    string    `json:"motivo"`
+}
+
+// Motivos de quiebre.
+const (
+	QuiebreSecuencia = "SECUENCIA_FALTANTE"     // se borró o reordenó un eslabón
+	QuiebreEnlace    = "HASH_ANTERIOR_DISTINTO" // no enlaza con el eslabón previo
+	QuiebreContenido = "CONTENIDO_ALTERADO"     // el contenido no corresponde a su hash
+)
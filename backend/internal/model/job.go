@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobEjecucion una corrida de un job del scheduler.
+// No tiene tenant_id: los jobs son del sistema.
+type JobEjecucion struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Nombre     string     `json:"nombre" db:"nombre"`
+	Instancia  string     `json:"instancia" db:"instancia"`
+	Origen     string     `json:"origen" db:"origen"`
+	Estado     string     `json:"estado" db:"estado"`
+	Resultado  NullString `json:"resultado" db:"resultado"`
+	Error      NullString `json:"error" db:"error"`
+	DuracionMs NullInt64  `json:"duracion_ms" db:"duracion_ms"`
+
+	FechaInicio time.Time `json:"fecha_inicio" db:"fecha_inicio"`
+	FechaFin    NullTime  `json:"fecha_fin" db:"fecha_fin"`
+}
+
+// Estados de ejecución.
+const (
+	JobEnCurso = "EN_CURSO"
+	JobExitoso = "EXITOSO"
+	JobFallido = "FALLIDO"
+)
+
+// Orígenes de ejecución.
+const (
+	JobOrigenProgramado = "PROGRAMADO"
+	JobOrigenManual     = "MANUAL"
+)
@@ -13,6 +13,8 @@ const (
 	RecursoReclamo       Recurso = "RECLAMO"
 	RecursoChatbot       Recurso = "CHATBOT"
 	RecursoCanalWhatsApp Recurso = "CANAL_WHATSAPP"
+	RecursoStorage       Recurso = "STORAGE"
+	RecursoTokensIA      Recurso = "TOKENS_IA"
 )
 
 // Funcionalidad identifica un feature del plan.
@@ -52,6 +54,7 @@ type UsoTenant struct {
 	LimiteChatbots         int `json:"limite_chatbots"`
 	LimiteCanalesWhatsApp  int `json:"limite_canales_whatsapp"`
 	LimiteStorageMB        int `json:"limite_storage_mb"`
+	LimiteTokensIAMes      int `json:"limite_tokens_ia_mes"`
 
 	// Funcionalidades
 	PermiteChatbot       bool `json:"permite_chatbot"`
@@ -71,6 +74,8 @@ type UsoTenant struct {
 	UsoReclamosMes      int `json:"uso_reclamos_mes"`
 	UsoChatbots         int `json:"uso_chatbots"`
 	UsoCanalesWhatsApp  int `json:"uso_canales_whatsapp"`
+	UsoStorageMB        int `json:"uso_storage_mb"` // redondeado hacia arriba
+	UsoTokensIAMes      int `json:"uso_tokens_ia_mes"`
 }
 
 // LimiteDeRecurso retorna (uso, límite) para un recurso dado.
@@ -86,6 +91,10 @@ func (u *UsoTenant) LimiteDeRecurso(r Recurso) (uso int, limite int) {
 		return u.UsoChatbots, u.LimiteChatbots
 	case RecursoCanalWhatsApp:
 		return u.UsoCanalesWhatsApp, u.LimiteCanalesWhatsApp
+	case RecursoStorage:
+		return u.UsoStorageMB, u.LimiteStorageMB
+	case RecursoTokensIA:
+		return u.UsoTokensIAMes, u.LimiteTokensIAMes
 	default:
 		return 0, 0
 	}
@@ -143,6 +152,8 @@ func NombreRecurso(r Recurso) string {
 		RecursoReclamo:       "reclamos del mes",
 		RecursoChatbot:       "chatbots",
 		RecursoCanalWhatsApp: "canales de WhatsApp",
+		RecursoStorage:       "MB de adjuntos",
+		RecursoTokensIA:      "tokens de IA del mes",
 	}
 	if n, ok := nombres[r]; ok {
 		return n
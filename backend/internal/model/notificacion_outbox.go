@@ -0,0 +1,76 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificacionOutbox una notificación pendiente/enviada del outbox transaccional.
+type NotificacionOutbox struct {
+	TenantModel
+	ReclamoID    NullUUID        `json:"reclamo_id" db:"reclamo_id"`
+	Canal        string          `json:"canal" db:"canal"`
+	Tipo         string          `json:"tipo" db:"tipo"`
+	Destinatario string          `json:"destinatario" db:"destinatario"`
+	Payload      json.RawMessage `json:"payload" db:"payload"`
+
+	Estado         string     `json:"estado" db:"estado"`
+	Intentos       int        `json:"intentos" db:"intentos"`
+	MaxIntentos    int        `json:"max_intentos" db:"max_intentos"`
+	ProximoIntento time.Time  `json:"proximo_intento" db:"proximo_intento"`
+	UltimoError    NullString `json:"ultimo_error" db:"ultimo_error"`
+
+	FechaCreacion time.Time `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaEnvio    NullTime  `json:"fecha_envio" db:"fecha_envio"`
+
+	// Seguimiento de entrega de WhatsApp (webhook de estados de Meta)
+	MensajeExternoID NullString `json:"mensaje_externo_id" db:"mensaje_externo_id"`
+	EstadoEntrega    NullString `json:"estado_entrega" db:"estado_entrega"`
+	FechaEntrega     NullTime   `json:"fecha_entrega" db:"fecha_entrega"`
+	FechaLectura     NullTime   `json:"fecha_lectura" db:"fecha_lectura"`
+	ErrorEntrega     NullString `json:"error_entrega" db:"error_entrega"`
+}
+
+// PayloadNotificacion datos para renderizar la notificación al momento del envío.
+// Cada tipo usa solo los campos que necesita.
+type PayloadNotificacion struct {
+	Codigo        string `json:"codigo,omitempty"`
+	NombreCliente string `json:"nombre_cliente,omitempty"`
+	TipoSolicitud string `json:"tipo_solicitud,omitempty"`
+	Fecha         string `json:"fecha,omitempty"`
+	Estado        string `json:"estado,omitempty"`
+	FechaLimite   string `json:"fecha_limite,omitempty"`
+	Texto         string `json:"texto,omitempty"`
+	AccionTomada  string `json:"accion_tomada,omitempty"`
+}
+
+// Canales de notificación.
+const (
+	CanalNotifEmail    = "EMAIL"
+	CanalNotifWhatsApp = "WHATSAPP"
+)
+
+// Tipos de notificación.
+const (
+	NotifConfirmacionReclamo = "CONFIRMACION_RECLAMO"
+	NotifNuevoReclamoEmpresa = "NUEVO_RECLAMO_EMPRESA"
+	NotifCambioEstado        = "CAMBIO_ESTADO"
+	NotifResolucion          = "RESOLUCION"
+	NotifMensajeNuevo        = "MENSAJE_NUEVO"
+)
+
+// Estados del outbox.
+const (
+	OutboxPendiente  = "PENDIENTE"
+	OutboxProcesando = "PROCESANDO"
+	OutboxEnviada    = "ENVIADA"
+	OutboxFallida    = "FALLIDA" // dead-letter
+)
+
+// Estados de entrega de WhatsApp informados por Meta.
+const (
+	EntregaEnviado   = "ENVIADO"
+	EntregaEntregado = "ENTREGADO"
+	EntregaLeido     = "LEIDO"
+	EntregaFallido   = "FALLIDO"
+)
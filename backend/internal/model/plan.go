@@ -42,6 +42,9 @@ type Plan struct {
 	// ── Storage ──
 	MaxStorageMB int `json:"max_storage_mb" db:"max_storage_mb"`
 
+	// ── IA: tokens (prompt + output) por mes calendario, -1 = ilimitado ──
+	MaxTokensIAMes int `json:"max_tokens_ia_mes" db:"max_tokens_ia_mes"`
+
 	// ── Display ──
 	Orden     int  `json:"orden" db:"orden"`
 	Activo    bool `json:"activo" db:"activo"`
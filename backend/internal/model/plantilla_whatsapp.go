@@ -0,0 +1,36 @@
+package model
+
+// PlantillaWhatsApp plantilla aprobada en Meta con la que el tenant notifica
+// al consumidor por WhatsApp. Una por tipo de notificación.
+//
+// Variables indica qué dato va en cada parámetro del cuerpo: Variables[0] es
+// {{1}}, Variables[1] es {{2}}, etc.
+type PlantillaWhatsApp struct {
+	TenantModel
+	Tipo      string   `json:"tipo" db:"tipo"`
+	Nombre    string   `json:"nombre" db:"nombre"`
+	Idioma    string   `json:"idioma" db:"idioma"`
+	Variables []string `json:"variables" db:"variables"`
+	Activo    bool     `json:"activo" db:"activo"`
+	Timestamps
+}
+
+// Tipos de notificación que se pueden enviar por WhatsApp.
+var TiposNotifWhatsApp = []string{NotifCambioEstado, NotifResolucion, NotifMensajeNuevo}
+
+// Variables disponibles para los parámetros de una plantilla.
+const (
+	VarPlantillaCodigo        = "codigo"
+	VarPlantillaNombreCliente = "nombre_cliente"
+	VarPlantillaEstado        = "estado"
+	VarPlantillaFechaLimite   = "fecha_limite"
+	VarPlantillaEmpresa       = "empresa"
+	VarPlantillaMensaje       = "mensaje"
+)
+
+// VariablesPlantillaWhatsApp todas las variables válidas, en el orden en que
+// se documentan.
+var VariablesPlantillaWhatsApp = []string{
+	VarPlantillaCodigo, VarPlantillaNombreCliente, VarPlantillaEstado,
+	VarPlantillaFechaLimite, VarPlantillaEmpresa, VarPlantillaMensaje,
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Canales por los que el consumidor se identifica en el portal.
+const (
+	CanalAccesoEmail    = "EMAIL"
+	CanalAccesoTelefono = "TELEFONO"
+)
+
+// IdentidadConsumidor consumidor autenticado en el portal de un tenant: el
+// email (en minúsculas) o los últimos 9 dígitos del teléfono que dejó en sus
+// reclamos.
+type IdentidadConsumidor struct {
+	TenantID uuid.UUID `json:"-"`
+	Canal    string    `json:"canal"`
+	Destino  string    `json:"destino"`
+}
+
+// CodigoAccesoConsumidor código de un solo uso enviado al consumidor.
+// Solo se guarda el hash del código.
+type CodigoAccesoConsumidor struct {
+	TenantModel
+	Canal            string     `json:"canal" db:"canal"`
+	Destino          string     `json:"destino" db:"destino"`
+	CodigoHash       string     `json:"-" db:"codigo_hash"`
+	Intentos         int        `json:"intentos" db:"intentos"`
+	Usado            bool       `json:"usado" db:"usado"`
+	IPAddress        NullString `json:"ip_address" db:"ip_address"`
+	FechaCreacion    time.Time  `json:"fecha_creacion" db:"fecha_creacion"`
+	FechaVencimiento time.Time  `json:"fecha_vencimiento" db:"fecha_vencimiento"`
+}
@@ -67,6 +67,19 @@ type Reclamo struct {
 	CanalOrigen string     `json:"canal_origen" db:"canal_origen"`
 	DeletedAt   NullTime   `json:"deleted_at" db:"deleted_at"`
 
+	// Clasificación con IA (asíncrona, ver ClasificacionService). No entra en el hash.
+	Categoria           NullString `json:"categoria" db:"categoria"`
+	Sentimiento         NullString `json:"sentimiento" db:"sentimiento"`
+	Urgencia            NullString `json:"urgencia" db:"urgencia"`
+	ResumenIA           NullString `json:"resumen_ia" db:"resumen_ia"`
+	ClasificacionEstado NullString `json:"clasificacion_estado" db:"clasificacion_estado"`
+	FechaClasificacion  NullTime   `json:"fecha_clasificacion" db:"fecha_clasificacion"`
+
+	// Cadena de integridad (se calcula al insertar, ver repo.HashReclamo)
+	CadenaSecuencia NullInt64  `json:"cadena_secuencia" db:"cadena_secuencia"`
+	HashAnterior    NullString `json:"hash_anterior" db:"hash_anterior"`
+	Hash            NullString `json:"hash" db:"hash"`
+
 	// Campo transiente (no es columna DB, se llena con JOIN en queries específicas)
 	NombreAtendidoPor string `json:"nombre_atendido_por,omitempty" db:"-"`
 }
@@ -86,6 +99,46 @@ const (
 	EstadoRechazado  = "RECHAZADO" // <--- FALTABA ESTA LÍNEA
 )
 
+// transicionesEstado estados a los que se puede pasar desde cada estado con
+// CambiarEstado. Salir de RESUELTO o CERRADO hacia EN_PROCESO es una
+// reapertura (ReclamoService.Reabrir), no un cambio de estado. Cerrar un
+// duplicado abierto al fusionarlo tampoco lo es (PuedeFusionarse).
+var transicionesEstado = map[string][]string{
+	EstadoPendiente: {EstadoEnProceso, EstadoResuelto, EstadoRechazado},
+	EstadoEnProceso: {EstadoResuelto, EstadoRechazado},
+	EstadoResuelto:  {EstadoCerrado},
+	EstadoRechazado: {EstadoCerrado},
+	EstadoCerrado:   {},
+}
+
+// TransicionValida indica si un reclamo puede pasar de desde a hacia.
+func TransicionValida(desde, hacia string) bool {
+	for _, e := range transicionesEstado[desde] {
+		if e == hacia {
+			return true
+		}
+	}
+	return false
+}
+
+// EsEstadoFinal estados en los que el reclamo quedó atendido (fecha_cierre fijada).
+func EsEstadoFinal(estado string) bool {
+	return estado == EstadoResuelto || estado == EstadoCerrado || estado == EstadoRechazado
+}
+
+// PuedeReabrirse estados desde los que se permite la reapertura.
+func PuedeReabrirse(estado string) bool {
+	return estado == EstadoResuelto || estado == EstadoCerrado
+}
+
+// PuedeFusionarse estados en los que un reclamo sigue abierto: solo entonces
+// puede fusionarse en otro o recibir una fusión. El duplicado pasa de aquí a
+// CERRADO sin respuesta propia porque se atiende en el principal; es la única
+// salida a CERRADO fuera de transicionesEstado.
+func PuedeFusionarse(estado string) bool {
+	return estado == EstadoPendiente || estado == EstadoEnProceso
+}
+
 // Tipos de documento.
 const (
 	DocDNI       = "DNI"
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReclamoRelacion par de reclamos relacionados: posible duplicado detectado al
+// registrar o vínculo/fusión hecho por el equipo. En una fusión ReclamoID es
+// el reclamo cerrado y RelacionadoID el que se sigue atendiendo.
+type ReclamoRelacion struct {
+	TenantModel
+	ReclamoID       uuid.UUID `json:"reclamo_id" db:"reclamo_id"`
+	RelacionadoID   uuid.UUID `json:"relacionado_id" db:"relacionado_id"`
+	Motivos         []string  `json:"motivos" db:"motivos"`
+	Puntaje         int       `json:"puntaje" db:"puntaje"`
+	Estado          string    `json:"estado" db:"estado"`
+	ResueltoPor     NullUUID  `json:"resuelto_por" db:"resuelto_por"`
+	FechaDeteccion  time.Time `json:"fecha_deteccion" db:"fecha_deteccion"`
+	FechaResolucion NullTime  `json:"fecha_resolucion" db:"fecha_resolucion"`
+
+	// Campo transiente: el otro reclamo del par, visto desde el consultado
+	Otro *ReclamoConsumidor `json:"otro,omitempty" db:"-"`
+}
+
+// ReclamoConsumidor resumen de otro reclamo que coincide con el consultado
+// (historial del consumidor y candidatos a duplicado).
+type ReclamoConsumidor struct {
+	ID            uuid.UUID  `json:"id"`
+	CodigoReclamo string     `json:"codigo_reclamo"`
+	TipoSolicitud string     `json:"tipo_solicitud"`
+	Estado        string     `json:"estado"`
+	CanalOrigen   string     `json:"canal_origen"`
+	SedeNombre    NullString `json:"sede_nombre"`
+	Categoria     NullString `json:"categoria"`
+	FechaRegistro time.Time  `json:"fecha_registro"`
+	Coincidencias []string   `json:"coincidencias,omitempty"` // DOCUMENTO, EMAIL, TELEFONO, PEDIDO
+
+	// Relato para comparar textos; no se expone
+	Relato string `json:"-"`
+}
+
+// Estados de una relación entre reclamos.
+const (
+	RelacionProbable   = "PROBABLE"
+	RelacionDescartada = "DESCARTADO"
+	RelacionVinculada  = "VINCULADO"
+	RelacionFusionada  = "FUSIONADO"
+)
+
+// Motivos de coincidencia entre dos reclamos.
+const (
+	CoincideDocumento = "DOCUMENTO"
+	CoincideEmail     = "EMAIL"
+	CoincideTelefono  = "TELEFONO"
+	CoincidePedido    = "PEDIDO"
+	CoincideTexto     = "TEXTO"
+	CoincideManual    = "MANUAL"
+)
+
+// pesosCoincidencia aporte de cada motivo al puntaje (tope 100).
+var pesosCoincidencia = map[string]int{
+	CoincideDocumento: 40,
+	CoincideEmail:     25,
+	CoincideTelefono:  25,
+	CoincidePedido:    35,
+	CoincideTexto:     30,
+}
+
+// PuntajeDuplicado suma los pesos de los motivos, con tope 100.
+func PuntajeDuplicado(motivos []string) int {
+	total := 0
+	for _, m := range motivos {
+		total += pesosCoincidencia[m]
+	}
+	if total > 100 {
+		return 100
+	}
+	return total
+}
+
+// EsDuplicadoProbable el mismo consumidor con el mismo relato, o el mismo
+// pedido con otra coincidencia. Que solo coincida el consumidor es un
+// reclamo repetido de la misma persona, no un duplicado.
+func EsDuplicadoProbable(motivos []string) bool {
+	tiene := make(map[string]bool, len(motivos))
+	for _, m := range motivos {
+		tiene[m] = true
+	}
+	identidad := tiene[CoincideDocumento] || tiene[CoincideEmail] || tiene[CoincideTelefono]
+	if tiene[CoincideTexto] && (identidad || tiene[CoincidePedido]) {
+		return true
+	}
+	return tiene[CoincidePedido] && identidad
+}
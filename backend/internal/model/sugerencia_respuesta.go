@@ -0,0 +1,28 @@
+package model
+
+// SugerenciaRespuesta borrador de respuesta redactado por la IA. No se guarda:
+// el usuario lo edita y lo envía con RespuestaService.Crear.
+type SugerenciaRespuesta struct {
+	RespuestaEmpresa     string `json:"respuesta_empresa"`
+	AccionTomada         string `json:"accion_tomada"`
+	CompensacionOfrecida string `json:"compensacion_ofrecida"`
+
+	// Códigos de los casos parecidos usados como referencia
+	CasosReferencia []string `json:"casos_referencia"`
+
+	Proveedor    string `json:"proveedor"`
+	PromptTokens int    `json:"prompt_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+}
+
+// CasoRespondido reclamo del tenant ya respondido, referencia para sugerencias.
+type CasoRespondido struct {
+	CodigoReclamo        string     `json:"codigo_reclamo"`
+	TipoSolicitud        string     `json:"tipo_solicitud"`
+	Estado               string     `json:"estado"`
+	DetalleReclamo       string     `json:"detalle_reclamo"`
+	PedidoConsumidor     string     `json:"pedido_consumidor"`
+	RespuestaEmpresa     string     `json:"respuesta_empresa"`
+	AccionTomada         NullString `json:"accion_tomada"`
+	CompensacionOfrecida NullString `json:"compensacion_ofrecida"`
+}
@@ -31,6 +31,7 @@ type Suscripcion struct {
 	OverrideMaxChatbots         NullInt64 `json:"override_max_chatbots" db:"override_max_chatbots"`
 	OverrideMaxCanalesWhatsApp  NullInt64 `json:"override_max_canales_whatsapp" db:"override_max_canales_whatsapp"`
 	OverrideMaxStorageMB        NullInt64 `json:"override_max_storage_mb" db:"override_max_storage_mb"`
+	OverrideMaxTokensIA         NullInt64 `json:"override_max_tokens_ia" db:"override_max_tokens_ia"`
 
 	// ── Pago ──
 	ReferenciaPago NullString `json:"referencia_pago" db:"referencia_pago"`
@@ -25,6 +25,8 @@ type Tenant struct {
 	MensajeConfirmacion  NullString `json:"mensaje_confirmacion" db:"mensaje_confirmacion"`
 	NotificarWhatsapp    bool       `json:"notificar_whatsapp" db:"notificar_whatsapp"`
 	NotificarEmail       bool       `json:"notificar_email" db:"notificar_email"`
+	DiasReapertura       int        `json:"dias_reapertura" db:"dias_reapertura"` // 0 = el consumidor no puede reabrir
+	FormatoCodigo        NullString `json:"formato_codigo" db:"formato_codigo"`   // NULL = helper.FormatoCodigoPorDefecto
 
 	// Control
 	Activo  bool `json:"activo" db:"activo"`
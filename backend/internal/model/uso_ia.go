@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Orígenes de consumo de IA.
+const (
+	OrigenIAAsistente     = "ASISTENTE"
+	OrigenIAWhatsApp      = "WHATSAPP"
+	OrigenIASugerencia    = "SUGERENCIA_RESPUESTA"
+	OrigenIAClasificacion = "CLASIFICACION"
+	OrigenIATranscripcion = "TRANSCRIPCION"
+)
+
+// UsoIA una llamada al proveedor de IA en el ledger de tokens.
+type UsoIA struct {
+	TenantModel
+	Origen       string    `json:"origen" db:"origen"`
+	Proveedor    string    `json:"proveedor" db:"proveedor"`
+	TokensPrompt int       `json:"tokens_prompt" db:"tokens_prompt"`
+	TokensOutput int       `json:"tokens_output" db:"tokens_output"`
+	Fecha        time.Time `json:"fecha" db:"fecha"`
+}
+
+// UsoIAAgrupado totales de tokens de un grupo del reporte (origen, proveedor o día).
+type UsoIAAgrupado struct {
+	Clave        string `json:"clave"`
+	Llamadas     int    `json:"llamadas"`
+	TokensPrompt int    `json:"tokens_prompt"`
+	TokensOutput int    `json:"tokens_output"`
+}
+
+// ReporteUsoIA consumo de IA de un tenant en un rango de fechas,
+// junto con la cuota del mes en curso.
+type ReporteUsoIA struct {
+	Desde        time.Time       `json:"desde"`
+	Hasta        time.Time       `json:"hasta"`
+	Llamadas     int             `json:"llamadas"`
+	TokensPrompt int             `json:"tokens_prompt"`
+	TokensOutput int             `json:"tokens_output"`
+	PorOrigen    []UsoIAAgrupado `json:"por_origen"`
+	PorProveedor []UsoIAAgrupado `json:"por_proveedor"`
+	PorDia       []UsoIAAgrupado `json:"por_dia"`
+
+	// Cuota del mes calendario en curso (-1 = ilimitado)
+	LimiteMes     int  `json:"limite_mes"`
+	UsoMes        int  `json:"uso_mes"`
+	PorcentajeMes int  `json:"porcentaje_mes"`
+	CuotaAgotada  bool `json:"cuota_agotada"`
+}
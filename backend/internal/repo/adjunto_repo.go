@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// AdjuntoRepo metadatos de archivos adjuntos.
+type AdjuntoRepo struct {
+	db DBTX
+}
+
+func NewAdjuntoRepo(db *sql.DB) *AdjuntoRepo {
+	return &AdjuntoRepo{db: db}
+}
+
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *AdjuntoRepo) WithTx(tx *sql.Tx) *AdjuntoRepo {
+	return &AdjuntoRepo{db: tx}
+}
+
+const adjuntoColumns = `
+	tenant_id, id, reclamo_id, entidad, entidad_id,
+	nombre_original, clave, mime_type, tamano_bytes, sha256,
+	origen, subido_por, fecha_creacion`
+
+func scanAdjunto(row interface{ Scan(...any) error }, a *model.Adjunto) error {
+	return row.Scan(
+		&a.TenantID, &a.ID, &a.ReclamoID, &a.Entidad, &a.EntidadID,
+		&a.NombreOriginal, &a.Clave, &a.MimeType, &a.TamanoBytes, &a.SHA256,
+		&a.Origen, &a.SubidoPor, &a.FechaCreacion,
+	)
+}
+
+// Create inserta el adjunto. El ID lo asigna el service (es parte de la clave del storage).
+func (r *AdjuntoRepo) Create(ctx context.Context, a *model.Adjunto) error {
+	query := `
+		INSERT INTO adjuntos (
+			tenant_id, id, reclamo_id, entidad, entidad_id,
+			nombre_original, clave, mime_type, tamano_bytes, sha256,
+			origen, subido_por
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+		RETURNING fecha_creacion`
+
+	err := r.db.QueryRowContext(ctx, query,
+		a.TenantID, a.ID, a.ReclamoID, a.Entidad, a.EntidadID,
+		a.NombreOriginal, a.Clave, a.MimeType, a.TamanoBytes, a.SHA256,
+		a.Origen, a.SubidoPor,
+	).Scan(&a.FechaCreacion)
+	if err != nil {
+		return fmt.Errorf("adjunto_repo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *AdjuntoRepo) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*model.Adjunto, error) {
+	query := `SELECT ` + adjuntoColumns + ` FROM adjuntos WHERE tenant_id = $1 AND id = $2`
+
+	a := &model.Adjunto{}
+	err := scanAdjunto(r.db.QueryRowContext(ctx, query, tenantID, id), a)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("adjunto_repo.GetByID: %w", err)
+	}
+	return a, nil
+}
+
+func (r *AdjuntoRepo) ListarPorReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.Adjunto, error) {
+	query := `SELECT ` + adjuntoColumns + `
+		FROM adjuntos
+		WHERE tenant_id = $1 AND reclamo_id = $2
+		ORDER BY fecha_creacion`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("adjunto_repo.ListarPorReclamo: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Adjunto
+	for rows.Next() {
+		var a model.Adjunto
+		if err := scanAdjunto(rows, &a); err != nil {
+			return nil, fmt.Errorf("adjunto_repo.ListarPorReclamo scan: %w", err)
+		}
+		items = append(items, a)
+	}
+	return items, rows.Err()
+}
+
+// UsoBytes total almacenado por el tenant (para el límite de storage del plan).
+func (r *AdjuntoRepo) UsoBytes(ctx context.Context, tenantID uuid.UUID) (int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(tamano_bytes), 0) FROM adjuntos WHERE tenant_id = $1`, tenantID,
+	).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("adjunto_repo.UsoBytes: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type AlertaSLARepo struct {
+	db *sql.DB
+}
+
+func NewAlertaSLARepo(db *sql.DB) *AlertaSLARepo {
+	return &AlertaSLARepo{db: db}
+}
+
+// ── Configuración ──
+
+// ObtenerConfig retorna la configuración del tenant. nil si no tiene fila propia.
+func (r *AlertaSLARepo) ObtenerConfig(ctx context.Context, tenantID uuid.UUID) (*model.ConfigAlertasSLA, error) {
+	query := `
+		SELECT tenant_id, activo, dias_aviso, modo_resumen,
+			notificar_asignado, notificar_sede, notificar_empresa, fecha_actualizacion
+		FROM config_alertas_sla
+		WHERE tenant_id = $1`
+
+	c := &model.ConfigAlertasSLA{}
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&c.TenantID, &c.Activo, &c.DiasAviso, &c.ModoResumen,
+		&c.NotificarAsignado, &c.NotificarSede, &c.NotificarEmpresa, &c.FechaActualizacion,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("alerta_sla_repo.ObtenerConfig: %w", err)
+	}
+	return c, nil
+}
+
+func (r *AlertaSLARepo) GuardarConfig(ctx context.Context, c *model.ConfigAlertasSLA) error {
+	query := `
+		INSERT INTO config_alertas_sla (
+			tenant_id, activo, dias_aviso, modo_resumen,
+			notificar_asignado, notificar_sede, notificar_empresa
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			activo = excluded.activo,
+			dias_aviso = excluded.dias_aviso,
+			modo_resumen = excluded.modo_resumen,
+			notificar_asignado = excluded.notificar_asignado,
+			notificar_sede = excluded.notificar_sede,
+			notificar_empresa = excluded.notificar_empresa,
+			fecha_actualizacion = now()
+		RETURNING fecha_actualizacion`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.TenantID, c.Activo, c.DiasAviso, c.ModoResumen,
+		c.NotificarAsignado, c.NotificarSede, c.NotificarEmpresa,
+	).Scan(&c.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("alerta_sla_repo.GuardarConfig: %w", err)
+	}
+	return nil
+}
+
+// ── Candidatos ──
+
+// ReclamoAbiertoSLA datos de un reclamo abierto para evaluar su plazo.
+type ReclamoAbiertoSLA struct {
+	ID            uuid.UUID
+	Codigo        string
+	Consumidor    string
+	TipoSolicitud string
+	FechaLimite   time.Time
+	AtendidoPor   model.NullUUID
+	SedeID        model.NullUUID
+	SedeNombre    model.NullString
+}
+
+// TenantsConReclamosAbiertos tenants activos con al menos un reclamo abierto con plazo.
+func (r *AlertaSLARepo) TenantsConReclamosAbiertos(ctx context.Context) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT r.tenant_id
+		FROM reclamos r
+		JOIN configuracion_tenant t ON t.tenant_id = r.tenant_id AND t.activo = true
+		WHERE r.deleted_at IS NULL
+		  AND r.estado IN ('PENDIENTE', 'EN_PROCESO')
+		  AND r.fecha_limite_respuesta IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("alerta_sla_repo.TenantsConReclamosAbiertos: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("alerta_sla_repo.TenantsConReclamosAbiertos scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ReclamosAbiertos reclamos PENDIENTE / EN_PROCESO del tenant con fecha límite.
+func (r *AlertaSLARepo) ReclamosAbiertos(ctx context.Context, tenantID uuid.UUID) ([]ReclamoAbiertoSLA, error) {
+	query := `
+		SELECT id, codigo_reclamo, nombre_completo, tipo_solicitud,
+			fecha_limite_respuesta, atendido_por, sede_id, sede_nombre
+		FROM reclamos
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		  AND estado IN ('PENDIENTE', 'EN_PROCESO')
+		  AND fecha_limite_respuesta IS NOT NULL
+		ORDER BY fecha_limite_respuesta ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("alerta_sla_repo.ReclamosAbiertos: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ReclamoAbiertoSLA
+	for rows.Next() {
+		var rec ReclamoAbiertoSLA
+		if err := rows.Scan(
+			&rec.ID, &rec.Codigo, &rec.Consumidor, &rec.TipoSolicitud,
+			&rec.FechaLimite, &rec.AtendidoPor, &rec.SedeID, &rec.SedeNombre,
+		); err != nil {
+			return nil, fmt.Errorf("alerta_sla_repo.ReclamosAbiertos scan: %w", err)
+		}
+		result = append(result, rec)
+	}
+	return result, rows.Err()
+}
+
+// ── Deduplicación ──
+
+// ClaveAlerta identifica un evento ya notificado.
+func ClaveAlerta(reclamoID uuid.UUID, tipo string, fechaLimite time.Time) string {
+	return reclamoID.String() + "|" + tipo + "|" + fechaLimite.Format("2006-01-02")
+}
+
+// Enviadas retorna las claves de alertas ya notificadas del tenant.
+func (r *AlertaSLARepo) Enviadas(ctx context.Context, tenantID uuid.UUID) (map[string]bool, error) {
+	query := `
+		SELECT reclamo_id, tipo, fecha_limite
+		FROM alertas_sla_enviadas
+		WHERE tenant_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("alerta_sla_repo.Enviadas: %w", err)
+	}
+	defer rows.Close()
+
+	enviadas := make(map[string]bool)
+	for rows.Next() {
+		var id uuid.UUID
+		var tipo string
+		var fecha time.Time
+		if err := rows.Scan(&id, &tipo, &fecha); err != nil {
+			return nil, fmt.Errorf("alerta_sla_repo.Enviadas scan: %w", err)
+		}
+		enviadas[ClaveAlerta(id, tipo, fecha)] = true
+	}
+	return enviadas, rows.Err()
+}
+
+// RegistrarEnvio marca la alerta como notificada (idempotente).
+func (r *AlertaSLARepo) RegistrarEnvio(ctx context.Context, tenantID uuid.UUID, a model.AlertaSLA, destinatarios int) error {
+	query := `
+		INSERT INTO alertas_sla_enviadas (tenant_id, reclamo_id, tipo, fecha_limite, destinatarios)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query,
+		tenantID, a.ReclamoID, a.Tipo, a.FechaLimite.Format("2006-01-02"), destinatarios,
+	)
+	if err != nil {
+		return fmt.Errorf("alerta_sla_repo.RegistrarEnvio: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// AsistenteAccionRepo acciones de escritura propuestas por el asistente IA.
+type AsistenteAccionRepo struct {
+	db *sql.DB
+}
+
+func NewAsistenteAccionRepo(db *sql.DB) *AsistenteAccionRepo {
+	return &AsistenteAccionRepo{db: db}
+}
+
+const columnasAsistenteAccion = `
+	tenant_id, id, usuario_id, conversacion_id, reclamo_id,
+	herramienta, argumentos, resumen, estado, resultado,
+	fecha_creacion, fecha_resolucion`
+
+func scanAsistenteAccion(row interface{ Scan(...interface{}) error }, a *model.AsistenteAccion) error {
+	var args []byte
+	err := row.Scan(
+		&a.TenantID, &a.ID, &a.UsuarioID, &a.ConversacionID, &a.ReclamoID,
+		&a.Herramienta, &args, &a.Resumen, &a.Estado, &a.Resultado,
+		&a.FechaCreacion, &a.FechaResolucion,
+	)
+	a.Argumentos = args
+	return err
+}
+
+// Crear registra la acción como PENDIENTE.
+func (r *AsistenteAccionRepo) Crear(ctx context.Context, a *model.AsistenteAccion) error {
+	query := `
+		INSERT INTO asistente_acciones (tenant_id, usuario_id, conversacion_id, reclamo_id, herramienta, argumentos, resumen)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, estado, fecha_creacion`
+
+	err := r.db.QueryRowContext(ctx, query,
+		a.TenantID, a.UsuarioID, a.ConversacionID, a.ReclamoID, a.Herramienta, []byte(a.Argumentos), a.Resumen,
+	).Scan(&a.ID, &a.Estado, &a.FechaCreacion)
+	if err != nil {
+		return fmt.Errorf("asistente_accion_repo.Crear: %w", err)
+	}
+	return nil
+}
+
+// GetByID acción del usuario. Retorna nil si no existe o es de otro usuario.
+func (r *AsistenteAccionRepo) GetByID(ctx context.Context, tenantID, usuarioID, id uuid.UUID) (*model.AsistenteAccion, error) {
+	query := `SELECT ` + columnasAsistenteAccion + `
+		FROM asistente_acciones
+		WHERE tenant_id = $1 AND id = $2 AND usuario_id = $3`
+
+	var a model.AsistenteAccion
+	err := scanAsistenteAccion(r.db.QueryRowContext(ctx, query, tenantID, id, usuarioID), &a)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("asistente_accion_repo.GetByID: %w", err)
+	}
+	return &a, nil
+}
+
+// Pendientes acciones PENDIENTES de una conversación, en orden de creación.
+func (r *AsistenteAccionRepo) Pendientes(ctx context.Context, tenantID, conversacionID uuid.UUID) ([]model.AsistenteAccion, error) {
+	query := `SELECT ` + columnasAsistenteAccion + `
+		FROM asistente_acciones
+		WHERE tenant_id = $1 AND conversacion_id = $2 AND estado = 'PENDIENTE'
+		  AND fecha_creacion > now() - $3 * INTERVAL '1 second'
+		ORDER BY fecha_creacion`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, conversacionID, int64(model.VigenciaAccionAsistente.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("asistente_accion_repo.Pendientes: %w", err)
+	}
+	defer rows.Close()
+
+	acciones := make([]model.AsistenteAccion, 0)
+	for rows.Next() {
+		var a model.AsistenteAccion
+		if err := scanAsistenteAccion(rows, &a); err != nil {
+			return nil, fmt.Errorf("asistente_accion_repo.Pendientes scan: %w", err)
+		}
+		acciones = append(acciones, a)
+	}
+	return acciones, rows.Err()
+}
+
+// Resolver pasa la acción de PENDIENTE (y vigente) al estado indicado.
+// Retorna false si ya estaba resuelta o venció: así una acción no se
+// ejecuta dos veces aunque se confirme en paralelo.
+func (r *AsistenteAccionRepo) Resolver(ctx context.Context, tenantID, id uuid.UUID, estado string) (bool, error) {
+	query := `
+		UPDATE asistente_acciones
+		SET estado = $3, fecha_resolucion = now()
+		WHERE tenant_id = $1 AND id = $2 AND estado = 'PENDIENTE'
+		  AND fecha_creacion > now() - $4 * INTERVAL '1 second'`
+
+	res, err := r.db.ExecContext(ctx, query, tenantID, id, estado, int64(model.VigenciaAccionAsistente.Seconds()))
+	if err != nil {
+		return false, fmt.Errorf("asistente_accion_repo.Resolver: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("asistente_accion_repo.Resolver: %w", err)
+	}
+	return n == 1, nil
+}
+
+// RegistrarResultado guarda el resultado de la ejecución (o el error, con FALLIDA).
+func (r *AsistenteAccionRepo) RegistrarResultado(ctx context.Context, tenantID, id uuid.UUID, estado, resultado string) error {
+	query := `UPDATE asistente_acciones SET estado = $3, resultado = $4 WHERE tenant_id = $1 AND id = $2`
+	if _, err := r.db.ExecContext(ctx, query, tenantID, id, estado, resultado); err != nil {
+		return fmt.Errorf("asistente_accion_repo.RegistrarResultado: %w", err)
+	}
+	return nil
+}
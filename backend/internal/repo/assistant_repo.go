@@ -30,8 +30,9 @@ type EstadisticasReclamos struct {
 	Vencidos   int `json:"vencidos"`
 }
 
-// GetEstadisticas retorna conteos reales por estado para un tenant.
-func (r *AssistantRepo) GetEstadisticas(ctx context.Context, tenantID uuid.UUID) (*EstadisticasReclamos, error) {
+// GetEstadisticas retorna conteos reales por estado para un tenant
+// (solo de la sede indicada si sedeID no es nil).
+func (r *AssistantRepo) GetEstadisticas(ctx context.Context, tenantID uuid.UUID, sedeID *uuid.UUID) (*EstadisticasReclamos, error) {
 	query := `
 		SELECT
 			COUNT(*) AS total,
@@ -42,10 +43,11 @@ func (r *AssistantRepo) GetEstadisticas(ctx context.Context, tenantID uuid.UUID)
 			COUNT(*) FILTER (WHERE estado = 'RECHAZADO') AS rechazados,
 			COUNT(*) FILTER (WHERE estado IN ('PENDIENTE','EN_PROCESO') AND fecha_limite_respuesta < CURRENT_DATE) AS vencidos
 		FROM reclamos
-		WHERE tenant_id = $1 AND deleted_at IS NULL`
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		  AND ($2::UUID IS NULL OR sede_id = $2)`
 
 	e := &EstadisticasReclamos{}
-	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+	err := r.db.QueryRowContext(ctx, query, tenantID, sedeID).Scan(
 		&e.Total, &e.Pendientes, &e.EnProceso, &e.Resueltos,
 		&e.Cerrados, &e.Rechazados, &e.Vencidos,
 	)
@@ -183,4 +185,63 @@ func (r *AssistantRepo) scanReclamos(ctx context.Context, query string, tenantID
 		reclamos = append(reclamos, rec)
 	}
 	return reclamos, rows.Err()
-}
\ No newline at end of file
+}
+
+// MetricasSede conteos de reclamos de una sede en un rango de fechas.
+type MetricasSede struct {
+	Sede                   string  `json:"sede"`
+	Total                  int     `json:"total"`
+	Reclamos               int     `json:"reclamos"`
+	Quejas                 int     `json:"quejas"`
+	Pendientes             int     `json:"pendientes"`
+	EnProceso              int     `json:"en_proceso"`
+	Atendidos              int     `json:"atendidos"`
+	Vencidos               int     `json:"vencidos"`
+	PromedioDiasResolucion float64 `json:"promedio_dias_resolucion"`
+}
+
+// MetricasPorSede agrupa por sede los reclamos registrados en [desde, hasta)
+// (sin límite si son nil). Con sedeID solo retorna esa sede.
+func (r *AssistantRepo) MetricasPorSede(ctx context.Context, tenantID uuid.UUID, sedeID *uuid.UUID, desde, hasta *time.Time) ([]MetricasSede, error) {
+	query := `
+		SELECT
+			COALESCE(sede_nombre, 'Sin sede') AS sede,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE tipo_solicitud = 'RECLAMO'),
+			COUNT(*) FILTER (WHERE tipo_solicitud = 'QUEJA'),
+			COUNT(*) FILTER (WHERE estado = 'PENDIENTE'),
+			COUNT(*) FILTER (WHERE estado = 'EN_PROCESO'),
+			COUNT(*) FILTER (WHERE estado IN ('RESUELTO', 'CERRADO', 'RECHAZADO')),
+			COUNT(*) FILTER (WHERE estado IN ('PENDIENTE', 'EN_PROCESO') AND fecha_limite_respuesta < CURRENT_DATE),
+			COALESCE(ROUND(AVG(
+				CASE WHEN fecha_respuesta IS NOT NULL
+				THEN EXTRACT(EPOCH FROM (fecha_respuesta - fecha_registro)) / 86400.0 END
+			)::NUMERIC, 1), 0)::FLOAT8
+		FROM reclamos
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		  AND ($2::UUID IS NULL OR sede_id = $2)
+		  AND ($3::TIMESTAMPTZ IS NULL OR fecha_registro >= $3)
+		  AND ($4::TIMESTAMPTZ IS NULL OR fecha_registro < $4)
+		GROUP BY sede
+		ORDER BY 2 DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, sedeID, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("assistant_repo.MetricasPorSede: %w", err)
+	}
+	defer rows.Close()
+
+	metricas := make([]MetricasSede, 0)
+	for rows.Next() {
+		var m MetricasSede
+		if err := rows.Scan(
+			&m.Sede, &m.Total, &m.Reclamos, &m.Quejas,
+			&m.Pendientes, &m.EnProceso, &m.Atendidos, &m.Vencidos,
+			&m.PromedioDiasResolucion,
+		); err != nil {
+			return nil, fmt.Errorf("assistant_repo.MetricasPorSede scan: %w", err)
+		}
+		metricas = append(metricas, m)
+	}
+	return metricas, rows.Err()
+}
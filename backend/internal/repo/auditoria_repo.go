@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"libro-reclamaciones/internal/model"
 
@@ -56,4 +57,91 @@ func (r *AuditoriaRepo) GetByTenant(ctx context.Context, tenantID uuid.UUID, lim
 		auditorias = append(auditorias, a)
 	}
 	return auditorias, rows.Err()
-}
\ No newline at end of file
+}
+
+// FiltrosAuditoria filtros opcionales del listado de auditoría.
+type FiltrosAuditoria struct {
+	TenantID   uuid.UUID
+	UsuarioID  *uuid.UUID
+	Entidad    string
+	EntidadID  string
+	Accion     string
+	FechaDesde *time.Time
+	FechaHasta *time.Time
+}
+
+// Listar retorna la auditoría filtrada (más reciente primero) y el total sin paginar.
+// Incluye JOIN con usuarios_admin para el nombre del usuario.
+func (r *AuditoriaRepo) Listar(ctx context.Context, f FiltrosAuditoria, limit, offset int) ([]model.Auditoria, int, error) {
+	where := "a.tenant_id = $1"
+	args := []interface{}{f.TenantID}
+	argIdx := 2
+
+	if f.UsuarioID != nil {
+		where += fmt.Sprintf(" AND a.usuario_id = $%d", argIdx)
+		args = append(args, *f.UsuarioID)
+		argIdx++
+	}
+	if f.Entidad != "" {
+		where += fmt.Sprintf(" AND a.entidad = $%d", argIdx)
+		args = append(args, f.Entidad)
+		argIdx++
+	}
+	if f.EntidadID != "" {
+		where += fmt.Sprintf(" AND a.entidad_id = $%d", argIdx)
+		args = append(args, f.EntidadID)
+		argIdx++
+	}
+	if f.Accion != "" {
+		where += fmt.Sprintf(" AND a.accion = $%d", argIdx)
+		args = append(args, f.Accion)
+		argIdx++
+	}
+	if f.FechaDesde != nil {
+		where += fmt.Sprintf(" AND a.fecha >= $%d", argIdx)
+		args = append(args, *f.FechaDesde)
+		argIdx++
+	}
+	if f.FechaHasta != nil {
+		where += fmt.Sprintf(" AND a.fecha <= $%d", argIdx)
+		args = append(args, *f.FechaHasta)
+		argIdx++
+	}
+
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM auditoria_admin a WHERE %s", where)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("auditoria_repo.Listar count: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT a.tenant_id, a.id, a.usuario_id, a.accion, a.entidad, a.entidad_id,
+			a.detalles, a.ip_address, a.fecha,
+			COALESCE(ua.nombre_completo, '')
+		FROM auditoria_admin a
+		LEFT JOIN usuarios_admin ua ON a.tenant_id = ua.tenant_id AND a.usuario_id = ua.id
+		WHERE %s
+		ORDER BY a.fecha DESC
+		LIMIT $%d OFFSET $%d`, where, argIdx, argIdx+1)
+	args = append(args, limit, offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("auditoria_repo.Listar: %w", err)
+	}
+	defer rows.Close()
+
+	var auditorias []model.Auditoria
+	for rows.Next() {
+		var a model.Auditoria
+		if err := rows.Scan(
+			&a.TenantID, &a.ID, &a.UsuarioID, &a.Accion, &a.Entidad, &a.EntidadID,
+			&a.Detalles, &a.IPAddress, &a.Fecha,
+			&a.NombreUsuario,
+		); err != nil {
+			return nil, 0, fmt.Errorf("auditoria_repo.scan: %w", err)
+		}
+		auditorias = append(auditorias, a)
+	}
+	return auditorias, total, rows.Err()
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type CalendarioRepo struct {
+	db *sql.DB
+}
+
+func NewCalendarioRepo(db *sql.DB) *CalendarioRepo {
+	return &CalendarioRepo{db: db}
+}
+
+// ── Configuración ──
+
+// ObtenerConfig retorna la configuración del tenant. nil si no tiene fila propia.
+func (r *CalendarioRepo) ObtenerConfig(ctx context.Context, tenantID uuid.UUID) (*model.CalendarioConfig, error) {
+	query := `
+		SELECT tenant_id, dias_habiles, zona_horaria, dias_no_laborables,
+			incluir_feriados_nacionales, version, fecha_creacion, fecha_actualizacion
+		FROM calendarios_sla
+		WHERE tenant_id = $1`
+
+	c := &model.CalendarioConfig{}
+	var dias string
+	err := r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&c.TenantID, &c.DiasHabiles, &c.ZonaHoraria, &dias,
+		&c.IncluirFeriadosNacionales, &c.Version, &c.FechaCreacion, &c.FechaActualizacion,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("calendario_repo.ObtenerConfig: %w", err)
+	}
+	c.DiasNoLaborables = parseDiasSemana(dias)
+	return c, nil
+}
+
+// GuardarConfig inserta o actualiza la configuración e incrementa la versión.
+func (r *CalendarioRepo) GuardarConfig(ctx context.Context, c *model.CalendarioConfig) error {
+	query := `
+		INSERT INTO calendarios_sla (
+			tenant_id, dias_habiles, zona_horaria, dias_no_laborables, incluir_feriados_nacionales
+		) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			dias_habiles = excluded.dias_habiles,
+			zona_horaria = excluded.zona_horaria,
+			dias_no_laborables = excluded.dias_no_laborables,
+			incluir_feriados_nacionales = excluded.incluir_feriados_nacionales,
+			version = calendarios_sla.version + 1,
+			fecha_actualizacion = now()
+		RETURNING version, fecha_creacion, fecha_actualizacion`
+
+	err := r.db.QueryRowContext(ctx, query,
+		c.TenantID, c.DiasHabiles, c.ZonaHoraria, joinDiasSemana(c.DiasNoLaborables), c.IncluirFeriadosNacionales,
+	).Scan(&c.Version, &c.FechaCreacion, &c.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("calendario_repo.GuardarConfig: %w", err)
+	}
+	return nil
+}
+
+// ── Feriados ──
+
+func (r *CalendarioRepo) ListarFeriados(ctx context.Context, tenantID uuid.UUID) ([]model.Feriado, error) {
+	query := `
+		SELECT tenant_id, id, fecha, descripcion, recurrente, fecha_creacion
+		FROM feriados_tenant
+		WHERE tenant_id = $1
+		ORDER BY fecha ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("calendario_repo.ListarFeriados: %w", err)
+	}
+	defer rows.Close()
+
+	var feriados []model.Feriado
+	for rows.Next() {
+		var f model.Feriado
+		if err := rows.Scan(&f.TenantID, &f.ID, &f.Fecha, &f.Descripcion, &f.Recurrente, &f.FechaCreacion); err != nil {
+			return nil, fmt.Errorf("calendario_repo.ListarFeriados scan: %w", err)
+		}
+		feriados = append(feriados, f)
+	}
+	return feriados, rows.Err()
+}
+
+func (r *CalendarioRepo) CrearFeriado(ctx context.Context, f *model.Feriado) error {
+	query := `
+		INSERT INTO feriados_tenant (tenant_id, fecha, descripcion, recurrente)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, fecha_creacion`
+
+	err := r.db.QueryRowContext(ctx, query,
+		f.TenantID, f.Fecha.Format("2006-01-02"), f.Descripcion, f.Recurrente,
+	).Scan(&f.ID, &f.FechaCreacion)
+	if err != nil {
+		return fmt.Errorf("calendario_repo.CrearFeriado: %w", err)
+	}
+	return nil
+}
+
+// EliminarFeriado retorna false si el feriado no existía.
+func (r *CalendarioRepo) EliminarFeriado(ctx context.Context, tenantID, feriadoID uuid.UUID) (bool, error) {
+	query := `DELETE FROM feriados_tenant WHERE tenant_id = $1 AND id = $2`
+	res, err := r.db.ExecContext(ctx, query, tenantID, feriadoID)
+	if err != nil {
+		return false, fmt.Errorf("calendario_repo.EliminarFeriado: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ── Helpers ──
+
+// parseDiasSemana convierte "0,6" → []int{0, 6}. Ignora valores fuera de rango.
+func parseDiasSemana(s string) []int {
+	var dias []int
+	for _, p := range strings.Split(s, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || d < int(time.Sunday) || d > int(time.Saturday) {
+			continue
+		}
+		dias = append(dias, d)
+	}
+	return dias
+}
+
+func joinDiasSemana(dias []int) string {
+	partes := make([]string, 0, len(dias))
+	for _, d := range dias {
+		partes = append(partes, strconv.Itoa(d))
+	}
+	return strings.Join(partes, ",")
+}
@@ -11,30 +11,75 @@ import (
 	"github.com/google/uuid"
 )
 
+// CifradorSecretos cifra los tokens del canal en reposo (cifrado.Secretos).
+type CifradorSecretos interface {
+	Cifrar(ctx context.Context, tenantID uuid.UUID, campo, texto string) (string, error)
+	Descifrar(ctx context.Context, tenantID uuid.UUID, campo, valor string) (string, error)
+	Vigente(ctx context.Context, tenantID uuid.UUID, valor string) (bool, error)
+}
+
+// CanalWhatsAppRepo guarda access_token, verify_token y app_secret cifrados
+// y los entrega descifrados. Con secretos nil (desarrollo sin clave maestra)
+// se guardan en claro.
 type CanalWhatsAppRepo struct {
-	db *sql.DB
+	db       *sql.DB
+	secretos CifradorSecretos
 }
 
-func NewCanalWhatsAppRepo(db *sql.DB) *CanalWhatsAppRepo {
-	return &CanalWhatsAppRepo{db: db}
+func NewCanalWhatsAppRepo(db *sql.DB, secretos CifradorSecretos) *CanalWhatsAppRepo {
+	return &CanalWhatsAppRepo{db: db, secretos: secretos}
 }
 
 // Columnas centralizadas para evitar repetición
 const canalWAColumns = `
 	tenant_id, id, phone_number_id, display_phone,
-	access_token, verify_token, nombre_canal, chatbot_id, activo,
+	access_token, verify_token, app_secret, nombre_canal, chatbot_id, activo,
 	fecha_creacion, fecha_actualizacion`
 
 func scanCanalWA(scanner interface{ Scan(...interface{}) error }) (*model.CanalWhatsApp, error) {
 	c := &model.CanalWhatsApp{}
 	err := scanner.Scan(
 		&c.TenantID, &c.ID, &c.PhoneNumberID, &c.DisplayPhone,
-		&c.AccessToken, &c.VerifyToken, &c.NombreCanal, &c.ChatbotID, &c.Activo,
+		&c.AccessToken, &c.VerifyToken, &c.AppSecret, &c.NombreCanal, &c.ChatbotID, &c.Activo,
 		&c.FechaCreacion, &c.FechaActualizacion,
 	)
 	return c, err
 }
 
+// columnasSecretasCanalWA columnas cifradas, en el orden de cifrar y descifrar.
+var columnasSecretasCanalWA = [3]string{"access_token", "verify_token", "app_secret"}
+
+// descifrar reemplaza en c los tokens guardados por su valor en claro.
+func (r *CanalWhatsAppRepo) descifrar(ctx context.Context, c *model.CanalWhatsApp) error {
+	if r.secretos == nil {
+		return nil
+	}
+	for i, campo := range []*string{&c.AccessToken, &c.VerifyToken, &c.AppSecret} {
+		texto, err := r.secretos.Descifrar(ctx, c.TenantID, columnasSecretasCanalWA[i], *campo)
+		if err != nil {
+			return fmt.Errorf("canal %s: %w", c.ID, err)
+		}
+		*campo = texto
+	}
+	return nil
+}
+
+// cifrar retorna los tokens de c tal como se guardan (access, verify, app_secret).
+func (r *CanalWhatsAppRepo) cifrar(ctx context.Context, c *model.CanalWhatsApp) (string, string, string, error) {
+	if r.secretos == nil {
+		return c.AccessToken, c.VerifyToken, c.AppSecret, nil
+	}
+	var cifrados [3]string
+	for i, texto := range []string{c.AccessToken, c.VerifyToken, c.AppSecret} {
+		v, err := r.secretos.Cifrar(ctx, c.TenantID, columnasSecretasCanalWA[i], texto)
+		if err != nil {
+			return "", "", "", err
+		}
+		cifrados[i] = v
+	}
+	return cifrados[0], cifrados[1], cifrados[2], nil
+}
+
 // GetByPhoneNumberID busca el canal activo asociado a un phone_number_id de Meta.
 // Esta es la query clave para resolver tenant dinámicamente en el webhook.
 func (r *CanalWhatsAppRepo) GetByPhoneNumberID(ctx context.Context, phoneNumberID string) (*model.CanalWhatsApp, error) {
@@ -50,6 +95,9 @@ func (r *CanalWhatsAppRepo) GetByPhoneNumberID(ctx context.Context, phoneNumberI
 	if err != nil {
 		return nil, fmt.Errorf("canal_whatsapp_repo.GetByPhoneNumberID: %w", err)
 	}
+	if err := r.descifrar(ctx, c); err != nil {
+		return nil, fmt.Errorf("canal_whatsapp_repo.GetByPhoneNumberID: %w", err)
+	}
 	return c, nil
 }
 
@@ -72,6 +120,9 @@ func (r *CanalWhatsAppRepo) GetByTenant(ctx context.Context, tenantID uuid.UUID)
 		if err != nil {
 			return nil, fmt.Errorf("canal_whatsapp_repo.scan: %w", err)
 		}
+		if err := r.descifrar(ctx, c); err != nil {
+			return nil, fmt.Errorf("canal_whatsapp_repo.GetByTenant: %w", err)
+		}
 		canales = append(canales, *c)
 	}
 	return canales, rows.Err()
@@ -90,37 +141,50 @@ func (r *CanalWhatsAppRepo) GetByID(ctx context.Context, tenantID, canalID uuid.
 	if err != nil {
 		return nil, fmt.Errorf("canal_whatsapp_repo.GetByID: %w", err)
 	}
+	if err := r.descifrar(ctx, c); err != nil {
+		return nil, fmt.Errorf("canal_whatsapp_repo.GetByID: %w", err)
+	}
 	return c, nil
 }
 
 // Create inserta un nuevo canal WhatsApp para un tenant.
 func (r *CanalWhatsAppRepo) Create(ctx context.Context, c *model.CanalWhatsApp) error {
+	accessToken, verifyToken, appSecret, err := r.cifrar(ctx, c)
+	if err != nil {
+		return fmt.Errorf("canal_whatsapp_repo.Create: %w", err)
+	}
+
 	query := `
 		INSERT INTO canales_whatsapp (
 			tenant_id, phone_number_id, display_phone,
-			access_token, verify_token, nombre_canal, chatbot_id
-		) VALUES ($1,$2,$3,$4,$5,$6,$7)
+			access_token, verify_token, app_secret, nombre_canal, chatbot_id
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
 		RETURNING id, activo, fecha_creacion, fecha_actualizacion`
 
 	return r.db.QueryRowContext(ctx, query,
 		c.TenantID, c.PhoneNumberID, c.DisplayPhone,
-		c.AccessToken, c.VerifyToken, c.NombreCanal, c.ChatbotID,
+		accessToken, verifyToken, appSecret, c.NombreCanal, c.ChatbotID,
 	).Scan(&c.ID, &c.Activo, &c.FechaCreacion, &c.FechaActualizacion)
 }
 
 // Update actualiza los campos editables de un canal.
 func (r *CanalWhatsAppRepo) Update(ctx context.Context, c *model.CanalWhatsApp) error {
+	accessToken, verifyToken, appSecret, err := r.cifrar(ctx, c)
+	if err != nil {
+		return fmt.Errorf("canal_whatsapp_repo.Update: %w", err)
+	}
+
 	query := `
 		UPDATE canales_whatsapp SET
 			phone_number_id = $1, display_phone = $2,
-			access_token = $3, verify_token = $4,
-			nombre_canal = $5, chatbot_id = $6, activo = $7,
-			fecha_actualizacion = $8
-		WHERE tenant_id = $9 AND id = $10`
+			access_token = $3, verify_token = $4, app_secret = $5,
+			nombre_canal = $6, chatbot_id = $7, activo = $8,
+			fecha_actualizacion = $9
+		WHERE tenant_id = $10 AND id = $11`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = r.db.ExecContext(ctx, query,
 		c.PhoneNumberID, c.DisplayPhone,
-		c.AccessToken, c.VerifyToken,
+		accessToken, verifyToken, appSecret,
 		c.NombreCanal, c.ChatbotID, c.Activo,
 		time.Now(), c.TenantID, c.ID,
 	)
@@ -138,4 +202,87 @@ func (r *CanalWhatsAppRepo) Deactivate(ctx context.Context, tenantID, canalID uu
 		return fmt.Errorf("canal_whatsapp_repo.Deactivate: %w", err)
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// TenantsConSecretos tenants con algún canal (activo o no) que guarda tokens.
+func (r *CanalWhatsAppRepo) TenantsConSecretos(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT tenant_id FROM canales_whatsapp ORDER BY tenant_id`)
+	if err != nil {
+		return nil, fmt.Errorf("canal_whatsapp_repo.TenantsConSecretos: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("canal_whatsapp_repo.TenantsConSecretos scan: %w", err)
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants, rows.Err()
+}
+
+// RecifrarSecretos vuelve a cifrar con la clave de datos activa los tokens de
+// los canales del tenant que siguen en claro o con una clave anterior. Solo
+// toca las columnas de tokens, y solo si no cambiaron desde que se leyeron:
+// un Update concurrente ya los guarda cifrados y no se pisa con los viejos.
+// Retorna cuántos canales actualizó.
+func (r *CanalWhatsAppRepo) RecifrarSecretos(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	if r.secretos == nil {
+		return 0, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: sin clave maestra configurada")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+canalWAColumns+` FROM canales_whatsapp WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: %w", err)
+	}
+	var pendientes []*model.CanalWhatsApp
+	for rows.Next() {
+		c, err := scanCanalWA(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos scan: %w", err)
+		}
+		for _, v := range []string{c.AccessToken, c.VerifyToken, c.AppSecret} {
+			vigente, err := r.secretos.Vigente(ctx, tenantID, v)
+			if err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: %w", err)
+			}
+			if !vigente {
+				pendientes = append(pendientes, c)
+				break
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: %w", err)
+	}
+
+	actualizados := 0
+	for _, c := range pendientes {
+		leidos := [3]string{c.AccessToken, c.VerifyToken, c.AppSecret}
+		if err := r.descifrar(ctx, c); err != nil {
+			return actualizados, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: %w", err)
+		}
+		accessToken, verifyToken, appSecret, err := r.cifrar(ctx, c)
+		if err != nil {
+			return actualizados, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: %w", err)
+		}
+		res, err := r.db.ExecContext(ctx, `
+			UPDATE canales_whatsapp SET access_token = $1, verify_token = $2, app_secret = $3
+			WHERE tenant_id = $4 AND id = $5
+			  AND access_token = $6 AND verify_token = $7 AND app_secret = $8`,
+			accessToken, verifyToken, appSecret, tenantID, c.ID, leidos[0], leidos[1], leidos[2],
+		)
+		if err != nil {
+			return actualizados, fmt.Errorf("canal_whatsapp_repo.RecifrarSecretos: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			actualizados++
+		}
+	}
+	return actualizados, nil
+}
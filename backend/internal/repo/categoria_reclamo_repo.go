@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// CategoriaReclamoRepo taxonomía de categorías del tenant (clasificación IA).
+type CategoriaReclamoRepo struct {
+	db *sql.DB
+}
+
+func NewCategoriaReclamoRepo(db *sql.DB) *CategoriaReclamoRepo {
+	return &CategoriaReclamoRepo{db: db}
+}
+
+const columnasCategoriaReclamo = `tenant_id, id, nombre, descripcion, activo, fecha_creacion, fecha_actualizacion`
+
+func scanCategoriaReclamo(row interface{ Scan(...interface{}) error }, c *model.CategoriaReclamo) error {
+	return row.Scan(&c.TenantID, &c.ID, &c.Nombre, &c.Descripcion, &c.Activo, &c.FechaCreacion, &c.FechaActualizacion)
+}
+
+// Listar categorías del tenant por nombre. soloActivas = las que usa la IA.
+func (r *CategoriaReclamoRepo) Listar(ctx context.Context, tenantID uuid.UUID, soloActivas bool) ([]model.CategoriaReclamo, error) {
+	query := `SELECT ` + columnasCategoriaReclamo + `
+		FROM categorias_reclamo
+		WHERE tenant_id = $1 AND ($2 = false OR activo = true)
+		ORDER BY nombre`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, soloActivas)
+	if err != nil {
+		return nil, fmt.Errorf("categoria_reclamo_repo.Listar: %w", err)
+	}
+	defer rows.Close()
+
+	categorias := make([]model.CategoriaReclamo, 0)
+	for rows.Next() {
+		var c model.CategoriaReclamo
+		if err := scanCategoriaReclamo(rows, &c); err != nil {
+			return nil, fmt.Errorf("categoria_reclamo_repo.Listar scan: %w", err)
+		}
+		categorias = append(categorias, c)
+	}
+	return categorias, rows.Err()
+}
+
+// GetByID retorna nil si no existe.
+func (r *CategoriaReclamoRepo) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*model.CategoriaReclamo, error) {
+	query := `SELECT ` + columnasCategoriaReclamo + ` FROM categorias_reclamo WHERE tenant_id = $1 AND id = $2`
+
+	var c model.CategoriaReclamo
+	err := scanCategoriaReclamo(r.db.QueryRowContext(ctx, query, tenantID, id), &c)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("categoria_reclamo_repo.GetByID: %w", err)
+	}
+	return &c, nil
+}
+
+// ExisteNombre indica si otra categoría del tenant ya usa el nombre (sin
+// distinguir mayúsculas). excluirID permite renombrar la misma categoría.
+func (r *CategoriaReclamoRepo) ExisteNombre(ctx context.Context, tenantID uuid.UUID, nombre string, excluirID uuid.UUID) (bool, error) {
+	var existe bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM categorias_reclamo
+			WHERE tenant_id = $1 AND lower(nombre) = lower($2) AND id <> $3
+		)`, tenantID, nombre, excluirID,
+	).Scan(&existe)
+	if err != nil {
+		return false, fmt.Errorf("categoria_reclamo_repo.ExisteNombre: %w", err)
+	}
+	return existe, nil
+}
+
+func (r *CategoriaReclamoRepo) Create(ctx context.Context, c *model.CategoriaReclamo) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO categorias_reclamo (tenant_id, nombre, descripcion)
+		VALUES ($1, $2, $3)
+		RETURNING id, activo, fecha_creacion, fecha_actualizacion`,
+		c.TenantID, c.Nombre, c.Descripcion,
+	).Scan(&c.ID, &c.Activo, &c.FechaCreacion, &c.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("categoria_reclamo_repo.Create: %w", err)
+	}
+	return nil
+}
+
+// Update cambia nombre, descripción y estado. Los reclamos ya clasificados
+// conservan el nombre anterior (snapshot).
+func (r *CategoriaReclamoRepo) Update(ctx context.Context, c *model.CategoriaReclamo) error {
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE categorias_reclamo
+		SET nombre = $1, descripcion = $2, activo = $3, fecha_actualizacion = now()
+		WHERE tenant_id = $4 AND id = $5
+		RETURNING fecha_actualizacion`,
+		c.Nombre, c.Descripcion, c.Activo, c.TenantID, c.ID,
+	).Scan(&c.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("categoria_reclamo_repo.Update: %w", err)
+	}
+	return nil
+}
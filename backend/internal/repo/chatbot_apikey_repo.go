@@ -76,6 +76,31 @@ func (r *ChatbotAPIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*mod
 	return k, nil
 }
 
+func (r *ChatbotAPIKeyRepo) GetByID(ctx context.Context, tenantID, keyID uuid.UUID) (*model.APIKey, error) {
+	query := `
+		SELECT tenant_id, id, chatbot_id, nombre, key_prefix, key_hash,
+			entorno, activa, fecha_expiracion, ultimo_uso,
+			requests_por_minuto, requests_por_dia,
+			fecha_creacion, creado_por
+		FROM chatbot_api_keys
+		WHERE tenant_id = $1 AND id = $2`
+
+	k := &model.APIKey{}
+	err := r.db.QueryRowContext(ctx, query, tenantID, keyID).Scan(
+		&k.TenantID, &k.ID, &k.ChatbotID, &k.Nombre, &k.KeyPrefix, &k.KeyHash,
+		&k.Entorno, &k.Activa, &k.FechaExpiracion, &k.UltimoUso,
+		&k.RequestsPorMinuto, &k.RequestsPorDia,
+		&k.FechaCreacion, &k.CreadoPor,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("apikey_repo.GetByID: %w", err)
+	}
+	return k, nil
+}
+
 func (r *ChatbotAPIKeyRepo) Create(ctx context.Context, k *model.APIKey) error {
 	query := `
 		INSERT INTO chatbot_api_keys (
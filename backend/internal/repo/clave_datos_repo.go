@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ClaveDatosRepo claves de datos (DEK) por tenant, envueltas con la clave maestra.
+type ClaveDatosRepo struct {
+	db *sql.DB
+}
+
+func NewClaveDatosRepo(db *sql.DB) *ClaveDatosRepo {
+	return &ClaveDatosRepo{db: db}
+}
+
+const columnasClaveDatos = `tenant_id, version, clave_cifrada, version_maestra, activa, fecha_creacion, fecha_actualizacion`
+
+func scanClaveDatos(row interface{ Scan(...interface{}) error }) (*model.ClaveDatosTenant, error) {
+	c := &model.ClaveDatosTenant{}
+	err := row.Scan(&c.TenantID, &c.Version, &c.ClaveCifrada, &c.VersionMaestra, &c.Activa, &c.FechaCreacion, &c.FechaActualizacion)
+	return c, err
+}
+
+// Activa DEK con la que se cifran los secretos nuevos del tenant. nil si aún no tiene.
+func (r *ClaveDatosRepo) Activa(ctx context.Context, tenantID uuid.UUID) (*model.ClaveDatosTenant, error) {
+	query := `SELECT ` + columnasClaveDatos + ` FROM claves_datos_tenant WHERE tenant_id = $1 AND activa = true`
+
+	c, err := scanClaveDatos(r.db.QueryRowContext(ctx, query, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clave_datos_repo.Activa: %w", err)
+	}
+	return c, nil
+}
+
+// GetByVersion DEK de una versión (activa o no). nil si no existe.
+func (r *ClaveDatosRepo) GetByVersion(ctx context.Context, tenantID uuid.UUID, version int) (*model.ClaveDatosTenant, error) {
+	query := `SELECT ` + columnasClaveDatos + ` FROM claves_datos_tenant WHERE tenant_id = $1 AND version = $2`
+
+	c, err := scanClaveDatos(r.db.QueryRowContext(ctx, query, tenantID, version))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clave_datos_repo.GetByVersion: %w", err)
+	}
+	return c, nil
+}
+
+// UltimaVersion versión más alta del tenant (0 si no tiene ninguna).
+func (r *ClaveDatosRepo) UltimaVersion(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(max(version), 0) FROM claves_datos_tenant WHERE tenant_id = $1`, tenantID,
+	).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("clave_datos_repo.UltimaVersion: %w", err)
+	}
+	return version, nil
+}
+
+// CrearPrimera guarda la primera DEK del tenant. Retorna false si otra
+// réplica la creó antes: el llamador debe leer la que quedó.
+func (r *ClaveDatosRepo) CrearPrimera(ctx context.Context, c *model.ClaveDatosTenant) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO claves_datos_tenant (tenant_id, version, clave_cifrada, version_maestra, activa)
+		VALUES ($1, $2, $3, $4, true)
+		ON CONFLICT DO NOTHING`,
+		c.TenantID, c.Version, c.ClaveCifrada, c.VersionMaestra,
+	)
+	if err != nil {
+		return false, fmt.Errorf("clave_datos_repo.CrearPrimera: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// Rotar desactiva la DEK activa del tenant y deja c como la nueva activa.
+// La anterior se conserva para descifrar lo que aún no se re-cifró.
+func (r *ClaveDatosRepo) Rotar(ctx context.Context, c *model.ClaveDatosTenant) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("clave_datos_repo.Rotar: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE claves_datos_tenant SET activa = false, fecha_actualizacion = now()
+		WHERE tenant_id = $1 AND activa = true`, c.TenantID,
+	); err != nil {
+		return fmt.Errorf("clave_datos_repo.Rotar desactivar: %w", err)
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO claves_datos_tenant (tenant_id, version, clave_cifrada, version_maestra, activa)
+		VALUES ($1, $2, $3, $4, true)
+		RETURNING activa, fecha_creacion, fecha_actualizacion`,
+		c.TenantID, c.Version, c.ClaveCifrada, c.VersionMaestra,
+	).Scan(&c.Activa, &c.FechaCreacion, &c.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("clave_datos_repo.Rotar: %w", err)
+	}
+	return tx.Commit()
+}
+
+// EnvueltasConOtraMaestra DEK (de cualquier tenant) que no están envueltas con
+// la versión maestra indicada: las que hay que re-envolver al rotarla.
+func (r *ClaveDatosRepo) EnvueltasConOtraMaestra(ctx context.Context, versionMaestra int) ([]model.ClaveDatosTenant, error) {
+	query := `SELECT ` + columnasClaveDatos + `
+		FROM claves_datos_tenant
+		WHERE version_maestra <> $1
+		ORDER BY tenant_id, version`
+
+	rows, err := r.db.QueryContext(ctx, query, versionMaestra)
+	if err != nil {
+		return nil, fmt.Errorf("clave_datos_repo.EnvueltasConOtraMaestra: %w", err)
+	}
+	defer rows.Close()
+
+	var claves []model.ClaveDatosTenant
+	for rows.Next() {
+		c, err := scanClaveDatos(rows)
+		if err != nil {
+			return nil, fmt.Errorf("clave_datos_repo.EnvueltasConOtraMaestra scan: %w", err)
+		}
+		claves = append(claves, *c)
+	}
+	return claves, rows.Err()
+}
+
+// Reenvolver guarda la misma DEK envuelta con otra clave maestra.
+func (r *ClaveDatosRepo) Reenvolver(ctx context.Context, c *model.ClaveDatosTenant) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE claves_datos_tenant
+		SET clave_cifrada = $1, version_maestra = $2, fecha_actualizacion = now()
+		WHERE tenant_id = $3 AND version = $4`,
+		c.ClaveCifrada, c.VersionMaestra, c.TenantID, c.Version,
+	)
+	if err != nil {
+		return fmt.Errorf("clave_datos_repo.Reenvolver: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// CodigoAccesoRepo códigos de un solo uso del portal del consumidor.
+type CodigoAccesoRepo struct {
+	db DBTX
+}
+
+func NewCodigoAccesoRepo(db *sql.DB) *CodigoAccesoRepo {
+	return &CodigoAccesoRepo{db: db}
+}
+
+// Create registra un código nuevo (con ID y hash ya calculados) e invalida
+// los vigentes del mismo destino: solo vale el último enviado.
+func (r *CodigoAccesoRepo) Create(ctx context.Context, c *model.CodigoAccesoConsumidor) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE codigos_acceso_consumidor SET usado = true
+		WHERE tenant_id = $1 AND canal = $2 AND destino = $3 AND usado = false`,
+		c.TenantID, c.Canal, c.Destino,
+	)
+	if err != nil {
+		return fmt.Errorf("codigo_acceso_repo.Create invalidar: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO codigos_acceso_consumidor (
+			tenant_id, id, canal, destino, codigo_hash, ip_address, fecha_vencimiento
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING fecha_creacion`,
+		c.TenantID, c.ID, c.Canal, c.Destino, c.CodigoHash, c.IPAddress, c.FechaVencimiento,
+	).Scan(&c.FechaCreacion)
+	if err != nil {
+		return fmt.Errorf("codigo_acceso_repo.Create: %w", err)
+	}
+	return nil
+}
+
+// RegistrarSolicitud deja constancia de una solicitud que no generó código
+// (contacto sin reclamos): cuenta para ContarRecientes igual que un código
+// enviado, pero nace usado y vencido, así que nunca se puede verificar.
+func (r *CodigoAccesoRepo) RegistrarSolicitud(ctx context.Context, tenantID uuid.UUID, canal, destino string, ip model.NullString) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO codigos_acceso_consumidor (
+			tenant_id, canal, destino, codigo_hash, usado, ip_address, fecha_vencimiento
+		) VALUES ($1, $2, $3, '', true, $4, now())`,
+		tenantID, canal, destino, ip,
+	)
+	if err != nil {
+		return fmt.Errorf("codigo_acceso_repo.RegistrarSolicitud: %w", err)
+	}
+	return nil
+}
+
+// ContarRecientes solicitudes de código (enviadas o no) desde `desde` para el destino y desde la IP.
+func (r *CodigoAccesoRepo) ContarRecientes(ctx context.Context, tenantID uuid.UUID, canal, destino, ip string, desde time.Time) (porDestino, porIP int, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		SELECT
+			count(*) FILTER (WHERE canal = $2 AND destino = $3),
+			count(*) FILTER (WHERE ip_address = $4)
+		FROM codigos_acceso_consumidor
+		WHERE tenant_id = $1 AND fecha_creacion >= $5
+		  AND ((canal = $2 AND destino = $3) OR ip_address = $4)`,
+		tenantID, canal, destino, ip, desde,
+	).Scan(&porDestino, &porIP)
+	if err != nil {
+		return 0, 0, fmt.Errorf("codigo_acceso_repo.ContarRecientes: %w", err)
+	}
+	return porDestino, porIP, nil
+}
+
+// ReservarIntento consume un intento del último código sin usar, sin vencer y
+// con intentos disponibles del destino, y lo retorna (nil si no hay). El
+// intento se cuenta antes de comparar y en el mismo UPDATE que verifica el
+// límite: verificaciones en paralelo no suman más de maxIntentos.
+func (r *CodigoAccesoRepo) ReservarIntento(ctx context.Context, tenantID uuid.UUID, canal, destino string, maxIntentos int) (*model.CodigoAccesoConsumidor, error) {
+	c := &model.CodigoAccesoConsumidor{}
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE codigos_acceso_consumidor SET intentos = intentos + 1
+		WHERE tenant_id = $1 AND id = (
+			SELECT id FROM codigos_acceso_consumidor
+			WHERE tenant_id = $1 AND canal = $2 AND destino = $3
+			  AND usado = false AND fecha_vencimiento > now()
+			ORDER BY fecha_creacion DESC
+			LIMIT 1
+		)
+		  AND usado = false AND fecha_vencimiento > now() AND intentos < $4
+		RETURNING tenant_id, id, canal, destino, codigo_hash, intentos, usado,
+			ip_address, fecha_creacion, fecha_vencimiento`,
+		tenantID, canal, destino, maxIntentos,
+	).Scan(
+		&c.TenantID, &c.ID, &c.Canal, &c.Destino, &c.CodigoHash, &c.Intentos, &c.Usado,
+		&c.IPAddress, &c.FechaCreacion, &c.FechaVencimiento,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("codigo_acceso_repo.ReservarIntento: %w", err)
+	}
+	return c, nil
+}
+
+// Consumir marca el código como usado. Retorna false si ya se usó, venció o
+// superó maxIntentos, de modo que dos verificaciones simultáneas no obtienen
+// ambas un token. El intento en curso ya se contó en ReservarIntento, por eso
+// el último permitido llega con intentos = maxIntentos.
+func (r *CodigoAccesoRepo) Consumir(ctx context.Context, tenantID, id uuid.UUID, maxIntentos int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE codigos_acceso_consumidor SET usado = true
+		WHERE tenant_id = $1 AND id = $2 AND usado = false AND fecha_vencimiento > now()
+		  AND intentos <= $3`,
+		tenantID, id, maxIntentos,
+	)
+	if err != nil {
+		return false, fmt.Errorf("codigo_acceso_repo.Consumir: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
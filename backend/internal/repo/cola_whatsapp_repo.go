@@ -0,0 +1,182 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ColaWhatsAppRepo cola durable de mensajes entrantes de WhatsApp.
+type ColaWhatsAppRepo struct {
+	db *sql.DB
+}
+
+func NewColaWhatsAppRepo(db *sql.DB) *ColaWhatsAppRepo {
+	return &ColaWhatsAppRepo{db: db}
+}
+
+const colaWAColumns = `
+	tenant_id, id, phone_number_id, mensaje_id, telefono, tipo, texto, contenido, fecha_mensaje,
+	estado, intentos, max_intentos, proximo_intento, respuesta, ultimo_error,
+	fecha_creacion, fecha_proceso`
+
+func scanColaWA(row interface{ Scan(...any) error }) (*model.MensajeWhatsAppEntrante, error) {
+	m := &model.MensajeWhatsAppEntrante{}
+	var contenido []byte
+	err := row.Scan(
+		&m.TenantID, &m.ID, &m.PhoneNumberID, &m.MensajeID, &m.Telefono, &m.Tipo, &m.Texto, &contenido, &m.FechaMensaje,
+		&m.Estado, &m.Intentos, &m.MaxIntentos, &m.ProximoIntento, &m.Respuesta, &m.UltimoError,
+		&m.FechaCreacion, &m.FechaProceso,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(contenido) > 0 {
+		m.Contenido = &model.ContenidoWhatsApp{}
+		if err := json.Unmarshal(contenido, m.Contenido); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Encolar inserta el mensaje PENDIENTE. Retorna false si el wamid ya estaba
+// encolado para el tenant.
+func (r *ColaWhatsAppRepo) Encolar(ctx context.Context, m *model.MensajeWhatsAppEntrante) (bool, error) {
+	var contenido any // NULL en los mensajes de texto
+	if m.Contenido != nil {
+		data, err := json.Marshal(m.Contenido)
+		if err != nil {
+			return false, fmt.Errorf("cola_whatsapp_repo.Encolar: %w", err)
+		}
+		contenido = string(data)
+	}
+
+	query := `
+		INSERT INTO cola_whatsapp_entrante (
+			tenant_id, phone_number_id, mensaje_id, telefono, tipo, texto, contenido, fecha_mensaje
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, mensaje_id) DO NOTHING
+		RETURNING id, estado, intentos, max_intentos, proximo_intento, fecha_creacion`
+
+	err := r.db.QueryRowContext(ctx, query,
+		m.TenantID, m.PhoneNumberID, m.MensajeID, m.Telefono, m.Tipo, m.Texto, contenido, m.FechaMensaje,
+	).Scan(&m.ID, &m.Estado, &m.Intentos, &m.MaxIntentos, &m.ProximoIntento, &m.FechaCreacion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cola_whatsapp_repo.Encolar: %w", err)
+	}
+	return true, nil
+}
+
+// Reclamar toma hasta `limite` mensajes listos y los marca PROCESANDO con un
+// lease. De cada (tenant, teléfono) solo se considera el mensaje más antiguo
+// sin terminar: si está en proceso, los siguientes esperan. Cada tenant tiene
+// como máximo `maxPorTenant` mensajes en proceso entre todas las réplicas.
+// También recupera los PROCESANDO con el lease vencido (réplica caída).
+func (r *ColaWhatsAppRepo) Reclamar(ctx context.Context, limite, maxPorTenant int, lease time.Duration) ([]model.MensajeWhatsAppEntrante, error) {
+	query := `
+		WITH cabezas AS (
+			SELECT DISTINCT ON (tenant_id, telefono)
+				tenant_id, id, estado, proximo_intento, bloqueado_hasta, fecha_mensaje, fecha_creacion
+			FROM cola_whatsapp_entrante
+			WHERE estado IN ('PENDIENTE', 'PROCESANDO')
+			ORDER BY tenant_id, telefono, fecha_mensaje, fecha_creacion, id
+		),
+		ocupados AS (
+			SELECT tenant_id, count(*) AS n
+			FROM cabezas
+			WHERE estado = 'PROCESANDO' AND bloqueado_hasta >= now()
+			GROUP BY tenant_id
+		),
+		candidatos AS (
+			SELECT c.tenant_id, c.id, c.fecha_mensaje,
+				COALESCE(o.n, 0) + row_number() OVER (
+					PARTITION BY c.tenant_id ORDER BY c.fecha_mensaje, c.fecha_creacion
+				) AS puesto
+			FROM cabezas c
+			LEFT JOIN ocupados o ON o.tenant_id = c.tenant_id
+			WHERE (c.estado = 'PENDIENTE' AND c.proximo_intento <= now())
+			   OR (c.estado = 'PROCESANDO' AND c.bloqueado_hasta < now())
+		)
+		UPDATE cola_whatsapp_entrante
+		SET estado = 'PROCESANDO',
+			bloqueado_hasta = now() + $3 * INTERVAL '1 second'
+		WHERE (tenant_id, id) IN (
+			SELECT tenant_id, id FROM candidatos
+			WHERE puesto <= $2
+			ORDER BY fecha_mensaje
+			LIMIT $1
+		)
+		RETURNING ` + colaWAColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limite, maxPorTenant, int64(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("cola_whatsapp_repo.Reclamar: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.MensajeWhatsAppEntrante
+	for rows.Next() {
+		m, err := scanColaWA(rows)
+		if err != nil {
+			return nil, fmt.Errorf("cola_whatsapp_repo.Reclamar scan: %w", err)
+		}
+		items = append(items, *m)
+	}
+	return items, rows.Err()
+}
+
+// GuardarRespuesta guarda la respuesta generada (mensaje saliente en JSON)
+// antes de enviarla, para que un reintento solo la reenvíe.
+func (r *ColaWhatsAppRepo) GuardarRespuesta(ctx context.Context, tenantID, id uuid.UUID, respuesta string) error {
+	query := `UPDATE cola_whatsapp_entrante SET respuesta = $3 WHERE tenant_id = $1 AND id = $2`
+	if _, err := r.db.ExecContext(ctx, query, tenantID, id, respuesta); err != nil {
+		return fmt.Errorf("cola_whatsapp_repo.GuardarRespuesta: %w", err)
+	}
+	return nil
+}
+
+// MarcarProcesado registra el mensaje como respondido y libera la conversación.
+func (r *ColaWhatsAppRepo) MarcarProcesado(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `
+		UPDATE cola_whatsapp_entrante
+		SET estado = 'PROCESADO', intentos = intentos + 1,
+			fecha_proceso = now(), bloqueado_hasta = NULL, ultimo_error = NULL
+		WHERE tenant_id = $1 AND id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, tenantID, id); err != nil {
+		return fmt.Errorf("cola_whatsapp_repo.MarcarProcesado: %w", err)
+	}
+	return nil
+}
+
+// MarcarError registra un intento fallido. Si ya no quedan intentos lo deja
+// FALLIDO (y libera la conversación); si no, vuelve a PENDIENTE para `proximo`.
+func (r *ColaWhatsAppRepo) MarcarError(ctx context.Context, tenantID, id uuid.UUID, mensaje string, proximo time.Time) (string, error) {
+	query := `
+		UPDATE cola_whatsapp_entrante
+		SET intentos = intentos + 1,
+			estado = CASE WHEN intentos + 1 >= max_intentos THEN 'FALLIDO' ELSE 'PENDIENTE' END,
+			proximo_intento = $3,
+			bloqueado_hasta = NULL,
+			ultimo_error = $4
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING estado`
+
+	var estado string
+	err := r.db.QueryRowContext(ctx, query, tenantID, id, proximo, mensaje).Scan(&estado)
+	if err != nil {
+		return "", fmt.Errorf("cola_whatsapp_repo.MarcarError: %w", err)
+	}
+	return estado, nil
+}
@@ -0,0 +1,233 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ──────────────────────────────────────────────────────────────────────────────
+// ConversacionWhatsAppRepo — Memoria del bot de WhatsApp en CockroachDB.
+//
+// Una fila por (tenant, teléfono). La vigencia se evalúa con now() de la BD
+// para que todas las réplicas usen el mismo reloj; el TTL de la tabla solo
+// libera espacio.
+// ──────────────────────────────────────────────────────────────────────────────
+
+type ConversacionWhatsAppRepo struct {
+	db *sql.DB
+}
+
+func NewConversacionWhatsAppRepo(db *sql.DB) *ConversacionWhatsAppRepo {
+	return &ConversacionWhatsAppRepo{db: db}
+}
+
+// Agregar añade un mensaje al historial, descartando el anterior si expiró
+// y conservando solo los últimos maxMensajes.
+func (r *ConversacionWhatsAppRepo) Agregar(ctx context.Context, tenantID uuid.UUID, telefono string, msg ai.Message, maxMensajes int, ttl time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("conversacion_whatsapp_repo.Agregar: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		raw     []byte
+		vigente bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT mensajes, ultima_actividad > now() - $3 * INTERVAL '1 second'
+		FROM conversaciones_whatsapp
+		WHERE tenant_id = $1 AND telefono = $2
+		FOR UPDATE`,
+		tenantID, telefono, ttl.Seconds(),
+	).Scan(&raw, &vigente)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("conversacion_whatsapp_repo.Agregar: %w", err)
+	}
+
+	var mensajes []ai.Message
+	if vigente {
+		if err := json.Unmarshal(raw, &mensajes); err != nil {
+			return fmt.Errorf("conversacion_whatsapp_repo.Agregar: %w", err)
+		}
+	}
+	mensajes = append(mensajes, msg)
+	if len(mensajes) > maxMensajes {
+		mensajes = mensajes[len(mensajes)-maxMensajes:]
+	}
+
+	data, err := json.Marshal(mensajes)
+	if err != nil {
+		return fmt.Errorf("conversacion_whatsapp_repo.Agregar: %w", err)
+	}
+
+	// Una conversación nueva no hereda las evidencias de la que expiró
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO conversaciones_whatsapp (tenant_id, telefono, mensajes, ultima_actividad, fecha_expiracion)
+		VALUES ($1, $2, $3, now(), now() + $4 * INTERVAL '1 second')
+		ON CONFLICT (tenant_id, telefono) DO UPDATE SET
+			mensajes         = excluded.mensajes,
+			evidencias       = CASE WHEN $5 THEN conversaciones_whatsapp.evidencias ELSE '[]' END,
+			ultima_actividad = excluded.ultima_actividad,
+			fecha_expiracion = GREATEST(conversaciones_whatsapp.fecha_expiracion, excluded.fecha_expiracion)`,
+		tenantID, telefono, data, ttl.Seconds(), vigente,
+	)
+	if err != nil {
+		return fmt.Errorf("conversacion_whatsapp_repo.Agregar: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("conversacion_whatsapp_repo.Agregar: %w", err)
+	}
+	return nil
+}
+
+// Historial retorna los mensajes de la conversación vigente (nil si no hay o expiró).
+func (r *ConversacionWhatsAppRepo) Historial(ctx context.Context, tenantID uuid.UUID, telefono string, ttl time.Duration) ([]ai.Message, error) {
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT mensajes
+		FROM conversaciones_whatsapp
+		WHERE tenant_id = $1 AND telefono = $2
+		  AND ultima_actividad > now() - $3 * INTERVAL '1 second'`,
+		tenantID, telefono, ttl.Seconds(),
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversacion_whatsapp_repo.Historial: %w", err)
+	}
+
+	var mensajes []ai.Message
+	if err := json.Unmarshal(raw, &mensajes); err != nil {
+		return nil, fmt.Errorf("conversacion_whatsapp_repo.Historial: %w", err)
+	}
+	return mensajes, nil
+}
+
+// MarcarACK registra el envío de un ACK si ya pasó el cooldown desde el anterior.
+// Retorna false si otro ACK se envió dentro del cooldown (por cualquier réplica).
+func (r *ConversacionWhatsAppRepo) MarcarACK(ctx context.Context, tenantID uuid.UUID, telefono string, cooldown time.Duration) (bool, error) {
+	var marcado bool
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO conversaciones_whatsapp (tenant_id, telefono, ultimo_ack, fecha_expiracion)
+		VALUES ($1, $2, now(), now() + $3 * INTERVAL '1 second')
+		ON CONFLICT (tenant_id, telefono) DO UPDATE SET
+			ultimo_ack       = excluded.ultimo_ack,
+			fecha_expiracion = GREATEST(conversaciones_whatsapp.fecha_expiracion, excluded.fecha_expiracion)
+		WHERE conversaciones_whatsapp.ultimo_ack IS NULL
+		   OR conversaciones_whatsapp.ultimo_ack <= now() - $3 * INTERVAL '1 second'
+		RETURNING true`,
+		tenantID, telefono, cooldown.Seconds(),
+	).Scan(&marcado)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("conversacion_whatsapp_repo.MarcarACK: %w", err)
+	}
+	return marcado, nil
+}
+
+// AgregarEvidencia guarda un archivo pendiente de adjuntar al reclamo. Retorna
+// false, sin guardarlo, si la conversación ya tiene maxEvidencias.
+func (r *ConversacionWhatsAppRepo) AgregarEvidencia(ctx context.Context, tenantID uuid.UUID, telefono string, ev model.EvidenciaWhatsApp, maxEvidencias int, ttl time.Duration) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("conversacion_whatsapp_repo.AgregarEvidencia: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		raw     []byte
+		vigente bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT evidencias, ultima_actividad > now() - $3 * INTERVAL '1 second'
+		FROM conversaciones_whatsapp
+		WHERE tenant_id = $1 AND telefono = $2
+		FOR UPDATE`,
+		tenantID, telefono, ttl.Seconds(),
+	).Scan(&raw, &vigente)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("conversacion_whatsapp_repo.AgregarEvidencia: %w", err)
+	}
+
+	var evidencias []model.EvidenciaWhatsApp
+	if vigente {
+		if err := json.Unmarshal(raw, &evidencias); err != nil {
+			return false, fmt.Errorf("conversacion_whatsapp_repo.AgregarEvidencia: %w", err)
+		}
+	}
+	if len(evidencias) >= maxEvidencias {
+		return false, nil
+	}
+	evidencias = append(evidencias, ev)
+
+	data, err := json.Marshal(evidencias)
+	if err != nil {
+		return false, fmt.Errorf("conversacion_whatsapp_repo.AgregarEvidencia: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO conversaciones_whatsapp (tenant_id, telefono, evidencias, ultima_actividad, fecha_expiracion)
+		VALUES ($1, $2, $3, now(), now() + $4 * INTERVAL '1 second')
+		ON CONFLICT (tenant_id, telefono) DO UPDATE SET
+			evidencias       = excluded.evidencias,
+			mensajes         = CASE WHEN $5 THEN conversaciones_whatsapp.mensajes ELSE '[]' END,
+			ultima_actividad = excluded.ultima_actividad,
+			fecha_expiracion = GREATEST(conversaciones_whatsapp.fecha_expiracion, excluded.fecha_expiracion)`,
+		tenantID, telefono, data, ttl.Seconds(), vigente,
+	)
+	if err != nil {
+		return false, fmt.Errorf("conversacion_whatsapp_repo.AgregarEvidencia: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("conversacion_whatsapp_repo.AgregarEvidencia: %w", err)
+	}
+	return true, nil
+}
+
+// Evidencias retorna los archivos pendientes de la conversación vigente.
+func (r *ConversacionWhatsAppRepo) Evidencias(ctx context.Context, tenantID uuid.UUID, telefono string, ttl time.Duration) ([]model.EvidenciaWhatsApp, error) {
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, `
+		SELECT evidencias
+		FROM conversaciones_whatsapp
+		WHERE tenant_id = $1 AND telefono = $2
+		  AND ultima_actividad > now() - $3 * INTERVAL '1 second'`,
+		tenantID, telefono, ttl.Seconds(),
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("conversacion_whatsapp_repo.Evidencias: %w", err)
+	}
+
+	var evidencias []model.EvidenciaWhatsApp
+	if err := json.Unmarshal(raw, &evidencias); err != nil {
+		return nil, fmt.Errorf("conversacion_whatsapp_repo.Evidencias: %w", err)
+	}
+	return evidencias, nil
+}
+
+// LimpiarEvidencias descarta los archivos pendientes (ya adjuntados al reclamo).
+func (r *ConversacionWhatsAppRepo) LimpiarEvidencias(ctx context.Context, tenantID uuid.UUID, telefono string) error {
+	query := `UPDATE conversaciones_whatsapp SET evidencias = '[]' WHERE tenant_id = $1 AND telefono = $2`
+	if _, err := r.db.ExecContext(ctx, query, tenantID, telefono); err != nil {
+		return fmt.Errorf("conversacion_whatsapp_repo.LimpiarEvidencias: %w", err)
+	}
+	return nil
+}
@@ -26,12 +26,14 @@ func (r *DashboardRepo) GetUsoTenant(ctx context.Context, tenantID uuid.UUID) (*
 			plan_id, plan_codigo, plan_nombre,
 			suscripcion_id, suscripcion_estado, suscripcion_ciclo, suscripcion_es_trial,
 			limite_sedes, limite_usuarios, limite_reclamos_mes, limite_chatbots,
-			limite_canales_whatsapp, limite_storage_mb,
+			limite_canales_whatsapp, limite_storage_mb, limite_tokens_ia_mes,
 			permite_chatbot, permite_whatsapp, permite_email,
 			permite_reportes_pdf, permite_exportar_excel, permite_api,
 			permite_marca_blanca, permite_multi_idioma,
 			permite_asistente_ia, permite_atencion_vivo,
-			uso_sedes, uso_usuarios, uso_reclamos_mes, uso_chatbots, uso_canales_whatsapp
+			uso_sedes, uso_usuarios, uso_reclamos_mes, uso_chatbots, uso_canales_whatsapp, uso_tokens_ia_mes,
+			(SELECT CEIL(COALESCE(SUM(a.tamano_bytes), 0) / 1048576.0)::INT
+			 FROM adjuntos a WHERE a.tenant_id = v_uso_tenant.tenant_id) AS uso_storage_mb
 		FROM v_uso_tenant
 		WHERE tenant_id = $1`
 
@@ -41,12 +43,13 @@ func (r *DashboardRepo) GetUsoTenant(ctx context.Context, tenantID uuid.UUID) (*
 		&u.PlanID, &u.PlanCodigo, &u.PlanNombre,
 		&u.SuscripcionID, &u.SuscripcionEstado, &u.SuscripcionCiclo, &u.EsTrial,
 		&u.LimiteSedes, &u.LimiteUsuarios, &u.LimiteReclamosMes, &u.LimiteChatbots,
-		&u.LimiteCanalesWhatsApp, &u.LimiteStorageMB,
+		&u.LimiteCanalesWhatsApp, &u.LimiteStorageMB, &u.LimiteTokensIAMes,
 		&u.PermiteChatbot, &u.PermiteWhatsapp, &u.PermiteEmail,
 		&u.PermiteReportesPDF, &u.PermiteExportarExcel, &u.PermiteAPI,
 		&u.PermiteMarcaBlanca, &u.PermiteMultiIdioma,
 		&u.PermiteAsistenteIA, &u.PermiteAtencionVivo,
-		&u.UsoSedes, &u.UsoUsuarios, &u.UsoReclamosMes, &u.UsoChatbots, &u.UsoCanalesWhatsApp,
+		&u.UsoSedes, &u.UsoUsuarios, &u.UsoReclamosMes, &u.UsoChatbots, &u.UsoCanalesWhatsApp, &u.UsoTokensIAMes,
+		&u.UsoStorageMB,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -96,5 +99,45 @@ func (r *DashboardRepo) GetMetricas(ctx context.Context, tenantID uuid.UUID, sed
 	if err != nil {
 		return nil, fmt.Errorf("dashboard_repo.GetMetricas: %w", err)
 	}
+
+	if m.PorCategoria, err = r.conteoPorDimension(ctx, "categoria", baseWhere, args); err != nil {
+		return nil, err
+	}
+	if m.PorSentimiento, err = r.conteoPorDimension(ctx, "sentimiento", baseWhere, args); err != nil {
+		return nil, err
+	}
+	if m.PorUrgencia, err = r.conteoPorDimension(ctx, "urgencia", baseWhere, args); err != nil {
+		return nil, err
+	}
 	return &m, nil
+}
+
+// SinClasificar valor de dimensión para reclamos sin clasificación IA.
+const SinClasificar = "SIN_CLASIFICAR"
+
+// conteoPorDimension reclamos agrupados por una columna de la clasificación IA.
+// columna es un identificador fijo del código, nunca entrada del usuario.
+func (r *DashboardRepo) conteoPorDimension(ctx context.Context, columna, baseWhere string, args []interface{}) ([]dto.DimensionConteo, error) {
+	query := fmt.Sprintf(`
+		SELECT COALESCE(%s, '%s') AS valor, COUNT(*)
+		FROM reclamos
+		WHERE %s
+		GROUP BY valor
+		ORDER BY 2 DESC, valor`, columna, SinClasificar, baseWhere)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("dashboard_repo.conteoPorDimension %s: %w", columna, err)
+	}
+	defer rows.Close()
+
+	conteos := make([]dto.DimensionConteo, 0)
+	for rows.Next() {
+		var d dto.DimensionConteo
+		if err := rows.Scan(&d.Valor, &d.Total); err != nil {
+			return nil, fmt.Errorf("dashboard_repo.conteoPorDimension scan: %w", err)
+		}
+		conteos = append(conteos, d)
+	}
+	return conteos, rows.Err()
 }
\ No newline at end of file
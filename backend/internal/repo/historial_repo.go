@@ -11,18 +11,24 @@ import (
 )
 
 type HistorialRepo struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewHistorialRepo(db *sql.DB) *HistorialRepo {
 	return &HistorialRepo{db: db}
 }
 
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *HistorialRepo) WithTx(tx *sql.Tx) *HistorialRepo {
+	return &HistorialRepo{db: tx}
+}
+
 func (r *HistorialRepo) GetByReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.Historial, error) {
 	query := `
 		SELECT tenant_id, id, reclamo_id,
 			estado_anterior, estado_nuevo, tipo_accion,
-			comentario, usuario_accion, chatbot_id, ip_address, fecha_accion
+			comentario, usuario_accion, chatbot_id, ip_address, fecha_accion,
+			cadena_secuencia, hash_anterior, hash
 		FROM historial_reclamos
 		WHERE tenant_id = $1 AND reclamo_id = $2
 		ORDER BY fecha_accion DESC`
@@ -40,6 +46,7 @@ func (r *HistorialRepo) GetByReclamo(ctx context.Context, tenantID, reclamoID uu
 			&h.TenantID, &h.ID, &h.ReclamoID,
 			&h.EstadoAnterior, &h.EstadoNuevo, &h.TipoAccion,
 			&h.Comentario, &h.UsuarioAccion, &h.ChatbotID, &h.IPAddress, &h.FechaAccion,
+			&h.CadenaSecuencia, &h.HashAnterior, &h.Hash,
 		); err != nil {
 			return nil, fmt.Errorf("historial_repo.scan: %w", err)
 		}
@@ -48,16 +55,35 @@ func (r *HistorialRepo) GetByReclamo(ctx context.Context, tenantID, reclamoID uu
 	return historial, rows.Err()
 }
 
+// Create inserta la fila como nuevo eslabón de la cadena de integridad del
+// tenant. Debe llamarse dentro de una transacción (ver ultimoEslabon).
 func (r *HistorialRepo) Create(ctx context.Context, h *model.Historial) error {
+	secuencia, anterior, err := ultimoEslabon(ctx, r.db, "historial_reclamos", h.TenantID)
+	if err != nil {
+		return fmt.Errorf("historial_repo.Create: %w", err)
+	}
+
+	h.ID = uuid.New()
+	h.FechaAccion = ahoraCadena()
+	secuencia++
+	h.CadenaSecuencia = model.NullInt64{NullInt64: sql.NullInt64{Int64: secuencia, Valid: true}}
+	h.HashAnterior = model.NullString{NullString: sql.NullString{String: anterior, Valid: true}}
+	h.Hash = model.NullString{NullString: sql.NullString{String: HashHistorial(anterior, secuencia, h), Valid: true}}
+
 	query := `
 		INSERT INTO historial_reclamos (
-			tenant_id, reclamo_id, estado_anterior, estado_nuevo,
-			tipo_accion, comentario, usuario_accion, chatbot_id, ip_address
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
-		RETURNING id, fecha_accion`
-
-	return r.db.QueryRowContext(ctx, query,
-		h.TenantID, h.ReclamoID, h.EstadoAnterior, h.EstadoNuevo,
-		h.TipoAccion, h.Comentario, h.UsuarioAccion, h.ChatbotID, h.IPAddress,
-	).Scan(&h.ID, &h.FechaAccion)
-}
\ No newline at end of file
+			tenant_id, id, reclamo_id, estado_anterior, estado_nuevo,
+			tipo_accion, comentario, usuario_accion, chatbot_id, ip_address, fecha_accion,
+			cadena_secuencia, hash_anterior, hash
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		h.TenantID, h.ID, h.ReclamoID, h.EstadoAnterior, h.EstadoNuevo,
+		h.TipoAccion, h.Comentario, h.UsuarioAccion, h.ChatbotID, h.IPAddress, h.FechaAccion,
+		h.CadenaSecuencia, h.HashAnterior, h.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("historial_repo.Create: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,285 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ─── HASH CANÓNICO ──────────────────────────────────────────────────────────
+// El contenido se serializa como arreglo JSON de strings en orden fijo.
+// Cambiar el orden o la lista de campos invalida todos los hashes existentes.
+
+// HashReclamo hash del eslabón de un reclamo. Solo incluye lo que presentó el
+// consumidor; estado y fechas de gestión cambian legítimamente después.
+func HashReclamo(anterior string, secuencia int64, rec *model.Reclamo) string {
+	monto := ""
+	if rec.MontoReclamado.Valid {
+		monto = strconv.FormatFloat(rec.MontoReclamado.Float64, 'f', 2, 64)
+	}
+	sedeID := ""
+	if rec.SedeID.Valid {
+		sedeID = rec.SedeID.UUID.String()
+	}
+
+	return hashEslabon(anterior, []string{
+		rec.TenantID.String(), rec.ID.String(), strconv.FormatInt(secuencia, 10),
+		rec.CodigoReclamo, rec.TipoSolicitud,
+		rec.NombreCompleto, rec.TipoDocumento, rec.NumeroDocumento, rec.Telefono, rec.Email,
+		rec.Domicilio.String, rec.Departamento.String, rec.Provincia.String, rec.Distrito.String,
+		strconv.FormatBool(rec.MenorDeEdad), rec.NombreApoderado.String,
+		rec.RazonSocialProveedor.String, rec.RUCProveedor.String, rec.DireccionProveedor.String,
+		sedeID, rec.SedeNombre.String, rec.SedeDireccion.String,
+		rec.TipoBien.String, monto, rec.DescripcionBien, rec.NumeroPedido.String,
+		rec.AreaQueja.String, rec.DescripcionSituacion.String,
+		rec.FechaIncidente.Format("2006-01-02"), rec.DetalleReclamo, rec.PedidoConsumidor,
+		rec.FirmaDigital.String, rec.IPAddress.String, rec.UserAgent.String,
+		strconv.FormatBool(rec.AceptaTerminos), strconv.FormatBool(rec.AceptaCopia),
+		formatoHash(rec.FechaRegistro), rec.CanalOrigen,
+	})
+}
+
+// HashHistorial hash del eslabón de una fila de historial (inmutable completa).
+func HashHistorial(anterior string, secuencia int64, h *model.Historial) string {
+	usuario, chatbot := "", ""
+	if h.UsuarioAccion.Valid {
+		usuario = h.UsuarioAccion.UUID.String()
+	}
+	if h.ChatbotID.Valid {
+		chatbot = h.ChatbotID.UUID.String()
+	}
+
+	return hashEslabon(anterior, []string{
+		h.TenantID.String(), h.ID.String(), strconv.FormatInt(secuencia, 10),
+		h.ReclamoID.String(), h.EstadoAnterior.String, h.EstadoNuevo, h.TipoAccion,
+		h.Comentario.String, usuario, chatbot, h.IPAddress.String,
+		formatoHash(h.FechaAccion),
+	})
+}
+
+func hashEslabon(anterior string, campos []string) string {
+	contenido, _ := json.Marshal(campos)
+	sum := sha256.Sum256(append([]byte(anterior), contenido...))
+	return hex.EncodeToString(sum[:])
+}
+
+// formatoHash UTC con microsegundos: la precisión de TIMESTAMPTZ.
+func formatoHash(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
+
+// ahoraCadena timestamp que se guarda y se hashea: se genera en Go (no con
+// DEFAULT now()) para que el valor hasheado sea exactamente el persistido.
+func ahoraCadena() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// ultimoEslabon bloquea y retorna el último eslabón de la cadena del tenant.
+// Debe ejecutarse dentro de la transacción del insert: el FOR UPDATE serializa
+// inserciones concurrentes y el índice único (tenant_id, cadena_secuencia)
+// rechaza la segunda si ambas partían de una cadena vacía.
+func ultimoEslabon(ctx context.Context, db DBTX, tabla string, tenantID uuid.UUID) (int64, string, error) {
+	query := fmt.Sprintf(`
+		SELECT cadena_secuencia, hash FROM %s
+		WHERE tenant_id = $1 AND cadena_secuencia IS NOT NULL
+		ORDER BY cadena_secuencia DESC
+		LIMIT 1
+		FOR UPDATE`, tabla)
+
+	var secuencia int64
+	var hash string
+	err := db.QueryRowContext(ctx, query, tenantID).Scan(&secuencia, &hash)
+	if err == sql.ErrNoRows {
+		return 0, model.HashGenesis, nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("ultimoEslabon %s: %w", tabla, err)
+	}
+	return secuencia, hash, nil
+}
+
+// ─── VERIFICACIÓN ───────────────────────────────────────────────────────────
+
+type IntegridadRepo struct {
+	db *sql.DB
+}
+
+func NewIntegridadRepo(db *sql.DB) *IntegridadRepo {
+	return &IntegridadRepo{db: db}
+}
+
+// ListarTenantsConCadena tenants con al menos un eslabón (para verificar todos).
+func (r *IntegridadRepo) ListarTenantsConCadena(ctx context.Context) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT tenant_id FROM reclamos WHERE cadena_secuencia IS NOT NULL
+		UNION
+		SELECT DISTINCT tenant_id FROM historial_reclamos WHERE cadena_secuencia IS NOT NULL`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("integridad_repo.ListarTenantsConCadena: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("integridad_repo.scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// VerificarReclamos recorre la cadena de reclamos del tenant (incluye eliminados).
+func (r *IntegridadRepo) VerificarReclamos(ctx context.Context, tenantID uuid.UUID) (model.VerificacionCadena, error) {
+	res := model.VerificacionCadena{Cadena: model.CadenaReclamos}
+	if err := r.contarSinCadena(ctx, "reclamos", tenantID, &res); err != nil {
+		return res, err
+	}
+
+	query := `
+		SELECT tenant_id, id, codigo_reclamo, tipo_solicitud,
+			nombre_completo, tipo_documento, numero_documento, telefono, email,
+			domicilio, departamento, provincia, distrito, menor_de_edad, nombre_apoderado,
+			razon_social_proveedor, ruc_proveedor, direccion_proveedor,
+			sede_id, sede_nombre, sede_direccion,
+			tipo_bien, monto_reclamado, descripcion_bien, numero_pedido,
+			area_queja, descripcion_situacion,
+			fecha_incidente, detalle_reclamo, pedido_consumidor,
+			firma_digital, ip_address, user_agent,
+			acepta_terminos, acepta_copia,
+			fecha_registro, canal_origen,
+			cadena_secuencia, hash_anterior, hash
+		FROM reclamos
+		WHERE tenant_id = $1 AND cadena_secuencia IS NOT NULL
+		ORDER BY cadena_secuencia`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return res, fmt.Errorf("integridad_repo.VerificarReclamos: %w", err)
+	}
+	defer rows.Close()
+
+	v := nuevoVerificador(&res)
+	for rows.Next() {
+		var rec model.Reclamo
+		if err := rows.Scan(
+			&rec.TenantID, &rec.ID, &rec.CodigoReclamo, &rec.TipoSolicitud,
+			&rec.NombreCompleto, &rec.TipoDocumento, &rec.NumeroDocumento, &rec.Telefono, &rec.Email,
+			&rec.Domicilio, &rec.Departamento, &rec.Provincia, &rec.Distrito, &rec.MenorDeEdad, &rec.NombreApoderado,
+			&rec.RazonSocialProveedor, &rec.RUCProveedor, &rec.DireccionProveedor,
+			&rec.SedeID, &rec.SedeNombre, &rec.SedeDireccion,
+			&rec.TipoBien, &rec.MontoReclamado, &rec.DescripcionBien, &rec.NumeroPedido,
+			&rec.AreaQueja, &rec.DescripcionSituacion,
+			&rec.FechaIncidente, &rec.DetalleReclamo, &rec.PedidoConsumidor,
+			&rec.FirmaDigital, &rec.IPAddress, &rec.UserAgent,
+			&rec.AceptaTerminos, &rec.AceptaCopia,
+			&rec.FechaRegistro, &rec.CanalOrigen,
+			&rec.CadenaSecuencia, &rec.HashAnterior, &rec.Hash,
+		); err != nil {
+			return res, fmt.Errorf("integridad_repo.scan: %w", err)
+		}
+
+		seq := rec.CadenaSecuencia.Int64
+		if !v.eslabon(seq, rec.ID, rec.HashAnterior.String, rec.Hash.String, HashReclamo(rec.HashAnterior.String, seq, &rec)) {
+			return res, nil
+		}
+	}
+	return res, rows.Err()
+}
+
+// VerificarHistorial recorre la cadena de historial del tenant.
+func (r *IntegridadRepo) VerificarHistorial(ctx context.Context, tenantID uuid.UUID) (model.VerificacionCadena, error) {
+	res := model.VerificacionCadena{Cadena: model.CadenaHistorial}
+	if err := r.contarSinCadena(ctx, "historial_reclamos", tenantID, &res); err != nil {
+		return res, err
+	}
+
+	query := `
+		SELECT tenant_id, id, reclamo_id,
+			estado_anterior, estado_nuevo, tipo_accion,
+			comentario, usuario_accion, chatbot_id, ip_address, fecha_accion,
+			cadena_secuencia, hash_anterior, hash
+		FROM historial_reclamos
+		WHERE tenant_id = $1 AND cadena_secuencia IS NOT NULL
+		ORDER BY cadena_secuencia`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return res, fmt.Errorf("integridad_repo.VerificarHistorial: %w", err)
+	}
+	defer rows.Close()
+
+	v := nuevoVerificador(&res)
+	for rows.Next() {
+		var h model.Historial
+		if err := rows.Scan(
+			&h.TenantID, &h.ID, &h.ReclamoID,
+			&h.EstadoAnterior, &h.EstadoNuevo, &h.TipoAccion,
+			&h.Comentario, &h.UsuarioAccion, &h.ChatbotID, &h.IPAddress, &h.FechaAccion,
+			&h.CadenaSecuencia, &h.HashAnterior, &h.Hash,
+		); err != nil {
+			return res, fmt.Errorf("integridad_repo.scan: %w", err)
+		}
+
+		seq := h.CadenaSecuencia.Int64
+		if !v.eslabon(seq, h.ID, h.HashAnterior.String, h.Hash.String, HashHistorial(h.HashAnterior.String, seq, &h)) {
+			return res, nil
+		}
+	}
+	return res, rows.Err()
+}
+
+func (r *IntegridadRepo) contarSinCadena(ctx context.Context, tabla string, tenantID uuid.UUID, res *model.VerificacionCadena) error {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE tenant_id = $1 AND cadena_secuencia IS NULL`, tabla)
+	if err := r.db.QueryRowContext(ctx, query, tenantID).Scan(&res.SinCadena); err != nil {
+		return fmt.Errorf("integridad_repo.contarSinCadena %s: %w", tabla, err)
+	}
+	return nil
+}
+
+// verificador compara cada eslabón con el anterior y se detiene en el primer quiebre.
+type verificador struct {
+	res      *model.VerificacionCadena
+	esperada int64
+	anterior string
+}
+
+func nuevoVerificador(res *model.VerificacionCadena) *verificador {
+	res.Integra = true
+	return &verificador{res: res, esperada: 1, anterior: model.HashGenesis}
+}
+
+// eslabon retorna false si la cadena se rompe en este eslabón.
+func (v *verificador) eslabon(secuencia int64, id uuid.UUID, hashAnterior, hash, recalculado string) bool {
+	motivo := ""
+	switch {
+	case secuencia != v.esperada:
+		motivo = model.QuiebreSecuencia
+	case hashAnterior != v.anterior:
+		motivo = model.QuiebreEnlace
+	case hash != recalculado:
+		motivo = model.QuiebreContenido
+	}
+	if motivo != "" {
+		v.res.Integra = false
+		v.res.Quiebre = &model.QuiebreCadena{Secuencia: secuencia, ID: id, Motivo: motivo}
+		return false
+	}
+
+	v.res.Eslabones++
+	v.esperada++
+	v.anterior = hash
+	return true
+}
@@ -0,0 +1,129 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+)
+
+// JobRepo locks distribuidos e historial del scheduler.
+type JobRepo struct {
+	db *sql.DB
+}
+
+func NewJobRepo(db *sql.DB) *JobRepo {
+	return &JobRepo{db: db}
+}
+
+// ── Locks ──
+
+// IntentarBloqueo toma el lock del job para el slot dado.
+// Retorna false si otra réplica lo tiene o ya ejecutó ese slot.
+func (r *JobRepo) IntentarBloqueo(ctx context.Context, nombre, propietario string, slot time.Time, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO jobs_bloqueos (nombre, propietario, bloqueado_hasta, ultimo_slot)
+		VALUES ($1, $2, now() + $3 * INTERVAL '1 second', $4)
+		ON CONFLICT (nombre) DO UPDATE SET
+			propietario = excluded.propietario,
+			bloqueado_hasta = excluded.bloqueado_hasta,
+			ultimo_slot = excluded.ultimo_slot
+		WHERE jobs_bloqueos.bloqueado_hasta < now()
+		  AND jobs_bloqueos.ultimo_slot < excluded.ultimo_slot
+		RETURNING propietario`
+
+	var dueno string
+	err := r.db.QueryRowContext(ctx, query, nombre, propietario, int64(ttl.Seconds()), slot).Scan(&dueno)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("job_repo.IntentarBloqueo: %w", err)
+	}
+	return dueno == propietario, nil
+}
+
+// LiberarBloqueo suelta el lock si sigue siendo del propietario.
+func (r *JobRepo) LiberarBloqueo(ctx context.Context, nombre, propietario string) error {
+	query := `UPDATE jobs_bloqueos SET bloqueado_hasta = now() WHERE nombre = $1 AND propietario = $2`
+	if _, err := r.db.ExecContext(ctx, query, nombre, propietario); err != nil {
+		return fmt.Errorf("job_repo.LiberarBloqueo: %w", err)
+	}
+	return nil
+}
+
+// ── Historial ──
+
+func (r *JobRepo) CrearEjecucion(ctx context.Context, e *model.JobEjecucion) error {
+	query := `
+		INSERT INTO jobs_ejecuciones (nombre, instancia, origen, estado)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, fecha_inicio`
+
+	err := r.db.QueryRowContext(ctx, query, e.Nombre, e.Instancia, e.Origen, e.Estado).
+		Scan(&e.ID, &e.FechaInicio)
+	if err != nil {
+		return fmt.Errorf("job_repo.CrearEjecucion: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepo) FinalizarEjecucion(ctx context.Context, e *model.JobEjecucion) error {
+	query := `
+		UPDATE jobs_ejecuciones
+		SET estado = $1, resultado = $2, error = $3,
+			fecha_fin = $4, duracion_ms = $5
+		WHERE id = $6`
+
+	_, err := r.db.ExecContext(ctx, query,
+		e.Estado, e.Resultado, e.Error, e.FechaFin, e.DuracionMs, e.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("job_repo.FinalizarEjecucion: %w", err)
+	}
+	return nil
+}
+
+// ListarEjecuciones historial de un job, más reciente primero.
+func (r *JobRepo) ListarEjecuciones(ctx context.Context, nombre string, limit int) ([]model.JobEjecucion, error) {
+	query := `
+		SELECT id, nombre, instancia, origen, estado, resultado, error,
+			duracion_ms, fecha_inicio, fecha_fin
+		FROM jobs_ejecuciones
+		WHERE nombre = $1
+		ORDER BY fecha_inicio DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, nombre, limit)
+	if err != nil {
+		return nil, fmt.Errorf("job_repo.ListarEjecuciones: %w", err)
+	}
+	defer rows.Close()
+
+	var result []model.JobEjecucion
+	for rows.Next() {
+		var e model.JobEjecucion
+		if err := rows.Scan(
+			&e.ID, &e.Nombre, &e.Instancia, &e.Origen, &e.Estado, &e.Resultado, &e.Error,
+			&e.DuracionMs, &e.FechaInicio, &e.FechaFin,
+		); err != nil {
+			return nil, fmt.Errorf("job_repo.ListarEjecuciones scan: %w", err)
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// UltimaEjecucion retorna la corrida más reciente del job. nil si nunca corrió.
+func (r *JobRepo) UltimaEjecucion(ctx context.Context, nombre string) (*model.JobEjecucion, error) {
+	ejecuciones, err := r.ListarEjecuciones(ctx, nombre, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(ejecuciones) == 0 {
+		return nil, nil
+	}
+	return &ejecuciones[0], nil
+}
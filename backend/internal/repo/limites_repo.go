@@ -28,11 +28,13 @@ func (r *LimitesRepo) ObtenerUso(ctx context.Context, tenantID uuid.UUID) (*mode
 			tenant_id, plan_id, plan_codigo, plan_nombre,
 			suscripcion_id, suscripcion_estado, suscripcion_ciclo, suscripcion_es_trial,
 			limite_sedes, limite_usuarios, limite_reclamos_mes,
-			limite_chatbots, limite_canales_whatsapp, limite_storage_mb,
+			limite_chatbots, limite_canales_whatsapp, limite_storage_mb, limite_tokens_ia_mes,
 			permite_chatbot, permite_whatsapp, permite_email,
 			permite_reportes_pdf, permite_exportar_excel, permite_api,
 			permite_marca_blanca, permite_multi_idioma, permite_asistente_ia, permite_atencion_vivo,
-			uso_sedes, uso_usuarios, uso_reclamos_mes, uso_chatbots, uso_canales_whatsapp
+			uso_sedes, uso_usuarios, uso_reclamos_mes, uso_chatbots, uso_canales_whatsapp, uso_tokens_ia_mes,
+			(SELECT CEIL(COALESCE(SUM(a.tamano_bytes), 0) / 1048576.0)::INT
+			 FROM adjuntos a WHERE a.tenant_id = v_uso_tenant.tenant_id) AS uso_storage_mb
 		FROM v_uso_tenant
 		WHERE tenant_id = $1`
 
@@ -41,11 +43,12 @@ func (r *LimitesRepo) ObtenerUso(ctx context.Context, tenantID uuid.UUID) (*mode
 		&u.TenantID, &u.PlanID, &u.PlanCodigo, &u.PlanNombre,
 		&u.SuscripcionID, &u.SuscripcionEstado, &u.SuscripcionCiclo, &u.EsTrial,
 		&u.LimiteSedes, &u.LimiteUsuarios, &u.LimiteReclamosMes,
-		&u.LimiteChatbots, &u.LimiteCanalesWhatsApp, &u.LimiteStorageMB,
+		&u.LimiteChatbots, &u.LimiteCanalesWhatsApp, &u.LimiteStorageMB, &u.LimiteTokensIAMes,
 		&u.PermiteChatbot, &u.PermiteWhatsapp, &u.PermiteEmail,
 		&u.PermiteReportesPDF, &u.PermiteExportarExcel, &u.PermiteAPI,
 	&u.PermiteMarcaBlanca, &u.PermiteMultiIdioma, &u.PermiteAsistenteIA, &u.PermiteAtencionVivo,
-		&u.UsoSedes, &u.UsoUsuarios, &u.UsoReclamosMes, &u.UsoChatbots, &u.UsoCanalesWhatsApp,
+		&u.UsoSedes, &u.UsoUsuarios, &u.UsoReclamosMes, &u.UsoChatbots, &u.UsoCanalesWhatsApp, &u.UsoTokensIAMes,
+		&u.UsoStorageMB,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
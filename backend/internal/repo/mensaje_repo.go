@@ -12,13 +12,18 @@ import (
 )
 
 type MensajeRepo struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewMensajeRepo(db *sql.DB) *MensajeRepo {
 	return &MensajeRepo{db: db}
 }
 
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *MensajeRepo) WithTx(tx *sql.Tx) *MensajeRepo {
+	return &MensajeRepo{db: tx}
+}
+
 func (r *MensajeRepo) GetByReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.Mensaje, error) {
 	query := `
 		SELECT tenant_id, id, reclamo_id,
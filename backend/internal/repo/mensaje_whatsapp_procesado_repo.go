@@ -0,0 +1,48 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// MensajeWhatsAppProcesadoRepo registro de idempotencia de los mensajes
+// entrantes de WhatsApp, por (tenant, wamid). El TTL de la tabla lo limpia
+// cuando Meta ya no reintenta.
+type MensajeWhatsAppProcesadoRepo struct {
+	db *sql.DB
+}
+
+func NewMensajeWhatsAppProcesadoRepo(db *sql.DB) *MensajeWhatsAppProcesadoRepo {
+	return &MensajeWhatsAppProcesadoRepo{db: db}
+}
+
+// Registrar marca el mensaje como recibido. Devuelve false si ya estaba
+// registrado (reintento de Meta o entrega duplicada).
+func (r *MensajeWhatsAppProcesadoRepo) Registrar(ctx context.Context, tenantID uuid.UUID, mensajeID, phoneNumberID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO mensajes_whatsapp_procesados (tenant_id, mensaje_id, phone_number_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, mensaje_id) DO NOTHING`,
+		tenantID, mensajeID, phoneNumberID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("mensaje_whatsapp_procesado_repo.Registrar: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("mensaje_whatsapp_procesado_repo.Registrar: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Liberar borra el registro de un mensaje que no se llegó a encolar.
+func (r *MensajeWhatsAppProcesadoRepo) Liberar(ctx context.Context, tenantID uuid.UUID, mensajeID string) error {
+	query := `DELETE FROM mensajes_whatsapp_procesados WHERE tenant_id = $1 AND mensaje_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, tenantID, mensajeID); err != nil {
+		return fmt.Errorf("mensaje_whatsapp_procesado_repo.Liberar: %w", err)
+	}
+	return nil
+}
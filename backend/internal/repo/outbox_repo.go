@@ -0,0 +1,221 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// OutboxRepo notificaciones del outbox transaccional.
+type OutboxRepo struct {
+	db DBTX
+}
+
+func NewOutboxRepo(db *sql.DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *OutboxRepo) WithTx(tx *sql.Tx) *OutboxRepo {
+	return &OutboxRepo{db: tx}
+}
+
+const outboxColumns = `
+	tenant_id, id, reclamo_id, canal, tipo, destinatario, payload,
+	estado, intentos, max_intentos, proximo_intento, ultimo_error,
+	fecha_creacion, fecha_envio,
+	mensaje_externo_id, estado_entrega, fecha_entrega, fecha_lectura, error_entrega`
+
+func scanOutbox(row interface{ Scan(...any) error }) (*model.NotificacionOutbox, error) {
+	n := &model.NotificacionOutbox{}
+	var payload []byte
+	err := row.Scan(
+		&n.TenantID, &n.ID, &n.ReclamoID, &n.Canal, &n.Tipo, &n.Destinatario, &payload,
+		&n.Estado, &n.Intentos, &n.MaxIntentos, &n.ProximoIntento, &n.UltimoError,
+		&n.FechaCreacion, &n.FechaEnvio,
+		&n.MensajeExternoID, &n.EstadoEntrega, &n.FechaEntrega, &n.FechaLectura, &n.ErrorEntrega,
+	)
+	if err != nil {
+		return nil, err
+	}
+	n.Payload = payload
+	return n, nil
+}
+
+// Encolar inserta una notificación PENDIENTE.
+// Usar con WithTx para que quede en la misma transacción que el cambio de negocio.
+func (r *OutboxRepo) Encolar(ctx context.Context, n *model.NotificacionOutbox) error {
+	query := `
+		INSERT INTO notificaciones_outbox (
+			tenant_id, reclamo_id, canal, tipo, destinatario, payload
+		) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, estado, intentos, max_intentos, proximo_intento, fecha_creacion`
+
+	payload := []byte(n.Payload)
+	if len(payload) == 0 {
+		payload = []byte("{}")
+	}
+
+	err := r.db.QueryRowContext(ctx, query,
+		n.TenantID, n.ReclamoID, n.Canal, n.Tipo, n.Destinatario, string(payload),
+	).Scan(&n.ID, &n.Estado, &n.Intentos, &n.MaxIntentos, &n.ProximoIntento, &n.FechaCreacion)
+	if err != nil {
+		return fmt.Errorf("outbox_repo.Encolar: %w", err)
+	}
+	return nil
+}
+
+// Reclamar toma hasta `limite` notificaciones listas para enviar (de cualquier tenant)
+// y las marca PROCESANDO con un lease. También recupera las que quedaron
+// PROCESANDO con el lease vencido (réplica caída a mitad del envío).
+func (r *OutboxRepo) Reclamar(ctx context.Context, limite int, lease time.Duration) ([]model.NotificacionOutbox, error) {
+	query := `
+		UPDATE notificaciones_outbox
+		SET estado = 'PROCESANDO',
+			bloqueado_hasta = now() + $2 * INTERVAL '1 second'
+		WHERE (estado = 'PENDIENTE' AND proximo_intento <= now())
+		   OR (estado = 'PROCESANDO' AND bloqueado_hasta < now())
+		ORDER BY proximo_intento
+		LIMIT $1
+		RETURNING ` + outboxColumns
+
+	rows, err := r.db.QueryContext(ctx, query, limite, int64(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("outbox_repo.Reclamar: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.NotificacionOutbox
+	for rows.Next() {
+		n, err := scanOutbox(rows)
+		if err != nil {
+			return nil, fmt.Errorf("outbox_repo.Reclamar scan: %w", err)
+		}
+		items = append(items, *n)
+	}
+	return items, rows.Err()
+}
+
+// MarcarEnviada registra el envío exitoso.
+func (r *OutboxRepo) MarcarEnviada(ctx context.Context, tenantID, id uuid.UUID) error {
+	query := `
+		UPDATE notificaciones_outbox
+		SET estado = 'ENVIADA', intentos = intentos + 1,
+			fecha_envio = now(), bloqueado_hasta = NULL, ultimo_error = NULL
+		WHERE tenant_id = $1 AND id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, tenantID, id); err != nil {
+		return fmt.Errorf("outbox_repo.MarcarEnviada: %w", err)
+	}
+	return nil
+}
+
+// RegistrarMensajeExterno guarda el ID del mensaje en el proveedor (wamid de
+// WhatsApp) para seguir su entrega con el webhook de estados.
+func (r *OutboxRepo) RegistrarMensajeExterno(ctx context.Context, tenantID, id uuid.UUID, mensajeID string) error {
+	query := `
+		UPDATE notificaciones_outbox
+		SET mensaje_externo_id = $3, estado_entrega = 'ENVIADO',
+			fecha_entrega = NULL, fecha_lectura = NULL, error_entrega = NULL
+		WHERE tenant_id = $1 AND id = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, tenantID, id, mensajeID); err != nil {
+		return fmt.Errorf("outbox_repo.RegistrarMensajeExterno: %w", err)
+	}
+	return nil
+}
+
+// ActualizarEntrega aplica un estado de entrega informado por el proveedor.
+// Los estados llegan desordenados, así que nunca retrocede: FALLIDO gana
+// siempre y LEIDO no vuelve a ENTREGADO. Retorna false si el mensaje no
+// corresponde a ninguna notificación (p. ej. respuestas del bot).
+func (r *OutboxRepo) ActualizarEntrega(ctx context.Context, tenantID uuid.UUID, mensajeID, estado string, fecha time.Time, detalle string) (bool, error) {
+	query := `
+		UPDATE notificaciones_outbox
+		SET estado_entrega = CASE
+				WHEN $3 = 'FALLIDO' OR estado_entrega = 'FALLIDO' THEN 'FALLIDO'
+				WHEN $3 = 'LEIDO' OR estado_entrega = 'LEIDO' THEN 'LEIDO'
+				WHEN $3 = 'ENTREGADO' OR estado_entrega = 'ENTREGADO' THEN 'ENTREGADO'
+				ELSE $3
+			END,
+			fecha_entrega = CASE WHEN $3 IN ('ENTREGADO', 'LEIDO') THEN COALESCE(fecha_entrega, $4) ELSE fecha_entrega END,
+			fecha_lectura = CASE WHEN $3 = 'LEIDO' THEN COALESCE(fecha_lectura, $4) ELSE fecha_lectura END,
+			error_entrega = CASE WHEN $3 = 'FALLIDO' THEN $5 ELSE error_entrega END
+		WHERE tenant_id = $1 AND mensaje_externo_id = $2`
+
+	res, err := r.db.ExecContext(ctx, query, tenantID, mensajeID, estado, fecha, sql.NullString{String: detalle, Valid: detalle != ""})
+	if err != nil {
+		return false, fmt.Errorf("outbox_repo.ActualizarEntrega: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// MarcarError registra un intento fallido. Si ya no quedan intentos la deja
+// FALLIDA (dead-letter); si no, vuelve a PENDIENTE para `proximo`.
+func (r *OutboxRepo) MarcarError(ctx context.Context, tenantID, id uuid.UUID, mensaje string, proximo time.Time) (string, error) {
+	query := `
+		UPDATE notificaciones_outbox
+		SET intentos = intentos + 1,
+			estado = CASE WHEN intentos + 1 >= max_intentos THEN 'FALLIDA' ELSE 'PENDIENTE' END,
+			proximo_intento = $3,
+			bloqueado_hasta = NULL,
+			ultimo_error = $4
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING estado`
+
+	var estado string
+	err := r.db.QueryRowContext(ctx, query, tenantID, id, proximo, mensaje).Scan(&estado)
+	if err != nil {
+		return "", fmt.Errorf("outbox_repo.MarcarError: %w", err)
+	}
+	return estado, nil
+}
+
+// ListarPorReclamo estado de entrega de las notificaciones de un reclamo.
+func (r *OutboxRepo) ListarPorReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.NotificacionOutbox, error) {
+	query := `SELECT ` + outboxColumns + `
+		FROM notificaciones_outbox
+		WHERE tenant_id = $1 AND reclamo_id = $2
+		ORDER BY fecha_creacion DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("outbox_repo.ListarPorReclamo: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.NotificacionOutbox
+	for rows.Next() {
+		n, err := scanOutbox(rows)
+		if err != nil {
+			return nil, fmt.Errorf("outbox_repo.ListarPorReclamo scan: %w", err)
+		}
+		items = append(items, *n)
+	}
+	return items, rows.Err()
+}
+
+// Reintentar saca una notificación del dead-letter y la vuelve a encolar
+// con intentos en cero. También reenvía las que el proveedor no pudo
+// entregar (estado_entrega FALLIDO). Retorna false si no existe o no falló.
+func (r *OutboxRepo) Reintentar(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE notificaciones_outbox
+		SET estado = 'PENDIENTE', intentos = 0, proximo_intento = now(),
+			bloqueado_hasta = NULL
+		WHERE tenant_id = $1 AND id = $2
+		  AND (estado = 'FALLIDA' OR estado_entrega = 'FALLIDO')`
+
+	res, err := r.db.ExecContext(ctx, query, tenantID, id)
+	if err != nil {
+		return false, fmt.Errorf("outbox_repo.Reintentar: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
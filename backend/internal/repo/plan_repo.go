@@ -28,7 +28,7 @@ const columnasSelectPlan = `
 	permite_reportes_pdf, permite_exportar_excel, permite_api,
 	permite_marca_blanca, permite_multi_idioma,
 	permite_asistente_ia, permite_atencion_vivo,
-	max_storage_mb, orden, activo, destacado, fecha_creacion`
+	max_storage_mb, orden, activo, destacado, fecha_creacion, max_tokens_ia_mes`
 
 func scanPlan(row interface{ Scan(...interface{}) error }) (*model.Plan, error) {
 	p := &model.Plan{}
@@ -40,7 +40,7 @@ func scanPlan(row interface{ Scan(...interface{}) error }) (*model.Plan, error)
 		&p.PermiteReportesPDF, &p.PermiteExportarExcel, &p.PermiteAPI,
 		&p.PermiteMarcaBlanca, &p.PermiteMultiIdioma,
 		&p.PermiteAsistenteIA, &p.PermiteAtencionVivo,
-		&p.MaxStorageMB, &p.Orden, &p.Activo, &p.Destacado, &p.FechaCreacion,
+		&p.MaxStorageMB, &p.Orden, &p.Activo, &p.Destacado, &p.FechaCreacion, &p.MaxTokensIAMes,
 	)
 	return p, err
 }
@@ -114,9 +114,9 @@ func (r *PlanRepo) Create(ctx context.Context, p *model.Plan) error {
 			permite_reportes_pdf, permite_exportar_excel, permite_api,
 			permite_marca_blanca, permite_multi_idioma,
 			permite_asistente_ia, permite_atencion_vivo,
-			max_storage_mb, orden, activo, destacado
+			max_storage_mb, orden, activo, destacado, max_tokens_ia_mes
 		) VALUES (
-			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26
+			$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27
 		) RETURNING id, fecha_creacion`
 
 	return r.db.QueryRowContext(ctx, query,
@@ -127,7 +127,7 @@ func (r *PlanRepo) Create(ctx context.Context, p *model.Plan) error {
 		p.PermiteReportesPDF, p.PermiteExportarExcel, p.PermiteAPI,
 		p.PermiteMarcaBlanca, p.PermiteMultiIdioma,
 		p.PermiteAsistenteIA, p.PermiteAtencionVivo,
-		p.MaxStorageMB, p.Orden, p.Activo, p.Destacado,
+		p.MaxStorageMB, p.Orden, p.Activo, p.Destacado, p.MaxTokensIAMes,
 	).Scan(&p.ID, &p.FechaCreacion)
 }
 
@@ -142,8 +142,9 @@ func (r *PlanRepo) Update(ctx context.Context, p *model.Plan) error {
 			permite_reportes_pdf = $15, permite_exportar_excel = $16, permite_api = $17,
 			permite_marca_blanca = $18, permite_multi_idioma = $19,
 			permite_asistente_ia = $20, permite_atencion_vivo = $21,
-			max_storage_mb = $22, orden = $23, activo = $24, destacado = $25
-		WHERE id = $26`
+			max_storage_mb = $22, orden = $23, activo = $24, destacado = $25,
+			max_tokens_ia_mes = $26
+		WHERE id = $27`
 
 	_, err := r.db.ExecContext(ctx, query,
 		p.Nombre, p.Descripcion,
@@ -154,6 +155,7 @@ func (r *PlanRepo) Update(ctx context.Context, p *model.Plan) error {
 		p.PermiteMarcaBlanca, p.PermiteMultiIdioma,
 		p.PermiteAsistenteIA, p.PermiteAtencionVivo,
 		p.MaxStorageMB, p.Orden, p.Activo, p.Destacado,
+		p.MaxTokensIAMes,
 		p.ID,
 	)
 	if err != nil {
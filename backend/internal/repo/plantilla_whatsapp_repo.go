@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// PlantillaWhatsAppRepo plantillas de Meta con las que el tenant notifica por WhatsApp.
+type PlantillaWhatsAppRepo struct {
+	db *sql.DB
+}
+
+func NewPlantillaWhatsAppRepo(db *sql.DB) *PlantillaWhatsAppRepo {
+	return &PlantillaWhatsAppRepo{db: db}
+}
+
+const columnasPlantillaWhatsApp = `tenant_id, id, tipo, nombre, idioma, variables, activo, fecha_creacion, fecha_actualizacion`
+
+func scanPlantillaWhatsApp(row interface{ Scan(...interface{}) error }, p *model.PlantillaWhatsApp) error {
+	var variables []byte
+	if err := row.Scan(&p.TenantID, &p.ID, &p.Tipo, &p.Nombre, &p.Idioma, &variables, &p.Activo, &p.FechaCreacion, &p.FechaActualizacion); err != nil {
+		return err
+	}
+	p.Variables = []string{}
+	if len(variables) > 0 {
+		if err := json.Unmarshal(variables, &p.Variables); err != nil {
+			return fmt.Errorf("variables: %w", err)
+		}
+	}
+	return nil
+}
+
+// Listar plantillas del tenant por tipo.
+func (r *PlantillaWhatsAppRepo) Listar(ctx context.Context, tenantID uuid.UUID) ([]model.PlantillaWhatsApp, error) {
+	query := `SELECT ` + columnasPlantillaWhatsApp + `
+		FROM plantillas_whatsapp
+		WHERE tenant_id = $1
+		ORDER BY tipo`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_repo.Listar: %w", err)
+	}
+	defer rows.Close()
+
+	plantillas := make([]model.PlantillaWhatsApp, 0)
+	for rows.Next() {
+		var p model.PlantillaWhatsApp
+		if err := scanPlantillaWhatsApp(rows, &p); err != nil {
+			return nil, fmt.Errorf("plantilla_whatsapp_repo.Listar scan: %w", err)
+		}
+		plantillas = append(plantillas, p)
+	}
+	return plantillas, rows.Err()
+}
+
+// GetByID retorna nil si no existe.
+func (r *PlantillaWhatsAppRepo) GetByID(ctx context.Context, tenantID, id uuid.UUID) (*model.PlantillaWhatsApp, error) {
+	query := `SELECT ` + columnasPlantillaWhatsApp + ` FROM plantillas_whatsapp WHERE tenant_id = $1 AND id = $2`
+
+	var p model.PlantillaWhatsApp
+	err := scanPlantillaWhatsApp(r.db.QueryRowContext(ctx, query, tenantID, id), &p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_repo.GetByID: %w", err)
+	}
+	return &p, nil
+}
+
+// GetActivaPorTipo plantilla activa del tipo de notificación. nil si no hay.
+func (r *PlantillaWhatsAppRepo) GetActivaPorTipo(ctx context.Context, tenantID uuid.UUID, tipo string) (*model.PlantillaWhatsApp, error) {
+	query := `SELECT ` + columnasPlantillaWhatsApp + `
+		FROM plantillas_whatsapp
+		WHERE tenant_id = $1 AND tipo = $2 AND activo = true`
+
+	var p model.PlantillaWhatsApp
+	err := scanPlantillaWhatsApp(r.db.QueryRowContext(ctx, query, tenantID, tipo), &p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_repo.GetActivaPorTipo: %w", err)
+	}
+	return &p, nil
+}
+
+// ExisteTipo indica si otra plantilla del tenant ya cubre el tipo.
+// excluirID permite actualizar la misma plantilla.
+func (r *PlantillaWhatsAppRepo) ExisteTipo(ctx context.Context, tenantID uuid.UUID, tipo string, excluirID uuid.UUID) (bool, error) {
+	var existe bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM plantillas_whatsapp
+			WHERE tenant_id = $1 AND tipo = $2 AND id <> $3
+		)`, tenantID, tipo, excluirID,
+	).Scan(&existe)
+	if err != nil {
+		return false, fmt.Errorf("plantilla_whatsapp_repo.ExisteTipo: %w", err)
+	}
+	return existe, nil
+}
+
+func (r *PlantillaWhatsAppRepo) Create(ctx context.Context, p *model.PlantillaWhatsApp) error {
+	variables, err := json.Marshal(p.Variables)
+	if err != nil {
+		return fmt.Errorf("plantilla_whatsapp_repo.Create: %w", err)
+	}
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO plantillas_whatsapp (tenant_id, tipo, nombre, idioma, variables, activo)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, fecha_creacion, fecha_actualizacion`,
+		p.TenantID, p.Tipo, p.Nombre, p.Idioma, string(variables), p.Activo,
+	).Scan(&p.ID, &p.FechaCreacion, &p.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("plantilla_whatsapp_repo.Create: %w", err)
+	}
+	return nil
+}
+
+func (r *PlantillaWhatsAppRepo) Update(ctx context.Context, p *model.PlantillaWhatsApp) error {
+	variables, err := json.Marshal(p.Variables)
+	if err != nil {
+		return fmt.Errorf("plantilla_whatsapp_repo.Update: %w", err)
+	}
+	err = r.db.QueryRowContext(ctx, `
+		UPDATE plantillas_whatsapp
+		SET tipo = $1, nombre = $2, idioma = $3, variables = $4, activo = $5, fecha_actualizacion = now()
+		WHERE tenant_id = $6 AND id = $7
+		RETURNING fecha_actualizacion`,
+		p.Tipo, p.Nombre, p.Idioma, string(variables), p.Activo, p.TenantID, p.ID,
+	).Scan(&p.FechaActualizacion)
+	if err != nil {
+		return fmt.Errorf("plantilla_whatsapp_repo.Update: %w", err)
+	}
+	return nil
+}
+
+// Delete retorna false si no existía. Las notificaciones ya encoladas con la
+// plantilla fallan al enviarse y quedan en dead-letter.
+func (r *PlantillaWhatsAppRepo) Delete(ctx context.Context, tenantID, id uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM plantillas_whatsapp WHERE tenant_id = $1 AND id = $2`, tenantID, id)
+	if err != nil {
+		return false, fmt.Errorf("plantilla_whatsapp_repo.Delete: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,382 @@
+package repo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// Órdenes soportados por la búsqueda.
+const (
+	OrdenFechaRegistro = "fecha_registro"
+	OrdenFechaLimite   = "fecha_limite"
+	OrdenCodigo        = "codigo"
+)
+
+// ErrCursorInvalido el cursor no se puede leer o es de otro orden.
+var ErrCursorInvalido = errors.New("cursor de búsqueda inválido")
+
+// SinAsignar valor de la faceta atendido_por para reclamos sin responsable.
+const SinAsignar = "SIN_ASIGNAR"
+
+// FiltrosBusqueda criterios de GET /api/v1/reclamos/buscar.
+// Los filtros de faceta aceptan varios valores (OR dentro de la faceta, AND entre facetas).
+type FiltrosBusqueda struct {
+	TenantID       uuid.UUID
+	Texto          string
+	Estados        []string
+	TiposSolicitud []string
+	Canales        []string
+	SedeIDs        []uuid.UUID
+	SedeForzada    *uuid.UUID // alcance del usuario (SOPORTE con sede): se aplica también a las facetas
+	AtendidoPor    []uuid.UUID
+	SinAsignar     bool
+	Prioridades    []string
+	Categorias     []string // clasificación IA
+	Sentimientos   []string
+	Urgencias      []string
+	FechaDesde     *time.Time
+	FechaHasta     *time.Time
+
+	Orden  string // OrdenFechaRegistro (default), OrdenFechaLimite, OrdenCodigo
+	Asc    bool
+	Cursor string
+	Limite int
+}
+
+// ordenBusqueda expresión SQL del orden y cómo se serializa su valor en el cursor.
+type ordenBusqueda struct {
+	expr    string
+	cast    string
+	formato string // layout de time.Parse del valor en el cursor ("" = texto libre)
+	valor   func(rec *model.Reclamo) string
+}
+
+var ordenesBusqueda = map[string]ordenBusqueda{
+	OrdenFechaRegistro: {
+		expr:    "r.fecha_registro",
+		cast:    "TIMESTAMPTZ",
+		formato: time.RFC3339Nano,
+		valor:   func(rec *model.Reclamo) string { return rec.FechaRegistro.Format(time.RFC3339Nano) },
+	},
+	OrdenFechaLimite: {
+		// Sin fecha límite va al final en orden ascendente
+		expr:    "COALESCE(r.fecha_limite_respuesta, DATE '9999-12-31')",
+		cast:    "DATE",
+		formato: "2006-01-02",
+		valor: func(rec *model.Reclamo) string {
+			if !rec.FechaLimiteRespuesta.Valid {
+				return "9999-12-31"
+			}
+			return rec.FechaLimiteRespuesta.Time.Format("2006-01-02")
+		},
+	},
+	OrdenCodigo: {
+		expr:  "r.codigo_reclamo",
+		cast:  "STRING",
+		valor: func(rec *model.Reclamo) string { return rec.CodigoReclamo },
+	},
+}
+
+// valorValido indica si el valor de un cursor se puede convertir al tipo del
+// orden: un cursor alterado no debe llegar a la BD.
+func (o ordenBusqueda) valorValido(valor string) bool {
+	if o.formato == "" {
+		return true
+	}
+	_, err := time.Parse(o.formato, valor)
+	return err == nil
+}
+
+// OrdenBusquedaValido indica si el orden está soportado ("" = fecha_registro).
+func OrdenBusquedaValido(orden string) bool {
+	_, ok := ordenesBusqueda[orden]
+	return orden == "" || ok
+}
+
+// prioridadSQL misma regla que v_detalle_reclamo (RECHAZADO también cuenta como completado).
+const prioridadSQL = `CASE
+		WHEN r.estado IN ('RESUELTO', 'CERRADO', 'RECHAZADO') THEN 'COMPLETADO'
+		WHEN r.fecha_limite_respuesta < CURRENT_DATE THEN 'VENCIDO'
+		WHEN (r.fecha_limite_respuesta - CURRENT_DATE) <= 3 THEN 'URGENTE'
+		ELSE 'EN_TIEMPO'
+	END`
+
+// facetasBusqueda valor (y etiqueta, para IDs) de cada faceta.
+var facetasBusqueda = []struct {
+	nombre   string
+	valor    string
+	etiqueta string
+}{
+	{model.FacetaEstado, "r.estado", "''"},
+	{model.FacetaTipoSolicitud, "r.tipo_solicitud", "''"},
+	{model.FacetaCanalOrigen, "COALESCE(r.canal_origen, '')", "''"},
+	{model.FacetaSede, "COALESCE(r.sede_id::STRING, '')", "COALESCE(MAX(r.sede_nombre), '')"},
+	{model.FacetaAtendidoPor, "COALESCE(r.atendido_por::STRING, '" + SinAsignar + "')", "COALESCE(MAX(ua.nombre_completo), '')"},
+	{model.FacetaPrioridad, prioridadSQL, "''"},
+	{model.FacetaCategoria, "COALESCE(r.categoria, '')", "''"},
+	{model.FacetaSentimiento, "COALESCE(r.sentimiento, '')", "''"},
+	{model.FacetaUrgencia, "COALESCE(r.urgencia, '')", "''"},
+}
+
+// cursorBusqueda posición después del último reclamo devuelto.
+type cursorBusqueda struct {
+	Orden string    `json:"o"`
+	Valor string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Buscar retorna una página de reclamos con texto libre, facetas y paginación por cursor.
+// El texto se busca con el índice de texto completo (relato del consumidor) y con
+// trigramas (nombre, documento, código y número de pedido).
+func (r *ReclamoRepo) Buscar(ctx context.Context, f FiltrosBusqueda) (*model.BusquedaReclamos, error) {
+	if f.Orden == "" {
+		f.Orden = OrdenFechaRegistro
+	}
+	orden, ok := ordenesBusqueda[f.Orden]
+	if !ok {
+		return nil, fmt.Errorf("reclamo_repo.Buscar: orden no soportado %q", f.Orden)
+	}
+
+	b := &filtroSQL{}
+	condicionesBusqueda(b, f, "")
+
+	if f.Cursor != "" {
+		cur, err := decodificarCursor(f.Cursor)
+		if err != nil || cur.Orden != f.Orden || !orden.valorValido(cur.Valor) {
+			return nil, ErrCursorInvalido
+		}
+		op := "<"
+		if f.Asc {
+			op = ">"
+		}
+		b.add(fmt.Sprintf("(%s, r.id) %s (%s::%s, %s)", orden.expr, op, b.arg(cur.Valor), orden.cast, b.arg(cur.ID)))
+	}
+
+	dir := "DESC"
+	if f.Asc {
+		dir = "ASC"
+	}
+	query := fmt.Sprintf(`
+		SELECT r.tenant_id, r.id, r.codigo_reclamo, r.tipo_solicitud, r.estado,
+			r.nombre_completo, r.tipo_documento, r.numero_documento, r.telefono, r.email,
+			r.domicilio, r.departamento, r.provincia, r.distrito, r.menor_de_edad, r.nombre_apoderado,
+			r.razon_social_proveedor, r.ruc_proveedor, r.direccion_proveedor,
+			r.sede_id, r.sede_nombre, r.sede_direccion,
+			r.tipo_bien, r.monto_reclamado, r.descripcion_bien, r.numero_pedido,
+			r.area_queja, r.descripcion_situacion,
+			r.fecha_incidente, r.detalle_reclamo, r.pedido_consumidor,
+			r.firma_digital, r.ip_address, r.user_agent,
+			r.acepta_terminos, r.acepta_copia,
+			r.fecha_registro, r.fecha_limite_respuesta, r.fecha_respuesta, r.fecha_cierre,
+			r.atendido_por, r.canal_origen, r.deleted_at,
+			r.categoria, r.sentimiento, r.urgencia, r.resumen_ia, r.clasificacion_estado, r.fecha_clasificacion,
+			COALESCE(ua.nombre_completo, '')
+		FROM reclamos r
+		LEFT JOIN usuarios_admin ua ON r.tenant_id = ua.tenant_id AND r.atendido_por = ua.id
+		WHERE %s
+		ORDER BY %s %s, r.id %s
+		LIMIT %s`, b.where(), orden.expr, dir, dir, b.arg(f.Limite+1))
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_repo.Buscar: %w", err)
+	}
+	defer rows.Close()
+
+	reclamos, err := r.scanReclamos(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	resultado := &model.BusquedaReclamos{Data: reclamos}
+	if len(reclamos) > f.Limite {
+		resultado.Data = reclamos[:f.Limite]
+		ultimo := &resultado.Data[f.Limite-1]
+		resultado.SiguienteCursor = codificarCursor(cursorBusqueda{
+			Orden: f.Orden,
+			Valor: orden.valor(ultimo),
+			ID:    ultimo.ID,
+		})
+	}
+	if resultado.Data == nil {
+		resultado.Data = []model.Reclamo{}
+	}
+
+	resultado.Facetas, err = r.facetasBusqueda(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	return resultado, nil
+}
+
+// facetasBusqueda cuenta los valores de cada faceta. Cada faceta se cuenta con
+// todos los filtros salvo el suyo, para que el panel muestre las alternativas.
+func (r *ReclamoRepo) facetasBusqueda(ctx context.Context, f FiltrosBusqueda) (map[string][]model.FacetaValor, error) {
+	facetas := make(map[string][]model.FacetaValor, len(facetasBusqueda))
+	for _, fc := range facetasBusqueda {
+		b := &filtroSQL{}
+		condicionesBusqueda(b, f, fc.nombre)
+
+		query := fmt.Sprintf(`
+			SELECT %s, %s, COUNT(*)
+			FROM reclamos r
+			LEFT JOIN usuarios_admin ua ON r.tenant_id = ua.tenant_id AND r.atendido_por = ua.id
+			WHERE %s
+			GROUP BY 1
+			ORDER BY 3 DESC, 1`, fc.valor, fc.etiqueta, b.where())
+
+		rows, err := r.db.QueryContext(ctx, query, b.args...)
+		if err != nil {
+			return nil, fmt.Errorf("reclamo_repo.facetasBusqueda %s: %w", fc.nombre, err)
+		}
+
+		valores := []model.FacetaValor{}
+		for rows.Next() {
+			var v model.FacetaValor
+			if err := rows.Scan(&v.Valor, &v.Nombre, &v.Total); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("reclamo_repo.facetasBusqueda scan: %w", err)
+			}
+			if v.Valor != "" {
+				valores = append(valores, v)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reclamo_repo.facetasBusqueda %s: %w", fc.nombre, err)
+		}
+		facetas[fc.nombre] = valores
+	}
+	return facetas, nil
+}
+
+// condicionesBusqueda agrega los filtros al WHERE, omitiendo la faceta excluir.
+func condicionesBusqueda(b *filtroSQL, f FiltrosBusqueda, excluir string) {
+	b.add("r.tenant_id = " + b.arg(f.TenantID))
+	b.add("r.deleted_at IS NULL")
+	if f.SedeForzada != nil {
+		b.add("r.sede_id = " + b.arg(*f.SedeForzada))
+	}
+
+	if texto := strings.TrimSpace(f.Texto); texto != "" {
+		patron := "%" + escaparLike(texto) + "%"
+		p := b.arg(patron)
+		b.add(fmt.Sprintf(`(r.busqueda_tsv @@ plainto_tsquery('spanish', %s)
+			OR r.nombre_completo ILIKE %s OR r.numero_documento ILIKE %s
+			OR r.codigo_reclamo ILIKE %s OR r.numero_pedido ILIKE %s)`,
+			b.arg(texto), p, p, p, p))
+	}
+	if f.FechaDesde != nil {
+		b.add("r.fecha_registro >= " + b.arg(*f.FechaDesde))
+	}
+	if f.FechaHasta != nil {
+		b.add("r.fecha_registro <= " + b.arg(*f.FechaHasta))
+	}
+
+	if excluir != model.FacetaEstado {
+		b.in("r.estado", f.Estados)
+	}
+	if excluir != model.FacetaTipoSolicitud {
+		b.in("r.tipo_solicitud", f.TiposSolicitud)
+	}
+	if excluir != model.FacetaCanalOrigen {
+		b.in("r.canal_origen", f.Canales)
+	}
+	if excluir != model.FacetaPrioridad {
+		b.in(prioridadSQL, f.Prioridades)
+	}
+	if excluir != model.FacetaSede {
+		b.in("r.sede_id", f.SedeIDs)
+	}
+	if excluir != model.FacetaCategoria {
+		b.in("r.categoria", f.Categorias)
+	}
+	if excluir != model.FacetaSentimiento {
+		b.in("r.sentimiento", f.Sentimientos)
+	}
+	if excluir != model.FacetaUrgencia {
+		b.in("r.urgencia", f.Urgencias)
+	}
+	if excluir != model.FacetaAtendidoPor && (len(f.AtendidoPor) > 0 || f.SinAsignar) {
+		var partes []string
+		if len(f.AtendidoPor) > 0 {
+			partes = append(partes, "r.atendido_por IN ("+b.lista(f.AtendidoPor)+")")
+		}
+		if f.SinAsignar {
+			partes = append(partes, "r.atendido_por IS NULL")
+		}
+		b.add("(" + strings.Join(partes, " OR ") + ")")
+	}
+}
+
+// filtroSQL arma un WHERE con placeholders numerados.
+type filtroSQL struct {
+	conds []string
+	args  []interface{}
+}
+
+func (b *filtroSQL) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *filtroSQL) add(cond string) {
+	b.conds = append(b.conds, cond)
+}
+
+func (b *filtroSQL) where() string {
+	return strings.Join(b.conds, " AND ")
+}
+
+// lista placeholders separados por coma para IN (...).
+func (b *filtroSQL) lista(valores interface{}) string {
+	var ph []string
+	switch vs := valores.(type) {
+	case []string:
+		for _, v := range vs {
+			ph = append(ph, b.arg(v))
+		}
+	case []uuid.UUID:
+		for _, v := range vs {
+			ph = append(ph, b.arg(v))
+		}
+	}
+	return strings.Join(ph, ", ")
+}
+
+// in agrega "expr IN (...)" si hay valores.
+func (b *filtroSQL) in(expr string, valores interface{}) {
+	if ph := b.lista(valores); ph != "" {
+		b.add(expr + " IN (" + ph + ")")
+	}
+}
+
+// escaparLike escapa los comodines de LIKE en el texto del usuario.
+func escaparLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func codificarCursor(c cursorBusqueda) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodificarCursor(s string) (cursorBusqueda, error) {
+	var c cursorBusqueda
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
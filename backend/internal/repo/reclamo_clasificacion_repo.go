@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ReclamarParaClasificar toma hasta limite reclamos con clasificación
+// PENDIENTE cuyo próximo intento ya llegó (cross-tenant). Mueve
+// clasificacion_proximo al final del lease: si la réplica muere, otra los
+// retoma cuando vence. Cada toma cuenta como un intento.
+func (r *ReclamoRepo) ReclamarParaClasificar(ctx context.Context, limite int, lease time.Duration) ([]model.ReclamoPorClasificar, error) {
+	query := `
+		UPDATE reclamos
+		SET clasificacion_proximo = now() + $2 * INTERVAL '1 second',
+			clasificacion_intentos = clasificacion_intentos + 1
+		WHERE clasificacion_estado = 'PENDIENTE'
+		  AND clasificacion_proximo <= now()
+		  AND deleted_at IS NULL
+		ORDER BY clasificacion_proximo
+		LIMIT $1
+		RETURNING tenant_id, id, codigo_reclamo, tipo_solicitud, area_queja,
+			descripcion_bien, descripcion_situacion, detalle_reclamo, pedido_consumidor,
+			monto_reclamado, clasificacion_intentos`
+
+	rows, err := r.db.QueryContext(ctx, query, limite, int64(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_repo.ReclamarParaClasificar: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.ReclamoPorClasificar
+	for rows.Next() {
+		var rc model.ReclamoPorClasificar
+		if err := rows.Scan(
+			&rc.TenantID, &rc.ID, &rc.CodigoReclamo, &rc.TipoSolicitud, &rc.AreaQueja,
+			&rc.DescripcionBien, &rc.DescripcionSituacion, &rc.DetalleReclamo, &rc.PedidoConsumidor,
+			&rc.MontoReclamado, &rc.Intentos,
+		); err != nil {
+			return nil, fmt.Errorf("reclamo_repo.ReclamarParaClasificar scan: %w", err)
+		}
+		items = append(items, rc)
+	}
+	return items, rows.Err()
+}
+
+// GuardarClasificacion registra el resultado de la IA.
+func (r *ReclamoRepo) GuardarClasificacion(ctx context.Context, tenantID, reclamoID uuid.UUID, res model.ResultadoClasificacion) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE reclamos
+		SET categoria = NULLIF($3, ''), sentimiento = $4, urgencia = $5, resumen_ia = $6,
+			clasificacion_estado = 'CLASIFICADO', clasificacion_proximo = NULL,
+			fecha_clasificacion = now()
+		WHERE tenant_id = $1 AND id = $2`,
+		tenantID, reclamoID, res.Categoria, res.Sentimiento, res.Urgencia, res.Resumen,
+	)
+	if err != nil {
+		return fmt.Errorf("reclamo_repo.GuardarClasificacion: %w", err)
+	}
+	return nil
+}
+
+// PosponerClasificacion programa un reintento; con agotado = true la deja FALLIDO.
+func (r *ReclamoRepo) PosponerClasificacion(ctx context.Context, tenantID, reclamoID uuid.UUID, proximo time.Time, agotado bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE reclamos
+		SET clasificacion_estado = CASE WHEN $4::BOOL THEN 'FALLIDO' ELSE 'PENDIENTE' END,
+			clasificacion_proximo = CASE WHEN $4::BOOL THEN NULL ELSE $3::TIMESTAMPTZ END
+		WHERE tenant_id = $1 AND id = $2`,
+		tenantID, reclamoID, proximo, agotado,
+	)
+	if err != nil {
+		return fmt.Errorf("reclamo_repo.PosponerClasificacion: %w", err)
+	}
+	return nil
+}
+
+// EncolarClasificacion vuelve a poner el reclamo en cola (reclasificar desde
+// el panel). Reinicia los intentos. Retorna false si el reclamo no existe.
+func (r *ReclamoRepo) EncolarClasificacion(ctx context.Context, tenantID, reclamoID uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reclamos
+		SET clasificacion_estado = 'PENDIENTE', clasificacion_intentos = 0, clasificacion_proximo = now()
+		WHERE tenant_id = $1 AND id = $2 AND deleted_at IS NULL`,
+		tenantID, reclamoID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("reclamo_repo.EncolarClasificacion: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// DiferirClasificacion reprograma el reclamo sin consumir el intento tomado
+// (tenant sin cuota de IA: no es un fallo de la clasificación).
+func (r *ReclamoRepo) DiferirClasificacion(ctx context.Context, tenantID, reclamoID uuid.UUID, proximo time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE reclamos
+		SET clasificacion_proximo = $3,
+			clasificacion_intentos = GREATEST(clasificacion_intentos - 1, 0)
+		WHERE tenant_id = $1 AND id = $2 AND clasificacion_estado = 'PENDIENTE'`,
+		tenantID, reclamoID, proximo,
+	)
+	if err != nil {
+		return fmt.Errorf("reclamo_repo.DiferirClasificacion: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ReclamoRelacionRepo duplicados probables y vínculos entre reclamos.
+type ReclamoRelacionRepo struct {
+	db DBTX
+}
+
+func NewReclamoRelacionRepo(db *sql.DB) *ReclamoRelacionRepo {
+	return &ReclamoRelacionRepo{db: db}
+}
+
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *ReclamoRelacionRepo) WithTx(tx *sql.Tx) *ReclamoRelacionRepo {
+	return &ReclamoRelacionRepo{db: tx}
+}
+
+// CriteriosCoincidencia datos del consumidor ya normalizados (ver
+// service.criteriosDe). Un campo vacío no se compara.
+type CriteriosCoincidencia struct {
+	TipoDocumento   string
+	NumeroDocumento string
+	Email           string // en minúsculas
+	Telefono        string // últimos 9 dígitos
+	NumeroPedido    string // en minúsculas
+}
+
+// Coincidentes otros reclamos del tenant que comparten documento, email,
+// teléfono o número de pedido con los criterios, del más reciente al más
+// antiguo. desde != nil limita por fecha de registro; sedeID != nil a una sede.
+func (r *ReclamoRelacionRepo) Coincidentes(ctx context.Context, tenantID, excluirID uuid.UUID, c CriteriosCoincidencia, desde *time.Time, sedeID *uuid.UUID, limite int) ([]model.ReclamoConsumidor, error) {
+	query := `
+		SELECT id, codigo_reclamo, tipo_solicitud, estado, canal_origen, sede_nombre,
+			categoria, fecha_registro, documento, email, telefono, pedido, relato
+		FROM (
+			SELECT r.id, r.codigo_reclamo, r.tipo_solicitud, r.estado, r.canal_origen,
+				r.sede_nombre, r.categoria, r.fecha_registro,
+				($4 <> '' AND r.tipo_documento = $3 AND r.numero_documento = $4) AS documento,
+				($5 <> '' AND lower(trim(r.email)) = $5) AS email,
+				($6 <> '' AND right(regexp_replace(r.telefono, '[^0-9]', '', 'g'), 9) = $6) AS telefono,
+				($7 <> '' AND lower(trim(COALESCE(r.numero_pedido, ''))) = $7) AS pedido,
+				LEFT(r.detalle_reclamo || ' ' || r.pedido_consumidor || ' ' ||
+					COALESCE(r.descripcion_situacion, ''), 3000) AS relato
+			FROM reclamos r
+			WHERE r.tenant_id = $1 AND r.id <> $2 AND r.deleted_at IS NULL
+			  AND ($8::TIMESTAMPTZ IS NULL OR r.fecha_registro >= $8)
+			  AND ($9::UUID IS NULL OR r.sede_id = $9)
+		) c
+		WHERE documento OR email OR telefono OR pedido
+		ORDER BY fecha_registro DESC
+		LIMIT $10`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, excluirID,
+		c.TipoDocumento, c.NumeroDocumento, c.Email, c.Telefono, c.NumeroPedido,
+		desde, sedeID, limite,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_relacion_repo.Coincidentes: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.ReclamoConsumidor, 0)
+	for rows.Next() {
+		var rc model.ReclamoConsumidor
+		var documento, email, telefono, pedido bool
+		if err := rows.Scan(
+			&rc.ID, &rc.CodigoReclamo, &rc.TipoSolicitud, &rc.Estado, &rc.CanalOrigen, &rc.SedeNombre,
+			&rc.Categoria, &rc.FechaRegistro, &documento, &email, &telefono, &pedido, &rc.Relato,
+		); err != nil {
+			return nil, fmt.Errorf("reclamo_relacion_repo.Coincidentes scan: %w", err)
+		}
+		rc.Coincidencias = make([]string, 0, 4)
+		for _, m := range []struct {
+			ok     bool
+			motivo string
+		}{
+			{documento, model.CoincideDocumento},
+			{email, model.CoincideEmail},
+			{telefono, model.CoincideTelefono},
+			{pedido, model.CoincidePedido},
+		} {
+			if m.ok {
+				rc.Coincidencias = append(rc.Coincidencias, m.motivo)
+			}
+		}
+		items = append(items, rc)
+	}
+	return items, rows.Err()
+}
+
+// Create registra un duplicado probable. Si el par ya existe no hace nada.
+func (r *ReclamoRelacionRepo) Create(ctx context.Context, rel *model.ReclamoRelacion) error {
+	motivos, err := json.Marshal(rel.Motivos)
+	if err != nil {
+		return fmt.Errorf("reclamo_relacion_repo.Create: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO reclamos_relacionados (tenant_id, reclamo_id, relacionado_id, motivos, puntaje, estado)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, reclamo_id, relacionado_id) DO NOTHING`,
+		rel.TenantID, rel.ReclamoID, rel.RelacionadoID, motivos, rel.Puntaje, rel.Estado,
+	)
+	if err != nil {
+		return fmt.Errorf("reclamo_relacion_repo.Create: %w", err)
+	}
+	return nil
+}
+
+// ListarPorReclamo relaciones del reclamo en ambos sentidos, con el resumen
+// del otro reclamo del par.
+func (r *ReclamoRelacionRepo) ListarPorReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.ReclamoRelacion, error) {
+	query := `
+		SELECT rr.tenant_id, rr.id, rr.reclamo_id, rr.relacionado_id, rr.motivos, rr.puntaje,
+			rr.estado, rr.resuelto_por, rr.fecha_deteccion, rr.fecha_resolucion,
+			o.id, o.codigo_reclamo, o.tipo_solicitud, o.estado, o.canal_origen,
+			o.sede_nombre, o.categoria, o.fecha_registro
+		FROM reclamos_relacionados rr
+		JOIN reclamos o ON o.tenant_id = rr.tenant_id
+			AND o.id = CASE WHEN rr.reclamo_id = $2 THEN rr.relacionado_id ELSE rr.reclamo_id END
+		WHERE rr.tenant_id = $1 AND (rr.reclamo_id = $2 OR rr.relacionado_id = $2)
+		ORDER BY rr.fecha_deteccion DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_relacion_repo.ListarPorReclamo: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]model.ReclamoRelacion, 0)
+	for rows.Next() {
+		var rel model.ReclamoRelacion
+		var motivos []byte
+		otro := &model.ReclamoConsumidor{}
+		if err := rows.Scan(
+			&rel.TenantID, &rel.ID, &rel.ReclamoID, &rel.RelacionadoID, &motivos, &rel.Puntaje,
+			&rel.Estado, &rel.ResueltoPor, &rel.FechaDeteccion, &rel.FechaResolucion,
+			&otro.ID, &otro.CodigoReclamo, &otro.TipoSolicitud, &otro.Estado, &otro.CanalOrigen,
+			&otro.SedeNombre, &otro.Categoria, &otro.FechaRegistro,
+		); err != nil {
+			return nil, fmt.Errorf("reclamo_relacion_repo.ListarPorReclamo scan: %w", err)
+		}
+		if err := json.Unmarshal(motivos, &rel.Motivos); err != nil {
+			return nil, fmt.Errorf("reclamo_relacion_repo.ListarPorReclamo motivos: %w", err)
+		}
+		rel.Otro = otro
+		items = append(items, rel)
+	}
+	return items, rows.Err()
+}
+
+// Resolver fija el estado del par (VINCULADO o FUSIONADO) con reclamoID y
+// relacionadoID en ese orden, exista o no una detección previa en cualquier
+// sentido. Sin detección se registra con motivo MANUAL.
+func (r *ReclamoRelacionRepo) Resolver(ctx context.Context, tenantID, reclamoID, relacionadoID uuid.UUID, estado string, userID uuid.UUID) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reclamos_relacionados
+		SET reclamo_id = $2, relacionado_id = $3, estado = $4,
+			resuelto_por = $5, fecha_resolucion = now()
+		WHERE tenant_id = $1
+		  AND ((reclamo_id = $2 AND relacionado_id = $3) OR (reclamo_id = $3 AND relacionado_id = $2))`,
+		tenantID, reclamoID, relacionadoID, estado, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("reclamo_relacion_repo.Resolver: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO reclamos_relacionados (
+			tenant_id, reclamo_id, relacionado_id, motivos, estado, resuelto_por, fecha_resolucion
+		) VALUES ($1, $2, $3, $4, $5, $6, now())`,
+		tenantID, reclamoID, relacionadoID, `["`+model.CoincideManual+`"]`, estado, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("reclamo_relacion_repo.Resolver insert: %w", err)
+	}
+	return nil
+}
+
+// Descartar marca como DESCARTADO un duplicado PROBABLE del par (en cualquier
+// sentido). Retorna false si no hay uno pendiente de revisión.
+func (r *ReclamoRelacionRepo) Descartar(ctx context.Context, tenantID, reclamoID, relacionadoID, userID uuid.UUID) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE reclamos_relacionados
+		SET estado = 'DESCARTADO', resuelto_por = $4, fecha_resolucion = now()
+		WHERE tenant_id = $1 AND estado = 'PROBABLE'
+		  AND ((reclamo_id = $2 AND relacionado_id = $3) OR (reclamo_id = $3 AND relacionado_id = $2))`,
+		tenantID, reclamoID, relacionadoID, userID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("reclamo_relacion_repo.Descartar: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// FusionadoEn retorna el reclamo en el que se fusionó reclamoID, o nil.
+func (r *ReclamoRelacionRepo) FusionadoEn(ctx context.Context, tenantID, reclamoID uuid.UUID) (*uuid.UUID, error) {
+	var principal uuid.UUID
+	err := r.db.QueryRowContext(ctx, `
+		SELECT relacionado_id FROM reclamos_relacionados
+		WHERE tenant_id = $1 AND reclamo_id = $2 AND estado = 'FUSIONADO'
+		LIMIT 1`,
+		tenantID, reclamoID,
+	).Scan(&principal)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_relacion_repo.FusionadoEn: %w", err)
+	}
+	return &principal, nil
+}
@@ -3,23 +3,36 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"libro-reclamaciones/internal/apperror"
 	"libro-reclamaciones/internal/model"
 	"libro-reclamaciones/internal/model/dto"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrCodigoReclamoDuplicado el código generado ya existe en el tenant
+// (idx_reclamo_codigo). El service genera otro y reintenta.
+var ErrCodigoReclamoDuplicado = errors.New("código de reclamo duplicado")
+
 type ReclamoRepo struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewReclamoRepo(db *sql.DB) *ReclamoRepo {
 	return &ReclamoRepo{db: db}
 }
 
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *ReclamoRepo) WithTx(tx *sql.Tx) *ReclamoRepo {
+	return &ReclamoRepo{db: tx}
+}
+
 func (r *ReclamoRepo) GetByTenant(ctx context.Context, tenantID uuid.UUID, pag dto.PaginationRequest, sedeID *uuid.UUID, fechaDesde, fechaHasta *time.Time) ([]model.Reclamo, int, error) {
 	where := "r.tenant_id = $1 AND r.deleted_at IS NULL"
 	args := []interface{}{tenantID}
@@ -60,6 +73,7 @@ func (r *ReclamoRepo) GetByTenant(ctx context.Context, tenantID uuid.UUID, pag d
 			r.acepta_terminos, r.acepta_copia,
 			r.fecha_registro, r.fecha_limite_respuesta, r.fecha_respuesta, r.fecha_cierre,
 			r.atendido_por, r.canal_origen, r.deleted_at,
+			r.categoria, r.sentimiento, r.urgencia, r.resumen_ia, r.clasificacion_estado, r.fecha_clasificacion,
 			COALESCE(ua.nombre_completo, '')
 		FROM reclamos r
 		LEFT JOIN usuarios_admin ua ON r.tenant_id = ua.tenant_id AND r.atendido_por = ua.id
@@ -93,6 +107,8 @@ func (r *ReclamoRepo) GetByID(ctx context.Context, tenantID, reclamoID uuid.UUID
 			r.acepta_terminos, r.acepta_copia,
 			r.fecha_registro, r.fecha_limite_respuesta, r.fecha_respuesta, r.fecha_cierre,
 			r.atendido_por, r.canal_origen, r.deleted_at,
+			r.categoria, r.sentimiento, r.urgencia, r.resumen_ia, r.clasificacion_estado, r.fecha_clasificacion,
+			r.cadena_secuencia, r.hash_anterior, r.hash,
 			COALESCE(ua.nombre_completo, '')
 		FROM reclamos r
 		LEFT JOIN usuarios_admin ua ON r.tenant_id = ua.tenant_id AND r.atendido_por = ua.id
@@ -112,6 +128,8 @@ func (r *ReclamoRepo) GetByID(ctx context.Context, tenantID, reclamoID uuid.UUID
 		&rec.AceptaTerminos, &rec.AceptaCopia,
 		&rec.FechaRegistro, &rec.FechaLimiteRespuesta, &rec.FechaRespuesta, &rec.FechaCierre,
 		&rec.AtendidoPor, &rec.CanalOrigen, &rec.DeletedAt,
+		&rec.Categoria, &rec.Sentimiento, &rec.Urgencia, &rec.ResumenIA, &rec.ClasificacionEstado, &rec.FechaClasificacion,
+		&rec.CadenaSecuencia, &rec.HashAnterior, &rec.Hash,
 		&rec.NombreAtendidoPor,
 	)
 	if err == sql.ErrNoRows {
@@ -123,10 +141,26 @@ func (r *ReclamoRepo) GetByID(ctx context.Context, tenantID, reclamoID uuid.UUID
 	return rec, nil
 }
 
+// Create inserta el reclamo como nuevo eslabón de la cadena de integridad del
+// tenant. Debe llamarse dentro de una transacción (ver ultimoEslabon).
 func (r *ReclamoRepo) Create(ctx context.Context, rec *model.Reclamo) error {
+	secuencia, anterior, err := ultimoEslabon(ctx, r.db, "reclamos", rec.TenantID)
+	if err != nil {
+		return fmt.Errorf("reclamo_repo.Create: %w", err)
+	}
+
+	if rec.ID == uuid.Nil {
+		rec.ID = uuid.New()
+	}
+	rec.FechaRegistro = ahoraCadena()
+	secuencia++
+	rec.CadenaSecuencia = model.NullInt64{NullInt64: sql.NullInt64{Int64: secuencia, Valid: true}}
+	rec.HashAnterior = model.NullString{NullString: sql.NullString{String: anterior, Valid: true}}
+	rec.Hash = model.NullString{NullString: sql.NullString{String: HashReclamo(anterior, secuencia, rec), Valid: true}}
+
 	query := `
 		INSERT INTO reclamos (
-			tenant_id, codigo_reclamo, tipo_solicitud, estado,
+			tenant_id, id, codigo_reclamo, tipo_solicitud, estado,
 			nombre_completo, tipo_documento, numero_documento, telefono, email,
 			domicilio, departamento, provincia, distrito, menor_de_edad, nombre_apoderado,
 			razon_social_proveedor, ruc_proveedor, direccion_proveedor,
@@ -136,12 +170,14 @@ func (r *ReclamoRepo) Create(ctx context.Context, rec *model.Reclamo) error {
 			fecha_incidente, detalle_reclamo, pedido_consumidor,
 			firma_digital, ip_address, user_agent,
 			acepta_terminos, acepta_copia,
-			fecha_limite_respuesta, canal_origen
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37)
-		RETURNING id, fecha_registro`
-
-	return r.db.QueryRowContext(ctx, query,
-		rec.TenantID, rec.CodigoReclamo, rec.TipoSolicitud, rec.Estado,
+			fecha_registro, fecha_limite_respuesta, canal_origen,
+			cadena_secuencia, hash_anterior, hash,
+			clasificacion_estado, clasificacion_proximo
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$26,$27,$28,$29,$30,$31,$32,$33,$34,$35,$36,$37,$38,$39,$40,$41,$42,
+			$43, CASE WHEN $43::STRING IS NULL THEN NULL ELSE now() END)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		rec.TenantID, rec.ID, rec.CodigoReclamo, rec.TipoSolicitud, rec.Estado,
 		rec.NombreCompleto, rec.TipoDocumento, rec.NumeroDocumento, rec.Telefono, rec.Email,
 		rec.Domicilio, rec.Departamento, rec.Provincia, rec.Distrito, rec.MenorDeEdad, rec.NombreApoderado,
 		rec.RazonSocialProveedor, rec.RUCProveedor, rec.DireccionProveedor,
@@ -151,27 +187,125 @@ func (r *ReclamoRepo) Create(ctx context.Context, rec *model.Reclamo) error {
 		rec.FechaIncidente, rec.DetalleReclamo, rec.PedidoConsumidor,
 		rec.FirmaDigital, rec.IPAddress, rec.UserAgent,
 		rec.AceptaTerminos, rec.AceptaCopia,
-		rec.FechaLimiteRespuesta, rec.CanalOrigen,
-	).Scan(&rec.ID, &rec.FechaRegistro)
+		rec.FechaRegistro, rec.FechaLimiteRespuesta, rec.CanalOrigen,
+		rec.CadenaSecuencia, rec.HashAnterior, rec.Hash,
+		rec.ClasificacionEstado,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" && strings.Contains(pqErr.Message, "idx_reclamo_codigo") {
+		return ErrCodigoReclamoDuplicado
+	}
+	if err != nil {
+		return fmt.Errorf("reclamo_repo.Create: %w", err)
+	}
+	return nil
+}
+
+// ExisteCodigo indica si el tenant ya tiene un reclamo con ese código
+// (incluye eliminados: el índice único también los cubre).
+func (r *ReclamoRepo) ExisteCodigo(ctx context.Context, tenantID uuid.UUID, codigo string) (bool, error) {
+	var existe bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM reclamos WHERE tenant_id = $1 AND codigo_reclamo = $2)`,
+		tenantID, codigo,
+	).Scan(&existe)
+	if err != nil {
+		return false, fmt.Errorf("reclamo_repo.ExisteCodigo: %w", err)
+	}
+	return existe, nil
 }
 
-func (r *ReclamoRepo) UpdateEstado(ctx context.Context, tenantID, reclamoID uuid.UUID, estado string, userID *uuid.UUID) error {
-	query := `UPDATE reclamos SET estado = $1, atendido_por = COALESCE(atendido_por, $4) WHERE tenant_id = $2 AND id = $3 AND deleted_at IS NULL`
-	result, err := r.db.ExecContext(ctx, query, estado, tenantID, reclamoID, userID)
+// UpdateEstado pasa el reclamo de estadoAnterior a estado. Al pasar a
+// RESUELTO / CERRADO / RECHAZADO fija fecha_cierre (la primera vez), que es
+// desde donde corre el plazo de reapertura. Si el reclamo ya no está en
+// estadoAnterior (otro cambio se adelantó desde que se validó la transición)
+// retorna ErrTransicionEstado.
+func (r *ReclamoRepo) UpdateEstado(ctx context.Context, tenantID, reclamoID uuid.UUID, estadoAnterior, estado string, userID *uuid.UUID) error {
+	query := `
+		UPDATE reclamos SET
+			estado = $1,
+			atendido_por = COALESCE(atendido_por, $4),
+			fecha_cierre = CASE WHEN $1 IN ('RESUELTO', 'CERRADO', 'RECHAZADO')
+				THEN COALESCE(fecha_cierre, now()) ELSE fecha_cierre END
+		WHERE tenant_id = $2 AND id = $3 AND deleted_at IS NULL
+		  AND estado = $5`
+	result, err := r.db.ExecContext(ctx, query, estado, tenantID, reclamoID, userID, estadoAnterior)
 	if err != nil {
 		return fmt.Errorf("reclamo_repo.UpdateEstado: %w", err)
 	}
 	rows, _ := result.RowsAffected()
 	if rows == 0 {
-		return fmt.Errorf("not_found")
+		return apperror.ErrTransicionEstado.Withf(estadoAnterior, estado)
+	}
+	return nil
+}
+
+// Reabrir vuelve el reclamo a EN_PROCESO con un nuevo plazo de respuesta.
+func (r *ReclamoRepo) Reabrir(ctx context.Context, tenantID, reclamoID uuid.UUID, fechaLimite time.Time) error {
+	query := `
+		UPDATE reclamos SET
+			estado = 'EN_PROCESO',
+			fecha_cierre = NULL,
+			fecha_limite_respuesta = $1
+		WHERE tenant_id = $2 AND id = $3 AND deleted_at IS NULL
+		  AND estado IN ('RESUELTO', 'CERRADO')`
+	result, err := r.db.ExecContext(ctx, query, fechaLimite.Format("2006-01-02"), tenantID, reclamoID)
+	if err != nil {
+		return fmt.Errorf("reclamo_repo.Reabrir: %w", err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return apperror.ErrNotFound
 	}
 	return nil
 }
 
 func (r *ReclamoRepo) UpdateFechaRespuesta(ctx context.Context, tenantID, reclamoID uuid.UUID) error {
 	query := `UPDATE reclamos SET fecha_respuesta = $1 WHERE tenant_id = $2 AND id = $3`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), tenantID, reclamoID)
-	return err
+	if _, err := r.db.ExecContext(ctx, query, time.Now(), tenantID, reclamoID); err != nil {
+		return fmt.Errorf("reclamo_repo.UpdateFechaRespuesta: %w", err)
+	}
+	return nil
+}
+
+// ReclamoPlazo datos mínimos para recalcular la fecha límite de un reclamo abierto.
+type ReclamoPlazo struct {
+	ID                   uuid.UUID
+	FechaRegistro        time.Time
+	FechaLimiteRespuesta sql.NullTime
+}
+
+// ListarAbiertosPlazo retorna los reclamos PENDIENTE / EN_PROCESO del tenant.
+func (r *ReclamoRepo) ListarAbiertosPlazo(ctx context.Context, tenantID uuid.UUID) ([]ReclamoPlazo, error) {
+	query := `
+		SELECT id, fecha_registro, fecha_limite_respuesta
+		FROM reclamos
+		WHERE tenant_id = $1 AND deleted_at IS NULL
+		  AND estado IN ('PENDIENTE', 'EN_PROCESO')`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_repo.ListarAbiertosPlazo: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ReclamoPlazo
+	for rows.Next() {
+		var rp ReclamoPlazo
+		if err := rows.Scan(&rp.ID, &rp.FechaRegistro, &rp.FechaLimiteRespuesta); err != nil {
+			return nil, fmt.Errorf("reclamo_repo.ListarAbiertosPlazo scan: %w", err)
+		}
+		result = append(result, rp)
+	}
+	return result, rows.Err()
+}
+
+func (r *ReclamoRepo) UpdateFechaLimite(ctx context.Context, tenantID, reclamoID uuid.UUID, fechaLimite time.Time) error {
+	query := `UPDATE reclamos SET fecha_limite_respuesta = $1 WHERE tenant_id = $2 AND id = $3 AND deleted_at IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, fechaLimite.Format("2006-01-02"), tenantID, reclamoID); err != nil {
+		return fmt.Errorf("reclamo_repo.UpdateFechaLimite: %w", err)
+	}
+	return nil
 }
 
 func (r *ReclamoRepo) Asignar(ctx context.Context, tenantID, reclamoID, adminID uuid.UUID) error {
@@ -201,16 +335,16 @@ func (r *ReclamoRepo) SoftDelete(ctx context.Context, tenantID, reclamoID uuid.U
 func (r *ReclamoRepo) GetByCodigoPublico(ctx context.Context, tenantID uuid.UUID, codigo string) (*model.Reclamo, error) {
 	query := `
 		SELECT id, codigo_reclamo, tipo_solicitud, estado,
-			fecha_registro, fecha_limite_respuesta, fecha_respuesta,
-			sede_nombre, descripcion_bien, detalle_reclamo
+			fecha_registro, fecha_limite_respuesta, fecha_respuesta, fecha_cierre,
+			sede_nombre, descripcion_bien, detalle_reclamo, email, telefono
 		FROM reclamos
 		WHERE tenant_id = $1 AND codigo_reclamo = $2 AND deleted_at IS NULL`
 
 	rec := &model.Reclamo{}
 	err := r.db.QueryRowContext(ctx, query, tenantID, codigo).Scan(
 		&rec.ID, &rec.CodigoReclamo, &rec.TipoSolicitud, &rec.Estado,
-		&rec.FechaRegistro, &rec.FechaLimiteRespuesta, &rec.FechaRespuesta,
-		&rec.SedeNombre, &rec.DescripcionBien, &rec.DetalleReclamo,
+		&rec.FechaRegistro, &rec.FechaLimiteRespuesta, &rec.FechaRespuesta, &rec.FechaCierre,
+		&rec.SedeNombre, &rec.DescripcionBien, &rec.DetalleReclamo, &rec.Email, &rec.Telefono,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -242,6 +376,7 @@ func (r *ReclamoRepo) scanReclamos(rows *sql.Rows) ([]model.Reclamo, error) {
 			&rec.AceptaTerminos, &rec.AceptaCopia,
 			&rec.FechaRegistro, &rec.FechaLimiteRespuesta, &rec.FechaRespuesta, &rec.FechaCierre,
 			&rec.AtendidoPor, &rec.CanalOrigen, &rec.DeletedAt,
+			&rec.Categoria, &rec.Sentimiento, &rec.Urgencia, &rec.ResumenIA, &rec.ClasificacionEstado, &rec.FechaClasificacion,
 			&rec.NombreAtendidoPor,
 		); err != nil {
 			return nil, fmt.Errorf("reclamo_repo.scan: %w", err)
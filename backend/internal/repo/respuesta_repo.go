@@ -11,13 +11,18 @@ import (
 )
 
 type RespuestaRepo struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewRespuestaRepo(db *sql.DB) *RespuestaRepo {
 	return &RespuestaRepo{db: db}
 }
 
+// WithTx retorna una copia del repo que opera dentro de la transacción.
+func (r *RespuestaRepo) WithTx(tx *sql.Tx) *RespuestaRepo {
+	return &RespuestaRepo{db: tx}
+}
+
 func (r *RespuestaRepo) GetByReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.Respuesta, error) {
 	query := `
 		SELECT tenant_id, id, reclamo_id,
@@ -57,13 +62,65 @@ func (r *RespuestaRepo) Create(ctx context.Context, resp *model.Respuesta) error
 		INSERT INTO respuestas (
 			tenant_id, reclamo_id, respuesta_empresa, accion_tomada,
 			compensacion_ofrecida, respondido_por, cargo_responsable,
-			origen, chatbot_id
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			origen, chatbot_id, archivos_adjuntos
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 		RETURNING id, fecha_respuesta`
 
+	var adjuntos sql.NullString
+	if len(resp.ArchivosAdjuntos) > 0 {
+		adjuntos = sql.NullString{String: string(resp.ArchivosAdjuntos), Valid: true}
+	}
+
 	return r.db.QueryRowContext(ctx, query,
 		resp.TenantID, resp.ReclamoID, resp.RespuestaEmpresa, resp.AccionTomada,
 		resp.CompensacionOfrecida, resp.RespondidoPor, resp.CargoResponsable,
-		resp.Origen, resp.ChatbotID,
+		resp.Origen, resp.ChatbotID, adjuntos,
 	).Scan(&resp.ID, &resp.FechaRespuesta)
-}
\ No newline at end of file
+}
+// CasosSimilares reclamos ya respondidos del tenant cuyo relato coincide con
+// terminos (tsquery en español, p. ej. "refrigeradora | puerta"), ordenados
+// por relevancia. Se toma la última respuesta de cada reclamo. sedeID != nil
+// limita a esa sede; excluirID deja fuera al reclamo que se está respondiendo.
+func (r *RespuestaRepo) CasosSimilares(ctx context.Context, tenantID, excluirID uuid.UUID, sedeID *uuid.UUID, terminos string, limite int) ([]model.CasoRespondido, error) {
+	query := `
+		WITH q AS (SELECT to_tsquery('spanish', $3) AS consulta)
+		SELECT codigo_reclamo, tipo_solicitud, estado, detalle, pedido,
+			respuesta_empresa, accion_tomada, compensacion_ofrecida
+		FROM (
+			SELECT DISTINCT ON (r.id)
+				r.codigo_reclamo, r.tipo_solicitud, r.estado,
+				LEFT(r.detalle_reclamo, 400) AS detalle,
+				LEFT(r.pedido_consumidor, 200) AS pedido,
+				LEFT(p.respuesta_empresa, 1200) AS respuesta_empresa,
+				p.accion_tomada, p.compensacion_ofrecida,
+				ts_rank(r.busqueda_tsv, q.consulta) AS rango
+			FROM reclamos r
+			CROSS JOIN q
+			JOIN respuestas p ON p.tenant_id = r.tenant_id AND p.reclamo_id = r.id
+			WHERE r.tenant_id = $1 AND r.id <> $2 AND r.deleted_at IS NULL
+			  AND ($4::UUID IS NULL OR r.sede_id = $4)
+			  AND r.busqueda_tsv @@ q.consulta
+			ORDER BY r.id, p.fecha_respuesta DESC
+		) casos
+		ORDER BY rango DESC
+		LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, excluirID, terminos, sedeID, limite)
+	if err != nil {
+		return nil, fmt.Errorf("respuesta_repo.CasosSimilares: %w", err)
+	}
+	defer rows.Close()
+
+	casos := make([]model.CasoRespondido, 0)
+	for rows.Next() {
+		var c model.CasoRespondido
+		if err := rows.Scan(
+			&c.CodigoReclamo, &c.TipoSolicitud, &c.Estado, &c.DetalleReclamo, &c.PedidoConsumidor,
+			&c.RespuestaEmpresa, &c.AccionTomada, &c.CompensacionOfrecida,
+		); err != nil {
+			return nil, fmt.Errorf("respuesta_repo.CasosSimilares scan: %w", err)
+		}
+		casos = append(casos, c)
+	}
+	return casos, rows.Err()
+}
@@ -27,6 +27,7 @@ const columnasSelectSuscripcion = `
 	es_trial, dias_trial, fecha_fin_trial,
 	override_max_sedes, override_max_usuarios, override_max_reclamos,
 	override_max_chatbots, override_max_canales_whatsapp, override_max_storage_mb,
+	override_max_tokens_ia,
 	referencia_pago, metodo_pago, activado_por, notas,
 	fecha_creacion, fecha_actualizacion`
 
@@ -38,6 +39,7 @@ func scanSuscripcion(row interface{ Scan(...interface{}) error }) (*model.Suscri
 		&s.EsTrial, &s.DiasTrial, &s.FechaFinTrial,
 		&s.OverrideMaxSedes, &s.OverrideMaxUsuarios, &s.OverrideMaxReclamos,
 		&s.OverrideMaxChatbots, &s.OverrideMaxCanalesWhatsApp, &s.OverrideMaxStorageMB,
+		&s.OverrideMaxTokensIA,
 		&s.ReferenciaPago, &s.MetodoPago, &s.ActivadoPor, &s.Notas,
 		&s.FechaCreacion, &s.FechaActualizacion,
 	)
@@ -139,8 +141,9 @@ func (r *SuscripcionRepo) Create(ctx context.Context, s *model.Suscripcion) erro
 			es_trial, dias_trial, fecha_fin_trial,
 			override_max_sedes, override_max_usuarios, override_max_reclamos,
 			override_max_chatbots, override_max_canales_whatsapp, override_max_storage_mb,
+			override_max_tokens_ia,
 			referencia_pago, metodo_pago, activado_por, notas
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20)
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21)
 		RETURNING id, fecha_creacion, fecha_actualizacion`
 
 	return r.db.QueryRowContext(ctx, query,
@@ -149,6 +152,7 @@ func (r *SuscripcionRepo) Create(ctx context.Context, s *model.Suscripcion) erro
 		s.EsTrial, s.DiasTrial, s.FechaFinTrial,
 		s.OverrideMaxSedes, s.OverrideMaxUsuarios, s.OverrideMaxReclamos,
 		s.OverrideMaxChatbots, s.OverrideMaxCanalesWhatsApp, s.OverrideMaxStorageMB,
+		s.OverrideMaxTokensIA,
 		s.ReferenciaPago, s.MetodoPago, s.ActivadoPor, s.Notas,
 	).Scan(&s.ID, &s.FechaCreacion, &s.FechaActualizacion)
 }
@@ -184,7 +188,7 @@ func (r *SuscripcionRepo) CambiarEstado(ctx context.Context, tenantID, suscripci
 
 // ActualizarOverrides modifica los overrides de una suscripción.
 func (r *SuscripcionRepo) ActualizarOverrides(ctx context.Context, tenantID, suscripcionID uuid.UUID,
-	sedes, usuarios, reclamos, chatbots, canalesWA, storageMB *int64) error {
+	sedes, usuarios, reclamos, chatbots, canalesWA, storageMB, tokensIA *int64) error {
 
 	query := `
 		UPDATE suscripciones SET
@@ -194,11 +198,12 @@ func (r *SuscripcionRepo) ActualizarOverrides(ctx context.Context, tenantID, sus
 			override_max_chatbots = $4,
 			override_max_canales_whatsapp = $5,
 			override_max_storage_mb = $6,
-			fecha_actualizacion = $7
-		WHERE tenant_id = $8 AND id = $9`
+			override_max_tokens_ia = $7,
+			fecha_actualizacion = $8
+		WHERE tenant_id = $9 AND id = $10`
 
 	_, err := r.db.ExecContext(ctx, query,
-		sedes, usuarios, reclamos, chatbots, canalesWA, storageMB,
+		sedes, usuarios, reclamos, chatbots, canalesWA, storageMB, tokensIA,
 		time.Now(), tenantID, suscripcionID,
 	)
 	if err != nil {
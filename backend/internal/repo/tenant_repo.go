@@ -25,7 +25,7 @@ func (r *TenantRepo) GetByTenantID(ctx context.Context, tenantID uuid.UUID) (*mo
 			direccion_legal, departamento, provincia, distrito,
 			telefono, email_contacto, logo_url, slug, sitio_web,
 			color_primario, plazo_respuesta_dias, mensaje_confirmacion,
-			notificar_whatsapp, notificar_email, activo, version,
+			notificar_whatsapp, notificar_email, dias_reapertura, formato_codigo, activo, version,
 			fecha_creacion, fecha_actualizacion
 		FROM configuracion_tenant
 		WHERE tenant_id = $1
@@ -37,7 +37,7 @@ func (r *TenantRepo) GetByTenantID(ctx context.Context, tenantID uuid.UUID) (*mo
 		&t.DireccionLegal, &t.Departamento, &t.Provincia, &t.Distrito,
 		&t.Telefono, &t.EmailContacto, &t.LogoURL, &t.Slug, &t.SitioWeb,
 		&t.ColorPrimario, &t.PlazoRespuestaDias, &t.MensajeConfirmacion,
-		&t.NotificarWhatsapp, &t.NotificarEmail, &t.Activo, &t.Version,
+		&t.NotificarWhatsapp, &t.NotificarEmail, &t.DiasReapertura, &t.FormatoCodigo, &t.Activo, &t.Version,
 		&t.FechaCreacion, &t.FechaActualizacion,
 	)
 	if err == sql.ErrNoRows {
@@ -55,7 +55,7 @@ func (r *TenantRepo) GetBySlug(ctx context.Context, slug string) (*model.Tenant,
 			direccion_legal, departamento, provincia, distrito,
 			telefono, email_contacto, logo_url, slug, sitio_web,
 			color_primario, plazo_respuesta_dias, mensaje_confirmacion,
-			notificar_whatsapp, notificar_email, activo, version,
+			notificar_whatsapp, notificar_email, dias_reapertura, formato_codigo, activo, version,
 			fecha_creacion, fecha_actualizacion
 		FROM configuracion_tenant
 		WHERE slug = $1
@@ -67,7 +67,7 @@ func (r *TenantRepo) GetBySlug(ctx context.Context, slug string) (*model.Tenant,
 		&t.DireccionLegal, &t.Departamento, &t.Provincia, &t.Distrito,
 		&t.Telefono, &t.EmailContacto, &t.LogoURL, &t.Slug, &t.SitioWeb,
 		&t.ColorPrimario, &t.PlazoRespuestaDias, &t.MensajeConfirmacion,
-		&t.NotificarWhatsapp, &t.NotificarEmail, &t.Activo, &t.Version,
+		&t.NotificarWhatsapp, &t.NotificarEmail, &t.DiasReapertura, &t.FormatoCodigo, &t.Activo, &t.Version,
 		&t.FechaCreacion, &t.FechaActualizacion,
 	)
 	if err == sql.ErrNoRows {
@@ -106,7 +106,8 @@ func (r *TenantRepo) Update(ctx context.Context, t *model.Tenant) error {
 			direccion_legal = $4, departamento = $5, provincia = $6, distrito = $7,
 			telefono = $8, email_contacto = $9, logo_url = $10, sitio_web = $11,
 			color_primario = $12, plazo_respuesta_dias = $13, mensaje_confirmacion = $14,
-			notificar_whatsapp = $15, notificar_email = $16,
+			notificar_whatsapp = $15, notificar_email = $16, dias_reapertura = $20,
+			formato_codigo = $21,
 			version = version + 1, fecha_actualizacion = $17
 		WHERE tenant_id = $18 AND version = $19`
 
@@ -117,7 +118,7 @@ func (r *TenantRepo) Update(ctx context.Context, t *model.Tenant) error {
 		t.Telefono.NullString, t.EmailContacto.NullString, t.LogoURL.NullString, t.SitioWeb.NullString,
 		t.ColorPrimario, t.PlazoRespuestaDias, t.MensajeConfirmacion.NullString,
 		t.NotificarWhatsapp, t.NotificarEmail,
-		time.Now(), t.TenantID, t.Version,
+		time.Now(), t.TenantID, t.Version, t.DiasReapertura, t.FormatoCodigo.NullString,
 	)
 	if err != nil {
 		return fmt.Errorf("tenant_repo.Update: %w", err)
@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxIntentosTransaccion intentos de una transacción que pierde la carrera
+// por el siguiente eslabón de la cadena de integridad.
+const maxIntentosTransaccion = 4
+
+// DBTX operaciones comunes a *sql.DB y *sql.Tx.
+// Los repos que participan en transacciones guardan un DBTX y exponen WithTx.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Transactor abre transacciones que abarcan varios repos
+// (ej: reclamo + historial + outbox de notificaciones).
+type Transactor struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// EnTransaccion ejecuta fn dentro de una transacción.
+// Si fn retorna error se hace rollback; si no, commit.
+// Dos registros simultáneos del mismo tenant compiten por el último eslabón
+// (FOR UPDATE): el perdedor recibe 40001 o choca con el índice único de la
+// cadena. En ese caso se repite fn completa, que vuelve a leer el eslabón; fn
+// no debe tener efectos fuera de la transacción.
+func (t *Transactor) EnTransaccion(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	for intento := 1; ; intento++ {
+		err := t.ejecutar(ctx, fn)
+		if err == nil || !conflictoReintentable(err) || intento == maxIntentosTransaccion {
+			return err
+		}
+		espera := time.Duration(intento*10+rand.IntN(20)) * time.Millisecond
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(espera):
+		}
+	}
+}
+
+// conflictoReintentable error de serialización o choque en el índice de la cadena.
+func conflictoReintentable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	switch pqErr.Code {
+	case "40001":
+		return true
+	case "23505":
+		return strings.Contains(pqErr.Constraint+" "+pqErr.Message, "_cadena")
+	}
+	return false
+}
+
+func (t *Transactor) ejecutar(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("transactor.begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("transactor.commit: %w", err)
+	}
+	return nil
+}
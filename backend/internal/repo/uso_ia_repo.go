@@ -0,0 +1,80 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// UsoIARepo ledger de tokens consumidos por llamada a la IA.
+type UsoIARepo struct {
+	db *sql.DB
+}
+
+func NewUsoIARepo(db *sql.DB) *UsoIARepo {
+	return &UsoIARepo{db: db}
+}
+
+// Registrar inserta una llamada en el ledger.
+func (r *UsoIARepo) Registrar(ctx context.Context, u *model.UsoIA) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO uso_ia (tenant_id, origen, proveedor, tokens_prompt, tokens_output)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, fecha`,
+		u.TenantID, u.Origen, u.Proveedor, u.TokensPrompt, u.TokensOutput,
+	).Scan(&u.ID, &u.Fecha)
+	if err != nil {
+		return fmt.Errorf("uso_ia_repo.Registrar: %w", err)
+	}
+	return nil
+}
+
+// Agrupaciones del reporte (expresión SQL de la clave).
+const (
+	agruparPorOrigen    = "origen"
+	agruparPorProveedor = "proveedor"
+	agruparPorDia       = "to_char(fecha, 'YYYY-MM-DD')"
+)
+
+// agrupado totales de [desde, hasta) agrupados por la expresión indicada.
+func (r *UsoIARepo) agrupado(ctx context.Context, tenantID uuid.UUID, desde, hasta time.Time, expr string) ([]model.UsoIAAgrupado, error) {
+	query := fmt.Sprintf(`
+		SELECT %s AS clave, COUNT(*), COALESCE(SUM(tokens_prompt), 0)::INT8, COALESCE(SUM(tokens_output), 0)::INT8
+		FROM uso_ia
+		WHERE tenant_id = $1 AND fecha >= $2 AND fecha < $3
+		GROUP BY clave
+		ORDER BY clave`, expr)
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, desde, hasta)
+	if err != nil {
+		return nil, fmt.Errorf("uso_ia_repo.agrupado: %w", err)
+	}
+	defer rows.Close()
+
+	grupos := make([]model.UsoIAAgrupado, 0)
+	for rows.Next() {
+		var g model.UsoIAAgrupado
+		if err := rows.Scan(&g.Clave, &g.Llamadas, &g.TokensPrompt, &g.TokensOutput); err != nil {
+			return nil, fmt.Errorf("uso_ia_repo.agrupado scan: %w", err)
+		}
+		grupos = append(grupos, g)
+	}
+	return grupos, rows.Err()
+}
+
+func (r *UsoIARepo) PorOrigen(ctx context.Context, tenantID uuid.UUID, desde, hasta time.Time) ([]model.UsoIAAgrupado, error) {
+	return r.agrupado(ctx, tenantID, desde, hasta, agruparPorOrigen)
+}
+
+func (r *UsoIARepo) PorProveedor(ctx context.Context, tenantID uuid.UUID, desde, hasta time.Time) ([]model.UsoIAAgrupado, error) {
+	return r.agrupado(ctx, tenantID, desde, hasta, agruparPorProveedor)
+}
+
+func (r *UsoIARepo) PorDia(ctx context.Context, tenantID uuid.UUID, desde, hasta time.Time) ([]model.UsoIAAgrupado, error) {
+	return r.agrupado(ctx, tenantID, desde, hasta, agruparPorDia)
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterAdjuntoRoutes adjuntos de reclamos, mensajes y respuestas (panel).
+// La subida va en los endpoints de cada entidad (multipart/form-data).
+//
+//	GET /api/v1/reclamos/:id/adjuntos  → metadatos de todos los adjuntos del reclamo
+//	GET /api/v1/adjuntos/:id           → descargar contenido
+func RegisterAdjuntoRoutes(r *gin.Engine, ctrl *controller.AdjuntoController, authMw, tenantMw gin.HandlerFunc) {
+	reclamos := r.Group("/api/v1/reclamos")
+	reclamos.Use(authMw, tenantMw)
+	{
+		reclamos.GET("/:id/adjuntos", ctrl.GetByReclamo)
+	}
+
+	adjuntos := r.Group("/api/v1/adjuntos")
+	adjuntos.Use(authMw, tenantMw)
+	{
+		adjuntos.GET("/:id", ctrl.Descargar)
+	}
+}
+
+// RegisterAdjuntoPublicRoutes descarga de adjuntos desde el seguimiento público,
+// con el token del portal del consumidor.
+//
+//	GET /libro/:slug/seguimiento/:codigo/adjuntos/:id
+func RegisterAdjuntoPublicRoutes(r *gin.Engine, ctrl *controller.AdjuntoController, consumidorMw gin.HandlerFunc) {
+	r.GET("/libro/:slug/seguimiento/:codigo/adjuntos/:id", consumidorMw, ctrl.DescargarPublico)
+}
@@ -11,9 +11,12 @@ func RegisterAssistantRoutes(r *gin.Engine, ctrl *controller.AssistantController
 	assistant.Use(authMw, tenantMw)
 	{
 		assistant.POST("/chat", ctrl.Chat)
+		assistant.POST("/chat/stream", ctrl.ChatStream)
 		assistant.GET("/conversations", ctrl.ListarConversaciones)
 		assistant.GET("/conversations/:id/messages", ctrl.ObtenerMensajes)
 		assistant.DELETE("/conversations/:id", ctrl.EliminarConversacion)
+		assistant.POST("/acciones/:id/confirmar", ctrl.ConfirmarAccion)
+		assistant.POST("/acciones/:id/cancelar", ctrl.CancelarAccion)
 		assistant.GET("/health", ctrl.Health)
 	}
 }
@@ -0,0 +1,109 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RegisterAuditoriaRoutes consulta de la auditoría del panel (solo ADMIN).
+// GET /api/v1/auditoria          → Listado paginado y filtrable
+// GET /api/v1/auditoria/exportar → Mismos filtros, en CSV
+func RegisterAuditoriaRoutes(r *gin.Engine, ctrl *controller.AuditoriaController, authMw, tenantMw, adminMw gin.HandlerFunc) {
+	auditoria := r.Group("/api/v1/auditoria")
+	auditoria.Use(authMw, tenantMw, adminMw)
+	{
+		auditoria.GET("", ctrl.Listar)
+		auditoria.GET("/exportar", ctrl.Exportar)
+	}
+}
+
+// cargadoresAuditoria lectores del estado de cada entidad auditable, usados
+// por el middleware de auditoría para armar el diff antes/después.
+func cargadoresAuditoria(
+	usuarioService *service.UsuarioService,
+	sedeService *service.SedeService,
+	chatbotService *service.ChatbotService,
+	tenantService *service.TenantService,
+	suscripcionService *service.SuscripcionService,
+	planService *service.PlanService,
+) map[string]middleware.CargadorAuditoria {
+	return map[string]middleware.CargadorAuditoria{
+		model.EntidadUsuario: func(c *gin.Context, id string) (interface{}, error) {
+			tenantID, userID, err := tenantYEntidad(c, id)
+			if err != nil {
+				return nil, err
+			}
+			// Sin ID en la ruta: el usuario autenticado (cambio de contraseña propio)
+			if id == "" {
+				if userID, err = helper.GetUserID(c); err != nil {
+					return nil, err
+				}
+			}
+			return usuarioService.GetByID(c.Request.Context(), tenantID, userID)
+		},
+		model.EntidadSede: func(c *gin.Context, id string) (interface{}, error) {
+			tenantID, sedeID, err := tenantYEntidad(c, id)
+			if err != nil {
+				return nil, err
+			}
+			return sedeService.GetByID(c.Request.Context(), tenantID, sedeID)
+		},
+		model.EntidadChatbot: func(c *gin.Context, id string) (interface{}, error) {
+			tenantID, chatbotID, err := tenantYEntidad(c, id)
+			if err != nil {
+				return nil, err
+			}
+			return chatbotService.GetByID(c.Request.Context(), tenantID, chatbotID)
+		},
+		model.EntidadAPIKey: func(c *gin.Context, id string) (interface{}, error) {
+			tenantID, keyID, err := tenantYEntidad(c, id)
+			if err != nil {
+				return nil, err
+			}
+			return chatbotService.GetAPIKey(c.Request.Context(), tenantID, keyID)
+		},
+		model.EntidadConfig: func(c *gin.Context, _ string) (interface{}, error) {
+			tenantID, err := helper.GetTenantID(c)
+			if err != nil {
+				return nil, err
+			}
+			return tenantService.GetByTenantID(c.Request.Context(), tenantID)
+		},
+		model.EntidadSuscripcion: func(c *gin.Context, _ string) (interface{}, error) {
+			tenantID, err := helper.GetTenantID(c)
+			if err != nil {
+				return nil, err
+			}
+			return suscripcionService.GetActiva(c.Request.Context(), tenantID)
+		},
+		model.EntidadPlan: func(c *gin.Context, id string) (interface{}, error) {
+			planID, err := uuid.Parse(id)
+			if err != nil {
+				return nil, err
+			}
+			return planService.GetByID(c.Request.Context(), planID)
+		},
+	}
+}
+
+// tenantYEntidad tenant del contexto + ID de la entidad (uuid.Nil si id viene vacío).
+func tenantYEntidad(c *gin.Context, id string) (uuid.UUID, uuid.UUID, error) {
+	tenantID, err := helper.GetTenantID(c)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	if id == "" {
+		return tenantID, uuid.Nil, nil
+	}
+	entidadID, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, err
+	}
+	return tenantID, entidadID, nil
+}
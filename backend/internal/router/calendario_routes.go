@@ -0,0 +1,48 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCalendarioRoutes calendario SLA del tenant (días hábiles y feriados).
+//
+//	GET    /api/v1/calendario               → reglas vigentes
+//	GET    /api/v1/calendario/feriados      → feriados del tenant
+//	PUT    /api/v1/calendario               → actualizar reglas (ADMIN)
+//	POST   /api/v1/calendario/feriados      → agregar feriado (ADMIN)
+//	DELETE /api/v1/calendario/feriados/:id  → eliminar feriado (ADMIN)
+//	POST   /api/v1/calendario/recalcular    → recalcular reclamos abiertos (ADMIN)
+func RegisterCalendarioRoutes(r *gin.Engine, ctrl *controller.CalendarioController, authMw, tenantMw gin.HandlerFunc) {
+	calendario := r.Group("/api/v1/calendario")
+	calendario.Use(authMw, tenantMw)
+	{
+		calendario.GET("", ctrl.GetConfig)
+		calendario.GET("/feriados", ctrl.GetFeriados)
+
+		admin := calendario.Group("")
+		admin.Use(middleware.RoleMiddleware(model.RolAdmin))
+		{
+			admin.PUT("", ctrl.UpdateConfig)
+			admin.POST("/feriados", ctrl.CreateFeriado)
+			admin.DELETE("/feriados/:id", ctrl.DeleteFeriado)
+			admin.POST("/recalcular", ctrl.Recalcular)
+		}
+	}
+}
+
+// RegisterAlertaSLARoutes configuración de alertas SLA del tenant.
+//
+//	GET /api/v1/alertas-sla  → umbrales y destinatarios vigentes
+//	PUT /api/v1/alertas-sla  → actualizar (ADMIN)
+func RegisterAlertaSLARoutes(r *gin.Engine, ctrl *controller.AlertaSLAController, authMw, tenantMw gin.HandlerFunc) {
+	alertas := r.Group("/api/v1/alertas-sla")
+	alertas.Use(authMw, tenantMw)
+	{
+		alertas.GET("", ctrl.GetConfig)
+		alertas.PUT("", middleware.RoleMiddleware(model.RolAdmin), ctrl.UpdateConfig)
+	}
+}
@@ -8,23 +8,23 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterChatbotRoutes(r *gin.Engine, ctrl *controller.ChatbotController, authMw, tenantMw gin.HandlerFunc) {
+func RegisterChatbotRoutes(r *gin.Engine, ctrl *controller.ChatbotController, authMw, tenantMw gin.HandlerFunc, auditar middleware.Auditar) {
 	chatbots := r.Group("/api/v1/chatbots")
 	chatbots.Use(authMw, tenantMw, middleware.RoleMiddleware(model.RolAdmin))
 	{
 		chatbots.GET("", ctrl.GetAll)
 		chatbots.GET("/:id", ctrl.GetByID)
-		chatbots.POST("", ctrl.Create)
-		chatbots.PUT("/:id", ctrl.Update)
-		chatbots.DELETE("/:id", ctrl.Delete)
+		chatbots.POST("", auditar(model.EntidadChatbot, model.AuditCrear, ""), ctrl.Create)
+		chatbots.PUT("/:id", auditar(model.EntidadChatbot, model.AuditActualizar, "id"), ctrl.Update)
+		chatbots.DELETE("/:id", auditar(model.EntidadChatbot, model.AuditEliminar, "id"), ctrl.Delete)
 
 		// Lifecycle
-		chatbots.POST("/:id/deactivate", ctrl.Deactivate)
-		chatbots.POST("/:id/reactivate", ctrl.Reactivate)
+		chatbots.POST("/:id/deactivate", auditar(model.EntidadChatbot, model.AuditDesactivar, "id"), ctrl.Deactivate)
+		chatbots.POST("/:id/reactivate", auditar(model.EntidadChatbot, model.AuditActivar, "id"), ctrl.Reactivate)
 
 		// API Keys
 		chatbots.GET("/:id/api-keys", ctrl.GetAPIKeys)
-		chatbots.POST("/:id/api-keys", ctrl.GenerateAPIKey)
-		chatbots.DELETE("/:id/api-keys/:keyId", ctrl.RevokeAPIKey)
+		chatbots.POST("/:id/api-keys", auditar(model.EntidadAPIKey, model.AuditGenerarAPIKey, ""), ctrl.GenerateAPIKey)
+		chatbots.DELETE("/:id/api-keys/:keyId", auditar(model.EntidadAPIKey, model.AuditRevocarAPIKey, "keyId"), ctrl.RevokeAPIKey)
 	}
 }
\ No newline at end of file
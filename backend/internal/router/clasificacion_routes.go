@@ -0,0 +1,30 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCategoriaReclamoRoutes taxonomía de la clasificación IA. Leer: todo
+// usuario (filtros del panel); crear/editar: solo ADMIN.
+func RegisterCategoriaReclamoRoutes(r *gin.Engine, ctrl *controller.CategoriaReclamoController, authMw, tenantMw, adminMw gin.HandlerFunc, auditar middleware.Auditar) {
+	categorias := r.Group("/api/v1/categorias-reclamo")
+	categorias.Use(authMw, tenantMw)
+	{
+		categorias.GET("", ctrl.Listar)
+		categorias.POST("", adminMw, auditar(model.EntidadCategoria, model.AuditCrear, ""), ctrl.Crear)
+		categorias.PUT("/:id", adminMw, auditar(model.EntidadCategoria, model.AuditActualizar, "id"), ctrl.Actualizar)
+	}
+}
+
+// RegisterClasificacionRoutes solo se registra con un proveedor de IA configurado.
+func RegisterClasificacionRoutes(r *gin.Engine, ctrl *controller.ClasificacionController, authMw, tenantMw gin.HandlerFunc) {
+	reclamos := r.Group("/api/v1/reclamos")
+	reclamos.Use(authMw, tenantMw)
+	{
+		reclamos.POST("/:id/clasificar", ctrl.Reclasificar)
+	}
+}
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterDuplicadoRoutes duplicados probables, vínculos y fusiones entre
+// reclamos, e historial del consumidor.
+func RegisterDuplicadoRoutes(r *gin.Engine, ctrl *controller.DuplicadoController, authMw, tenantMw gin.HandlerFunc) {
+	reclamos := r.Group("/api/v1/reclamos")
+	reclamos.Use(authMw, tenantMw)
+	{
+		reclamos.GET("/:id/duplicados", ctrl.Listar)
+		reclamos.GET("/:id/consumidor", ctrl.HistorialConsumidor)
+		// Vincular y fusionar modifican también el otro reclamo: solo ADMIN
+		reclamos.POST("/:id/vincular", middleware.RoleMiddleware(model.RolAdmin), ctrl.Vincular)
+		reclamos.POST("/:id/fusionar", middleware.RoleMiddleware(model.RolAdmin), ctrl.Fusionar)
+		reclamos.POST("/:id/descartar-duplicado", ctrl.Descartar)
+	}
+}
@@ -13,4 +13,10 @@ func RegistrarRutasExportacion(r *gin.Engine, ctrl *controller.ExportarControlad
 		exportar.GET("/pdf", ctrl.ExportarPDF)
 		exportar.GET("/excel", ctrl.ExportarExcel)
 	}
-}
\ No newline at end of file
+
+	reclamos := r.Group("/api/v1/reclamos")
+	reclamos.Use(authMw, tenantMw)
+	{
+		reclamos.GET("/:id/pdf", ctrl.ExportarHojaReclamo)
+	}
+}
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterIntegridadRoutes verificación de la cadena de hash del tenant. Solo ADMIN.
+// GET /api/v1/admin/integridad/verificar → Recorre reclamos e historial, reporta el primer quiebre
+func RegisterIntegridadRoutes(r *gin.Engine, ctrl *controller.IntegridadController, authMw, tenantMw, adminMw gin.HandlerFunc) {
+	integridad := r.Group("/api/v1/admin/integridad")
+	integridad.Use(authMw, tenantMw, adminMw)
+	{
+		integridad.GET("/verificar", ctrl.Verificar)
+	}
+}
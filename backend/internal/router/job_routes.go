@@ -0,0 +1,23 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterJobAdminRoutes jobs del scheduler (tareas periódicas del sistema).
+// Son de toda la plataforma: solo los ADMIN de los tenants operadores
+// (middleware.OperadorPlataformaMiddleware), no los de cada cliente.
+// GET  /api/v1/admin/jobs                      → Jobs registrados + última ejecución
+// GET  /api/v1/admin/jobs/:nombre/ejecuciones  → Historial de corridas
+// POST /api/v1/admin/jobs/:nombre/ejecutar     → Ejecutar ahora
+func RegisterJobAdminRoutes(r *gin.Engine, ctrl *controller.JobController, authMw, tenantMw, operadorMw gin.HandlerFunc) {
+	jobs := r.Group("/api/v1/admin/jobs")
+	jobs.Use(authMw, tenantMw, operadorMw)
+	{
+		jobs.GET("", ctrl.GetAll)
+		jobs.GET("/:nombre/ejecuciones", ctrl.GetEjecuciones)
+		jobs.POST("/:nombre/ejecutar", ctrl.Ejecutar)
+	}
+}
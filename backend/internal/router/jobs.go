@@ -0,0 +1,88 @@
+package router
+
+import (
+	"context"
+	"fmt"
+
+	"libro-reclamaciones/internal/scheduler"
+	"libro-reclamaciones/internal/service"
+)
+
+// registrarJobs define las tareas periódicas del sistema.
+// Horarios en la zona de SCHEDULER_TIMEZONE (America/Lima por defecto).
+func registrarJobs(
+	sched *scheduler.Scheduler,
+	suscripcionService *service.SuscripcionService,
+	alertaSLAService *service.AlertaSLAService,
+	outboxService *service.OutboxService,
+) {
+	registrar := func(nombre, horario, descripcion string, tarea scheduler.Tarea) {
+		if err := sched.Registrar(nombre, horario, descripcion, 0, tarea); err != nil {
+			fmt.Printf("[WARN] %v\n", err)
+		}
+	}
+
+	registrar("vencimiento_suscripciones", "5 * * * *",
+		"Marca como VENCIDAS las suscripciones trial expiradas",
+		func(ctx context.Context) (string, error) {
+			n, err := suscripcionService.ProcesarVencimientos(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d suscripciones vencidas", n), nil
+		})
+
+	registrar("alertas_sla", "0 8 * * *",
+		"Avisa reclamos que entran en ventana URGENTE o vencen (asignado, sede, empresa)",
+		func(ctx context.Context) (string, error) {
+			n, err := alertaSLAService.ProcesarAlertas(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d reclamos notificados", n), nil
+		})
+
+	registrar("outbox_notificaciones", "@every 15s",
+		"Envía notificaciones pendientes del outbox (reintentos con backoff, dead-letter)",
+		func(ctx context.Context) (string, error) {
+			enviadas, fallidas, err := outboxService.Procesar(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d enviadas, %d con error", enviadas, fallidas), nil
+		})
+}
+
+// registrarJobClasificacion solo con un proveedor de IA configurado; sin él
+// los reclamos quedan PENDIENTE hasta que se configure.
+func registrarJobClasificacion(sched *scheduler.Scheduler, clasificacionService *service.ClasificacionService) {
+	err := sched.Registrar("clasificacion_reclamos", "@every 30s",
+		"Clasifica con IA los reclamos nuevos (categoría, sentimiento, urgencia, resumen)", 0,
+		func(ctx context.Context) (string, error) {
+			clasificados, fallidos, err := clasificacionService.Procesar(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d clasificados, %d con error", clasificados, fallidos), nil
+		})
+	if err != nil {
+		fmt.Printf("[WARN] %v\n", err)
+	}
+}
+
+// registrarJobColaWhatsApp red de seguridad de la cola de WhatsApp: el webhook
+// la despierta al encolar; el job retoma reintentos y mensajes de réplicas caídas.
+func registrarJobColaWhatsApp(sched *scheduler.Scheduler, colaService *service.ColaWhatsAppService) {
+	err := sched.Registrar("cola_whatsapp_entrante", "@every 10s",
+		"Responde los mensajes entrantes de WhatsApp encolados (orden por teléfono, reintentos del envío)", 0,
+		func(ctx context.Context) (string, error) {
+			procesados, fallidos, err := colaService.Procesar(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d respondidos, %d con error", procesados, fallidos), nil
+		})
+	if err != nil {
+		fmt.Printf("[WARN] %v\n", err)
+	}
+}
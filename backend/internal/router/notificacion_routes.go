@@ -0,0 +1,29 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterNotificacionRoutes estado de entrega del outbox de notificaciones (ADMIN).
+//
+//	GET  /api/v1/reclamos/:id/notificaciones     → notificaciones del reclamo con estado e intentos
+//	POST /api/v1/notificaciones/:id/reintentar   → reencolar una notificación en dead-letter
+func RegisterNotificacionRoutes(r *gin.Engine, ctrl *controller.NotificacionController, authMw, tenantMw gin.HandlerFunc) {
+	adminMw := middleware.RoleMiddleware(model.RolAdmin)
+
+	reclamos := r.Group("/api/v1/reclamos")
+	reclamos.Use(authMw, tenantMw, adminMw)
+	{
+		reclamos.GET("/:id/notificaciones", ctrl.GetByReclamo)
+	}
+
+	notificaciones := r.Group("/api/v1/notificaciones")
+	notificaciones.Use(authMw, tenantMw, adminMw)
+	{
+		notificaciones.POST("/:id/reintentar", ctrl.Reintentar)
+	}
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,15 +27,15 @@ func RegisterPlanRoutes(r *gin.Engine, ctrl *controller.PlanController, authMw,
 // PUT    /api/v1/admin/planes/:id          → Actualizar plan
 // PATCH  /api/v1/admin/planes/:id/activar  → Activar
 // PATCH  /api/v1/admin/planes/:id/desactivar → Desactivar
-func RegisterPlanAdminRoutes(r *gin.Engine, ctrl *controller.PlanController, authMw, tenantMw, adminMw gin.HandlerFunc) {
+func RegisterPlanAdminRoutes(r *gin.Engine, ctrl *controller.PlanController, authMw, tenantMw, adminMw gin.HandlerFunc, auditar middleware.Auditar) {
 	admin := r.Group("/api/v1/admin/planes")
 	admin.Use(authMw, tenantMw, adminMw)
 	{
 		admin.GET("", ctrl.GetAllAdmin)
-		admin.POST("", ctrl.Crear)
-		admin.PUT("/:id", ctrl.Actualizar)
-		admin.PATCH("/:id/activar", ctrl.Activar)
-		admin.PATCH("/:id/desactivar", ctrl.Desactivar)
+		admin.POST("", auditar(model.EntidadPlan, model.AuditCrear, ""), ctrl.Crear)
+		admin.PUT("/:id", auditar(model.EntidadPlan, model.AuditActualizar, "id"), ctrl.Actualizar)
+		admin.PATCH("/:id/activar", auditar(model.EntidadPlan, model.AuditActivar, "id"), ctrl.Activar)
+		admin.PATCH("/:id/desactivar", auditar(model.EntidadPlan, model.AuditDesactivar, "id"), ctrl.Desactivar)
 	}
 }
 
@@ -42,13 +44,13 @@ func RegisterPlanAdminRoutes(r *gin.Engine, ctrl *controller.PlanController, aut
 // GET  /api/v1/suscripcion/uso         → Uso actual vs límites
 // GET  /api/v1/suscripcion/historial   → Historial de suscripciones
 // POST /api/v1/suscripcion/cambiar-plan → Cambiar de plan
-func RegisterSuscripcionRoutes(r *gin.Engine, ctrl *controller.SuscripcionController, authMw, tenantMw gin.HandlerFunc) {
+func RegisterSuscripcionRoutes(r *gin.Engine, ctrl *controller.SuscripcionController, authMw, tenantMw gin.HandlerFunc, auditar middleware.Auditar) {
 	sus := r.Group("/api/v1/suscripcion")
 	sus.Use(authMw, tenantMw)
 	{
 		sus.GET("", ctrl.GetActiva)
 		sus.GET("/uso", ctrl.GetUso)
 		sus.GET("/historial", ctrl.GetHistorial)
-		sus.POST("/cambiar-plan", ctrl.CambiarPlan)
+		sus.POST("/cambiar-plan", auditar(model.EntidadSuscripcion, model.AuditCambiarPlan, ""), ctrl.CambiarPlan)
 	}
 }
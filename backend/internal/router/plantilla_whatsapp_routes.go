@@ -0,0 +1,27 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPlantillaWhatsAppRoutes plantillas de Meta para notificar al
+// consumidor por WhatsApp (ADMIN).
+//
+//	GET    /api/v1/plantillas-whatsapp       → plantillas del tenant
+//	POST   /api/v1/plantillas-whatsapp       → registrar la plantilla de un tipo
+//	PUT    /api/v1/plantillas-whatsapp/:id   → cambiar nombre, idioma, variables o activo
+//	DELETE /api/v1/plantillas-whatsapp/:id   → dejar de notificar ese tipo
+func RegisterPlantillaWhatsAppRoutes(r *gin.Engine, ctrl *controller.PlantillaWhatsAppController, authMw, tenantMw gin.HandlerFunc) {
+	plantillas := r.Group("/api/v1/plantillas-whatsapp")
+	plantillas.Use(authMw, tenantMw, middleware.RoleMiddleware(model.RolAdmin))
+	{
+		plantillas.GET("", ctrl.Listar)
+		plantillas.POST("", ctrl.Crear)
+		plantillas.PUT("/:id", ctrl.Actualizar)
+		plantillas.DELETE("/:id", ctrl.Eliminar)
+	}
+}
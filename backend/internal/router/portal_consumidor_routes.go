@@ -0,0 +1,23 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPortalConsumidorRoutes acceso del consumidor con código de un solo
+// uso y listado de sus reclamos. El token obtenido se envía como Bearer en
+// /libro/:slug/seguimiento/:codigo (respuesta, PDF, mensajes y adjuntos).
+//
+//	POST /libro/:slug/portal/acceso            → enviar código por email o WhatsApp
+//	POST /libro/:slug/portal/acceso/verificar  → canjear el código por el token
+//	GET  /libro/:slug/portal/reclamos          → reclamos del consumidor (token)
+func RegisterPortalConsumidorRoutes(r *gin.Engine, ctrl *controller.PortalConsumidorController, consumidorMw gin.HandlerFunc) {
+	portal := r.Group("/libro/:slug/portal")
+	{
+		portal.POST("/acceso", ctrl.SolicitarCodigo)
+		portal.POST("/acceso/verificar", ctrl.Verificar)
+		portal.GET("/reclamos", consumidorMw, ctrl.ListarReclamos)
+	}
+}
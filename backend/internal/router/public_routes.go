@@ -6,14 +6,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterPublicRoutes(r *gin.Engine, ctrl *controller.PublicController) {
+// RegisterPublicRoutes formulario y seguimiento públicos del libro.
+// consumidorMw lee el token del portal del consumidor si viene: la respuesta,
+// la hoja PDF y los mensajes solo se entregan al consumidor dueño del reclamo.
+func RegisterPublicRoutes(r *gin.Engine, ctrl *controller.PublicController, consumidorMw gin.HandlerFunc) {
 	libro := r.Group("/libro/:slug")
 	{
 		libro.GET("/tenant", ctrl.GetTenant)
 		libro.GET("/sedes", ctrl.GetSedes)
 		libro.POST("/reclamos", ctrl.CrearReclamo)
-		libro.GET("/seguimiento/:codigo", ctrl.ConsultarSeguimiento)
-		libro.GET("/seguimiento/:codigo/mensajes", ctrl.ListarMensajesPublico)
-		libro.POST("/seguimiento/:codigo/mensajes", ctrl.EnviarMensajePublico)
+		libro.GET("/seguimiento/:codigo", consumidorMw, ctrl.ConsultarSeguimiento)
+		libro.GET("/seguimiento/:codigo/pdf", consumidorMw, ctrl.DescargarHojaPublica)
+		libro.POST("/seguimiento/:codigo/reabrir", ctrl.ReabrirPublico)
+		libro.GET("/seguimiento/:codigo/mensajes", consumidorMw, ctrl.ListarMensajesPublico)
+		libro.POST("/seguimiento/:codigo/mensajes", consumidorMw, ctrl.EnviarMensajePublico)
 	}
-}
\ No newline at end of file
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,8 +13,10 @@ func RegisterReclamoRoutes(r *gin.Engine, ctrl *controller.ReclamoController, au
 	reclamos.Use(authMw, tenantMw)
 	{
 		reclamos.GET("", ctrl.GetAll)
+		reclamos.GET("/buscar", ctrl.Buscar)
 		reclamos.GET("/:id", ctrl.GetByID)
 		reclamos.POST("/:id/estado", ctrl.CambiarEstado)
 		reclamos.POST("/:id/asignar", ctrl.Asignar)
+		reclamos.POST("/:id/reabrir", middleware.RoleMiddleware(model.RolAdmin), ctrl.Reabrir)
 	}
 }
\ No newline at end of file
@@ -13,4 +13,12 @@ func RegisterRespuestaRoutes(r *gin.Engine, ctrl *controller.RespuestaController
 		respuestas.GET("/:id/respuestas", ctrl.GetByReclamo)
 		respuestas.POST("/:id/respuestas", ctrl.Create)
 	}
-}
\ No newline at end of file
+}
+// RegisterSugerenciaRespuestaRoutes solo se registra con un proveedor de IA configurado.
+func RegisterSugerenciaRespuestaRoutes(r *gin.Engine, ctrl *controller.SugerenciaRespuestaController, authMw, tenantMw gin.HandlerFunc) {
+	respuestas := r.Group("/api/v1/reclamos")
+	respuestas.Use(authMw, tenantMw)
+	{
+		respuestas.POST("/:id/respuestas/sugerencia", ctrl.Sugerir)
+	}
+}
@@ -5,16 +5,28 @@ import (
 	"fmt"
 
 	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/cifrado"
 	"libro-reclamaciones/internal/config"
 	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/helper"
 	"libro-reclamaciones/internal/middleware"
 	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/scheduler"
 	"libro-reclamaciones/internal/service"
+	"libro-reclamaciones/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
+// RegisterRoutes arma repos, services y controllers y registra todas las rutas.
+// Retorna el scheduler con los jobs del sistema; main lo inicia y detiene.
+// secretos nil = tokens de tenants en claro (desarrollo sin clave maestra).
+func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB, almacenamiento storage.Storage, secretos *cifrado.Secretos) *scheduler.Scheduler {
+	var cifradorSecretos repo.CifradorSecretos
+	if secretos != nil {
+		cifradorSecretos = secretos
+	}
+
 	// --- Repos ---
 	planRepo := repo.NewPlanRepo(db)
 	suscripcionRepo := repo.NewSuscripcionRepo(db)
@@ -30,12 +42,26 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
 	chatbotRepo := repo.NewChatbotRepo(db)
 	apiKeyRepo := repo.NewChatbotAPIKeyRepo(db)
 	logRepo := repo.NewChatbotLogRepo(db)
-	canalWARepo := repo.NewCanalWhatsAppRepo(db)
+	canalWARepo := repo.NewCanalWhatsAppRepo(db, cifradorSecretos)
+	plantillaWARepo := repo.NewPlantillaWhatsAppRepo(db)
 	solicitudAsesorRepo := repo.NewSolicitudAsesorRepo(db)
 	mensajeAtencionRepo := repo.NewMensajeAtencionRepo(db)
+	calendarioRepo := repo.NewCalendarioRepo(db)
+	jobRepo := repo.NewJobRepo(db)
+	alertaSLARepo := repo.NewAlertaSLARepo(db)
+	outboxRepo := repo.NewOutboxRepo(db)
+	adjuntoRepo := repo.NewAdjuntoRepo(db)
+	integridadRepo := repo.NewIntegridadRepo(db)
+	auditoriaRepo := repo.NewAuditoriaRepo(db)
+	reclamoRelacionRepo := repo.NewReclamoRelacionRepo(db)
+	codigoAccesoRepo := repo.NewCodigoAccesoRepo(db)
+	transactor := repo.NewTransactor(db)
 
 	// --- Services ---
 	notifService := service.NewNotificacionService(cfg.SMTP)
+	exportarPDFServicio := service.NuevoExportarPDFServicio()
+	outboxService := service.NewOutboxService(outboxRepo, reclamoRepo, tenantRepo, plantillaWARepo, canalWARepo, notifService, exportarPDFServicio)
+	adjuntoService := service.NewAdjuntoService(adjuntoRepo, dashboardRepo, almacenamiento, cfg.Storage)
 
 	planService := service.NewPlanService(planRepo)
 	suscripcionService := service.NewSuscripcionService(suscripcionRepo, planRepo)
@@ -43,12 +69,18 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
 	sedeService := service.NewSedeService(sedeRepo, dashboardRepo)
 	usuarioService := service.NewUsuarioService(usuarioRepo, dashboardRepo)
 	authService := service.NewAuthService(usuarioRepo, sesionRepo, tenantRepo, cfg.JWT)
-	reclamoService := service.NewReclamoService(reclamoRepo, historialRepo, tenantRepo, sedeRepo, dashboardRepo, notifService)
-	respuestaService := service.NewRespuestaService(respuestaRepo, reclamoRepo, historialRepo, notifService, tenantRepo)
-	mensajeService := service.NewMensajeService(mensajeRepo, reclamoRepo, tenantRepo, notifService)
+	calendarioService := service.NewCalendarioService(calendarioRepo, reclamoRepo, tenantRepo)
+	duplicadoService := service.NewDuplicadoService(reclamoRelacionRepo, reclamoRepo, historialRepo, transactor, outboxService)
+	reclamoService := service.NewReclamoService(reclamoRepo, historialRepo, tenantRepo, sedeRepo, dashboardRepo, calendarioService, transactor, outboxService, adjuntoService, duplicadoService)
+	alertaSLAService := service.NewAlertaSLAService(alertaSLARepo, tenantRepo, sedeRepo, usuarioRepo, calendarioService, notifService)
+	respuestaService := service.NewRespuestaService(respuestaRepo, reclamoRepo, historialRepo, transactor, outboxService, adjuntoService)
+	mensajeService := service.NewMensajeService(mensajeRepo, reclamoRepo, transactor, outboxService, adjuntoService)
 	chatbotService := service.NewChatbotService(chatbotRepo, apiKeyRepo, dashboardRepo, cfg.APIKey.Prefix)
 	mensajeAtencionService := service.NewMensajeAtencionService(mensajeAtencionRepo, solicitudAsesorRepo, canalWARepo)
 	solicitudAsesorService := service.NewSolicitudAsesorService(solicitudAsesorRepo, mensajeAtencionService, canalWARepo, usuarioRepo)
+	integridadService := service.NewIntegridadService(integridadRepo)
+	auditoriaService := service.NewAuditoriaService(auditoriaRepo)
+	portalConsumidorService := service.NewPortalConsumidorService(codigoAccesoRepo, reclamoRelacionRepo, canalWARepo, notifService, cfg.JWT)
 
 	// --- Controllers ---
 	planCtrl := controller.NewPlanController(planService)
@@ -60,13 +92,17 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
 	usuarioCtrl := controller.NewUsuarioController(usuarioService)
 	authCtrl := controller.NewAuthController(authService)
 	reclamoCtrl := controller.NewReclamoController(reclamoService)
-	exportarPDFServicio := service.NuevoExportarPDFServicio()
+	calendarioCtrl := controller.NewCalendarioController(calendarioService)
+	alertaSLACtrl := controller.NewAlertaSLAController(alertaSLAService)
+	notificacionCtrl := controller.NewNotificacionController(outboxService)
+	adjuntoCtrl := controller.NewAdjuntoController(adjuntoService, tenantService, reclamoService)
 	exportarExcelServicio := service.NuevoExportarExcelServicio()
-	exportarCtrl := controller.NuevoExportarControlador(reclamoService, tenantService, exportarPDFServicio, exportarExcelServicio)
+	exportarCtrl := controller.NuevoExportarControlador(reclamoService, tenantService, respuestaService, exportarPDFServicio, exportarExcelServicio)
 
-	respuestaCtrl := controller.NewRespuestaController(respuestaService)
+	respuestaCtrl := controller.NewRespuestaController(respuestaService, adjuntoService)
 	mensajeCtrl := controller.NewMensajeController(mensajeService)
-	publicCtrl := controller.NewPublicController(reclamoService, tenantService, sedeService, mensajeService, respuestaService)
+	publicCtrl := controller.NewPublicController(reclamoService, tenantService, sedeService, mensajeService, respuestaService, adjuntoService, exportarPDFServicio)
+	portalConsumidorCtrl := controller.NewPortalConsumidorController(portalConsumidorService, tenantService)
 	dashboardCtrl := controller.NewDashboardController(dashboardRepo)
 	chatbotCtrl := controller.NewChatbotController(chatbotService)
 	botAPICtrl := controller.NewBotAPIController(reclamoService, respuestaService, mensajeService, logRepo)
@@ -79,26 +115,48 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
 	// --- Middlewares ---
 	authMw := middleware.AuthMiddleware(cfg.JWT)
 	tenantMw := middleware.TenantMiddleware(db)
+	consumidorMw := middleware.ConsumidorAuthMiddleware(cfg.JWT)
+	consumidorOpcionalMw := middleware.ConsumidorOpcionalMiddleware(cfg.JWT)
+	auditar := middleware.NuevoAuditar(auditoriaService, cargadoresAuditoria(
+		usuarioService, sedeService, chatbotService, tenantService, suscripcionService, planService,
+	))
 
 	// --- Rutas públicas ---
-	RegisterPublicRoutes(r, publicCtrl)
+	RegisterPublicRoutes(r, publicCtrl, consumidorOpcionalMw)
+	RegisterAdjuntoPublicRoutes(r, adjuntoCtrl, consumidorOpcionalMw)
+	RegisterPortalConsumidorRoutes(r, portalConsumidorCtrl, consumidorMw)
 	RegisterOnboardingRoutes(r, onboardingCtrl)
 
 	// --- Rutas admin (JWT) ---
 	RegisterAuthRoutes(r, authCtrl, authMw, tenantMw)
 	RegisterPlanRoutes(r, planCtrl, authMw, tenantMw)
-	RegisterSuscripcionRoutes(r, suscripcionCtrl, authMw, tenantMw)
-	RegisterTenantRoutes(r, tenantCtrl, authMw, tenantMw)
-	RegisterSedeRoutes(r, sedeCtrl, authMw, tenantMw)
-	RegisterUsuarioRoutes(r, usuarioCtrl, authMw, tenantMw)
+	RegisterSuscripcionRoutes(r, suscripcionCtrl, authMw, tenantMw, auditar)
+	RegisterTenantRoutes(r, tenantCtrl, authMw, tenantMw, auditar)
+	RegisterSedeRoutes(r, sedeCtrl, authMw, tenantMw, auditar)
+	RegisterUsuarioRoutes(r, usuarioCtrl, authMw, tenantMw, auditar)
 	RegistrarRutasExportacion(r, exportarCtrl, authMw, tenantMw)
 	RegisterReclamoRoutes(r, reclamoCtrl, authMw, tenantMw)
+	RegisterDuplicadoRoutes(r, controller.NewDuplicadoController(duplicadoService), authMw, tenantMw)
+	RegisterCalendarioRoutes(r, calendarioCtrl, authMw, tenantMw)
+	RegisterAlertaSLARoutes(r, alertaSLACtrl, authMw, tenantMw)
+	RegisterNotificacionRoutes(r, notificacionCtrl, authMw, tenantMw)
+	RegisterAdjuntoRoutes(r, adjuntoCtrl, authMw, tenantMw)
 	RegisterRespuestaRoutes(r, respuestaCtrl, authMw, tenantMw)
 	RegisterMensajeRoutes(r, mensajeCtrl, authMw, tenantMw)
 	RegisterDashboardRoutes(r, dashboardCtrl, authMw, tenantMw)
-	RegisterChatbotRoutes(r, chatbotCtrl, authMw, tenantMw)
+	RegisterChatbotRoutes(r, chatbotCtrl, authMw, tenantMw, auditar)
 	adminMw := middleware.RoleMiddleware("ADMIN")
-	RegisterPlanAdminRoutes(r, planCtrl, authMw, tenantMw, adminMw)
+	operadorMw := middleware.OperadorPlataformaMiddleware(cfg.Plataforma.TenantsOperadores)
+	RegisterPlanAdminRoutes(r, planCtrl, authMw, tenantMw, adminMw, auditar)
+	RegisterAuditoriaRoutes(r, controller.NewAuditoriaController(auditoriaService), authMw, tenantMw, adminMw)
+	RegisterIntegridadRoutes(r, controller.NewIntegridadController(integridadService), authMw, tenantMw, adminMw)
+	categoriaReclamoRepo := repo.NewCategoriaReclamoRepo(db)
+	RegisterCategoriaReclamoRoutes(r, controller.NewCategoriaReclamoController(service.NewCategoriaReclamoService(categoriaReclamoRepo)), authMw, tenantMw, adminMw, auditar)
+
+	// --- Scheduler (jobs periódicos) ---
+	sched := scheduler.New(jobRepo, helper.CargarZonaHoraria(cfg.Scheduler.ZonaHoraria))
+	registrarJobs(sched, suscripcionService, alertaSLAService, outboxService)
+	RegisterJobAdminRoutes(r, controller.NewJobController(sched, jobRepo), authMw, tenantMw, operadorMw)
 	RegisterSolicitudAsesorRoutes(r, solicitudAsesorCtrl, mensajeAtencionCtrl, authMw, tenantMw)
 
 	// --- API externa para chatbots (API Key) ---
@@ -131,8 +189,27 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
 		}
 	}
 
+	// --- Uso de IA: ledger de tokens y cuota mensual por plan ---
+	usoIAService := service.NewUsoIAService(repo.NewUsoIARepo(db), limitesService)
+	RegisterUsoIARoutes(r, controller.NewUsoIAController(usoIAService), authMw, tenantMw, adminMw)
+
 	// --- WhatsApp: Webhook + Config Admin ---
 	if cfg.WhatsApp.Enabled {
+		// Transcripción de notas de voz (opcional)
+		var transcriptor ai.Transcriptor
+		if cfg.AI.STTProvider != "" {
+			var err error
+			transcriptor, err = ai.NewTranscriptor(ai.GatewayConfig{
+				Provider: cfg.AI.STTProvider,
+				APIKey:   cfg.AI.STTAPIKey,
+				Model:    cfg.AI.STTModel,
+				BaseURL:  cfg.AI.STTBaseURL,
+			})
+			if err != nil {
+				fmt.Printf("[WARN] Transcripción de audio no disponible: %v\n", err)
+			}
+		}
+
 		whatsappService := service.NewWhatsAppService(
 			reclamoService,
 			solicitudAsesorService,
@@ -140,33 +217,72 @@ func RegisterRoutes(r *gin.Engine, cfg *config.Config, db *sql.DB) {
 			tenantRepo,
 			canalWARepo,
 			chatbotRepo,
+			sedeRepo,
+			adjuntoService,
 			aiProvider,
+			transcriptor,
+			usoIAService,
+			repo.NewConversacionWhatsAppRepo(db),
+		)
+
+		webhookWhatsAppService := service.NewWebhookWhatsAppService(
+			cfg.WhatsApp.AppSecret,
+			canalWARepo,
+			repo.NewMensajeWhatsAppProcesadoRepo(db),
 		)
 
-		whatsappCtrl := controller.NewWhatsAppController(cfg.WhatsApp, whatsappService)
+		colaWhatsAppService := service.NewColaWhatsAppService(repo.NewColaWhatsAppRepo(db), whatsappService)
+		registrarJobColaWhatsApp(sched, colaWhatsAppService)
+
+		whatsappCtrl := controller.NewWhatsAppController(cfg.WhatsApp, whatsappService, webhookWhatsAppService, colaWhatsAppService, outboxService)
 		RegistrarRutasWebhookWhatsApp(r, whatsappCtrl)
+		RegisterWebhookWhatsAppAdminRoutes(r, whatsappCtrl, authMw, tenantMw, operadorMw)
 
 		// ← CAMBIO: ahora recibe limitesService para validar límite de canales
 		whatsappConfigCtrl := controller.NewWhatsAppConfigController(canalWARepo, chatbotRepo, limitesService)
 		RegisterWhatsAppConfigRoutes(r, whatsappConfigCtrl, authMw, tenantMw)
 
+		// Plantillas de Meta para notificar al consumidor (notificar_whatsapp)
+		plantillaWACtrl := controller.NewPlantillaWhatsAppController(service.NewPlantillaWhatsAppService(plantillaWARepo, limitesService))
+		RegisterPlantillaWhatsAppRoutes(r, plantillaWACtrl, authMw, tenantMw)
+
 		iaStatus := "sin IA (respuestas fijas)"
 		if aiProvider != nil {
 			iaStatus = aiProvider.Name()
 		}
 		fmt.Printf("[INFO] WhatsApp webhook activo en /webhook/whatsapp (multi-tenant + IA: %s)\n", iaStatus)
+		if transcriptor != nil {
+			fmt.Printf("[INFO] WhatsApp notas de voz transcritas con %s\n", transcriptor.Name())
+		}
 		fmt.Println("[INFO] WhatsApp config admin en /api/v1/canales/whatsapp")
+		if cfg.WhatsApp.AppSecret == "" {
+			fmt.Println("[WARN] WHATSAPP_APP_SECRET no configurado: solo se aceptan webhooks de canales con app_secret propio")
+		}
 	}
 
 	// --- Asistente IA interno (panel admin) ---
 	if aiProvider != nil {
 		assistantRepo := repo.NewAssistantRepo(db)
 		historialAsistenteRepo := repo.NewAsistenteHistorialRepo(db)
-		assistantService := service.NewAssistantService(aiProvider, assistantRepo, historialAsistenteRepo, tenantRepo)
+		assistantService := service.NewAssistantService(
+			aiProvider, assistantRepo, historialAsistenteRepo, tenantRepo, usoIAService,
+			reclamoService, respuestaService, historialRepo, sedeRepo, usuarioRepo,
+			repo.NewAsistenteAccionRepo(db),
+		)
 		assistantCtrl := controller.NewAssistantController(assistantService)
 		RegisterAssistantRoutes(r, assistantCtrl, authMw, tenantMw)
+
+		sugerenciaService := service.NewSugerenciaRespuestaService(aiProvider, reclamoRepo, mensajeRepo, respuestaRepo, tenantRepo, usoIAService)
+		RegisterSugerenciaRespuestaRoutes(r, controller.NewSugerenciaRespuestaController(sugerenciaService), authMw, tenantMw)
+
+		// Clasificación de reclamos nuevos: categoría, sentimiento, urgencia y resumen
+		clasificacionService := service.NewClasificacionService(aiProvider, reclamoRepo, categoriaReclamoRepo, usoIAService)
+		registrarJobClasificacion(sched, clasificacionService)
+		RegisterClasificacionRoutes(r, controller.NewClasificacionController(clasificacionService), authMw, tenantMw)
 		fmt.Printf("[INFO] Asistente IA activo (proveedor: %s)\n", aiProvider.Name())
 	} else {
 		fmt.Println("[INFO] Asistente IA desactivado (AI_PROVIDER no configurado)")
 	}
-}
\ No newline at end of file
+
+	return sched
+}
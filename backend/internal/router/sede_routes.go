@@ -2,18 +2,20 @@ package router
 
 import (
 	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterSedeRoutes(r *gin.Engine, ctrl *controller.SedeController, authMw, tenantMw gin.HandlerFunc) {
+func RegisterSedeRoutes(r *gin.Engine, ctrl *controller.SedeController, authMw, tenantMw gin.HandlerFunc, auditar middleware.Auditar) {
 	sedes := r.Group("/api/v1/sedes")
 	sedes.Use(authMw, tenantMw)
 	{
 		sedes.GET("", ctrl.GetAll)
 		sedes.GET("/:id", ctrl.GetByID)
-		sedes.POST("", ctrl.Create)
-		sedes.PUT("/:id", ctrl.Update)
-		sedes.DELETE("/:id", ctrl.Deactivate)
+		sedes.POST("", auditar(model.EntidadSede, model.AuditCrear, ""), ctrl.Create)
+		sedes.PUT("/:id", auditar(model.EntidadSede, model.AuditActualizar, "id"), ctrl.Update)
+		sedes.DELETE("/:id", auditar(model.EntidadSede, model.AuditDesactivar, "id"), ctrl.Deactivate)
 	}
 }
\ No newline at end of file
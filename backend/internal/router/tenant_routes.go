@@ -2,15 +2,17 @@ package router
 
 import (
 	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterTenantRoutes(r *gin.Engine, ctrl *controller.TenantController, authMw, tenantMw gin.HandlerFunc) {
+func RegisterTenantRoutes(r *gin.Engine, ctrl *controller.TenantController, authMw, tenantMw gin.HandlerFunc, auditar middleware.Auditar) {
 	tenant := r.Group("/api/v1/tenant")
 	tenant.Use(authMw, tenantMw)
 	{
 		tenant.GET("", ctrl.Get)
-		tenant.PUT("", ctrl.Update)
+		tenant.PUT("", auditar(model.EntidadConfig, model.AuditConfigurar, ""), ctrl.Update)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,17 @@
+package router
+
+import (
+	"libro-reclamaciones/internal/controller"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterUsoIARoutes reporte de consumo de IA del tenant (solo ADMIN).
+// GET /api/v1/uso-ia → Tokens por origen, proveedor y día + cuota del mes
+func RegisterUsoIARoutes(r *gin.Engine, ctrl *controller.UsoIAController, authMw, tenantMw, adminMw gin.HandlerFunc) {
+	usoIA := r.Group("/api/v1/uso-ia")
+	usoIA.Use(authMw, tenantMw, adminMw)
+	{
+		usoIA.GET("", ctrl.Reporte)
+	}
+}
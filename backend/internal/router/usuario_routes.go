@@ -8,22 +8,22 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterUsuarioRoutes(r *gin.Engine, ctrl *controller.UsuarioController, authMw, tenantMw gin.HandlerFunc) {
+func RegisterUsuarioRoutes(r *gin.Engine, ctrl *controller.UsuarioController, authMw, tenantMw gin.HandlerFunc, auditar middleware.Auditar) {
 	usuarios := r.Group("/api/v1/usuarios")
 	usuarios.Use(authMw, tenantMw)
 	{
 		usuarios.GET("", ctrl.GetAll)
 		usuarios.GET("/:id", ctrl.GetByID)
-		usuarios.PUT("/password", ctrl.ChangePassword)
+		usuarios.PUT("/password", auditar(model.EntidadUsuario, model.AuditCambiarPassword, ""), ctrl.ChangePassword)
 
 		// Solo ADMIN puede crear, editar y desactivar usuarios
 		admin := usuarios.Group("")
 		admin.Use(middleware.RoleMiddleware(model.RolAdmin))
 		{
-			admin.POST("", ctrl.Create)
-		admin.PUT("/:id", ctrl.Update)
-		admin.PATCH("/:id/password", ctrl.AdminResetPassword)
-		admin.DELETE("/:id", ctrl.Deactivate)
+			admin.POST("", auditar(model.EntidadUsuario, model.AuditCrear, ""), ctrl.Create)
+			admin.PUT("/:id", auditar(model.EntidadUsuario, model.AuditActualizar, "id"), ctrl.Update)
+			admin.PATCH("/:id/password", auditar(model.EntidadUsuario, model.AuditResetearPassword, "id"), ctrl.AdminResetPassword)
+			admin.DELETE("/:id", auditar(model.EntidadUsuario, model.AuditDesactivar, "id"), ctrl.Deactivate)
 		}
 	}
 }
\ No newline at end of file
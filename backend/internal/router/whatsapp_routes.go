@@ -12,4 +12,15 @@ func RegistrarRutasWebhookWhatsApp(r *gin.Engine, ctrl *controller.WhatsAppContr
 		webhook.GET("/whatsapp", ctrl.VerificarWebhook)
 		webhook.POST("/whatsapp", ctrl.RecibirMensajeEntrante)
 	}
-}
\ No newline at end of file
+}
+
+// RegisterWebhookWhatsAppAdminRoutes métricas del webhook de WhatsApp. Cuentan
+// los mensajes de todos los tenants: solo para los operadores de la plataforma.
+// GET /api/v1/admin/webhooks/whatsapp/metricas → Aceptados, rechazos por firma y duplicados
+func RegisterWebhookWhatsAppAdminRoutes(r *gin.Engine, ctrl *controller.WhatsAppController, authMw, tenantMw, operadorMw gin.HandlerFunc) {
+	webhooks := r.Group("/api/v1/admin/webhooks/whatsapp")
+	webhooks.Use(authMw, tenantMw, operadorMw)
+	{
+		webhooks.GET("/metricas", ctrl.Metricas)
+	}
+}
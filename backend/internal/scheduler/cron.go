@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Horario calcula la próxima ejecución de un job a partir de un instante.
+type Horario interface {
+	Siguiente(desde time.Time) time.Time
+}
+
+// ParseHorario interpreta una expresión de horario:
+//
+//	"*/15 * * * *"  → cron de 5 campos (minuto hora día-mes mes día-semana)
+//	"@every 30s"    → intervalo fijo (time.ParseDuration)
+//	"@hourly" | "@daily" | "@weekly" | "@monthly"
+//
+// Los campos cron aceptan *, números, rangos (1-5), listas (1,3,5) y pasos (*/10, 8-18/2).
+func ParseHorario(expr string, loc *time.Location) (Horario, error) {
+	expr = strings.TrimSpace(expr)
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	switch expr {
+	case "@hourly":
+		expr = "0 * * * *"
+	case "@daily", "@midnight":
+		expr = "0 0 * * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	}
+
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("intervalo inválido: %q", expr)
+		}
+		return intervalo(d), nil
+	}
+
+	campos := strings.Fields(expr)
+	if len(campos) != 5 {
+		return nil, fmt.Errorf("expresión cron inválida (se esperan 5 campos): %q", expr)
+	}
+
+	limites := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	var c cron
+	for i, campo := range campos {
+		set, err := parseCampo(campo, limites[i][0], limites[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("campo %d de %q: %w", i+1, expr, err)
+		}
+		switch i {
+		case 0:
+			c.minutos = set
+		case 1:
+			c.horas = set
+		case 2:
+			c.diasMes, c.diasMesLibre = set, campo == "*"
+		case 3:
+			c.meses = set
+		case 4:
+			c.diasSemana, c.diasSemanaLibre = set, campo == "*"
+		}
+	}
+	c.loc = loc
+	return &c, nil
+}
+
+// ── @every ──
+
+type intervalo time.Duration
+
+// Siguiente alinea al múltiplo del intervalo para que todas las réplicas
+// calculen el mismo slot (clave del lock distribuido).
+func (i intervalo) Siguiente(desde time.Time) time.Time {
+	return desde.Truncate(time.Duration(i)).Add(time.Duration(i))
+}
+
+// ── Cron de 5 campos ──
+
+type cron struct {
+	minutos, horas, diasMes, meses, diasSemana uint64 // bitsets
+	diasMesLibre, diasSemanaLibre              bool
+	loc                                        *time.Location
+}
+
+// maxBusqueda evita bucles infinitos con expresiones imposibles (ej: 31 de febrero).
+const maxBusqueda = 366 * 24 * 60
+
+func (c *cron) Siguiente(desde time.Time) time.Time {
+	t := desde.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	for i := 0; i < maxBusqueda; i++ {
+		if c.coincide(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return time.Time{}
+}
+
+func (c *cron) coincide(t time.Time) bool {
+	if !tiene(c.minutos, t.Minute()) || !tiene(c.horas, t.Hour()) || !tiene(c.meses, int(t.Month())) {
+		return false
+	}
+	dm := tiene(c.diasMes, t.Day())
+	ds := tiene(c.diasSemana, int(t.Weekday()))
+	// Semántica clásica de cron: si ambos días están restringidos, basta con uno.
+	switch {
+	case c.diasMesLibre && c.diasSemanaLibre:
+		return true
+	case c.diasMesLibre:
+		return ds
+	case c.diasSemanaLibre:
+		return dm
+	default:
+		return dm || ds
+	}
+}
+
+func tiene(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+func parseCampo(campo string, min, max int) (uint64, error) {
+	var set uint64
+	for _, parte := range strings.Split(campo, ",") {
+		paso := 1
+		if i := strings.Index(parte, "/"); i >= 0 {
+			p, err := strconv.Atoi(parte[i+1:])
+			if err != nil || p <= 0 {
+				return 0, fmt.Errorf("paso inválido %q", parte)
+			}
+			paso = p
+			parte = parte[:i]
+		}
+
+		desde, hasta := min, max
+		switch {
+		case parte == "*":
+		case strings.Contains(parte, "-"):
+			r := strings.SplitN(parte, "-", 2)
+			a, errA := strconv.Atoi(r[0])
+			b, errB := strconv.Atoi(r[1])
+			if errA != nil || errB != nil || a > b {
+				return 0, fmt.Errorf("rango inválido %q", parte)
+			}
+			desde, hasta = a, b
+		default:
+			v, err := strconv.Atoi(parte)
+			if err != nil {
+				return 0, fmt.Errorf("valor inválido %q", parte)
+			}
+			desde, hasta = v, v
+			if paso > 1 {
+				hasta = max
+			}
+		}
+
+		if desde < min || hasta > max {
+			return 0, fmt.Errorf("fuera de rango %d-%d", min, max)
+		}
+		for v := desde; v <= hasta; v += paso {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
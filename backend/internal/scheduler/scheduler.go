@@ -0,0 +1,291 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// Tarea trabajo a ejecutar. Retorna un resumen legible para el historial.
+type Tarea func(ctx context.Context) (string, error)
+
+// Errores del scheduler.
+var (
+	ErrJobNoExiste  = errors.New("job no registrado")
+	ErrJobEnCurso   = errors.New("job en ejecución en otra réplica")
+	ErrJobDuplicado = errors.New("job ya registrado")
+)
+
+// timeoutPorDefecto duración máxima de una corrida si el job no define otra.
+const timeoutPorDefecto = 10 * time.Minute
+
+// JobInfo vista de un job registrado (para el panel admin).
+type JobInfo struct {
+	Nombre           string              `json:"nombre"`
+	Descripcion      string              `json:"descripcion"`
+	Horario          string              `json:"horario"`
+	ProximaEjecucion time.Time           `json:"proxima_ejecucion"`
+	UltimaEjecucion  *model.JobEjecucion `json:"ultima_ejecucion"`
+}
+
+type job struct {
+	nombre      string
+	descripcion string
+	expresion   string
+	horario     Horario
+	timeout     time.Duration
+	tarea       Tarea
+	proxima     time.Time
+}
+
+// Scheduler ejecuta jobs periódicos dentro del proceso.
+// Cada corrida toma un lock en CockroachDB, así con N réplicas solo una la ejecuta.
+type Scheduler struct {
+	jobRepo   *repo.JobRepo
+	loc       *time.Location
+	instancia string
+
+	mu   sync.Mutex
+	jobs map[string]*job
+
+	ctx    context.Context // contexto base; se cancela en Stop
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New crea un scheduler. loc define la zona de las expresiones cron.
+func New(jobRepo *repo.JobRepo, loc *time.Location) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		jobRepo:   jobRepo,
+		loc:       loc,
+		instancia: fmt.Sprintf("%s:%d:%s", host, os.Getpid(), uuid.NewString()[:8]),
+		jobs:      make(map[string]*job),
+		ctx:       context.Background(),
+	}
+}
+
+// Registrar agrega un job. timeout = 0 usa el valor por defecto.
+func (s *Scheduler) Registrar(nombre, expresion, descripcion string, timeout time.Duration, tarea Tarea) error {
+	h, err := ParseHorario(expresion, s.loc)
+	if err != nil {
+		return fmt.Errorf("scheduler.Registrar %s: %w", nombre, err)
+	}
+	if timeout <= 0 {
+		timeout = timeoutPorDefecto
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[nombre]; ok {
+		return fmt.Errorf("scheduler.Registrar %s: %w", nombre, ErrJobDuplicado)
+	}
+	s.jobs[nombre] = &job{
+		nombre:      nombre,
+		descripcion: descripcion,
+		expresion:   expresion,
+		horario:     h,
+		timeout:     timeout,
+		tarea:       tarea,
+		proxima:     h.Siguiente(time.Now()),
+	}
+	return nil
+}
+
+// Start inicia el loop en segundo plano. Detener con Stop.
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.ctx = ctx
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ahora := <-ticker.C:
+				s.despachar(ctx, ahora)
+			}
+		}
+	}()
+	fmt.Printf("[INFO] Scheduler iniciado (%d jobs, instancia %s)\n", len(s.jobs), s.instancia)
+}
+
+// Stop cancela los jobs en curso y espera a que terminen.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+// Jobs lista los jobs registrados con su última ejecución.
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobInfo, error) {
+	s.mu.Lock()
+	jobs := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, JobInfo{
+			Nombre:           j.nombre,
+			Descripcion:      j.descripcion,
+			Horario:          j.expresion,
+			ProximaEjecucion: j.proxima,
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].Nombre < jobs[b].Nombre })
+	for i := range jobs {
+		ultima, err := s.jobRepo.UltimaEjecucion(ctx, jobs[i].Nombre)
+		if err != nil {
+			return nil, err
+		}
+		jobs[i].UltimaEjecucion = ultima
+	}
+	return jobs, nil
+}
+
+// Existe indica si hay un job registrado con ese nombre.
+func (s *Scheduler) Existe(nombre string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[nombre]
+	return ok
+}
+
+// EjecutarAhora dispara un job manualmente. Toma el lock de forma síncrona
+// (ErrJobEnCurso si otra réplica lo tiene) y corre la tarea en segundo plano.
+func (s *Scheduler) EjecutarAhora(nombre string) (*model.JobEjecucion, error) {
+	s.mu.Lock()
+	j, ok := s.jobs[nombre]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrJobNoExiste
+	}
+
+	ctx := s.ctx
+	ejec, err := s.iniciar(ctx, j, time.Now(), model.JobOrigenManual)
+	if err != nil {
+		return nil, err
+	}
+	if ejec == nil {
+		return nil, ErrJobEnCurso
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.correr(ctx, j, ejec)
+	}()
+	return ejec, nil
+}
+
+// ── Internos ──
+
+// despachar lanza los jobs cuyo horario ya llegó.
+func (s *Scheduler) despachar(ctx context.Context, ahora time.Time) {
+	s.mu.Lock()
+	var vencidos []struct {
+		j    *job
+		slot time.Time
+	}
+	for _, j := range s.jobs {
+		if j.proxima.IsZero() || ahora.Before(j.proxima) {
+			continue
+		}
+		vencidos = append(vencidos, struct {
+			j    *job
+			slot time.Time
+		}{j, j.proxima})
+		j.proxima = j.horario.Siguiente(ahora)
+	}
+	s.mu.Unlock()
+
+	for _, v := range vencidos {
+		j, slot := v.j, v.slot
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			ejec, err := s.iniciar(ctx, j, slot, model.JobOrigenProgramado)
+			if err != nil {
+				fmt.Printf("[ERROR] Scheduler %s: %v\n", j.nombre, err)
+				return
+			}
+			if ejec == nil {
+				return // otra réplica lo tomó
+			}
+			s.correr(ctx, j, ejec)
+		}()
+	}
+}
+
+// iniciar toma el lock y registra la corrida. nil si otra réplica la tiene.
+func (s *Scheduler) iniciar(ctx context.Context, j *job, slot time.Time, origen string) (*model.JobEjecucion, error) {
+	ok, err := s.jobRepo.IntentarBloqueo(ctx, j.nombre, s.instancia, slot, j.timeout+time.Minute)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	ejec := &model.JobEjecucion{
+		Nombre:    j.nombre,
+		Instancia: s.instancia,
+		Origen:    origen,
+		Estado:    model.JobEnCurso,
+	}
+	if err := s.jobRepo.CrearEjecucion(ctx, ejec); err != nil {
+		_ = s.jobRepo.LiberarBloqueo(context.Background(), j.nombre, s.instancia)
+		return nil, err
+	}
+	return ejec, nil
+}
+
+// correr ejecuta la tarea con timeout, guarda el resultado y libera el lock.
+func (s *Scheduler) correr(ctx context.Context, j *job, ejec *model.JobEjecucion) {
+	runCtx, cancel := context.WithTimeout(ctx, j.timeout)
+	defer cancel()
+
+	resultado, err := s.ejecutarSeguro(runCtx, j)
+
+	fin := time.Now()
+	ejec.FechaFin = model.NullTime{NullTime: sql.NullTime{Time: fin, Valid: true}}
+	ejec.DuracionMs = model.NullInt64{NullInt64: sql.NullInt64{Int64: fin.Sub(ejec.FechaInicio).Milliseconds(), Valid: true}}
+	ejec.Resultado = model.NullString{NullString: sql.NullString{String: resultado, Valid: resultado != ""}}
+	ejec.Estado = model.JobExitoso
+	if err != nil {
+		ejec.Estado = model.JobFallido
+		ejec.Error = model.NullString{NullString: sql.NullString{String: err.Error(), Valid: true}}
+		fmt.Printf("[ERROR] Job %s falló: %v\n", j.nombre, err)
+	}
+
+	// Contexto nuevo: el del job pudo vencer o cancelarse en el shutdown.
+	bgCtx, bgCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer bgCancel()
+	if errF := s.jobRepo.FinalizarEjecucion(bgCtx, ejec); errF != nil {
+		fmt.Printf("[ERROR] Job %s historial: %v\n", j.nombre, errF)
+	}
+	if errL := s.jobRepo.LiberarBloqueo(bgCtx, j.nombre, s.instancia); errL != nil {
+		fmt.Printf("[ERROR] Job %s lock: %v\n", j.nombre, errL)
+	}
+}
+
+func (s *Scheduler) ejecutarSeguro(ctx context.Context, j *job) (resultado string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.tarea(ctx)
+}
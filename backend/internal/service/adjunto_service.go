@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// mimesPermitidos tipos aceptados, según el contenido real del archivo.
+var mimesPermitidos = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       true,
+}
+
+// ArchivoSubido archivo ya leído y validado, listo para almacenar.
+type ArchivoSubido struct {
+	Nombre string
+	MIME   string
+	Datos  []byte
+}
+
+// AdjuntoService valida, almacena y sirve adjuntos de reclamos, mensajes y respuestas.
+type AdjuntoService struct {
+	adjuntoRepo   *repo.AdjuntoRepo
+	dashboardRepo *repo.DashboardRepo
+	storage       storage.Storage
+	maxBytes      int64
+	maxArchivos   int
+}
+
+func NewAdjuntoService(adjuntoRepo *repo.AdjuntoRepo, dashboardRepo *repo.DashboardRepo, st storage.Storage, cfg config.StorageConfig) *AdjuntoService {
+	return &AdjuntoService{
+		adjuntoRepo:   adjuntoRepo,
+		dashboardRepo: dashboardRepo,
+		storage:       st,
+		maxBytes:      int64(cfg.MaxArchivoMB) << 20,
+		maxArchivos:   cfg.MaxArchivos,
+	}
+}
+
+// LimiteRequest tamaño máximo del body multipart (archivos + campos del formulario).
+func (s *AdjuntoService) LimiteRequest() int64 {
+	return int64(s.maxArchivos)*s.maxBytes + 1<<20
+}
+
+// Preparar lee los archivos del formulario, valida cantidad, tamaño y tipo.
+// El tipo se detecta del contenido: el Content-Type del cliente se ignora.
+func (s *AdjuntoService) Preparar(archivos []*multipart.FileHeader, maxArchivos int) ([]ArchivoSubido, error) {
+	if maxArchivos <= 0 || maxArchivos > s.maxArchivos {
+		maxArchivos = s.maxArchivos
+	}
+	if len(archivos) > maxArchivos {
+		return nil, apperror.ErrDemasiadosArchivos.Withf(maxArchivos)
+	}
+
+	maxMB := int(s.maxBytes >> 20)
+	subidos := make([]ArchivoSubido, 0, len(archivos))
+	for _, fh := range archivos {
+		nombre := limpiarNombreArchivo(fh.Filename)
+		if fh.Size > s.maxBytes {
+			return nil, apperror.ErrArchivoMuyGrande.Withf(nombre, maxMB)
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			return nil, apperror.ErrBadRequest
+		}
+		datos, err := io.ReadAll(io.LimitReader(f, s.maxBytes+1))
+		f.Close()
+		if err != nil {
+			return nil, apperror.ErrBadRequest
+		}
+		subido, err := s.PrepararDatos(nombre, datos)
+		if err != nil {
+			return nil, err
+		}
+		subidos = append(subidos, subido)
+	}
+	return subidos, nil
+}
+
+// PrepararDatos valida tamaño y tipo de un archivo obtenido por otra vía (p. ej.
+// descargado de WhatsApp), con las mismas reglas que Preparar.
+func (s *AdjuntoService) PrepararDatos(nombre string, datos []byte) (ArchivoSubido, error) {
+	nombre = limpiarNombreArchivo(nombre)
+	if int64(len(datos)) > s.maxBytes {
+		return ArchivoSubido{}, apperror.ErrArchivoMuyGrande.Withf(nombre, int(s.maxBytes>>20))
+	}
+
+	mime, ok := detectarMIME(nombre, datos)
+	if !ok {
+		return ArchivoSubido{}, apperror.ErrArchivoTipoNoPermitido.Withf(nombre)
+	}
+	return ArchivoSubido{Nombre: nombre, MIME: mime, Datos: datos}, nil
+}
+
+// MaxBytes tamaño máximo de cada archivo.
+func (s *AdjuntoService) MaxBytes() int64 {
+	return s.maxBytes
+}
+
+// MaxArchivos cantidad máxima de archivos por envío.
+func (s *AdjuntoService) MaxArchivos() int {
+	return s.maxArchivos
+}
+
+// VerificarCuota valida que los archivos entren en el límite de storage del plan.
+func (s *AdjuntoService) VerificarCuota(ctx context.Context, tenantID uuid.UUID, archivos []ArchivoSubido) error {
+	if len(archivos) == 0 {
+		return nil
+	}
+
+	uso, err := s.dashboardRepo.GetUsoTenant(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("adjunto_service.VerificarCuota uso: %w", err)
+	}
+	if uso == nil {
+		return apperror.ErrSuscripcionInactiva
+	}
+	if model.EsIlimitado(uso.LimiteStorageMB) {
+		return nil
+	}
+
+	usados, err := s.adjuntoRepo.UsoBytes(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("adjunto_service.VerificarCuota: %w", err)
+	}
+	for _, a := range archivos {
+		usados += int64(len(a.Datos))
+	}
+	if usados > int64(uso.LimiteStorageMB)<<20 {
+		return apperror.ErrPlanLimitStorage.Withf(uso.LimiteStorageMB)
+	}
+	return nil
+}
+
+// Almacenar sube los archivos al storage y retorna sus metadatos sin persistir.
+// Si alguno falla, borra los ya subidos.
+func (s *AdjuntoService) Almacenar(ctx context.Context, tenantID, reclamoID uuid.UUID, entidad, origen string, subidoPor uuid.UUID, archivos []ArchivoSubido) ([]model.Adjunto, error) {
+	adjuntos := make([]model.Adjunto, 0, len(archivos))
+	for _, a := range archivos {
+		id := uuid.New()
+		hash := sha256.Sum256(a.Datos)
+		adj := model.Adjunto{
+			TenantModel:    model.TenantModel{TenantID: tenantID, ID: id},
+			ReclamoID:      reclamoID,
+			Entidad:        entidad,
+			NombreOriginal: a.Nombre,
+			Clave:          fmt.Sprintf("%s/%s/%s", tenantID, reclamoID, id),
+			MimeType:       a.MIME,
+			TamanoBytes:    int64(len(a.Datos)),
+			SHA256:         hex.EncodeToString(hash[:]),
+			Origen:         origen,
+			SubidoPor:      model.NullUUID{UUID: subidoPor, Valid: subidoPor != uuid.Nil},
+		}
+		if err := s.storage.Guardar(ctx, adj.Clave, a.Datos, a.MIME); err != nil {
+			s.Descartar(adjuntos)
+			return nil, fmt.Errorf("adjunto_service.Almacenar: %w", err)
+		}
+		adjuntos = append(adjuntos, adj)
+	}
+	return adjuntos, nil
+}
+
+// RegistrarTx persiste los metadatos dentro de la transacción del llamador.
+func (s *AdjuntoService) RegistrarTx(ctx context.Context, tx *sql.Tx, adjuntos []model.Adjunto, entidadID uuid.UUID) error {
+	r := s.adjuntoRepo.WithTx(tx)
+	for i := range adjuntos {
+		adjuntos[i].EntidadID = model.NullUUID{UUID: entidadID, Valid: entidadID != uuid.Nil}
+		if err := r.Create(ctx, &adjuntos[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Adjuntar almacena y registra archivos fuera de una transacción
+// (ej: adjuntos del reclamo, que se suben después de crearlo).
+func (s *AdjuntoService) Adjuntar(ctx context.Context, tenantID, reclamoID uuid.UUID, entidad, origen string, archivos []ArchivoSubido) ([]model.Adjunto, error) {
+	adjuntos, err := s.Almacenar(ctx, tenantID, reclamoID, entidad, origen, uuid.Nil, archivos)
+	if err != nil {
+		return nil, err
+	}
+	for i := range adjuntos {
+		if err := s.adjuntoRepo.Create(ctx, &adjuntos[i]); err != nil {
+			s.Descartar(adjuntos[i:])
+			return adjuntos[:i], err
+		}
+	}
+	return adjuntos, nil
+}
+
+// Descartar borra del storage archivos cuyo registro no llegó a persistir.
+func (s *AdjuntoService) Descartar(adjuntos []model.Adjunto) {
+	for _, a := range adjuntos {
+		if err := s.storage.Eliminar(context.Background(), a.Clave); err != nil {
+			fmt.Printf("[WARN] No se pudo borrar adjunto huérfano %s: %v\n", a.Clave, err)
+		}
+	}
+}
+
+func (s *AdjuntoService) ListarPorReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.Adjunto, error) {
+	return s.adjuntoRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+}
+
+func (s *AdjuntoService) Obtener(ctx context.Context, tenantID, id uuid.UUID) (*model.Adjunto, error) {
+	a, err := s.adjuntoRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("adjunto_service.Obtener: %w", err)
+	}
+	if a == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return a, nil
+}
+
+// Abrir retorna el contenido del adjunto. El llamador debe cerrarlo.
+func (s *AdjuntoService) Abrir(ctx context.Context, a *model.Adjunto) (io.ReadCloser, error) {
+	rc, err := s.storage.Abrir(ctx, a.Clave)
+	if err == storage.ErrNoExiste {
+		return nil, apperror.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("adjunto_service.Abrir: %w", err)
+	}
+	return rc, nil
+}
+
+// URLPublicaAdjunto ruta de descarga accesible para el consumidor.
+func URLPublicaAdjunto(slug, codigo string, id uuid.UUID) string {
+	return fmt.Sprintf("/libro/%s/seguimiento/%s/adjuntos/%s", slug, codigo, id)
+}
+
+// resumenAdjuntos JSON guardado en respuestas.archivos_adjuntos.
+func resumenAdjuntos(adjuntos []model.Adjunto) json.RawMessage {
+	if len(adjuntos) == 0 {
+		return nil
+	}
+	type resumen struct {
+		ID          uuid.UUID `json:"id"`
+		Nombre      string    `json:"nombre"`
+		MimeType    string    `json:"mime_type"`
+		TamanoBytes int64     `json:"tamano_bytes"`
+	}
+	items := make([]resumen, len(adjuntos))
+	for i, a := range adjuntos {
+		items[i] = resumen{ID: a.ID, Nombre: a.NombreOriginal, MimeType: a.MimeType, TamanoBytes: a.TamanoBytes}
+	}
+	b, _ := json.Marshal(items)
+	return b
+}
+
+// detectarMIME sniffing del contenido. Los .docx/.xlsx se detectan como ZIP:
+// en ese caso se usa la extensión para distinguirlos.
+func detectarMIME(nombre string, datos []byte) (string, bool) {
+	mime := http.DetectContentType(datos)
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+	if mime == "application/zip" {
+		switch strings.ToLower(filepath.Ext(nombre)) {
+		case ".docx":
+			mime = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+		case ".xlsx":
+			mime = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		}
+	}
+	return mime, mimesPermitidos[mime]
+}
+
+// limpiarNombreArchivo quita rutas y caracteres de control del nombre original.
+func limpiarNombreArchivo(nombre string) string {
+	nombre = filepath.Base(strings.ReplaceAll(nombre, "\\", "/"))
+	nombre = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, nombre)
+	if nombre == "" || nombre == "." || nombre == "/" {
+		nombre = "archivo"
+	}
+	if r := []rune(nombre); len(r) > 200 {
+		nombre = string(r[:200])
+	}
+	return nombre
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// AlertaSLAService avisa proactivamente de reclamos que entran en ventana URGENTE o vencen.
+// Lo ejecuta el scheduler (job alertas_sla).
+type AlertaSLAService struct {
+	alertaRepo    *repo.AlertaSLARepo
+	tenantRepo    *repo.TenantRepo
+	sedeRepo      *repo.SedeRepo
+	usuarioRepo   *repo.UsuarioRepo
+	calendarioSvc *CalendarioService
+	notifService  *NotificacionService
+}
+
+func NewAlertaSLAService(
+	alertaRepo *repo.AlertaSLARepo,
+	tenantRepo *repo.TenantRepo,
+	sedeRepo *repo.SedeRepo,
+	usuarioRepo *repo.UsuarioRepo,
+	calendarioSvc *CalendarioService,
+	notifService *NotificacionService,
+) *AlertaSLAService {
+	return &AlertaSLAService{
+		alertaRepo:    alertaRepo,
+		tenantRepo:    tenantRepo,
+		sedeRepo:      sedeRepo,
+		usuarioRepo:   usuarioRepo,
+		calendarioSvc: calendarioSvc,
+		notifService:  notifService,
+	}
+}
+
+// ObtenerConfig retorna la configuración del tenant o la por defecto.
+func (s *AlertaSLAService) ObtenerConfig(ctx context.Context, tenantID uuid.UUID) (*model.ConfigAlertasSLA, error) {
+	cfg, err := s.alertaRepo.ObtenerConfig(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("alerta_sla_service.ObtenerConfig: %w", err)
+	}
+	if cfg == nil {
+		return model.ConfigAlertasSLAPorDefecto(tenantID), nil
+	}
+	return cfg, nil
+}
+
+func (s *AlertaSLAService) ActualizarConfig(ctx context.Context, cfg *model.ConfigAlertasSLA) error {
+	if cfg.DiasAviso < 0 || cfg.DiasAviso > 30 {
+		return apperror.ErrBadRequest
+	}
+	if err := s.alertaRepo.GuardarConfig(ctx, cfg); err != nil {
+		return fmt.Errorf("alerta_sla_service.ActualizarConfig: %w", err)
+	}
+	return nil
+}
+
+// ProcesarAlertas evalúa todos los tenants y envía las alertas pendientes.
+// Retorna cuántos reclamos fueron notificados.
+func (s *AlertaSLAService) ProcesarAlertas(ctx context.Context) (int, error) {
+	tenants, err := s.alertaRepo.TenantsConReclamosAbiertos(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("alerta_sla_service.ProcesarAlertas: %w", err)
+	}
+
+	total := 0
+	for _, tenantID := range tenants {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		n, err := s.procesarTenant(ctx, tenantID)
+		if err != nil {
+			// Un tenant con error no bloquea al resto
+			fmt.Printf("[ERROR] Alertas SLA tenant %s: %v\n", tenantID, err)
+			continue
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (s *AlertaSLAService) procesarTenant(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	cfg, err := s.ObtenerConfig(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if !cfg.Activo {
+		return 0, nil
+	}
+
+	tenant, err := s.tenantRepo.GetByTenantID(ctx, tenantID)
+	if err != nil || tenant == nil {
+		return 0, err
+	}
+
+	cal, err := s.calendarioSvc.ObtenerCalendario(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	reclamos, err := s.alertaRepo.ReclamosAbiertos(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	enviadas, err := s.alertaRepo.Enviadas(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 1. Detectar reclamos en ventana URGENTE o VENCIDOS no avisados aún
+	ahora := time.Now()
+	porDestinatario := make(map[string][]model.AlertaSLA)
+	var orden []string
+	var pendientes []model.AlertaSLA
+
+	agregar := func(email string, a model.AlertaSLA) {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if email == "" {
+			return
+		}
+		for _, prev := range porDestinatario[email] {
+			if prev.ReclamoID == a.ReclamoID {
+				return // misma persona por dos vías (asignado y empresa)
+			}
+		}
+		if _, ok := porDestinatario[email]; !ok {
+			orden = append(orden, email)
+		}
+		porDestinatario[email] = append(porDestinatario[email], a)
+	}
+
+	emailsUsuario := make(map[uuid.UUID]string)
+	emailsSede := make(map[uuid.UUID]string)
+
+	for _, r := range reclamos {
+		dias := cal.DiasRestantes(r.FechaLimite, ahora)
+		tipo := ""
+		switch {
+		case dias < 0:
+			tipo = model.AlertaSLAVencido
+		case dias <= cfg.DiasAviso:
+			tipo = model.AlertaSLAUrgente
+		default:
+			continue
+		}
+		if enviadas[repo.ClaveAlerta(r.ID, tipo, r.FechaLimite)] {
+			continue
+		}
+
+		a := model.AlertaSLA{
+			ReclamoID:     r.ID,
+			Codigo:        r.Codigo,
+			Consumidor:    r.Consumidor,
+			TipoSolicitud: r.TipoSolicitud,
+			SedeNombre:    r.SedeNombre.String,
+			Tipo:          tipo,
+			FechaLimite:   r.FechaLimite,
+			DiasRestantes: dias,
+		}
+		pendientes = append(pendientes, a)
+
+		// 2. Resolver destinatarios
+		if cfg.NotificarAsignado && r.AtendidoPor.Valid {
+			email, ok := emailsUsuario[r.AtendidoPor.UUID]
+			if !ok {
+				if u, _ := s.usuarioRepo.GetByID(ctx, tenantID, r.AtendidoPor.UUID); u != nil && u.Activo {
+					email = u.Email
+				}
+				emailsUsuario[r.AtendidoPor.UUID] = email
+			}
+			agregar(email, a)
+		}
+		if cfg.NotificarSede && r.SedeID.Valid {
+			email, ok := emailsSede[r.SedeID.UUID]
+			if !ok {
+				if sede, _ := s.sedeRepo.GetByID(ctx, tenantID, r.SedeID.UUID); sede != nil {
+					email = sede.Email.String
+				}
+				emailsSede[r.SedeID.UUID] = email
+			}
+			agregar(email, a)
+		}
+		if cfg.NotificarEmpresa && tenant.EmailContacto.Valid {
+			agregar(tenant.EmailContacto.String, a)
+		}
+	}
+
+	if len(pendientes) == 0 {
+		return 0, nil
+	}
+
+	// 3. Enviar: un resumen por destinatario o un email por reclamo.
+	// entregadas cuenta por reclamo los destinatarios a los que sí llegó.
+	entregadas := make(map[uuid.UUID]int)
+	enviar := func(email string, alertas []model.AlertaSLA) {
+		if err := s.notifService.EnviarAlertaSLA(ctx, email, tenant, alertas); err != nil {
+			fmt.Printf("[ERROR SMTP AlertaSLA] %s: %v\n", email, err)
+			return
+		}
+		for _, a := range alertas {
+			entregadas[a.ReclamoID]++
+		}
+	}
+	for _, email := range orden {
+		alertas := porDestinatario[email]
+		if cfg.ModoResumen {
+			enviar(email, alertas)
+			continue
+		}
+		for _, a := range alertas {
+			enviar(email, []model.AlertaSLA{a})
+		}
+	}
+
+	// 4. Registrar para no repetir el aviso. Una alerta que no llegó a nadie
+	// queda sin registrar y se reintenta en la próxima ejecución.
+	notificados := 0
+	for _, a := range pendientes {
+		if entregadas[a.ReclamoID] == 0 {
+			continue
+		}
+		if err := s.alertaRepo.RegistrarEnvio(ctx, tenantID, a, entregadas[a.ReclamoID]); err != nil {
+			return notificados, err
+		}
+		notificados++
+	}
+	return notificados, nil
+}
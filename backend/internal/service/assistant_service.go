@@ -2,12 +2,13 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"strings"
 	"time"
 
 	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
 	"libro-reclamaciones/internal/repo"
 
 	"github.com/google/uuid"
@@ -22,6 +23,15 @@ type AssistantService struct {
 	assistantRepo *repo.AssistantRepo
 	historialRepo *repo.AsistenteHistorialRepo
 	tenantRepo    *repo.TenantRepo
+	usoIA         *UsoIAService
+
+	// ── Herramientas: consultas y acciones sobre reclamos ──
+	reclamoService       *ReclamoService
+	respuestaService     *RespuestaService
+	historialReclamoRepo *repo.HistorialRepo
+	sedeRepo             *repo.SedeRepo
+	usuarioRepo          *repo.UsuarioRepo
+	accionRepo           *repo.AsistenteAccionRepo
 }
 
 func NewAssistantService(
@@ -29,15 +39,33 @@ func NewAssistantService(
 	assistantRepo *repo.AssistantRepo,
 	historialRepo *repo.AsistenteHistorialRepo,
 	tenantRepo *repo.TenantRepo,
+	usoIA *UsoIAService,
+	reclamoService *ReclamoService,
+	respuestaService *RespuestaService,
+	historialReclamoRepo *repo.HistorialRepo,
+	sedeRepo *repo.SedeRepo,
+	usuarioRepo *repo.UsuarioRepo,
+	accionRepo *repo.AsistenteAccionRepo,
 ) *AssistantService {
 	return &AssistantService{
-		aiProvider:    aiProvider,
-		assistantRepo: assistantRepo,
-		historialRepo: historialRepo,
-		tenantRepo:    tenantRepo,
+		aiProvider:           aiProvider,
+		assistantRepo:        assistantRepo,
+		historialRepo:        historialRepo,
+		tenantRepo:           tenantRepo,
+		usoIA:                usoIA,
+		reclamoService:       reclamoService,
+		respuestaService:     respuestaService,
+		historialReclamoRepo: historialReclamoRepo,
+		sedeRepo:             sedeRepo,
+		usuarioRepo:          usuarioRepo,
+		accionRepo:           accionRepo,
 	}
 }
 
+// maxRondasHerramientas llamadas al modelo por turno; en la última no se
+// ofrecen herramientas para forzar una respuesta de texto.
+const maxRondasHerramientas = 5
+
 // ChatMessage representa un mensaje en la conversación del asistente.
 type ChatMessage struct {
 	Role    string `json:"role"`
@@ -51,13 +79,43 @@ type ChatResult struct {
 	OutputTokens   int    `json:"output_tokens"`
 	Provider       string `json:"provider"`
 	ConversacionID string `json:"conversacion_id"`
+
+	// Acciones propuestas en este turno, pendientes de confirmación
+	AccionesPendientes []model.AsistenteAccion `json:"acciones_pendientes"`
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
 // Chat
 // ──────────────────────────────────────────────────────────────────────────────
 
-func (s *AssistantService) Chat(ctx context.Context, tenantID, usuarioID uuid.UUID, conversacionID uuid.UUID, userMessage string) (*ChatResult, error) {
+func (s *AssistantService) Chat(ctx context.Context, alcance AlcanceAsistente, conversacionID uuid.UUID, userMessage string) (*ChatResult, error) {
+	turno, err := s.IniciarTurno(ctx, alcance, conversacionID, userMessage)
+	if err != nil {
+		return nil, err
+	}
+	return s.responder(ctx, turno, nil)
+}
+
+// TurnoChat mensaje del usuario ya guardado y request listo para la IA.
+// Separa la preparación de la generación para que el endpoint de streaming
+// pueda responder errores de validación antes de abrir el stream.
+type TurnoChat struct {
+	TenantID       uuid.UUID
+	ConversacionID uuid.UUID
+	alcance        AlcanceAsistente
+	request        ai.ChatRequest
+}
+
+// IniciarTurno crea o valida la conversación, guarda el mensaje del usuario
+// y arma el request con el historial, el contexto del tenant y las herramientas.
+func (s *AssistantService) IniciarTurno(ctx context.Context, alcance AlcanceAsistente, conversacionID uuid.UUID, userMessage string) (*TurnoChat, error) {
+	tenantID, usuarioID := alcance.TenantID, alcance.UsuarioID
+
+	// 0. Cuota mensual de tokens (antes de guardar nada)
+	if err := s.usoIA.ValidarCuota(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
 	// 1. Si no hay conversación, crear una nueva
 	if conversacionID == uuid.Nil {
 		titulo := userMessage
@@ -107,47 +165,207 @@ func (s *AssistantService) Chat(ctx context.Context, tenantID, usuarioID uuid.UU
 		messages = append(messages, ai.Message{Role: role, Content: m.Contenido})
 	}
 
-	// 5. Construir contexto del tenant
-	tenantContext, err := s.buildTenantContext(ctx, tenantID)
+	// 5. Construir contexto del tenant (los datos de reclamos se piden con herramientas)
+	tenantContext, err := s.buildTenantContext(ctx, alcance)
 	if err != nil {
 		fmt.Printf("[WARN] buildTenantContext falló: %v\n", err)
 		tenantContext = "[No se pudo cargar contexto del tenant]"
 	}
 
-	// DEBUG: imprimir el contexto completo para verificar que tiene los datos
-	fmt.Printf("[DEBUG] Contexto del tenant (%d chars):\n%s\n", len(tenantContext), tenantContext)
+	return &TurnoChat{
+		TenantID:       tenantID,
+		ConversacionID: conversacionID,
+		alcance:        alcance,
+		request: ai.ChatRequest{
+			SystemPrompt: s.buildSystemPrompt(tenantContext),
+			Messages:     messages,
+			MaxTokens:    4096,
+			Tools:        herramientasAsistente,
+		},
+	}, nil
+}
+
+// ChatStream genera la respuesta del turno en streaming, entregando cada
+// fragmento a onDelta. Al terminar persiste el mensaje con sus tokens; si el
+// stream se corta (error del proveedor o cliente desconectado) persiste lo
+// generado hasta ese punto para que el historial quede consistente.
+func (s *AssistantService) ChatStream(ctx context.Context, turno *TurnoChat, onDelta func(texto string) error) (*ChatResult, error) {
+	if onDelta == nil {
+		onDelta = func(string) error { return nil }
+	}
+	return s.responder(ctx, turno, onDelta)
+}
+
+// responder ejecuta las rondas de herramientas del turno: mientras el modelo
+// pida herramientas se ejecutan y sus resultados vuelven como mensajes "tool".
+// Con onDelta != nil cada ronda se genera en streaming. El texto de todas las
+// rondas forma la respuesta que se persiste.
+func (s *AssistantService) responder(ctx context.Context, turno *TurnoChat, onDelta func(texto string) error) (*ChatResult, error) {
+	req := turno.request
+	req.Messages = append([]ai.Message(nil), turno.request.Messages...)
+
+	var (
+		texto    strings.Builder
+		total    ai.ChatResponse
+		acciones = make([]model.AsistenteAccion, 0)
+		errRonda error
+		inicioIA = time.Now()
+	)
 
-	systemPrompt := s.buildSystemPrompt(tenantContext)
+	for ronda := 1; ; ronda++ {
+		if ronda == maxRondasHerramientas {
+			req.Tools = nil
+		}
 
-	// 6. Llamar al proveedor de IA
-	inicio_ia := time.Now()
-	resp, err := s.aiProvider.Chat(ctx, ai.ChatRequest{
-		SystemPrompt: systemPrompt,
-		Messages:     messages,
-		MaxTokens:    4096,
-	})
-	duracionMs := int(time.Since(inicio_ia).Milliseconds())
+		var resp *ai.ChatResponse
+		if onDelta != nil {
+			resp, errRonda = s.aiProvider.ChatStream(ctx, req, onDelta)
+		} else {
+			resp, errRonda = s.aiProvider.Chat(ctx, req)
+		}
+		s.usoIA.Registrar(ctx, turno.TenantID, model.OrigenIAAsistente, resp)
+		if resp != nil {
+			texto.WriteString(resp.Content)
+			total.PromptTokens += resp.PromptTokens
+			total.OutputTokens += resp.OutputTokens
+			total.Provider = resp.Provider
+		}
+		if errRonda != nil || len(resp.ToolCalls) == 0 {
+			break
+		}
 
-	if err != nil {
-		return nil, fmt.Errorf("assistant_service.Chat IA: %w", err)
+		// Separar el texto de esta ronda del de la siguiente
+		if resp.Content != "" {
+			texto.WriteString("\n\n")
+			if onDelta != nil {
+				if errRonda = onDelta("\n\n"); errRonda != nil {
+					break
+				}
+			}
+		}
+
+		req.Messages = append(req.Messages, ai.Message{Role: "assistant", Content: resp.Content, ToolCalls: resp.ToolCalls})
+		for _, llamada := range resp.ToolCalls {
+			resultado, accion := s.ejecutarHerramienta(ctx, turno, llamada)
+			if accion != nil {
+				acciones = append(acciones, *accion)
+			}
+			req.Messages = append(req.Messages, ai.Message{
+				Role: "tool", Content: resultado, ToolCallID: llamada.ID, ToolName: llamada.Name,
+			})
+		}
+	}
+	duracionMs := int(time.Since(inicioIA).Milliseconds())
+
+	contenido := strings.TrimSpace(texto.String())
+	if contenido != "" {
+		// Sin cancelación: el cliente pudo haberse ido, el mensaje igual se guarda
+		if errGuardar := s.historialRepo.GuardarMensajeAsistente(
+			context.WithoutCancel(ctx), turno.TenantID, turno.ConversacionID,
+			contenido, total.PromptTokens, total.OutputTokens,
+			duracionMs, total.Provider,
+		); errGuardar != nil {
+			fmt.Printf("[ERROR Assistant] guardar respuesta: %v\n", errGuardar)
+		}
 	}
 
-	// 7. Guardar respuesta de la IA en BD
-	_ = s.historialRepo.GuardarMensajeAsistente(
-		ctx, tenantID, conversacionID,
-		resp.Content, resp.PromptTokens, resp.OutputTokens,
-		duracionMs, resp.Provider,
-	)
+	if errRonda != nil {
+		return nil, fmt.Errorf("assistant_service.responder IA: %w", errRonda)
+	}
 
 	return &ChatResult{
-		Response:       resp.Content,
-		PromptTokens:   resp.PromptTokens,
-		OutputTokens:   resp.OutputTokens,
-		Provider:       resp.Provider,
-		ConversacionID: conversacionID.String(),
+		Response:           contenido,
+		PromptTokens:       total.PromptTokens,
+		OutputTokens:       total.OutputTokens,
+		Provider:           total.Provider,
+		ConversacionID:     turno.ConversacionID.String(),
+		AccionesPendientes: acciones,
 	}, nil
 }
 
+// ──────────────────────────────────────────────────────────────────────────────
+// Acciones propuestas — confirmar o cancelar
+// ──────────────────────────────────────────────────────────────────────────────
+
+// ConfirmarAccion ejecuta una acción propuesta por el asistente. Se vuelven a
+// validar rol, sede y estado del reclamo; si ya no aplica queda FALLIDA.
+func (s *AssistantService) ConfirmarAccion(ctx context.Context, alcance AlcanceAsistente, accionID uuid.UUID, ip string) (*model.AsistenteAccion, error) {
+	accion, err := s.accionRepo.GetByID(ctx, alcance.TenantID, alcance.UsuarioID, accionID)
+	if err != nil {
+		return nil, fmt.Errorf("assistant_service.ConfirmarAccion: %w", err)
+	}
+	if accion == nil {
+		return nil, apperror.ErrNotFound
+	}
+	if accion.Estado != model.AccionAsistentePendiente || time.Since(accion.FechaCreacion) > model.VigenciaAccionAsistente {
+		return nil, apperror.ErrAccionNoPendiente
+	}
+
+	prep, err := s.prepararAccion(ctx, alcance, accion.Herramienta, accion.Argumentos)
+	if err != nil {
+		if _, errRes := s.accionRepo.Resolver(ctx, alcance.TenantID, accionID, model.AccionAsistenteFallida); errRes == nil {
+			_ = s.accionRepo.RegistrarResultado(ctx, alcance.TenantID, accionID, model.AccionAsistenteFallida, mensajeErrorHerramienta(err))
+		}
+		return nil, err
+	}
+
+	ok, err := s.accionRepo.Resolver(ctx, alcance.TenantID, accionID, model.AccionAsistenteEjecutada)
+	if err != nil {
+		return nil, fmt.Errorf("assistant_service.ConfirmarAccion: %w", err)
+	}
+	if !ok {
+		return nil, apperror.ErrAccionNoPendiente
+	}
+
+	estado, resultado := model.AccionAsistenteEjecutada, prep.resumen
+	errEjecutar := prep.ejecutar(ctx, ip)
+	if errEjecutar != nil {
+		estado, resultado = model.AccionAsistenteFallida, mensajeErrorHerramienta(errEjecutar)
+	}
+	if err := s.accionRepo.RegistrarResultado(context.WithoutCancel(ctx), alcance.TenantID, accionID, estado, resultado); err != nil {
+		fmt.Printf("[ERROR Assistant] resultado de la acción %s: %v\n", accionID, err)
+	}
+	if errEjecutar != nil {
+		return nil, errEjecutar
+	}
+
+	accion.Estado = estado
+	accion.Resultado = model.NullString{}
+	accion.Resultado.String, accion.Resultado.Valid = resultado, true
+	return accion, nil
+}
+
+// CancelarAccion descarta una acción propuesta sin ejecutarla.
+func (s *AssistantService) CancelarAccion(ctx context.Context, alcance AlcanceAsistente, accionID uuid.UUID) error {
+	accion, err := s.accionRepo.GetByID(ctx, alcance.TenantID, alcance.UsuarioID, accionID)
+	if err != nil {
+		return fmt.Errorf("assistant_service.CancelarAccion: %w", err)
+	}
+	if accion == nil {
+		return apperror.ErrNotFound
+	}
+	ok, err := s.accionRepo.Resolver(ctx, alcance.TenantID, accionID, model.AccionAsistenteCancelada)
+	if err != nil {
+		return fmt.Errorf("assistant_service.CancelarAccion: %w", err)
+	}
+	if !ok {
+		return apperror.ErrAccionNoPendiente
+	}
+	return nil
+}
+
+// AccionesPendientes acciones aún sin confirmar de una conversación del usuario.
+func (s *AssistantService) AccionesPendientes(ctx context.Context, alcance AlcanceAsistente, conversacionID uuid.UUID) ([]model.AsistenteAccion, error) {
+	ok, err := s.historialRepo.VerificarConversacionDelUsuario(ctx, alcance.TenantID, alcance.UsuarioID, conversacionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("conversacion_no_encontrada")
+	}
+	return s.accionRepo.Pendientes(ctx, alcance.TenantID, conversacionID)
+}
+
 // ──────────────────────────────────────────────────────────────────────────────
 // Gestión de conversaciones
 // ──────────────────────────────────────────────────────────────────────────────
@@ -172,22 +390,40 @@ func (s *AssistantService) EliminarConversacion(ctx context.Context, tenantID, u
 }
 
 // ──────────────────────────────────────────────────────────────────────────────
-// Contexto del tenant — CON LOGS DE DEBUG PARA DIAGNOSTICAR
+// Contexto del tenant — los datos de reclamos se consultan con herramientas
 // ──────────────────────────────────────────────────────────────────────────────
 
-func (s *AssistantService) buildTenantContext(ctx context.Context, tenantID uuid.UUID) (string, error) {
+func (s *AssistantService) buildTenantContext(ctx context.Context, alcance AlcanceAsistente) (string, error) {
 	var parts []string
 
 	// Info del tenant
-	tenant, err := s.tenantRepo.GetByTenantID(ctx, tenantID)
+	tenant, err := s.tenantRepo.GetByTenantID(ctx, alcance.TenantID)
 	if err != nil {
 		fmt.Printf("[ERROR] tenantRepo.GetByTenantID: %v\n", err)
 	} else if tenant != nil {
 		parts = append(parts, fmt.Sprintf("Empresa: %s (RUC: %s)", tenant.RazonSocial, tenant.RUC))
 	}
 
-	// Estadísticas generales
-	stats, err := s.assistantRepo.GetEstadisticas(ctx, tenantID)
+	// Usuario y alcance de sus consultas
+	sedes, err := s.sedeRepo.GetByTenant(ctx, alcance.TenantID)
+	if err != nil {
+		fmt.Printf("[ERROR] sedeRepo.GetByTenant: %v\n", err)
+	}
+	alcanceTexto := "todas las sedes"
+	var nombres []string
+	for _, sd := range sedes {
+		if alcance.SedeID != nil && sd.ID == *alcance.SedeID {
+			alcanceTexto = "solo la sede " + sd.Nombre
+		}
+		nombres = append(nombres, sd.Nombre)
+	}
+	parts = append(parts, fmt.Sprintf("USUARIO: rol %s, ve %s.", alcance.Rol, alcanceTexto))
+	if alcance.SedeID == nil && len(nombres) > 0 {
+		parts = append(parts, "SEDES: "+strings.Join(nombres, ", "))
+	}
+
+	// Estadísticas generales (dentro del alcance del usuario)
+	stats, err := s.assistantRepo.GetEstadisticas(ctx, alcance.TenantID, alcance.SedeID)
 	if err != nil {
 		fmt.Printf("[ERROR] GetEstadisticas: %v\n", err)
 	} else {
@@ -205,135 +441,32 @@ func (s *AssistantService) buildTenantContext(ctx context.Context, tenantID uuid
 		))
 	}
 
-	// TODOS los reclamos PENDIENTES (hasta 30)
-	pendientes, err := s.assistantRepo.GetReclamosPorEstado(ctx, tenantID, "PENDIENTE", 30)
-	if err != nil {
-		fmt.Printf("[ERROR] GetReclamosPorEstado PENDIENTE: %v\n", err)
-	} else {
-		fmt.Printf("[DEBUG] Pendientes encontrados: %d\n", len(pendientes))
-		if len(pendientes) > 0 {
-			parts = append(parts, "DETALLE DE RECLAMOS PENDIENTES ("+fmt.Sprintf("%d", len(pendientes))+" reclamos):\n"+s.formatReclamosDetallado(pendientes))
-		}
-	}
-
-	// TODOS los reclamos EN_PROCESO (hasta 30)
-	enProceso, err := s.assistantRepo.GetReclamosPorEstado(ctx, tenantID, "EN_PROCESO", 30)
-	if err != nil {
-		fmt.Printf("[ERROR] GetReclamosPorEstado EN_PROCESO: %v\n", err)
-	} else {
-		fmt.Printf("[DEBUG] En proceso encontrados: %d\n", len(enProceso))
-		if len(enProceso) > 0 {
-			parts = append(parts, "DETALLE DE RECLAMOS EN PROCESO ("+fmt.Sprintf("%d", len(enProceso))+" reclamos):\n"+s.formatReclamosDetallado(enProceso))
-		}
-	}
-
-	// Últimos resueltos (5)
-	resueltos, err := s.assistantRepo.GetReclamosPorEstado(ctx, tenantID, "RESUELTO", 5)
-	if err != nil {
-		fmt.Printf("[ERROR] GetReclamosPorEstado RESUELTO: %v\n", err)
-	} else if len(resueltos) > 0 {
-		parts = append(parts, "ÚLTIMOS RECLAMOS RESUELTOS:\n"+s.formatReclamosCorto(resueltos))
-	}
-
-	// Últimos rechazados (5)
-	rechazados, err := s.assistantRepo.GetReclamosPorEstado(ctx, tenantID, "RECHAZADO", 5)
-	if err != nil {
-		fmt.Printf("[ERROR] GetReclamosPorEstado RECHAZADO: %v\n", err)
-	} else if len(rechazados) > 0 {
-		parts = append(parts, "ÚLTIMOS RECLAMOS RECHAZADOS:\n"+s.formatReclamosCorto(rechazados))
-	}
-
 	parts = append(parts, fmt.Sprintf("Fecha actual: %s", time.Now().Format("2006-01-02 15:04")))
 
 	return strings.Join(parts, "\n\n"), nil
 }
 
-// formatReclamosDetallado muestra cada reclamo con toda la info útil.
-func (s *AssistantService) formatReclamosDetallado(reclamos []repo.ReclamoResumen) string {
-	var lines []string
-	for _, r := range reclamos {
-		urgencia := s.calcularUrgencia(r)
-		sede := ""
-		if r.SedeNombre.Valid {
-			sede = " | Sede: " + r.SedeNombre.String
-		}
-		lines = append(lines, fmt.Sprintf(
-			"  [%s] %s | %s | %s | %s | Tel: %s%s\n"+
-				"    Bien: %s\n"+
-				"    Detalle: %s\n"+
-				"    Pedido: %s\n"+
-				"    Registrado: %s | Límite: %s | %s",
-			r.CodigoReclamo, r.TipoSolicitud, urgencia,
-			r.NombreCompleto, r.Email, r.Telefono, sede,
-			r.DetalleBien,
-			r.DetalleCorto,
-			r.PedidoConsumidor,
-			r.FechaRegistro.Format("2006-01-02"),
-			s.formatFechaLimite(r.FechaLimite),
-			s.calcularDiasTexto(r.DiasRestantes),
-		))
-	}
-	return strings.Join(lines, "\n\n")
-}
-
-// formatReclamosCorto muestra un resumen de una línea por reclamo.
-func (s *AssistantService) formatReclamosCorto(reclamos []repo.ReclamoResumen) string {
-	var lines []string
-	for _, r := range reclamos {
-		lines = append(lines, fmt.Sprintf(
-			"  - %s | %s | %s | %s | %s",
-			r.CodigoReclamo, r.Estado, r.NombreCompleto,
-			r.FechaRegistro.Format("2006-01-02"), r.DetalleCorto,
-		))
-	}
-	return strings.Join(lines, "\n")
-}
-
-func (s *AssistantService) calcularUrgencia(r repo.ReclamoResumen) string {
-	if r.DiasRestantes < 0 {
-		return fmt.Sprintf("¡¡VENCIDO hace %d días!!", -r.DiasRestantes)
-	}
-	if r.DiasRestantes <= 3 {
-		return fmt.Sprintf("CRITICO: %d días", r.DiasRestantes)
-	}
-	if r.DiasRestantes <= 7 {
-		return fmt.Sprintf("URGENTE: %d días", r.DiasRestantes)
-	}
-	return fmt.Sprintf("%d días restantes", r.DiasRestantes)
-}
-
-func (s *AssistantService) formatFechaLimite(fl sql.NullTime) string {
-	if !fl.Valid {
-		return "Sin fecha límite"
-	}
-	return fl.Time.Format("2006-01-02")
-}
-
-func (s *AssistantService) calcularDiasTexto(dias int) string {
-	if dias < 0 {
-		return fmt.Sprintf("VENCIDO hace %d días", -dias)
-	}
-	if dias == 0 {
-		return "VENCE HOY"
-	}
-	return fmt.Sprintf("%d días restantes", dias)
-}
-
 func (s *AssistantService) buildSystemPrompt(tenantContext string) string {
 	return fmt.Sprintf(`Eres el asistente interno de IA para gestión del Libro de Reclamaciones digital. Respondes en español.
 
-REGLA ABSOLUTA: Usa SOLO los datos de la sección DATOS ACTUALES DEL TENANT.
-Si un dato no aparece ahí, responde "No tengo esa información disponible."
-NUNCA inventes datos.
+REGLA ABSOLUTA: Los datos de reclamos se obtienen SOLO con las herramientas
+(buscar_reclamos, obtener_reclamo, historial_reclamo, metricas_por_sede).
+Si una herramienta no devuelve el dato, responde "No tengo esa información disponible."
+NUNCA inventes códigos, clientes, fechas ni cifras.
 
 DATOS ACTUALES DEL TENANT:
 %s
 
+ACCIONES:
+- asignar_reclamo, cambiar_estado_reclamo y responder_reclamo NO se ejecutan al llamarlas:
+  quedan PENDIENTES hasta que el usuario las confirme en el panel.
+- Al proponer una acción, explica qué hará y pide al usuario que la confirme. Nunca digas que ya se ejecutó.
+- Si una herramienta devuelve "error", explícalo al usuario con tus palabras.
+
 INSTRUCCIONES:
 - SIEMPRE formatea tus respuestas en Markdown: usa **negritas** para códigos y campos clave, ## para títulos de sección, y listas numeradas (1. 2. 3.) para enumerar reclamos
-- Cuando listes reclamos, presenta cada uno con campos separados en líneas: **Código**, **Cliente**, **Email**, **Bien**, **Detalle**, **Pedido**, **Días restantes**
-- Copia TEXTUALMENTE los códigos, nombres y detalles que aparecen en los datos
-- Si un reclamo dice VENCIDO o CRITICO, destácalo con **VENCIDO** o **CRÍTICO** en negritas
+- Copia TEXTUALMENTE los códigos, nombres y detalles que devuelven las herramientas
+- Si un reclamo está vencido o le quedan 3 días o menos, destácalo con **VENCIDO** o **CRÍTICO** en negritas
 - Puedes asesorar sobre normativa INDECOPI (plazo 30 días, multas hasta 450 UIT)
-- Puedes redactar respuestas profesionales usando el detalle y pedido del consumidor como base`, tenantContext)
-}
\ No newline at end of file
+- Para redactar una respuesta consulta primero el reclamo con obtener_reclamo`, tenantContext)
+}
@@ -0,0 +1,650 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// ── Herramientas del asistente interno (tool calling) ───────────────────────
+// Lectura: se ejecutan al momento y el resultado vuelve al modelo como JSON.
+// Escritura: no se ejecutan; quedan como acción PENDIENTE que el usuario
+// confirma desde el panel (ConfirmarAccion vuelve a validar todo).
+// Todas respetan el rol y la sede del usuario que conversa.
+
+// AlcanceAsistente usuario que conversa con el asistente: las herramientas
+// ven y modifican solo lo que ese usuario puede en el panel.
+type AlcanceAsistente struct {
+	TenantID  uuid.UUID
+	UsuarioID uuid.UUID
+	Rol       string
+	SedeID    *uuid.UUID // nil = todas las sedes
+}
+
+const (
+	toolBuscarReclamos      = "buscar_reclamos"
+	toolObtenerReclamo      = "obtener_reclamo"
+	toolHistorialReclamo    = "historial_reclamo"
+	toolMetricasPorSede     = "metricas_por_sede"
+	toolAsignarReclamo      = "asignar_reclamo"
+	toolCambiarEstado       = "cambiar_estado_reclamo"
+	toolResponderReclamo    = "responder_reclamo"
+	maxResultadosBusquedaIA = 25
+)
+
+const patronFecha = `^\d{4}-\d{2}-\d{2}$`
+
+var schemaCodigoReclamo = &ai.Schema{Type: "string", Description: "Código del reclamo, p. ej. 2026-DEMO-XXXX-XXXXX", MinLength: intPtr(5), MaxLength: intPtr(40)}
+
+var herramientasAsistente = []ai.Tool{
+	{
+		Name: toolBuscarReclamos,
+		Description: "Busca reclamos y quejas con filtros. Úsala para listar, contar o encontrar reclamos " +
+			"(por estado, tipo, sede, fechas, sin asignar o por texto: nombre, DNI, código, pedido).",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"texto":          {Type: "string", Description: "Texto libre: nombre, documento, código, nº de pedido o palabras del relato", MaxLength: intPtr(200)},
+				"estado":         {Type: "string", Enum: []string{model.EstadoPendiente, model.EstadoEnProceso, model.EstadoResuelto, model.EstadoCerrado, model.EstadoRechazado}},
+				"tipo_solicitud": {Type: "string", Enum: []string{model.TipoReclamo, model.TipoQueja}},
+				"sede":           {Type: "string", Description: "Nombre (o parte del nombre) de la sede", MaxLength: intPtr(100)},
+				"fecha_desde":    {Type: "string", Description: "Registrados desde (YYYY-MM-DD)", Pattern: patronFecha},
+				"fecha_hasta":    {Type: "string", Description: "Registrados hasta, inclusive (YYYY-MM-DD)", Pattern: patronFecha},
+				"sin_asignar":    {Type: "boolean", Description: "Solo reclamos sin responsable asignado"},
+				"orden":          {Type: "string", Description: "fecha_limite para ver primero los más urgentes", Enum: []string{repo.OrdenFechaRegistro, repo.OrdenFechaLimite}},
+				"limite":         {Type: "integer", Description: fmt.Sprintf("Máximo de resultados (1-%d, por defecto 10)", maxResultadosBusquedaIA)},
+			},
+		},
+	},
+	{
+		Name:        toolObtenerReclamo,
+		Description: "Obtiene el detalle completo de un reclamo (consumidor, bien, relato, pedido, plazos y respuestas).",
+		Parameters: &ai.Schema{
+			Type:       "object",
+			Properties: map[string]*ai.Schema{"codigo": schemaCodigoReclamo},
+			Required:   []string{"codigo"},
+		},
+	},
+	{
+		Name:        toolHistorialReclamo,
+		Description: "Lista los cambios de estado, asignaciones y respuestas de un reclamo, del más reciente al más antiguo.",
+		Parameters: &ai.Schema{
+			Type:       "object",
+			Properties: map[string]*ai.Schema{"codigo": schemaCodigoReclamo},
+			Required:   []string{"codigo"},
+		},
+	},
+	{
+		Name:        toolMetricasPorSede,
+		Description: "Métricas por sede: totales, reclamos vs quejas, pendientes, vencidos y días promedio de resolución.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"fecha_desde": {Type: "string", Description: "Registrados desde (YYYY-MM-DD)", Pattern: patronFecha},
+				"fecha_hasta": {Type: "string", Description: "Registrados hasta, inclusive (YYYY-MM-DD)", Pattern: patronFecha},
+			},
+		},
+	},
+	{
+		Name: toolAsignarReclamo,
+		Description: "Propone asignar un reclamo a un usuario del panel. NO se ejecuta: queda pendiente " +
+			"hasta que el usuario la confirme.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"codigo":            schemaCodigoReclamo,
+				"email_responsable": {Type: "string", Description: "Email del usuario que atenderá el reclamo", Pattern: `^[^@\s]+@[^@\s]+\.[^@\s]+$`, MaxLength: intPtr(254)},
+			},
+			Required: []string{"codigo", "email_responsable"},
+		},
+	},
+	{
+		Name: toolCambiarEstado,
+		Description: "Propone cambiar el estado de un reclamo. NO se ejecuta: queda pendiente hasta que " +
+			"el usuario la confirme.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"codigo":     schemaCodigoReclamo,
+				"estado":     {Type: "string", Enum: []string{model.EstadoEnProceso, model.EstadoResuelto, model.EstadoRechazado, model.EstadoCerrado}},
+				"comentario": {Type: "string", Description: "Motivo del cambio (queda en el historial)", MinLength: intPtr(5), MaxLength: intPtr(1000)},
+			},
+			Required: []string{"codigo", "estado", "comentario"},
+		},
+	},
+	{
+		Name: toolResponderReclamo,
+		Description: "Propone registrar la respuesta de la empresa a un reclamo (se envía al consumidor y el " +
+			"reclamo pasa a RESUELTO). Redacta el texto completo. NO se ejecuta: queda pendiente hasta " +
+			"que el usuario la confirme.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"codigo":        schemaCodigoReclamo,
+				"respuesta":     {Type: "string", Description: "Respuesta dirigida al consumidor", MinLength: intPtr(20), MaxLength: intPtr(5000)},
+				"accion_tomada": {Type: "string", Description: "Acción concreta que tomó la empresa", MaxLength: intPtr(1000)},
+			},
+			Required: []string{"codigo", "respuesta"},
+		},
+	},
+}
+
+// argsBusquedaIA argumentos de buscar_reclamos.
+type argsBusquedaIA struct {
+	Texto         string `json:"texto"`
+	Estado        string `json:"estado"`
+	TipoSolicitud string `json:"tipo_solicitud"`
+	Sede          string `json:"sede"`
+	FechaDesde    string `json:"fecha_desde"`
+	FechaHasta    string `json:"fecha_hasta"`
+	SinAsignar    bool   `json:"sin_asignar"`
+	Orden         string `json:"orden"`
+	Limite        int    `json:"limite"`
+}
+
+// argsCodigoIA argumentos de obtener_reclamo e historial_reclamo.
+type argsCodigoIA struct {
+	Codigo string `json:"codigo"`
+}
+
+// argsRangoIA argumentos de metricas_por_sede.
+type argsRangoIA struct {
+	FechaDesde string `json:"fecha_desde"`
+	FechaHasta string `json:"fecha_hasta"`
+}
+
+// argsAsignarIA argumentos de asignar_reclamo.
+type argsAsignarIA struct {
+	Codigo           string `json:"codigo"`
+	EmailResponsable string `json:"email_responsable"`
+}
+
+// argsCambiarEstadoIA argumentos de cambiar_estado_reclamo.
+type argsCambiarEstadoIA struct {
+	Codigo     string `json:"codigo"`
+	Estado     string `json:"estado"`
+	Comentario string `json:"comentario"`
+}
+
+// argsResponderIA argumentos de responder_reclamo.
+type argsResponderIA struct {
+	Codigo       string `json:"codigo"`
+	Respuesta    string `json:"respuesta"`
+	AccionTomada string `json:"accion_tomada"`
+}
+
+// ejecutarHerramienta corre una invocación del modelo y retorna el contenido
+// del mensaje "tool" (JSON). Si la herramienta es de escritura retorna
+// también la acción pendiente creada. Los errores vuelven al modelo como
+// {"error": "..."} para que se los explique al usuario.
+func (s *AssistantService) ejecutarHerramienta(ctx context.Context, turno *TurnoChat, llamada ai.ToolCall) (string, *model.AsistenteAccion) {
+	resultado, accion, err := s.despacharHerramienta(ctx, turno, llamada)
+	if err != nil {
+		fmt.Printf("[Assistant] herramienta %s: %v — args: %s\n", llamada.Name, err, string(llamada.Arguments))
+		return resultadoHerramienta(map[string]string{"error": mensajeErrorHerramienta(err)}), nil
+	}
+	return resultadoHerramienta(resultado), accion
+}
+
+func (s *AssistantService) despacharHerramienta(ctx context.Context, turno *TurnoChat, llamada ai.ToolCall) (interface{}, *model.AsistenteAccion, error) {
+	tool := ai.BuscarTool(herramientasAsistente, llamada.Name)
+	if tool == nil {
+		return nil, nil, fmt.Errorf("herramienta desconocida %q", llamada.Name)
+	}
+	alcance := turno.alcance
+
+	switch llamada.Name {
+	case toolBuscarReclamos:
+		var args argsBusquedaIA
+		if err := tool.Decodificar(llamada.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		res, err := s.buscarReclamosIA(ctx, alcance, args)
+		return res, nil, err
+
+	case toolObtenerReclamo:
+		var args argsCodigoIA
+		if err := tool.Decodificar(llamada.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		res, err := s.obtenerReclamoIA(ctx, alcance, args.Codigo)
+		return res, nil, err
+
+	case toolHistorialReclamo:
+		var args argsCodigoIA
+		if err := tool.Decodificar(llamada.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		res, err := s.historialReclamoIA(ctx, alcance, args.Codigo)
+		return res, nil, err
+
+	case toolMetricasPorSede:
+		var args argsRangoIA
+		if err := tool.Decodificar(llamada.Arguments, &args); err != nil {
+			return nil, nil, err
+		}
+		desde, hasta, err := rangoFechasIA(args.FechaDesde, args.FechaHasta)
+		if err != nil {
+			return nil, nil, err
+		}
+		res, err := s.assistantRepo.MetricasPorSede(ctx, alcance.TenantID, alcance.SedeID, desde, hasta)
+		return res, nil, err
+
+	default: // herramientas de escritura
+		if err := tool.Parameters.Validar(llamada.Arguments); err != nil {
+			return nil, nil, fmt.Errorf("ai: argumentos de %s: %w", tool.Name, err)
+		}
+		accion, err := s.proponerAccion(ctx, turno, llamada.Name, llamada.Arguments)
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]interface{}{
+			"accion_id": accion.ID,
+			"estado":    "PENDIENTE_CONFIRMACION",
+			"resumen":   accion.Resumen,
+			"nota":      "La acción NO se ejecutó. El usuario debe confirmarla en el panel.",
+		}, accion, nil
+	}
+}
+
+// ── Lectura ──
+
+// reclamoIA resumen de un reclamo para los resultados de búsqueda.
+type reclamoIA struct {
+	Codigo        string `json:"codigo"`
+	Tipo          string `json:"tipo"`
+	Estado        string `json:"estado"`
+	Cliente       string `json:"cliente"`
+	Sede          string `json:"sede,omitempty"`
+	FechaRegistro string `json:"fecha_registro"`
+	FechaLimite   string `json:"fecha_limite,omitempty"`
+	Plazo         string `json:"plazo,omitempty"`
+	AsignadoA     string `json:"asignado_a,omitempty"`
+	Detalle       string `json:"detalle"`
+}
+
+func nuevoReclamoIA(r *model.Reclamo) reclamoIA {
+	res := reclamoIA{
+		Codigo:        r.CodigoReclamo,
+		Tipo:          r.TipoSolicitud,
+		Estado:        r.Estado,
+		Cliente:       r.NombreCompleto,
+		Sede:          r.SedeNombre.String,
+		FechaRegistro: r.FechaRegistro.Format("2006-01-02"),
+		AsignadoA:     r.NombreAtendidoPor,
+		Detalle:       truncarTexto(r.DetalleReclamo, 200),
+	}
+	if r.FechaLimiteRespuesta.Valid {
+		res.FechaLimite = r.FechaLimiteRespuesta.Time.Format("2006-01-02")
+		if !model.EsEstadoFinal(r.Estado) {
+			res.Plazo = textoPlazo(r.FechaLimiteRespuesta.Time)
+		}
+	}
+	return res
+}
+
+func (s *AssistantService) buscarReclamosIA(ctx context.Context, alcance AlcanceAsistente, args argsBusquedaIA) (interface{}, error) {
+	limite := args.Limite
+	if limite <= 0 {
+		limite = 10
+	}
+	if limite > maxResultadosBusquedaIA {
+		limite = maxResultadosBusquedaIA
+	}
+
+	filtros := repo.FiltrosBusqueda{
+		TenantID:   alcance.TenantID,
+		Texto:      args.Texto,
+		SinAsignar: args.SinAsignar,
+		Orden:      args.Orden,
+		Asc:        args.Orden == repo.OrdenFechaLimite,
+		Limite:     limite,
+	}
+	if args.Estado != "" {
+		filtros.Estados = []string{args.Estado}
+	}
+	if args.TipoSolicitud != "" {
+		filtros.TiposSolicitud = []string{args.TipoSolicitud}
+	}
+
+	desde, hasta, err := rangoFechasIA(args.FechaDesde, args.FechaHasta)
+	if err != nil {
+		return nil, err
+	}
+	filtros.FechaDesde = desde
+	if hasta != nil {
+		fin := hasta.Add(-time.Second) // FiltrosBusqueda.FechaHasta es inclusiva
+		filtros.FechaHasta = &fin
+	}
+
+	if args.Sede != "" {
+		sede, err := s.sedePorNombre(ctx, alcance.TenantID, args.Sede)
+		if err != nil {
+			return nil, err
+		}
+		filtros.SedeIDs = []uuid.UUID{sede.ID}
+	}
+	if alcance.SedeID != nil {
+		if len(filtros.SedeIDs) > 0 && filtros.SedeIDs[0] != *alcance.SedeID {
+			return nil, apperror.ErrSedeNoPermitida
+		}
+		filtros.SedeForzada = alcance.SedeID
+	}
+
+	resultado, err := s.reclamoService.Buscar(ctx, filtros)
+	if err != nil {
+		return nil, err
+	}
+
+	reclamos := make([]reclamoIA, 0, len(resultado.Data))
+	for i := range resultado.Data {
+		reclamos = append(reclamos, nuevoReclamoIA(&resultado.Data[i]))
+	}
+	return map[string]interface{}{
+		"mostrados": len(reclamos),
+		"hay_mas":   resultado.SiguienteCursor != "",
+		"reclamos":  reclamos,
+	}, nil
+}
+
+func (s *AssistantService) obtenerReclamoIA(ctx context.Context, alcance AlcanceAsistente, codigo string) (interface{}, error) {
+	r, err := s.reclamoPorCodigo(ctx, alcance, codigo)
+	if err != nil {
+		return nil, err
+	}
+
+	detalle := map[string]interface{}{
+		"resumen":           nuevoReclamoIA(r),
+		"detalle":           r.DetalleReclamo,
+		"pedido_consumidor": r.PedidoConsumidor,
+		"bien":              r.DescripcionBien,
+		"documento":         r.TipoDocumento + " " + r.NumeroDocumento,
+		"email":             r.Email,
+		"telefono":          r.Telefono,
+		"fecha_incidente":   r.FechaIncidente.Format("2006-01-02"),
+		"canal_origen":      r.CanalOrigen,
+	}
+	if r.MontoReclamado.Valid {
+		detalle["monto_reclamado"] = r.MontoReclamado.Float64
+	}
+	if r.NumeroPedido.Valid {
+		detalle["numero_pedido"] = r.NumeroPedido.String
+	}
+	if r.AreaQueja.Valid {
+		detalle["area_queja"] = r.AreaQueja.String
+	}
+
+	respuestas, err := s.respuestaService.GetByReclamo(ctx, alcance.TenantID, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	lista := make([]map[string]string, 0, len(respuestas))
+	for _, resp := range respuestas {
+		lista = append(lista, map[string]string{
+			"fecha":         resp.FechaRespuesta.Format("2006-01-02"),
+			"respuesta":     truncarTexto(resp.RespuestaEmpresa, 1000),
+			"accion_tomada": resp.AccionTomada.String,
+		})
+	}
+	detalle["respuestas"] = lista
+	return detalle, nil
+}
+
+func (s *AssistantService) historialReclamoIA(ctx context.Context, alcance AlcanceAsistente, codigo string) (interface{}, error) {
+	r, err := s.reclamoPorCodigo(ctx, alcance, codigo)
+	if err != nil {
+		return nil, err
+	}
+	historial, err := s.historialReclamoRepo.GetByReclamo(ctx, alcance.TenantID, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventos := make([]map[string]string, 0, len(historial))
+	for _, h := range historial {
+		eventos = append(eventos, map[string]string{
+			"fecha":           h.FechaAccion.Format("2006-01-02 15:04"),
+			"accion":          h.TipoAccion,
+			"estado_anterior": h.EstadoAnterior.String,
+			"estado_nuevo":    h.EstadoNuevo,
+			"comentario":      h.Comentario.String,
+		})
+	}
+	return map[string]interface{}{"codigo": r.CodigoReclamo, "eventos": eventos}, nil
+}
+
+// ── Escritura (confirmar antes de ejecutar) ──
+
+// accionPreparada acción de escritura ya validada contra el estado actual.
+type accionPreparada struct {
+	reclamo  *model.Reclamo
+	resumen  string
+	ejecutar func(ctx context.Context, ip string) error
+}
+
+// prepararAccion valida rol, sede, reclamo y argumentos de una herramienta de
+// escritura. Se usa al proponerla y otra vez al confirmarla, porque entre
+// ambos momentos pudo cambiar el reclamo o los permisos del usuario.
+func (s *AssistantService) prepararAccion(ctx context.Context, alcance AlcanceAsistente, herramienta string, argumentos json.RawMessage) (*accionPreparada, error) {
+	switch herramienta {
+	case toolAsignarReclamo:
+		var args argsAsignarIA
+		if err := json.Unmarshal(argumentos, &args); err != nil {
+			return nil, err
+		}
+		if alcance.Rol != model.RolAdmin {
+			return nil, apperror.ErrRolInsuficiente
+		}
+		r, err := s.reclamoPorCodigo(ctx, alcance, args.Codigo)
+		if err != nil {
+			return nil, err
+		}
+		responsable, err := s.usuarioRepo.GetByEmail(ctx, alcance.TenantID, strings.ToLower(strings.TrimSpace(args.EmailResponsable)))
+		if err != nil {
+			return nil, err
+		}
+		if responsable == nil || !responsable.Activo {
+			return nil, fmt.Errorf("no hay un usuario activo con el email %s", args.EmailResponsable)
+		}
+		if responsable.SedeID.Valid && r.SedeID.Valid && responsable.SedeID.UUID != r.SedeID.UUID {
+			return nil, fmt.Errorf("%s solo atiende reclamos de otra sede", responsable.NombreCompleto)
+		}
+		return &accionPreparada{
+			reclamo: r,
+			resumen: fmt.Sprintf("Asignar %s a %s (%s)", r.CodigoReclamo, responsable.NombreCompleto, responsable.Email),
+			ejecutar: func(ctx context.Context, ip string) error {
+				return s.reclamoService.Asignar(ctx, alcance.TenantID, r.ID, responsable.ID, alcance.UsuarioID, ip)
+			},
+		}, nil
+
+	case toolCambiarEstado:
+		var args argsCambiarEstadoIA
+		if err := json.Unmarshal(argumentos, &args); err != nil {
+			return nil, err
+		}
+		r, err := s.reclamoPorCodigo(ctx, alcance, args.Codigo)
+		if err != nil {
+			return nil, err
+		}
+		if !model.TransicionValida(r.Estado, args.Estado) {
+			return nil, apperror.ErrTransicionEstado.Withf(r.Estado, args.Estado)
+		}
+		return &accionPreparada{
+			reclamo: r,
+			resumen: fmt.Sprintf("Cambiar %s de %s a %s: %s", r.CodigoReclamo, r.Estado, args.Estado, args.Comentario),
+			ejecutar: func(ctx context.Context, ip string) error {
+				return s.reclamoService.CambiarEstado(ctx, alcance.TenantID, r.ID, alcance.UsuarioID, args.Estado, args.Comentario, ip)
+			},
+		}, nil
+
+	case toolResponderReclamo:
+		var args argsResponderIA
+		if err := json.Unmarshal(argumentos, &args); err != nil {
+			return nil, err
+		}
+		r, err := s.reclamoPorCodigo(ctx, alcance, args.Codigo)
+		if err != nil {
+			return nil, err
+		}
+		if r.Estado != model.EstadoPendiente && r.Estado != model.EstadoEnProceso {
+			return nil, fmt.Errorf("el reclamo %s está %s; solo se responden reclamos pendientes o en proceso", r.CodigoReclamo, r.Estado)
+		}
+		return &accionPreparada{
+			reclamo: r,
+			resumen: fmt.Sprintf("Responder %s y marcarlo RESUELTO: \"%s\"", r.CodigoReclamo, truncarTexto(args.Respuesta, 300)),
+			ejecutar: func(ctx context.Context, ip string) error {
+				_, err := s.respuestaService.Crear(ctx, alcance.TenantID, r.ID, alcance.UsuarioID,
+					args.Respuesta, args.AccionTomada, "", "", ip, nil)
+				return err
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("herramienta desconocida %q", herramienta)
+}
+
+// proponerAccion valida la acción y la guarda como PENDIENTE.
+func (s *AssistantService) proponerAccion(ctx context.Context, turno *TurnoChat, herramienta string, argumentos json.RawMessage) (*model.AsistenteAccion, error) {
+	prep, err := s.prepararAccion(ctx, turno.alcance, herramienta, argumentos)
+	if err != nil {
+		return nil, err
+	}
+	accion := &model.AsistenteAccion{
+		TenantModel:    model.TenantModel{TenantID: turno.TenantID},
+		UsuarioID:      turno.alcance.UsuarioID,
+		ConversacionID: turno.ConversacionID,
+		ReclamoID:      prep.reclamo.ID,
+		Herramienta:    herramienta,
+		Argumentos:     argumentos,
+		Resumen:        prep.resumen,
+	}
+	if err := s.accionRepo.Crear(ctx, accion); err != nil {
+		return nil, err
+	}
+	return accion, nil
+}
+
+// ── Helpers ──
+
+// reclamoPorCodigo reclamo completo por código, dentro de la sede del usuario.
+func (s *AssistantService) reclamoPorCodigo(ctx context.Context, alcance AlcanceAsistente, codigo string) (*model.Reclamo, error) {
+	ref, err := s.reclamoService.GetByCodigoPublico(ctx, alcance.TenantID, strings.ToUpper(strings.TrimSpace(codigo)))
+	if err != nil {
+		return nil, err
+	}
+	if ref == nil {
+		return nil, fmt.Errorf("no existe un reclamo con el código %s", codigo)
+	}
+	r, err := s.reclamoService.GetByID(ctx, alcance.TenantID, ref.ID)
+	if err != nil {
+		return nil, err
+	}
+	if alcance.SedeID != nil && (!r.SedeID.Valid || r.SedeID.UUID != *alcance.SedeID) {
+		return nil, apperror.ErrSedeNoPermitida
+	}
+	return r, nil
+}
+
+// sedePorNombre sede cuyo nombre coincide (o contiene) el texto indicado.
+func (s *AssistantService) sedePorNombre(ctx context.Context, tenantID uuid.UUID, nombre string) (*model.Sede, error) {
+	sedes, err := s.sedeRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	buscado := strings.ToLower(strings.TrimSpace(nombre))
+	var candidatas []*model.Sede
+	nombres := make([]string, 0, len(sedes))
+	for i := range sedes {
+		actual := strings.ToLower(sedes[i].Nombre)
+		if actual == buscado {
+			return &sedes[i], nil
+		}
+		if strings.Contains(actual, buscado) {
+			candidatas = append(candidatas, &sedes[i])
+		}
+		nombres = append(nombres, sedes[i].Nombre)
+	}
+	if len(candidatas) == 1 {
+		return candidatas[0], nil
+	}
+	return nil, fmt.Errorf("no se identificó la sede %q; sedes disponibles: %s", nombre, strings.Join(nombres, ", "))
+}
+
+// rangoFechasIA convierte fechas YYYY-MM-DD en [desde, hasta+1día).
+func rangoFechasIA(desdeStr, hastaStr string) (*time.Time, *time.Time, error) {
+	var desde, hasta *time.Time
+	if desdeStr != "" {
+		t, err := time.Parse("2006-01-02", desdeStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fecha_desde inválida")
+		}
+		desde = &t
+	}
+	if hastaStr != "" {
+		t, err := time.Parse("2006-01-02", hastaStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fecha_hasta inválida")
+		}
+		t = t.AddDate(0, 0, 1)
+		hasta = &t
+	}
+	return desde, hasta, nil
+}
+
+// textoPlazo días hasta la fecha límite, en el formato que ya usa el prompt.
+func textoPlazo(limite time.Time) string {
+	hoy := time.Now()
+	hoy = time.Date(hoy.Year(), hoy.Month(), hoy.Day(), 0, 0, 0, 0, limite.Location())
+	dias := int(limite.Sub(hoy).Hours() / 24)
+	switch {
+	case dias < 0:
+		return fmt.Sprintf("VENCIDO hace %d días", -dias)
+	case dias == 0:
+		return "VENCE HOY"
+	case dias <= 3:
+		return fmt.Sprintf("CRÍTICO: %d días", dias)
+	}
+	return fmt.Sprintf("%d días restantes", dias)
+}
+
+func truncarTexto(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max]) + "…"
+}
+
+func resultadoHerramienta(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return `{"error":"no se pudo serializar el resultado"}`
+	}
+	return string(b)
+}
+
+// mensajeErrorHerramienta texto seguro para el modelo: los AppError y los
+// errores de validación se explican; los internos no se filtran.
+func mensajeErrorHerramienta(err error) string {
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "la consulta tardó demasiado"
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "_repo.") || strings.Contains(msg, "_service.") {
+		return "error interno al consultar los datos"
+	}
+	return msg
+}
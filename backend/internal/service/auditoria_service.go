@@ -0,0 +1,155 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/repo"
+)
+
+// maxValorAuditoria largo máximo de un string en el diff (logos en base64, etc.).
+const maxValorAuditoria = 500
+
+// AuditoriaService registra quién cambió qué en el panel admin.
+type AuditoriaService struct {
+	auditoriaRepo *repo.AuditoriaRepo
+}
+
+func NewAuditoriaService(auditoriaRepo *repo.AuditoriaRepo) *AuditoriaService {
+	return &AuditoriaService{auditoriaRepo: auditoriaRepo}
+}
+
+// Registrar guarda la entrada de auditoría con el diff antes/después en Detalles.
+// Solo se incluyen los campos que cambiaron; antes o despues pueden ser nil
+// (creación o eliminación física).
+func (s *AuditoriaService) Registrar(ctx context.Context, a *model.Auditoria, antes, despues interface{}) error {
+	detalles, err := diffAuditoria(antes, despues)
+	if err != nil {
+		return fmt.Errorf("auditoria_service.Registrar: %w", err)
+	}
+	a.Detalles = detalles
+
+	if err := s.auditoriaRepo.Create(ctx, a); err != nil {
+		return fmt.Errorf("auditoria_service.Registrar: %w", err)
+	}
+	return nil
+}
+
+// Listar retorna la auditoría filtrada y paginada.
+func (s *AuditoriaService) Listar(ctx context.Context, filtros repo.FiltrosAuditoria, pag dto.PaginationRequest) ([]model.Auditoria, int, error) {
+	return s.auditoriaRepo.Listar(ctx, filtros, pag.Limit(), pag.Offset())
+}
+
+// maxExportAuditoria límite de filas de la exportación CSV.
+const maxExportAuditoria = 10000
+
+// ExportarCSV genera el CSV de la auditoría filtrada (máximo maxExportAuditoria filas).
+func (s *AuditoriaService) ExportarCSV(ctx context.Context, filtros repo.FiltrosAuditoria) ([]byte, error) {
+	registros, _, err := s.auditoriaRepo.Listar(ctx, filtros, maxExportAuditoria, 0)
+	if err != nil {
+		return nil, fmt.Errorf("auditoria_service.ExportarCSV: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff") // BOM para que Excel lea UTF-8
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"fecha", "usuario_id", "usuario", "accion", "entidad", "entidad_id", "ip", "detalles"})
+	for _, a := range registros {
+		_ = w.Write([]string{
+			a.Fecha.Format("2006-01-02 15:04:05"),
+			a.UsuarioID.String(),
+			celdaCSV(a.NombreUsuario),
+			a.Accion,
+			a.Entidad,
+			celdaCSV(a.EntidadID.String),
+			celdaCSV(a.IPAddress.String),
+			string(a.Detalles),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("auditoria_service.ExportarCSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// diffAuditoria compara ambos estados por sus campos JSON y retorna
+// {"antes": {...}, "despues": {...}} solo con las claves que cambiaron.
+func diffAuditoria(antes, despues interface{}) (json.RawMessage, error) {
+	mAntes, err := aMapaAuditoria(antes)
+	if err != nil {
+		return nil, err
+	}
+	mDespues, err := aMapaAuditoria(despues)
+	if err != nil {
+		return nil, err
+	}
+
+	cambiosAntes := map[string]interface{}{}
+	cambiosDespues := map[string]interface{}{}
+	for k, v := range mAntes {
+		if dv, ok := mDespues[k]; !ok || !reflect.DeepEqual(v, dv) {
+			cambiosAntes[k] = recortarValor(v)
+		}
+	}
+	for k, v := range mDespues {
+		if av, ok := mAntes[k]; !ok || !reflect.DeepEqual(av, v) {
+			cambiosDespues[k] = recortarValor(v)
+		}
+	}
+
+	detalles := map[string]interface{}{}
+	if mAntes != nil {
+		detalles["antes"] = cambiosAntes
+	}
+	if mDespues != nil {
+		detalles["despues"] = cambiosDespues
+	}
+	return json.Marshal(detalles)
+}
+
+// aMapaAuditoria serializa el estado a mapa. Usa los tags JSON, así que los
+// campos json:"-" (hashes, tokens) nunca llegan a la auditoría.
+func aMapaAuditoria(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// recortarValor corta los strings largos por caracteres, no por bytes, para
+// no partir una letra acentuada o un emoji.
+func recortarValor(v interface{}) interface{} {
+	if s, ok := v.(string); ok && utf8.RuneCountInString(s) > maxValorAuditoria {
+		return string([]rune(s)[:maxValorAuditoria]) + "…"
+	}
+	return v
+}
+
+// celdaCSV antepone ' a los valores que Excel interpretaría como fórmula
+// (inyección de fórmulas al abrir la exportación).
+func celdaCSV(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
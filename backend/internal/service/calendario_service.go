@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// CalendarioService administra el calendario SLA (días hábiles y feriados) de cada tenant
+// y recalcula las fechas límite de los reclamos abiertos cuando el calendario cambia.
+type CalendarioService struct {
+	calendarioRepo *repo.CalendarioRepo
+	reclamoRepo    *repo.ReclamoRepo
+	tenantRepo     *repo.TenantRepo
+}
+
+func NewCalendarioService(calendarioRepo *repo.CalendarioRepo, reclamoRepo *repo.ReclamoRepo, tenantRepo *repo.TenantRepo) *CalendarioService {
+	return &CalendarioService{
+		calendarioRepo: calendarioRepo,
+		reclamoRepo:    reclamoRepo,
+		tenantRepo:     tenantRepo,
+	}
+}
+
+// ObtenerCalendario construye el calendario SLA efectivo del tenant.
+func (s *CalendarioService) ObtenerCalendario(ctx context.Context, tenantID uuid.UUID) (*helper.CalendarioSLA, error) {
+	cfg, err := s.ObtenerConfig(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	feriados, err := s.calendarioRepo.ListarFeriados(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("calendario_service.ObtenerCalendario: %w", err)
+	}
+
+	op := helper.OpcionesCalendario{
+		ZonaHoraria:               cfg.ZonaHoraria,
+		DiasHabiles:               cfg.DiasHabiles,
+		IncluirFeriadosNacionales: cfg.IncluirFeriadosNacionales,
+	}
+	for _, d := range cfg.DiasNoLaborables {
+		op.DiasNoLaborables = append(op.DiasNoLaborables, time.Weekday(d))
+	}
+	for _, f := range feriados {
+		if f.Recurrente {
+			op.FeriadosRecurrentes = append(op.FeriadosRecurrentes, f.Fecha)
+		} else {
+			op.Feriados = append(op.Feriados, f.Fecha)
+		}
+	}
+	return helper.NewCalendarioSLA(op), nil
+}
+
+// ObtenerConfig retorna la configuración del tenant o la legal por defecto.
+func (s *CalendarioService) ObtenerConfig(ctx context.Context, tenantID uuid.UUID) (*model.CalendarioConfig, error) {
+	cfg, err := s.calendarioRepo.ObtenerConfig(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("calendario_service.ObtenerConfig: %w", err)
+	}
+	if cfg == nil {
+		return model.CalendarioConfigPorDefecto(tenantID), nil
+	}
+	return cfg, nil
+}
+
+// ActualizarConfig guarda las reglas y recalcula los reclamos abiertos en segundo plano.
+func (s *CalendarioService) ActualizarConfig(ctx context.Context, cfg *model.CalendarioConfig) error {
+	if _, err := time.LoadLocation(cfg.ZonaHoraria); err != nil && cfg.ZonaHoraria != helper.ZonaHorariaLima {
+		return apperror.ErrBadRequest
+	}
+	for _, d := range cfg.DiasNoLaborables {
+		if d < int(time.Sunday) || d > int(time.Saturday) {
+			return apperror.ErrBadRequest
+		}
+	}
+	if len(cfg.DiasNoLaborables) >= 7 {
+		return apperror.ErrBadRequest
+	}
+
+	if err := s.calendarioRepo.GuardarConfig(ctx, cfg); err != nil {
+		return fmt.Errorf("calendario_service.ActualizarConfig: %w", err)
+	}
+	s.recalcularEnSegundoPlano(cfg.TenantID)
+	return nil
+}
+
+// ── Feriados ──
+
+func (s *CalendarioService) ListarFeriados(ctx context.Context, tenantID uuid.UUID) ([]model.Feriado, error) {
+	feriados, err := s.calendarioRepo.ListarFeriados(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("calendario_service.ListarFeriados: %w", err)
+	}
+	return feriados, nil
+}
+
+func (s *CalendarioService) CrearFeriado(ctx context.Context, f *model.Feriado) error {
+	existentes, err := s.calendarioRepo.ListarFeriados(ctx, f.TenantID)
+	if err != nil {
+		return fmt.Errorf("calendario_service.CrearFeriado: %w", err)
+	}
+	for _, e := range existentes {
+		if e.Fecha.Format("2006-01-02") == f.Fecha.Format("2006-01-02") {
+			return apperror.ErrConflict
+		}
+	}
+
+	if err := s.calendarioRepo.CrearFeriado(ctx, f); err != nil {
+		return fmt.Errorf("calendario_service.CrearFeriado: %w", err)
+	}
+	s.recalcularEnSegundoPlano(f.TenantID)
+	return nil
+}
+
+func (s *CalendarioService) EliminarFeriado(ctx context.Context, tenantID, feriadoID uuid.UUID) error {
+	ok, err := s.calendarioRepo.EliminarFeriado(ctx, tenantID, feriadoID)
+	if err != nil {
+		return fmt.Errorf("calendario_service.EliminarFeriado: %w", err)
+	}
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	s.recalcularEnSegundoPlano(tenantID)
+	return nil
+}
+
+// ── Recálculo ──
+
+// RecalcularFechasLimite recalcula fecha_limite_respuesta de los reclamos abiertos
+// (PENDIENTE / EN_PROCESO) con el calendario vigente. Retorna cuántos cambiaron.
+func (s *CalendarioService) RecalcularFechasLimite(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	tenant, err := s.tenantRepo.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("calendario_service.RecalcularFechasLimite tenant: %w", err)
+	}
+	if tenant == nil {
+		return 0, apperror.ErrNotFound
+	}
+
+	cal, err := s.ObtenerCalendario(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	abiertos, err := s.reclamoRepo.ListarAbiertosPlazo(ctx, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("calendario_service.RecalcularFechasLimite: %w", err)
+	}
+
+	actualizados := 0
+	for _, r := range abiertos {
+		nueva := cal.CalcularFechaLimite(r.FechaRegistro, tenant.PlazoRespuestaDias)
+		if r.FechaLimiteRespuesta.Valid &&
+			r.FechaLimiteRespuesta.Time.Format("2006-01-02") == nueva.Format("2006-01-02") {
+			continue
+		}
+		if err := s.reclamoRepo.UpdateFechaLimite(ctx, tenantID, r.ID, nueva); err != nil {
+			return actualizados, fmt.Errorf("calendario_service.RecalcularFechasLimite: %w", err)
+		}
+		actualizados++
+	}
+	return actualizados, nil
+}
+
+// recalcularEnSegundoPlano lanza el recálculo sin bloquear la respuesta HTTP.
+func (s *CalendarioService) recalcularEnSegundoPlano(tenantID uuid.UUID) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("[CRITICAL] Panic en recálculo SLA: %v\n", r)
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		n, err := s.RecalcularFechasLimite(ctx, tenantID)
+		if err != nil {
+			fmt.Printf("[ERROR] Recálculo SLA tenant %s: %v\n", tenantID, err)
+			return
+		}
+		fmt.Printf("[INFO] Recálculo SLA tenant %s: %d reclamos actualizados\n", tenantID, n)
+	}()
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minRunasNombreCategoria      = 2
+	maxRunasNombreCategoria      = 60
+	maxRunasDescripcionCategoria = 300
+	maxCategoriasActivas         = 50 // cada una viaja en el prompt de clasificación
+)
+
+// CategoriaReclamoService administra la taxonomía que usa la clasificación IA.
+// No se eliminan categorías: se desactivan para conservar el histórico.
+type CategoriaReclamoService struct {
+	categoriaRepo *repo.CategoriaReclamoRepo
+}
+
+func NewCategoriaReclamoService(categoriaRepo *repo.CategoriaReclamoRepo) *CategoriaReclamoService {
+	return &CategoriaReclamoService{categoriaRepo: categoriaRepo}
+}
+
+func (s *CategoriaReclamoService) Listar(ctx context.Context, tenantID uuid.UUID, soloActivas bool) ([]model.CategoriaReclamo, error) {
+	categorias, err := s.categoriaRepo.Listar(ctx, tenantID, soloActivas)
+	if err != nil {
+		return nil, fmt.Errorf("categoria_reclamo_service.Listar: %w", err)
+	}
+	return categorias, nil
+}
+
+func (s *CategoriaReclamoService) Crear(ctx context.Context, tenantID uuid.UUID, req *dto.CategoriaReclamoRequest) (*model.CategoriaReclamo, error) {
+	c := &model.CategoriaReclamo{TenantModel: model.TenantModel{TenantID: tenantID}}
+	if err := s.aplicar(ctx, c, req, true); err != nil {
+		return nil, err
+	}
+	if err := s.categoriaRepo.Create(ctx, c); err != nil {
+		return nil, fmt.Errorf("categoria_reclamo_service.Crear: %w", err)
+	}
+	return c, nil
+}
+
+func (s *CategoriaReclamoService) Actualizar(ctx context.Context, tenantID, id uuid.UUID, req *dto.CategoriaReclamoRequest) (*model.CategoriaReclamo, error) {
+	c, err := s.categoriaRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("categoria_reclamo_service.Actualizar: %w", err)
+	}
+	if c == nil {
+		return nil, apperror.ErrNotFound
+	}
+
+	activaAntes := c.Activo
+	if req.Activo != nil {
+		c.Activo = *req.Activo
+	}
+	if err := s.aplicar(ctx, c, req, c.Activo && !activaAntes); err != nil {
+		return nil, err
+	}
+	if err := s.categoriaRepo.Update(ctx, c); err != nil {
+		return nil, fmt.Errorf("categoria_reclamo_service.Actualizar: %w", err)
+	}
+	return c, nil
+}
+
+// aplicar valida el request y lo copia a c. activando indica que c pasa a
+// contar entre las activas (alta o reactivación).
+func (s *CategoriaReclamoService) aplicar(ctx context.Context, c *model.CategoriaReclamo, req *dto.CategoriaReclamoRequest, activando bool) error {
+	nombre := strings.Join(strings.Fields(req.Nombre), " ")
+	if n := utf8.RuneCountInString(nombre); n < minRunasNombreCategoria || n > maxRunasNombreCategoria {
+		return apperror.ErrCategoriaInvalida.Withf(fmt.Sprintf("el nombre debe tener entre %d y %d caracteres", minRunasNombreCategoria, maxRunasNombreCategoria))
+	}
+	if strings.EqualFold(nombre, categoriaSinCategoria) {
+		return apperror.ErrCategoriaInvalida.Withf(fmt.Sprintf("%s es un nombre reservado", categoriaSinCategoria))
+	}
+	descripcion := strings.TrimSpace(req.Descripcion)
+	if utf8.RuneCountInString(descripcion) > maxRunasDescripcionCategoria {
+		return apperror.ErrCategoriaInvalida.Withf(fmt.Sprintf("la descripción admite hasta %d caracteres", maxRunasDescripcionCategoria))
+	}
+
+	existe, err := s.categoriaRepo.ExisteNombre(ctx, c.TenantID, nombre, c.ID)
+	if err != nil {
+		return fmt.Errorf("categoria_reclamo_service.aplicar: %w", err)
+	}
+	if existe {
+		return apperror.ErrCategoriaDuplicada
+	}
+
+	if activando {
+		activas, err := s.categoriaRepo.Listar(ctx, c.TenantID, true)
+		if err != nil {
+			return fmt.Errorf("categoria_reclamo_service.aplicar: %w", err)
+		}
+		if len(activas) >= maxCategoriasActivas {
+			return apperror.ErrDemasiadasCategorias.Withf(maxCategoriasActivas)
+		}
+	}
+
+	c.Nombre = nombre
+	c.Descripcion = model.NullString{NullString: sql.NullString{String: descripcion, Valid: descripcion != ""}}
+	return nil
+}
@@ -186,6 +186,17 @@ func (s *ChatbotService) GetAPIKeys(ctx context.Context, tenantID, chatbotID uui
 	return s.apiKeyRepo.GetByChatbot(ctx, tenantID, chatbotID)
 }
 
+func (s *ChatbotService) GetAPIKey(ctx context.Context, tenantID, keyID uuid.UUID) (*model.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByID(ctx, tenantID, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("chatbot_service.GetAPIKey: %w", err)
+	}
+	if key == nil {
+		return nil, apperror.ErrNotFound
+	}
+	return key, nil
+}
+
 func (s *ChatbotService) GenerateAPIKey(ctx context.Context, tenantID, chatbotID uuid.UUID, nombre, entorno string, creadoPor uuid.UUID) (*model.APIKey, string, error) {
 	existing, err := s.chatbotRepo.GetByID(ctx, tenantID, chatbotID)
 	if err != nil {
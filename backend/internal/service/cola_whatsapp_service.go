@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+)
+
+const (
+	colaWALote         = 20
+	colaWALease        = 3 * time.Minute  // respuesta (90 s) + envío, con margen
+	colaWAWorkers      = 8                // mensajes en paralelo por réplica
+	colaWAMaxPorTenant = 2                // mensajes en proceso por tenant entre todas las réplicas
+	colaWATimeoutIA    = 90 * time.Second // IA + descarga y transcripción de audio o evidencias
+	colaWABackoffBase  = 10 * time.Second // 10s, 20s, 40s, ...
+	colaWABackoffMax   = 10 * time.Minute
+)
+
+// ColaWhatsAppService cola durable de mensajes entrantes: el webhook encola y
+// responde 200 al instante; un pool de workers genera y envía las respuestas
+// en orden por teléfono y con concurrencia acotada por tenant.
+type ColaWhatsAppService struct {
+	colaRepo        *repo.ColaWhatsAppRepo
+	whatsappService *WhatsAppService
+
+	despertando atomic.Bool
+}
+
+func NewColaWhatsAppService(colaRepo *repo.ColaWhatsAppRepo, whatsappService *WhatsAppService) *ColaWhatsAppService {
+	return &ColaWhatsAppService{
+		colaRepo:        colaRepo,
+		whatsappService: whatsappService,
+	}
+}
+
+// Encolar guarda el mensaje para procesarlo después. Retorna false si el
+// mismo wamid ya estaba en la cola.
+func (s *ColaWhatsAppService) Encolar(ctx context.Context, m *model.MensajeWhatsAppEntrante) (bool, error) {
+	nuevo, err := s.colaRepo.Encolar(ctx, m)
+	if err != nil {
+		return false, fmt.Errorf("cola_whatsapp_service.Encolar: %w", err)
+	}
+	return nuevo, nil
+}
+
+// Despertar procesa la cola en segundo plano tras encolar, sin esperar al
+// próximo tick del scheduler. Si ya hay una corrida en esta réplica no hace nada.
+func (s *ColaWhatsAppService) Despertar() {
+	if !s.despertando.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.despertando.Store(false)
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("[CRITICAL] Panic en cola WhatsApp: %v\n", r)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*colaWALease)
+		defer cancel()
+		if _, _, err := s.Procesar(ctx); err != nil {
+			fmt.Printf("[ERROR] Cola WhatsApp: %v\n", err)
+		}
+	}()
+}
+
+// Procesar reclama lotes y los atiende con hasta colaWAWorkers en paralelo,
+// hasta vaciar la cola o agotar el contexto. Cada lote trae como mucho un
+// mensaje por teléfono; el siguiente del mismo cliente llega en otro lote,
+// cuando el anterior ya se respondió.
+func (s *ColaWhatsAppService) Procesar(ctx context.Context) (procesados, fallidos int, err error) {
+	for ctx.Err() == nil {
+		lote, err := s.colaRepo.Reclamar(ctx, colaWALote, colaWAMaxPorTenant, colaWALease)
+		if err != nil {
+			return procesados, fallidos, err
+		}
+		if len(lote) == 0 {
+			break
+		}
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			workers  = make(chan struct{}, colaWAWorkers)
+			errMarca error
+		)
+		for i := range lote {
+			m := &lote[i]
+			workers <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-workers }()
+
+				if errProceso := s.atender(ctx, m); errProceso != nil {
+					s.registrarError(ctx, m, errProceso)
+					mu.Lock()
+					fallidos++
+					mu.Unlock()
+					return
+				}
+				err := s.colaRepo.MarcarProcesado(ctx, m.TenantID, m.ID)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					errMarca = err
+					return
+				}
+				procesados++
+			}()
+		}
+		wg.Wait()
+
+		if errMarca != nil {
+			return procesados, fallidos, errMarca
+		}
+	}
+	return procesados, fallidos, nil
+}
+
+// atender genera la respuesta (una sola vez) y la envía. Un reintento reusa la
+// respuesta guardada: no vuelve a llamar a la IA ni a registrar el reclamo.
+func (s *ColaWhatsAppService) atender(ctx context.Context, m *model.MensajeWhatsAppEntrante) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	canal, err := s.whatsappService.ResolverCanalPorPhoneNumberID(ctx, m.PhoneNumberID)
+	if err != nil {
+		return err
+	}
+	if canal == nil || canal.TenantID != m.TenantID {
+		// El canal se desactivó o cambió de tenant después de recibir el mensaje
+		fmt.Printf("[WhatsApp] Canal %s ya no está activo — mensaje %s descartado\n", m.PhoneNumberID, m.MensajeID)
+		return nil
+	}
+
+	var respuesta MensajeSaliente
+	if m.Respuesta.Valid {
+		respuesta = respuestaGuardada(m.Respuesta.String)
+	} else {
+		respuesta = s.responder(ctx, canal, m)
+		guardada := ""
+		if !respuesta.Vacio() {
+			data, err := json.Marshal(respuesta)
+			if err != nil {
+				return err
+			}
+			guardada = string(data)
+		}
+		if err := s.colaRepo.GuardarRespuesta(ctx, m.TenantID, m.ID, guardada); err != nil {
+			return err
+		}
+	}
+	if respuesta.Vacio() {
+		return nil
+	}
+	return EnviarMensaje(ctx, canal.AccessToken, canal.PhoneID, m.Telefono, respuesta)
+}
+
+func (s *ColaWhatsAppService) responder(ctx context.Context, canal *CanalResuelto, m *model.MensajeWhatsAppEntrante) MensajeSaliente {
+	ctx, cancel := context.WithTimeout(ctx, colaWATimeoutIA)
+	defer cancel()
+	return s.whatsappService.ProcesarEntrante(ctx, canal, m)
+}
+
+// respuestaGuardada lee la respuesta de la cola. Las filas anteriores a los
+// mensajes interactivos guardaban texto plano.
+func respuestaGuardada(guardada string) MensajeSaliente {
+	if guardada == "" {
+		return MensajeSaliente{}
+	}
+	var mensaje MensajeSaliente
+	if err := json.Unmarshal([]byte(guardada), &mensaje); err != nil || mensaje.Tipo == "" {
+		return MensajeTexto(guardada)
+	}
+	return mensaje
+}
+
+func (s *ColaWhatsAppService) registrarError(ctx context.Context, m *model.MensajeWhatsAppEntrante, errProceso error) {
+	msg := mensajeError(errProceso)
+	estado, err := s.colaRepo.MarcarError(ctx, m.TenantID, m.ID, msg, time.Now().Add(backoffColaWA(m.Intentos+1)))
+	if err != nil {
+		fmt.Printf("[ERROR] Cola WhatsApp MarcarError %s: %v\n", m.ID, err)
+		return
+	}
+	if estado == model.EntranteFallido {
+		fmt.Printf("[ERROR] Cola WhatsApp %s de %s → FALLIDO tras %d intentos: %s\n", m.MensajeID, m.Telefono, m.Intentos+1, msg)
+	}
+}
+
+// backoffColaWA espera antes de reintentar el envío: base·2^(intento-1), con tope.
+func backoffColaWA(intento int) time.Duration {
+	d := colaWABackoffBase
+	for i := 1; i < intento; i++ {
+		d *= 2
+		if d >= colaWABackoffMax {
+			return colaWABackoffMax
+		}
+	}
+	return d
+}
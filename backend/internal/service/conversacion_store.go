@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ConversacionStore memoria de conversación del bot de WhatsApp por (tenant, teléfono).
+// En producción se usa repo.ConversacionWhatsAppRepo (CockroachDB) para que el
+// historial sobreviva a reinicios y se comparta entre réplicas.
+type ConversacionStore interface {
+	// Agregar añade un mensaje; si la conversación expiró (ttl) empieza una nueva.
+	Agregar(ctx context.Context, tenantID uuid.UUID, telefono string, msg ai.Message, maxMensajes int, ttl time.Duration) error
+	// Historial mensajes de la conversación vigente, nil si no existe o expiró.
+	Historial(ctx context.Context, tenantID uuid.UUID, telefono string, ttl time.Duration) ([]ai.Message, error)
+	// MarcarACK registra un ACK y retorna true si ya pasó el cooldown desde el anterior.
+	MarcarACK(ctx context.Context, tenantID uuid.UUID, telefono string, cooldown time.Duration) (bool, error)
+	// AgregarEvidencia guarda un archivo pendiente de adjuntar; false si ya hay maxEvidencias.
+	AgregarEvidencia(ctx context.Context, tenantID uuid.UUID, telefono string, ev model.EvidenciaWhatsApp, maxEvidencias int, ttl time.Duration) (bool, error)
+	// Evidencias archivos pendientes de la conversación vigente.
+	Evidencias(ctx context.Context, tenantID uuid.UUID, telefono string, ttl time.Duration) ([]model.EvidenciaWhatsApp, error)
+	// LimpiarEvidencias descarta los archivos pendientes (ya adjuntados al reclamo).
+	LimpiarEvidencias(ctx context.Context, tenantID uuid.UUID, telefono string) error
+}
+
+// ConversacionStoreMemoria implementación en memoria del proceso (tests y desarrollo).
+// Las conversaciones expiradas se descartan al volver a escribirlas.
+type ConversacionStoreMemoria struct {
+	mu             sync.Mutex
+	conversaciones map[claveConversacion]*conversacionWA
+	ultimoACK      map[claveConversacion]time.Time
+}
+
+type claveConversacion struct {
+	tenantID uuid.UUID
+	telefono string
+}
+
+// conversacionWA almacena el historial de mensajes de un usuario y los
+// archivos que envió.
+type conversacionWA struct {
+	mensajes        []ai.Message
+	evidencias      []model.EvidenciaWhatsApp
+	ultimaActividad time.Time
+}
+
+func NewConversacionStoreMemoria() *ConversacionStoreMemoria {
+	return &ConversacionStoreMemoria{
+		conversaciones: make(map[claveConversacion]*conversacionWA),
+		ultimoACK:      make(map[claveConversacion]time.Time),
+	}
+}
+
+func (m *ConversacionStoreMemoria) Agregar(_ context.Context, tenantID uuid.UUID, telefono string, msg ai.Message, maxMensajes int, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clave := claveConversacion{tenantID, telefono}
+	convo, existe := m.conversaciones[clave]
+	if !existe || time.Since(convo.ultimaActividad) > ttl {
+		convo = &conversacionWA{mensajes: make([]ai.Message, 0)}
+		m.conversaciones[clave] = convo
+	}
+
+	convo.mensajes = append(convo.mensajes, msg)
+	convo.ultimaActividad = time.Now()
+
+	if len(convo.mensajes) > maxMensajes {
+		convo.mensajes = convo.mensajes[len(convo.mensajes)-maxMensajes:]
+	}
+	return nil
+}
+
+func (m *ConversacionStoreMemoria) Historial(_ context.Context, tenantID uuid.UUID, telefono string, ttl time.Duration) ([]ai.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	convo, existe := m.conversaciones[claveConversacion{tenantID, telefono}]
+	if !existe || time.Since(convo.ultimaActividad) > ttl {
+		return nil, nil
+	}
+
+	copia := make([]ai.Message, len(convo.mensajes))
+	copy(copia, convo.mensajes)
+	return copia, nil
+}
+
+func (m *ConversacionStoreMemoria) MarcarACK(_ context.Context, tenantID uuid.UUID, telefono string, cooldown time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clave := claveConversacion{tenantID, telefono}
+	if ultimo, existe := m.ultimoACK[clave]; existe && time.Since(ultimo) < cooldown {
+		return false, nil
+	}
+	m.ultimoACK[clave] = time.Now()
+	return true, nil
+}
+
+func (m *ConversacionStoreMemoria) AgregarEvidencia(_ context.Context, tenantID uuid.UUID, telefono string, ev model.EvidenciaWhatsApp, maxEvidencias int, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clave := claveConversacion{tenantID, telefono}
+	convo, existe := m.conversaciones[clave]
+	if !existe || time.Since(convo.ultimaActividad) > ttl {
+		convo = &conversacionWA{mensajes: make([]ai.Message, 0)}
+		m.conversaciones[clave] = convo
+	}
+	if len(convo.evidencias) >= maxEvidencias {
+		return false, nil
+	}
+
+	convo.evidencias = append(convo.evidencias, ev)
+	convo.ultimaActividad = time.Now()
+	return true, nil
+}
+
+func (m *ConversacionStoreMemoria) Evidencias(_ context.Context, tenantID uuid.UUID, telefono string, ttl time.Duration) ([]model.EvidenciaWhatsApp, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	convo, existe := m.conversaciones[claveConversacion{tenantID, telefono}]
+	if !existe || time.Since(convo.ultimaActividad) > ttl || len(convo.evidencias) == 0 {
+		return nil, nil
+	}
+
+	copia := make([]model.EvidenciaWhatsApp, len(convo.evidencias))
+	copy(copia, convo.evidencias)
+	return copia, nil
+}
+
+func (m *ConversacionStoreMemoria) LimpiarEvidencias(_ context.Context, tenantID uuid.UUID, telefono string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if convo, existe := m.conversaciones[claveConversacion{tenantID, telefono}]; existe {
+		convo.evidencias = nil
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ventanaDuplicados       = 30 * 24 * time.Hour // antigüedad máxima de un duplicado
+	maxCandidatosDuplicado  = 20
+	maxHistorialConsumidor  = 50
+	minPalabrasTextoComun   = 3
+	umbralSimilitudRelato   = 0.5 // palabras en común / palabras del relato más corto
+	digitosTelefonoCompara  = 9   // celular peruano sin código de país
+	minDigitosTelefonoValid = 7
+)
+
+// DuplicadoService detecta al registrar reclamos probablemente duplicados
+// (mismo consumidor por web, WhatsApp y API) y permite al equipo vincularlos,
+// fusionarlos o descartarlos. También arma el historial del consumidor.
+type DuplicadoService struct {
+	relacionRepo  *repo.ReclamoRelacionRepo
+	reclamoRepo   *repo.ReclamoRepo
+	historialRepo *repo.HistorialRepo
+	tx            *repo.Transactor
+	outbox        *OutboxService
+}
+
+func NewDuplicadoService(
+	relacionRepo *repo.ReclamoRelacionRepo,
+	reclamoRepo *repo.ReclamoRepo,
+	historialRepo *repo.HistorialRepo,
+	tx *repo.Transactor,
+	outbox *OutboxService,
+) *DuplicadoService {
+	return &DuplicadoService{
+		relacionRepo:  relacionRepo,
+		reclamoRepo:   reclamoRepo,
+		historialRepo: historialRepo,
+		tx:            tx,
+		outbox:        outbox,
+	}
+}
+
+// Detectar registra como PROBABLE los reclamos recientes del tenant que
+// parecen el mismo caso que rec. Se llama después de crear el reclamo: un
+// error aquí no debe afectar el registro. Retorna cuántos se marcaron.
+func (s *DuplicadoService) Detectar(ctx context.Context, rec *model.Reclamo) (int, error) {
+	desde := rec.FechaRegistro.Add(-ventanaDuplicados)
+	candidatos, err := s.relacionRepo.Coincidentes(ctx, rec.TenantID, rec.ID, criteriosDe(rec), &desde, nil, maxCandidatosDuplicado)
+	if err != nil {
+		return 0, fmt.Errorf("duplicado_service.Detectar: %w", err)
+	}
+
+	relato := relatoDe(rec)
+	marcados := 0
+	for _, c := range candidatos {
+		motivos := c.Coincidencias
+		if similitudRelato(relato, c.Relato) {
+			motivos = append(motivos, model.CoincideTexto)
+		}
+		if !model.EsDuplicadoProbable(motivos) {
+			continue
+		}
+		rel := &model.ReclamoRelacion{
+			TenantModel:   model.TenantModel{TenantID: rec.TenantID},
+			ReclamoID:     rec.ID,
+			RelacionadoID: c.ID,
+			Motivos:       motivos,
+			Puntaje:       model.PuntajeDuplicado(motivos),
+			Estado:        model.RelacionProbable,
+		}
+		if err := s.relacionRepo.Create(ctx, rel); err != nil {
+			return marcados, fmt.Errorf("duplicado_service.Detectar: %w", err)
+		}
+		marcados++
+	}
+	return marcados, nil
+}
+
+// Listar duplicados probables y vínculos del reclamo.
+func (s *DuplicadoService) Listar(ctx context.Context, tenantID, reclamoID uuid.UUID, sedeID *uuid.UUID) ([]model.ReclamoRelacion, error) {
+	if _, err := s.obtener(ctx, tenantID, reclamoID, sedeID); err != nil {
+		return nil, err
+	}
+	relaciones, err := s.relacionRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("duplicado_service.Listar: %w", err)
+	}
+	return relaciones, nil
+}
+
+// HistorialConsumidor otros reclamos del mismo consumidor (documento, email o
+// teléfono), sin límite de antigüedad. sedeID != nil limita a esa sede.
+func (s *DuplicadoService) HistorialConsumidor(ctx context.Context, tenantID, reclamoID uuid.UUID, sedeID *uuid.UUID) ([]model.ReclamoConsumidor, error) {
+	rec, err := s.obtener(ctx, tenantID, reclamoID, sedeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// El número de pedido no identifica al consumidor
+	criterios := criteriosDe(rec)
+	criterios.NumeroPedido = ""
+	reclamos, err := s.relacionRepo.Coincidentes(ctx, tenantID, reclamoID, criterios, nil, sedeID, maxHistorialConsumidor)
+	if err != nil {
+		return nil, fmt.Errorf("duplicado_service.HistorialConsumidor: %w", err)
+	}
+	return reclamos, nil
+}
+
+// Vincular relaciona dos reclamos que siguen abiertos por separado y lo deja
+// en el historial de ambos.
+func (s *DuplicadoService) Vincular(ctx context.Context, tenantID, reclamoID, relacionadoID, userID uuid.UUID, sedeID *uuid.UUID, ip string) error {
+	rec, otro, err := s.obtenerPar(ctx, tenantID, reclamoID, relacionadoID, sedeID)
+	if err != nil {
+		return err
+	}
+
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.relacionRepo.WithTx(tx).Resolver(ctx, tenantID, rec.ID, otro.ID, model.RelacionVinculada, userID); err != nil {
+			return err
+		}
+		historial := s.historialRepo.WithTx(tx)
+		if err := historial.Create(ctx, entradaRelacion(rec, rec.Estado, model.AccionVinculacion,
+			fmt.Sprintf("Vinculado con %s", otro.CodigoReclamo), userID, ip)); err != nil {
+			return err
+		}
+		return historial.Create(ctx, entradaRelacion(otro, otro.Estado, model.AccionVinculacion,
+			fmt.Sprintf("Vinculado con %s", rec.CodigoReclamo), userID, ip))
+	})
+	if err != nil {
+		return fmt.Errorf("duplicado_service.Vincular: %w", err)
+	}
+	return nil
+}
+
+// Fusionar cierra duplicadoID y lo deja atendido en principalID. Ambos
+// conservan su historial; el cierre y la fusión quedan registrados en los dos.
+func (s *DuplicadoService) Fusionar(ctx context.Context, tenantID, duplicadoID, principalID, userID uuid.UUID, sedeID *uuid.UUID, ip string) error {
+	dup, principal, err := s.obtenerPar(ctx, tenantID, duplicadoID, principalID, sedeID)
+	if err != nil {
+		return err
+	}
+	if !model.PuedeFusionarse(dup.Estado) {
+		return apperror.ErrFusionNoPermitida.Withf(dup.CodigoReclamo, dup.Estado)
+	}
+	if !model.PuedeFusionarse(principal.Estado) {
+		return apperror.ErrFusionNoPermitida.Withf(principal.CodigoReclamo, principal.Estado)
+	}
+
+	// El consumidor del duplicado se entera del cierre como en cualquier cambio de estado
+	notifs, err := s.outbox.NotificacionesConsumidor(ctx, tenantID, dup, model.NotifCambioEstado, model.PayloadNotificacion{
+		Codigo:        dup.CodigoReclamo,
+		NombreCliente: dup.NombreCompleto,
+		Estado:        model.EstadoCerrado,
+	})
+	if err != nil {
+		return fmt.Errorf("duplicado_service.Fusionar: %w", err)
+	}
+
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		reclamoTx, relacionTx := s.reclamoRepo.WithTx(tx), s.relacionRepo.WithTx(tx)
+
+		// El principal se vuelve a leer dentro de la transacción: si otro
+		// usuario lo cerró o lo fusionó entretanto, no se fusiona en él
+		actual, err := reclamoTx.GetByID(ctx, tenantID, principal.ID)
+		if err != nil {
+			return err
+		}
+		if actual == nil {
+			return apperror.ErrNotFound
+		}
+		if !model.PuedeFusionarse(actual.Estado) {
+			return apperror.ErrFusionNoPermitida.Withf(principal.CodigoReclamo, actual.Estado)
+		}
+		fusionadoEn, err := relacionTx.FusionadoEn(ctx, tenantID, principal.ID)
+		if err != nil {
+			return err
+		}
+		if fusionadoEn != nil {
+			return apperror.ErrFusionNoPermitida.Withf(principal.CodigoReclamo, "FUSIONADO")
+		}
+
+		if err := relacionTx.Resolver(ctx, tenantID, dup.ID, principal.ID, model.RelacionFusionada, userID); err != nil {
+			return err
+		}
+		// Condicionado al estado leído: un cambio que se adelantó aborta la fusión
+		if err := reclamoTx.UpdateEstado(ctx, tenantID, dup.ID, dup.Estado, model.EstadoCerrado, &userID); err != nil {
+			return err
+		}
+		historial := s.historialRepo.WithTx(tx)
+		if err := historial.Create(ctx, entradaRelacion(dup, model.EstadoCerrado, model.AccionFusion,
+			fmt.Sprintf("Fusionado en %s: se atiende en ese reclamo", principal.CodigoReclamo), userID, ip)); err != nil {
+			return err
+		}
+		if err := historial.Create(ctx, entradaRelacion(principal, principal.Estado, model.AccionFusion,
+			fmt.Sprintf("Se fusionó %s en este reclamo", dup.CodigoReclamo), userID, ip)); err != nil {
+			return err
+		}
+		return s.outbox.EncolarTx(ctx, tx, notifs...)
+	})
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return fmt.Errorf("duplicado_service.Fusionar: %w", err)
+	}
+	s.outbox.Despertar()
+	return nil
+}
+
+// FusionadoEn reclamo en el que se fusionó reclamoID, o nil. Un reclamo
+// fusionado se atiende en el principal y no se reabre.
+func (s *DuplicadoService) FusionadoEn(ctx context.Context, tenantID, reclamoID uuid.UUID) (*uuid.UUID, error) {
+	principalID, err := s.relacionRepo.FusionadoEn(ctx, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("duplicado_service.FusionadoEn: %w", err)
+	}
+	return principalID, nil
+}
+
+// Descartar marca que el duplicado probable no es el mismo caso.
+func (s *DuplicadoService) Descartar(ctx context.Context, tenantID, reclamoID, relacionadoID, userID uuid.UUID, sedeID *uuid.UUID) error {
+	if _, err := s.obtener(ctx, tenantID, reclamoID, sedeID); err != nil {
+		return err
+	}
+	ok, err := s.relacionRepo.Descartar(ctx, tenantID, reclamoID, relacionadoID, userID)
+	if err != nil {
+		return fmt.Errorf("duplicado_service.Descartar: %w", err)
+	}
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+func (s *DuplicadoService) obtener(ctx context.Context, tenantID, reclamoID uuid.UUID, sedeID *uuid.UUID) (*model.Reclamo, error) {
+	rec, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("duplicado_service.obtener: %w", err)
+	}
+	if rec == nil {
+		return nil, apperror.ErrNotFound
+	}
+	if sedeID != nil && (!rec.SedeID.Valid || rec.SedeID.UUID != *sedeID) {
+		return nil, apperror.ErrSedeNoPermitida
+	}
+	return rec, nil
+}
+
+func (s *DuplicadoService) obtenerPar(ctx context.Context, tenantID, a, b uuid.UUID, sedeID *uuid.UUID) (*model.Reclamo, *model.Reclamo, error) {
+	if a == b {
+		return nil, nil, apperror.ErrVinculoMismoReclamo
+	}
+	recA, err := s.obtener(ctx, tenantID, a, sedeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	recB, err := s.obtener(ctx, tenantID, b, sedeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recA, recB, nil
+}
+
+func entradaRelacion(rec *model.Reclamo, estadoNuevo, tipo, comentario string, userID uuid.UUID, ip string) *model.Historial {
+	return &model.Historial{
+		TenantModel:    model.TenantModel{TenantID: rec.TenantID},
+		ReclamoID:      rec.ID,
+		EstadoAnterior: model.NullString{NullString: sql.NullString{String: rec.Estado, Valid: true}},
+		EstadoNuevo:    estadoNuevo,
+		TipoAccion:     tipo,
+		Comentario:     model.NullString{NullString: sql.NullString{String: comentario, Valid: true}},
+		UsuarioAccion:  model.NullUUID{UUID: userID, Valid: true},
+		IPAddress:      model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
+	}
+}
+
+// criteriosDe normaliza los datos de contacto del reclamo para compararlos.
+func criteriosDe(rec *model.Reclamo) repo.CriteriosCoincidencia {
+	c := repo.CriteriosCoincidencia{
+		TipoDocumento:   rec.TipoDocumento,
+		NumeroDocumento: strings.TrimSpace(rec.NumeroDocumento),
+		Email:           normalizarEmail(rec.Email),
+		Telefono:        normalizarTelefono(rec.Telefono),
+	}
+	if rec.NumeroPedido.Valid {
+		c.NumeroPedido = strings.ToLower(strings.TrimSpace(rec.NumeroPedido.String))
+	}
+	return c
+}
+
+// normalizarEmail email en minúsculas y sin espacios.
+func normalizarEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// normalizarTelefono últimos 9 dígitos del teléfono, o "" si tiene muy pocos
+// dígitos para identificar a alguien.
+func normalizarTelefono(telefono string) string {
+	digitos := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, telefono)
+	if len(digitos) < minDigitosTelefonoValid {
+		return ""
+	}
+	if len(digitos) > digitosTelefonoCompara {
+		digitos = digitos[len(digitos)-digitosTelefonoCompara:]
+	}
+	return digitos
+}
+
+// relatoDe mismos campos que repo.Coincidentes compara del otro reclamo.
+func relatoDe(rec *model.Reclamo) string {
+	return rec.DetalleReclamo + " " + rec.PedidoConsumidor + " " + rec.DescripcionSituacion.String
+}
+
+// similitudRelato indica si dos relatos comparten la mayoría de sus palabras
+// significativas (coeficiente de solapamiento sobre el relato más corto).
+func similitudRelato(a, b string) bool {
+	pa, pb := palabrasRelato(a), palabrasRelato(b)
+	if len(pa) > len(pb) {
+		pa, pb = pb, pa
+	}
+	if len(pa) < minPalabrasTextoComun {
+		return false
+	}
+	comunes := 0
+	for p := range pa {
+		if pb[p] {
+			comunes++
+		}
+	}
+	return comunes >= minPalabrasTextoComun && float64(comunes)/float64(len(pa)) >= umbralSimilitudRelato
+}
+
+func palabrasRelato(texto string) map[string]bool {
+	palabras := make(map[string]bool)
+	for _, p := range strings.FieldsFunc(strings.ToLower(texto), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if len([]rune(p)) >= minRunasTerminoBusqueda {
+			palabras[p] = true
+		}
+	}
+	return palabras
+}
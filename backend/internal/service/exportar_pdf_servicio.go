@@ -179,4 +179,203 @@ func truncar(texto string, max int) string {
 		return texto[:max] + "..."
 	}
 	return texto
-}
\ No newline at end of file
+}
+// ─── HOJA DE RECLAMACIÓN ────────────────────────────────────────────────────
+
+// GenerarHojaReclamacion genera la hoja de reclamación individual que se entrega
+// al consumidor (formato del Libro de Reclamaciones, Ley N° 29571).
+// respuesta puede ser nil: la sección 4 queda pendiente hasta que el proveedor responda.
+func (s *ExportarPDFServicio) GenerarHojaReclamacion(reclamo *model.Reclamo, respuesta *model.Respuesta) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Arial", "I", 7)
+		pdf.SetTextColor(150, 150, 150)
+		pdf.CellFormat(0, 5, tr("Hoja de Reclamación "+reclamo.CodigoReclamo), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Pag %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	// --- ENCABEZADO ---
+	pdf.SetFillColor(26, 86, 219)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 10, tr("LIBRO DE RECLAMACIONES"), "", 1, "C", true, 0, "")
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 7, tr("HOJA DE RECLAMACIÓN"), "", 1, "C", true, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(95, 6, tr("N° "+reclamo.CodigoReclamo), "1", 0, "L", false, 0, "")
+	pdf.CellFormat(95, 6, "Fecha: "+reclamo.FechaRegistro.Format("02/01/2006 15:04"), "1", 1, "R", false, 0, "")
+	pdf.Ln(2)
+
+	// --- PROVEEDOR (snapshot al momento del registro) ---
+	sedeDir := reclamo.SedeDireccion.String
+	if sedeDir == "" {
+		sedeDir = reclamo.DireccionProveedor.String
+	}
+	s.seccionHoja(pdf, tr, "PROVEEDOR")
+	s.campoHoja(pdf, tr, "Razón social", reclamo.RazonSocialProveedor.String)
+	s.campoHoja(pdf, tr, "RUC", reclamo.RUCProveedor.String)
+	if reclamo.SedeNombre.Valid && reclamo.SedeNombre.String != "" {
+		s.campoHoja(pdf, tr, "Establecimiento", reclamo.SedeNombre.String)
+	}
+	s.campoHoja(pdf, tr, "Dirección", sedeDir)
+	pdf.Ln(3)
+
+	// --- 1. CONSUMIDOR ---
+	ubicacion := unirNoVacios(", ", reclamo.Distrito.String, reclamo.Provincia.String, reclamo.Departamento.String)
+	domicilio := unirNoVacios(" - ", reclamo.Domicilio.String, ubicacion)
+
+	s.seccionHoja(pdf, tr, "1. IDENTIFICACIÓN DEL CONSUMIDOR RECLAMANTE")
+	s.campoHoja(pdf, tr, "Nombre", reclamo.NombreCompleto)
+	s.campoHoja(pdf, tr, "Documento", reclamo.TipoDocumento+" "+reclamo.NumeroDocumento)
+	s.campoHoja(pdf, tr, "Domicilio", domicilio)
+	s.campoHoja(pdf, tr, "Teléfono", reclamo.Telefono)
+	s.campoHoja(pdf, tr, "Email", reclamo.Email)
+	if reclamo.MenorDeEdad {
+		s.campoHoja(pdf, tr, "Padre/madre o apoderado", reclamo.NombreApoderado.String)
+	}
+	pdf.Ln(3)
+
+	// --- 2. BIEN CONTRATADO ---
+	monto := "-"
+	if reclamo.MontoReclamado.Valid && reclamo.MontoReclamado.Float64 > 0 {
+		monto = fmt.Sprintf("S/ %.2f", reclamo.MontoReclamado.Float64)
+	}
+	s.seccionHoja(pdf, tr, "2. IDENTIFICACIÓN DEL BIEN CONTRATADO")
+	s.campoHoja(pdf, tr, "Tipo", reclamo.TipoBien.String)
+	s.campoHoja(pdf, tr, "Monto reclamado", monto)
+	s.campoHoja(pdf, tr, "Descripción", reclamo.DescripcionBien)
+	if reclamo.NumeroPedido.Valid && reclamo.NumeroPedido.String != "" {
+		s.campoHoja(pdf, tr, "N° de pedido", reclamo.NumeroPedido.String)
+	}
+	pdf.Ln(3)
+
+	// --- 3. DETALLE ---
+	s.seccionHoja(pdf, tr, "3. DETALLE DE LA RECLAMACIÓN Y PEDIDO DEL CONSUMIDOR")
+	s.campoHoja(pdf, tr, "Tipo", reclamo.TipoSolicitud)
+	s.campoHoja(pdf, tr, "Fecha del incidente", reclamo.FechaIncidente.Format("02/01/2006"))
+	if reclamo.TipoSolicitud == model.TipoQueja {
+		s.campoHoja(pdf, tr, "Área", reclamo.AreaQueja.String)
+		s.campoHoja(pdf, tr, "Situación", reclamo.DescripcionSituacion.String)
+	}
+	s.campoHoja(pdf, tr, "Detalle", reclamo.DetalleReclamo)
+	s.campoHoja(pdf, tr, "Pedido", reclamo.PedidoConsumidor)
+	s.firmaHoja(pdf, tr, reclamo.FirmaDigital)
+	pdf.Ln(3)
+
+	// --- 4. RESPUESTA DEL PROVEEDOR ---
+	limite := "-"
+	if reclamo.FechaLimiteRespuesta.Valid {
+		limite = reclamo.FechaLimiteRespuesta.Time.Format("02/01/2006")
+	}
+	s.seccionHoja(pdf, tr, "4. OBSERVACIONES Y ACCIONES ADOPTADAS POR EL PROVEEDOR")
+	s.campoHoja(pdf, tr, "Fecha límite de respuesta", limite)
+	if respuesta != nil {
+		s.campoHoja(pdf, tr, "Fecha de respuesta", respuesta.FechaRespuesta.Format("02/01/2006"))
+		s.campoHoja(pdf, tr, "Respuesta", respuesta.RespuestaEmpresa)
+		if respuesta.AccionTomada.Valid && respuesta.AccionTomada.String != "" {
+			s.campoHoja(pdf, tr, "Acción adoptada", respuesta.AccionTomada.String)
+		}
+	} else {
+		s.campoHoja(pdf, tr, "Respuesta", "Pendiente")
+	}
+	pdf.Ln(4)
+
+	// --- NOTAS LEGALES ---
+	pdf.SetFont("Arial", "", 7.5)
+	pdf.SetTextColor(90, 90, 90)
+	notas := "RECLAMO: Disconformidad relacionada a los productos o servicios.\n" +
+		"QUEJA: Disconformidad no relacionada a los productos o servicios; o, malestar o descontento respecto a la atención al público.\n" +
+		"La formulación del reclamo no impide acudir a otras vías de solución de controversias ni es requisito previo para interponer una denuncia ante el INDECOPI.\n" +
+		"El proveedor deberá dar respuesta al reclamo dentro del plazo establecido en el Código de Protección y Defensa del Consumidor (Ley N° 29571)."
+	pdf.MultiCell(0, 4, tr(notas), "T", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("exportar_pdf: error generando hoja de reclamación: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *ExportarPDFServicio) seccionHoja(pdf *fpdf.Fpdf, tr func(string) string, titulo string) {
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 7, tr(" "+titulo), "1", 1, "L", true, 0, "")
+}
+
+// campoHoja dibuja una fila etiqueta | valor; el valor se ajusta en varias líneas.
+func (s *ExportarPDFServicio) campoHoja(pdf *fpdf.Fpdf, tr func(string) string, etiqueta, valor string) {
+	if valor == "" {
+		valor = "-"
+	}
+	const anchoEtiqueta = 50
+
+	pdf.SetFont("Arial", "", 9)
+	lineas := pdf.SplitLines([]byte(tr(valor)), 190-anchoEtiqueta-2)
+	alto := float64(len(lineas)) * 5
+	if pdf.GetY()+alto > 282 {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(anchoEtiqueta, alto, tr(etiqueta), "LB", 0, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(0, 5, tr(valor), "RB", "L", false)
+}
+
+// firmaHoja incrusta la firma del consumidor (data URL PNG/JPEG del canvas).
+// Si no hay firma o la imagen no es válida, deja el recuadro vacío.
+func (s *ExportarPDFServicio) firmaHoja(pdf *fpdf.Fpdf, tr func(string) string, firma model.NullString) {
+	const altoFirma = 25
+	if pdf.GetY()+altoFirma+5 > 282 {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(50, altoFirma, tr("Firma del consumidor"), "LB", 0, "L", false, 0, "")
+	x, y := pdf.GetXY()
+	pdf.CellFormat(0, altoFirma, "", "RB", 1, "L", false, 0, "")
+
+	if !firma.Valid || firma.String == "" {
+		return
+	}
+	mime, data, ok := parseDataURL(firma.String)
+	if !ok {
+		return
+	}
+	tipo := "PNG"
+	if strings.Contains(mime, "jpeg") || strings.Contains(mime, "jpg") {
+		tipo = "JPG"
+	}
+
+	opciones := fpdf.ImageOptions{ImageType: tipo}
+	pdf.RegisterImageOptionsReader("firma", opciones, bytes.NewReader(data))
+	if !pdf.Ok() {
+		// Una firma corrupta no debe impedir entregar la hoja
+		pdf.ClearError()
+		return
+	}
+	pdf.ImageOptions("firma", x+2, y+1, 0, altoFirma-2, false, opciones, 0, "")
+}
+
+// unirNoVacios concatena las partes no vacías con el separador.
+func unirNoVacios(sep string, partes ...string) string {
+	var out []string
+	for _, p := range partes {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
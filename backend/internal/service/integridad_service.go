@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// IntegridadService verifica las cadenas de hash de reclamos e historial
+// (prueba ante el regulador de que un registro no fue alterado).
+type IntegridadService struct {
+	integridadRepo *repo.IntegridadRepo
+}
+
+func NewIntegridadService(integridadRepo *repo.IntegridadRepo) *IntegridadService {
+	return &IntegridadService{integridadRepo: integridadRepo}
+}
+
+// Verificar recorre ambas cadenas del tenant y reporta el primer eslabón roto de cada una.
+func (s *IntegridadService) Verificar(ctx context.Context, tenantID uuid.UUID) (*model.VerificacionIntegridad, error) {
+	reclamos, err := s.integridadRepo.VerificarReclamos(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("integridad_service.Verificar: %w", err)
+	}
+	historial, err := s.integridadRepo.VerificarHistorial(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("integridad_service.Verificar: %w", err)
+	}
+
+	return &model.VerificacionIntegridad{
+		TenantID:          tenantID,
+		Integra:           reclamos.Integra && historial.Integra,
+		Reclamos:          reclamos,
+		Historial:         historial,
+		FechaVerificacion: time.Now(),
+	}, nil
+}
+
+// VerificarTodos verifica todos los tenants que tienen cadena (uso desde CLI).
+func (s *IntegridadService) VerificarTodos(ctx context.Context) ([]model.VerificacionIntegridad, error) {
+	tenants, err := s.integridadRepo.ListarTenantsConCadena(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("integridad_service.VerificarTodos: %w", err)
+	}
+
+	resultados := make([]model.VerificacionIntegridad, 0, len(tenants))
+	for _, tenantID := range tenants {
+		v, err := s.Verificar(ctx, tenantID)
+		if err != nil {
+			return resultados, err
+		}
+		resultados = append(resultados, *v)
+	}
+	return resultados, nil
+}
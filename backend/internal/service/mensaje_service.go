@@ -13,18 +13,20 @@ import (
 )
 
 type MensajeService struct {
-	mensajeRepo  *repo.MensajeRepo
-	reclamoRepo  *repo.ReclamoRepo
-	tenantRepo   *repo.TenantRepo
-	notifService *NotificacionService
+	mensajeRepo *repo.MensajeRepo
+	reclamoRepo *repo.ReclamoRepo
+	tx          *repo.Transactor
+	outbox      *OutboxService
+	adjuntoSvc  *AdjuntoService
 }
 
-func NewMensajeService(mensajeRepo *repo.MensajeRepo, reclamoRepo *repo.ReclamoRepo, tenantRepo *repo.TenantRepo, notifService *NotificacionService) *MensajeService {
+func NewMensajeService(mensajeRepo *repo.MensajeRepo, reclamoRepo *repo.ReclamoRepo, tx *repo.Transactor, outbox *OutboxService, adjuntoSvc *AdjuntoService) *MensajeService {
 	return &MensajeService{
-		mensajeRepo:  mensajeRepo,
-		reclamoRepo:  reclamoRepo,
-		tenantRepo:   tenantRepo,
-		notifService: notifService,
+		mensajeRepo: mensajeRepo,
+		reclamoRepo: reclamoRepo,
+		tx:          tx,
+		outbox:      outbox,
+		adjuntoSvc:  adjuntoSvc,
 	}
 }
 
@@ -51,33 +53,29 @@ func (s *MensajeService) Crear(ctx context.Context, tenantID, reclamoID uuid.UUI
 		ArchivoNombre: model.NullString{NullString: sql.NullString{String: archivoNombre, Valid: archivoNombre != ""}},
 	}
 
-	if err := s.mensajeRepo.Create(ctx, msg); err != nil {
+	// Mensaje + notificación al cliente (si es de la EMPRESA/ADMIN) en una transacción
+	var notifs []model.NotificacionOutbox
+	if tipoMensaje != "CLIENTE" {
+		notifs, err = s.outbox.NotificacionesConsumidor(ctx, tenantID, reclamo, model.NotifMensajeNuevo, model.PayloadNotificacion{
+			Codigo:        reclamo.CodigoReclamo,
+			NombreCliente: reclamo.NombreCompleto,
+			Texto:         texto,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mensaje_service.Crear: %w", err)
+		}
+	}
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.mensajeRepo.WithTx(tx).Create(ctx, msg); err != nil {
+			return err
+		}
+		return s.outbox.EncolarTx(ctx, tx, notifs...)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("mensaje_service.Crear: %w", err)
 	}
-
-	// Notificar al cliente si el mensaje es de la EMPRESA/ADMIN
-	if tipoMensaje != "CLIENTE" && reclamo.Email != "" {
-		go func() {
-			bgCtx := context.Background()
-			t, _ := s.tenantRepo.GetByTenantID(bgCtx, tenantID)
-
-			// Fail-safe
-			if t == nil {
-				t = &model.Tenant{RazonSocial: "Soporte", ColorPrimario: "#1a56db", Slug: "portal"}
-			}
-
-			errEnvio := s.notifService.EnviarNotificacionMensajeNuevo(
-				bgCtx,
-				reclamo.Email,
-				t,
-				reclamo.CodigoReclamo,
-				reclamo.NombreCompleto,
-				texto,
-			)
-			if errEnvio != nil {
-				fmt.Printf("[ERROR SMTP Mensaje] %v\n", errEnvio)
-			}
-		}()
+	if len(notifs) > 0 {
+		s.outbox.Despertar()
 	}
 
 	return msg, nil
@@ -107,6 +105,43 @@ func (s *MensajeService) CrearPublico(ctx context.Context, tenantID, reclamoID u
 	return msg, nil
 }
 
+// CrearPublicoConAdjunto mensaje del consumidor con un archivo subido.
+// El archivo se guarda en el storage y el mensaje apunta a su URL pública de descarga.
+func (s *MensajeService) CrearPublicoConAdjunto(ctx context.Context, tenant *model.Tenant, reclamo *model.Reclamo, texto string, archivo ArchivoSubido) (*model.Mensaje, error) {
+	archivos := []ArchivoSubido{archivo}
+	if err := s.adjuntoSvc.VerificarCuota(ctx, tenant.TenantID, archivos); err != nil {
+		return nil, err
+	}
+
+	adjuntos, err := s.adjuntoSvc.Almacenar(ctx, tenant.TenantID, reclamo.ID,
+		model.AdjuntoEntidadMensaje, model.AdjuntoOrigenPublico, uuid.Nil, archivos)
+	if err != nil {
+		return nil, fmt.Errorf("mensaje_service.CrearPublicoConAdjunto: %w", err)
+	}
+	adj := adjuntos[0]
+
+	msg := &model.Mensaje{
+		TenantModel:   model.TenantModel{TenantID: tenant.TenantID},
+		ReclamoID:     reclamo.ID,
+		TipoMensaje:   "CLIENTE",
+		MensajeTexto:  texto,
+		ArchivoURL:    model.NullString{NullString: sql.NullString{String: URLPublicaAdjunto(tenant.Slug, reclamo.CodigoReclamo, adj.ID), Valid: true}},
+		ArchivoNombre: model.NullString{NullString: sql.NullString{String: adj.NombreOriginal, Valid: true}},
+	}
+
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.mensajeRepo.WithTx(tx).Create(ctx, msg); err != nil {
+			return err
+		}
+		return s.adjuntoSvc.RegistrarTx(ctx, tx, adjuntos, msg.ID)
+	})
+	if err != nil {
+		s.adjuntoSvc.Descartar(adjuntos)
+		return nil, fmt.Errorf("mensaje_service.CrearPublicoConAdjunto: %w", err)
+	}
+	return msg, nil
+}
+
 func (s *MensajeService) MarcarLeidos(ctx context.Context, tenantID, reclamoID uuid.UUID, tipo string) error {
 	reclamo, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
 	if err != nil {
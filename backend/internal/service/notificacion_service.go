@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/smtp"
+	"net/url"
 	"strings"
 	"time"
 
@@ -191,9 +192,10 @@ func writeWrapped(sb *strings.Builder, content string) {
 // ─── EMAILS ─────────────────────────────────────────────────────────────────
 
 // EnviarNotificacionReclamo envía la confirmación de registro al CLIENTE.
+// Si hojaPDF no está vacío se adjunta la copia de la hoja de reclamación.
 func (s *NotificacionService) EnviarNotificacionReclamo(
 	ctx context.Context, paraEmail string, tenant *model.Tenant,
-	codigoReclamo, nombreCliente, fecha string,
+	codigoReclamo, nombreCliente, fecha string, hojaPDF []byte,
 ) error {
 	if s.cfg.User == "" || s.cfg.Pass == "" {
 		return nil
@@ -247,6 +249,13 @@ func (s *NotificacionService) EnviarNotificacionReclamo(
 	inner.WriteString(`  Puede hacer seguimiento de su caso en nuestro` + "\r\n")
 	inner.WriteString(`  portal web utilizando el codigo proporcionado.` + "\r\n")
 	inner.WriteString(`</p>` + "\r\n")
+	if len(hojaPDF) > 0 {
+		inner.WriteString(`<p style="margin: 16px 0 0 0; font-size: 14px;` + "\r\n")
+		inner.WriteString(`  color: #6b7280; line-height: 1.6;">` + "\r\n")
+		inner.WriteString(`  Adjunto encontrara la copia de su hoja de` + "\r\n")
+		inner.WriteString(`  reclamacion en formato PDF.` + "\r\n")
+		inner.WriteString(`</p>` + "\r\n")
+	}
 
 	razon := "La Empresa"
 	if tenant != nil {
@@ -255,7 +264,11 @@ func (s *NotificacionService) EnviarNotificacionReclamo(
 	footer := "Este correo fue enviado por <strong>" + razon + "</strong>.<br>\r\nSi no reconoce esta solicitud, puede ignorar este mensaje."
 
 	cuerpo := buildEmail(color, logoHTML, inner.String(), footer)
-	return s.enviarEmailBase(paraEmail, asunto, cuerpo, nil, "", b.logoData, b.logoMIME)
+	nombreArchivo := ""
+	if len(hojaPDF) > 0 {
+		nombreArchivo = "Hoja_Reclamacion_" + codigoReclamo + ".pdf"
+	}
+	return s.enviarEmailBase(paraEmail, asunto, cuerpo, hojaPDF, nombreArchivo, b.logoData, b.logoMIME)
 }
 
 // EnviarNotificacionNuevoReclamoEmpresa notifica a la empresa que llegó un reclamo nuevo.
@@ -555,6 +568,149 @@ func (s *NotificacionService) EnviarNotificacionMensajeNuevo(
 	return s.enviarEmailBase(emailDestino, asunto, cuerpo, nil, "", b.logoData, b.logoMIME)
 }
 
+// EnviarAlertaSLA avisa al equipo interno de reclamos por vencer o vencidos.
+// Con un solo reclamo es un aviso puntual; con varios es el resumen (modo digest).
+func (s *NotificacionService) EnviarAlertaSLA(
+	ctx context.Context, emailDestino string, tenant *model.Tenant,
+	alertas []model.AlertaSLA,
+) error {
+	if s.cfg.User == "" || s.cfg.Pass == "" || len(alertas) == 0 {
+		return nil
+	}
+
+	b := getBranding(tenant)
+	color, logoHTML := b.color, b.logoHTML
+
+	vencidos := 0
+	for _, a := range alertas {
+		if a.Tipo == model.AlertaSLAVencido {
+			vencidos++
+		}
+	}
+
+	var asunto string
+	if len(alertas) == 1 {
+		a := alertas[0]
+		if a.Tipo == model.AlertaSLAVencido {
+			asunto = "Plazo VENCIDO - " + a.Codigo
+		} else {
+			asunto = fmt.Sprintf("Plazo por vencer (%d dias) - %s", a.DiasRestantes, a.Codigo)
+		}
+	} else {
+		asunto = fmt.Sprintf("Resumen SLA: %d casos por vencer, %d vencidos", len(alertas)-vencidos, vencidos)
+	}
+
+	var inner strings.Builder
+	inner.WriteString(`<h3 style="margin: 0 0 8px 0; font-size: 18px;` + "\r\n")
+	inner.WriteString(`  color: #111827;">Casos que requieren atencion</h3>` + "\r\n")
+	inner.WriteString(`<p style="margin: 0 0 24px 0; font-size: 15px;` + "\r\n")
+	inner.WriteString(`  color: #4b5563; line-height: 1.6;">` + "\r\n")
+	inner.WriteString(`  Los siguientes casos estan proximos a vencer o ya` + "\r\n")
+	inner.WriteString(`  superaron el plazo legal de respuesta.` + "\r\n")
+	inner.WriteString(`</p>` + "\r\n")
+	inner.WriteString(`<table role="presentation" width="100%"` + "\r\n")
+	inner.WriteString(`  cellspacing="0" cellpadding="0" border="0"` + "\r\n")
+	inner.WriteString(`  style="border-radius: 8px; border: 1px solid #e5e7eb;` + "\r\n")
+	inner.WriteString(`  font-size: 13px; color: #374151;">` + "\r\n")
+	inner.WriteString(`  <tr style="background-color: #f9fafb;">` + "\r\n")
+	inner.WriteString(`    <td style="padding: 10px 12px; font-weight: 600;">Codigo</td>` + "\r\n")
+	inner.WriteString(`    <td style="padding: 10px 12px; font-weight: 600;">Consumidor</td>` + "\r\n")
+	inner.WriteString(`    <td style="padding: 10px 12px; font-weight: 600;">Vence</td>` + "\r\n")
+	inner.WriteString(`    <td style="padding: 10px 12px; font-weight: 600;">Estado</td>` + "\r\n")
+	inner.WriteString(`  </tr>` + "\r\n")
+
+	for _, a := range alertas {
+		badgeColor, badgeBg, etiqueta := "#d97706", "#fef3c7", fmt.Sprintf("%d dias", a.DiasRestantes)
+		if a.Tipo == model.AlertaSLAVencido {
+			badgeColor, badgeBg, etiqueta = "#dc2626", "#fee2e2", "VENCIDO"
+		}
+		sede := ""
+		if a.SedeNombre != "" {
+			sede = `<br><span style="color: #9ca3af;">` + a.SedeNombre + `</span>`
+		}
+		inner.WriteString(`  <tr style="border-top: 1px solid #e5e7eb;">` + "\r\n")
+		inner.WriteString(`    <td style="padding: 10px 12px; font-weight: 700;` + "\r\n")
+		inner.WriteString(`      color: ` + color + `;">` + a.Codigo + `</td>` + "\r\n")
+		inner.WriteString(`    <td style="padding: 10px 12px;">` + a.Consumidor + sede + `</td>` + "\r\n")
+		inner.WriteString(`    <td style="padding: 10px 12px;">` + a.FechaLimite.Format("02/01/2006") + `</td>` + "\r\n")
+		inner.WriteString(`    <td style="padding: 10px 12px;">` + "\r\n")
+		inner.WriteString(`      <span style="padding: 3px 8px; border-radius: 4px;` + "\r\n")
+		inner.WriteString(`        font-size: 11px; font-weight: 700;` + "\r\n")
+		inner.WriteString(`        color: ` + badgeColor + `; background-color: ` + badgeBg + `;">` + etiqueta + `</span>` + "\r\n")
+		inner.WriteString(`    </td>` + "\r\n")
+		inner.WriteString(`  </tr>` + "\r\n")
+	}
+	inner.WriteString(`</table>` + "\r\n")
+	inner.WriteString(`<p style="margin: 24px 0 0 0; font-size: 14px;` + "\r\n")
+	inner.WriteString(`  color: #6b7280; line-height: 1.6;">` + "\r\n")
+	inner.WriteString(`  Ingrese al panel de administracion para responder` + "\r\n")
+	inner.WriteString(`  estos casos dentro del plazo establecido por ley.` + "\r\n")
+	inner.WriteString(`</p>` + "\r\n")
+
+	footer := "Alerta automatica del sistema de Libro de Reclamaciones."
+	cuerpo := buildEmail(color, logoHTML, inner.String(), footer)
+	return s.enviarEmailBase(emailDestino, asunto, cuerpo, nil, "", b.logoData, b.logoMIME)
+}
+
+// EnviarCodigoAccesoPortal envía al consumidor el código de un solo uso del
+// portal, con un enlace que lo ingresa directamente.
+func (s *NotificacionService) EnviarCodigoAccesoPortal(
+	ctx context.Context, emailDestino string, tenant *model.Tenant,
+	codigo string, vigencia time.Duration,
+) error {
+	if s.cfg.User == "" || s.cfg.Pass == "" {
+		return nil
+	}
+
+	b := getBranding(tenant)
+	color, logoHTML, slug := b.color, b.logoHTML, b.slug
+	asunto := "Su codigo de acceso: " + codigo
+	enlace := "https://codeplex.pe/libro/" + slug + "/portal?" + url.Values{
+		"email":  {emailDestino},
+		"codigo": {codigo},
+	}.Encode()
+
+	var inner strings.Builder
+	inner.WriteString(`<p style="margin: 0 0 24px 0; font-size: 15px;` + "\r\n")
+	inner.WriteString(`  color: #4b5563; line-height: 1.6;">` + "\r\n")
+	inner.WriteString(`  Use este codigo para ver sus reclamos y mensajes` + "\r\n")
+	inner.WriteString(`  en el portal del consumidor:` + "\r\n")
+	inner.WriteString(`</p>` + "\r\n")
+	inner.WriteString(`<p style="margin: 0 0 24px 0; text-align: center;` + "\r\n")
+	inner.WriteString(`  font-size: 32px; font-weight: 700; letter-spacing: 8px;` + "\r\n")
+	inner.WriteString(`  color: ` + color + `;">` + codigo + `</p>` + "\r\n")
+	inner.WriteString(`<table role="presentation" width="100%"` + "\r\n")
+	inner.WriteString(`  cellspacing="0" cellpadding="0" border="0">` + "\r\n")
+	inner.WriteString(`  <tr>` + "\r\n")
+	inner.WriteString(`    <td align="center">` + "\r\n")
+	inner.WriteString(`      <a href="` + enlace + `"` + "\r\n")
+	inner.WriteString(`        style="display: inline-block;` + "\r\n")
+	inner.WriteString(`        padding: 14px 32px;` + "\r\n")
+	inner.WriteString(`        background-color: ` + color + `;` + "\r\n")
+	inner.WriteString(`        color: #ffffff; text-decoration: none;` + "\r\n")
+	inner.WriteString(`        border-radius: 8px; font-size: 14px;` + "\r\n")
+	inner.WriteString(`        font-weight: 600;">` + "\r\n")
+	inner.WriteString(`        Ingresar al Portal` + "\r\n")
+	inner.WriteString(`      </a>` + "\r\n")
+	inner.WriteString(`    </td>` + "\r\n")
+	inner.WriteString(`  </tr>` + "\r\n")
+	inner.WriteString(`</table>` + "\r\n")
+	inner.WriteString(`<p style="margin: 24px 0 0 0; font-size: 14px;` + "\r\n")
+	inner.WriteString(`  color: #6b7280; line-height: 1.6;">` + "\r\n")
+	inner.WriteString(fmt.Sprintf(`  El codigo vence en %d minutos y solo puede usarse una vez.`, int(vigencia.Minutes())) + "\r\n")
+	inner.WriteString(`  Si usted no lo solicito, ignore este correo.` + "\r\n")
+	inner.WriteString(`</p>` + "\r\n")
+
+	razon := "La Empresa"
+	if tenant != nil {
+		razon = tenant.RazonSocial
+	}
+	footer := "Acceso al portal del consumidor de <strong>" + razon + "</strong>."
+
+	cuerpo := buildEmail(color, logoHTML, inner.String(), footer)
+	return s.enviarEmailBase(emailDestino, asunto, cuerpo, nil, "", b.logoData, b.logoMIME)
+}
+
 // ─── SMTP BASE ──────────────────────────────────────────────────────────────
 
 func (s *NotificacionService) enviarEmailBase(
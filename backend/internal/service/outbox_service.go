@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+const (
+	outboxLote        = 50               // notificaciones por lote
+	outboxLease       = 2 * time.Minute  // tiempo máximo de envío antes de que otra réplica la retome
+	outboxBackoffBase = 30 * time.Second // 30s, 1m, 2m, 4m, ...
+	outboxBackoffMax  = 6 * time.Hour
+)
+
+// ManejadorOutbox envía una notificación de un canal/tipo concreto.
+// Retornar error programa un reintento (o dead-letter si se agotaron).
+type ManejadorOutbox func(ctx context.Context, tenant *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error
+
+// OutboxService encola notificaciones dentro de transacciones de negocio y
+// las despacha desde el scheduler con reintentos y backoff exponencial.
+type OutboxService struct {
+	outboxRepo    *repo.OutboxRepo
+	reclamoRepo   *repo.ReclamoRepo
+	tenantRepo    *repo.TenantRepo
+	plantillaRepo *repo.PlantillaWhatsAppRepo
+	canalWARepo   *repo.CanalWhatsAppRepo
+	notifService  *NotificacionService
+	pdfServicio   *ExportarPDFServicio
+
+	manejadores map[string]ManejadorOutbox // "CANAL:TIPO"
+	despertando atomic.Bool
+}
+
+func NewOutboxService(
+	outboxRepo *repo.OutboxRepo,
+	reclamoRepo *repo.ReclamoRepo,
+	tenantRepo *repo.TenantRepo,
+	plantillaRepo *repo.PlantillaWhatsAppRepo,
+	canalWARepo *repo.CanalWhatsAppRepo,
+	notifService *NotificacionService,
+	pdfServicio *ExportarPDFServicio,
+) *OutboxService {
+	s := &OutboxService{
+		outboxRepo:    outboxRepo,
+		reclamoRepo:   reclamoRepo,
+		tenantRepo:    tenantRepo,
+		plantillaRepo: plantillaRepo,
+		canalWARepo:   canalWARepo,
+		notifService:  notifService,
+		pdfServicio:   pdfServicio,
+		manejadores:   make(map[string]ManejadorOutbox),
+	}
+	s.registrarManejadoresEmail()
+	s.registrarManejadoresWhatsApp()
+	return s
+}
+
+// Registrar asocia un manejador a canal+tipo. Se llama al arrancar, antes de Procesar.
+func (s *OutboxService) Registrar(canal, tipo string, m ManejadorOutbox) {
+	s.manejadores[canal+":"+tipo] = m
+}
+
+// NuevaNotificacion arma una notificación lista para encolar.
+func NuevaNotificacion(tenantID, reclamoID uuid.UUID, canal, tipo, destinatario string, p model.PayloadNotificacion) model.NotificacionOutbox {
+	payload, _ := json.Marshal(p)
+	return model.NotificacionOutbox{
+		TenantModel:  model.TenantModel{TenantID: tenantID},
+		ReclamoID:    model.NullUUID{UUID: reclamoID, Valid: reclamoID != uuid.Nil},
+		Canal:        canal,
+		Tipo:         tipo,
+		Destinatario: destinatario,
+		Payload:      payload,
+	}
+}
+
+// NotificacionesConsumidor avisos al consumidor de un reclamo: por email si
+// dejó correo y por WhatsApp si el tenant lo activó, el reclamo tiene
+// teléfono y hay plantilla activa para el tipo. Se arman antes de la
+// transacción y se encolan con EncolarTx.
+func (s *OutboxService) NotificacionesConsumidor(ctx context.Context, tenantID uuid.UUID, reclamo *model.Reclamo, tipo string, p model.PayloadNotificacion) ([]model.NotificacionOutbox, error) {
+	if p.FechaLimite == "" && reclamo.FechaLimiteRespuesta.Valid {
+		p.FechaLimite = reclamo.FechaLimiteRespuesta.Time.Format("02/01/2006")
+	}
+
+	var notifs []model.NotificacionOutbox
+	if reclamo.Email != "" {
+		notifs = append(notifs, NuevaNotificacion(tenantID, reclamo.ID, model.CanalNotifEmail, tipo, reclamo.Email, p))
+	}
+
+	// Solo a un número del que se sabe a quién llega: el mensaje lleva el
+	// nombre del consumidor, el código y el texto de la respuesta
+	telefono := NumeroWhatsApp(reclamo.Telefono)
+	if telefono == "" || !slices.Contains(model.TiposNotifWhatsApp, tipo) {
+		return notifs, nil
+	}
+	tenant, err := s.tenantRepo.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("outbox_service.NotificacionesConsumidor: %w", err)
+	}
+	if tenant == nil || !tenant.NotificarWhatsapp {
+		return notifs, nil
+	}
+	plantilla, err := s.plantillaRepo.GetActivaPorTipo(ctx, tenantID, tipo)
+	if err != nil {
+		return nil, fmt.Errorf("outbox_service.NotificacionesConsumidor: %w", err)
+	}
+	if plantilla == nil {
+		return notifs, nil
+	}
+	return append(notifs, NuevaNotificacion(tenantID, reclamo.ID, model.CanalNotifWhatsApp, tipo, telefono, p)), nil
+}
+
+// EncolarTx inserta las notificaciones dentro de la transacción del llamador.
+// Si la transacción hace rollback, las notificaciones tampoco existen.
+func (s *OutboxService) EncolarTx(ctx context.Context, tx *sql.Tx, notifs ...model.NotificacionOutbox) error {
+	r := s.outboxRepo.WithTx(tx)
+	for i := range notifs {
+		if err := r.Encolar(ctx, &notifs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Despertar dispara un lote en segundo plano tras un commit, para no esperar
+// al próximo tick del scheduler. Si ya hay uno corriendo en esta réplica no hace nada.
+func (s *OutboxService) Despertar() {
+	if !s.despertando.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.despertando.Store(false)
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Printf("[CRITICAL] Panic en outbox: %v\n", r)
+			}
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), outboxLease)
+		defer cancel()
+		if _, _, err := s.Procesar(ctx); err != nil {
+			fmt.Printf("[ERROR] Outbox: %v\n", err)
+		}
+	}()
+}
+
+// Procesar reclama lotes de notificaciones y las envía hasta vaciar la cola
+// o agotar el contexto. Retorna cuántas se enviaron y cuántas fallaron.
+func (s *OutboxService) Procesar(ctx context.Context) (enviadas, fallidas int, err error) {
+	tenants := make(map[uuid.UUID]*model.Tenant)
+
+	for ctx.Err() == nil {
+		lote, err := s.outboxRepo.Reclamar(ctx, outboxLote, outboxLease)
+		if err != nil {
+			return enviadas, fallidas, err
+		}
+
+		for i := range lote {
+			n := &lote[i]
+			if errEnvio := s.enviar(ctx, tenants, n); errEnvio != nil {
+				fallidas++
+				s.registrarError(ctx, n, errEnvio)
+				continue
+			}
+			if err := s.outboxRepo.MarcarEnviada(ctx, n.TenantID, n.ID); err != nil {
+				return enviadas, fallidas, err
+			}
+			enviadas++
+		}
+
+		if len(lote) < outboxLote {
+			break
+		}
+	}
+	return enviadas, fallidas, nil
+}
+
+// ListarPorReclamo estado de entrega de las notificaciones de un reclamo.
+func (s *OutboxService) ListarPorReclamo(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]model.NotificacionOutbox, error) {
+	return s.outboxRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+}
+
+// RegistrarEstadoWhatsApp aplica un estado de entrega del webhook de Meta
+// (sent, delivered, read, failed) a la notificación con ese wamid. Los
+// mensajes que no salieron del outbox (respuestas del bot) se ignoran.
+func (s *OutboxService) RegistrarEstadoWhatsApp(ctx context.Context, tenantID uuid.UUID, wamid, status string, fecha time.Time, detalle string) error {
+	estado := EstadoEntregaMeta(status)
+	if estado == "" || wamid == "" {
+		return nil
+	}
+	ok, err := s.outboxRepo.ActualizarEntrega(ctx, tenantID, wamid, estado, fecha, detalle)
+	if err != nil {
+		return fmt.Errorf("outbox_service.RegistrarEstadoWhatsApp: %w", err)
+	}
+	if ok && estado == model.EntregaFallido {
+		fmt.Printf("[WARN] WhatsApp no entregó la notificación %s: %s\n", wamid, detalle)
+	}
+	return nil
+}
+
+// EstadoEntregaMeta traduce el status del webhook de Meta. "" si no se sigue.
+func EstadoEntregaMeta(status string) string {
+	switch status {
+	case "sent":
+		return model.EntregaEnviado
+	case "delivered":
+		return model.EntregaEntregado
+	case "read":
+		return model.EntregaLeido
+	case "failed":
+		return model.EntregaFallido
+	default:
+		return ""
+	}
+}
+
+// Reintentar saca una notificación del dead-letter y la reenvía.
+func (s *OutboxService) Reintentar(ctx context.Context, tenantID, id uuid.UUID) error {
+	ok, err := s.outboxRepo.Reintentar(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("outbox_service.Reintentar: %w", err)
+	}
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	s.Despertar()
+	return nil
+}
+
+func (s *OutboxService) enviar(ctx context.Context, tenants map[uuid.UUID]*model.Tenant, n *model.NotificacionOutbox) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	m, ok := s.manejadores[n.Canal+":"+n.Tipo]
+	if !ok {
+		return fmt.Errorf("sin manejador para %s:%s", n.Canal, n.Tipo)
+	}
+
+	var p model.PayloadNotificacion
+	if err := json.Unmarshal(n.Payload, &p); err != nil {
+		return fmt.Errorf("payload inválido: %w", err)
+	}
+
+	tenant, ok := tenants[n.TenantID]
+	if !ok {
+		tenant, err = s.tenantRepo.GetByTenantID(ctx, n.TenantID)
+		if err != nil {
+			return err
+		}
+		tenants[n.TenantID] = tenant
+	}
+	if tenant == nil {
+		// Fail-safe: branding genérico si el tenant ya no existe
+		tenant = &model.Tenant{RazonSocial: "Su Proveedor", ColorPrimario: "#1a56db", Slug: "portal"}
+	}
+
+	return m(ctx, tenant, n, p)
+}
+
+func (s *OutboxService) registrarError(ctx context.Context, n *model.NotificacionOutbox, errEnvio error) {
+	msg := mensajeError(errEnvio)
+	estado, err := s.outboxRepo.MarcarError(ctx, n.TenantID, n.ID, msg, time.Now().Add(backoffOutbox(n.Intentos+1)))
+	if err != nil {
+		fmt.Printf("[ERROR] Outbox MarcarError %s: %v\n", n.ID, err)
+		return
+	}
+	if estado == model.OutboxFallida {
+		fmt.Printf("[ERROR] Outbox %s %s → dead-letter tras %d intentos: %s\n", n.Tipo, n.Destinatario, n.Intentos+1, msg)
+	}
+}
+
+// mensajeError texto del error que se guarda en ultimo_error, cortado a 500
+// caracteres (no bytes: un corte a mitad de una tilde deja UTF-8 inválido).
+func mensajeError(err error) string {
+	runas := []rune(err.Error())
+	if len(runas) > 500 {
+		runas = runas[:500]
+	}
+	return string(runas)
+}
+
+// HojaConfirmacion PDF de la hoja de reclamación que se adjunta a la
+// confirmación de registro. nil si el consumidor no pidió copia (acepta_copia)
+// o el reclamo ya no existe.
+func (s *OutboxService) HojaConfirmacion(ctx context.Context, tenantID, reclamoID uuid.UUID) ([]byte, error) {
+	reclamo, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
+	if err != nil {
+		return nil, err
+	}
+	if reclamo == nil || !reclamo.AceptaCopia {
+		return nil, nil
+	}
+	hoja, err := s.pdfServicio.GenerarHojaReclamacion(reclamo, nil)
+	if err != nil {
+		return nil, fmt.Errorf("pdf: %w", err)
+	}
+	return hoja, nil
+}
+
+// backoffOutbox espera antes del siguiente intento: base·2^(intento-1), con tope.
+func backoffOutbox(intento int) time.Duration {
+	d := outboxBackoffBase
+	for i := 1; i < intento; i++ {
+		d *= 2
+		if d >= outboxBackoffMax {
+			return outboxBackoffMax
+		}
+	}
+	return d
+}
+
+// ─── MANEJADORES EMAIL ──────────────────────────────────────────────────────
+
+func (s *OutboxService) registrarManejadoresEmail() {
+	// Si el consumidor pidió copia (acepta_copia) se adjunta la hoja de reclamación.
+	s.Registrar(model.CanalNotifEmail, model.NotifConfirmacionReclamo,
+		func(ctx context.Context, t *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error {
+			var hoja []byte
+			if n.ReclamoID.Valid {
+				var err error
+				if hoja, err = s.HojaConfirmacion(ctx, n.TenantID, n.ReclamoID.UUID); err != nil {
+					return err
+				}
+			}
+			return s.notifService.EnviarNotificacionReclamo(ctx, n.Destinatario, t, p.Codigo, p.NombreCliente, p.Fecha, hoja)
+		})
+
+	s.Registrar(model.CanalNotifEmail, model.NotifNuevoReclamoEmpresa,
+		func(ctx context.Context, t *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error {
+			return s.notifService.EnviarNotificacionNuevoReclamoEmpresa(ctx, n.Destinatario, t, p.Codigo, p.NombreCliente, p.TipoSolicitud, p.Fecha)
+		})
+
+	s.Registrar(model.CanalNotifEmail, model.NotifCambioEstado,
+		func(ctx context.Context, t *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error {
+			// Limpiar estado para legibilidad: "EN_PROCESO" -> "EN PROCESO"
+			estadoLegible := strings.ReplaceAll(p.Estado, "_", " ")
+			return s.notifService.EnviarNotificacionCambioEstado(ctx, n.Destinatario, t, p.Codigo, p.NombreCliente, estadoLegible)
+		})
+
+	s.Registrar(model.CanalNotifEmail, model.NotifMensajeNuevo,
+		func(ctx context.Context, t *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error {
+			return s.notifService.EnviarNotificacionMensajeNuevo(ctx, n.Destinatario, t, p.Codigo, p.NombreCliente, p.Texto)
+		})
+
+	// La resolución lleva el PDF adjunto: se genera al enviar con el reclamo actual.
+	s.Registrar(model.CanalNotifEmail, model.NotifResolucion,
+		func(ctx context.Context, t *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error {
+			if !n.ReclamoID.Valid {
+				return fmt.Errorf("resolución sin reclamo_id")
+			}
+			reclamo, err := s.reclamoRepo.GetByID(ctx, n.TenantID, n.ReclamoID.UUID)
+			if err != nil {
+				return err
+			}
+			if reclamo == nil {
+				return fmt.Errorf("reclamo %s no existe", n.ReclamoID.UUID)
+			}
+			pdf, err := generarPDFResolucion(reclamo, p.Texto, p.AccionTomada)
+			if err != nil {
+				return fmt.Errorf("pdf: %w", err)
+			}
+			return s.notifService.EnviarResolucionCliente(ctx, n.Destinatario, t, p.Codigo, p.NombreCliente, p.Texto, pdf)
+		})
+}
+
+// ─── MANEJADORES WHATSAPP ───────────────────────────────────────────────────
+
+// registrarManejadoresWhatsApp envía la plantilla del tenant para el tipo
+// desde su primer canal activo y guarda el wamid para seguir la entrega.
+func (s *OutboxService) registrarManejadoresWhatsApp() {
+	for _, tipo := range model.TiposNotifWhatsApp {
+		s.Registrar(model.CanalNotifWhatsApp, tipo, s.enviarPlantillaWhatsApp)
+	}
+}
+
+func (s *OutboxService) enviarPlantillaWhatsApp(ctx context.Context, t *model.Tenant, n *model.NotificacionOutbox, p model.PayloadNotificacion) error {
+	plantilla, err := s.plantillaRepo.GetActivaPorTipo(ctx, n.TenantID, n.Tipo)
+	if err != nil {
+		return err
+	}
+	if plantilla == nil {
+		return fmt.Errorf("sin plantilla de WhatsApp activa para %s", n.Tipo)
+	}
+
+	canales, err := s.canalWARepo.GetByTenant(ctx, n.TenantID)
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(canales, func(c model.CanalWhatsApp) bool { return c.Activo })
+	if idx < 0 {
+		return fmt.Errorf("el tenant no tiene canal de WhatsApp activo")
+	}
+	canal := canales[idx]
+
+	wamid, err := EnviarPlantillaWhatsApp(ctx, canal.AccessToken, canal.PhoneNumberID, n.Destinatario, MensajePlantilla{
+		Nombre:     plantilla.Nombre,
+		Idioma:     plantilla.Idioma,
+		Parametros: ParametrosPlantillaWhatsApp(plantilla.Variables, t, p),
+	})
+	if err != nil {
+		return err
+	}
+
+	// El mensaje ya salió: un error aquí no debe reenviarlo, solo se pierde el seguimiento
+	if wamid != "" {
+		if err := s.outboxRepo.RegistrarMensajeExterno(ctx, n.TenantID, n.ID, wamid); err != nil {
+			fmt.Printf("[ERROR] Outbox %s: %v\n", n.ID, err)
+		}
+	}
+	return nil
+}
+
+// ParametrosPlantillaWhatsApp valores de {{1}}, {{2}}, ... según las
+// variables de la plantilla.
+func ParametrosPlantillaWhatsApp(variables []string, t *model.Tenant, p model.PayloadNotificacion) []string {
+	parametros := make([]string, len(variables))
+	for i, v := range variables {
+		switch v {
+		case model.VarPlantillaCodigo:
+			parametros[i] = p.Codigo
+		case model.VarPlantillaNombreCliente:
+			parametros[i] = p.NombreCliente
+		case model.VarPlantillaEstado:
+			parametros[i] = strings.ReplaceAll(p.Estado, "_", " ")
+		case model.VarPlantillaFechaLimite:
+			parametros[i] = p.FechaLimite
+		case model.VarPlantillaEmpresa:
+			parametros[i] = t.RazonSocial
+		case model.VarPlantillaMensaje:
+			parametros[i] = p.Texto
+		}
+	}
+	return parametros
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+const (
+	idiomaPlantillaDefecto  = "es"
+	maxVariablesPlantillaWA = 10
+)
+
+var (
+	// Meta solo acepta minúsculas, dígitos y guion bajo en el nombre.
+	regexNombrePlantillaWA = regexp.MustCompile(`^[a-z0-9_]{1,512}$`)
+	// es, es_PE, en_US, ...
+	regexIdiomaPlantillaWA = regexp.MustCompile(`^[a-z]{2,3}(_[A-Z]{2})?$`)
+)
+
+// PlantillaWhatsAppService registro de plantillas de Meta por tenant. Meta las
+// aprueba en WhatsApp Manager; aquí solo se indica cuál usar para cada tipo
+// de notificación y qué dato va en cada parámetro.
+type PlantillaWhatsAppService struct {
+	plantillaRepo  *repo.PlantillaWhatsAppRepo
+	limitesService *LimitesService
+}
+
+func NewPlantillaWhatsAppService(plantillaRepo *repo.PlantillaWhatsAppRepo, limitesService *LimitesService) *PlantillaWhatsAppService {
+	return &PlantillaWhatsAppService{plantillaRepo: plantillaRepo, limitesService: limitesService}
+}
+
+func (s *PlantillaWhatsAppService) Listar(ctx context.Context, tenantID uuid.UUID) ([]model.PlantillaWhatsApp, error) {
+	plantillas, err := s.plantillaRepo.Listar(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_service.Listar: %w", err)
+	}
+	return plantillas, nil
+}
+
+func (s *PlantillaWhatsAppService) Crear(ctx context.Context, tenantID uuid.UUID, req *dto.PlantillaWhatsAppRequest) (*model.PlantillaWhatsApp, error) {
+	p := &model.PlantillaWhatsApp{TenantModel: model.TenantModel{TenantID: tenantID}, Activo: true}
+	if req.Activo != nil {
+		p.Activo = *req.Activo
+	}
+	if err := s.aplicar(ctx, p, req, p.Activo); err != nil {
+		return nil, err
+	}
+	if err := s.plantillaRepo.Create(ctx, p); err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_service.Crear: %w", err)
+	}
+	return p, nil
+}
+
+func (s *PlantillaWhatsAppService) Actualizar(ctx context.Context, tenantID, id uuid.UUID, req *dto.PlantillaWhatsAppRequest) (*model.PlantillaWhatsApp, error) {
+	p, err := s.plantillaRepo.GetByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_service.Actualizar: %w", err)
+	}
+	if p == nil {
+		return nil, apperror.ErrNotFound
+	}
+
+	activaAntes := p.Activo
+	if req.Activo != nil {
+		p.Activo = *req.Activo
+	}
+	if err := s.aplicar(ctx, p, req, p.Activo && !activaAntes); err != nil {
+		return nil, err
+	}
+	if err := s.plantillaRepo.Update(ctx, p); err != nil {
+		return nil, fmt.Errorf("plantilla_whatsapp_service.Actualizar: %w", err)
+	}
+	return p, nil
+}
+
+// Eliminar quita la plantilla: el tipo deja de notificarse por WhatsApp.
+func (s *PlantillaWhatsAppService) Eliminar(ctx context.Context, tenantID, id uuid.UUID) error {
+	ok, err := s.plantillaRepo.Delete(ctx, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("plantilla_whatsapp_service.Eliminar: %w", err)
+	}
+	if !ok {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+// aplicar valida el request y lo copia a p. activando indica que p empieza a
+// usarse para notificar (alta activa o reactivación): requiere el plan.
+func (s *PlantillaWhatsAppService) aplicar(ctx context.Context, p *model.PlantillaWhatsApp, req *dto.PlantillaWhatsAppRequest, activando bool) error {
+	tipo := strings.ToUpper(strings.TrimSpace(req.Tipo))
+	if !slices.Contains(model.TiposNotifWhatsApp, tipo) {
+		return apperror.ErrPlantillaWhatsAppInvalida.Withf("tipo debe ser " + strings.Join(model.TiposNotifWhatsApp, ", "))
+	}
+	nombre := strings.TrimSpace(req.Nombre)
+	if !regexNombrePlantillaWA.MatchString(nombre) {
+		return apperror.ErrPlantillaWhatsAppInvalida.Withf("el nombre admite solo minúsculas, números y guion bajo, como en WhatsApp Manager")
+	}
+	idioma := strings.TrimSpace(req.Idioma)
+	if idioma == "" {
+		idioma = idiomaPlantillaDefecto
+	}
+	if !regexIdiomaPlantillaWA.MatchString(idioma) {
+		return apperror.ErrPlantillaWhatsAppInvalida.Withf(fmt.Sprintf("idioma %q no válido (ej.: es, es_PE)", idioma))
+	}
+	if len(req.Variables) > maxVariablesPlantillaWA {
+		return apperror.ErrPlantillaWhatsAppInvalida.Withf(fmt.Sprintf("se permiten como máximo %d variables", maxVariablesPlantillaWA))
+	}
+	variables := make([]string, len(req.Variables))
+	for i, v := range req.Variables {
+		variables[i] = strings.ToLower(strings.TrimSpace(v))
+		if !slices.Contains(model.VariablesPlantillaWhatsApp, variables[i]) {
+			return apperror.ErrPlantillaWhatsAppInvalida.Withf(fmt.Sprintf("variable %q no existe; use %s", v, strings.Join(model.VariablesPlantillaWhatsApp, ", ")))
+		}
+	}
+
+	existe, err := s.plantillaRepo.ExisteTipo(ctx, p.TenantID, tipo, p.ID)
+	if err != nil {
+		return fmt.Errorf("plantilla_whatsapp_service.aplicar: %w", err)
+	}
+	if existe {
+		return apperror.ErrPlantillaWhatsAppDuplicada
+	}
+
+	if activando {
+		if err := s.limitesService.ValidarFuncionalidad(ctx, p.TenantID, model.FuncWhatsApp); err != nil {
+			return err
+		}
+	}
+
+	p.Tipo = tipo
+	p.Nombre = nombre
+	p.Idioma = idioma
+	p.Variables = variables
+	return nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+const (
+	vigenciaCodigoAcceso    = 10 * time.Minute
+	maxIntentosCodigoAcceso = 5
+	ventanaCodigosAcceso    = 15 * time.Minute
+	maxCodigosPorDestino    = 3  // por ventana
+	maxCodigosPorIP         = 10 // por ventana
+	duracionTokenConsumidor = 2 * time.Hour
+	maxReclamosPortal       = 100
+	prefijoPaisWhatsApp     = "51" // el destino guarda el celular sin código de país
+)
+
+// PortalConsumidorService acceso del consumidor a todos sus reclamos con un
+// tenant: se identifica con el email o teléfono que dejó al registrarlos,
+// recibe un código de un solo uso y obtiene un token propio del portal.
+type PortalConsumidorService struct {
+	codigoRepo   *repo.CodigoAccesoRepo
+	relacionRepo *repo.ReclamoRelacionRepo
+	canalRepo    *repo.CanalWhatsAppRepo
+	notificacion *NotificacionService
+	jwtCfg       config.JWTConfig
+}
+
+func NewPortalConsumidorService(
+	codigoRepo *repo.CodigoAccesoRepo,
+	relacionRepo *repo.ReclamoRelacionRepo,
+	canalRepo *repo.CanalWhatsAppRepo,
+	notificacion *NotificacionService,
+	jwtCfg config.JWTConfig,
+) *PortalConsumidorService {
+	return &PortalConsumidorService{
+		codigoRepo:   codigoRepo,
+		relacionRepo: relacionRepo,
+		canalRepo:    canalRepo,
+		notificacion: notificacion,
+		jwtCfg:       jwtCfg,
+	}
+}
+
+// SesionConsumidor token del portal entregado al verificar el código.
+type SesionConsumidor struct {
+	Token     string `json:"token"`
+	ExpiresIn int    `json:"expires_in"`
+	Canal     string `json:"canal"`
+}
+
+// Identidad normaliza el email o el teléfono con el que el consumidor quiere
+// ingresar. Se acepta uno de los dos.
+func (s *PortalConsumidorService) Identidad(tenantID uuid.UUID, email, telefono string) (model.IdentidadConsumidor, error) {
+	id := model.IdentidadConsumidor{TenantID: tenantID}
+	switch {
+	case strings.TrimSpace(email) != "" && strings.TrimSpace(telefono) != "":
+		return id, apperror.ErrContactoInvalido
+	case strings.TrimSpace(email) != "":
+		id.Canal, id.Destino = model.CanalAccesoEmail, normalizarEmail(email)
+		if !strings.Contains(id.Destino, "@") {
+			return id, apperror.ErrContactoInvalido
+		}
+	default:
+		// El código va por WhatsApp a 51 + Destino: solo celulares peruanos,
+		// para no enviarlo al número de otra persona
+		numero := NumeroWhatsApp(telefono)
+		if !strings.HasPrefix(numero, prefijoPaisWhatsApp) {
+			return id, apperror.ErrContactoInvalido
+		}
+		id.Canal, id.Destino = model.CanalAccesoTelefono, strings.TrimPrefix(numero, prefijoPaisWhatsApp)
+	}
+	return id, nil
+}
+
+// SolicitarCodigo envía un código al consumidor si tiene reclamos con el
+// tenant. Si no tiene, no envía nada pero responde igual, para no revelar
+// qué emails o teléfonos registraron reclamos.
+func (s *PortalConsumidorService) SolicitarCodigo(ctx context.Context, tenant *model.Tenant, id model.IdentidadConsumidor, ip string) error {
+	var canal *model.CanalWhatsApp
+	if id.Canal == model.CanalAccesoTelefono {
+		var err error
+		if canal, err = s.canalActivo(ctx, tenant.TenantID); err != nil {
+			return err
+		}
+	}
+
+	porDestino, porIP, err := s.codigoRepo.ContarRecientes(ctx, tenant.TenantID, id.Canal, id.Destino, ip, time.Now().Add(-ventanaCodigosAcceso))
+	if err != nil {
+		return fmt.Errorf("portal_consumidor_service.SolicitarCodigo: %w", err)
+	}
+	if porDestino >= maxCodigosPorDestino || porIP >= maxCodigosPorIP {
+		return apperror.ErrDemasiadosCodigos
+	}
+
+	reclamos, err := s.relacionRepo.Coincidentes(ctx, tenant.TenantID, uuid.Nil, criteriosIdentidad(id), nil, nil, 1)
+	if err != nil {
+		return fmt.Errorf("portal_consumidor_service.SolicitarCodigo reclamos: %w", err)
+	}
+	if len(reclamos) == 0 {
+		// Se registra igual: el límite debe agotarse al mismo ritmo con o sin
+		// reclamos, o el 429 revelaría qué contactos existen.
+		if err := s.codigoRepo.RegistrarSolicitud(ctx, tenant.TenantID, id.Canal, id.Destino, model.NullString{NullString: nullStr(ip)}); err != nil {
+			return fmt.Errorf("portal_consumidor_service.SolicitarCodigo: %w", err)
+		}
+		return nil
+	}
+
+	codigo, err := generarCodigoAcceso()
+	if err != nil {
+		return fmt.Errorf("portal_consumidor_service.SolicitarCodigo codigo: %w", err)
+	}
+	registro := &model.CodigoAccesoConsumidor{
+		TenantModel:      model.TenantModel{TenantID: tenant.TenantID, ID: uuid.New()},
+		Canal:            id.Canal,
+		Destino:          id.Destino,
+		IPAddress:        model.NullString{NullString: nullStr(ip)},
+		FechaVencimiento: time.Now().Add(vigenciaCodigoAcceso),
+	}
+	registro.CodigoHash = hashCodigoAcceso(registro.ID, codigo)
+	if err := s.codigoRepo.Create(ctx, registro); err != nil {
+		return fmt.Errorf("portal_consumidor_service.SolicitarCodigo: %w", err)
+	}
+
+	// El código se envía en el momento, no por el outbox: el outbox guarda el
+	// payload y el código quedaría en texto plano en la BD.
+	if id.Canal == model.CanalAccesoEmail {
+		err = s.notificacion.EnviarCodigoAccesoPortal(ctx, id.Destino, tenant, codigo, vigenciaCodigoAcceso)
+	} else {
+		texto := fmt.Sprintf("Tu código de acceso al portal de reclamos de %s es %s. Vence en %d minutos. No lo compartas con nadie.",
+			tenant.RazonSocial, codigo, int(vigenciaCodigoAcceso.Minutes()))
+		err = EnviarMensajeWhatsApp(ctx, canal.AccessToken, canal.PhoneNumberID, prefijoPaisWhatsApp+id.Destino, texto)
+	}
+	if err != nil {
+		return fmt.Errorf("portal_consumidor_service.SolicitarCodigo envio: %w", err)
+	}
+	return nil
+}
+
+// Verificar valida el código y entrega el token del portal. Cada código
+// admite maxIntentosCodigoAcceso intentos y se puede usar una sola vez.
+func (s *PortalConsumidorService) Verificar(ctx context.Context, id model.IdentidadConsumidor, codigo string) (*SesionConsumidor, error) {
+	// El intento se cuenta antes de comparar, acierte o no
+	registro, err := s.codigoRepo.ReservarIntento(ctx, id.TenantID, id.Canal, id.Destino, maxIntentosCodigoAcceso)
+	if err != nil {
+		return nil, fmt.Errorf("portal_consumidor_service.Verificar: %w", err)
+	}
+	if registro == nil {
+		return nil, apperror.ErrCodigoAccesoInvalido
+	}
+
+	hash := hashCodigoAcceso(registro.ID, strings.TrimSpace(codigo))
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(registro.CodigoHash)) != 1 {
+		return nil, apperror.ErrCodigoAccesoInvalido
+	}
+
+	ok, err := s.codigoRepo.Consumir(ctx, id.TenantID, registro.ID, maxIntentosCodigoAcceso)
+	if err != nil {
+		return nil, fmt.Errorf("portal_consumidor_service.Verificar: %w", err)
+	}
+	if !ok {
+		return nil, apperror.ErrCodigoAccesoInvalido
+	}
+
+	token, err := middleware.GenerateConsumidorToken(id, duracionTokenConsumidor, s.jwtCfg)
+	if err != nil {
+		return nil, fmt.Errorf("portal_consumidor_service.Verificar token: %w", err)
+	}
+	return &SesionConsumidor{
+		Token:     token,
+		ExpiresIn: int(duracionTokenConsumidor.Seconds()),
+		Canal:     id.Canal,
+	}, nil
+}
+
+// Reclamos del consumidor con el tenant, del más reciente al más antiguo.
+func (s *PortalConsumidorService) Reclamos(ctx context.Context, id model.IdentidadConsumidor) ([]model.ReclamoConsumidor, error) {
+	items, err := s.relacionRepo.Coincidentes(ctx, id.TenantID, uuid.Nil, criteriosIdentidad(id), nil, nil, maxReclamosPortal)
+	if err != nil {
+		return nil, fmt.Errorf("portal_consumidor_service.Reclamos: %w", err)
+	}
+	return items, nil
+}
+
+// VerificarAccesoConsumidor exige que el reclamo consultado por código sea del
+// consumidor autenticado en el portal. Sin identidad pide ingresar; si el
+// reclamo no es suyo responde como inexistente.
+func VerificarAccesoConsumidor(id *model.IdentidadConsumidor, rec *model.Reclamo) error {
+	if id == nil {
+		return apperror.ErrAccesoConsumidorRequerido
+	}
+	if rec == nil || rec.TenantID != id.TenantID {
+		return apperror.ErrNotFound
+	}
+	switch id.Canal {
+	case model.CanalAccesoEmail:
+		if normalizarEmail(rec.Email) == id.Destino {
+			return nil
+		}
+	case model.CanalAccesoTelefono:
+		if normalizarTelefono(rec.Telefono) == id.Destino {
+			return nil
+		}
+	}
+	return apperror.ErrNotFound
+}
+
+// canalActivo primer canal de WhatsApp activo del tenant, desde el que se
+// envían los códigos a los teléfonos.
+func (s *PortalConsumidorService) canalActivo(ctx context.Context, tenantID uuid.UUID) (*model.CanalWhatsApp, error) {
+	canales, err := s.canalRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("portal_consumidor_service.canalActivo: %w", err)
+	}
+	for i := range canales {
+		if canales[i].Activo {
+			return &canales[i], nil
+		}
+	}
+	return nil, apperror.ErrAccesoWhatsAppNoDisponible
+}
+
+// criteriosIdentidad compara solo el dato con el que ingresó el consumidor.
+func criteriosIdentidad(id model.IdentidadConsumidor) repo.CriteriosCoincidencia {
+	if id.Canal == model.CanalAccesoEmail {
+		return repo.CriteriosCoincidencia{Email: id.Destino}
+	}
+	return repo.CriteriosCoincidencia{Telefono: id.Destino}
+}
+
+// generarCodigoAcceso código numérico de 6 dígitos.
+func generarCodigoAcceso() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashCodigoAcceso hash del código ligado a su registro, para que el mismo
+// código en dos registros no produzca el mismo hash.
+func hashCodigoAcceso(id uuid.UUID, codigo string) string {
+	return helper.SHA256Hash(id.String() + ":" + codigo)
+}
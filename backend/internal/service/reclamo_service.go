@@ -3,6 +3,7 @@
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,13 +17,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxIntentosCodigo códigos a probar antes de fallar; con 30^8 combinaciones
+// por prefijo un segundo intento ya es excepcional.
+const maxIntentosCodigo = 5
+
 type ReclamoService struct {
 	reclamoRepo   *repo.ReclamoRepo
 	historialRepo *repo.HistorialRepo
 	tenantRepo    *repo.TenantRepo
 	sedeRepo      *repo.SedeRepo
 	dashboardRepo *repo.DashboardRepo
-	notifService  *NotificacionService
+	calendarioSvc *CalendarioService
+	tx            *repo.Transactor
+	outbox        *OutboxService
+	adjuntoSvc    *AdjuntoService
+	duplicados    *DuplicadoService
 }
 
 func NewReclamoService(
@@ -31,7 +40,11 @@ func NewReclamoService(
 	tenantRepo *repo.TenantRepo,
 	sedeRepo *repo.SedeRepo,
 	dashboardRepo *repo.DashboardRepo,
-	notifService *NotificacionService,
+	calendarioSvc *CalendarioService,
+	tx *repo.Transactor,
+	outbox *OutboxService,
+	adjuntoSvc *AdjuntoService,
+	duplicados *DuplicadoService,
 ) *ReclamoService {
 	return &ReclamoService{
 		reclamoRepo:   reclamoRepo,
@@ -39,7 +52,11 @@ func NewReclamoService(
 		tenantRepo:    tenantRepo,
 		sedeRepo:      sedeRepo,
 		dashboardRepo: dashboardRepo,
-		notifService:  notifService,
+		calendarioSvc: calendarioSvc,
+		tx:            tx,
+		outbox:        outbox,
+		adjuntoSvc:    adjuntoSvc,
+		duplicados:    duplicados,
 	}
 }
 
@@ -51,6 +68,21 @@ func (s *ReclamoService) GetByTenant(ctx context.Context, tenantID uuid.UUID, pa
 
 
 
+// Buscar búsqueda libre con facetas y paginación por cursor.
+func (s *ReclamoService) Buscar(ctx context.Context, filtros repo.FiltrosBusqueda) (*model.BusquedaReclamos, error) {
+	if !repo.OrdenBusquedaValido(filtros.Orden) {
+		return nil, apperror.ErrOrdenInvalido.Withf(filtros.Orden)
+	}
+	resultado, err := s.reclamoRepo.Buscar(ctx, filtros)
+	if errors.Is(err, repo.ErrCursorInvalido) {
+		return nil, apperror.ErrCursorInvalido
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reclamo_service.Buscar: %w", err)
+	}
+	return resultado, nil
+}
+
 func (s *ReclamoService) ObtenerParaExportacion(ctx context.Context, filtros repo.FiltrosExportacion) ([]model.Reclamo, error) {
 	return s.reclamoRepo.ObtenerParaExportacion(ctx, filtros)
 }
@@ -58,7 +90,7 @@ func (s *ReclamoService) ObtenerParaExportacion(ctx context.Context, filtros rep
 
 
 func (s *ReclamoService) GetByCodigoPublico(ctx context.Context, tenantID uuid.UUID, codigo string) (*model.Reclamo, error) {
-	return s.reclamoRepo.GetByCodigoPublico(ctx, tenantID, codigo)
+	return s.reclamoRepo.GetByCodigoPublico(ctx, tenantID, strings.ToUpper(strings.TrimSpace(codigo)))
 }
 
 func (s *ReclamoService) GetByID(ctx context.Context, tenantID, reclamoID uuid.UUID) (*model.Reclamo, error) {
@@ -73,7 +105,8 @@ func (s *ReclamoService) GetByID(ctx context.Context, tenantID, reclamoID uuid.U
 }
 
 // CrearPublico crea un reclamo desde el formulario público (sin auth).
-func (s *ReclamoService) CrearPublico(ctx context.Context, tenantSlug string, req dto.CreateReclamoRequest, ip, userAgent string) (*model.Reclamo, error) {
+// archivos son adjuntos ya validados (puede ser nil).
+func (s *ReclamoService) CrearPublico(ctx context.Context, tenantSlug string, req dto.CreateReclamoRequest, ip, userAgent string, archivos []ArchivoSubido) (*model.Reclamo, error) {
 	// 1. Buscar tenant por slug
 	tenant, err := s.tenantRepo.GetBySlug(ctx, tenantSlug)
 	if err != nil {
@@ -94,6 +127,9 @@ func (s *ReclamoService) CrearPublico(ctx context.Context, tenantSlug string, re
 	if !uso.CanCreateReclamo() {
 		return nil, apperror.ErrPlanLimitReclamos.Withf(uso.LimiteReclamosMes)
 	}
+	if err := s.adjuntoSvc.VerificarCuota(ctx, tenant.TenantID, archivos); err != nil {
+		return nil, err
+	}
 
 	// 3. Resolver sede
 	var sede *model.Sede
@@ -104,16 +140,19 @@ func (s *ReclamoService) CrearPublico(ctx context.Context, tenantSlug string, re
 		}
 	}
 
-	// 4. Generar código
+	// 4. Generar código (formato del tenant, sin repetir uno existente)
 	sedeSlug := ""
 	if sede != nil {
 		sedeSlug = sede.Slug
 	}
-	codigo := helper.GenerateCodigoReclamo(tenant.Slug, sedeSlug)
+	codigo, err := s.generarCodigo(ctx, tenant, sedeSlug)
+	if err != nil {
+		return nil, err
+	}
 
-	// 5. Calcular fecha límite
+	// 5. Calcular fecha límite (días hábiles según el calendario SLA del tenant)
 	fechaIncidente, _ := time.Parse("2006-01-02", req.FechaIncidente)
-	fechaLimite := helper.CalcularFechaLimite(time.Now(), tenant.PlazoRespuestaDias)
+	fechaLimite := s.calcularFechaLimite(ctx, tenant)
 
 	// 6. Construir reclamo con snapshots
 	reclamo := &model.Reclamo{
@@ -151,12 +190,18 @@ func (s *ReclamoService) CrearPublico(ctx context.Context, tenantSlug string, re
 		DetalleReclamo:   req.DetalleReclamo,
 		PedidoConsumidor: req.PedidoConsumidor,
 
+		AceptaTerminos: req.AceptaTerminos,
+		AceptaCopia:    req.AceptaCopia,
+
 		FirmaDigital: model.NullString{NullString: sql.NullString{String: req.FirmaDigital, Valid: req.FirmaDigital != ""}},
 		IPAddress:    model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
 		UserAgent:    model.NullString{NullString: sql.NullString{String: userAgent, Valid: userAgent != ""}},
 
 		FechaLimiteRespuesta: model.NullTime{NullTime: sql.NullTime{Time: fechaLimite, Valid: true}},
 		CanalOrigen:          model.CanalWeb,
+
+		// Lo toma el job de clasificación IA
+		ClasificacionEstado: model.NullString{NullString: sql.NullString{String: model.ClasificacionPendiente, Valid: true}},
 	}
 
 	// Snapshot sede
@@ -166,58 +211,84 @@ func (s *ReclamoService) CrearPublico(ctx context.Context, tenantSlug string, re
 		reclamo.SedeDireccion = model.NullString{NullString: sql.NullString{String: sede.Direccion, Valid: true}}
 	}
 
-	// 7. Insertar reclamo
-	if err := s.reclamoRepo.Create(ctx, reclamo); err != nil {
+	// 7-9. Reclamo + historial + notificaciones en una sola transacción (outbox):
+	// si el commit falla no queda reclamo sin aviso ni aviso sin reclamo.
+	// Si otro registro tomó el mismo código entre la verificación y el INSERT,
+	// se genera otro y se repite la transacción.
+	for intento := 1; ; intento++ {
+		err = s.registrar(ctx, tenant, reclamo, ip)
+		if !errors.Is(err, repo.ErrCodigoReclamoDuplicado) || intento == maxIntentosCodigo {
+			break
+		}
+		if reclamo.CodigoReclamo, err = s.generarCodigo(ctx, tenant, sedeSlug); err != nil {
+			break
+		}
+	}
+	if err != nil {
 		return nil, fmt.Errorf("reclamo_service.CrearPublico create: %w", err)
 	}
+	s.outbox.Despertar()
 
-	// 8. Registrar historial
-	historial := &model.Historial{
-		TenantModel: model.TenantModel{TenantID: tenant.TenantID},
-		ReclamoID:   reclamo.ID,
-		EstadoNuevo: model.EstadoPendiente,
-		TipoAccion:  model.AccionCreacion,
-		IPAddress:   model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
-	}
-	_ = s.historialRepo.Create(ctx, historial)
-
-	// 9. Notificaciones por Email (Asíncrono)
-	fechaFormateada := reclamo.FechaRegistro.Format("02/01/2006 15:04")
-
-	// 9a. Confirmación al CLIENTE
-	if tenant.NotificarEmail && reclamo.Email != "" {
-		go func() {
-			bgCtx := context.Background()
-			_ = s.notifService.EnviarNotificacionReclamo(
-				bgCtx,
-				reclamo.Email,
-				tenant,
-				reclamo.CodigoReclamo,
-				reclamo.NombreCompleto,
-				fechaFormateada,
-			)
-		}()
-	}
-
-	// 9b. Notificación a la EMPRESA (email_contacto del tenant)
-	if tenant.NotificarEmail && tenant.EmailContacto.Valid && tenant.EmailContacto.String != "" {
-		go func() {
-			bgCtx := context.Background()
-			_ = s.notifService.EnviarNotificacionNuevoReclamoEmpresa(
-				bgCtx,
-				tenant.EmailContacto.String,
-				tenant,
-				reclamo.CodigoReclamo,
-				reclamo.NombreCompleto,
-				reclamo.TipoSolicitud,
-				fechaFormateada,
-			)
-		}()
+	// 10. Adjuntos: el reclamo ya quedó registrado; si el storage falla no se pierde
+	if len(archivos) > 0 {
+		if _, err := s.adjuntoSvc.Adjuntar(ctx, tenant.TenantID, reclamo.ID,
+			model.AdjuntoEntidadReclamo, model.AdjuntoOrigenPublico, archivos); err != nil {
+			fmt.Printf("[ERROR] Adjuntos reclamo %s: %v\n", reclamo.CodigoReclamo, err)
+		}
+	}
+
+	// 11. Duplicados probables (mismo consumidor por otro canal); no bloquea el registro
+	if _, err := s.duplicados.Detectar(ctx, reclamo); err != nil {
+		fmt.Printf("[ERROR] Duplicados reclamo %s: %v\n", reclamo.CodigoReclamo, err)
 	}
 
 	return reclamo, nil
 }
 
+// registrar inserta el reclamo, su historial de creación y las notificaciones
+// en una transacción.
+func (s *ReclamoService) registrar(ctx context.Context, tenant *model.Tenant, reclamo *model.Reclamo, ip string) error {
+	return s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.reclamoRepo.WithTx(tx).Create(ctx, reclamo); err != nil {
+			return err
+		}
+
+		// 8. Registrar historial
+		historial := &model.Historial{
+			TenantModel: model.TenantModel{TenantID: tenant.TenantID},
+			ReclamoID:   reclamo.ID,
+			EstadoNuevo: model.EstadoPendiente,
+			TipoAccion:  model.AccionCreacion,
+			IPAddress:   model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
+		}
+		if err := s.historialRepo.WithTx(tx).Create(ctx, historial); err != nil {
+			return err
+		}
+
+		// 9. Notificaciones por Email (outbox)
+		return s.outbox.EncolarTx(ctx, tx, s.notificacionesNuevoReclamo(tenant, reclamo)...)
+	})
+}
+
+// generarCodigo código con el formato del tenant que todavía no existe entre
+// sus reclamos.
+func (s *ReclamoService) generarCodigo(ctx context.Context, tenant *model.Tenant, sedeSlug string) (string, error) {
+	for i := 0; i < maxIntentosCodigo; i++ {
+		codigo, err := helper.GenerateCodigoReclamo(tenant.FormatoCodigo.String, tenant.Slug, sedeSlug)
+		if err != nil {
+			return "", fmt.Errorf("reclamo_service.generarCodigo: %w", err)
+		}
+		existe, err := s.reclamoRepo.ExisteCodigo(ctx, tenant.TenantID, codigo)
+		if err != nil {
+			return "", fmt.Errorf("reclamo_service.generarCodigo: %w", err)
+		}
+		if !existe {
+			return codigo, nil
+		}
+	}
+	return "", fmt.Errorf("reclamo_service.generarCodigo: %d códigos seguidos ya existían", maxIntentosCodigo)
+}
+
 func (s *ReclamoService) CambiarEstado(ctx context.Context, tenantID, reclamoID, userID uuid.UUID, nuevoEstado, comentario, ip string) error {
 	reclamo, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
 	if err != nil {
@@ -228,54 +299,51 @@ func (s *ReclamoService) CambiarEstado(ctx context.Context, tenantID, reclamoID,
 	}
 
 	estadoAnterior := reclamo.Estado
-
-	if err := s.reclamoRepo.UpdateEstado(ctx, tenantID, reclamoID, nuevoEstado, &userID); err != nil {
-		return fmt.Errorf("reclamo_service.CambiarEstado update: %w", err)
+	if !model.TransicionValida(estadoAnterior, nuevoEstado) {
+		return apperror.ErrTransicionEstado.Withf(estadoAnterior, nuevoEstado)
 	}
 
-	// Registrar historial
-	historial := &model.Historial{
-		TenantModel:    model.TenantModel{TenantID: tenantID},
-		ReclamoID:      reclamoID,
-		EstadoAnterior: model.NullString{NullString: sql.NullString{String: estadoAnterior, Valid: true}},
-		EstadoNuevo:    nuevoEstado,
-		TipoAccion:     model.AccionCambioEstado,
-		Comentario:     model.NullString{NullString: sql.NullString{String: comentario, Valid: comentario != ""}},
-		UsuarioAccion:  model.NullUUID{UUID: userID, Valid: true},
-		IPAddress:      model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
+	// Avisos al consumidor (email y WhatsApp) vía outbox
+	notifs, err := s.outbox.NotificacionesConsumidor(ctx, tenantID, reclamo, model.NotifCambioEstado, model.PayloadNotificacion{
+		Codigo:        reclamo.CodigoReclamo,
+		NombreCliente: reclamo.NombreCompleto,
+		Estado:        nuevoEstado,
+	})
+	if err != nil {
+		return fmt.Errorf("reclamo_service.CambiarEstado: %w", err)
 	}
-	_ = s.historialRepo.Create(ctx, historial)
 
-	// Notificar al cliente por email
-	if reclamo.Email != "" {
-		go func() {
-			bgCtx := context.Background()
-
-			t, errT := s.tenantRepo.GetByTenantID(bgCtx, tenantID)
-			if errT != nil || t == nil {
-				t = &model.Tenant{
-					RazonSocial:   "Su Proveedor",
-					ColorPrimario: "#1a56db",
-					Slug:          "portal",
-				}
-			}
-
-			// Limpiar estado para legibilidad: "EN_PROCESO" -> "EN PROCESO"
-			estadoLegible := strings.ReplaceAll(nuevoEstado, "_", " ")
-
-			errEnvio := s.notifService.EnviarNotificacionCambioEstado(
-				bgCtx,
-				reclamo.Email,
-				t,
-				reclamo.CodigoReclamo,
-				reclamo.NombreCompleto,
-				estadoLegible,
-			)
-			if errEnvio != nil {
-				fmt.Printf("[ERROR SMTP CambiarEstado] %v\n", errEnvio)
-			}
-		}()
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.reclamoRepo.WithTx(tx).UpdateEstado(ctx, tenantID, reclamoID, estadoAnterior, nuevoEstado, &userID); err != nil {
+			return err
+		}
+
+		// Registrar historial
+		historial := &model.Historial{
+			TenantModel:    model.TenantModel{TenantID: tenantID},
+			ReclamoID:      reclamoID,
+			EstadoAnterior: model.NullString{NullString: sql.NullString{String: estadoAnterior, Valid: true}},
+			EstadoNuevo:    nuevoEstado,
+			TipoAccion:     model.AccionCambioEstado,
+			Comentario:     model.NullString{NullString: sql.NullString{String: comentario, Valid: comentario != ""}},
+			UsuarioAccion:  model.NullUUID{UUID: userID, Valid: true},
+			IPAddress:      model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
+		}
+		if err := s.historialRepo.WithTx(tx).Create(ctx, historial); err != nil {
+			return err
+		}
+
+		return s.outbox.EncolarTx(ctx, tx, notifs...)
+	})
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		// Otro cambio de estado se adelantó entre la lectura y el UPDATE
+		return appErr
+	}
+	if err != nil {
+		return fmt.Errorf("reclamo_service.CambiarEstado update: %w", err)
 	}
+	s.outbox.Despertar()
 
 	return nil
 }
@@ -289,10 +357,6 @@ func (s *ReclamoService) Asignar(ctx context.Context, tenantID, reclamoID, admin
 		return apperror.ErrNotFound
 	}
 
-	if err := s.reclamoRepo.Asignar(ctx, tenantID, reclamoID, adminID); err != nil {
-		return fmt.Errorf("reclamo_service.Asignar: %w", err)
-	}
-
 	historial := &model.Historial{
 		TenantModel:    model.TenantModel{TenantID: tenantID},
 		ReclamoID:      reclamoID,
@@ -303,11 +367,209 @@ func (s *ReclamoService) Asignar(ctx context.Context, tenantID, reclamoID, admin
 		UsuarioAccion:  model.NullUUID{UUID: userID, Valid: true},
 		IPAddress:      model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
 	}
-	_ = s.historialRepo.Create(ctx, historial)
+
+	// El historial es un eslabón de la cadena de integridad: va en la misma transacción
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.reclamoRepo.WithTx(tx).Asignar(ctx, tenantID, reclamoID, adminID); err != nil {
+			return err
+		}
+		return s.historialRepo.WithTx(tx).Create(ctx, historial)
+	})
+	if err != nil {
+		return fmt.Errorf("reclamo_service.Asignar: %w", err)
+	}
+
+	return nil
+}
+
+// Reabrir reapertura desde el panel: un admin puede reabrir un reclamo
+// RESUELTO o CERRADO en cualquier momento. sedeID restringe a la sede del usuario.
+func (s *ReclamoService) Reabrir(ctx context.Context, tenantID, reclamoID, userID uuid.UUID, sedeID *uuid.UUID, motivo, ip string) error {
+	reclamo, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
+	if err != nil {
+		return fmt.Errorf("reclamo_service.Reabrir: %w", err)
+	}
+	if reclamo == nil {
+		return apperror.ErrNotFound
+	}
+	if sedeID != nil && (!reclamo.SedeID.Valid || reclamo.SedeID.UUID != *sedeID) {
+		return apperror.ErrSedeNoPermitida
+	}
+	tenant, err := s.tenantRepo.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return fmt.Errorf("reclamo_service.Reabrir tenant: %w", err)
+	}
+	if tenant == nil {
+		return apperror.ErrNotFound
+	}
+	return s.reabrir(ctx, tenant, reclamo, &userID, motivo, ip)
+}
+
+// ReabrirPublico reapertura por el consumidor desde el seguimiento público.
+// Debe confirmar su número de documento y estar dentro del plazo del tenant.
+func (s *ReclamoService) ReabrirPublico(ctx context.Context, tenant *model.Tenant, codigo, numeroDocumento, motivo, ip string) error {
+	encontrado, err := s.reclamoRepo.GetByCodigoPublico(ctx, tenant.TenantID, codigo)
+	if err != nil {
+		return fmt.Errorf("reclamo_service.ReabrirPublico: %w", err)
+	}
+	if encontrado == nil {
+		return apperror.ErrNotFound
+	}
+	reclamo, err := s.reclamoRepo.GetByID(ctx, tenant.TenantID, encontrado.ID)
+	if err != nil {
+		return fmt.Errorf("reclamo_service.ReabrirPublico: %w", err)
+	}
+	if reclamo == nil {
+		return apperror.ErrNotFound
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(numeroDocumento), reclamo.NumeroDocumento) {
+		return apperror.ErrDocumentoNoCoincide
+	}
+	if !model.PuedeReabrirse(reclamo.Estado) {
+		return apperror.ErrReaperturaNoPermitida
+	}
+	if limite, ok := PlazoReapertura(tenant, reclamo); !ok || time.Now().After(limite) {
+		return apperror.ErrReaperturaVencida
+	}
+
+	return s.reabrir(ctx, tenant, reclamo, nil, motivo, ip)
+}
+
+// PlazoReapertura fecha hasta la que el consumidor puede reabrir el reclamo.
+// ok = false si el tenant desactivó la reapertura o el estado no lo permite.
+// El plazo corre desde fecha_cierre (o fecha_respuesta en reclamos anteriores).
+func PlazoReapertura(tenant *model.Tenant, reclamo *model.Reclamo) (time.Time, bool) {
+	if tenant.DiasReapertura <= 0 || !model.PuedeReabrirse(reclamo.Estado) {
+		return time.Time{}, false
+	}
+	desde := reclamo.FechaRegistro
+	if reclamo.FechaCierre.Valid {
+		desde = reclamo.FechaCierre.Time
+	} else if reclamo.FechaRespuesta.Valid {
+		desde = reclamo.FechaRespuesta.Time
+	}
+	return desde.AddDate(0, 0, tenant.DiasReapertura), true
+}
+
+// PlazoReaperturaSeguimiento plazo que ve el consumidor en el seguimiento:
+// el de PlazoReapertura, con ok = false si el reclamo se fusionó en otro.
+func (s *ReclamoService) PlazoReaperturaSeguimiento(ctx context.Context, tenant *model.Tenant, reclamo *model.Reclamo) (time.Time, bool, error) {
+	limite, ok := PlazoReapertura(tenant, reclamo)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	fusionadoEn, err := s.duplicados.FusionadoEn(ctx, tenant.TenantID, reclamo.ID)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("reclamo_service.PlazoReaperturaSeguimiento: %w", err)
+	}
+	if fusionadoEn != nil {
+		return time.Time{}, false, nil
+	}
+	return limite, true, nil
+}
+
+// reabrir vuelve el reclamo a EN_PROCESO con un plazo de respuesta nuevo
+// (contado desde hoy con el calendario del tenant) y registra REAPERTURA.
+// userID nil = reabierto por el consumidor.
+func (s *ReclamoService) reabrir(ctx context.Context, tenant *model.Tenant, reclamo *model.Reclamo, userID *uuid.UUID, motivo, ip string) error {
+	if !model.PuedeReabrirse(reclamo.Estado) {
+		return apperror.ErrReaperturaNoPermitida
+	}
+	// Un duplicado fusionado se atiende en el principal
+	fusionadoEn, err := s.duplicados.FusionadoEn(ctx, tenant.TenantID, reclamo.ID)
+	if err != nil {
+		return fmt.Errorf("reclamo_service.Reabrir: %w", err)
+	}
+	if fusionadoEn != nil {
+		return apperror.ErrReaperturaFusionado
+	}
+
+	fechaLimite := s.calcularFechaLimite(ctx, tenant)
+
+	historial := &model.Historial{
+		TenantModel:    model.TenantModel{TenantID: tenant.TenantID},
+		ReclamoID:      reclamo.ID,
+		EstadoAnterior: model.NullString{NullString: sql.NullString{String: reclamo.Estado, Valid: true}},
+		EstadoNuevo:    model.EstadoEnProceso,
+		TipoAccion:     model.AccionReapertura,
+		Comentario:     model.NullString{NullString: nullStr(motivo)},
+		IPAddress:      model.NullString{NullString: nullStr(ip)},
+	}
+	if userID != nil {
+		historial.UsuarioAccion = model.NullUUID{UUID: *userID, Valid: true}
+	}
+
+	notifs, err := s.outbox.NotificacionesConsumidor(ctx, tenant.TenantID, reclamo, model.NotifCambioEstado, model.PayloadNotificacion{
+		Codigo:        reclamo.CodigoReclamo,
+		NombreCliente: reclamo.NombreCompleto,
+		Estado:        model.EstadoEnProceso,
+		FechaLimite:   fechaLimite.Format("02/01/2006"),
+	})
+	if err != nil {
+		return fmt.Errorf("reclamo_service.Reabrir: %w", err)
+	}
+
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		if err := s.reclamoRepo.WithTx(tx).Reabrir(ctx, tenant.TenantID, reclamo.ID, fechaLimite); err != nil {
+			return err
+		}
+		if err := s.historialRepo.WithTx(tx).Create(ctx, historial); err != nil {
+			return err
+		}
+		return s.outbox.EncolarTx(ctx, tx, notifs...)
+	})
+	if errors.Is(err, apperror.ErrNotFound) {
+		// Otro usuario lo reabrió o lo borró entre la lectura y el UPDATE
+		return apperror.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("reclamo_service.Reabrir: %w", err)
+	}
+	s.outbox.Despertar()
 
 	return nil
 }
 
+// notificacionesNuevoReclamo confirmación al cliente y aviso a la empresa.
+func (s *ReclamoService) notificacionesNuevoReclamo(tenant *model.Tenant, reclamo *model.Reclamo) []model.NotificacionOutbox {
+	if !tenant.NotificarEmail {
+		return nil
+	}
+	p := model.PayloadNotificacion{
+		Codigo:        reclamo.CodigoReclamo,
+		NombreCliente: reclamo.NombreCompleto,
+		TipoSolicitud: reclamo.TipoSolicitud,
+		Fecha:         reclamo.FechaRegistro.Format("02/01/2006 15:04"),
+	}
+
+	var notifs []model.NotificacionOutbox
+	// Confirmación al CLIENTE
+	if reclamo.Email != "" {
+		notifs = append(notifs, NuevaNotificacion(tenant.TenantID, reclamo.ID,
+			model.CanalNotifEmail, model.NotifConfirmacionReclamo, reclamo.Email, p))
+	}
+	// Notificación a la EMPRESA (email_contacto del tenant)
+	if tenant.EmailContacto.Valid && tenant.EmailContacto.String != "" {
+		notifs = append(notifs, NuevaNotificacion(tenant.TenantID, reclamo.ID,
+			model.CanalNotifEmail, model.NotifNuevoReclamoEmpresa, tenant.EmailContacto.String, p))
+	}
+	return notifs
+}
+
+// calcularFechaLimite usa el calendario del tenant; si no se puede cargar,
+// cae al calendario legal por defecto para no bloquear el registro del reclamo.
+func (s *ReclamoService) calcularFechaLimite(ctx context.Context, tenant *model.Tenant) time.Time {
+	if s.calendarioSvc != nil {
+		cal, err := s.calendarioSvc.ObtenerCalendario(ctx, tenant.TenantID)
+		if err == nil {
+			return cal.CalcularFechaLimite(time.Now(), tenant.PlazoRespuestaDias)
+		}
+		fmt.Printf("[WARN] Calendario SLA tenant %s: %v\n", tenant.TenantID, err)
+	}
+	return helper.CalcularFechaLimite(time.Now(), tenant.PlazoRespuestaDias)
+}
+
 // nullStr helper inline para construir sql.NullString.
 func nullStr(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: s != ""}
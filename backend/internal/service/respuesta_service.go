@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"libro-reclamaciones/internal/apperror"
@@ -18,17 +19,19 @@ type RespuestaService struct {
 	respuestaRepo *repo.RespuestaRepo
 	reclamoRepo   *repo.ReclamoRepo
 	historialRepo *repo.HistorialRepo
-	notifService  *NotificacionService
-	tenantRepo    *repo.TenantRepo
+	tx            *repo.Transactor
+	outbox        *OutboxService
+	adjuntoSvc    *AdjuntoService
 }
 
-func NewRespuestaService(respuestaRepo *repo.RespuestaRepo, reclamoRepo *repo.ReclamoRepo, historialRepo *repo.HistorialRepo, notifService *NotificacionService, tenantRepo *repo.TenantRepo) *RespuestaService {
+func NewRespuestaService(respuestaRepo *repo.RespuestaRepo, reclamoRepo *repo.ReclamoRepo, historialRepo *repo.HistorialRepo, tx *repo.Transactor, outbox *OutboxService, adjuntoSvc *AdjuntoService) *RespuestaService {
 	return &RespuestaService{
 		respuestaRepo: respuestaRepo,
 		reclamoRepo:   reclamoRepo,
 		historialRepo: historialRepo,
-		notifService:  notifService,
-		tenantRepo:    tenantRepo,
+		tx:            tx,
+		outbox:        outbox,
+		adjuntoSvc:    adjuntoSvc,
 	}
 }
 
@@ -36,7 +39,8 @@ func (s *RespuestaService) GetByReclamo(ctx context.Context, tenantID, reclamoID
 	return s.respuestaRepo.GetByReclamo(ctx, tenantID, reclamoID)
 }
 
-func (s *RespuestaService) Crear(ctx context.Context, tenantID, reclamoID, userID uuid.UUID, respuestaTexto, accionTomada, compensacion, cargo, ip string) (*model.Respuesta, error) {
+// Crear registra la respuesta de la empresa. archivos son adjuntos ya validados (puede ser nil).
+func (s *RespuestaService) Crear(ctx context.Context, tenantID, reclamoID, userID uuid.UUID, respuestaTexto, accionTomada, compensacion, cargo, ip string, archivos []ArchivoSubido) (*model.Respuesta, error) {
 	// 1. Obtener datos del reclamo
 	reclamo, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
 	if err != nil {
@@ -58,162 +62,184 @@ func (s *RespuestaService) Crear(ctx context.Context, tenantID, reclamoID, userI
 		Origen:               model.OrigenPanel,
 	}
 
-	// 3. Guardar respuesta
-	if err := s.respuestaRepo.Create(ctx, resp); err != nil {
+	// 2b. Resolución al cliente (outbox; el PDF del email se genera al enviar)
+	estadoAnterior := reclamo.Estado
+	estadoFinal := estadoAnterior
+	if estadoAnterior == model.EstadoPendiente || estadoAnterior == model.EstadoEnProceso {
+		estadoFinal = model.EstadoResuelto
+	}
+	notifs, err := s.outbox.NotificacionesConsumidor(ctx, tenantID, reclamo, model.NotifResolucion, model.PayloadNotificacion{
+		Codigo:        reclamo.CodigoReclamo,
+		NombreCliente: reclamo.NombreCompleto,
+		Estado:        estadoFinal,
+		Texto:         respuestaTexto,
+		AccionTomada:  accionTomada,
+	})
+	if err != nil {
 		return nil, fmt.Errorf("respuesta_service.Crear: %w", err)
 	}
 
-	// 4. Actualizar fecha de respuesta
-	_ = s.reclamoRepo.UpdateFechaRespuesta(ctx, tenantID, reclamoID)
-
-	// 5. Cambiar estado a RESUELTO automáticamente si está pendiente o en proceso
-	estadoAnterior := reclamo.Estado
-	if estadoAnterior == model.EstadoPendiente || estadoAnterior == model.EstadoEnProceso {
-		_ = s.reclamoRepo.UpdateEstado(ctx, tenantID, reclamoID, model.EstadoResuelto, &userID)
+	// 2c. Adjuntos: se suben antes de la transacción y se registran dentro de ella
+	if err := s.adjuntoSvc.VerificarCuota(ctx, tenantID, archivos); err != nil {
+		return nil, err
 	}
+	adjuntos, err := s.adjuntoSvc.Almacenar(ctx, tenantID, reclamoID,
+		model.AdjuntoEntidadRespuesta, model.AdjuntoOrigenPanel, userID, archivos)
+	if err != nil {
+		return nil, fmt.Errorf("respuesta_service.Crear adjuntos: %w", err)
+	}
+	resp.ArchivosAdjuntos = resumenAdjuntos(adjuntos)
 
-	// 6. Registrar historial de la respuesta
-	_ = s.historialRepo.Create(ctx, &model.Historial{
-		TenantModel:    model.TenantModel{TenantID: tenantID},
-		ReclamoID:      reclamoID,
-		EstadoAnterior: model.NullString{NullString: sql.NullString{String: estadoAnterior, Valid: true}},
-		EstadoNuevo:    model.EstadoResuelto,
-		TipoAccion:     model.AccionRespuesta,
-		UsuarioAccion:  model.NullUUID{UUID: userID, Valid: true},
-		IPAddress:      model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
-	})
+	// 3-6. Respuesta + fecha + estado + historial + notificación en una transacción
+	err = s.tx.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		reclamoTx := s.reclamoRepo.WithTx(tx)
 
-	// 7. Generación de PDF y envío (Background)
-	if reclamo.Email != "" {
-		// Capturamos variables para evitar punteros nulos en la goroutine
-		targetEmail := reclamo.Email
-		codigo := reclamo.CodigoReclamo
-		nombreCli := reclamo.NombreCompleto
-		tDoc, nDoc := reclamo.TipoDocumento, reclamo.NumeroDocumento
-		detReclamo := reclamo.DetalleReclamo
-		pedidoCli := reclamo.PedidoConsumidor
-		fechaReg := reclamo.FechaRegistro.Format("02/01/2006")
-
-		// Datos del consumidor para el PDF
-		domicilioCli := reclamo.Domicilio.String
-		telefonoCli := reclamo.Telefono
-		emailCli := reclamo.Email
-
-		// Datos del Proveedor (snapshot)
-		rSoc := reclamo.RazonSocialProveedor.String
-		ruc := reclamo.RUCProveedor.String
-
-		// Sede y Dirección
-		sedeNom := reclamo.SedeNombre.String
-		if sedeNom == "" {
-			sedeNom = "Establecimiento no especificado"
+		if err := s.respuestaRepo.WithTx(tx).Create(ctx, resp); err != nil {
+			return err
 		}
-
-		sedeDir := reclamo.SedeDireccion.String
-		if sedeDir == "" {
-			sedeDir = reclamo.DireccionProveedor.String
+		if err := s.adjuntoSvc.RegistrarTx(ctx, tx, adjuntos, resp.ID); err != nil {
+			return err
 		}
-		if sedeDir == "" {
-			sedeDir = "No registrada en el sistema"
+
+		// Actualizar fecha de respuesta
+		if err := reclamoTx.UpdateFechaRespuesta(ctx, tenantID, reclamoID); err != nil {
+			return err
 		}
 
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("[CRITICAL] Panic en goroutine PDF: %v\n", r)
-				}
-			}()
-
-			pdf := gofpdf.New("P", "mm", "A4", "")
-			tr := pdf.UnicodeTranslatorFromDescriptor("")
-			pdf.AddPage()
-
-			// --- ENCABEZADO ---
-			pdf.SetFillColor(28, 63, 170)
-			pdf.SetTextColor(255, 255, 255)
-			pdf.SetFont("Arial", "B", 14)
-			pdf.CellFormat(0, 12, tr("RESOLUCIÓN DE HOJA DE RECLAMACIÓN"), "0", 1, "C", true, 0, "")
-			pdf.Ln(5)
-
-			pdf.SetTextColor(0, 0, 0)
-			pdf.SetFont("Arial", "B", 10)
-			pdf.CellFormat(0, 5, tr("Código: ")+codigo, "0", 1, "R", false, 0, "")
-			pdf.CellFormat(0, 5, "Fecha de Registro: "+fechaReg, "0", 1, "R", false, 0, "")
-			pdf.Ln(5)
-
-			// --- 1. PROVEEDOR ---
-			pdf.SetFillColor(240, 240, 240)
-			pdf.SetFont("Arial", "B", 11)
-			pdf.CellFormat(0, 8, " 1. IDENTIFICACIÓN DEL PROVEEDOR", "1", 1, "L", true, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			txtProv := fmt.Sprintf("Razón Social: %s\nRUC: %s\nSede: %s\nDirección: %s", rSoc, ruc, sedeNom, sedeDir)
-			pdf.MultiCell(0, 6, tr(txtProv), "1", "L", false)
-			pdf.Ln(4)
-
-			// --- 2. CONSUMIDOR ---
-			pdf.SetFont("Arial", "B", 11)
-			pdf.CellFormat(0, 8, " 2. IDENTIFICACIÓN DEL CONSUMIDOR", "1", 1, "L", true, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			txtCons := fmt.Sprintf("Nombre: %s\nDocumento: %s %s\nDirección: %s\nContacto: %s / %s",
-				nombreCli, tDoc, nDoc, domicilioCli, telefonoCli, emailCli)
-			pdf.MultiCell(0, 6, tr(txtCons), "1", "L", false)
-			pdf.Ln(4)
-
-			// --- 3. DETALLE ---
-			pdf.SetFont("Arial", "B", 11)
-			pdf.CellFormat(0, 8, " 3. DETALLE DEL RECLAMO / QUEJA", "1", 1, "L", true, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			pdf.MultiCell(0, 6, tr("Detalle: "+detReclamo), "1", "L", false)
-			pdf.MultiCell(0, 6, tr("Pedido: "+pedidoCli), "1", "L", false)
-			pdf.Ln(4)
-
-			// --- 4. RESOLUCIÓN ---
-			pdf.SetFont("Arial", "B", 11)
-			pdf.CellFormat(0, 8, tr(" 4. RESOLUCIÓN DE LA EMPRESA"), "1", 1, "L", true, 0, "")
-			pdf.SetFont("Arial", "B", 10)
-			pdf.CellFormat(0, 7, "Respuesta:", "LR", 1, "L", false, 0, "")
-			pdf.SetFont("Arial", "", 10)
-			pdf.MultiCell(0, 6, tr(respuestaTexto), "LRB", "L", false)
-
-			if accionTomada != "" {
-				pdf.SetFont("Arial", "B", 10)
-				pdf.CellFormat(0, 7, tr("Acción adoptada:"), "LR", 1, "L", false, 0, "")
-				pdf.SetFont("Arial", "", 10)
-				pdf.MultiCell(0, 6, tr(accionTomada), "LRB", "L", false)
+		// Cambiar estado a RESUELTO automáticamente si está pendiente o en proceso
+		if estadoFinal != estadoAnterior {
+			if err := reclamoTx.UpdateEstado(ctx, tenantID, reclamoID, estadoAnterior, estadoFinal, &userID); err != nil {
+				return err
 			}
+		}
 
-			pdf.SetY(-35)
-			pdf.SetFont("Arial", "I", 8)
-			pdf.SetTextColor(100, 100, 100)
-			pdf.MultiCell(0, 4, tr("Documento generado conforme a la Ley N° 29571. Codeplex SaaS."), "T", "C", false)
+		// Registrar historial de la respuesta (sin cambio de estado si ya estaba cerrado)
+		if err := s.historialRepo.WithTx(tx).Create(ctx, &model.Historial{
+			TenantModel:    model.TenantModel{TenantID: tenantID},
+			ReclamoID:      reclamoID,
+			EstadoAnterior: model.NullString{NullString: sql.NullString{String: estadoAnterior, Valid: true}},
+			EstadoNuevo:    estadoFinal,
+			TipoAccion:     model.AccionRespuesta,
+			UsuarioAccion:  model.NullUUID{UUID: userID, Valid: true},
+			IPAddress:      model.NullString{NullString: sql.NullString{String: ip, Valid: ip != ""}},
+		}); err != nil {
+			return err
+		}
 
-			var buf bytes.Buffer
-			if err := pdf.Output(&buf); err != nil {
-				fmt.Printf("[ERROR] PDF Output: %v\n", err)
-				return
-			}
+		// 7. Resolución al cliente (outbox)
+		return s.outbox.EncolarTx(ctx, tx, notifs...)
+	})
+	if err != nil {
+		s.adjuntoSvc.Descartar(adjuntos)
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr // el estado cambió mientras se respondía
+		}
+		return nil, fmt.Errorf("respuesta_service.Crear: %w", err)
+	}
+	s.outbox.Despertar()
 
-			// Obtener tenant para Branding del email
-			tenantObj, _ := s.tenantRepo.GetByTenantID(context.Background(), tenantID)
-			if tenantObj == nil {
-				tenantObj = &model.Tenant{RazonSocial: rSoc, ColorPrimario: "#1a56db", Slug: "portal"}
-			}
+	return resp, nil
+}
 
-			// Enviar email con PDF adjunto
-			errEnvio := s.notifService.EnviarResolucionCliente(
-				context.Background(),
-				targetEmail,
-				tenantObj,
-				codigo,
-				nombreCli,
-				respuestaTexto,
-				buf.Bytes(),
-			)
-			if errEnvio != nil {
-				fmt.Printf("[ERROR SMTP Resolución] %v\n", errEnvio)
-			}
-		}()
+// generarPDFResolucion arma el PDF de la resolución de la hoja de reclamación.
+func generarPDFResolucion(reclamo *model.Reclamo, respuestaTexto, accionTomada string) ([]byte, error) {
+	codigo := reclamo.CodigoReclamo
+	nombreCli := reclamo.NombreCompleto
+	tDoc, nDoc := reclamo.TipoDocumento, reclamo.NumeroDocumento
+	detReclamo := reclamo.DetalleReclamo
+	pedidoCli := reclamo.PedidoConsumidor
+	fechaReg := reclamo.FechaRegistro.Format("02/01/2006")
+
+	// Datos del consumidor para el PDF
+	domicilioCli := reclamo.Domicilio.String
+	telefonoCli := reclamo.Telefono
+	emailCli := reclamo.Email
+
+	// Datos del Proveedor (snapshot)
+	rSoc := reclamo.RazonSocialProveedor.String
+	ruc := reclamo.RUCProveedor.String
+
+	// Sede y Dirección
+	sedeNom := reclamo.SedeNombre.String
+	if sedeNom == "" {
+		sedeNom = "Establecimiento no especificado"
 	}
 
-	return resp, nil
-}
\ No newline at end of file
+	sedeDir := reclamo.SedeDireccion.String
+	if sedeDir == "" {
+		sedeDir = reclamo.DireccionProveedor.String
+	}
+	if sedeDir == "" {
+		sedeDir = "No registrada en el sistema"
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	// --- ENCABEZADO ---
+	pdf.SetFillColor(28, 63, 170)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 12, tr("RESOLUCIÓN DE HOJA DE RECLAMACIÓN"), "0", 1, "C", true, 0, "")
+	pdf.Ln(5)
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 5, tr("Código: ")+codigo, "0", 1, "R", false, 0, "")
+	pdf.CellFormat(0, 5, "Fecha de Registro: "+fechaReg, "0", 1, "R", false, 0, "")
+	pdf.Ln(5)
+
+	// --- 1. PROVEEDOR ---
+	pdf.SetFillColor(240, 240, 240)
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 8, " 1. IDENTIFICACIÓN DEL PROVEEDOR", "1", 1, "L", true, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	txtProv := fmt.Sprintf("Razón Social: %s\nRUC: %s\nSede: %s\nDirección: %s", rSoc, ruc, sedeNom, sedeDir)
+	pdf.MultiCell(0, 6, tr(txtProv), "1", "L", false)
+	pdf.Ln(4)
+
+	// --- 2. CONSUMIDOR ---
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 8, " 2. IDENTIFICACIÓN DEL CONSUMIDOR", "1", 1, "L", true, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	txtCons := fmt.Sprintf("Nombre: %s\nDocumento: %s %s\nDirección: %s\nContacto: %s / %s",
+		nombreCli, tDoc, nDoc, domicilioCli, telefonoCli, emailCli)
+	pdf.MultiCell(0, 6, tr(txtCons), "1", "L", false)
+	pdf.Ln(4)
+
+	// --- 3. DETALLE ---
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 8, " 3. DETALLE DEL RECLAMO / QUEJA", "1", 1, "L", true, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(0, 6, tr("Detalle: "+detReclamo), "1", "L", false)
+	pdf.MultiCell(0, 6, tr("Pedido: "+pedidoCli), "1", "L", false)
+	pdf.Ln(4)
+
+	// --- 4. RESOLUCIÓN ---
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 8, tr(" 4. RESOLUCIÓN DE LA EMPRESA"), "1", 1, "L", true, 0, "")
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(0, 7, "Respuesta:", "LR", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(0, 6, tr(respuestaTexto), "LRB", "L", false)
+
+	if accionTomada != "" {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.CellFormat(0, 7, tr("Acción adoptada:"), "LR", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 10)
+		pdf.MultiCell(0, 6, tr(accionTomada), "LRB", "L", false)
+	}
+
+	pdf.SetY(-35)
+	pdf.SetFont("Arial", "I", 8)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.MultiCell(0, 4, tr("Documento generado conforme a la Ley N° 29571. Codeplex SaaS."), "T", "C", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// SugerenciaRespuestaService redacta con IA un borrador de respuesta a un
+// reclamo: respuesta_empresa, accion_tomada y compensacion_ofrecida. Usa el
+// relato del consumidor, los mensajes de seguimiento y cómo respondió el
+// tenant a casos parecidos. El borrador no se guarda.
+type SugerenciaRespuestaService struct {
+	aiProvider    ai.Provider
+	reclamoRepo   *repo.ReclamoRepo
+	mensajeRepo   *repo.MensajeRepo
+	respuestaRepo *repo.RespuestaRepo
+	tenantRepo    *repo.TenantRepo
+	usoIA         *UsoIAService
+}
+
+func NewSugerenciaRespuestaService(
+	aiProvider ai.Provider,
+	reclamoRepo *repo.ReclamoRepo,
+	mensajeRepo *repo.MensajeRepo,
+	respuestaRepo *repo.RespuestaRepo,
+	tenantRepo *repo.TenantRepo,
+	usoIA *UsoIAService,
+) *SugerenciaRespuestaService {
+	return &SugerenciaRespuestaService{
+		aiProvider:    aiProvider,
+		reclamoRepo:   reclamoRepo,
+		mensajeRepo:   mensajeRepo,
+		respuestaRepo: respuestaRepo,
+		tenantRepo:    tenantRepo,
+		usoIA:         usoIA,
+	}
+}
+
+const (
+	maxCasosReferencia      = 3
+	maxMensajesSugerencia   = 20
+	maxTerminosSimilitud    = 15
+	toolRedactarRespuesta   = "redactar_respuesta"
+	minRunasTerminoBusqueda = 4
+)
+
+// herramientaRedactar fija el formato del borrador. Si el proveedor responde
+// con texto en lugar de invocarla, se intenta leer el JSON del texto con el
+// mismo schema.
+var herramientaRedactar = ai.Tool{
+	Name:        toolRedactarRespuesta,
+	Description: "Entrega el borrador de respuesta al consumidor.",
+	Parameters: &ai.Schema{
+		Type: "object",
+		Properties: map[string]*ai.Schema{
+			"respuesta_empresa":     {Type: "string", Description: "Respuesta formal dirigida al consumidor", MinLength: intPtr(20), MaxLength: intPtr(4000)},
+			"accion_tomada":         {Type: "string", Description: "Acción concreta que toma la empresa; vacío si no aplica", MaxLength: intPtr(1000)},
+			"compensacion_ofrecida": {Type: "string", Description: "Compensación ofrecida; vacío si no corresponde", MaxLength: intPtr(1000)},
+		},
+		Required: []string{"respuesta_empresa", "accion_tomada", "compensacion_ofrecida"},
+	},
+}
+
+type borradorRespuestaIA struct {
+	RespuestaEmpresa     string `json:"respuesta_empresa"`
+	AccionTomada         string `json:"accion_tomada"`
+	CompensacionOfrecida string `json:"compensacion_ofrecida"`
+}
+
+// Sugerir redacta el borrador para el reclamo. sedeID != nil (SOPORTE con
+// sede) restringe el reclamo y los casos de referencia a esa sede.
+func (s *SugerenciaRespuestaService) Sugerir(ctx context.Context, tenantID, reclamoID uuid.UUID, sedeID *uuid.UUID) (*model.SugerenciaRespuesta, error) {
+	if err := s.usoIA.ValidarCuota(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	rec, err := s.reclamoRepo.GetByID(ctx, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("sugerencia_respuesta_service.Sugerir: %w", err)
+	}
+	if rec == nil {
+		return nil, apperror.ErrNotFound
+	}
+	if sedeID != nil && (!rec.SedeID.Valid || rec.SedeID.UUID != *sedeID) {
+		return nil, apperror.ErrSedeNoPermitida
+	}
+
+	mensajes, err := s.mensajeRepo.GetByReclamo(ctx, tenantID, reclamoID)
+	if err != nil {
+		return nil, fmt.Errorf("sugerencia_respuesta_service.Sugerir: %w", err)
+	}
+	if len(mensajes) > maxMensajesSugerencia {
+		mensajes = mensajes[len(mensajes)-maxMensajesSugerencia:]
+	}
+
+	casos := make([]model.CasoRespondido, 0)
+	if terminos := terminosSimilitud(rec.DescripcionBien, rec.DetalleReclamo, rec.PedidoConsumidor, rec.DescripcionSituacion.String); terminos != "" {
+		casos, err = s.respuestaRepo.CasosSimilares(ctx, tenantID, reclamoID, sedeID, terminos, maxCasosReferencia)
+		if err != nil {
+			// Sin referencias se puede redactar igual
+			fmt.Printf("[WARN Sugerencia] casos similares: %v\n", err)
+			casos = make([]model.CasoRespondido, 0)
+		}
+	}
+
+	razonSocial := ""
+	if tenant, err := s.tenantRepo.GetByTenantID(ctx, tenantID); err == nil && tenant != nil {
+		razonSocial = tenant.RazonSocial
+	}
+
+	resp, err := s.aiProvider.Chat(ctx, ai.ChatRequest{
+		SystemPrompt: promptSugerenciaRespuesta(razonSocial),
+		Messages: []ai.Message{
+			{Role: "user", Content: contextoSugerencia(rec, mensajes, casos)},
+		},
+		MaxTokens: 2048,
+		Tools:     []ai.Tool{herramientaRedactar},
+	})
+	s.usoIA.Registrar(ctx, tenantID, model.OrigenIASugerencia, resp)
+	if err != nil {
+		fmt.Printf("[ERROR Sugerencia] IA: %v\n", err)
+		return nil, apperror.ErrIANoDisponible
+	}
+
+	borrador, err := leerBorrador(resp)
+	if err != nil {
+		fmt.Printf("[WARN Sugerencia] borrador inválido: %v\n", err)
+		return nil, apperror.ErrSugerenciaInvalida
+	}
+
+	sug := &model.SugerenciaRespuesta{
+		RespuestaEmpresa:     strings.TrimSpace(borrador.RespuestaEmpresa),
+		AccionTomada:         strings.TrimSpace(borrador.AccionTomada),
+		CompensacionOfrecida: strings.TrimSpace(borrador.CompensacionOfrecida),
+		CasosReferencia:      make([]string, 0, len(casos)),
+		Proveedor:            resp.Provider,
+		PromptTokens:         resp.PromptTokens,
+		OutputTokens:         resp.OutputTokens,
+	}
+	for _, c := range casos {
+		sug.CasosReferencia = append(sug.CasosReferencia, c.CodigoReclamo)
+	}
+	return sug, nil
+}
+
+// leerBorrador toma los argumentos de redactar_respuesta o, si el modelo
+// respondió en texto, el objeto JSON contenido en él.
+func leerBorrador(resp *ai.ChatResponse) (*borradorRespuestaIA, error) {
+	var b borradorRespuestaIA
+	if err := herramientaRedactar.DecodificarRespuesta(resp, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// terminosSimilitud arma un tsquery OR con las palabras significativas del
+// relato. Solo letras y dígitos: el resultado es seguro para to_tsquery.
+func terminosSimilitud(textos ...string) string {
+	vistos := make(map[string]bool)
+	var terminos []string
+	for _, texto := range textos {
+		palabras := strings.FieldsFunc(strings.ToLower(texto), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, p := range palabras {
+			if len([]rune(p)) < minRunasTerminoBusqueda || vistos[p] {
+				continue
+			}
+			vistos[p] = true
+			terminos = append(terminos, p)
+			if len(terminos) == maxTerminosSimilitud {
+				return strings.Join(terminos, " | ")
+			}
+		}
+	}
+	return strings.Join(terminos, " | ")
+}
+
+func promptSugerenciaRespuesta(razonSocial string) string {
+	empresa := "la empresa"
+	if razonSocial != "" {
+		empresa = razonSocial
+	}
+	return fmt.Sprintf(`Eres un especialista en atención al cliente de %s y redactas respuestas a reclamos y quejas del Libro de Reclamaciones (Perú, Código de Protección y Defensa del Consumidor). Escribes en español, con tono formal, empático y claro.
+
+Redacta un borrador con:
+- respuesta_empresa: carta dirigida al consumidor por su nombre. Reconoce el problema concreto, explica lo que la empresa hará y cierra de forma cordial. Sin firmas ni datos inventados.
+- accion_tomada: la acción concreta (reembolso, cambio, reparación, capacitación, etc.). Vacío si no aplica.
+- compensacion_ofrecida: solo si corresponde según el pedido del consumidor y los casos de referencia. Vacío si no.
+
+REGLAS:
+- Usa SOLO los hechos del reclamo y de los mensajes. No inventes montos, fechas, números de pedido ni políticas.
+- Los CASOS DE REFERENCIA muestran cómo la empresa resolvió situaciones parecidas: úsalos como guía de criterio y tono, nunca copies sus datos.
+- Si falta información para decidir, propón la acción más prudente y dilo en la respuesta.
+
+Entrega el borrador llamando a la herramienta %s. Si no puedes llamarla, responde SOLO con un objeto JSON con las claves respuesta_empresa, accion_tomada y compensacion_ofrecida.`,
+		empresa, toolRedactarRespuesta)
+}
+
+// contextoSugerencia datos del reclamo para el modelo. Se omiten documento,
+// correo y teléfono del consumidor: no hacen falta para redactar.
+func contextoSugerencia(rec *model.Reclamo, mensajes []model.Mensaje, casos []model.CasoRespondido) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "RECLAMO %s (%s, estado %s)\n", rec.CodigoReclamo, rec.TipoSolicitud, rec.Estado)
+	fmt.Fprintf(&b, "Consumidor: %s\n", rec.NombreCompleto)
+	fmt.Fprintf(&b, "Fecha del incidente: %s | Registrado: %s\n",
+		rec.FechaIncidente.Format("2006-01-02"), rec.FechaRegistro.Format("2006-01-02"))
+	if rec.SedeNombre.Valid {
+		fmt.Fprintf(&b, "Sede: %s\n", rec.SedeNombre.String)
+	}
+	fmt.Fprintf(&b, "Bien contratado: %s\n", truncarTexto(rec.DescripcionBien, 300))
+	if rec.MontoReclamado.Valid {
+		fmt.Fprintf(&b, "Monto reclamado: S/ %.2f\n", rec.MontoReclamado.Float64)
+	}
+	if rec.DescripcionSituacion.Valid && rec.DescripcionSituacion.String != "" {
+		fmt.Fprintf(&b, "Situación: %s\n", truncarTexto(rec.DescripcionSituacion.String, 1000))
+	}
+	fmt.Fprintf(&b, "Detalle: %s\n", truncarTexto(rec.DetalleReclamo, 2000))
+	fmt.Fprintf(&b, "Pedido del consumidor: %s\n", truncarTexto(rec.PedidoConsumidor, 1000))
+
+	if len(mensajes) > 0 {
+		b.WriteString("\nMENSAJES DE SEGUIMIENTO:\n")
+		for _, m := range mensajes {
+			fmt.Fprintf(&b, "- [%s %s] %s\n", m.FechaMensaje.Format("2006-01-02"), m.TipoMensaje, truncarTexto(m.MensajeTexto, 500))
+		}
+	}
+
+	if len(casos) > 0 {
+		b.WriteString("\nCASOS DE REFERENCIA (respuestas anteriores de la empresa):\n")
+		for i, c := range casos {
+			fmt.Fprintf(&b, "%d. %s (%s, %s)\n", i+1, c.CodigoReclamo, c.TipoSolicitud, c.Estado)
+			fmt.Fprintf(&b, "   Detalle: %s\n", c.DetalleReclamo)
+			fmt.Fprintf(&b, "   Pedido: %s\n", c.PedidoConsumidor)
+			fmt.Fprintf(&b, "   Respuesta: %s\n", c.RespuestaEmpresa)
+			if c.AccionTomada.Valid && c.AccionTomada.String != "" {
+				fmt.Fprintf(&b, "   Acción: %s\n", c.AccionTomada.String)
+			}
+			if c.CompensacionOfrecida.Valid && c.CompensacionOfrecida.String != "" {
+				fmt.Fprintf(&b, "   Compensación: %s\n", c.CompensacionOfrecida.String)
+			}
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// UsoIAService mide el consumo de tokens de IA por tenant y aplica la cuota
+// mensual del plan (recurso TOKENS_IA de LimitesService).
+type UsoIAService struct {
+	usoIARepo      *repo.UsoIARepo
+	limitesService *LimitesService
+}
+
+func NewUsoIAService(usoIARepo *repo.UsoIARepo, limitesService *LimitesService) *UsoIAService {
+	return &UsoIAService{usoIARepo: usoIARepo, limitesService: limitesService}
+}
+
+// ValidarCuota retorna un apperror si el tenant agotó los tokens del mes.
+func (s *UsoIAService) ValidarCuota(ctx context.Context, tenantID uuid.UUID) error {
+	return s.limitesService.ValidarCreacion(ctx, tenantID, model.RecursoTokensIA)
+}
+
+// CuotaDisponible versión sin error para degradar en lugar de fallar (WhatsApp).
+func (s *UsoIAService) CuotaDisponible(ctx context.Context, tenantID uuid.UUID) bool {
+	return s.limitesService.PuedeCrear(ctx, tenantID, model.RecursoTokensIA)
+}
+
+// Registrar anota los tokens de una respuesta de la IA. Un fallo del ledger
+// no debe cortar la conversación: se registra en el log y se continúa.
+func (s *UsoIAService) Registrar(ctx context.Context, tenantID uuid.UUID, origen string, resp *ai.ChatResponse) {
+	if resp == nil || resp.PromptTokens+resp.OutputTokens == 0 {
+		return
+	}
+	u := &model.UsoIA{
+		TenantModel:  model.TenantModel{TenantID: tenantID},
+		Origen:       origen,
+		Proveedor:    resp.Provider,
+		TokensPrompt: resp.PromptTokens,
+		TokensOutput: resp.OutputTokens,
+	}
+	if err := s.usoIARepo.Registrar(context.WithoutCancel(ctx), u); err != nil {
+		fmt.Printf("[ERROR UsoIA] tenant %s: %v\n", tenantID, err)
+	}
+}
+
+// maxDiasReporteIA rango máximo del reporte.
+const maxDiasReporteIA = 366
+
+// Reporte consumo de IA en [desde, hasta] (fechas inclusive) más la cuota del mes.
+func (s *UsoIAService) Reporte(ctx context.Context, tenantID uuid.UUID, desde, hasta time.Time) (*model.ReporteUsoIA, error) {
+	hastaExcl := hasta.AddDate(0, 0, 1)
+	if !desde.Before(hastaExcl) {
+		return nil, apperror.ErrRangoFechasInvalido.Withf("fecha_desde debe ser anterior o igual a fecha_hasta")
+	}
+	if hastaExcl.Sub(desde) > maxDiasReporteIA*24*time.Hour {
+		return nil, apperror.ErrRangoFechasInvalido.Withf(fmt.Sprintf("máximo %d días", maxDiasReporteIA))
+	}
+
+	rep := &model.ReporteUsoIA{Desde: desde, Hasta: hasta}
+
+	var err error
+	if rep.PorOrigen, err = s.usoIARepo.PorOrigen(ctx, tenantID, desde, hastaExcl); err != nil {
+		return nil, fmt.Errorf("uso_ia_service.Reporte: %w", err)
+	}
+	if rep.PorProveedor, err = s.usoIARepo.PorProveedor(ctx, tenantID, desde, hastaExcl); err != nil {
+		return nil, fmt.Errorf("uso_ia_service.Reporte: %w", err)
+	}
+	if rep.PorDia, err = s.usoIARepo.PorDia(ctx, tenantID, desde, hastaExcl); err != nil {
+		return nil, fmt.Errorf("uso_ia_service.Reporte: %w", err)
+	}
+	for _, g := range rep.PorOrigen {
+		rep.Llamadas += g.Llamadas
+		rep.TokensPrompt += g.TokensPrompt
+		rep.TokensOutput += g.TokensOutput
+	}
+
+	uso, err := s.limitesService.ObtenerUso(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	rep.UsoMes = uso.UsoTokensIAMes
+	rep.LimiteMes = uso.LimiteTokensIAMes
+	rep.PorcentajeMes = uso.PorcentajeUso(model.RecursoTokensIA)
+	rep.CuotaAgotada = !uso.PuedeCrear(model.RecursoTokensIA)
+
+	return rep, nil
+}
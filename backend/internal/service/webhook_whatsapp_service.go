@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// CabeceraFirmaMeta header con el que Meta firma cada POST al webhook.
+const CabeceraFirmaMeta = "X-Hub-Signature-256"
+
+// MensajesProcesadosStore registro de idempotencia de los mensajes entrantes
+// por (tenant, wamid). En producción se usa repo.MensajeWhatsAppProcesadoRepo
+// para que los reintentos se detecten en cualquier réplica.
+type MensajesProcesadosStore interface {
+	// Registrar marca el mensaje como recibido; false si ya estaba registrado.
+	Registrar(ctx context.Context, tenantID uuid.UUID, mensajeID, phoneNumberID string) (bool, error)
+	// Liberar borra el registro de un mensaje que no se llegó a encolar.
+	Liberar(ctx context.Context, tenantID uuid.UUID, mensajeID string) error
+}
+
+// MensajesProcesadosStoreMemoria implementación en memoria del proceso (tests y desarrollo).
+type MensajesProcesadosStoreMemoria struct {
+	mu       sync.Mutex
+	mensajes map[claveMensajeProcesado]time.Time
+	vigencia time.Duration
+}
+
+type claveMensajeProcesado struct {
+	tenantID  uuid.UUID
+	mensajeID string
+}
+
+func NewMensajesProcesadosStoreMemoria(vigencia time.Duration) *MensajesProcesadosStoreMemoria {
+	return &MensajesProcesadosStoreMemoria{
+		mensajes: make(map[claveMensajeProcesado]time.Time),
+		vigencia: vigencia,
+	}
+}
+
+func (m *MensajesProcesadosStoreMemoria) Registrar(_ context.Context, tenantID uuid.UUID, mensajeID, _ string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clave := claveMensajeProcesado{tenantID: tenantID, mensajeID: mensajeID}
+	if recibido, ok := m.mensajes[clave]; ok && time.Since(recibido) < m.vigencia {
+		return false, nil
+	}
+	m.mensajes[clave] = time.Now()
+	return true, nil
+}
+
+func (m *MensajesProcesadosStoreMemoria) Liberar(_ context.Context, tenantID uuid.UUID, mensajeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.mensajes, claveMensajeProcesado{tenantID: tenantID, mensajeID: mensajeID})
+	return nil
+}
+
+// MetricasWebhookWhatsApp contadores del webhook desde que arrancó la réplica.
+type MetricasWebhookWhatsApp struct {
+	Aceptados     int64     `json:"aceptados"`
+	FirmaAusente  int64     `json:"firma_ausente"`
+	FirmaInvalida int64     `json:"firma_invalida"`
+	SinSecreto    int64     `json:"sin_secreto"` // ni secreto global ni app_secret del canal
+	Duplicados    int64     `json:"mensajes_duplicados"`
+	Desde         time.Time `json:"desde"`
+}
+
+// WebhookWhatsAppService seguridad del webhook de WhatsApp: valida la firma de
+// Meta antes de procesar nada, descarta los reintentos de un mismo mensaje y
+// cuenta los rechazos.
+type WebhookWhatsAppService struct {
+	appSecret   string
+	canalWARepo *repo.CanalWhatsAppRepo
+	procesados  MensajesProcesadosStore
+
+	desde         time.Time
+	aceptados     atomic.Int64
+	firmaAusente  atomic.Int64
+	firmaInvalida atomic.Int64
+	sinSecreto    atomic.Int64
+	duplicados    atomic.Int64
+}
+
+func NewWebhookWhatsAppService(
+	appSecret string,
+	canalWARepo *repo.CanalWhatsAppRepo,
+	procesados MensajesProcesadosStore,
+) *WebhookWhatsAppService {
+	return &WebhookWhatsAppService{
+		appSecret:   appSecret,
+		canalWARepo: canalWARepo,
+		procesados:  procesados,
+		desde:       time.Now(),
+	}
+}
+
+// VerificarFirma valida X-Hub-Signature-256 contra el body crudo. Primero con
+// el secreto global; si no coincide, cada número del payload debe validar con
+// el app_secret de su canal (canales conectados a otra app de Meta).
+func (s *WebhookWhatsAppService) VerificarFirma(ctx context.Context, cuerpo []byte, firma string, phoneNumberIDs []string) error {
+	if firma == "" {
+		s.firmaAusente.Add(1)
+		return apperror.ErrFirmaWebhookAusente
+	}
+	if helper.FirmaMetaValida(cuerpo, firma, s.appSecret) {
+		s.aceptados.Add(1)
+		return nil
+	}
+
+	sinSecreto := s.appSecret == "" && len(phoneNumberIDs) == 0
+	validos := 0
+	for _, phoneNumberID := range phoneNumberIDs {
+		canal, err := s.canalWARepo.GetByPhoneNumberID(ctx, phoneNumberID)
+		if err != nil {
+			return fmt.Errorf("webhook_whatsapp_service.VerificarFirma: %w", err)
+		}
+		if canal == nil || canal.AppSecret == "" {
+			sinSecreto = s.appSecret == ""
+			break
+		}
+		if !helper.FirmaMetaValida(cuerpo, firma, canal.AppSecret) {
+			break
+		}
+		validos++
+	}
+	if len(phoneNumberIDs) > 0 && validos == len(phoneNumberIDs) {
+		s.aceptados.Add(1)
+		return nil
+	}
+
+	if sinSecreto {
+		s.sinSecreto.Add(1)
+	} else {
+		s.firmaInvalida.Add(1)
+	}
+	return apperror.ErrFirmaWebhookInvalida
+}
+
+// RegistrarMensaje registra el wamid antes de procesarlo. Devuelve false si el
+// mensaje ya se recibió: Meta lo reintenta si no obtuvo un 200 a tiempo y el
+// bot no debe responder ni registrar el reclamo dos veces.
+func (s *WebhookWhatsAppService) RegistrarMensaje(ctx context.Context, canal *CanalResuelto, mensajeID string) (bool, error) {
+	if mensajeID == "" {
+		return true, nil
+	}
+	nuevo, err := s.procesados.Registrar(ctx, canal.TenantID, mensajeID, canal.PhoneID)
+	if err != nil {
+		return false, fmt.Errorf("webhook_whatsapp_service.RegistrarMensaje: %w", err)
+	}
+	if !nuevo {
+		s.duplicados.Add(1)
+	}
+	return nuevo, nil
+}
+
+// LiberarMensaje deshace RegistrarMensaje cuando el mensaje no se pudo
+// encolar, para que el reintento de Meta sí se procese.
+func (s *WebhookWhatsAppService) LiberarMensaje(ctx context.Context, canal *CanalResuelto, mensajeID string) error {
+	if mensajeID == "" {
+		return nil
+	}
+	if err := s.procesados.Liberar(ctx, canal.TenantID, mensajeID); err != nil {
+		return fmt.Errorf("webhook_whatsapp_service.LiberarMensaje: %w", err)
+	}
+	return nil
+}
+
+// Metricas contadores de esta réplica.
+func (s *WebhookWhatsAppService) Metricas() MetricasWebhookWhatsApp {
+	return MetricasWebhookWhatsApp{
+		Aceptados:     s.aceptados.Load(),
+		FirmaAusente:  s.firmaAusente.Load(),
+		FirmaInvalida: s.firmaInvalida.Load(),
+		SinSecreto:    s.sinSecreto.Load(),
+		Duplicados:    s.duplicados.Load(),
+		Desde:         s.desde,
+	}
+}
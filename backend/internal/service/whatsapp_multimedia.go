@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"libro-reclamaciones/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ── Mensajes que no son texto: archivos, notas de voz, ubicación y opciones ──
+
+const maxBytesAudioWhatsApp = 16 << 20 // límite de audio de la Cloud API
+
+// Prefijos del ID de las opciones interactivas; vuelven en la respuesta del cliente.
+const (
+	prefijoOpcionTipo = "tipo_solicitud:"
+	prefijoOpcionSede = "sede:"
+)
+
+const (
+	textoTipoNoSoportado = "Por ahora no puedo procesar este tipo de mensaje. " +
+		"Puedes escribirme, enviarme una nota de voz, fotos o documentos. 📝"
+	textoSinNotasDeVoz = "Por ahora no puedo escuchar notas de voz. ¿Podrías escribirme tu mensaje? 📝"
+)
+
+// extensionesEvidencia nombre por defecto de las fotos y documentos sin filename.
+var extensionesEvidencia = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       ".xlsx",
+}
+
+// ProcesarEntrante genera la respuesta a un mensaje de la cola según su tipo.
+func (s *WhatsAppService) ProcesarEntrante(ctx context.Context, canal *CanalResuelto, m *model.MensajeWhatsAppEntrante) MensajeSaliente {
+	if m.Tipo == model.WhatsAppTipoTexto {
+		if !m.Texto.Valid {
+			return MensajeTexto(textoTipoNoSoportado)
+		}
+		fmt.Printf("[WhatsApp] Mensaje de %s: %s\n", m.Telefono, m.Texto.String)
+		return s.ProcesarMensaje(ctx, canal, m.Telefono, m.Texto.String)
+	}
+
+	c := m.Contenido
+	if c == nil {
+		fmt.Printf("[WhatsApp] Mensaje %s de %s no soportado (%s)\n", m.MensajeID, m.Telefono, m.Tipo)
+		return MensajeTexto(textoTipoNoSoportado)
+	}
+	fmt.Printf("[WhatsApp] Mensaje de %s: [%s]\n", m.Telefono, m.Tipo)
+
+	switch m.Tipo {
+	case model.WhatsAppTipoImagen, model.WhatsAppTipoDocumento:
+		return s.procesarEvidencia(ctx, canal, m.Telefono, m.Tipo, c)
+	case model.WhatsAppTipoAudio:
+		return s.procesarNotaDeVoz(ctx, canal, m.Telefono, c)
+	case model.WhatsAppTipoUbicacion:
+		return s.ProcesarMensaje(ctx, canal, m.Telefono, textoUbicacion(c))
+	case model.WhatsAppTipoInteractivo, model.WhatsAppTipoBoton:
+		return s.ProcesarMensaje(ctx, canal, m.Telefono, textoOpcionElegida(c))
+	default:
+		return MensajeTexto(textoTipoNoSoportado)
+	}
+}
+
+// ── Fotos y documentos: evidencias del reclamo ──────────────────────────────
+
+// procesarEvidencia guarda el archivo para adjuntarlo al registrar el reclamo.
+// Solo se guarda la referencia de Meta; se descarga y valida al registrar.
+func (s *WhatsAppService) procesarEvidencia(ctx context.Context, canal *CanalResuelto, telefono, tipo string, c *model.ContenidoWhatsApp) MensajeSaliente {
+	tenantID := canal.TenantID
+
+	mime := mimeBase(c.MIME)
+	if c.MediaID == "" || !mimesPermitidos[mime] {
+		return MensajeTexto("No puedo adjuntar ese tipo de archivo. 📎\n\n" +
+			"Envíame fotos (JPG, PNG) o documentos PDF, Word o Excel.")
+	}
+
+	ev := model.EvidenciaWhatsApp{
+		MediaID:      c.MediaID,
+		MIME:         mime,
+		Nombre:       nombreEvidencia(tipo, c),
+		FechaRecibio: time.Now(),
+	}
+	maxArchivos := s.adjuntoService.MaxArchivos()
+	agregada, err := s.conversaciones.AgregarEvidencia(ctx, tenantID, telefono, ev, maxArchivos, ttlConversacion)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Error guardando evidencia de %s: %v\n", telefono, err)
+		return MensajeTexto("No pude recibir tu archivo. ¿Podrías enviarlo de nuevo? 🙏")
+	}
+	if !agregada {
+		return MensajeTexto(fmt.Sprintf("Ya recibí %d archivos, el máximo que puedo adjuntar a un reclamo. 📎", maxArchivos))
+	}
+
+	nota := fmt.Sprintf("[Envié %s como evidencia: %s]", descripcionEvidencia(tipo), ev.Nombre)
+	if caption := strings.TrimSpace(c.Caption); caption != "" {
+		nota += "\n" + caption
+	}
+
+	// Si el archivo trae texto, o un asesor atiende la conversación, sigue el flujo normal
+	if c.Caption != "" || s.tieneSolicitudActiva(ctx, tenantID, telefono) {
+		return s.ProcesarMensaje(ctx, canal, telefono, nota)
+	}
+
+	respuesta := "📎 Recibí tu archivo. Lo adjuntaré como evidencia cuando registremos tu reclamo."
+	s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "user", nota)
+	s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+	return MensajeTexto(respuesta)
+}
+
+// evidenciasParaReclamo descarga y valida los archivos pendientes de la
+// conversación. Los que no se pueden adjuntar (media vencido, muy grande o de
+// un tipo no permitido) se omiten y se cuentan.
+func (s *WhatsAppService) evidenciasParaReclamo(ctx context.Context, canal *CanalResuelto, telefono string) ([]ArchivoSubido, int) {
+	evidencias, err := s.conversaciones.Evidencias(ctx, canal.TenantID, telefono, ttlConversacion)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Error leyendo evidencias de %s: %v\n", telefono, err)
+		return nil, 0
+	}
+
+	var (
+		archivos []ArchivoSubido
+		omitidos int
+	)
+	for _, ev := range evidencias {
+		datos, _, err := DescargarMediaWhatsApp(ctx, canal.AccessToken, ev.MediaID, s.adjuntoService.MaxBytes())
+		if err != nil {
+			fmt.Printf("[WhatsApp] Evidencia %s de %s no descargada: %v\n", ev.MediaID, telefono, err)
+			omitidos++
+			continue
+		}
+		archivo, err := s.adjuntoService.PrepararDatos(ev.Nombre, datos)
+		if err != nil {
+			fmt.Printf("[WhatsApp] Evidencia %s de %s rechazada: %v\n", ev.MediaID, telefono, err)
+			omitidos++
+			continue
+		}
+		archivos = append(archivos, archivo)
+	}
+	return archivos, omitidos
+}
+
+func (s *WhatsAppService) tieneSolicitudActiva(ctx context.Context, tenantID uuid.UUID, telefono string) bool {
+	sol, _ := s.solicitudAsesorService.BuscarActivaPorTelefono(ctx, tenantID, telefono)
+	return sol != nil
+}
+
+func nombreEvidencia(tipo string, c *model.ContenidoWhatsApp) string {
+	if c.Nombre != "" {
+		return limpiarNombreArchivo(c.Nombre)
+	}
+	prefijo := "documento"
+	if tipo == model.WhatsAppTipoImagen {
+		prefijo = "foto"
+	}
+	return prefijo + "-" + time.Now().Format("20060102-150405") + extensionesEvidencia[mimeBase(c.MIME)]
+}
+
+func descripcionEvidencia(tipo string) string {
+	if tipo == model.WhatsAppTipoImagen {
+		return "una foto"
+	}
+	return "un documento"
+}
+
+func mimeBase(mime string) string {
+	if i := strings.IndexByte(mime, ';'); i >= 0 {
+		mime = mime[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mime))
+}
+
+// ── Notas de voz ────────────────────────────────────────────────────────────
+
+// procesarNotaDeVoz transcribe el audio y lo responde como si fuera texto.
+func (s *WhatsAppService) procesarNotaDeVoz(ctx context.Context, canal *CanalResuelto, telefono string, c *model.ContenidoWhatsApp) MensajeSaliente {
+	if s.transcriptor == nil || c.MediaID == "" {
+		return MensajeTexto(textoSinNotasDeVoz)
+	}
+	// La transcripción también consume la cuota de IA del tenant
+	if !s.usoIA.CuotaDisponible(ctx, canal.TenantID) {
+		fmt.Printf("[WhatsApp] Tenant %s sin cuota de IA, nota de voz sin transcribir\n", canal.TenantID)
+		return MensajeTexto(textoSinNotasDeVoz)
+	}
+
+	audio, mime, err := DescargarMediaWhatsApp(ctx, canal.AccessToken, c.MediaID, maxBytesAudioWhatsApp)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Nota de voz de %s no descargada: %v\n", telefono, err)
+		return MensajeTexto("No pude escuchar tu nota de voz. ¿Podrías escribirme tu mensaje? 🙏")
+	}
+	if mime == "" {
+		mime = c.MIME
+	}
+
+	resp, err := s.transcriptor.Transcribir(ctx, audio, mime)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Nota de voz de %s sin transcribir (%s): %v\n", telefono, s.transcriptor.Name(), err)
+		return MensajeTexto("No pude entender tu nota de voz. ¿Podrías escribirme tu mensaje? 🙏")
+	}
+	// Se cobra aunque venga vacía: el proveedor ya procesó el audio
+	s.usoIA.Registrar(ctx, canal.TenantID, model.OrigenIATranscripcion, resp)
+	texto := resp.Content
+	if texto == "" {
+		fmt.Printf("[WhatsApp] Nota de voz de %s sin texto (%s)\n", telefono, s.transcriptor.Name())
+		return MensajeTexto("No pude entender tu nota de voz. ¿Podrías escribirme tu mensaje? 🙏")
+	}
+
+	fmt.Printf("[WhatsApp] Nota de voz de %s transcrita (%s): %s\n", telefono, s.transcriptor.Name(), texto)
+	return s.ProcesarMensaje(ctx, canal, telefono, texto)
+}
+
+// ── Ubicación y opciones elegidas ───────────────────────────────────────────
+
+// textoUbicacion describe la ubicación compartida para la IA.
+func textoUbicacion(c *model.ContenidoWhatsApp) string {
+	texto := "📍 Ubicación compartida"
+	if lugar := strings.TrimSpace(c.Lugar); lugar != "" {
+		texto += ": " + lugar
+	}
+	if direccion := strings.TrimSpace(c.Direccion); direccion != "" {
+		texto += ", " + direccion
+	}
+	return texto + fmt.Sprintf(" (%.6f, %.6f)", c.Latitud, c.Longitud)
+}
+
+// textoOpcionElegida convierte la opción elegida en texto. Las opciones del
+// bot llevan el valor entre paréntesis para que la IA lo use al registrar.
+func textoOpcionElegida(c *model.ContenidoWhatsApp) string {
+	titulo := strings.TrimSpace(c.OpcionTitulo)
+	switch {
+	case strings.HasPrefix(c.OpcionID, prefijoOpcionTipo):
+		return fmt.Sprintf("%s (tipo_solicitud: %s)", titulo, strings.TrimPrefix(c.OpcionID, prefijoOpcionTipo))
+	case strings.HasPrefix(c.OpcionID, prefijoOpcionSede):
+		return fmt.Sprintf("Sede %s (sede: %s)", titulo, strings.TrimPrefix(c.OpcionID, prefijoOpcionSede))
+	case titulo != "":
+		return titulo
+	default:
+		return c.OpcionID
+	}
+}
+
+// ── Botones y listas enviados por el bot ────────────────────────────────────
+
+// ofrecerOpciones arma los botones o la lista que pidió la IA. Si no se puede
+// (p. ej. demasiadas sedes) envía las opciones como texto.
+func (s *WhatsAppService) ofrecerOpciones(ctx context.Context, canal *CanalResuelto, telefono string, datos datosOfrecerOpciones, textoIA string) MensajeSaliente {
+	texto := strings.TrimSpace(datos.Mensaje)
+	if texto == "" {
+		texto = textoIA
+	}
+	texto = limpiarMarkdownParaWhatsApp(texto)
+
+	var (
+		mensaje MensajeSaliente
+		err     error
+	)
+	if datos.Opciones == opcionesSede {
+		mensaje, err = s.opcionesSede(ctx, canal.TenantID, texto)
+	} else {
+		if texto == "" {
+			texto = "¿Qué deseas registrar?\n\n" +
+				"*Reclamo:* disconformidad con un producto o servicio.\n" +
+				"*Queja:* malestar por la atención recibida."
+		}
+		mensaje, err = MensajeBotones(texto,
+			OpcionWhatsApp{ID: prefijoOpcionTipo + "RECLAMO", Titulo: "Reclamo"},
+			OpcionWhatsApp{ID: prefijoOpcionTipo + "QUEJA", Titulo: "Queja"},
+		)
+	}
+	if err != nil {
+		fmt.Printf("[WhatsApp] Opciones %q como texto: %v\n", datos.Opciones, err)
+		mensaje = MensajeTexto(texto)
+	}
+
+	s.agregarMensajeAlHistorial(ctx, canal.TenantID, telefono, "assistant", mensaje.TextoPlano())
+	return mensaje
+}
+
+// opcionesSede lista las sedes activas del tenant. Con una sola sede (o
+// ninguna) no hay nada que elegir; con más de las que caben en una lista se
+// envían como texto.
+func (s *WhatsAppService) opcionesSede(ctx context.Context, tenantID uuid.UUID, texto string) (MensajeSaliente, error) {
+	sedes, err := s.sedeRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		return MensajeSaliente{}, fmt.Errorf("whatsapp_service.opcionesSede: %w", err)
+	}
+	if texto == "" {
+		texto = "¿En cuál de nuestras sedes ocurrió?"
+	}
+
+	switch {
+	case len(sedes) == 0:
+		return MensajeTexto("El negocio no tiene sedes registradas: tu reclamo se registrará para la empresa en general. Continuemos. 😊"), nil
+
+	case len(sedes) == 1:
+		return MensajeTexto(fmt.Sprintf("Tu reclamo se registrará en la sede *%s*. Continuemos. 😊", sedes[0].Nombre)), nil
+
+	case len(sedes) > MaxFilasListaWhatsApp:
+		lineas := make([]string, len(sedes))
+		for i, sede := range sedes {
+			lineas[i] = "• " + sede.Nombre
+		}
+		return MensajeTexto(texto + "\n\n" + strings.Join(lineas, "\n") + "\n\nEscríbeme el nombre de la sede."), nil
+	}
+
+	opciones := make([]OpcionWhatsApp, len(sedes))
+	for i, sede := range sedes {
+		opciones[i] = OpcionWhatsApp{
+			ID:          prefijoOpcionSede + sede.Slug,
+			Titulo:      acortar(sede.Nombre, MaxTituloFilaWhatsApp),
+			Descripcion: acortar(sede.Direccion, MaxDescripcionFilaWhatsApp),
+		}
+	}
+	return MensajeLista(texto, "Ver sedes", opciones...)
+}
+
+// resolverSede retorna el slug de la sede que eligió el usuario, por slug o
+// por nombre (completo o el título recortado de la lista). "" si no existe.
+func (s *WhatsAppService) resolverSede(ctx context.Context, tenantID uuid.UUID, valor string) string {
+	sedes, err := s.sedeRepo.GetByTenant(ctx, tenantID)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Error listando sedes de %s: %v\n", tenantID, err)
+		return ""
+	}
+
+	valor = strings.Trim(valor, "* ")
+	for _, sede := range sedes {
+		if strings.EqualFold(sede.Slug, valor) || strings.EqualFold(sede.Nombre, valor) {
+			return sede.Slug
+		}
+	}
+
+	prefijo := strings.ToLower(strings.TrimSuffix(valor, "…"))
+	encontrada := ""
+	for _, sede := range sedes {
+		if prefijo != "" && strings.HasPrefix(strings.ToLower(sede.Nombre), prefijo) {
+			if encontrada != "" {
+				return "" // ambiguo
+			}
+			encontrada = sede.Slug
+		}
+	}
+	return encontrada
+}
+
+// acortar recorta el texto a limite caracteres, terminando en "…" si se cortó.
+func acortar(texto string, limite int) string {
+	texto = strings.TrimSpace(texto)
+	if utf8.RuneCountInString(texto) <= limite {
+		return texto
+	}
+	return strings.TrimSpace(string([]rune(texto)[:limite-1])) + "…"
+}
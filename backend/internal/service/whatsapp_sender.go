@@ -7,29 +7,297 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
-// EnviarMensajeWhatsApp envía un mensaje de texto vía la API de Meta.
-// Se usa para que el asesor responda al cliente desde el mismo número del bot.
-func EnviarMensajeWhatsApp(ctx context.Context, accessToken, phoneNumberID, telefonoDestino, texto string) error {
-	url := fmt.Sprintf("https://graph.facebook.com/v21.0/%s/messages", phoneNumberID)
+const graphAPIWhatsApp = "https://graph.facebook.com/v21.0"
+
+// ── Mensajes salientes ──────────────────────────────────────────────────────
+
+// Tipos de mensaje saliente.
+const (
+	MensajeSalienteTexto   = "text"
+	MensajeSalienteBotones = "button"
+	MensajeSalienteLista   = "list"
+)
+
+// Límites de la Cloud API para mensajes interactivos.
+const (
+	MaxBotonesWhatsApp         = 3
+	MaxTituloBotonWhatsApp     = 20
+	MaxFilasListaWhatsApp      = 10
+	MaxTituloFilaWhatsApp      = 24
+	MaxDescripcionFilaWhatsApp = 72
+	maxCuerpoInteractivo       = 1024
+	maxIDOpcionWhatsApp        = 200
+)
+
+// OpcionWhatsApp botón o fila de una lista. El ID vuelve en la respuesta del
+// cliente; el título es lo que ve.
+type OpcionWhatsApp struct {
+	ID          string `json:"id"`
+	Titulo      string `json:"titulo"`
+	Descripcion string `json:"descripcion,omitempty"` // solo filas de lista
+}
+
+// MensajeSaliente mensaje del bot: texto, botones de respuesta o lista. Se
+// guarda en JSON en la cola para que un reintento lo reenvíe igual.
+type MensajeSaliente struct {
+	Tipo     string           `json:"tipo"`
+	Texto    string           `json:"texto"`
+	Boton    string           `json:"boton,omitempty"` // botón que despliega la lista
+	Opciones []OpcionWhatsApp `json:"opciones,omitempty"`
+}
+
+// MensajeTexto mensaje de texto simple.
+func MensajeTexto(texto string) MensajeSaliente {
+	return MensajeSaliente{Tipo: MensajeSalienteTexto, Texto: texto}
+}
+
+// MensajeBotones mensaje con hasta 3 botones de respuesta rápida.
+func MensajeBotones(texto string, opciones ...OpcionWhatsApp) (MensajeSaliente, error) {
+	if len(opciones) == 0 || len(opciones) > MaxBotonesWhatsApp {
+		return MensajeSaliente{}, fmt.Errorf("whatsapp_sender: %d botones (1 a %d)", len(opciones), MaxBotonesWhatsApp)
+	}
+	if err := validarOpciones(texto, opciones, MaxTituloBotonWhatsApp); err != nil {
+		return MensajeSaliente{}, err
+	}
+	return MensajeSaliente{Tipo: MensajeSalienteBotones, Texto: texto, Opciones: opciones}, nil
+}
+
+// MensajeLista mensaje con una lista desplegable de hasta 10 opciones. boton es
+// el texto del botón que la abre.
+func MensajeLista(texto, boton string, opciones ...OpcionWhatsApp) (MensajeSaliente, error) {
+	if len(opciones) == 0 || len(opciones) > MaxFilasListaWhatsApp {
+		return MensajeSaliente{}, fmt.Errorf("whatsapp_sender: %d filas (1 a %d)", len(opciones), MaxFilasListaWhatsApp)
+	}
+	if boton == "" || utf8.RuneCountInString(boton) > MaxTituloBotonWhatsApp {
+		return MensajeSaliente{}, fmt.Errorf("whatsapp_sender: botón de lista %q (1 a %d caracteres)", boton, MaxTituloBotonWhatsApp)
+	}
+	if err := validarOpciones(texto, opciones, MaxTituloFilaWhatsApp); err != nil {
+		return MensajeSaliente{}, err
+	}
+	for _, o := range opciones {
+		if utf8.RuneCountInString(o.Descripcion) > MaxDescripcionFilaWhatsApp {
+			return MensajeSaliente{}, fmt.Errorf("whatsapp_sender: descripción de %q supera %d caracteres", o.ID, MaxDescripcionFilaWhatsApp)
+		}
+	}
+	return MensajeSaliente{Tipo: MensajeSalienteLista, Texto: texto, Boton: boton, Opciones: opciones}, nil
+}
+
+func validarOpciones(texto string, opciones []OpcionWhatsApp, maxTitulo int) error {
+	if texto == "" || utf8.RuneCountInString(texto) > maxCuerpoInteractivo {
+		return fmt.Errorf("whatsapp_sender: texto interactivo de %d caracteres (1 a %d)", utf8.RuneCountInString(texto), maxCuerpoInteractivo)
+	}
+	ids := make(map[string]bool, len(opciones))
+	for _, o := range opciones {
+		if o.ID == "" || len(o.ID) > maxIDOpcionWhatsApp || ids[o.ID] {
+			return fmt.Errorf("whatsapp_sender: id de opción %q vacío, largo o repetido", o.ID)
+		}
+		ids[o.ID] = true
+		if o.Titulo == "" || utf8.RuneCountInString(o.Titulo) > maxTitulo {
+			return fmt.Errorf("whatsapp_sender: título %q (1 a %d caracteres)", o.Titulo, maxTitulo)
+		}
+	}
+	return nil
+}
+
+// Vacio indica que no hay nada que enviar (p. ej. ACK ya enviado).
+func (m MensajeSaliente) Vacio() bool {
+	return strings.TrimSpace(m.Texto) == ""
+}
+
+// TextoPlano el mensaje como texto, con las opciones listadas: es lo que se
+// guarda en el historial de la IA.
+func (m MensajeSaliente) TextoPlano() string {
+	if len(m.Opciones) == 0 {
+		return m.Texto
+	}
+	titulos := make([]string, len(m.Opciones))
+	for i, o := range m.Opciones {
+		titulos[i] = o.Titulo
+	}
+	return m.Texto + "\n[Opciones: " + strings.Join(titulos, " | ") + "]"
+}
 
+// Payload cuerpo del POST /{phone-number-id}/messages de la Cloud API.
+func (m MensajeSaliente) Payload(telefonoDestino string) map[string]interface{} {
 	payload := map[string]interface{}{
 		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
 		"to":                telefonoDestino,
-		"type":              "text",
-		"text":              map[string]string{"body": texto},
 	}
 
+	switch m.Tipo {
+	case MensajeSalienteBotones:
+		botones := make([]map[string]interface{}, len(m.Opciones))
+		for i, o := range m.Opciones {
+			botones[i] = map[string]interface{}{
+				"type":  "reply",
+				"reply": map[string]string{"id": o.ID, "title": o.Titulo},
+			}
+		}
+		payload["type"] = "interactive"
+		payload["interactive"] = map[string]interface{}{
+			"type":   "button",
+			"body":   map[string]string{"text": m.Texto},
+			"action": map[string]interface{}{"buttons": botones},
+		}
+
+	case MensajeSalienteLista:
+		filas := make([]map[string]string, len(m.Opciones))
+		for i, o := range m.Opciones {
+			fila := map[string]string{"id": o.ID, "title": o.Titulo}
+			if o.Descripcion != "" {
+				fila["description"] = o.Descripcion
+			}
+			filas[i] = fila
+		}
+		payload["type"] = "interactive"
+		payload["interactive"] = map[string]interface{}{
+			"type": "list",
+			"body": map[string]string{"text": m.Texto},
+			"action": map[string]interface{}{
+				"button":   m.Boton,
+				"sections": []map[string]interface{}{{"rows": filas}},
+			},
+		}
+
+	default:
+		payload["type"] = "text"
+		payload["text"] = map[string]string{"body": m.Texto}
+	}
+	return payload
+}
+
+// ── Plantillas ──────────────────────────────────────────────────────────────
+
+// maxParametroPlantilla largo que se envía por parámetro; Meta rechaza el
+// mensaje completo si uno excede su límite.
+const maxParametroPlantilla = 900
+
+// MensajePlantilla plantilla aprobada en WhatsApp Manager. Es lo único que
+// Meta permite enviar fuera de la ventana de 24 h de una conversación.
+// Parametros reemplaza {{1}}, {{2}}, ... del cuerpo en orden.
+type MensajePlantilla struct {
+	Nombre     string
+	Idioma     string
+	Parametros []string
+}
+
+// Payload cuerpo del POST /{phone-number-id}/messages para la plantilla.
+func (m MensajePlantilla) Payload(telefonoDestino string) map[string]interface{} {
+	plantilla := map[string]interface{}{
+		"name":     m.Nombre,
+		"language": map[string]string{"code": m.Idioma},
+	}
+	if len(m.Parametros) > 0 {
+		parametros := make([]map[string]string, len(m.Parametros))
+		for i, p := range m.Parametros {
+			parametros[i] = map[string]string{"type": "text", "text": parametroPlantilla(p)}
+		}
+		plantilla["components"] = []map[string]interface{}{
+			{"type": "body", "parameters": parametros},
+		}
+	}
+	return map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                telefonoDestino,
+		"type":              "template",
+		"template":          plantilla,
+	}
+}
+
+// parametroPlantilla adapta el texto a las reglas de Meta: sin saltos de
+// línea ni tabulaciones, sin más de 4 espacios seguidos y nunca vacío.
+func parametroPlantilla(texto string) string {
+	texto = strings.Join(strings.Fields(texto), " ")
+	if texto == "" {
+		return "-"
+	}
+	return acortar(texto, maxParametroPlantilla)
+}
+
+// NumeroWhatsApp número de destino para la Cloud API (E.164 sin "+") a partir
+// del teléfono que dejó el consumidor, o "" si no es seguro a quién llegaría.
+// Se acepta un celular peruano (9 dígitos que empiezan con 9, con o sin 51) o
+// un número con código de país explícito (+ o 00). Un fijo o un número
+// extranjero sin código no se completa con 51: sería el celular de otra persona.
+func NumeroWhatsApp(telefono string) string {
+	t := strings.TrimSpace(telefono)
+	internacional := strings.HasPrefix(t, "+") || strings.HasPrefix(t, "00")
+	t = strings.TrimPrefix(t, "+")
+
+	var b strings.Builder
+	for _, r := range t {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case strings.ContainsRune(" -().", r):
+		default:
+			return ""
+		}
+	}
+	digitos := b.String()
+
+	if internacional {
+		digitos = strings.TrimPrefix(digitos, "00")
+		if len(digitos) < 8 || len(digitos) > 15 || digitos[0] == '0' {
+			return ""
+		}
+		// +51 solo con celular: WhatsApp no llega a fijos peruanos
+		if strings.HasPrefix(digitos, prefijoPaisWhatsApp) && !celularPeruano(digitos[len(prefijoPaisWhatsApp):]) {
+			return ""
+		}
+		return digitos
+	}
+	if celularPeruano(digitos) {
+		return prefijoPaisWhatsApp + digitos
+	}
+	if strings.HasPrefix(digitos, prefijoPaisWhatsApp) && celularPeruano(digitos[len(prefijoPaisWhatsApp):]) {
+		return digitos
+	}
+	return ""
+}
+
+func celularPeruano(digitos string) bool {
+	return len(digitos) == 9 && digitos[0] == '9'
+}
+
+// ── Envío ───────────────────────────────────────────────────────────────────
+
+// EnviarMensajeWhatsApp envía un mensaje de texto vía la API de Meta.
+// Se usa para que el asesor responda al cliente desde el mismo número del bot.
+func EnviarMensajeWhatsApp(ctx context.Context, accessToken, phoneNumberID, telefonoDestino, texto string) error {
+	return EnviarMensaje(ctx, accessToken, phoneNumberID, telefonoDestino, MensajeTexto(texto))
+}
+
+// EnviarMensaje envía un mensaje de cualquier tipo (texto, botones o lista).
+func EnviarMensaje(ctx context.Context, accessToken, phoneNumberID, telefonoDestino string, mensaje MensajeSaliente) error {
+	_, err := postMensajeWhatsApp(ctx, accessToken, phoneNumberID, mensaje.Payload(telefonoDestino))
+	return err
+}
+
+// EnviarPlantillaWhatsApp envía una plantilla aprobada y retorna el wamid con
+// el que Meta informa luego su entrega.
+func EnviarPlantillaWhatsApp(ctx context.Context, accessToken, phoneNumberID, telefonoDestino string, plantilla MensajePlantilla) (string, error) {
+	return postMensajeWhatsApp(ctx, accessToken, phoneNumberID, plantilla.Payload(telefonoDestino))
+}
+
+func postMensajeWhatsApp(ctx context.Context, accessToken, phoneNumberID string, payload map[string]interface{}) (string, error) {
+	url := fmt.Sprintf("%s/%s/messages", graphAPIWhatsApp, phoneNumberID)
+
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("whatsapp_sender.marshal: %w", err)
+		return "", fmt.Errorf("whatsapp_sender.marshal: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
-		return fmt.Errorf("whatsapp_sender.request: %w", err)
+		return "", fmt.Errorf("whatsapp_sender.request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 	req.Header.Set("Content-Type", "application/json")
@@ -37,14 +305,80 @@ func EnviarMensajeWhatsApp(ctx context.Context, accessToken, phoneNumberID, tele
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("whatsapp_sender.do: %w", err)
+		return "", fmt.Errorf("whatsapp_sender.do: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("whatsapp_sender: Meta API %d — %s", resp.StatusCode, string(respBody))
+		return "", fmt.Errorf("whatsapp_sender: Meta API %d — %s", resp.StatusCode, string(respBody))
 	}
 
-	return nil
-}
\ No newline at end of file
+	var enviado struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&enviado); err != nil || len(enviado.Messages) == 0 {
+		return "", nil
+	}
+	return enviado.Messages[0].ID, nil
+}
+
+// ── Descarga de archivos recibidos ──────────────────────────────────────────
+
+// DescargarMediaWhatsApp descarga un archivo recibido (imagen, documento,
+// audio): primero pide a Meta la URL temporal del media_id y luego lo baja con
+// el mismo token. Retorna error si pesa más de maxBytes.
+func DescargarMediaWhatsApp(ctx context.Context, accessToken, mediaID string, maxBytes int64) ([]byte, string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var media struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+		FileSize int64  `json:"file_size"`
+	}
+	if err := getGraphAPI(ctx, client, accessToken, graphAPIWhatsApp+"/"+mediaID, func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&media)
+	}); err != nil {
+		return nil, "", fmt.Errorf("whatsapp_sender.media: %w", err)
+	}
+	if media.URL == "" {
+		return nil, "", fmt.Errorf("whatsapp_sender.media: Meta no devolvió la URL de %s", mediaID)
+	}
+	if media.FileSize > maxBytes {
+		return nil, "", fmt.Errorf("whatsapp_sender.media: %s pesa %d bytes (máx. %d)", mediaID, media.FileSize, maxBytes)
+	}
+
+	var datos []byte
+	if err := getGraphAPI(ctx, client, accessToken, media.URL, func(r io.Reader) (err error) {
+		datos, err = io.ReadAll(io.LimitReader(r, maxBytes+1))
+		return err
+	}); err != nil {
+		return nil, "", fmt.Errorf("whatsapp_sender.media: %w", err)
+	}
+	if int64(len(datos)) > maxBytes {
+		return nil, "", fmt.Errorf("whatsapp_sender.media: %s supera %d bytes", mediaID, maxBytes)
+	}
+	return datos, media.MimeType, nil
+}
+
+func getGraphAPI(ctx context.Context, client *http.Client, accessToken, url string, leer func(io.Reader) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("Meta API %d — %s", resp.StatusCode, string(respBody))
+	}
+	return leer(resp.Body)
+}
@@ -2,13 +2,13 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/apperror"
 	"libro-reclamaciones/internal/model"
 	"libro-reclamaciones/internal/model/dto"
 	"libro-reclamaciones/internal/repo"
@@ -24,52 +24,22 @@ type WhatsAppService struct {
 	tenantRepo             *repo.TenantRepo
 	canalWARepo            *repo.CanalWhatsAppRepo
 	chatbotRepo            *repo.ChatbotRepo
+	sedeRepo               *repo.SedeRepo
+	adjuntoService         *AdjuntoService
 	iaProvider             ai.Provider
+	transcriptor           ai.Transcriptor // nil = sin notas de voz
+	usoIA                  *UsoIAService
 
-	// ── Memoria de conversación por teléfono ──
-	conversaciones   map[string]*conversacionWA
-	muConversaciones sync.RWMutex
-
-	// ── Throttle ACK para solicitudes en atención ──
-	ultimoACK   map[string]time.Time
-	muUltimoACK sync.RWMutex
-}
-
-// conversacionWA almacena el historial de mensajes de un usuario.
-type conversacionWA struct {
-	mensajes        []ai.Message
-	ultimaActividad time.Time
-	tenantID        uuid.UUID
+	// ── Memoria de conversación y throttle ACK por (tenant, teléfono) ──
+	conversaciones ConversacionStore
 }
 
 const (
 	ttlConversacion     = 15 * time.Minute
 	maxMensajesPorConvo = 20
-
-	// Marcador que la IA usa cuando tiene todos los datos confirmados
-	marcadorRegistro = ">>>REGISTRAR_RECLAMO:"
-	marcadorFin      = "<<<"
-
-	// Marcador para solicitar asesor humano
-	marcadorSolicitudAsesor = ">>>SOLICITAR_ASESOR:"
+	cooldownACK         = 5 * time.Minute
 )
 
-// datosReclamoWhatsApp estructura que la IA genera en JSON cuando el usuario confirma.
-type datosReclamoWhatsApp struct {
-	NombreCompleto  string `json:"nombre_completo"`
-	TipoDocumento   string `json:"tipo_documento"`
-	NumeroDocumento string `json:"numero_documento"`
-	Email           string `json:"email"`
-	Telefono        string `json:"telefono"`
-	Descripcion     string `json:"descripcion"`
-}
-
-// datosSolicitudAsesor estructura que la IA genera cuando el usuario quiere hablar con un asesor.
-type datosSolicitudAsesor struct {
-	Nombre string `json:"nombre"`
-	Motivo string `json:"motivo"`
-}
-
 func NewWhatsAppService(
 	reclamoService *ReclamoService,
 	solicitudAsesorService *SolicitudAsesorService,
@@ -77,23 +47,27 @@ func NewWhatsAppService(
 	tenantRepo *repo.TenantRepo,
 	canalWARepo *repo.CanalWhatsAppRepo,
 	chatbotRepo *repo.ChatbotRepo,
+	sedeRepo *repo.SedeRepo,
+	adjuntoService *AdjuntoService,
 	iaProvider ai.Provider,
+	transcriptor ai.Transcriptor,
+	usoIA *UsoIAService,
+	conversaciones ConversacionStore,
 ) *WhatsAppService {
-	svc := &WhatsAppService{
+	return &WhatsAppService{
 		reclamoService:         reclamoService,
 		solicitudAsesorService: solicitudAsesorService,
 		mensajeAtencionService: mensajeAtencionService,
 		tenantRepo:             tenantRepo,
 		canalWARepo:            canalWARepo,
 		chatbotRepo:            chatbotRepo,
+		sedeRepo:               sedeRepo,
+		adjuntoService:         adjuntoService,
 		iaProvider:             iaProvider,
-		conversaciones:         make(map[string]*conversacionWA),
-		ultimoACK:              make(map[string]time.Time),
+		transcriptor:           transcriptor,
+		usoIA:                  usoIA,
+		conversaciones:         conversaciones,
 	}
-
-	go svc.limpiarConversacionesExpiradas()
-
-	return svc
 }
 
 // ── Resolución dinámica del tenant ──────────────────────────────────────────
@@ -164,7 +138,9 @@ func (s *WhatsAppService) obtenerConfigIA(ctx context.Context, canal *CanalResue
 
 // ── Flujo principal con IA + memoria + registro real ────────────────────────
 
-func (s *WhatsAppService) ProcesarMensaje(ctx context.Context, canal *CanalResuelto, telefono, textoUsuario string) string {
+// ProcesarMensaje responde un mensaje de texto (o lo que llega como texto:
+// notas de voz transcritas, ubicaciones y opciones elegidas).
+func (s *WhatsAppService) ProcesarMensaje(ctx context.Context, canal *CanalResuelto, telefono, textoUsuario string) MensajeSaliente {
 	tenantID := canal.TenantID
 	textoLimpio := strings.TrimSpace(textoUsuario)
 
@@ -175,35 +151,41 @@ func (s *WhatsAppService) ProcesarMensaje(ctx context.Context, canal *CanalResue
 		fmt.Printf("[WhatsApp] Mensaje de %s desviado al panel (solicitud %s)\n", telefono, solActiva.ID)
 
 		// ACK con throttle: solo 1 cada 5 minutos para no spamear
-		if s.debeEnviarACK(telefono) {
-			return "📩 Tu mensaje fue recibido. Un asesor lo verá en breve.\n\nSi necesitas algo urgente, escribe *urgente*."
+		if s.debeEnviarACK(ctx, tenantID, telefono) {
+			return MensajeTexto("📩 Tu mensaje fue recibido. Un asesor lo verá en breve.\n\nSi necesitas algo urgente, escribe *urgente*.")
 		}
-		return "" // Ya se envió ACK recientemente, silencio
+		return MensajeSaliente{} // Ya se envió ACK recientemente, silencio
 	}
 
 	// ── Validación: mensaje demasiado largo ──
 	if len([]rune(textoLimpio)) > 700 {
-		return "Tu mensaje es demasiado largo. Por favor, sé más breve (máximo 700 caracteres). 📝"
+		return MensajeTexto("Tu mensaje es demasiado largo. Por favor, sé más breve (máximo 700 caracteres). 📝")
 	}
 
 	// ── Caso determinista: código de reclamo → buscar directo sin IA ──
 	if textoPareceCodigo(textoLimpio) {
 		respuesta := s.buscarReclamoEnBaseDeDatosYFormatear(ctx, tenantID, textoLimpio)
-		s.agregarMensajeAlHistorial(telefono, tenantID, "user", textoLimpio)
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
-		return respuesta
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "user", textoLimpio)
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+		return MensajeTexto(respuesta)
 	}
 
 	// ── Caso IA: lenguaje natural con memoria ──
 	if s.iaProvider == nil {
-		return s.respuestaFallbackSinIA(textoLimpio)
+		return MensajeTexto(s.respuestaFallbackSinIA(textoLimpio))
+	}
+
+	// Cuota mensual de tokens agotada → menú sin IA (la consulta por código sigue)
+	if !s.usoIA.CuotaDisponible(ctx, tenantID) {
+		fmt.Printf("[WhatsApp] Tenant %s sin cuota de tokens IA, respondiendo sin IA\n", tenantID)
+		return MensajeTexto(s.respuestaFallbackSinIA(textoLimpio))
 	}
 
 	// Agregar mensaje del usuario al historial
-	s.agregarMensajeAlHistorial(telefono, tenantID, "user", textoLimpio)
+	s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "user", textoLimpio)
 
 	// Obtener historial completo para enviar a la IA
-	historial := s.obtenerHistorial(telefono)
+	historial := s.obtenerHistorial(ctx, tenantID, telefono)
 
 	// Obtener contexto del tenant
 	contextoTenant := s.construirContextoTenant(ctx, tenantID)
@@ -217,62 +199,39 @@ func (s *WhatsAppService) ProcesarMensaje(ctx context.Context, canal *CanalResue
 		SystemPrompt: promptSistema,
 		Messages:     historial,
 		MaxTokens:    cfgIA.MaxTokens,
+		Tools:        herramientasWhatsApp,
 	})
+	s.usoIA.Registrar(ctx, tenantID, model.OrigenIAWhatsApp, respuestaIA)
 
 	if err != nil {
 		fmt.Printf("[WhatsApp] Error IA: %v\n", err)
-		return s.respuestaFallbackSinIA(textoLimpio)
+		return MensajeTexto(s.respuestaFallbackSinIA(textoLimpio))
 	}
 
 	contenidoIA := respuestaIA.Content
 	fmt.Printf("[WhatsApp] IA respondió (%s, %d tokens) a %s\n", respuestaIA.Provider, respuestaIA.OutputTokens, telefono)
 
-	// ── Detectar si la IA quiere registrar el reclamo ──
-	if strings.Contains(contenidoIA, marcadorRegistro) {
-		return s.procesarRegistroDesdeIA(ctx, tenantID, telefono, contenidoIA)
-	}
-
-	// ── Detectar si la IA quiere solicitar un asesor ──
-	if strings.Contains(contenidoIA, marcadorSolicitudAsesor) {
-		return s.procesarSolicitudAsesorDesdeIA(ctx, canal, telefono, contenidoIA)
+	// ── La IA invocó una herramienta (registrar, consultar, asesor, opciones) ──
+	if len(respuestaIA.ToolCalls) > 0 {
+		return s.ejecutarHerramienta(ctx, canal, telefono, respuestaIA.ToolCalls[0], contenidoIA)
 	}
 
 	// Respuesta normal conversacional
 	respuesta := limpiarMarkdownParaWhatsApp(contenidoIA)
-	s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
-	return respuesta
+	s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+	return MensajeTexto(respuesta)
 }
 
 // ── Registro real del reclamo en BD ─────────────────────────────────────────
 
-func (s *WhatsAppService) procesarRegistroDesdeIA(ctx context.Context, tenantID uuid.UUID, telefono, contenidoIA string) string {
-	// Extraer el JSON entre los marcadores
-	inicio := strings.Index(contenidoIA, marcadorRegistro)
-	fin := strings.Index(contenidoIA, marcadorFin)
-
-	if inicio == -1 || fin == -1 || fin <= inicio {
-		fmt.Printf("[WhatsApp] Marcador de registro malformado: %s\n", contenidoIA)
-		respuesta := "Tus datos fueron recibidos pero hubo un problema al procesarlos. Por favor, intenta de nuevo o escribe *agente* para hablar con una persona. 🙏"
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
-		return respuesta
-	}
-
-	jsonStr := contenidoIA[inicio+len(marcadorRegistro) : fin]
-	jsonStr = strings.TrimSpace(jsonStr)
-
-	var datos datosReclamoWhatsApp
-	if err := json.Unmarshal([]byte(jsonStr), &datos); err != nil {
-		fmt.Printf("[WhatsApp] Error parseando JSON de reclamo: %v — JSON: %s\n", err, jsonStr)
-		respuesta := "Hubo un error al procesar tus datos. ¿Podrías confirmarlos de nuevo? 🙏"
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
-		return respuesta
-	}
+func (s *WhatsAppService) procesarRegistroDesdeIA(ctx context.Context, canal *CanalResuelto, telefono string, datos datosReclamoWhatsApp) string {
+	tenantID := canal.TenantID
 
 	// Validar datos mínimos
 	if datos.NombreCompleto == "" || datos.NumeroDocumento == "" || datos.Email == "" || datos.Descripcion == "" {
 		fmt.Printf("[WhatsApp] Datos incompletos: %+v\n", datos)
 		respuesta := "Algunos datos están incompletos. ¿Podrías revisar y confirmar tu nombre, DNI, email y descripción del problema?"
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 		return respuesta
 	}
 
@@ -281,7 +240,7 @@ func (s *WhatsAppService) procesarRegistroDesdeIA(ctx context.Context, tenantID
 	if err != nil || tenant == nil {
 		fmt.Printf("[WhatsApp] Error obteniendo tenant: %v\n", err)
 		respuesta := "Hubo un error interno. Por favor, escribe *agente* para que te atienda una persona. 🙏"
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 		return respuesta
 	}
 
@@ -305,9 +264,14 @@ func (s *WhatsAppService) procesarRegistroDesdeIA(ctx context.Context, tenantID
 		telefonoReclamo = telefono
 	}
 
+	tipoSolicitud := strings.ToUpper(strings.TrimSpace(datos.TipoSolicitud))
+	if tipoSolicitud != "QUEJA" {
+		tipoSolicitud = "RECLAMO"
+	}
+
 	// Construir el DTO
 	req := dto.CreateReclamoRequest{
-		TipoSolicitud:   "RECLAMO",
+		TipoSolicitud:   tipoSolicitud,
 		NombreCompleto:  strings.TrimSpace(datos.NombreCompleto),
 		TipoDocumento:   tipoDoc,
 		NumeroDocumento: strings.TrimSpace(datos.NumeroDocumento),
@@ -317,68 +281,83 @@ func (s *WhatsAppService) procesarRegistroDesdeIA(ctx context.Context, tenantID
 		FechaIncidente:  time.Now().Format("2006-01-02"),
 		DetalleReclamo:  strings.TrimSpace(datos.Descripcion),
 		PedidoConsumidor: "Solución al problema reportado",
+		SedeSlug:        datos.Sede,
 	}
 
+	// Fotos y documentos enviados durante la conversación
+	archivos, omitidos := s.evidenciasParaReclamo(ctx, canal, telefono)
+
 	// ¡REGISTRAR EN BD!
-	reclamo, err := s.reclamoService.CrearPublico(ctx, tenant.Slug, req, "whatsapp", "WhatsApp Bot")
+	reclamo, err := s.reclamoService.CrearPublico(ctx, tenant.Slug, req, "whatsapp", "WhatsApp Bot", archivos)
+	var appErr *apperror.AppError
+	if len(archivos) > 0 && errors.As(err, &appErr) && appErr.Code == apperror.ErrPlanLimitStorage.Code {
+		// Sin espacio para los archivos el reclamo igual se registra
+		fmt.Printf("[WhatsApp] Tenant %s sin storage: reclamo sin %d evidencias\n", tenant.Slug, len(archivos))
+		omitidos += len(archivos)
+		archivos = nil
+		reclamo, err = s.reclamoService.CrearPublico(ctx, tenant.Slug, req, "whatsapp", "WhatsApp Bot", nil)
+	}
 	if err != nil {
 		fmt.Printf("[WhatsApp] Error creando reclamo: %v\n", err)
 
 		errMsg := err.Error()
 		if strings.Contains(errMsg, "limite") || strings.Contains(errMsg, "plan") {
 			respuesta := "Lo sentimos, el negocio ha alcanzado el límite de reclamos de su plan actual. Por favor, comunícate directamente con la empresa. 📞"
-			s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+			s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 			return respuesta
 		}
 
 		respuesta := "Hubo un error al registrar tu reclamo. Por favor, intenta de nuevo o escribe *agente* para hablar con una persona. 🙏"
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 		return respuesta
 	}
 
+	// Las evidencias ya quedaron en el reclamo (o se descartaron)
+	if err := s.conversaciones.LimpiarEvidencias(ctx, tenantID, telefono); err != nil {
+		fmt.Printf("[WhatsApp] Error limpiando evidencias de %s: %v\n", telefono, err)
+	}
+
 	// ¡ÉXITO!
+	titulo := "¡Reclamo registrado exitosamente!"
+	if tipoSolicitud == "QUEJA" {
+		titulo = "¡Queja registrada exitosamente!"
+	}
+	lineaEvidencias := ""
+	if len(archivos) > 0 {
+		lineaEvidencias = fmt.Sprintf("📎 *Evidencias adjuntas:* %d\n", len(archivos))
+	}
+	if omitidos > 0 {
+		lineaEvidencias += fmt.Sprintf("⚠️ No pude adjuntar %d archivo(s); puedes enviarlos respondiendo al correo de confirmación.\n", omitidos)
+	}
 	respuesta := fmt.Sprintf(
-		"✅ *¡Reclamo registrado exitosamente!*\n\n"+
+		"✅ *%s*\n\n"+
 			"📋 *Código:* %s\n"+
 			"📅 *Registrado:* %s\n"+
-			"⏰ *Fecha límite de respuesta:* %s\n\n"+
+			"⏰ *Fecha límite de respuesta:* %s\n%s\n"+
 			"📧 Recibirás un correo de confirmación en *%s* con todos los detalles.\n\n"+
 			"Para consultar el estado de tu reclamo en cualquier momento, envíame tu código: *%s*\n\n"+
 			"¿Necesitas algo más? 😊",
+		titulo,
 		reclamo.CodigoReclamo,
 		reclamo.FechaRegistro.Format("02/01/2006"),
 		reclamo.FechaLimiteRespuesta.Time.Format("02/01/2006"),
+		lineaEvidencias,
 		datos.Email,
 		reclamo.CodigoReclamo,
 	)
 
-	fmt.Printf("[WhatsApp] ✅ Reclamo %s registrado por %s (tenant: %s)\n",
-		reclamo.CodigoReclamo, telefono, tenant.Slug)
+	fmt.Printf("[WhatsApp] ✅ Reclamo %s registrado por %s con %d evidencias (tenant: %s)\n",
+		reclamo.CodigoReclamo, telefono, len(archivos), tenant.Slug)
 
-	s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+	s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 	return respuesta
 }
 
 // ── Solicitud de asesor humano desde IA ─────────────────────────────────────
 
-func (s *WhatsAppService) procesarSolicitudAsesorDesdeIA(ctx context.Context, canal *CanalResuelto, telefono, contenidoIA string) string {
+func (s *WhatsAppService) procesarSolicitudAsesorDesdeIA(ctx context.Context, canal *CanalResuelto, telefono string, datos datosSolicitudAsesor, textoIA string) string {
 	tenantID := canal.TenantID
 
-	// Extraer JSON entre marcadores
-	inicio := strings.Index(contenidoIA, marcadorSolicitudAsesor)
-	fin := strings.Index(contenidoIA[inicio:], marcadorFin)
-
-	var datos datosSolicitudAsesor
-
-	if inicio != -1 && fin != -1 {
-		jsonStr := contenidoIA[inicio+len(marcadorSolicitudAsesor) : inicio+fin]
-		jsonStr = strings.TrimSpace(jsonStr)
-
-		if err := json.Unmarshal([]byte(jsonStr), &datos); err != nil {
-			fmt.Printf("[WhatsApp] Error parseando JSON solicitud asesor: %v — JSON: %s\n", err, jsonStr)
-		}
-	}
-
 	// Defaults si la IA no pudo extraer datos
 	if datos.Nombre == "" {
 		datos.Nombre = "Cliente WhatsApp"
@@ -388,7 +367,7 @@ func (s *WhatsAppService) procesarSolicitudAsesorDesdeIA(ctx context.Context, ca
 	}
 
 	// Construir resumen de la conversación (últimos mensajes)
-	resumen := s.construirResumenConversacion(telefono)
+	resumen := s.construirResumenConversacion(ctx, canal.TenantID, telefono)
 
 	// Crear la solicitud en BD
 	params := CrearSolicitudParams{
@@ -408,23 +387,20 @@ func (s *WhatsAppService) procesarSolicitudAsesorDesdeIA(ctx context.Context, ca
 		// Si ya tiene una solicitud abierta, informar
 		if strings.Contains(err.Error(), "abierta") {
 			respuesta := "Ya tienes una solicitud de atención pendiente. Un asesor se comunicará contigo pronto. ⏳\n\nSi necesitas algo más mientras tanto, puedo ayudarte con reclamos. 😊"
-			s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+			s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 			return respuesta
 		}
 
 		respuesta := "Hubo un problema al registrar tu solicitud. Por favor, intenta de nuevo en unos minutos. 🙏"
-		s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", respuesta)
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
 		return respuesta
 	}
 
 	fmt.Printf("[WhatsApp] 📞 Solicitud asesor creada (ID: %s) por %s — %s (tenant: %s)\n",
 		solicitud.ID, telefono, datos.Nombre, tenantID)
 
-	// Extraer el mensaje visible (antes del marcador) o generar uno
-	mensajeVisible := ""
-	if inicio > 0 {
-		mensajeVisible = strings.TrimSpace(contenidoIA[:inicio])
-	}
+	// Mensaje visible: el texto que acompañó a la herramienta, o uno por defecto
+	mensajeVisible := textoIA
 	if mensajeVisible == "" {
 		mensajeVisible = fmt.Sprintf(
 			"✅ *Solicitud registrada, %s*\n\n"+
@@ -436,21 +412,17 @@ func (s *WhatsAppService) procesarSolicitudAsesorDesdeIA(ctx context.Context, ca
 		mensajeVisible = limpiarMarkdownParaWhatsApp(mensajeVisible)
 	}
 
-	s.agregarMensajeAlHistorial(telefono, tenantID, "assistant", mensajeVisible)
+	s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", mensajeVisible)
 	return mensajeVisible
 }
 
 // construirResumenConversacion genera un resumen legible de los últimos mensajes.
-func (s *WhatsAppService) construirResumenConversacion(telefono string) string {
-	s.muConversaciones.RLock()
-	defer s.muConversaciones.RUnlock()
-
-	convo, existe := s.conversaciones[telefono]
-	if !existe || len(convo.mensajes) == 0 {
+func (s *WhatsAppService) construirResumenConversacion(ctx context.Context, tenantID uuid.UUID, telefono string) string {
+	mensajes := s.obtenerHistorial(ctx, tenantID, telefono)
+	if len(mensajes) == 0 {
 		return ""
 	}
 
-	mensajes := convo.mensajes
 	if len(mensajes) > 20 {
 		mensajes = mensajes[len(mensajes)-20:]
 	}
@@ -468,82 +440,34 @@ func (s *WhatsAppService) construirResumenConversacion(telefono string) string {
 }
 
 // ── Gestión de memoria de conversación ──────────────────────────────────────
+// Los errores del store se registran y no cortan la conversación: sin memoria
+// el bot sigue respondiendo, solo pierde contexto.
 
-func (s *WhatsAppService) agregarMensajeAlHistorial(telefono string, tenantID uuid.UUID, rol, contenido string) {
-	s.muConversaciones.Lock()
-	defer s.muConversaciones.Unlock()
-
-	convo, existe := s.conversaciones[telefono]
-	if !existe {
-		convo = &conversacionWA{
-			mensajes: make([]ai.Message, 0),
-			tenantID: tenantID,
-		}
-		s.conversaciones[telefono] = convo
-	}
-
-	convo.mensajes = append(convo.mensajes, ai.Message{
-		Role:    rol,
-		Content: contenido,
-	})
-	convo.ultimaActividad = time.Now()
-
-	if len(convo.mensajes) > maxMensajesPorConvo {
-		convo.mensajes = convo.mensajes[len(convo.mensajes)-maxMensajesPorConvo:]
+func (s *WhatsAppService) agregarMensajeAlHistorial(ctx context.Context, tenantID uuid.UUID, telefono, rol, contenido string) {
+	msg := ai.Message{Role: rol, Content: contenido}
+	if err := s.conversaciones.Agregar(ctx, tenantID, telefono, msg, maxMensajesPorConvo, ttlConversacion); err != nil {
+		fmt.Printf("[WhatsApp] Error guardando historial de %s: %v\n", telefono, err)
 	}
 }
 
-func (s *WhatsAppService) obtenerHistorial(telefono string) []ai.Message {
-	s.muConversaciones.RLock()
-	defer s.muConversaciones.RUnlock()
-
-	convo, existe := s.conversaciones[telefono]
-	if !existe {
+func (s *WhatsAppService) obtenerHistorial(ctx context.Context, tenantID uuid.UUID, telefono string) []ai.Message {
+	historial, err := s.conversaciones.Historial(ctx, tenantID, telefono, ttlConversacion)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Error leyendo historial de %s: %v\n", telefono, err)
 		return nil
 	}
-
-	copia := make([]ai.Message, len(convo.mensajes))
-	copy(copia, convo.mensajes)
-	return copia
+	return historial
 }
 
 // debeEnviarACK verifica si ya pasaron 5 minutos desde el último ACK al teléfono.
-func (s *WhatsAppService) debeEnviarACK(telefono string) bool {
-	const cooldownACK = 5 * time.Minute
-
-	s.muUltimoACK.RLock()
-	ultimo, existe := s.ultimoACK[telefono]
-	s.muUltimoACK.RUnlock()
-
-	if existe && time.Since(ultimo) < cooldownACK {
-		return false
-	}
-
-	s.muUltimoACK.Lock()
-	s.ultimoACK[telefono] = time.Now()
-	s.muUltimoACK.Unlock()
-
-	return true
-}
-
-func (s *WhatsAppService) limpiarConversacionesExpiradas() {
-	ticker := time.NewTicker(5 * time.Minute)
-	for range ticker.C {
-		s.muConversaciones.Lock()
-		ahora := time.Now()
-		eliminadas := 0
-		for telefono, convo := range s.conversaciones {
-			if ahora.Sub(convo.ultimaActividad) > ttlConversacion {
-				delete(s.conversaciones, telefono)
-				eliminadas++
-			}
-		}
-		s.muConversaciones.Unlock()
-
-		if eliminadas > 0 {
-			fmt.Printf("[WhatsApp] Limpieza: %d conversaciones expiradas eliminadas\n", eliminadas)
-		}
+// Ante un error del store se envía el ACK: es preferible repetirlo a no confirmar.
+func (s *WhatsAppService) debeEnviarACK(ctx context.Context, tenantID uuid.UUID, telefono string) bool {
+	enviar, err := s.conversaciones.MarcarACK(ctx, tenantID, telefono, cooldownACK)
+	if err != nil {
+		fmt.Printf("[WhatsApp] Error registrando ACK de %s: %v\n", telefono, err)
+		return true
 	}
+	return enviar
 }
 
 // ── Prompt del sistema ──────────────────────────────────────────────────────
@@ -555,7 +479,7 @@ func (s *WhatsAppService) construirPromptSistemaWhatsApp(instruccionesAdicionale
 INSTRUCCIONES ADICIONALES DEL NEGOCIO (configuradas por el administrador):
 %s
 
-IMPORTANTE: Las instrucciones anteriores son complementarias. NO modifican el flujo de registro ni los marcadores del sistema.`, instruccionesAdicionales)
+IMPORTANTE: Las instrucciones anteriores son complementarias. NO modifican el flujo de registro ni el uso de las herramientas del sistema.`, instruccionesAdicionales)
 	}
 
 	return fmt.Sprintf(`Eres el asistente de atención al cliente por WhatsApp de un Libro de Reclamaciones digital.
@@ -577,48 +501,43 @@ MENÚ PRINCIPAL (solo al inicio o si el usuario pide volver):
 3️⃣ Hablar con un agente
 
 FLUJO PARA REGISTRAR RECLAMO — PIDE DATOS UNO POR UNO:
-1. *Nombre completo*
-2. *DNI* (8 dígitos) — si da otro tipo de documento, acéptalo (CE, Pasaporte, RUC)
-3. *Email*
-4. *Teléfono* (puede ser el mismo de WhatsApp)
-5. *Descripción del problema* (qué pasó, qué producto/servicio, cuándo)
+1. *Tipo*: invoca *ofrecer_opciones* con opciones="tipo_solicitud" para que elija Reclamo o Queja
+2. *Nombre completo*
+3. *DNI* (8 dígitos) — si da otro tipo de documento, acéptalo (CE, Pasaporte, RUC)
+4. *Email*
+5. *Teléfono* (puede ser el mismo de WhatsApp)
+6. *Descripción del problema* (qué pasó, qué producto/servicio, cuándo)
+7. *Sede* donde ocurrió: invoca *ofrecer_opciones* con opciones="sede"; si el negocio no tiene varias sedes, el sistema lo indica y sigues
 
 Espera la respuesta de cada dato antes de pedir el siguiente.
 Cuando tengas TODOS los datos, muestra un resumen y pregunta "¿Es correcto?"
 
 ACCIÓN CRÍTICA — CUANDO EL USUARIO CONFIRMA QUE LOS DATOS SON CORRECTOS:
-Cuando el usuario diga "sí", "correcto", "confirmo", "dale", "ok" (después de ver el resumen), 
-tu respuesta DEBE contener EXACTAMENTE este bloque al final (el sistema lo detecta para registrar el reclamo en la base de datos):
-
->>>REGISTRAR_RECLAMO:{"nombre_completo":"Jose Roberto La Rosa Ledezma","tipo_documento":"DNI","numero_documento":"07115385","email":"jose@gmail.com","telefono":"938192665","descripcion":"Me mandaron mi gato sin baterias y no conecta al wifi"}<<<
-
-REGLAS DEL BLOQUE DE REGISTRO:
-- El JSON debe ser válido, en UNA sola línea, sin saltos de línea dentro.
-- tipo_documento debe ser: DNI, CE, Pasaporte, o RUC.
-- Usa los datos EXACTOS que el usuario proporcionó.
-- ANTES del bloque, escribe un mensaje amable como "Perfecto, registrando tu reclamo... ⏳"
-- El bloque >>>REGISTRAR_RECLAMO:...<<< NO será visible para el usuario, el sistema lo intercepta.
+Cuando el usuario diga "sí", "correcto", "confirmo", "dale", "ok" (después de ver el resumen),
+invoca la herramienta *registrar_reclamo* con los datos EXACTOS que el usuario proporcionó.
+- tipo_documento debe ser: DNI, CE, PASAPORTE o RUC.
+- tipo_solicitud: RECLAMO o QUEJA, según lo que eligió.
+- sede: el valor que acompaña a "sede:" en la elección del usuario; vacío si no eligió sede.
+- NUNCA la invoques sin que el usuario haya confirmado el resumen.
+- NO escribas el código del reclamo: el sistema lo genera y se lo envía al usuario.
+
+ARCHIVOS, NOTAS DE VOZ Y UBICACIÓN:
+- El usuario puede enviar fotos o documentos como evidencia: el sistema los guarda y los adjunta solo al registrar. No le pidas que los envíe por otro medio.
+- Las notas de voz te llegan transcritas, como texto.
+- Una ubicación compartida llega con sus coordenadas: úsala como referencia del lugar de los hechos en la descripción.
+- Cuando el usuario elige una opción de botones o listas, su mensaje trae el valor entre paréntesis, p. ej. "(tipo_solicitud: QUEJA)".
 
 FLUJO PARA CONSULTAR ESTADO:
-- Pide el código de reclamo
-- Dile que lo encuentra en el correo de confirmación
+- Pide el código de reclamo (lo encuentra en el correo de confirmación).
+- Cuando lo tengas, invoca la herramienta *consultar_reclamo*. NUNCA inventes el estado.
 
 FLUJO PARA HABLAR CON UN AGENTE — MÁXIMA PRIORIDAD:
 Si el usuario pide hablar con un agente/asesor/persona/humano en CUALQUIER momento (incluyendo el primer mensaje), este flujo tiene PRIORIDAD sobre todo lo demás. NUNCA lo desvíes al flujo de reclamo si pidió un asesor.
 1. Pide su *nombre* (si no lo tienes ya de la conversación).
 2. Pide una *descripción breve* de su consulta o problema (si ya la mencionó, NO la pidas de nuevo).
-3. Cuando tengas ambos datos (nombre y motivo), tu respuesta DEBE contener este bloque al final:
-
->>>SOLICITAR_ASESOR:{"nombre":"Jose Roberto La Rosa Ledezma","motivo":"Mi gato vino sin baterias y quiero hablar con alguien"}<<<
-
-REGLAS DEL BLOQUE DE SOLICITUD ASESOR:
-- El JSON debe ser válido, en UNA sola línea, sin saltos de línea dentro.
-- "nombre": el nombre que el usuario proporcionó.
-- "motivo": resumen breve del problema o consulta del usuario.
-- ANTES del bloque, escribe un mensaje amable como "Perfecto, estoy registrando tu solicitud para que un asesor te contacte... ⏳"
-- El bloque >>>SOLICITAR_ASESOR:...<<< NO será visible para el usuario, el sistema lo intercepta.
-- Si el usuario ya dio su nombre y motivo en la conversación, NO los pidas de nuevo. Usa los datos que ya tienes.
-- NUNCA omitas el bloque cuando tengas nombre y motivo. SIEMPRE emítelo.
+3. Cuando tengas ambos datos, invoca la herramienta *solicitar_asesor* con "nombre" y "motivo" (resumen breve).
+- Puedes acompañarla de un mensaje amable como "Perfecto, estoy registrando tu solicitud para que un asesor te contacte... ⏳"
+- NUNCA omitas la herramienta cuando tengas nombre y motivo.
 %s
 %s`, bloqueInstrucciones, contextoTenant)
 }
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"libro-reclamaciones/internal/ai"
+)
+
+// ── Herramientas del bot de WhatsApp (tool calling) ─────────────────────────
+// El modelo invoca estas herramientas en lugar de emitir marcadores de texto.
+// Los argumentos se validan contra el schema antes de tocar la BD.
+
+const (
+	toolRegistrarReclamo = "registrar_reclamo"
+	toolConsultarReclamo = "consultar_reclamo"
+	toolSolicitarAsesor  = "solicitar_asesor"
+	toolOfrecerOpciones  = "ofrecer_opciones"
+)
+
+// Listas de opciones que el bot envía como botones o lista interactiva.
+const (
+	opcionesTipoSolicitud = "tipo_solicitud"
+	opcionesSede          = "sede"
+)
+
+func intPtr(n int) *int { return &n }
+
+var herramientasWhatsApp = []ai.Tool{
+	{
+		Name: toolRegistrarReclamo,
+		Description: "Registra el reclamo en el Libro de Reclamaciones. Úsala SOLO cuando el usuario " +
+			"confirmó que el resumen de sus datos es correcto.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"nombre_completo": {Type: "string", Description: "Nombre completo tal como lo escribió el usuario", MinLength: intPtr(3), MaxLength: intPtr(200)},
+				"tipo_documento":  {Type: "string", Description: "Tipo de documento de identidad", Enum: []string{"DNI", "CE", "PASAPORTE", "RUC"}},
+				"numero_documento": {Type: "string", Description: "Número de documento (DNI: 8 dígitos, RUC: 11)",
+					Pattern: `^[A-Za-z0-9-]{6,20}$`},
+				"email":       {Type: "string", Description: "Correo electrónico", Pattern: `^[^@\s]+@[^@\s]+\.[^@\s]+$`, MaxLength: intPtr(254)},
+				"telefono":    {Type: "string", Description: "Teléfono de contacto; vacío si es el mismo de WhatsApp", MaxLength: intPtr(20)},
+				"descripcion": {Type: "string", Description: "Qué pasó, con qué producto o servicio y cuándo", MinLength: intPtr(10), MaxLength: intPtr(2000)},
+				"tipo_solicitud": {Type: "string", Description: "RECLAMO (disconformidad con el producto o servicio) o QUEJA (malestar por la atención)",
+					Enum: []string{"RECLAMO", "QUEJA"}},
+				"sede": {Type: "string", Description: "Sede elegida por el usuario (el valor indicado tras 'sede:'); vacío si no eligió ninguna", MaxLength: intPtr(200)},
+			},
+			Required: []string{"nombre_completo", "tipo_documento", "numero_documento", "email", "descripcion", "tipo_solicitud"},
+		},
+	},
+	{
+		Name:        toolConsultarReclamo,
+		Description: "Consulta el estado de un reclamo a partir del código que el usuario recibió por correo.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"codigo": {Type: "string", Description: "Código del reclamo, p. ej. 2026-DEMO-XXXX-XXXXX", MinLength: intPtr(5), MaxLength: intPtr(40)},
+			},
+			Required: []string{"codigo"},
+		},
+	},
+	{
+		Name: toolSolicitarAsesor,
+		Description: "Deriva la conversación a un asesor humano. Úsala cuando el usuario pide hablar con " +
+			"una persona y ya tienes su nombre y el motivo.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"nombre": {Type: "string", Description: "Nombre del usuario", MinLength: intPtr(2), MaxLength: intPtr(200)},
+				"motivo": {Type: "string", Description: "Resumen breve de la consulta o problema", MinLength: intPtr(3), MaxLength: intPtr(500)},
+			},
+			Required: []string{"nombre", "motivo"},
+		},
+	},
+	{
+		Name: toolOfrecerOpciones,
+		Description: "Muestra botones o una lista para que el usuario elija en lugar de escribir: " +
+			"'tipo_solicitud' (reclamo o queja) o 'sede' (local del negocio donde ocurrió el problema). " +
+			"El sistema arma las opciones: no las enumeres en el mensaje.",
+		Parameters: &ai.Schema{
+			Type: "object",
+			Properties: map[string]*ai.Schema{
+				"opciones": {Type: "string", Description: "Qué debe elegir el usuario", Enum: []string{opcionesTipoSolicitud, opcionesSede}},
+				"mensaje":  {Type: "string", Description: "Pregunta breve que acompaña a las opciones", MaxLength: intPtr(300)},
+			},
+			Required: []string{"opciones"},
+		},
+	},
+}
+
+// datosReclamoWhatsApp argumentos de registrar_reclamo.
+type datosReclamoWhatsApp struct {
+	NombreCompleto  string `json:"nombre_completo"`
+	TipoDocumento   string `json:"tipo_documento"`
+	NumeroDocumento string `json:"numero_documento"`
+	Email           string `json:"email"`
+	Telefono        string `json:"telefono"`
+	Descripcion     string `json:"descripcion"`
+	TipoSolicitud   string `json:"tipo_solicitud"`
+	Sede            string `json:"sede"`
+}
+
+// datosConsultaReclamo argumentos de consultar_reclamo.
+type datosConsultaReclamo struct {
+	Codigo string `json:"codigo"`
+}
+
+// datosSolicitudAsesor argumentos de solicitar_asesor.
+type datosSolicitudAsesor struct {
+	Nombre string `json:"nombre"`
+	Motivo string `json:"motivo"`
+}
+
+// datosOfrecerOpciones argumentos de ofrecer_opciones.
+type datosOfrecerOpciones struct {
+	Opciones string `json:"opciones"`
+	Mensaje  string `json:"mensaje"`
+}
+
+// ejecutarHerramienta valida y ejecuta la primera herramienta pedida por el modelo.
+// textoIA es el texto que el modelo acompañó a la invocación (puede venir vacío).
+func (s *WhatsAppService) ejecutarHerramienta(ctx context.Context, canal *CanalResuelto, telefono string, llamada ai.ToolCall, textoIA string) MensajeSaliente {
+	tenantID := canal.TenantID
+
+	tool := ai.BuscarTool(herramientasWhatsApp, llamada.Name)
+	if tool == nil {
+		fmt.Printf("[WhatsApp] Herramienta desconocida %q pedida por la IA\n", llamada.Name)
+		respuesta := "No pude procesar tu solicitud. ¿Podrías repetirla? 🙏"
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+		return MensajeTexto(respuesta)
+	}
+
+	switch llamada.Name {
+	case toolRegistrarReclamo:
+		var datos datosReclamoWhatsApp
+		if err := tool.Decodificar(llamada.Arguments, &datos); err != nil {
+			fmt.Printf("[WhatsApp] %v — args: %s\n", err, string(llamada.Arguments))
+			respuesta := "Algunos datos están incompletos o no son válidos. ¿Podrías revisar y confirmar tu nombre, documento, email y descripción del problema?"
+			s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+			return MensajeTexto(respuesta)
+		}
+		// La sede se valida antes de registrar: si no existe se vuelve a ofrecer la lista
+		if datos.Sede != "" {
+			slug := s.resolverSede(ctx, tenantID, datos.Sede)
+			if slug == "" {
+				fmt.Printf("[WhatsApp] Sede %q no encontrada (tenant %s)\n", datos.Sede, tenantID)
+				return s.ofrecerOpciones(ctx, canal, telefono, datosOfrecerOpciones{
+					Opciones: opcionesSede,
+					Mensaje:  fmt.Sprintf("No encontré la sede *%s*. ¿En cuál de nuestras sedes ocurrió?", datos.Sede),
+				}, "")
+			}
+			datos.Sede = slug
+		}
+		return MensajeTexto(s.procesarRegistroDesdeIA(ctx, canal, telefono, datos))
+
+	case toolConsultarReclamo:
+		var datos datosConsultaReclamo
+		if err := tool.Decodificar(llamada.Arguments, &datos); err != nil {
+			fmt.Printf("[WhatsApp] %v — args: %s\n", err, string(llamada.Arguments))
+			respuesta := "Ese código no parece válido. Lo encuentras en el correo de confirmación, con un formato como *2026-DEMO-XXXX-XXXXX*."
+			s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+			return MensajeTexto(respuesta)
+		}
+		respuesta := s.buscarReclamoEnBaseDeDatosYFormatear(ctx, tenantID, datos.Codigo)
+		s.agregarMensajeAlHistorial(ctx, tenantID, telefono, "assistant", respuesta)
+		return MensajeTexto(respuesta)
+
+	case toolOfrecerOpciones:
+		var datos datosOfrecerOpciones
+		if err := tool.Decodificar(llamada.Arguments, &datos); err != nil {
+			fmt.Printf("[WhatsApp] %v — args: %s\n", err, string(llamada.Arguments))
+			datos = datosOfrecerOpciones{Opciones: opcionesTipoSolicitud}
+		}
+		return s.ofrecerOpciones(ctx, canal, telefono, datos, strings.TrimSpace(textoIA))
+
+	default: // toolSolicitarAsesor
+		var datos datosSolicitudAsesor
+		if err := tool.Decodificar(llamada.Arguments, &datos); err != nil {
+			// Sin datos válidos igual se deriva: la prioridad es no dejar sin atención
+			fmt.Printf("[WhatsApp] %v — args: %s\n", err, string(llamada.Arguments))
+			datos = datosSolicitudAsesor{}
+		}
+		return MensajeTexto(s.procesarSolicitudAsesorDesdeIA(ctx, canal, telefono, datos, strings.TrimSpace(textoIA)))
+	}
+}
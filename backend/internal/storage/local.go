@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Local guarda los objetos en un directorio del servidor.
+// Útil en desarrollo o con un volumen compartido entre réplicas.
+type Local struct {
+	dir string
+}
+
+func NewLocal(dir string) (*Local, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("storage.NewLocal: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0o750); err != nil {
+		return nil, fmt.Errorf("storage.NewLocal: %w", err)
+	}
+	return &Local{dir: abs}, nil
+}
+
+// Guardar escribe a un temporal y renombra: nunca queda un archivo a medias.
+func (l *Local) Guardar(ctx context.Context, clave string, datos []byte, mime string) error {
+	ruta, err := l.ruta(clave)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(ruta), 0o750); err != nil {
+		return fmt.Errorf("storage.Local.Guardar: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ruta), ".subida-*")
+	if err != nil {
+		return fmt.Errorf("storage.Local.Guardar: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(datos); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage.Local.Guardar: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage.Local.Guardar: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ruta); err != nil {
+		return fmt.Errorf("storage.Local.Guardar: %w", err)
+	}
+	return nil
+}
+
+func (l *Local) Abrir(ctx context.Context, clave string) (io.ReadCloser, error) {
+	ruta, err := l.ruta(clave)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(ruta)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoExiste
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage.Local.Abrir: %w", err)
+	}
+	return f, nil
+}
+
+func (l *Local) Eliminar(ctx context.Context, clave string) error {
+	ruta, err := l.ruta(clave)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(ruta); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage.Local.Eliminar: %w", err)
+	}
+	return nil
+}
+
+func (l *Local) ruta(clave string) (string, error) {
+	if err := validarClave(clave); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(clave)), nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Opciones conexión a un endpoint S3-compatible.
+type S3Opciones struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// S3 backend S3-compatible con firma AWS Signature V4 sobre net/http.
+// Solo usa PUT/GET/DELETE de objetos, por eso no depende del SDK de AWS.
+type S3 struct {
+	op       S3Opciones
+	endpoint *url.URL
+	client   *http.Client
+}
+
+func NewS3(op S3Opciones) (*S3, error) {
+	u, err := url.Parse(strings.TrimRight(op.Endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("storage.NewS3: endpoint inválido %q", op.Endpoint)
+	}
+	if op.Region == "" {
+		op.Region = "us-east-1"
+	}
+	return &S3{
+		op:       op,
+		endpoint: u,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (s *S3) Guardar(ctx context.Context, clave string, datos []byte, mime string) error {
+	req, err := s.request(ctx, http.MethodPut, clave, datos)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mime)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("storage.S3.Guardar: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("storage.S3.Guardar: %s", leerErrorS3(resp))
+	}
+	return nil
+}
+
+func (s *S3) Abrir(ctx context.Context, clave string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, clave, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage.S3.Abrir: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNoExiste
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("storage.S3.Abrir: %s", leerErrorS3(resp))
+	}
+	return resp.Body, nil
+}
+
+func (s *S3) Eliminar(ctx context.Context, clave string) error {
+	req, err := s.request(ctx, http.MethodDelete, clave, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("storage.S3.Eliminar: %w", err)
+	}
+	defer resp.Body.Close()
+	// S3 responde 204 aunque el objeto no exista
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("storage.S3.Eliminar: %s", leerErrorS3(resp))
+	}
+	return nil
+}
+
+// request arma la petición firmada para la clave dada.
+func (s *S3) request(ctx context.Context, metodo, clave string, cuerpo []byte) (*http.Request, error) {
+	if err := validarClave(clave); err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	if s.op.PathStyle {
+		u.Path = u.Path + "/" + s.op.Bucket + "/" + clave
+	} else {
+		u.Host = s.op.Bucket + "." + u.Host
+		u.Path = u.Path + "/" + clave
+	}
+	u.RawPath = codificarRuta(u.Path)
+
+	req, err := http.NewRequestWithContext(ctx, metodo, u.String(), bytes.NewReader(cuerpo))
+	if err != nil {
+		return nil, fmt.Errorf("storage.S3: %w", err)
+	}
+	req.ContentLength = int64(len(cuerpo))
+	s.firmar(req, cuerpo, time.Now().UTC())
+	return req, nil
+}
+
+// firmar agrega los headers de AWS Signature V4.
+func (s *S3) firmar(req *http.Request, cuerpo []byte, ahora time.Time) {
+	amzDate := ahora.Format("20060102T150405Z")
+	fecha := ahora.Format("20060102")
+	hashCuerpo := sha256Hex(cuerpo)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", hashCuerpo)
+
+	headersFirmados := "host;x-amz-content-sha256;x-amz-date"
+	canonico := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"host:" + req.URL.Host + "\n" +
+			"x-amz-content-sha256:" + hashCuerpo + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		headersFirmados,
+		hashCuerpo,
+	}, "\n")
+
+	alcance := fecha + "/" + s.op.Region + "/s3/aws4_request"
+	aFirmar := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + alcance + "\n" + sha256Hex([]byte(canonico))
+
+	clave := hmacSHA256([]byte("AWS4"+s.op.SecretKey), fecha)
+	clave = hmacSHA256(clave, s.op.Region)
+	clave = hmacSHA256(clave, "s3")
+	clave = hmacSHA256(clave, "aws4_request")
+	firma := hex.EncodeToString(hmacSHA256(clave, aFirmar))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.op.AccessKey+"/"+alcance+
+		", SignedHeaders="+headersFirmados+", Signature="+firma)
+}
+
+// codificarRuta aplica URI-encoding de S3 a cada segmento, preservando "/".
+func codificarRuta(ruta string) string {
+	segmentos := strings.Split(ruta, "/")
+	for i, seg := range segmentos {
+		segmentos[i] = strings.ReplaceAll(url.QueryEscape(seg), "+", "%20")
+	}
+	return strings.Join(segmentos, "/")
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(clave []byte, dato string) []byte {
+	m := hmac.New(sha256.New, clave)
+	m.Write([]byte(dato))
+	return m.Sum(nil)
+}
+
+func leerErrorS3(resp *http.Response) string {
+	cuerpo, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Sprintf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(cuerpo)))
+}
@@ -0,0 +1,56 @@
+// Package storage guarda los archivos adjuntos fuera de la base de datos.
+// La BD solo conserva metadatos (tabla adjuntos) y la clave del objeto.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"libro-reclamaciones/internal/config"
+)
+
+// ErrNoExiste el objeto no está en el storage.
+var ErrNoExiste = errors.New("storage: objeto no existe")
+
+// Storage backend de almacenamiento de objetos.
+// Las claves usan "/" como separador: {tenant_id}/{reclamo_id}/{adjunto_id}.
+type Storage interface {
+	Guardar(ctx context.Context, clave string, datos []byte, mime string) error
+	Abrir(ctx context.Context, clave string) (io.ReadCloser, error)
+	Eliminar(ctx context.Context, clave string) error
+}
+
+// New construye el backend indicado en la configuración.
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(cfg.LocalDir)
+	case "s3":
+		return NewS3(S3Opciones{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("storage: driver desconocido %q", cfg.Driver)
+	}
+}
+
+// validarClave rechaza claves vacías, absolutas o con segmentos "." / "..".
+func validarClave(clave string) error {
+	if clave == "" || strings.HasPrefix(clave, "/") || strings.Contains(clave, "\\") {
+		return fmt.Errorf("storage: clave inválida %q", clave)
+	}
+	for _, seg := range strings.Split(clave, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("storage: clave inválida %q", clave)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+-- =============================================================================
+-- 20. CALENDARIO SLA (Días hábiles para fecha_limite_respuesta)
+-- =============================================================================
+-- Configuración por tenant de cómo se cuenta el plazo de respuesta:
+--   - dias_habiles = true  → se excluyen fines de semana y feriados
+--   - dias_habiles = false → días calendario (comportamiento anterior)
+--
+-- Si un tenant no tiene fila, se usa el calendario legal por defecto:
+-- días hábiles, America/Lima, sábado y domingo no laborables,
+-- feriados nacionales del Perú.
+--
+-- dias_no_laborables: lista separada por comas de time.Weekday (0=domingo … 6=sábado)
+-- version: se incrementa en cada cambio; los reclamos abiertos se recalculan.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS calendarios_sla (
+    tenant_id                   UUID        NOT NULL,
+
+    dias_habiles                BOOL        NOT NULL DEFAULT true,
+    zona_horaria                STRING      NOT NULL DEFAULT 'America/Lima',
+    dias_no_laborables          STRING      NOT NULL DEFAULT '0,6',
+    incluir_feriados_nacionales BOOL        NOT NULL DEFAULT true,
+
+    version                     INT         NOT NULL DEFAULT 1,
+    fecha_creacion              TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_actualizacion         TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id)
+);
+
+COMMENT ON TABLE calendarios_sla IS 'Reglas de días hábiles por tenant para el plazo de respuesta';
+
+-- =============================================================================
+-- 21. FERIADOS DEL TENANT
+-- =============================================================================
+-- Feriados propios del tenant (regionales, aniversario, cierre de la empresa).
+-- Se suman a los feriados nacionales si incluir_feriados_nacionales = true.
+--   recurrente = true → se repite cada año (solo importan mes y día)
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS feriados_tenant (
+    tenant_id       UUID        NOT NULL,
+    id              UUID        NOT NULL DEFAULT gen_random_uuid(),
+
+    fecha           DATE        NOT NULL,
+    descripcion     STRING      NOT NULL,
+    recurrente      BOOL        NOT NULL DEFAULT false,
+
+    fecha_creacion  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id, id)
+);
+
+-- Un feriado por fecha y tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feriados_tenant_fecha
+    ON feriados_tenant (tenant_id, fecha);
+
+COMMENT ON TABLE feriados_tenant IS 'Feriados adicionales por tenant para el cálculo de días hábiles';
@@ -0,0 +1,58 @@
+-- =============================================================================
+-- 22. BLOQUEOS DE JOBS (Scheduler distribuido)
+-- =============================================================================
+-- Lock por job para que solo UNA réplica del backend ejecute cada corrida.
+-- NO tiene tenant_id: los jobs son del sistema, no de un tenant.
+--
+-- Una réplica toma el lock si:
+--   - bloqueado_hasta ya pasó (nadie lo tiene o el dueño murió), y
+--   - ultimo_slot < slot de la corrida (nadie ejecutó ya ese horario).
+-- bloqueado_hasta actúa como lease: si la réplica cae, el lock expira solo.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS jobs_bloqueos (
+    nombre              STRING      NOT NULL,
+    propietario         STRING      NOT NULL,             -- hostname:pid:uuid de la réplica
+    bloqueado_hasta     TIMESTAMPTZ NOT NULL,
+    ultimo_slot         TIMESTAMPTZ NOT NULL DEFAULT '1970-01-01 00:00:00+00',
+
+    PRIMARY KEY (nombre)
+);
+
+COMMENT ON TABLE jobs_bloqueos IS 'Locks distribuidos del scheduler (una réplica por corrida)';
+
+-- =============================================================================
+-- 23. EJECUCIONES DE JOBS (Historial)
+-- =============================================================================
+-- Una fila por corrida de un job (programada o manual).
+--   estado:  EN_CURSO | EXITOSO | FALLIDO
+--   origen:  PROGRAMADO | MANUAL
+--
+-- TTL: el historial se elimina automáticamente después de 30 días.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS jobs_ejecuciones (
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    nombre              STRING      NOT NULL,
+    instancia           STRING      NOT NULL,
+    origen              STRING      NOT NULL DEFAULT 'PROGRAMADO',
+    estado              STRING      NOT NULL DEFAULT 'EN_CURSO',
+
+    resultado           STRING,                           -- Resumen devuelto por el job
+    error               STRING,
+
+    fecha_inicio        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_fin           TIMESTAMPTZ,
+    duracion_ms         INT,
+
+    -- TTL: auto-eliminar después de 30 días
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '30 days',
+
+    PRIMARY KEY (id)
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@daily');
+
+-- Historial de un job (query principal del panel)
+CREATE INDEX IF NOT EXISTS idx_jobs_ejecuciones_nombre
+    ON jobs_ejecuciones (nombre, fecha_inicio DESC)
+    STORING (instancia, origen, estado, resultado, error, fecha_fin, duracion_ms);
+
+COMMENT ON TABLE jobs_ejecuciones IS 'Historial de ejecuciones del scheduler';
@@ -0,0 +1,60 @@
+-- =============================================================================
+-- 24. CONFIGURACIÓN DE ALERTAS SLA
+-- =============================================================================
+-- Avisos proactivos cuando un reclamo entra en ventana URGENTE o vence.
+-- Si un tenant no tiene fila se usan los valores por defecto.
+--
+--   dias_aviso    → días hábiles restantes para considerar URGENTE
+--   modo_resumen  → true = un solo email por destinatario con todos los casos
+--                   false = un email por reclamo
+-- Destinatarios: usuario asignado (atendido_por), email de la sede
+-- y email_contacto del tenant (cada uno activable).
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS config_alertas_sla (
+    tenant_id           UUID        NOT NULL,
+
+    activo              BOOL        NOT NULL DEFAULT true,
+    dias_aviso          INT         NOT NULL DEFAULT 3,
+    modo_resumen        BOOL        NOT NULL DEFAULT true,
+
+    notificar_asignado  BOOL        NOT NULL DEFAULT true,
+    notificar_sede      BOOL        NOT NULL DEFAULT true,
+    notificar_empresa   BOOL        NOT NULL DEFAULT true,
+
+    fecha_actualizacion TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id)
+);
+
+COMMENT ON TABLE config_alertas_sla IS 'Umbrales y destinatarios de alertas SLA por tenant';
+
+-- =============================================================================
+-- 25. ALERTAS SLA ENVIADAS
+-- =============================================================================
+-- Evita avisar dos veces el mismo evento. La clave incluye fecha_limite:
+-- si el plazo se recalcula (cambio de calendario, reapertura) se vuelve a avisar.
+--   tipo: URGENTE | VENCIDO
+--
+-- TTL: se eliminan automáticamente después de 180 días.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS alertas_sla_enviadas (
+    tenant_id           UUID        NOT NULL,
+    reclamo_id          UUID        NOT NULL,
+    tipo                STRING      NOT NULL,
+    fecha_limite        DATE        NOT NULL,
+
+    destinatarios       INT         NOT NULL DEFAULT 0,
+    fecha_envio         TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '180 days',
+
+    PRIMARY KEY (tenant_id, reclamo_id, tipo, fecha_limite),
+
+    CONSTRAINT fk_alerta_sla_reclamo
+        FOREIGN KEY (tenant_id, reclamo_id)
+        REFERENCES reclamos (tenant_id, id)
+        ON DELETE CASCADE
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@daily');
+
+COMMENT ON TABLE alertas_sla_enviadas IS 'Registro de alertas SLA ya notificadas (deduplicación)';
@@ -0,0 +1,58 @@
+-- =============================================================================
+-- 26. OUTBOX DE NOTIFICACIONES
+-- =============================================================================
+-- Las notificaciones se insertan en la MISMA transacción que el cambio que las
+-- origina (reclamo, cambio de estado, respuesta, mensaje). Un worker del
+-- scheduler las reclama y envía con reintentos y backoff exponencial.
+--
+--   canal:  EMAIL | WHATSAPP
+--   tipo:   CONFIRMACION_RECLAMO | NUEVO_RECLAMO_EMPRESA | CAMBIO_ESTADO
+--           | RESOLUCION | MENSAJE_NUEVO
+--   estado: PENDIENTE → PROCESANDO → ENVIADA
+--                                  ↘ PENDIENTE (reintento con backoff)
+--                                  ↘ FALLIDA   (dead-letter: agotó max_intentos)
+--
+-- bloqueado_hasta: lease del worker. Si la réplica muere a mitad del envío,
+-- otra la vuelve a tomar cuando el lease vence.
+--
+-- TTL: se eliminan automáticamente después de 90 días.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS notificaciones_outbox (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    reclamo_id          UUID,
+
+    canal               STRING      NOT NULL DEFAULT 'EMAIL',
+    tipo                STRING      NOT NULL,
+    destinatario        STRING      NOT NULL,
+    payload             JSONB       NOT NULL DEFAULT '{}',
+
+    estado              STRING      NOT NULL DEFAULT 'PENDIENTE',
+    intentos            INT         NOT NULL DEFAULT 0,
+    max_intentos        INT         NOT NULL DEFAULT 8,
+    proximo_intento     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    bloqueado_hasta     TIMESTAMPTZ,
+    ultimo_error        STRING,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_envio         TIMESTAMPTZ,
+
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '90 days',
+
+    PRIMARY KEY (tenant_id, id),
+
+    CONSTRAINT chk_outbox_estado CHECK (estado IN ('PENDIENTE', 'PROCESANDO', 'ENVIADA', 'FALLIDA')),
+    CONSTRAINT chk_outbox_canal CHECK (canal IN ('EMAIL', 'WHATSAPP'))
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@daily');
+
+-- Worker: pendientes listas para enviar (cross-tenant)
+CREATE INDEX IF NOT EXISTS idx_outbox_pendientes
+    ON notificaciones_outbox (estado, proximo_intento)
+    STORING (bloqueado_hasta);
+
+-- Admin: estado de entrega por reclamo
+CREATE INDEX IF NOT EXISTS idx_outbox_reclamo
+    ON notificaciones_outbox (tenant_id, reclamo_id, fecha_creacion DESC);
+
+COMMENT ON TABLE notificaciones_outbox IS 'Outbox transaccional de notificaciones con reintentos y dead-letter';
@@ -0,0 +1,48 @@
+-- =============================================================================
+-- 27. ADJUNTOS
+-- =============================================================================
+-- Metadatos de archivos subidos a reclamos, mensajes de seguimiento y
+-- respuestas. El contenido vive en el storage (disco local o S3-compatible)
+-- bajo la clave {tenant_id}/{reclamo_id}/{id}.
+--
+--   entidad:  RECLAMO | MENSAJE | RESPUESTA
+--   origen:   PUBLICO (consumidor) | PANEL (usuario admin)
+--   mime_type se detecta del contenido (sniffing), no del header del cliente.
+--
+-- La suma de tamano_bytes por tenant se compara con limite_storage_mb del plan.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS adjuntos (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    reclamo_id          UUID        NOT NULL,
+
+    entidad             STRING      NOT NULL,
+    entidad_id          UUID,
+
+    nombre_original     STRING      NOT NULL,
+    clave               STRING      NOT NULL,
+    mime_type           STRING      NOT NULL,
+    tamano_bytes        INT8        NOT NULL,
+    sha256              STRING      NOT NULL,
+
+    origen              STRING      NOT NULL DEFAULT 'PUBLICO',
+    subido_por          UUID,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id, id),
+
+    CONSTRAINT chk_adjunto_entidad CHECK (entidad IN ('RECLAMO', 'MENSAJE', 'RESPUESTA')),
+    CONSTRAINT chk_adjunto_origen CHECK (origen IN ('PUBLICO', 'PANEL')),
+
+    CONSTRAINT fk_adjunto_reclamo
+        FOREIGN KEY (tenant_id, reclamo_id)
+        REFERENCES reclamos (tenant_id, id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_adjunto_reclamo
+    ON adjuntos (tenant_id, reclamo_id, fecha_creacion)
+    STORING (entidad, entidad_id, nombre_original, mime_type, tamano_bytes);
+
+COMMENT ON TABLE adjuntos IS 'Archivos adjuntos de reclamos, mensajes y respuestas (contenido en storage externo)';
@@ -0,0 +1,37 @@
+-- =============================================================================
+-- 28. CADENA DE INTEGRIDAD (hash encadenado)
+-- =============================================================================
+-- Cada reclamo y cada fila de historial lleva el hash de su contenido
+-- enlazado con el hash de la fila anterior del mismo tenant:
+--
+--   hash = sha256(hash_anterior || contenido canónico)
+--
+-- Hay dos cadenas por tenant (reclamos e historial_reclamos), ordenadas por
+-- cadena_secuencia (1, 2, 3, ...). El primer eslabón enlaza con el hash
+-- génesis (64 ceros). Alterar, borrar o reordenar una fila rompe la cadena
+-- desde ese punto; se detecta con GET /api/v1/admin/integridad/verificar o con
+-- el comando cmd/verificar_cadena.
+--
+-- En reclamos solo se hashean los datos presentados por el consumidor
+-- (estado, atendido_por y fechas de gestión cambian legítimamente).
+--
+-- Las filas anteriores a esta migración quedan con cadena_secuencia NULL y
+-- se reportan como "sin cadena".
+-- =============================================================================
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS cadena_secuencia INT8;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS hash_anterior    STRING;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS hash             STRING;
+
+-- Dos inserciones concurrentes que leyeron el mismo eslabón anterior chocan aquí
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reclamos_cadena
+    ON reclamos (tenant_id, cadena_secuencia);
+
+ALTER TABLE historial_reclamos ADD COLUMN IF NOT EXISTS cadena_secuencia INT8;
+ALTER TABLE historial_reclamos ADD COLUMN IF NOT EXISTS hash_anterior    STRING;
+ALTER TABLE historial_reclamos ADD COLUMN IF NOT EXISTS hash             STRING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_historial_cadena
+    ON historial_reclamos (tenant_id, cadena_secuencia);
+
+COMMENT ON COLUMN reclamos.hash IS 'sha256(hash_anterior || contenido) — cadena de integridad por tenant';
+COMMENT ON COLUMN historial_reclamos.hash IS 'sha256(hash_anterior || contenido) — cadena de integridad por tenant';
@@ -0,0 +1,31 @@
+-- =============================================================================
+-- 29. BÚSQUEDA DE RECLAMOS (texto completo + trigramas)
+-- =============================================================================
+-- GET /api/v1/reclamos/buscar combina:
+--   * Texto completo (tsvector en español) sobre el relato del consumidor:
+--     detalle_reclamo, pedido_consumidor, descripcion_bien y descripcion_situacion.
+--   * Trigramas para coincidencias parciales (ILIKE '%texto%') en nombre,
+--     DNI/RUC, código de reclamo y número de pedido.
+--
+-- Todos los índices llevan tenant_id como prefijo: la búsqueda nunca cruza
+-- tenants y el índice invertido solo recorre las filas del tenant.
+-- =============================================================================
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS busqueda_tsv TSVECTOR AS (
+    to_tsvector('spanish',
+        COALESCE(detalle_reclamo, '') || ' ' ||
+        COALESCE(pedido_consumidor, '') || ' ' ||
+        COALESCE(descripcion_bien, '') || ' ' ||
+        COALESCE(descripcion_situacion, ''))
+) STORED;
+
+CREATE INVERTED INDEX IF NOT EXISTS idx_reclamos_busqueda_tsv
+    ON reclamos (tenant_id, busqueda_tsv);
+
+CREATE INVERTED INDEX IF NOT EXISTS idx_reclamos_trgm_nombre
+    ON reclamos (tenant_id, nombre_completo gin_trgm_ops);
+CREATE INVERTED INDEX IF NOT EXISTS idx_reclamos_trgm_documento
+    ON reclamos (tenant_id, numero_documento gin_trgm_ops);
+CREATE INVERTED INDEX IF NOT EXISTS idx_reclamos_trgm_codigo
+    ON reclamos (tenant_id, codigo_reclamo gin_trgm_ops);
+CREATE INVERTED INDEX IF NOT EXISTS idx_reclamos_trgm_pedido
+    ON reclamos (tenant_id, numero_pedido gin_trgm_ops);
@@ -0,0 +1,15 @@
+-- =============================================================================
+-- 30. REAPERTURA DE RECLAMOS
+-- =============================================================================
+-- Un reclamo RESUELTO o CERRADO puede reabrirse (vuelve a EN_PROCESO, se
+-- registra REAPERTURA en el historial y se recalcula fecha_limite_respuesta):
+--   * desde el panel, por un admin, en cualquier momento;
+--   * desde el seguimiento público, por el consumidor, dentro de
+--     dias_reapertura días calendario desde fecha_cierre.
+--
+-- dias_reapertura = 0 desactiva la reapertura por el consumidor.
+-- fecha_cierre se fija al pasar a RESUELTO / CERRADO / RECHAZADO y se limpia al reabrir.
+-- =============================================================================
+ALTER TABLE configuracion_tenant ADD COLUMN IF NOT EXISTS dias_reapertura INT NOT NULL DEFAULT 15;
+
+COMMENT ON COLUMN configuracion_tenant.dias_reapertura IS 'Días para que el consumidor reabra un reclamo resuelto (0 = desactivado)';
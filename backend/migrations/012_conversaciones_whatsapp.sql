@@ -0,0 +1,29 @@
+-- =============================================================================
+-- 31. CONVERSACIONES DE WHATSAPP
+-- =============================================================================
+-- Memoria del bot de WhatsApp por (tenant, teléfono): últimos mensajes que se
+-- envían a la IA y último ACK enviado mientras una solicitud está EN_ATENCION.
+-- Vive en BD para sobrevivir a despliegues y funcionar con varias réplicas.
+--
+--   mensajes:         JSONB [{"role":"user|assistant","content":"..."}] (máx. 20)
+--   ultima_actividad: último mensaje; la conversación expira 15 min después
+--   ultimo_ack:       throttle del ACK (1 cada 5 min)
+--
+-- TTL: la fila se elimina cuando vencen tanto la conversación como el ACK.
+-- Las lecturas filtran por ultima_actividad, sin esperar al job de TTL.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS conversaciones_whatsapp (
+    tenant_id           UUID        NOT NULL,
+    telefono            STRING      NOT NULL,
+
+    mensajes            JSONB       NOT NULL DEFAULT '[]',
+    ultima_actividad    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ultimo_ack          TIMESTAMPTZ,
+
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '15 minutes',
+
+    PRIMARY KEY (tenant_id, telefono)
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@hourly');
+
+COMMENT ON TABLE conversaciones_whatsapp IS 'Memoria de conversación del bot de WhatsApp por tenant y teléfono';
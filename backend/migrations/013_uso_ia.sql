@@ -0,0 +1,116 @@
+-- =============================================================================
+-- 32. USO DE IA (ledger de tokens) Y CUOTA MENSUAL POR PLAN
+-- =============================================================================
+-- Cada llamada al proveedor de IA (asistente interno y bot de WhatsApp)
+-- registra sus tokens. La suma del mes calendario se compara con
+-- max_tokens_ia_mes del plan (o el override de la suscripción).
+--
+--   origen: ASISTENTE | WHATSAPP
+--   tokens: prompt + output cuentan contra la cuota
+--   max_tokens_ia_mes: -1 = ilimitado
+--
+-- Al agotar la cuota: WhatsApp responde con el menú sin IA (consulta de
+-- códigos sigue funcionando) y el asistente interno responde 403.
+--
+-- TTL: el detalle se conserva 13 meses (reporte interanual del mes).
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS uso_ia (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+
+    origen              STRING      NOT NULL,
+    proveedor           STRING      NOT NULL,
+    tokens_prompt       INT         NOT NULL DEFAULT 0,
+    tokens_output       INT         NOT NULL DEFAULT 0,
+
+    fecha               TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '400 days',
+
+    PRIMARY KEY (tenant_id, id),
+
+    CONSTRAINT chk_uso_ia_origen CHECK (origen IN ('ASISTENTE', 'WHATSAPP'))
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@daily');
+
+-- Suma del mes (cuota) y reporte por rango de fechas
+CREATE INDEX IF NOT EXISTS idx_uso_ia_fecha
+    ON uso_ia (tenant_id, fecha)
+    STORING (origen, proveedor, tokens_prompt, tokens_output);
+
+COMMENT ON TABLE uso_ia IS 'Ledger de tokens consumidos por llamada a la IA (asistente y WhatsApp)';
+
+
+-- ─── Límite por plan + override por suscripción ────────────────────────────
+
+ALTER TABLE planes ADD COLUMN IF NOT EXISTS max_tokens_ia_mes INT8 NOT NULL DEFAULT -1;
+ALTER TABLE suscripciones ADD COLUMN IF NOT EXISTS override_max_tokens_ia INT8;
+
+UPDATE planes SET max_tokens_ia_mes = 100000   WHERE codigo = 'DEMO';
+UPDATE planes SET max_tokens_ia_mes = 1000000  WHERE codigo = 'EMPRENDEDOR';
+UPDATE planes SET max_tokens_ia_mes = 5000000  WHERE codigo = 'PYME';
+UPDATE planes SET max_tokens_ia_mes = 20000000 WHERE codigo = 'PRO';
+
+COMMENT ON COLUMN planes.max_tokens_ia_mes IS 'Tokens de IA (prompt + output) por mes calendario. -1 = ilimitado.';
+
+
+-- ─── Recrear v_uso_tenant con límite y uso de tokens ───────────────────────
+
+DROP VIEW IF EXISTS v_uso_tenant CASCADE;
+
+CREATE VIEW v_uso_tenant AS
+SELECT
+    ct.tenant_id,
+    -- Plan actual
+    p.id                                        AS plan_id,
+    p.codigo                                    AS plan_codigo,
+    p.nombre                                    AS plan_nombre,
+    s.id                                        AS suscripcion_id,
+    s.estado                                    AS suscripcion_estado,
+    s.ciclo                                     AS suscripcion_ciclo,
+    s.es_trial                                  AS suscripcion_es_trial,
+    s.fecha_inicio                              AS suscripcion_fecha_inicio,
+    s.fecha_fin                                 AS suscripcion_fecha_fin,
+    s.fecha_fin_trial                           AS suscripcion_fecha_fin_trial,
+    s.fecha_proximo_cobro                       AS suscripcion_proximo_cobro,
+
+    -- Límites efectivos (override > plan, -1 = ilimitado)
+    COALESCE(s.override_max_sedes, p.max_sedes)                         AS limite_sedes,
+    COALESCE(s.override_max_usuarios, p.max_usuarios)                   AS limite_usuarios,
+    COALESCE(s.override_max_reclamos, p.max_reclamos_mes)               AS limite_reclamos_mes,
+    COALESCE(s.override_max_chatbots, p.max_chatbots)                   AS limite_chatbots,
+    COALESCE(s.override_max_canales_whatsapp, p.max_canales_whatsapp)   AS limite_canales_whatsapp,
+    COALESCE(s.override_max_storage_mb, p.max_storage_mb)               AS limite_storage_mb,
+    COALESCE(s.override_max_tokens_ia, p.max_tokens_ia_mes)             AS limite_tokens_ia_mes,
+
+    -- Funcionalidades
+    p.permite_chatbot,
+    p.permite_whatsapp,
+    p.permite_email,
+    p.permite_reportes_pdf,
+    p.permite_exportar_excel,
+    p.permite_api,
+    p.permite_marca_blanca,
+    p.permite_multi_idioma,
+    p.permite_asistente_ia,
+    p.permite_atencion_vivo,
+
+    -- Uso actual
+    (SELECT COUNT(*) FROM sedes sd
+     WHERE sd.tenant_id = ct.tenant_id AND sd.activo = true)                AS uso_sedes,
+    (SELECT COUNT(*) FROM usuarios_admin ua
+     WHERE ua.tenant_id = ct.tenant_id AND ua.activo = true)                AS uso_usuarios,
+    (SELECT COUNT(*) FROM reclamos r
+     WHERE r.tenant_id = ct.tenant_id
+       AND r.deleted_at IS NULL
+       AND r.fecha_registro >= DATE_TRUNC('month', CURRENT_DATE))           AS uso_reclamos_mes,
+    (SELECT COUNT(*) FROM chatbots cb
+     WHERE cb.tenant_id = ct.tenant_id AND cb.activo = true)                AS uso_chatbots,
+    (SELECT COUNT(*) FROM canales_whatsapp cw
+     WHERE cw.tenant_id = ct.tenant_id AND cw.activo = true)               AS uso_canales_whatsapp,
+    (SELECT COALESCE(SUM(ui.tokens_prompt + ui.tokens_output), 0)::INT8 FROM uso_ia ui
+     WHERE ui.tenant_id = ct.tenant_id
+       AND ui.fecha >= DATE_TRUNC('month', CURRENT_DATE))                   AS uso_tokens_ia_mes
+
+FROM configuracion_tenant ct
+JOIN suscripciones s ON s.tenant_id = ct.tenant_id AND s.estado IN ('ACTIVA', 'TRIAL')
+JOIN planes p ON p.id = s.plan_id;
@@ -0,0 +1,52 @@
+-- =============================================================================
+-- 33. ASISTENTE IA — ACCIONES PROPUESTAS (confirmar antes de ejecutar)
+-- =============================================================================
+-- Las herramientas de escritura del asistente interno (asignar, cambiar estado,
+-- responder) no se ejecutan al invocarlas: quedan PENDIENTES hasta que el
+-- usuario que conversa con el asistente las confirma o cancela desde el panel.
+-- Al confirmar se vuelven a validar rol, sede y estado del reclamo.
+--
+--   herramienta: asignar_reclamo | cambiar_estado_reclamo | responder_reclamo
+--   argumentos:  JSONB validado contra el schema de la herramienta
+--   estado:      PENDIENTE → EJECUTADA | CANCELADA | FALLIDA
+--
+-- Una acción pendiente vence a los 30 minutos (se valida al confirmar).
+-- TTL: las filas se eliminan a los 7 días, igual que las conversaciones.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS asistente_acciones (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    usuario_id          UUID        NOT NULL,
+    conversacion_id     UUID        NOT NULL,
+    reclamo_id          UUID        NOT NULL,
+
+    herramienta         STRING      NOT NULL,
+    argumentos          JSONB       NOT NULL DEFAULT '{}',
+    resumen             STRING      NOT NULL,
+
+    estado              STRING      NOT NULL DEFAULT 'PENDIENTE',
+    resultado           STRING,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_resolucion    TIMESTAMPTZ,
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '7 days',
+
+    PRIMARY KEY (tenant_id, id),
+
+    CONSTRAINT fk_asistente_accion_conv
+        FOREIGN KEY (tenant_id, conversacion_id)
+        REFERENCES asistente_conversaciones (tenant_id, id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT chk_asistente_accion_estado
+        CHECK (estado IN ('PENDIENTE', 'EJECUTADA', 'CANCELADA', 'FALLIDA'))
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@hourly');
+
+-- Acciones pendientes de una conversación (respuesta del chat y panel)
+CREATE INDEX IF NOT EXISTS idx_asistente_acciones_conv
+    ON asistente_acciones (tenant_id, conversacion_id, fecha_creacion)
+    STORING (usuario_id, herramienta, resumen, estado)
+    WHERE estado = 'PENDIENTE';
+
+COMMENT ON TABLE asistente_acciones IS 'Acciones de escritura propuestas por el asistente IA, pendientes de confirmación del usuario';
@@ -0,0 +1,15 @@
+-- =============================================================================
+-- 34. SUGERENCIA DE RESPUESTA CON IA
+-- =============================================================================
+-- POST /api/v1/reclamos/:id/respuestas/sugerencia redacta un borrador de
+-- respuesta_empresa, accion_tomada y compensacion_ofrecida a partir del
+-- reclamo, sus mensajes de seguimiento y respuestas del tenant a casos
+-- parecidos (búsqueda por texto completo sobre reclamos.busqueda_tsv, ver 29).
+-- El borrador no se guarda: el usuario lo edita y lo envía con el POST normal
+-- de respuestas.
+--
+-- Sus tokens cuentan contra la cuota mensual con origen SUGERENCIA_RESPUESTA.
+-- =============================================================================
+ALTER TABLE uso_ia DROP CONSTRAINT IF EXISTS chk_uso_ia_origen;
+ALTER TABLE uso_ia ADD CONSTRAINT chk_uso_ia_origen
+    CHECK (origen IN ('ASISTENTE', 'WHATSAPP', 'SUGERENCIA_RESPUESTA'));
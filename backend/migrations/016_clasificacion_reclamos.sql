@@ -0,0 +1,68 @@
+-- =============================================================================
+-- 35. CLASIFICACIÓN AUTOMÁTICA DE RECLAMOS CON IA
+-- =============================================================================
+-- Los reclamos creados por el formulario público o por WhatsApp quedan con
+-- clasificacion_estado = 'PENDIENTE'. Un job del scheduler los toma en lotes
+-- (lease en clasificacion_proximo, como el outbox) y pide a la IA:
+--
+--   categoria:   una de categorias_reclamo del tenant (NULL si no hay taxonomía
+--                o ninguna aplica). Se guarda el nombre como snapshot.
+--   sentimiento: NEGATIVO | NEUTRO | POSITIVO
+--   urgencia:    BAJA | MEDIA | ALTA | CRITICA   (percibida en el relato; no
+--                confundir con la prioridad por fecha límite)
+--   resumen_ia:  una o dos oraciones
+--
+--   clasificacion_estado: PENDIENTE → CLASIFICADO
+--                                   ↘ PENDIENTE (reintento con backoff)
+--                                   ↘ FALLIDO   (agotó los intentos)
+--
+-- Estas columnas no forman parte del hash de la cadena de integridad (ver 28):
+-- son metadatos de gestión, no el contenido registrado por el consumidor.
+-- Los tokens cuentan contra la cuota mensual con origen CLASIFICACION.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS categorias_reclamo (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+
+    nombre              STRING      NOT NULL,
+    descripcion         STRING,
+    activo              BOOL        NOT NULL DEFAULT true,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_actualizacion TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id, id),
+    UNIQUE (tenant_id, nombre)
+);
+
+COMMENT ON TABLE categorias_reclamo IS 'Taxonomía de categorías definida por el tenant para la clasificación con IA';
+
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS categoria STRING;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS sentimiento STRING;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS urgencia STRING;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS resumen_ia STRING;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS clasificacion_estado STRING;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS clasificacion_intentos INT NOT NULL DEFAULT 0;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS clasificacion_proximo TIMESTAMPTZ;
+ALTER TABLE reclamos ADD COLUMN IF NOT EXISTS fecha_clasificacion TIMESTAMPTZ;
+
+ALTER TABLE reclamos ADD CONSTRAINT chk_reclamos_sentimiento
+    CHECK (sentimiento IS NULL OR sentimiento IN ('NEGATIVO', 'NEUTRO', 'POSITIVO'));
+ALTER TABLE reclamos ADD CONSTRAINT chk_reclamos_urgencia
+    CHECK (urgencia IS NULL OR urgencia IN ('BAJA', 'MEDIA', 'ALTA', 'CRITICA'));
+ALTER TABLE reclamos ADD CONSTRAINT chk_reclamos_clasificacion_estado
+    CHECK (clasificacion_estado IS NULL OR clasificacion_estado IN ('PENDIENTE', 'CLASIFICADO', 'FALLIDO'));
+
+-- Worker: pendientes listos para clasificar (cross-tenant)
+CREATE INDEX IF NOT EXISTS idx_reclamos_clasificacion_pendiente
+    ON reclamos (clasificacion_proximo)
+    WHERE clasificacion_estado = 'PENDIENTE';
+
+-- Filtros y dimensiones del dashboard
+CREATE INDEX IF NOT EXISTS idx_reclamos_categoria
+    ON reclamos (tenant_id, categoria)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE uso_ia DROP CONSTRAINT IF EXISTS chk_uso_ia_origen;
+ALTER TABLE uso_ia ADD CONSTRAINT chk_uso_ia_origen
+    CHECK (origen IN ('ASISTENTE', 'WHATSAPP', 'SUGERENCIA_RESPUESTA', 'CLASIFICACION'));
@@ -0,0 +1,58 @@
+-- =============================================================================
+-- 36. DUPLICADOS Y RECLAMOS RELACIONADOS
+-- =============================================================================
+-- Un mismo consumidor suele registrar el mismo reclamo por la web, WhatsApp y
+-- la API de bots, con códigos distintos. Al crear un reclamo se buscan otros
+-- del tenant de los últimos 30 días que coincidan en:
+--
+--   DOCUMENTO  tipo y número de documento
+--   EMAIL      email (sin distinguir mayúsculas)
+--   TELEFONO   últimos 9 dígitos del teléfono
+--   PEDIDO     número de pedido
+--   TEXTO      relato con la mayoría de palabras en común
+--
+-- Los pares probables quedan como PROBABLE (reclamo_id = el nuevo,
+-- relacionado_id = el anterior) para que el equipo los revise:
+--
+--   estado: PROBABLE → DESCARTADO   (no es el mismo caso)
+--                    → VINCULADO    (casos relacionados; ambos siguen abiertos)
+--                    → FUSIONADO    (reclamo_id se cierra y se atiende en
+--                                    relacionado_id)
+--
+-- Un vínculo o fusión manual sin detección previa se guarda con motivo MANUAL.
+-- Vincular y fusionar dejan constancia en el historial de ambos reclamos.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS reclamos_relacionados (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    reclamo_id          UUID        NOT NULL,
+    relacionado_id      UUID        NOT NULL,
+
+    motivos             JSONB       NOT NULL DEFAULT '[]',
+    puntaje             INT         NOT NULL DEFAULT 0,
+    estado              STRING      NOT NULL DEFAULT 'PROBABLE',
+
+    resuelto_por        UUID,
+    fecha_deteccion     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_resolucion    TIMESTAMPTZ,
+
+    PRIMARY KEY (tenant_id, id),
+    UNIQUE (tenant_id, reclamo_id, relacionado_id),
+
+    CONSTRAINT fk_relacion_reclamo
+        FOREIGN KEY (tenant_id, reclamo_id) REFERENCES reclamos (tenant_id, id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_relacion_relacionado
+        FOREIGN KEY (tenant_id, relacionado_id) REFERENCES reclamos (tenant_id, id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT chk_relacion_distintos CHECK (reclamo_id <> relacionado_id),
+    CONSTRAINT chk_relacion_estado
+        CHECK (estado IN ('PROBABLE', 'DESCARTADO', 'VINCULADO', 'FUSIONADO'))
+);
+
+COMMENT ON TABLE reclamos_relacionados IS 'Posibles duplicados detectados al registrar y vínculos/fusiones hechos por el equipo';
+
+-- Relaciones de un reclamo en cualquiera de los dos sentidos
+CREATE INDEX IF NOT EXISTS idx_relacionados_relacionado
+    ON reclamos_relacionados (tenant_id, relacionado_id);
@@ -0,0 +1,46 @@
+-- =============================================================================
+-- 37. PORTAL DEL CONSUMIDOR: CÓDIGOS DE ACCESO
+-- =============================================================================
+-- El consumidor entra al portal /libro/:slug con el email o teléfono que dejó
+-- en sus reclamos. Se le envía un código de 6 dígitos de un solo uso (por
+-- email, o por WhatsApp desde el canal activo del tenant) y al verificarlo
+-- recibe un token que le da acceso a todos sus reclamos con el tenant.
+--
+--   canal:   EMAIL | TELEFONO
+--   destino: email en minúsculas o últimos 9 dígitos del teléfono
+--
+-- Solo se guarda el hash del código. Vence a los 10 minutos, admite 5
+-- intentos y queda marcado al usarse; pedir uno nuevo invalida los anteriores.
+-- Las filas se conservan un día para limitar cuántos códigos se piden por
+-- destino e IP (TTL).
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS codigos_acceso_consumidor (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    canal               STRING      NOT NULL,
+    destino             STRING      NOT NULL,
+
+    codigo_hash         STRING      NOT NULL,
+    intentos            INT         NOT NULL DEFAULT 0,
+    usado               BOOL        NOT NULL DEFAULT false,
+    ip_address          STRING,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_vencimiento   TIMESTAMPTZ NOT NULL,
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '1 day',
+
+    PRIMARY KEY (tenant_id, id),
+
+    CONSTRAINT chk_codigo_acceso_canal CHECK (canal IN ('EMAIL', 'TELEFONO'))
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@hourly');
+
+COMMENT ON TABLE codigos_acceso_consumidor IS 'Códigos de un solo uso para el acceso del consumidor al portal';
+
+-- Último código vigente y conteo de solicitudes recientes por destino
+CREATE INDEX IF NOT EXISTS idx_codigos_acceso_destino
+    ON codigos_acceso_consumidor (tenant_id, canal, destino, fecha_creacion DESC);
+
+-- Conteo de solicitudes recientes por IP
+CREATE INDEX IF NOT EXISTS idx_codigos_acceso_ip
+    ON codigos_acceso_consumidor (tenant_id, ip_address, fecha_creacion DESC);
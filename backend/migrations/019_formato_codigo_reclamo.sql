@@ -0,0 +1,22 @@
+-- =============================================================================
+-- 38. FORMATO DEL CÓDIGO DE RECLAMO
+-- =============================================================================
+-- La parte final del código deja de derivarse de la hora de registro (dos
+-- reclamos en el mismo instante chocaban y los códigos se podían adivinar):
+-- ahora son 8 caracteres aleatorios (crypto/rand) sin caracteres ambiguos y
+-- se reintenta si el código ya existe en el tenant (idx_reclamo_codigo).
+--
+-- Cada tenant puede definir el formato con estos marcadores:
+--
+--   {AAAA}       año de registro
+--   {TENANT}     slug del tenant (máx. 8)
+--   {SEDE}       slug de la sede (máx. 4); sin sede se omite con su guion
+--   {ALEATORIO}  8 caracteres aleatorios (obligatorio)
+--   {DV}         dígito verificador ISO 7064 MOD 31,30 (opcional, al final)
+--
+-- NULL = formato histórico {AAAA}-{TENANT}-{SEDE}-{ALEATORIO}. Los códigos ya
+-- emitidos no cambian.
+-- =============================================================================
+ALTER TABLE configuracion_tenant ADD COLUMN IF NOT EXISTS formato_codigo STRING;
+
+COMMENT ON COLUMN configuracion_tenant.formato_codigo IS 'Formato del código de reclamo con marcadores {AAAA} {TENANT} {SEDE} {ALEATORIO} {DV} (NULL = por defecto)';
@@ -0,0 +1,31 @@
+-- =============================================================================
+-- 39. WEBHOOK DE WHATSAPP: FIRMA DE META E IDEMPOTENCIA
+-- =============================================================================
+-- Meta firma cada POST al webhook con X-Hub-Signature-256 (HMAC-SHA256 del
+-- body con el secreto de la app). Se valida con WHATSAPP_APP_SECRET y, para
+-- los canales conectados a otra app de Meta, con su propio app_secret
+-- ('' = usa el secreto global).
+--
+-- Meta reintenta un mismo mensaje (mismo wamid) hasta por 7 días si no recibe
+-- un 200 a tiempo. Cada mensaje se registra antes de procesarlo; si ya estaba,
+-- se ignora y el bot no responde ni registra el reclamo dos veces.
+--
+-- TTL: el registro se elimina a los 7 días, cuando Meta ya no reintenta.
+-- =============================================================================
+ALTER TABLE canales_whatsapp ADD COLUMN IF NOT EXISTS app_secret STRING NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN canales_whatsapp.app_secret IS 'Secreto de la app de Meta del canal para validar la firma del webhook ('''' = secreto global)';
+
+CREATE TABLE IF NOT EXISTS mensajes_whatsapp_procesados (
+    tenant_id           UUID        NOT NULL,
+    mensaje_id          STRING      NOT NULL,   -- wamid de Meta
+    phone_number_id     STRING      NOT NULL,
+    fecha_recepcion     TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '7 days',
+
+    PRIMARY KEY (tenant_id, mensaje_id)
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@hourly');
+
+COMMENT ON TABLE mensajes_whatsapp_procesados IS 'Mensajes entrantes de WhatsApp ya procesados, para ignorar los reintentos de Meta';
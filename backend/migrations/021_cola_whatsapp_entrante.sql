@@ -0,0 +1,64 @@
+-- =============================================================================
+-- 40. COLA DE MENSAJES ENTRANTES DE WHATSAPP
+-- =============================================================================
+-- El webhook ya no procesa los mensajes en línea (la IA puede tardar hasta
+-- 30 s y Meta reenvía si no recibe un 200 a tiempo): valida la firma, encola
+-- y responde 200 de inmediato. Un pool de workers los procesa después.
+--
+--   estado: PENDIENTE → PROCESANDO → PROCESADO
+--                                  ↘ PENDIENTE (reintento del envío con backoff)
+--                                  ↘ FALLIDO   (agotó max_intentos)
+--
+-- Orden por teléfono: solo se toma el mensaje más antiguo sin terminar de
+-- cada (tenant, teléfono), así el bot nunca responde dos mensajes del mismo
+-- cliente a la vez ni fuera de orden.
+--
+-- Concurrencia por tenant: los workers de todas las réplicas toman como
+-- máximo N mensajes PROCESANDO por tenant (N lo fija el servicio), para que un
+-- tenant con mucho tráfico no acapare la IA.
+--
+-- respuesta: se guarda al generarla; un reintento solo reenvía el texto, sin
+-- volver a llamar a la IA ni registrar el reclamo otra vez.
+--
+-- TTL: se eliminan 7 días después de recibidos.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS cola_whatsapp_entrante (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+    phone_number_id     STRING      NOT NULL,
+    mensaje_id          STRING      NOT NULL,   -- wamid de Meta
+    telefono            STRING      NOT NULL,   -- remitente
+    tipo                STRING      NOT NULL,   -- text, image, audio, ...
+    texto               STRING,
+    fecha_mensaje       TIMESTAMPTZ NOT NULL,   -- timestamp de Meta
+
+    estado              STRING      NOT NULL DEFAULT 'PENDIENTE',
+    intentos            INT         NOT NULL DEFAULT 0,
+    max_intentos        INT         NOT NULL DEFAULT 5,
+    proximo_intento     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    bloqueado_hasta     TIMESTAMPTZ,
+    respuesta           STRING,
+    ultimo_error        STRING,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_proceso       TIMESTAMPTZ,
+
+    fecha_expiracion    TIMESTAMPTZ NOT NULL DEFAULT now() + INTERVAL '7 days',
+
+    PRIMARY KEY (tenant_id, id),
+
+    CONSTRAINT chk_cola_wa_estado CHECK (estado IN ('PENDIENTE', 'PROCESANDO', 'PROCESADO', 'FALLIDO'))
+)
+WITH (ttl_expiration_expression = 'fecha_expiracion', ttl_job_cron = '@hourly');
+
+-- Un mismo wamid se encola una sola vez
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cola_wa_mensaje
+    ON cola_whatsapp_entrante (tenant_id, mensaje_id);
+
+-- Workers: cabeza de cada conversación sin terminar (cross-tenant)
+CREATE INDEX IF NOT EXISTS idx_cola_wa_pendientes
+    ON cola_whatsapp_entrante (tenant_id, telefono, fecha_mensaje, fecha_creacion)
+    STORING (estado, proximo_intento, bloqueado_hasta)
+    WHERE estado IN ('PENDIENTE', 'PROCESANDO');
+
+COMMENT ON TABLE cola_whatsapp_entrante IS 'Cola durable de mensajes entrantes de WhatsApp con orden por teléfono';
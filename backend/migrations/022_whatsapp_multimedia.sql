@@ -0,0 +1,37 @@
+-- =============================================================================
+-- 41. WHATSAPP: MULTIMEDIA, UBICACIÓN Y MENSAJES INTERACTIVOS
+-- =============================================================================
+-- El bot ya no responde solo a texto:
+--   image / document  → evidencia que se adjunta al reclamo al registrarlo
+--   audio             → nota de voz transcrita (speech-to-text) y tratada como texto
+--   location          → ubicación compartida, como texto para la IA
+--   interactive       → opción elegida en botones o listas enviados por el bot
+--
+-- contenido (cola): datos del mensaje que no caben en texto, tal como llegan
+-- de Meta (media_id, mime, nombre, caption, coordenadas u opción elegida).
+-- Los media_id de Meta vencen a los 30 días; el archivo se descarga recién
+-- al registrar el reclamo.
+--
+-- respuesta (cola): ahora guarda el mensaje saliente en JSON (texto, botones
+-- o lista) para que un reintento lo reenvíe igual. Las filas anteriores con
+-- texto plano se siguen enviando como texto.
+--
+-- evidencias (conversación): archivos recibidos antes de registrar el reclamo.
+-- Siguen la vigencia de la conversación (15 min sin actividad) y se vacían
+-- al adjuntarlos.
+--
+-- Las transcripciones de notas de voz cuentan contra la cuota mensual de IA
+-- con origen TRANSCRIPCION (los proveedores que cobran por duración se
+-- convierten a tokens).
+-- =============================================================================
+ALTER TABLE cola_whatsapp_entrante ADD COLUMN IF NOT EXISTS contenido JSONB;
+
+COMMENT ON COLUMN cola_whatsapp_entrante.contenido IS 'Multimedia, ubicación u opción interactiva del mensaje (NULL en mensajes de texto)';
+
+ALTER TABLE conversaciones_whatsapp ADD COLUMN IF NOT EXISTS evidencias JSONB NOT NULL DEFAULT '[]';
+
+COMMENT ON COLUMN conversaciones_whatsapp.evidencias IS 'Archivos enviados por el cliente pendientes de adjuntar al reclamo';
+
+ALTER TABLE uso_ia DROP CONSTRAINT IF EXISTS chk_uso_ia_origen;
+ALTER TABLE uso_ia ADD CONSTRAINT chk_uso_ia_origen
+    CHECK (origen IN ('ASISTENTE', 'WHATSAPP', 'SUGERENCIA_RESPUESTA', 'CLASIFICACION', 'TRANSCRIPCION'));
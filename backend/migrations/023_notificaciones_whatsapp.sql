@@ -0,0 +1,62 @@
+-- =============================================================================
+-- 42. NOTIFICACIONES POR WHATSAPP (PLANTILLAS DE META)
+-- =============================================================================
+-- Si el tenant activa notificar_whatsapp, el consumidor recibe por WhatsApp
+-- los mismos avisos que por email (cambio de estado, resolución, mensaje
+-- nuevo) desde el primer canal activo del tenant.
+--
+-- Fuera de la ventana de 24 h de una conversación, Meta solo permite enviar
+-- plantillas aprobadas. Cada tenant registra la suya por tipo de notificación:
+--
+--   tipo:      CAMBIO_ESTADO | RESOLUCION | MENSAJE_NUEVO
+--   nombre:    nombre de la plantilla en WhatsApp Manager
+--   idioma:    código de idioma de la plantilla (es, es_PE, ...)
+--   variables: qué dato va en {{1}}, {{2}}, ... del cuerpo, en orden:
+--              codigo | nombre_cliente | estado | fecha_limite | empresa | mensaje
+--
+-- Sin plantilla activa para el tipo no se encola nada por WhatsApp.
+--
+-- Seguimiento de entrega (webhook "statuses" de Meta), en el outbox:
+--   mensaje_externo_id: wamid devuelto por Meta al enviar
+--   estado_entrega:     ENVIADO → ENTREGADO → LEIDO
+--                       ↘ FALLIDO (Meta no pudo entregarlo; error_entrega)
+-- Los estados llegan desordenados: nunca se retrocede (LEIDO no vuelve a
+-- ENTREGADO).
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS plantillas_whatsapp (
+    tenant_id           UUID        NOT NULL,
+    id                  UUID        NOT NULL DEFAULT gen_random_uuid(),
+
+    tipo                STRING      NOT NULL,
+    nombre              STRING      NOT NULL,
+    idioma              STRING      NOT NULL DEFAULT 'es',
+    variables           JSONB       NOT NULL DEFAULT '[]',
+    activo              BOOL        NOT NULL DEFAULT true,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_actualizacion TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id, id),
+    UNIQUE (tenant_id, tipo),
+
+    CONSTRAINT chk_plantillas_whatsapp_tipo CHECK (tipo IN ('CAMBIO_ESTADO', 'RESOLUCION', 'MENSAJE_NUEVO'))
+);
+
+COMMENT ON TABLE plantillas_whatsapp IS 'Plantillas de WhatsApp aprobadas por Meta para notificar al consumidor, una por tipo';
+
+ALTER TABLE notificaciones_outbox ADD COLUMN IF NOT EXISTS mensaje_externo_id STRING;
+ALTER TABLE notificaciones_outbox ADD COLUMN IF NOT EXISTS estado_entrega STRING;
+ALTER TABLE notificaciones_outbox ADD COLUMN IF NOT EXISTS fecha_entrega TIMESTAMPTZ;
+ALTER TABLE notificaciones_outbox ADD COLUMN IF NOT EXISTS fecha_lectura TIMESTAMPTZ;
+ALTER TABLE notificaciones_outbox ADD COLUMN IF NOT EXISTS error_entrega STRING;
+
+ALTER TABLE notificaciones_outbox ADD CONSTRAINT chk_outbox_estado_entrega
+    CHECK (estado_entrega IS NULL OR estado_entrega IN ('ENVIADO', 'ENTREGADO', 'LEIDO', 'FALLIDO'));
+
+-- Webhook de estados: notificación por wamid
+CREATE INDEX IF NOT EXISTS idx_outbox_mensaje_externo
+    ON notificaciones_outbox (tenant_id, mensaje_externo_id)
+    WHERE mensaje_externo_id IS NOT NULL;
+
+COMMENT ON COLUMN notificaciones_outbox.mensaje_externo_id IS 'wamid del mensaje de WhatsApp enviado (NULL en email)';
+COMMENT ON COLUMN notificaciones_outbox.estado_entrega IS 'Último estado informado por Meta: ENVIADO, ENTREGADO, LEIDO o FALLIDO';
@@ -0,0 +1,51 @@
+-- =============================================================================
+-- 43. CIFRADO DE SECRETOS EN REPOSO (ENVELOPE ENCRYPTION)
+-- =============================================================================
+-- Los tokens de WhatsApp (access_token, verify_token, app_secret) y las
+-- credenciales de futuras integraciones se guardan cifrados:
+--
+--   clave maestra (KEK)  → configuración (SECRETS_MASTER_KEY), con versión
+--   clave de datos (DEK) → una activa por tenant, envuelta con la KEK (aquí)
+--   secreto              → AES-256-GCM con la DEK del tenant
+--
+-- Formato del secreto en su columna: enc:v1:<version_dek>:<base64>
+-- El cifrado se ata a <tenant_id>:<columna>: copiado a otro tenant o a otra
+-- columna no abre.
+-- Un valor sin ese prefijo es texto plano anterior a esta migración: se sigue
+-- leyendo igual hasta que cmd/rotar_claves lo cifra.
+--
+-- Rotación (cmd/rotar_claves):
+--   -maestra  re-envuelve las DEK con la clave maestra activa (los secretos
+--             no cambian)
+--   -datos    crea una DEK nueva por tenant y re-cifra sus secretos
+-- Las DEK anteriores se conservan inactivas: otra réplica pudo cifrar con
+-- ellas mientras tenía la activa en caché.
+-- =============================================================================
+CREATE TABLE IF NOT EXISTS claves_datos_tenant (
+    tenant_id           UUID        NOT NULL,
+    version             INT         NOT NULL,
+
+    clave_cifrada       BYTES       NOT NULL,   -- DEK envuelta con la clave maestra
+    version_maestra     INT         NOT NULL,   -- versión de la clave maestra que la envuelve
+    activa              BOOL        NOT NULL DEFAULT true,
+
+    fecha_creacion      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    fecha_actualizacion TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id, version)
+);
+
+-- Una sola DEK activa por tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_claves_datos_activa
+    ON claves_datos_tenant (tenant_id)
+    WHERE activa = true;
+
+-- Rotación de la clave maestra: DEK envueltas con versiones anteriores
+CREATE INDEX IF NOT EXISTS idx_claves_datos_version_maestra
+    ON claves_datos_tenant (version_maestra);
+
+COMMENT ON TABLE claves_datos_tenant IS 'Claves de datos por tenant, envueltas con la clave maestra de la configuración';
+
+COMMENT ON COLUMN canales_whatsapp.access_token IS 'Token de Meta, cifrado con la clave de datos del tenant (enc:v1:...)';
+COMMENT ON COLUMN canales_whatsapp.verify_token IS 'Token de verificación del webhook, cifrado con la clave de datos del tenant';
+COMMENT ON COLUMN canales_whatsapp.app_secret IS 'Secreto de la app de Meta del canal, cifrado con la clave de datos del tenant ('''' = secreto global)';
@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"libro-reclamaciones/internal/ai"
+)
+
+// TestAIStream_ToolCallsOpenAI los argumentos de una herramienta llegan
+// fragmentados en varios eventos SSE y se reensamblan en ToolCalls; los
+// resultados de herramientas vuelven al proveedor como mensajes "tool".
+func TestAIStream_ToolCallsOpenAI(t *testing.T) {
+	var recibido struct {
+		Messages []struct {
+			Role       string `json:"role"`
+			ToolCallID string `json:"tool_call_id"`
+			ToolCalls  []struct {
+				ID       string `json:"id"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"messages"`
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&recibido); err != nil {
+			t.Errorf("body inválido: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		eventos := []string{
+			`{"choices":[{"delta":{"content":"Buscando"}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"call_1","function":{"name":"obtener_reclamo","arguments":"{\"codi"}}]}}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"go\":\"R-1\"}"}}]}}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":7}}`,
+			`[DONE]`,
+		}
+		for _, e := range eventos {
+			fmt.Fprintf(w, "data: %s\n\n", e)
+		}
+	}))
+	defer srv.Close()
+
+	provider, err := ai.NewProvider(ai.GatewayConfig{Provider: "openai", APIKey: "test", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+
+	req := ai.ChatRequest{
+		Messages: []ai.Message{
+			{Role: "user", Content: "¿Cómo va R-0?"},
+			{Role: "assistant", ToolCalls: []ai.ToolCall{{ID: "call_0", Name: "obtener_reclamo", Arguments: json.RawMessage(`{"codigo":"R-0"}`)}}},
+			{Role: "tool", ToolCallID: "call_0", ToolName: "obtener_reclamo", Content: `{"estado":"PENDIENTE"}`},
+		},
+	}
+
+	var deltas string
+	resp, err := provider.ChatStream(context.Background(), req, func(texto string) error {
+		deltas += texto
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	if deltas != "Buscando" || resp.Content != "Buscando" {
+		t.Errorf("texto = %q / %q, want %q", deltas, resp.Content, "Buscando")
+	}
+	if resp.PromptTokens != 12 || resp.OutputTokens != 7 {
+		t.Errorf("tokens = %d/%d, want 12/7", resp.PromptTokens, resp.OutputTokens)
+	}
+	if len(resp.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %+v, want 1", resp.ToolCalls)
+	}
+	tc := resp.ToolCalls[0]
+	if tc.ID != "call_1" || tc.Name != "obtener_reclamo" || string(tc.Arguments) != `{"codigo":"R-1"}` {
+		t.Errorf("ToolCall = %s %s %s", tc.ID, tc.Name, tc.Arguments)
+	}
+
+	// Historial enviado: user, assistant con tool_calls, tool con su id
+	if len(recibido.Messages) != 3 {
+		t.Fatalf("mensajes enviados = %+v, want 3", recibido.Messages)
+	}
+	asistente, tool := recibido.Messages[1], recibido.Messages[2]
+	if len(asistente.ToolCalls) != 1 || asistente.ToolCalls[0].ID != "call_0" ||
+		asistente.ToolCalls[0].Function.Arguments != `{"codigo":"R-0"}` {
+		t.Errorf("mensaje assistant = %+v", asistente)
+	}
+	if tool.Role != "tool" || tool.ToolCallID != "call_0" {
+		t.Errorf("mensaje tool = %+v", tool)
+	}
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// auditorMemoria guarda lo que el middleware manda a registrar.
+type auditorMemoria struct {
+	entradas []entradaAuditada
+}
+
+type entradaAuditada struct {
+	auditoria      *model.Auditoria
+	antes, despues interface{}
+}
+
+func (a *auditorMemoria) Registrar(_ context.Context, au *model.Auditoria, antes, despues interface{}) error {
+	a.entradas = append(a.entradas, entradaAuditada{au, antes, despues})
+	return nil
+}
+
+type sedeAuditada struct {
+	ID     string `json:"id"`
+	Nombre string `json:"nombre"`
+}
+
+func TestAuditoriaMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tenantID, userID := uuid.New(), uuid.New()
+	sedes := map[string]sedeAuditada{"s1": {ID: "s1", Nombre: "Miraflores"}}
+	cargar := func(_ *gin.Context, id string) (interface{}, error) {
+		if s, ok := sedes[id]; ok {
+			return s, nil
+		}
+		return nil, nil
+	}
+
+	auditor := &auditorMemoria{}
+	auditar := middleware.NuevoAuditar(auditor, map[string]middleware.CargadorAuditoria{model.EntidadSede: cargar})
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		helper.SetContext(c, helper.CtxTenantID, tenantID)
+		helper.SetContext(c, helper.CtxUserID, userID)
+	})
+	r.PUT("/sedes/:id", auditar(model.EntidadSede, model.AuditActualizar, "id"), func(c *gin.Context) {
+		if c.Query("falla") != "" {
+			helper.ValidationError(c, "nombre requerido")
+			return
+		}
+		sedes[c.Param("id")] = sedeAuditada{ID: c.Param("id"), Nombre: "San Isidro"}
+		helper.Success(c, sedes[c.Param("id")])
+	})
+	r.POST("/sedes", auditar(model.EntidadSede, model.AuditCrear, ""), func(c *gin.Context) {
+		sedes["s2"] = sedeAuditada{ID: "s2", Nombre: "Surco"}
+		helper.Created(c, sedes["s2"])
+	})
+
+	enviar := func(metodo, ruta string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(metodo, ruta, nil))
+		return w.Code
+	}
+
+	// Respuesta de error: no se audita
+	if code := enviar(http.MethodPut, "/sedes/s1?falla=1"); code != http.StatusBadRequest {
+		t.Fatalf("PUT con error = %d", code)
+	}
+	if len(auditor.entradas) != 0 {
+		t.Fatalf("se auditó una respuesta 400: %+v", auditor.entradas)
+	}
+
+	// Actualización: estado antes y después del handler, ID de la ruta
+	if code := enviar(http.MethodPut, "/sedes/s1"); code != http.StatusOK {
+		t.Fatalf("PUT = %d", code)
+	}
+	if len(auditor.entradas) != 1 {
+		t.Fatalf("entradas = %d, want 1", len(auditor.entradas))
+	}
+	e := auditor.entradas[0]
+	if e.auditoria.TenantID != tenantID || e.auditoria.UsuarioID != userID || e.auditoria.Accion != model.AuditActualizar ||
+		e.auditoria.EntidadID.String != "s1" || !e.auditoria.IPAddress.Valid {
+		t.Errorf("auditoría = %+v", e.auditoria)
+	}
+	if e.antes.(sedeAuditada).Nombre != "Miraflores" || e.despues.(sedeAuditada).Nombre != "San Isidro" {
+		t.Errorf("antes = %+v, después = %+v", e.antes, e.despues)
+	}
+
+	// Creación: sin estado previo, el ID sale de data.id de la respuesta
+	if code := enviar(http.MethodPost, "/sedes"); code != http.StatusCreated {
+		t.Fatalf("POST = %d", code)
+	}
+	if len(auditor.entradas) != 2 {
+		t.Fatalf("entradas = %d, want 2", len(auditor.entradas))
+	}
+	e = auditor.entradas[1]
+	if e.auditoria.EntidadID.String != "s2" || e.antes != nil || e.despues.(sedeAuditada).Nombre != "Surco" {
+		t.Errorf("creación: auditoría = %+v, antes = %+v, después = %+v", e.auditoria, e.antes, e.despues)
+	}
+}
+
+func TestAuditoriaService_DiffYExportarCSV(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	auditoriaService := service.NewAuditoriaService(repo.NewAuditoriaRepo(testDB))
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM auditoria_admin WHERE tenant_id = $1`, tenantID)
+
+	// Un valor largo con tildes se corta por caracteres, sin dejar UTF-8 inválido
+	largo := strings.Repeat("ñ", 600)
+	a := &model.Auditoria{
+		TenantModel: model.TenantModel{TenantID: tenantID},
+		UsuarioID:   uuid.New(),
+		Accion:      model.AuditActualizar,
+		Entidad:     model.EntidadSede,
+		EntidadID:   model.NullString{NullString: sql.NullString{String: `=HYPERLINK("http://x","y")`, Valid: true}},
+	}
+	if err := auditoriaService.Registrar(ctx, a, sedeAuditada{Nombre: "corto"}, sedeAuditada{Nombre: largo}); err != nil {
+		t.Fatalf("Registrar: %v", err)
+	}
+	var detalles struct {
+		Despues struct {
+			Nombre string `json:"nombre"`
+		} `json:"despues"`
+	}
+	if err := json.Unmarshal(a.Detalles, &detalles); err != nil {
+		t.Fatalf("Detalles = %s: %v", a.Detalles, err)
+	}
+	if n := utf8.RuneCountInString(detalles.Despues.Nombre); !utf8.ValidString(detalles.Despues.Nombre) || n != 501 {
+		t.Errorf("nombre recortado a %d caracteres (válido: %v), want 500 + …", n, utf8.ValidString(detalles.Despues.Nombre))
+	}
+
+	// Valores que Excel tomaría como fórmula salen con ' delante
+	csvBytes, err := auditoriaService.ExportarCSV(ctx, repo.FiltrosAuditoria{TenantID: tenantID})
+	if err != nil {
+		t.Fatalf("ExportarCSV: %v", err)
+	}
+	if !strings.Contains(string(csvBytes), `'=HYPERLINK(`) {
+		t.Errorf("CSV sin escapar la fórmula:\n%s", csvBytes)
+	}
+}
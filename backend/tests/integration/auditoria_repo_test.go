@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/tests/testdata"
+
+	"github.com/google/uuid"
+)
+
+func TestAuditoriaRepo_CreateYListar(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	auditoriaRepo := repo.NewAuditoriaRepo(testDB)
+	ctx := context.Background()
+	entidadID := uuid.NewString()
+	defer testDB.ExecContext(ctx, `DELETE FROM auditoria_admin WHERE tenant_id = $1 AND entidad_id = $2`,
+		testdata.TestTenantID, entidadID)
+
+	for _, accion := range []string{model.AuditCrear, model.AuditActualizar} {
+		a := &model.Auditoria{
+			TenantModel: model.TenantModel{TenantID: testdata.TestTenantID},
+			UsuarioID:   testdata.TestUserID,
+			Accion:      accion,
+			Entidad:     model.EntidadSede,
+			EntidadID:   model.NullString{NullString: sql.NullString{String: entidadID, Valid: true}},
+			Detalles:    json.RawMessage(`{"despues":{"nombre":"Sede Auditoría"}}`),
+			IPAddress:   model.NullString{NullString: sql.NullString{String: "127.0.0.1", Valid: true}},
+		}
+		if err := auditoriaRepo.Create(ctx, a); err != nil {
+			t.Fatalf("Create %s: %v", accion, err)
+		}
+	}
+
+	filtros := repo.FiltrosAuditoria{
+		TenantID:  testdata.TestTenantID,
+		Entidad:   model.EntidadSede,
+		EntidadID: entidadID,
+	}
+	registros, total, err := auditoriaRepo.Listar(ctx, filtros, 10, 0)
+	if err != nil {
+		t.Fatalf("Listar: %v", err)
+	}
+	if total != 2 || len(registros) != 2 {
+		t.Fatalf("total = %d, len = %d, want 2", total, len(registros))
+	}
+
+	filtros.Accion = model.AuditActualizar
+	registros, total, err = auditoriaRepo.Listar(ctx, filtros, 10, 0)
+	if err != nil {
+		t.Fatalf("Listar por acción: %v", err)
+	}
+	if total != 1 || registros[0].Accion != model.AuditActualizar {
+		t.Errorf("filtro por acción: total = %d, registros = %+v", total, registros)
+	}
+}
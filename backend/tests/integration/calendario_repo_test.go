@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/tests/testdata"
+)
+
+func TestCalendarioRepo_GuardarConfigIncrementaVersion(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	calRepo := repo.NewCalendarioRepo(testDB)
+	ctx := context.Background()
+
+	cfg := model.CalendarioConfigPorDefecto(testdata.TestTenantID)
+	if err := calRepo.GuardarConfig(ctx, cfg); err != nil {
+		t.Fatalf("GuardarConfig: %v", err)
+	}
+	primera := cfg.Version
+
+	cfg.DiasNoLaborables = []int{0}
+	if err := calRepo.GuardarConfig(ctx, cfg); err != nil {
+		t.Fatalf("GuardarConfig (update): %v", err)
+	}
+	if cfg.Version != primera+1 {
+		t.Errorf("expected version %d, got %d", primera+1, cfg.Version)
+	}
+
+	leida, err := calRepo.ObtenerConfig(ctx, testdata.TestTenantID)
+	if err != nil {
+		t.Fatalf("ObtenerConfig: %v", err)
+	}
+	if leida == nil || len(leida.DiasNoLaborables) != 1 || leida.DiasNoLaborables[0] != 0 {
+		t.Errorf("expected dias_no_laborables [0], got %+v", leida)
+	}
+}
+
+func TestCalendarioRepo_CrearYEliminarFeriado(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	calRepo := repo.NewCalendarioRepo(testDB)
+	ctx := context.Background()
+
+	f := &model.Feriado{
+		TenantModel: model.TenantModel{TenantID: testdata.TestTenantID},
+		Fecha:       time.Date(2031, time.March, 3, 0, 0, 0, 0, time.UTC),
+		Descripcion: "Aniversario de la empresa",
+	}
+	if err := calRepo.CrearFeriado(ctx, f); err != nil {
+		t.Fatalf("CrearFeriado: %v", err)
+	}
+
+	ok, err := calRepo.EliminarFeriado(ctx, testdata.TestTenantID, f.ID)
+	if err != nil || !ok {
+		t.Fatalf("EliminarFeriado: ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,306 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"libro-reclamaciones/internal/cifrado"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// almacenClavesMemoria implementa cifrado.AlmacenClaves sin BD.
+type almacenClavesMemoria struct {
+	mu     sync.Mutex
+	claves []model.ClaveDatosTenant
+}
+
+func (a *almacenClavesMemoria) buscar(f func(c model.ClaveDatosTenant) bool) *model.ClaveDatosTenant {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, c := range a.claves {
+		if f(c) {
+			return &c
+		}
+	}
+	return nil
+}
+
+func (a *almacenClavesMemoria) Activa(_ context.Context, tenantID uuid.UUID) (*model.ClaveDatosTenant, error) {
+	return a.buscar(func(c model.ClaveDatosTenant) bool { return c.TenantID == tenantID && c.Activa }), nil
+}
+
+func (a *almacenClavesMemoria) GetByVersion(_ context.Context, tenantID uuid.UUID, version int) (*model.ClaveDatosTenant, error) {
+	return a.buscar(func(c model.ClaveDatosTenant) bool { return c.TenantID == tenantID && c.Version == version }), nil
+}
+
+func (a *almacenClavesMemoria) UltimaVersion(_ context.Context, tenantID uuid.UUID) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	ultima := 0
+	for _, c := range a.claves {
+		if c.TenantID == tenantID && c.Version > ultima {
+			ultima = c.Version
+		}
+	}
+	return ultima, nil
+}
+
+func (a *almacenClavesMemoria) CrearPrimera(ctx context.Context, c *model.ClaveDatosTenant) (bool, error) {
+	if activa, _ := a.Activa(ctx, c.TenantID); activa != nil {
+		return false, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.claves = append(a.claves, *c)
+	return true, nil
+}
+
+func (a *almacenClavesMemoria) Rotar(_ context.Context, c *model.ClaveDatosTenant) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range a.claves {
+		if a.claves[i].TenantID == c.TenantID {
+			a.claves[i].Activa = false
+		}
+	}
+	a.claves = append(a.claves, *c)
+	return nil
+}
+
+func (a *almacenClavesMemoria) EnvueltasConOtraMaestra(_ context.Context, versionMaestra int) ([]model.ClaveDatosTenant, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var claves []model.ClaveDatosTenant
+	for _, c := range a.claves {
+		if c.VersionMaestra != versionMaestra {
+			claves = append(claves, c)
+		}
+	}
+	return claves, nil
+}
+
+func (a *almacenClavesMemoria) Reenvolver(_ context.Context, c *model.ClaveDatosTenant) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range a.claves {
+		if a.claves[i].TenantID == c.TenantID && a.claves[i].Version == c.Version {
+			a.claves[i].ClaveCifrada, a.claves[i].VersionMaestra = c.ClaveCifrada, c.VersionMaestra
+		}
+	}
+	return nil
+}
+
+func nuevoLlaveroTest(t *testing.T, activa int, versiones ...int) (*cifrado.Llavero, map[int][]byte) {
+	t.Helper()
+	claves := map[int][]byte{}
+	for _, v := range versiones {
+		claves[v] = bytes.Repeat([]byte{byte(v)}, cifrado.TamanoClave)
+	}
+	llavero, err := cifrado.NewLlavero(activa, claves)
+	if err != nil {
+		t.Fatalf("NewLlavero: %v", err)
+	}
+	return llavero, claves
+}
+
+func TestSecretos_CifrarDescifrar(t *testing.T) {
+	if _, err := cifrado.NewLlavero(1, map[int][]byte{1: []byte("corta")}); err == nil {
+		t.Error("una clave maestra de 5 bytes debería rechazarse")
+	}
+
+	llavero, _ := nuevoLlaveroTest(t, 1, 1)
+	almacen := &almacenClavesMemoria{}
+	secretos := cifrado.NewSecretos(llavero, almacen)
+	ctx := context.Background()
+	tenantA, tenantB := uuid.New(), uuid.New()
+
+	cifrado1, err := secretos.Cifrar(ctx, tenantA, "access_token", "EAAG-token-de-meta")
+	if err != nil {
+		t.Fatalf("Cifrar: %v", err)
+	}
+	if !strings.HasPrefix(cifrado1, "enc:v1:1:") || strings.Contains(cifrado1, "EAAG") {
+		t.Fatalf("cifrado = %q", cifrado1)
+	}
+	// Nonce aleatorio: el mismo texto no produce el mismo valor
+	if cifrado2, _ := secretos.Cifrar(ctx, tenantA, "access_token", "EAAG-token-de-meta"); cifrado2 == cifrado1 {
+		t.Error("dos cifrados del mismo texto son iguales")
+	}
+	if texto, err := secretos.Descifrar(ctx, tenantA, "access_token", cifrado1); err != nil || texto != "EAAG-token-de-meta" {
+		t.Fatalf("Descifrar = %q, %v", texto, err)
+	}
+
+	// La DEK se guarda envuelta, nunca en claro
+	if len(almacen.claves) != 1 || almacen.claves[0].VersionMaestra != 1 {
+		t.Fatalf("claves = %+v", almacen.claves)
+	}
+
+	// Vacío se conserva vacío y el texto plano anterior se lee tal cual
+	if v, _ := secretos.Cifrar(ctx, tenantA, "access_token", ""); v != "" {
+		t.Errorf("Cifrar(\"\") = %q", v)
+	}
+	if texto, err := secretos.Descifrar(ctx, tenantA, "access_token", "token-en-claro"); err != nil || texto != "token-en-claro" {
+		t.Errorf("Descifrar(texto plano) = %q, %v", texto, err)
+	}
+
+	// Copiado a otro tenant, a otra columna o alterado, no abre
+	if _, err := secretos.Descifrar(ctx, tenantB, "access_token", cifrado1); err == nil {
+		t.Error("un secreto de otro tenant no debería descifrarse")
+	}
+	if _, err := secretos.Descifrar(ctx, tenantA, "verify_token", cifrado1); err == nil {
+		t.Error("un secreto de otra columna no debería descifrarse")
+	}
+	alterado := cifrado1[:len(cifrado1)-4] + "AAA="
+	if _, err := secretos.Descifrar(ctx, tenantA, "access_token", alterado); err == nil {
+		t.Error("un secreto alterado no debería descifrarse")
+	}
+}
+
+func TestSecretos_Rotacion(t *testing.T) {
+	llavero1, claves := nuevoLlaveroTest(t, 1, 1)
+	almacen := &almacenClavesMemoria{}
+	secretos := cifrado.NewSecretos(llavero1, almacen)
+	ctx := context.Background()
+	tenantID := uuid.New()
+
+	antes, _ := secretos.Cifrar(ctx, tenantID, "app_secret", "token-v1")
+
+	// Rotar la DEK: lo cifrado antes sigue abriendo, pero ya no está vigente
+	version, err := secretos.RotarClaveDatos(ctx, tenantID)
+	if err != nil || version != 2 {
+		t.Fatalf("RotarClaveDatos = %d, %v", version, err)
+	}
+	if vigente, _ := secretos.Vigente(ctx, tenantID, antes); vigente {
+		t.Error("un secreto de la DEK v1 no debería estar vigente tras rotar")
+	}
+	despues, _ := secretos.Cifrar(ctx, tenantID, "app_secret", "token-v2")
+	if !strings.HasPrefix(despues, "enc:v1:2:") {
+		t.Errorf("tras rotar se cifra con %q, want DEK v2", despues)
+	}
+	if vigente, _ := secretos.Vigente(ctx, tenantID, despues); !vigente {
+		t.Error("un secreto recién cifrado debería estar vigente")
+	}
+	if vigente, _ := secretos.Vigente(ctx, tenantID, "token-en-claro"); vigente {
+		t.Error("un secreto en claro nunca está vigente")
+	}
+
+	// Rotar la clave maestra: nueva réplica con v2 activa y v1 como anterior
+	claves[2] = bytes.Repeat([]byte{2}, cifrado.TamanoClave)
+	llavero2, err := cifrado.NewLlavero(2, claves)
+	if err != nil {
+		t.Fatalf("NewLlavero v2: %v", err)
+	}
+	n, err := cifrado.NewSecretos(llavero2, almacen).RotarMaestra(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("RotarMaestra = %d, %v (want 2 DEK re-envueltas)", n, err)
+	}
+
+	// Retirada la maestra v1, los secretos siguen abriendo (no se re-cifraron)
+	llavero3, _ := cifrado.NewLlavero(2, map[int][]byte{2: claves[2]})
+	nueva := cifrado.NewSecretos(llavero3, almacen)
+	for secreto, want := range map[string]string{antes: "token-v1", despues: "token-v2"} {
+		if texto, err := nueva.Descifrar(ctx, tenantID, "app_secret", secreto); err != nil || texto != want {
+			t.Errorf("Descifrar tras rotar la maestra = %q, %v, want %q", texto, err, want)
+		}
+	}
+	if n, _ := nueva.RotarMaestra(ctx); n != 0 {
+		t.Errorf("segunda RotarMaestra re-envolvió %d", n)
+	}
+}
+
+func TestCanalWhatsAppRepo_TokensCifrados(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	llavero, _ := nuevoLlaveroTest(t, 1, 1)
+	secretos := cifrado.NewSecretos(llavero, repo.NewClaveDatosRepo(testDB))
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM canales_whatsapp WHERE tenant_id = $1`, tenantID)
+	defer testDB.ExecContext(ctx, `DELETE FROM claves_datos_tenant WHERE tenant_id = $1`, tenantID)
+
+	// Canal creado antes del cifrado: tokens en claro
+	enClaro := repo.NewCanalWhatsAppRepo(testDB, nil)
+	canal := &model.CanalWhatsApp{
+		TenantModel:   model.TenantModel{TenantID: tenantID},
+		PhoneNumberID: "test-" + uuid.NewString()[:8],
+		AccessToken:   "EAAG-token",
+		VerifyToken:   "verificar",
+		NombreCanal:   "Canal cifrado",
+	}
+	if err := enClaro.Create(ctx, canal); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	canalRepo := repo.NewCanalWhatsAppRepo(testDB, secretos)
+	leido, err := canalRepo.GetByPhoneNumberID(ctx, canal.PhoneNumberID)
+	if err != nil || leido == nil || leido.AccessToken != "EAAG-token" {
+		t.Fatalf("GetByPhoneNumberID (en claro) = %+v, %v", leido, err)
+	}
+
+	if n, err := canalRepo.RecifrarSecretos(ctx, tenantID); err != nil || n != 1 {
+		t.Fatalf("RecifrarSecretos = %d, %v", n, err)
+	}
+	if n, _ := canalRepo.RecifrarSecretos(ctx, tenantID); n != 0 {
+		t.Errorf("segundo RecifrarSecretos = %d, want 0", n)
+	}
+
+	var accessToken, verifyToken, appSecret string
+	testDB.QueryRowContext(ctx, `SELECT access_token, verify_token, app_secret FROM canales_whatsapp WHERE id = $1`, canal.ID).
+		Scan(&accessToken, &verifyToken, &appSecret)
+	if !cifrado.EstaCifrado(accessToken) || !cifrado.EstaCifrado(verifyToken) || appSecret != "" {
+		t.Fatalf("en BD: access=%q verify=%q app_secret=%q", accessToken, verifyToken, appSecret)
+	}
+
+	// Lectura transparente y Update no cifra dos veces
+	leido, _ = canalRepo.GetByID(ctx, tenantID, canal.ID)
+	leido.AccessToken = "EAAG-nuevo"
+	if err := canalRepo.Update(ctx, leido); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if leido.AccessToken != "EAAG-nuevo" {
+		t.Error("Update no debe modificar el struct del llamador")
+	}
+	canales, _ := canalRepo.GetByTenant(ctx, tenantID)
+	if len(canales) != 1 || canales[0].AccessToken != "EAAG-nuevo" || canales[0].VerifyToken != "verificar" {
+		t.Errorf("GetByTenant = %+v", canales)
+	}
+
+	// Un Update que llega mientras se re-cifra no se pisa con los tokens leídos antes
+	if _, err := secretos.RotarClaveDatos(ctx, tenantID); err != nil {
+		t.Fatalf("RotarClaveDatos: %v", err)
+	}
+	concurrente := &cifradorConHook{CifradorSecretos: secretos, antes: func() {
+		leido.AccessToken = "EAAG-concurrente"
+		if err := canalRepo.Update(ctx, leido); err != nil {
+			t.Errorf("Update concurrente: %v", err)
+		}
+	}}
+	if n, err := repo.NewCanalWhatsAppRepo(testDB, concurrente).RecifrarSecretos(ctx, tenantID); err != nil || n != 0 {
+		t.Errorf("RecifrarSecretos con Update concurrente = %d, %v, want 0", n, err)
+	}
+	if leido, _ = canalRepo.GetByID(ctx, tenantID, canal.ID); leido.AccessToken != "EAAG-concurrente" {
+		t.Errorf("AccessToken = %q, el re-cifrado pisó el Update concurrente", leido.AccessToken)
+	}
+}
+
+// cifradorConHook ejecuta antes una sola vez, en el primer Cifrar.
+type cifradorConHook struct {
+	repo.CifradorSecretos
+	antes func()
+}
+
+func (c *cifradorConHook) Cifrar(ctx context.Context, tenantID uuid.UUID, campo, texto string) (string, error) {
+	if c.antes != nil {
+		antes := c.antes
+		c.antes = nil
+		antes()
+	}
+	return c.CifradorSecretos.Cifrar(ctx, tenantID, campo, texto)
+}
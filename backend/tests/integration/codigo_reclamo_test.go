@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestGenerateCodigoReclamo(t *testing.T) {
+	anio := strconv.Itoa(time.Now().Year())
+	casos := []struct {
+		formato, sede string
+		patron        string
+	}{
+		{"", "miraflores", `^` + anio + `-POLLREY-MIRA-[2-9A-HJKMNP-TV-Z]{8}$`},
+		{"", "", `^` + anio + `-POLLREY-[2-9A-HJKMNP-TV-Z]{8}$`},
+		{"R-{SEDE}-{ALEATORIO}{DV}", "", `^R-[2-9A-HJKMNP-TV-Z]{9}$`},
+		{"{TENANT}{ALEATORIO}", "centro", `^POLLREY[2-9A-HJKMNP-TV-Z]{8}$`},
+	}
+	for _, c := range casos {
+		codigo, err := helper.GenerateCodigoReclamo(c.formato, "poll-rey", c.sede)
+		if err != nil {
+			t.Fatalf("GenerateCodigoReclamo(%q): %v", c.formato, err)
+		}
+		if !regexp.MustCompile(c.patron).MatchString(codigo) {
+			t.Errorf("GenerateCodigoReclamo(%q, %q) = %q, want %s", c.formato, c.sede, codigo, c.patron)
+		}
+	}
+
+	// Mismo instante, mismo tenant y sede: sin colisiones
+	vistos := make(map[string]bool)
+	for i := 0; i < 2000; i++ {
+		codigo, _ := helper.GenerateCodigoReclamo("", "poll-rey", "mira")
+		if vistos[codigo] {
+			t.Fatalf("código repetido: %s", codigo)
+		}
+		vistos[codigo] = true
+	}
+}
+
+func TestDigitoVerificador(t *testing.T) {
+	codigo, err := helper.GenerateCodigoReclamo("{AAAA}-{ALEATORIO}-{DV}", "demo", "")
+	if err != nil {
+		t.Fatalf("GenerateCodigoReclamo: %v", err)
+	}
+	if !helper.DigitoVerificadorValido(codigo) {
+		t.Fatalf("DigitoVerificadorValido(%q) = false", codigo)
+	}
+	if !helper.DigitoVerificadorValido(strings.ToLower(codigo)) {
+		t.Errorf("DigitoVerificadorValido(%q) en minúsculas = false", codigo)
+	}
+
+	// Cualquier carácter cambiado se detecta
+	for i, r := range codigo[:len(codigo)-1] {
+		if r == '-' {
+			continue
+		}
+		otro := byte('2')
+		if r == '2' {
+			otro = '3'
+		}
+		mal := codigo[:i] + string(otro) + codigo[i+1:]
+		if helper.DigitoVerificadorValido(mal) {
+			t.Errorf("DigitoVerificadorValido(%q) = true, want false (original %q)", mal, codigo)
+		}
+	}
+
+	// El dígito nunca es un carácter ambiguo (0/O, 1/I/L, U)
+	for i := 0; i < 500; i++ {
+		codigo, _ := helper.GenerateCodigoReclamo("LR-{AAAA}-{ALEATORIO}{DV}", "demo", "")
+		if dv := codigo[len(codigo)-1]; strings.ContainsRune("01ILOU", rune(dv)) {
+			t.Fatalf("dígito verificador ambiguo en %q", codigo)
+		}
+	}
+}
+
+func TestValidarFormatoCodigo(t *testing.T) {
+	casos := []struct {
+		formato string
+		ok      bool
+	}{
+		{helper.FormatoCodigoPorDefecto, true},
+		{"LR-{AAAA}-{ALEATORIO}-{DV}", true},
+		{"{AAAA}-{TENANT}", false},                              // sin parte aleatoria
+		{"{ALEATORIO}-{ALEATORIO}", false},                      // marcador repetido
+		{"{DV}-{ALEATORIO}", false},                             // DV no está al final
+		{"lr-{ALEATORIO}", false},                               // minúsculas
+		{"LR/{ALEATORIO}", false},                               // rompe la URL de seguimiento
+		{"{FECHA}-{ALEATORIO}", false},                          // marcador desconocido
+		{"LIBRO-DE-RECLAMACIONES-OFICIALES-{ALEATORIO}", false}, // más de 40 caracteres
+	}
+	for _, c := range casos {
+		if err := helper.ValidarFormatoCodigo(c.formato); (err == nil) != c.ok {
+			t.Errorf("ValidarFormatoCodigo(%q) = %v, want ok=%v", c.formato, err, c.ok)
+		}
+	}
+}
+
+func TestReclamoRepo_CodigoDuplicado(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+
+	primero := nuevoReclamoCadenaTest(tenantID, 0)
+	if err := reclamoRepo.Create(ctx, primero); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if existe, err := reclamoRepo.ExisteCodigo(ctx, tenantID, primero.CodigoReclamo); err != nil || !existe {
+		t.Fatalf("ExisteCodigo = %v, %v, want true", existe, err)
+	}
+	if existe, _ := reclamoRepo.ExisteCodigo(ctx, uuid.New(), primero.CodigoReclamo); existe {
+		t.Error("ExisteCodigo en otro tenant = true")
+	}
+
+	segundo := nuevoReclamoCadenaTest(tenantID, 1)
+	segundo.CodigoReclamo = primero.CodigoReclamo
+	if err := reclamoRepo.Create(ctx, segundo); !errors.Is(err, repo.ErrCodigoReclamoDuplicado) {
+		t.Fatalf("Create con código repetido = %v, want ErrCodigoReclamoDuplicado", err)
+	}
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestColaWhatsAppRepo_OrdenYConcurrencia(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	colaRepo := repo.NewColaWhatsAppRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM cola_whatsapp_entrante WHERE tenant_id = $1`, tenantID)
+
+	base := time.Now().Add(-time.Hour)
+	encolar := func(telefono string, orden int) *model.MensajeWhatsAppEntrante {
+		m := &model.MensajeWhatsAppEntrante{
+			TenantModel:   model.TenantModel{TenantID: tenantID},
+			PhoneNumberID: "123",
+			MensajeID:     "wamid." + uuid.NewString(),
+			Telefono:      telefono,
+			Tipo:          "text",
+			FechaMensaje:  base.Add(time.Duration(orden) * time.Second),
+		}
+		nuevo, err := colaRepo.Encolar(ctx, m)
+		if err != nil || !nuevo {
+			t.Fatalf("Encolar = %v, %v", nuevo, err)
+		}
+		return m
+	}
+
+	// Llegan fuera de orden: manda el timestamp de Meta
+	segundoAna := encolar("51911111111", 2)
+	primeroAna := encolar("51911111111", 1)
+	luis := encolar("51922222222", 3)
+	encolar("51933333333", 4)
+
+	if nuevo, _ := colaRepo.Encolar(ctx, &model.MensajeWhatsAppEntrante{
+		TenantModel: model.TenantModel{TenantID: tenantID}, PhoneNumberID: "123",
+		MensajeID: primeroAna.MensajeID, Telefono: "51911111111", Tipo: "text", FechaMensaje: base,
+	}); nuevo {
+		t.Error("Encolar con wamid repetido = true")
+	}
+
+	reclamar := func(maxPorTenant int) map[uuid.UUID]bool {
+		lote, err := colaRepo.Reclamar(ctx, 50, maxPorTenant, time.Minute)
+		if err != nil {
+			t.Fatalf("Reclamar: %v", err)
+		}
+		ids := make(map[uuid.UUID]bool)
+		for _, m := range lote {
+			if m.TenantID == tenantID {
+				ids[m.ID] = true
+			}
+		}
+		return ids
+	}
+
+	// Un mensaje por teléfono y como máximo 2 del tenant en proceso
+	lote := reclamar(2)
+	if len(lote) != 2 || !lote[primeroAna.ID] || !lote[luis.ID] {
+		t.Fatalf("primer lote = %v, want {%s, %s}", lote, primeroAna.ID, luis.ID)
+	}
+	if lote := reclamar(2); len(lote) != 0 {
+		t.Fatalf("con 2 en proceso el tenant no toma más: %v", lote)
+	}
+
+	// Al terminar el primero de Ana entra el siguiente de Ana, no antes
+	if err := colaRepo.MarcarProcesado(ctx, tenantID, primeroAna.ID); err != nil {
+		t.Fatalf("MarcarProcesado: %v", err)
+	}
+	if lote := reclamar(2); len(lote) != 1 || !lote[segundoAna.ID] {
+		t.Fatalf("tras procesar = %v, want {%s}", lote, segundoAna.ID)
+	}
+
+	// Un error con reintento mantiene el orden: la conversación espera el backoff
+	if _, err := colaRepo.MarcarError(ctx, tenantID, segundoAna.ID, "Meta 500", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("MarcarError: %v", err)
+	}
+	if lote := reclamar(3); len(lote) != 1 || lote[segundoAna.ID] {
+		t.Fatalf("con Ana en backoff = %v, want solo el tercer teléfono", lote)
+	}
+
+	// Lease vencido (réplica caída): se vuelve a tomar
+	if _, err := testDB.ExecContext(ctx, `UPDATE cola_whatsapp_entrante SET bloqueado_hasta = now() - INTERVAL '1 second' WHERE tenant_id = $1 AND id = $2`, tenantID, luis.ID); err != nil {
+		t.Fatalf("vencer lease: %v", err)
+	}
+	if lote := reclamar(3); !lote[luis.ID] {
+		t.Errorf("lease vencido no se retomó: %v", lote)
+	}
+}
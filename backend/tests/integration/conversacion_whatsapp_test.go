@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/google/uuid"
+)
+
+func TestConversacionStore(t *testing.T) {
+	stores := map[string]service.ConversacionStore{
+		"memoria": service.NewConversacionStoreMemoria(),
+	}
+	if testDB != nil {
+		stores["cockroach"] = repo.NewConversacionWhatsAppRepo(testDB)
+	}
+
+	for nombre, store := range stores {
+		t.Run(nombre, func(t *testing.T) {
+			ctx := context.Background()
+			tenantID := uuid.New()
+			telefono := "51987654321"
+			if testDB != nil {
+				defer testDB.ExecContext(ctx, `DELETE FROM conversaciones_whatsapp WHERE tenant_id = $1`, tenantID)
+			}
+
+			for i, contenido := range []string{"hola", "quiero reclamar", "mi pedido no llegó"} {
+				if err := store.Agregar(ctx, tenantID, telefono, ai.Message{Role: "user", Content: contenido}, 2, time.Minute); err != nil {
+					t.Fatalf("Agregar #%d: %v", i, err)
+				}
+			}
+
+			historial, err := store.Historial(ctx, tenantID, telefono, time.Minute)
+			if err != nil {
+				t.Fatalf("Historial: %v", err)
+			}
+			if len(historial) != 2 || historial[0].Content != "quiero reclamar" {
+				t.Fatalf("historial = %+v, want los 2 últimos mensajes", historial)
+			}
+
+			// Otro tenant con el mismo teléfono no comparte memoria
+			if otro, _ := store.Historial(ctx, uuid.New(), telefono, time.Minute); len(otro) != 0 {
+				t.Errorf("historial de otro tenant = %+v, want vacío", otro)
+			}
+
+			// ACK: el primero se envía, el segundo cae dentro del cooldown
+			if ok, err := store.MarcarACK(ctx, tenantID, telefono, time.Minute); err != nil || !ok {
+				t.Fatalf("MarcarACK #1 = %v, %v; want true", ok, err)
+			}
+			if ok, err := store.MarcarACK(ctx, tenantID, telefono, time.Minute); err != nil || ok {
+				t.Fatalf("MarcarACK #2 = %v, %v; want false", ok, err)
+			}
+		})
+	}
+}
+
+// TestConversacionStore_Evidencias archivos pendientes de adjuntar: tope por
+// conversación, limpieza al registrar y vigencia de la conversación.
+func TestConversacionStore_Evidencias(t *testing.T) {
+	stores := map[string]service.ConversacionStore{
+		"memoria": service.NewConversacionStoreMemoria(),
+	}
+	if testDB != nil {
+		stores["cockroach"] = repo.NewConversacionWhatsAppRepo(testDB)
+	}
+
+	for nombre, store := range stores {
+		t.Run(nombre, func(t *testing.T) {
+			ctx := context.Background()
+			tenantID := uuid.New()
+			telefono := "51987654321"
+			if testDB != nil {
+				defer testDB.ExecContext(ctx, `DELETE FROM conversaciones_whatsapp WHERE tenant_id = $1`, tenantID)
+			}
+
+			evidencia := func(id string) model.EvidenciaWhatsApp {
+				return model.EvidenciaWhatsApp{MediaID: id, MIME: "image/jpeg", Nombre: id + ".jpg", FechaRecibio: time.Now()}
+			}
+			for i, id := range []string{"m1", "m2", "m3"} {
+				agregada, err := store.AgregarEvidencia(ctx, tenantID, telefono, evidencia(id), 2, time.Minute)
+				if err != nil {
+					t.Fatalf("AgregarEvidencia #%d: %v", i, err)
+				}
+				if agregada != (i < 2) {
+					t.Errorf("AgregarEvidencia #%d = %v, want %v (tope 2)", i, agregada, i < 2)
+				}
+			}
+
+			// Los mensajes de texto no borran las evidencias de la conversación vigente
+			if err := store.Agregar(ctx, tenantID, telefono, ai.Message{Role: "user", Content: "mi pedido llegó roto"}, 20, time.Minute); err != nil {
+				t.Fatalf("Agregar: %v", err)
+			}
+			evidencias, err := store.Evidencias(ctx, tenantID, telefono, time.Minute)
+			if err != nil {
+				t.Fatalf("Evidencias: %v", err)
+			}
+			if len(evidencias) != 2 || evidencias[0].MediaID != "m1" || evidencias[1].Nombre != "m2.jpg" {
+				t.Fatalf("evidencias = %+v, want m1 y m2", evidencias)
+			}
+			if otro, _ := store.Evidencias(ctx, uuid.New(), telefono, time.Minute); len(otro) != 0 {
+				t.Errorf("evidencias de otro tenant = %+v, want vacío", otro)
+			}
+
+			if err := store.LimpiarEvidencias(ctx, tenantID, telefono); err != nil {
+				t.Fatalf("LimpiarEvidencias: %v", err)
+			}
+			if evidencias, _ := store.Evidencias(ctx, tenantID, telefono, time.Minute); len(evidencias) != 0 {
+				t.Fatalf("tras limpiar = %+v, want vacío", evidencias)
+			}
+
+			// Una conversación nueva (la anterior expiró) no hereda evidencias
+			if _, err := store.AgregarEvidencia(ctx, tenantID, telefono, evidencia("m4"), 2, time.Minute); err != nil {
+				t.Fatalf("AgregarEvidencia: %v", err)
+			}
+			if err := store.Agregar(ctx, tenantID, telefono, ai.Message{Role: "user", Content: "hola"}, 20, time.Nanosecond); err != nil {
+				t.Fatalf("Agregar: %v", err)
+			}
+			if evidencias, _ := store.Evidencias(ctx, tenantID, telefono, time.Minute); len(evidencias) != 0 {
+				t.Errorf("conversación nueva con evidencias = %+v", evidencias)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/model/dto"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/google/uuid"
+)
+
+// El formulario público envía acepta_terminos y acepta_copia.
+func TestCreateReclamoRequest_Conformidad(t *testing.T) {
+	var req dto.CreateReclamoRequest
+	if err := json.Unmarshal([]byte(`{"acepta_terminos":true,"acepta_copia":true}`), &req); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !req.AceptaTerminos || !req.AceptaCopia {
+		t.Errorf("req = %+v, want acepta_terminos y acepta_copia", req)
+	}
+}
+
+// TestOutbox_HojaConfirmacion la confirmación lleva la hoja en PDF solo si el
+// consumidor pidió copia.
+func TestOutbox_HojaConfirmacion(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	outbox := service.NewOutboxService(nil, reclamoRepo, nil, nil, nil, nil, service.NuevoExportarPDFServicio())
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+
+	conCopia := nuevoReclamoCadenaTest(tenantID, 0)
+	sinCopia := nuevoReclamoCadenaTest(tenantID, 1)
+	sinCopia.AceptaCopia = false
+	for _, rec := range []*model.Reclamo{conCopia, sinCopia} {
+		if err := reclamoRepo.Create(ctx, rec); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	hoja, err := outbox.HojaConfirmacion(ctx, tenantID, conCopia.ID)
+	if err != nil {
+		t.Fatalf("HojaConfirmacion: %v", err)
+	}
+	if !bytes.HasPrefix(hoja, []byte("%PDF")) {
+		t.Errorf("con acepta_copia no se adjunta la hoja (%d bytes)", len(hoja))
+	}
+
+	if hoja, err := outbox.HojaConfirmacion(ctx, tenantID, sinCopia.ID); err != nil || hoja != nil {
+		t.Errorf("sin acepta_copia: hoja de %d bytes, err = %v", len(hoja), err)
+	}
+}
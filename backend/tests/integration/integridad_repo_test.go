@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestIntegridadRepo_CadenaYAlteracion(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	historialRepo := repo.NewHistorialRepo(testDB)
+	integridadRepo := repo.NewIntegridadRepo(testDB)
+	transactor := repo.NewTransactor(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+	defer testDB.ExecContext(ctx, `DELETE FROM historial_reclamos WHERE tenant_id = $1`, tenantID)
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		rec := nuevoReclamoCadenaTest(tenantID, i)
+		err := transactor.EnTransaccion(ctx, func(tx *sql.Tx) error {
+			if err := reclamoRepo.WithTx(tx).Create(ctx, rec); err != nil {
+				return err
+			}
+			return historialRepo.WithTx(tx).Create(ctx, &model.Historial{
+				TenantModel: model.TenantModel{TenantID: tenantID},
+				ReclamoID:   rec.ID,
+				EstadoNuevo: model.EstadoPendiente,
+				TipoAccion:  model.AccionCreacion,
+			})
+		})
+		if err != nil {
+			t.Fatalf("Create #%d: %v", i, err)
+		}
+		if rec.CadenaSecuencia.Int64 != int64(i+1) {
+			t.Fatalf("secuencia = %d, want %d", rec.CadenaSecuencia.Int64, i+1)
+		}
+		ids = append(ids, rec.ID)
+	}
+
+	reclamos, err := integridadRepo.VerificarReclamos(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("VerificarReclamos: %v", err)
+	}
+	if !reclamos.Integra || reclamos.Eslabones != 3 {
+		t.Fatalf("cadena íntegra esperada con 3 eslabones, got %+v", reclamos)
+	}
+	historial, err := integridadRepo.VerificarHistorial(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("VerificarHistorial: %v", err)
+	}
+	if !historial.Integra || historial.Eslabones != 3 {
+		t.Fatalf("historial íntegro esperado con 3 eslabones, got %+v", historial)
+	}
+
+	// Alterar el segundo reclamo por fuera de la aplicación
+	if _, err := testDB.ExecContext(ctx,
+		`UPDATE reclamos SET detalle_reclamo = 'texto alterado' WHERE tenant_id = $1 AND id = $2`,
+		tenantID, ids[1]); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	reclamos, err = integridadRepo.VerificarReclamos(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("VerificarReclamos: %v", err)
+	}
+	if reclamos.Integra || reclamos.Quiebre == nil {
+		t.Fatalf("se esperaba quiebre, got %+v", reclamos)
+	}
+	if reclamos.Quiebre.Secuencia != 2 || reclamos.Quiebre.Motivo != model.QuiebreContenido {
+		t.Errorf("quiebre = %+v, want secuencia 2 %s", reclamos.Quiebre, model.QuiebreContenido)
+	}
+}
+
+func TestTransactor_ReintentaConflictoDeCadena(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	historialRepo := repo.NewHistorialRepo(testDB)
+	transactor := repo.NewTransactor(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+	defer testDB.ExecContext(ctx, `DELETE FROM historial_reclamos WHERE tenant_id = $1`, tenantID)
+
+	// Registros simultáneos del mismo tenant: ninguno debe fallar por el eslabón
+	const n = 6
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := nuevoReclamoCadenaTest(tenantID, i)
+			errs <- transactor.EnTransaccion(ctx, func(tx *sql.Tx) error {
+				if err := reclamoRepo.WithTx(tx).Create(ctx, rec); err != nil {
+					return err
+				}
+				return historialRepo.WithTx(tx).Create(ctx, &model.Historial{
+					TenantModel: model.TenantModel{TenantID: tenantID},
+					ReclamoID:   rec.ID,
+					EstadoNuevo: model.EstadoPendiente,
+					TipoAccion:  model.AccionCreacion,
+				})
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("registro concurrente: %v", err)
+		}
+	}
+
+	integridadRepo := repo.NewIntegridadRepo(testDB)
+	reclamos, err := integridadRepo.VerificarReclamos(ctx, tenantID)
+	if err != nil || !reclamos.Integra || reclamos.Eslabones != n {
+		t.Errorf("cadena de reclamos = %+v, %v, want íntegra con %d eslabones", reclamos, err, n)
+	}
+	historial, err := integridadRepo.VerificarHistorial(ctx, tenantID)
+	if err != nil || !historial.Integra || historial.Eslabones != n {
+		t.Errorf("cadena de historial = %+v, %v, want íntegra con %d eslabones", historial, err, n)
+	}
+}
+
+func nuevoReclamoCadenaTest(tenantID uuid.UUID, i int) *model.Reclamo {
+	return &model.Reclamo{
+		TenantModel:      model.TenantModel{TenantID: tenantID},
+		CodigoReclamo:    "TEST-CADENA-" + uuid.NewString()[:8],
+		TipoSolicitud:    model.TipoReclamo,
+		Estado:           model.EstadoPendiente,
+		NombreCompleto:   "Consumidor Prueba",
+		TipoDocumento:    model.DocDNI,
+		NumeroDocumento:  "1234567" + string(rune('0'+i)),
+		Telefono:         "999888777",
+		Email:            "prueba@example.com",
+		DescripcionBien:  "Producto de prueba",
+		FechaIncidente:   time.Date(2031, time.January, 10, 0, 0, 0, 0, time.UTC),
+		DetalleReclamo:   "Detalle de prueba",
+		PedidoConsumidor: "Pedido de prueba",
+		AceptaTerminos:   true,
+		AceptaCopia:      true,
+		CanalOrigen:      model.CanalWeb,
+	}
+}
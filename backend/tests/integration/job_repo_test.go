@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Las rutas de jobs son de toda la plataforma: el ADMIN de un cliente no entra.
+func TestOperadorPlataformaMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	operador, cliente := uuid.New(), uuid.New()
+
+	casos := []struct {
+		nombre     string
+		operadores []uuid.UUID
+		tenantID   uuid.UUID
+		rol        string
+		want       int
+	}{
+		{"admin del operador", []uuid.UUID{operador}, operador, model.RolAdmin, http.StatusOK},
+		{"soporte del operador", []uuid.UUID{operador}, operador, model.RolSoporte, http.StatusForbidden},
+		{"admin de un cliente", []uuid.UUID{operador}, cliente, model.RolAdmin, http.StatusForbidden},
+		{"sin operadores configurados", nil, operador, model.RolAdmin, http.StatusForbidden},
+	}
+	for _, c := range casos {
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			helper.SetContext(ctx, helper.CtxTenantID, c.tenantID)
+			helper.SetContext(ctx, helper.CtxUserRole, c.rol)
+		})
+		r.GET("/jobs", middleware.OperadorPlataformaMiddleware(c.operadores), func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/jobs", nil))
+		if w.Code != c.want {
+			t.Errorf("%s: status = %d, want %d", c.nombre, w.Code, c.want)
+		}
+	}
+}
+
+func TestJobRepo_BloqueoUnaReplicaPorSlot(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	jobRepo := repo.NewJobRepo(testDB)
+	ctx := context.Background()
+	nombre := "test_job_" + uuid.NewString()[:8]
+	slot := time.Now().Truncate(time.Minute)
+
+	ok, err := jobRepo.IntentarBloqueo(ctx, nombre, "replica-a", slot, time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("replica-a debería tomar el lock: ok=%v err=%v", ok, err)
+	}
+
+	ok, err = jobRepo.IntentarBloqueo(ctx, nombre, "replica-b", slot, time.Minute)
+	if err != nil {
+		t.Fatalf("IntentarBloqueo: %v", err)
+	}
+	if ok {
+		t.Error("replica-b no debería tomar un lock ocupado")
+	}
+
+	if err := jobRepo.LiberarBloqueo(ctx, nombre, "replica-a"); err != nil {
+		t.Fatalf("LiberarBloqueo: %v", err)
+	}
+
+	// Mismo slot ya ejecutado: nadie lo repite aunque el lock esté libre
+	ok, _ = jobRepo.IntentarBloqueo(ctx, nombre, "replica-b", slot, time.Minute)
+	if ok {
+		t.Error("el slot ya ejecutado no debería repetirse")
+	}
+
+	ok, err = jobRepo.IntentarBloqueo(ctx, nombre, "replica-b", slot.Add(time.Minute), time.Minute)
+	if err != nil || !ok {
+		t.Errorf("replica-b debería tomar el siguiente slot: ok=%v err=%v", ok, err)
+	}
+}
@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/google/uuid"
+)
+
+func TestMensajePlantilla_Payload(t *testing.T) {
+	variables := []string{model.VarPlantillaNombreCliente, model.VarPlantillaCodigo, model.VarPlantillaEstado, model.VarPlantillaFechaLimite, model.VarPlantillaEmpresa, model.VarPlantillaMensaje}
+	tenant := &model.Tenant{RazonSocial: "Comercial Andina S.A.C."}
+	p := model.PayloadNotificacion{
+		Codigo:        "REC-2026-000123",
+		NombreCliente: "Ana Pérez",
+		Estado:        model.EstadoEnProceso,
+		FechaLimite:   "30/10/2026",
+		Texto:         "Hola Ana:\n\n\tRevisamos tu pedido     y lo reenviamos hoy.",
+	}
+
+	parametros := service.ParametrosPlantillaWhatsApp(variables, tenant, p)
+	want := []string{"Ana Pérez", "REC-2026-000123", "EN PROCESO", "30/10/2026", "Comercial Andina S.A.C.", p.Texto}
+	if strings.Join(parametros, "|") != strings.Join(want, "|") {
+		t.Fatalf("parametros = %q, want %q", parametros, want)
+	}
+
+	// Sin fecha límite el parámetro no puede ir vacío: Meta rechaza el mensaje
+	parametros[3] = ""
+	msg := service.MensajePlantilla{Nombre: "estado_reclamo", Idioma: "es_PE", Parametros: parametros}
+	data, _ := json.Marshal(msg.Payload("51987654321"))
+	var payload struct {
+		To       string `json:"to"`
+		Type     string `json:"type"`
+		Template struct {
+			Name     string `json:"name"`
+			Language struct {
+				Code string `json:"code"`
+			} `json:"language"`
+			Components []struct {
+				Type       string `json:"type"`
+				Parameters []struct{ Type, Text string }
+			} `json:"components"`
+		} `json:"template"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	tpl := payload.Template
+	if payload.To != "51987654321" || payload.Type != "template" || tpl.Name != "estado_reclamo" || tpl.Language.Code != "es_PE" {
+		t.Fatalf("payload = %s", data)
+	}
+	if len(tpl.Components) != 1 || tpl.Components[0].Type != "body" || len(tpl.Components[0].Parameters) != len(variables) {
+		t.Fatalf("components = %+v", tpl.Components)
+	}
+	params := tpl.Components[0].Parameters
+	if params[3].Text != "-" {
+		t.Errorf("parámetro vacío = %q, want -", params[3].Text)
+	}
+	// Sin saltos de línea, tabulaciones ni espacios repetidos
+	if got := params[5].Text; got != "Hola Ana: Revisamos tu pedido y lo reenviamos hoy." || params[5].Type != "text" {
+		t.Errorf("mensaje = %q", got)
+	}
+
+	// Plantilla sin variables: no lleva components
+	data, _ = json.Marshal(service.MensajePlantilla{Nombre: "reclamo_resuelto", Idioma: "es"}.Payload("51987654321"))
+	if strings.Contains(string(data), "components") {
+		t.Errorf("payload sin variables = %s", data)
+	}
+}
+
+func TestOutboxRepo_EstadoEntregaWhatsApp(t *testing.T) {
+	for status, want := range map[string]string{
+		"sent": model.EntregaEnviado, "delivered": model.EntregaEntregado,
+		"read": model.EntregaLeido, "failed": model.EntregaFallido, "deleted": "",
+	} {
+		if got := service.EstadoEntregaMeta(status); got != want {
+			t.Errorf("EstadoEntregaMeta(%q) = %q, want %q", status, got, want)
+		}
+	}
+
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	outboxRepo := repo.NewOutboxRepo(testDB)
+	ctx := context.Background()
+	tenantID, reclamoID := uuid.New(), uuid.New()
+
+	n := nuevaNotificacionTest(tenantID, reclamoID)
+	n.Canal, n.Destinatario = model.CanalNotifWhatsApp, "51987654321"
+	if err := outboxRepo.Encolar(ctx, &n); err != nil {
+		t.Fatalf("Encolar: %v", err)
+	}
+	if err := outboxRepo.MarcarEnviada(ctx, tenantID, n.ID); err != nil {
+		t.Fatalf("MarcarEnviada: %v", err)
+	}
+	if err := outboxRepo.RegistrarMensajeExterno(ctx, tenantID, n.ID, "wamid.NOTIF1"); err != nil {
+		t.Fatalf("RegistrarMensajeExterno: %v", err)
+	}
+
+	// Meta no garantiza el orden: read llega antes que delivered
+	entregado, leido := time.Now().Add(-time.Minute).Truncate(time.Second), time.Now().Truncate(time.Second)
+	for _, e := range []struct {
+		estado string
+		fecha  time.Time
+	}{{model.EntregaLeido, leido}, {model.EntregaEntregado, entregado}, {model.EntregaEnviado, entregado}} {
+		if ok, err := outboxRepo.ActualizarEntrega(ctx, tenantID, "wamid.NOTIF1", e.estado, e.fecha, ""); err != nil || !ok {
+			t.Fatalf("ActualizarEntrega(%s) = %v, %v", e.estado, ok, err)
+		}
+	}
+	items, _ := outboxRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+	if len(items) != 1 || items[0].EstadoEntrega.String != model.EntregaLeido || !items[0].FechaLectura.Time.Equal(leido) || !items[0].FechaEntrega.Valid {
+		t.Fatalf("tras read+delivered esperaba LEIDO con fechas, got %+v", items)
+	}
+
+	// Mensajes que no salieron del outbox (respuestas del bot) no se encuentran
+	if ok, _ := outboxRepo.ActualizarEntrega(ctx, tenantID, "wamid.BOT", model.EntregaEntregado, leido, ""); ok {
+		t.Error("un wamid desconocido no debería actualizar nada")
+	}
+
+	// Una notificación que Meta no pudo entregar se puede reenviar
+	if ok, _ := outboxRepo.Reintentar(ctx, tenantID, n.ID); ok {
+		t.Error("una notificación leída no debería reintentarse")
+	}
+	if _, err := outboxRepo.ActualizarEntrega(ctx, tenantID, "wamid.NOTIF1", model.EntregaFallido, leido, "131026: Message undeliverable"); err != nil {
+		t.Fatalf("ActualizarEntrega(FALLIDO): %v", err)
+	}
+	items, _ = outboxRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+	if items[0].EstadoEntrega.String != model.EntregaFallido || items[0].ErrorEntrega.String != "131026: Message undeliverable" {
+		t.Errorf("esperaba FALLIDO con el error de Meta, got %+v", items[0])
+	}
+	if ok, err := outboxRepo.Reintentar(ctx, tenantID, n.ID); err != nil || !ok {
+		t.Errorf("Reintentar entrega fallida: ok=%v err=%v", ok, err)
+	}
+}
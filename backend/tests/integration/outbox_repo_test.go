@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestOutboxRepo_RollbackNoEncola(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	outboxRepo := repo.NewOutboxRepo(testDB)
+	transactor := repo.NewTransactor(testDB)
+	ctx := context.Background()
+	tenantID, reclamoID := uuid.New(), uuid.New()
+
+	errAbortar := errors.New("abortar")
+	err := transactor.EnTransaccion(ctx, func(tx *sql.Tx) error {
+		n := nuevaNotificacionTest(tenantID, reclamoID)
+		if err := outboxRepo.WithTx(tx).Encolar(ctx, &n); err != nil {
+			return err
+		}
+		return errAbortar
+	})
+	if !errors.Is(err, errAbortar) {
+		t.Fatalf("esperaba errAbortar, got %v", err)
+	}
+
+	items, err := outboxRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+	if err != nil {
+		t.Fatalf("ListarPorReclamo: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("rollback no debería dejar notificaciones, got %d", len(items))
+	}
+}
+
+func TestOutboxRepo_DeadLetterYReintento(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	outboxRepo := repo.NewOutboxRepo(testDB)
+	ctx := context.Background()
+	tenantID, reclamoID := uuid.New(), uuid.New()
+
+	n := nuevaNotificacionTest(tenantID, reclamoID)
+	if err := outboxRepo.Encolar(ctx, &n); err != nil {
+		t.Fatalf("Encolar: %v", err)
+	}
+	if n.Estado != model.OutboxPendiente {
+		t.Fatalf("estado inicial = %s, want PENDIENTE", n.Estado)
+	}
+
+	var estado string
+	for i := 0; i < n.MaxIntentos; i++ {
+		var err error
+		estado, err = outboxRepo.MarcarError(ctx, tenantID, n.ID, "smtp caído", time.Now())
+		if err != nil {
+			t.Fatalf("MarcarError: %v", err)
+		}
+	}
+	if estado != model.OutboxFallida {
+		t.Fatalf("tras %d intentos estado = %s, want FALLIDA", n.MaxIntentos, estado)
+	}
+
+	ok, err := outboxRepo.Reintentar(ctx, tenantID, n.ID)
+	if err != nil || !ok {
+		t.Fatalf("Reintentar: ok=%v err=%v", ok, err)
+	}
+
+	items, _ := outboxRepo.ListarPorReclamo(ctx, tenantID, reclamoID)
+	if len(items) != 1 || items[0].Estado != model.OutboxPendiente || items[0].Intentos != 0 {
+		t.Errorf("tras reintentar esperaba PENDIENTE con 0 intentos, got %+v", items)
+	}
+
+	// Solo se reintenta lo que está en dead-letter
+	ok, _ = outboxRepo.Reintentar(ctx, tenantID, n.ID)
+	if ok {
+		t.Error("una notificación PENDIENTE no debería reintentarse")
+	}
+}
+
+func nuevaNotificacionTest(tenantID, reclamoID uuid.UUID) model.NotificacionOutbox {
+	return model.NotificacionOutbox{
+		TenantModel:  model.TenantModel{TenantID: tenantID},
+		ReclamoID:    model.NullUUID{UUID: reclamoID, Valid: true},
+		Canal:        model.CanalNotifEmail,
+		Tipo:         model.NotifCambioEstado,
+		Destinatario: "cliente@test.pe",
+		Payload:      []byte(`{"codigo":"TEST-001"}`),
+	}
+}
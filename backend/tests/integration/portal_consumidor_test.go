@@ -0,0 +1,222 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/middleware"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestVerificarAccesoConsumidor(t *testing.T) {
+	tenantID := uuid.New()
+	rec := &model.Reclamo{Email: " Ana.Perez@Example.com", Telefono: "+51 987 654 321"}
+	rec.TenantID = tenantID
+
+	casos := []struct {
+		nombre string
+		id     *model.IdentidadConsumidor
+		want   error
+	}{
+		{"sin token", nil, apperror.ErrAccesoConsumidorRequerido},
+		{"email", &model.IdentidadConsumidor{TenantID: tenantID, Canal: model.CanalAccesoEmail, Destino: "ana.perez@example.com"}, nil},
+		{"teléfono", &model.IdentidadConsumidor{TenantID: tenantID, Canal: model.CanalAccesoTelefono, Destino: "987654321"}, nil},
+		{"otro consumidor", &model.IdentidadConsumidor{TenantID: tenantID, Canal: model.CanalAccesoEmail, Destino: "otro@example.com"}, apperror.ErrNotFound},
+		{"teléfono como email", &model.IdentidadConsumidor{TenantID: tenantID, Canal: model.CanalAccesoEmail, Destino: "987654321"}, apperror.ErrNotFound},
+		{"otro tenant", &model.IdentidadConsumidor{TenantID: uuid.New(), Canal: model.CanalAccesoEmail, Destino: "ana.perez@example.com"}, apperror.ErrNotFound},
+	}
+	for _, c := range casos {
+		if got := service.VerificarAccesoConsumidor(c.id, rec); !errors.Is(got, c.want) {
+			t.Errorf("%s: VerificarAccesoConsumidor = %v, want %v", c.nombre, got, c.want)
+		}
+	}
+}
+
+func TestNumeroWhatsApp(t *testing.T) {
+	casos := map[string]string{
+		"987 654 321":       "51987654321",
+		"+51 987-654-321":   "51987654321",
+		"51987654321":       "51987654321",
+		"+34 612 345 678":   "34612345678",
+		"0034 612 345 678":  "34612345678",
+		"(01) 234-5678":     "", // fijo de Lima
+		"+51 1 234 5678":    "", // fijo con código de país
+		"612 345 678":       "", // extranjero sin código: 51 lo haría de otra persona
+		"2345678":           "",
+		"+1 (555) 010-9999": "15550109999",
+		"987654321 anexo 2": "",
+		"":                  "",
+	}
+	for telefono, want := range casos {
+		if got := service.NumeroWhatsApp(telefono); got != want {
+			t.Errorf("NumeroWhatsApp(%q) = %q, want %q", telefono, got, want)
+		}
+	}
+
+	// El portal solo acepta teléfonos a los que puede enviar el código
+	portal := service.NewPortalConsumidorService(nil, nil, nil, nil, config.JWTConfig{})
+	if id, err := portal.Identidad(uuid.New(), "", "+51 987 654 321"); err != nil || id.Destino != "987654321" {
+		t.Errorf("Identidad(celular) = %+v, %v", id, err)
+	}
+	for _, telefono := range []string{"612 345 678", "+34 612 345 678", "(01) 234-5678"} {
+		if _, err := portal.Identidad(uuid.New(), "", telefono); err != apperror.ErrContactoInvalido {
+			t.Errorf("Identidad(%q) = %v, want ErrContactoInvalido", telefono, err)
+		}
+	}
+}
+
+func TestConsumidorToken_NoSeMezclaConElPanel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtCfg := config.JWTConfig{Secret: "secreto-test", ExpirationHours: 1}
+	id := model.IdentidadConsumidor{TenantID: uuid.New(), Canal: model.CanalAccesoEmail, Destino: "ana@example.com"}
+
+	consumidor, err := middleware.GenerateConsumidorToken(id, time.Hour, jwtCfg)
+	if err != nil {
+		t.Fatalf("GenerateConsumidorToken: %v", err)
+	}
+	panel, err := middleware.GenerateToken(id.TenantID, uuid.New(), "ADMIN", nil, jwtCfg)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/portal", middleware.ConsumidorAuthMiddleware(jwtCfg), func(c *gin.Context) {
+		got, _ := c.Get(helper.CtxConsumidor)
+		if got != id {
+			t.Errorf("identidad = %+v, want %+v", got, id)
+		}
+		c.Status(http.StatusOK)
+	})
+	r.GET("/panel", middleware.AuthMiddleware(jwtCfg), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	casos := []struct {
+		ruta, token string
+		want        int
+	}{
+		{"/portal", consumidor, http.StatusOK},
+		{"/portal", panel, http.StatusUnauthorized},
+		{"/portal", "", http.StatusUnauthorized},
+		{"/panel", consumidor, http.StatusUnauthorized},
+		{"/panel", panel, http.StatusOK},
+	}
+	for i, c := range casos {
+		req := httptest.NewRequest(http.MethodGet, c.ruta, nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != c.want {
+			t.Errorf("caso %d: %s = %d, want %d", i, c.ruta, w.Code, c.want)
+		}
+	}
+}
+
+func TestCodigoAccesoRepo(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	codigoRepo := repo.NewCodigoAccesoRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM codigos_acceso_consumidor WHERE tenant_id = $1`, tenantID)
+
+	nuevo := func() *model.CodigoAccesoConsumidor {
+		c := &model.CodigoAccesoConsumidor{
+			TenantModel:      model.TenantModel{TenantID: tenantID, ID: uuid.New()},
+			Canal:            model.CanalAccesoEmail,
+			Destino:          "ana@example.com",
+			CodigoHash:       uuid.NewString(),
+			IPAddress:        model.NullString{NullString: sql.NullString{String: "10.0.0.1", Valid: true}},
+			FechaVencimiento: time.Now().Add(10 * time.Minute),
+		}
+		if err := codigoRepo.Create(ctx, c); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return c
+	}
+
+	primero := nuevo()
+	segundo := nuevo()
+
+	// Pedir otro código invalida el anterior
+	vigente, err := codigoRepo.ReservarIntento(ctx, tenantID, model.CanalAccesoEmail, "ana@example.com", 5)
+	if err != nil || vigente == nil || vigente.ID != segundo.ID || vigente.Intentos != 1 {
+		t.Fatalf("ReservarIntento = %+v, %v, want %s con 1 intento", vigente, err, segundo.ID)
+	}
+	if ok, _ := codigoRepo.Consumir(ctx, tenantID, primero.ID, 5); ok {
+		t.Error("Consumir(invalidado) = true")
+	}
+
+	porDestino, porIP, err := codigoRepo.ContarRecientes(ctx, tenantID, model.CanalAccesoEmail, "ana@example.com", "10.0.0.1", time.Now().Add(-time.Minute))
+	if err != nil || porDestino != 2 || porIP != 2 {
+		t.Errorf("ContarRecientes = %d, %d, %v, want 2, 2", porDestino, porIP, err)
+	}
+
+	// Un solo uso
+	if ok, err := codigoRepo.Consumir(ctx, tenantID, segundo.ID, 5); err != nil || !ok {
+		t.Fatalf("Consumir = %v, %v", ok, err)
+	}
+	if ok, _ := codigoRepo.Consumir(ctx, tenantID, segundo.ID, 5); ok {
+		t.Error("Consumir dos veces = true")
+	}
+
+	// Intentos en paralelo: nunca se reservan más de 5
+	tercero := nuevo()
+	var (
+		wg        sync.WaitGroup
+		reservas  atomic.Int32
+		erroresDB atomic.Int32
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := codigoRepo.ReservarIntento(ctx, tenantID, model.CanalAccesoEmail, "ana@example.com", 5)
+			if err != nil {
+				erroresDB.Add(1)
+			} else if c != nil {
+				reservas.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if reservas.Load() > 5 {
+		t.Errorf("se reservaron %d intentos en paralelo, máximo 5 (errores de BD: %d)", reservas.Load(), erroresDB.Load())
+	}
+	if vigente, _ := codigoRepo.ReservarIntento(ctx, tenantID, model.CanalAccesoEmail, "ana@example.com", 5); vigente != nil {
+		t.Errorf("ReservarIntento con intentos agotados = %+v, want nil", vigente)
+	}
+	if ok, _ := codigoRepo.Consumir(ctx, tenantID, tercero.ID, 4); ok {
+		t.Error("Consumir con más intentos que el máximo = true")
+	}
+
+	// Solicitud sin reclamos: cuenta para el límite pero nunca es verificable
+	ip := model.NullString{NullString: sql.NullString{String: "10.0.0.1", Valid: true}}
+	if err := codigoRepo.RegistrarSolicitud(ctx, tenantID, model.CanalAccesoEmail, "nadie@example.com", ip); err != nil {
+		t.Fatalf("RegistrarSolicitud: %v", err)
+	}
+	porDestino, porIP, _ = codigoRepo.ContarRecientes(ctx, tenantID, model.CanalAccesoEmail, "nadie@example.com", "10.0.0.1", time.Now().Add(-time.Minute))
+	if porDestino != 1 || porIP != 4 {
+		t.Errorf("ContarRecientes tras solicitud sin reclamos = %d, %d, want 1, 4", porDestino, porIP)
+	}
+	if vigente, _ := codigoRepo.ReservarIntento(ctx, tenantID, model.CanalAccesoEmail, "nadie@example.com", 5); vigente != nil {
+		t.Errorf("una solicitud sin reclamos no debe dejar un código vigente: %+v", vigente)
+	}
+}
@@ -0,0 +1,111 @@
+package integration
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestReclamoRepo_Buscar(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+
+	for i := 0; i < 3; i++ {
+		rec := nuevoReclamoCadenaTest(tenantID, i)
+		if i == 0 {
+			rec.NombreCompleto = "Rosa Quispe Mamani"
+			rec.DetalleReclamo = "La refrigeradora llegó con la puerta abollada"
+		}
+		if i == 2 {
+			rec.TipoSolicitud = model.TipoQueja
+		}
+		if err := reclamoRepo.Create(ctx, rec); err != nil {
+			t.Fatalf("Create #%d: %v", i, err)
+		}
+	}
+
+	// Texto completo sobre el relato
+	res, err := reclamoRepo.Buscar(ctx, repo.FiltrosBusqueda{TenantID: tenantID, Texto: "refrigeradora", Limite: 10})
+	if err != nil {
+		t.Fatalf("Buscar texto: %v", err)
+	}
+	if len(res.Data) != 1 || res.Data[0].NombreCompleto != "Rosa Quispe Mamani" {
+		t.Fatalf("texto: got %d resultados", len(res.Data))
+	}
+
+	// Coincidencia parcial en el nombre
+	res, err = reclamoRepo.Buscar(ctx, repo.FiltrosBusqueda{TenantID: tenantID, Texto: "quisp", Limite: 10})
+	if err != nil {
+		t.Fatalf("Buscar nombre: %v", err)
+	}
+	if len(res.Data) != 1 {
+		t.Fatalf("nombre: got %d resultados, want 1", len(res.Data))
+	}
+
+	// Faceta tipo_solicitud: el filtro no reduce su propia faceta
+	res, err = reclamoRepo.Buscar(ctx, repo.FiltrosBusqueda{
+		TenantID: tenantID, TiposSolicitud: []string{model.TipoQueja}, Limite: 10,
+	})
+	if err != nil {
+		t.Fatalf("Buscar faceta: %v", err)
+	}
+	if len(res.Data) != 1 {
+		t.Fatalf("faceta: got %d resultados, want 1", len(res.Data))
+	}
+	if n := len(res.Facetas[model.FacetaTipoSolicitud]); n != 2 {
+		t.Errorf("faceta tipo_solicitud con %d valores, want 2", n)
+	}
+
+	// Sede forzada (SOPORTE): tampoco se ven otras sedes en la faceta de sede
+	otraSede := uuid.New()
+	res, err = reclamoRepo.Buscar(ctx, repo.FiltrosBusqueda{TenantID: tenantID, SedeForzada: &otraSede, Limite: 10})
+	if err != nil {
+		t.Fatalf("Buscar sede forzada: %v", err)
+	}
+	if len(res.Data) != 0 || len(res.Facetas[model.FacetaSede]) != 0 {
+		t.Errorf("sede forzada: %d resultados, faceta sede %+v", len(res.Data), res.Facetas[model.FacetaSede])
+	}
+
+	// Paginación por cursor: 2 + 1 sin repetir
+	vistos := map[uuid.UUID]bool{}
+	filtros := repo.FiltrosBusqueda{TenantID: tenantID, Orden: repo.OrdenCodigo, Asc: true, Limite: 2}
+	for pagina := 0; pagina < 3; pagina++ {
+		res, err = reclamoRepo.Buscar(ctx, filtros)
+		if err != nil {
+			t.Fatalf("Buscar página %d: %v", pagina, err)
+		}
+		for _, rec := range res.Data {
+			if vistos[rec.ID] {
+				t.Fatalf("reclamo %s repetido entre páginas", rec.ID)
+			}
+			vistos[rec.ID] = true
+		}
+		if res.SiguienteCursor == "" {
+			break
+		}
+		filtros.Cursor = res.SiguienteCursor
+	}
+	if len(vistos) != 3 {
+		t.Errorf("paginación recorrió %d reclamos, want 3", len(vistos))
+	}
+
+	if _, err := reclamoRepo.Buscar(ctx, repo.FiltrosBusqueda{TenantID: tenantID, Cursor: "xx", Limite: 2}); err != repo.ErrCursorInvalido {
+		t.Errorf("cursor inválido: err = %v, want ErrCursorInvalido", err)
+	}
+	// Cursor bien formado pero con una fecha alterada: no debe llegar a la BD
+	alterado := base64.RawURLEncoding.EncodeToString([]byte(`{"o":"fecha_registro","v":"ayer","id":"` + uuid.NewString() + `"}`))
+	if _, err := reclamoRepo.Buscar(ctx, repo.FiltrosBusqueda{TenantID: tenantID, Cursor: alterado, Limite: 2}); err != repo.ErrCursorInvalido {
+		t.Errorf("cursor con fecha alterada: err = %v, want ErrCursorInvalido", err)
+	}
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// TestReclamoRepo_ColaClasificacion ciclo del job: solo los reclamos
+// PENDIENTE entran en la cola, cada toma cuenta un intento y el resultado o
+// el agotamiento los saca de ella.
+func TestReclamoRepo_ColaClasificacion(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+
+	pendiente := model.NullString{NullString: sql.NullString{String: model.ClasificacionPendiente, Valid: true}}
+	reclamos := make([]*model.Reclamo, 3)
+	for i := range reclamos {
+		rec := nuevoReclamoCadenaTest(tenantID, i)
+		if i < 2 {
+			rec.ClasificacionEstado = pendiente
+		}
+		if err := reclamoRepo.Create(ctx, rec); err != nil {
+			t.Fatalf("Create #%d: %v", i, err)
+		}
+		reclamos[i] = rec
+	}
+
+	// La cola es cross-tenant: se filtran los del tenant del test
+	tomados := make(map[uuid.UUID]model.ReclamoPorClasificar)
+	lote, err := reclamoRepo.ReclamarParaClasificar(ctx, 500, time.Minute)
+	if err != nil {
+		t.Fatalf("ReclamarParaClasificar: %v", err)
+	}
+	for _, rc := range lote {
+		if rc.TenantID == tenantID {
+			tomados[rc.ID] = rc
+		}
+	}
+	if len(tomados) != 2 {
+		t.Fatalf("tomados = %d, want 2 (el reclamo sin estado no entra)", len(tomados))
+	}
+	if rc := tomados[reclamos[0].ID]; rc.Intentos != 1 || rc.DetalleReclamo != reclamos[0].DetalleReclamo {
+		t.Errorf("reclamado = %+v, want intento 1 con el relato", rc)
+	}
+
+	// Con el lease vigente no se vuelven a tomar
+	lote, err = reclamoRepo.ReclamarParaClasificar(ctx, 500, time.Minute)
+	if err != nil {
+		t.Fatalf("ReclamarParaClasificar: %v", err)
+	}
+	for _, rc := range lote {
+		if rc.TenantID == tenantID {
+			t.Fatalf("%s tomado de nuevo durante el lease", rc.CodigoReclamo)
+		}
+	}
+
+	res := model.ResultadoClasificacion{
+		Sentimiento: model.SentimientoNegativo,
+		Urgencia:    model.UrgenciaAlta,
+		Resumen:     "Producto entregado dañado; pide cambio.",
+	}
+	if err := reclamoRepo.GuardarClasificacion(ctx, tenantID, reclamos[0].ID, res); err != nil {
+		t.Fatalf("GuardarClasificacion: %v", err)
+	}
+	if err := reclamoRepo.PosponerClasificacion(ctx, tenantID, reclamos[1].ID, time.Now(), true); err != nil {
+		t.Fatalf("PosponerClasificacion: %v", err)
+	}
+
+	clasificado, err := reclamoRepo.GetByID(ctx, tenantID, reclamos[0].ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if clasificado.ClasificacionEstado.String != model.ClasificacionClasificado ||
+		clasificado.Urgencia.String != model.UrgenciaAlta || clasificado.Categoria.Valid ||
+		!clasificado.FechaClasificacion.Valid {
+		t.Errorf("clasificado = %+v", clasificado)
+	}
+	fallido, err := reclamoRepo.GetByID(ctx, tenantID, reclamos[1].ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if fallido.ClasificacionEstado.String != model.ClasificacionFallido {
+		t.Errorf("estado = %q, want %s", fallido.ClasificacionEstado.String, model.ClasificacionFallido)
+	}
+
+	// Reclasificar lo devuelve a la cola con los intentos reiniciados
+	ok, err := reclamoRepo.EncolarClasificacion(ctx, tenantID, reclamos[1].ID)
+	if err != nil || !ok {
+		t.Fatalf("EncolarClasificacion = %v, %v", ok, err)
+	}
+	lote, err = reclamoRepo.ReclamarParaClasificar(ctx, 500, time.Minute)
+	if err != nil {
+		t.Fatalf("ReclamarParaClasificar: %v", err)
+	}
+	var retomado *model.ReclamoPorClasificar
+	for i := range lote {
+		if lote[i].ID == reclamos[1].ID {
+			retomado = &lote[i]
+		}
+	}
+	if retomado == nil || retomado.Intentos != 1 {
+		t.Errorf("retomado = %+v, want intento 1", retomado)
+	}
+	if ok, _ := reclamoRepo.EncolarClasificacion(ctx, tenantID, uuid.New()); ok {
+		t.Error("EncolarClasificacion de un reclamo inexistente = true")
+	}
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/apperror"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestReclamoRepo_CierreYReapertura(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+
+	rec := nuevoReclamoCadenaTest(tenantID, 0)
+	if err := reclamoRepo.Create(ctx, rec); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Reabrir un reclamo pendiente no hace nada
+	if err := reclamoRepo.Reabrir(ctx, tenantID, rec.ID, time.Now()); err != apperror.ErrNotFound {
+		t.Fatalf("Reabrir PENDIENTE: err = %v, want ErrNotFound", err)
+	}
+
+	if err := reclamoRepo.UpdateEstado(ctx, tenantID, rec.ID, model.EstadoPendiente, model.EstadoResuelto, nil); err != nil {
+		t.Fatalf("UpdateEstado: %v", err)
+	}
+	// Otro cambio desde PENDIENTE llega tarde: ya no se aplica
+	var appErr *apperror.AppError
+	if err := reclamoRepo.UpdateEstado(ctx, tenantID, rec.ID, model.EstadoPendiente, model.EstadoRechazado, nil); !errors.As(err, &appErr) || appErr.Code != apperror.ErrTransicionEstado.Code {
+		t.Fatalf("UpdateEstado desde un estado vencido: err = %v, want ErrTransicionEstado", err)
+	}
+	got, err := reclamoRepo.GetByID(ctx, tenantID, rec.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !got.FechaCierre.Valid {
+		t.Fatal("fecha_cierre no se fijó al resolver")
+	}
+
+	nuevoPlazo := time.Now().AddDate(0, 0, 15)
+	if err := reclamoRepo.Reabrir(ctx, tenantID, rec.ID, nuevoPlazo); err != nil {
+		t.Fatalf("Reabrir: %v", err)
+	}
+	got, err = reclamoRepo.GetByID(ctx, tenantID, rec.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Estado != model.EstadoEnProceso || got.FechaCierre.Valid {
+		t.Errorf("después de reabrir: estado = %s, fecha_cierre válida = %v", got.Estado, got.FechaCierre.Valid)
+	}
+	if got.FechaLimiteRespuesta.Time.Format("2006-01-02") != nuevoPlazo.Format("2006-01-02") {
+		t.Errorf("fecha_limite = %s, want %s", got.FechaLimiteRespuesta.Time.Format("2006-01-02"), nuevoPlazo.Format("2006-01-02"))
+	}
+}
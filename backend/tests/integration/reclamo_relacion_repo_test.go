@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestEsDuplicadoProbable(t *testing.T) {
+	casos := []struct {
+		motivos []string
+		want    bool
+	}{
+		{[]string{model.CoincideDocumento, model.CoincideTexto}, true},
+		{[]string{model.CoincideTelefono, model.CoincideTexto}, true},
+		{[]string{model.CoincidePedido, model.CoincideEmail}, true},
+		{[]string{model.CoincidePedido, model.CoincideTexto}, true},
+		{[]string{model.CoincideDocumento, model.CoincideEmail, model.CoincideTelefono}, false}, // mismo consumidor, otro caso
+		{[]string{model.CoincidePedido}, false},
+		{[]string{model.CoincideTexto}, false},
+	}
+	for _, c := range casos {
+		if got := model.EsDuplicadoProbable(c.motivos); got != c.want {
+			t.Errorf("EsDuplicadoProbable(%v) = %v, want %v", c.motivos, got, c.want)
+		}
+	}
+
+	todos := []string{model.CoincideDocumento, model.CoincideEmail, model.CoincideTelefono, model.CoincidePedido, model.CoincideTexto}
+	if got := model.PuntajeDuplicado(todos); got != 100 {
+		t.Errorf("PuntajeDuplicado(todos) = %d, want tope 100", got)
+	}
+}
+
+// Solo se fusionan reclamos abiertos, y el cierre del duplicado no es una
+// transición que CambiarEstado permita.
+func TestPuedeFusionarse(t *testing.T) {
+	casos := map[string]bool{
+		model.EstadoPendiente: true,
+		model.EstadoEnProceso: true,
+		model.EstadoResuelto:  false,
+		model.EstadoRechazado: false,
+		model.EstadoCerrado:   false,
+	}
+	for estado, want := range casos {
+		if got := model.PuedeFusionarse(estado); got != want {
+			t.Errorf("PuedeFusionarse(%s) = %v, want %v", estado, got, want)
+		}
+	}
+	if model.TransicionValida(model.EstadoPendiente, model.EstadoCerrado) {
+		t.Error("PENDIENTE → CERRADO no debe ser un cambio de estado")
+	}
+}
+
+func TestReclamoRelacionRepo_CoincidentesYResolver(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	relacionRepo := repo.NewReclamoRelacionRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	userID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+
+	// 0: el nuevo; 1: mismo DNI; 2: mismo teléfono con otro formato y pedido;
+	// 3: otro consumidor
+	reclamos := make([]*model.Reclamo, 4)
+	for i := range reclamos {
+		rec := nuevoReclamoCadenaTest(tenantID, i)
+		rec.Email = uuid.NewString()[:8] + "@example.com"
+		rec.Telefono = "9" + uuid.NewString()[:8]
+		reclamos[i] = rec
+	}
+	reclamos[1].NumeroDocumento = reclamos[0].NumeroDocumento
+	reclamos[0].Telefono = "987654321"
+	reclamos[2].Telefono = "+51 987 654 321"
+	reclamos[0].NumeroPedido = model.NullString{NullString: sql.NullString{String: "PED-77", Valid: true}}
+	reclamos[2].NumeroPedido = model.NullString{NullString: sql.NullString{String: "ped-77", Valid: true}}
+	for i := len(reclamos) - 1; i >= 0; i-- {
+		if err := reclamoRepo.Create(ctx, reclamos[i]); err != nil {
+			t.Fatalf("Create #%d: %v", i, err)
+		}
+	}
+
+	criterios := repo.CriteriosCoincidencia{
+		TipoDocumento:   reclamos[0].TipoDocumento,
+		NumeroDocumento: reclamos[0].NumeroDocumento,
+		Email:           reclamos[0].Email,
+		Telefono:        "987654321",
+		NumeroPedido:    "ped-77",
+	}
+	desde := time.Now().Add(-time.Hour)
+	coincidentes, err := relacionRepo.Coincidentes(ctx, tenantID, reclamos[0].ID, criterios, &desde, nil, 10)
+	if err != nil {
+		t.Fatalf("Coincidentes: %v", err)
+	}
+	motivos := make(map[uuid.UUID][]string)
+	for _, c := range coincidentes {
+		motivos[c.ID] = c.Coincidencias
+	}
+	if len(motivos) != 2 {
+		t.Fatalf("coincidentes = %+v, want #1 y #2", coincidentes)
+	}
+	if m := motivos[reclamos[1].ID]; len(m) != 1 || m[0] != model.CoincideDocumento {
+		t.Errorf("motivos #1 = %v, want [DOCUMENTO]", m)
+	}
+	if m := motivos[reclamos[2].ID]; len(m) != 2 || m[0] != model.CoincideTelefono || m[1] != model.CoincidePedido {
+		t.Errorf("motivos #2 = %v, want [TELEFONO PEDIDO]", m)
+	}
+
+	// Duplicado probable detectado: 0 → 2; descartarlo desde el otro lado
+	probable := &model.ReclamoRelacion{
+		TenantModel:   model.TenantModel{TenantID: tenantID},
+		ReclamoID:     reclamos[0].ID,
+		RelacionadoID: reclamos[2].ID,
+		Motivos:       motivos[reclamos[2].ID],
+		Puntaje:       model.PuntajeDuplicado(motivos[reclamos[2].ID]),
+		Estado:        model.RelacionProbable,
+	}
+	if err := relacionRepo.Create(ctx, probable); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := relacionRepo.Create(ctx, probable); err != nil {
+		t.Fatalf("Create repetido: %v", err)
+	}
+	ok, err := relacionRepo.Descartar(ctx, tenantID, reclamos[2].ID, reclamos[0].ID, userID)
+	if err != nil || !ok {
+		t.Fatalf("Descartar = %v, %v", ok, err)
+	}
+	if ok, _ := relacionRepo.Descartar(ctx, tenantID, reclamos[0].ID, reclamos[2].ID, userID); ok {
+		t.Error("Descartar dos veces = true")
+	}
+
+	// Fusión manual sin detección previa: 1 se atiende en 0
+	if err := relacionRepo.Resolver(ctx, tenantID, reclamos[1].ID, reclamos[0].ID, model.RelacionFusionada, userID); err != nil {
+		t.Fatalf("Resolver: %v", err)
+	}
+	principal, err := relacionRepo.FusionadoEn(ctx, tenantID, reclamos[1].ID)
+	if err != nil || principal == nil || *principal != reclamos[0].ID {
+		t.Fatalf("FusionadoEn = %v, %v, want %s", principal, err, reclamos[0].ID)
+	}
+	if p, _ := relacionRepo.FusionadoEn(ctx, tenantID, reclamos[0].ID); p != nil {
+		t.Errorf("FusionadoEn(principal) = %v, want nil", p)
+	}
+
+	relaciones, err := relacionRepo.ListarPorReclamo(ctx, tenantID, reclamos[0].ID)
+	if err != nil {
+		t.Fatalf("ListarPorReclamo: %v", err)
+	}
+	if len(relaciones) != 2 {
+		t.Fatalf("relaciones = %d, want 2", len(relaciones))
+	}
+	for _, rel := range relaciones {
+		switch rel.Otro.CodigoReclamo {
+		case reclamos[1].CodigoReclamo:
+			if rel.Estado != model.RelacionFusionada || len(rel.Motivos) != 1 || rel.Motivos[0] != model.CoincideManual {
+				t.Errorf("fusión = %+v", rel)
+			}
+		case reclamos[2].CodigoReclamo:
+			if rel.Estado != model.RelacionDescartada || !rel.ResueltoPor.Valid {
+				t.Errorf("descartado = %+v", rel)
+			}
+		default:
+			t.Errorf("relación inesperada con %s", rel.Otro.CodigoReclamo)
+		}
+	}
+}
@@ -1 +1,74 @@
 package integration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestRespuestaRepo_CasosSimilares(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	reclamoRepo := repo.NewReclamoRepo(testDB)
+	respuestaRepo := repo.NewRespuestaRepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM reclamos WHERE tenant_id = $1`, tenantID)
+	defer testDB.ExecContext(ctx, `DELETE FROM respuestas WHERE tenant_id = $1`, tenantID)
+
+	detalles := []string{
+		"La refrigeradora llegó con la puerta abollada",     // el que se responde
+		"Mi refrigeradora vino con la puerta rayada",        // parecido, respondido
+		"La lavadora no centrifuga desde la primera semana", // distinto, respondido
+		"Refrigeradora con la puerta rota en la entrega",    // parecido, sin respuesta
+	}
+	reclamos := make([]*model.Reclamo, len(detalles))
+	for i, d := range detalles {
+		rec := nuevoReclamoCadenaTest(tenantID, i)
+		rec.DetalleReclamo = d
+		if err := reclamoRepo.Create(ctx, rec); err != nil {
+			t.Fatalf("Create #%d: %v", i, err)
+		}
+		reclamos[i] = rec
+	}
+	for _, i := range []int{1, 2} {
+		resp := &model.Respuesta{
+			TenantModel:      model.TenantModel{TenantID: tenantID},
+			ReclamoID:        reclamos[i].ID,
+			RespuestaEmpresa: "Lamentamos lo ocurrido; cambiamos el producto.",
+			AccionTomada:     model.NullString{NullString: sql.NullString{String: "Cambio de producto", Valid: true}},
+			Origen:           model.OrigenPanel,
+		}
+		if err := respuestaRepo.Create(ctx, resp); err != nil {
+			t.Fatalf("Create respuesta #%d: %v", i, err)
+		}
+	}
+
+	casos, err := respuestaRepo.CasosSimilares(ctx, tenantID, reclamos[0].ID, nil, "refrigeradora | puerta | abollada", 5)
+	if err != nil {
+		t.Fatalf("CasosSimilares: %v", err)
+	}
+	if len(casos) != 1 || casos[0].CodigoReclamo != reclamos[1].CodigoReclamo {
+		t.Fatalf("CasosSimilares = %+v, want solo %s", casos, reclamos[1].CodigoReclamo)
+	}
+	if casos[0].AccionTomada.String != "Cambio de producto" {
+		t.Errorf("AccionTomada = %q", casos[0].AccionTomada.String)
+	}
+
+	// Otra sede: sin resultados
+	otraSede := uuid.New()
+	casos, err = respuestaRepo.CasosSimilares(ctx, tenantID, reclamos[0].ID, &otraSede, "refrigeradora", 5)
+	if err != nil {
+		t.Fatalf("CasosSimilares sede: %v", err)
+	}
+	if len(casos) != 0 {
+		t.Errorf("CasosSimilares otra sede = %d casos, want 0", len(casos))
+	}
+}
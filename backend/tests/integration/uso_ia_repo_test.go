@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+func TestUsoIARepo_RegistrarYAgrupar(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	usoIARepo := repo.NewUsoIARepo(testDB)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	defer testDB.ExecContext(ctx, `DELETE FROM uso_ia WHERE tenant_id = $1`, tenantID)
+
+	llamadas := []model.UsoIA{
+		{Origen: model.OrigenIAAsistente, Proveedor: "anthropic", TokensPrompt: 100, TokensOutput: 50},
+		{Origen: model.OrigenIAAsistente, Proveedor: "anthropic", TokensPrompt: 200, TokensOutput: 20},
+		{Origen: model.OrigenIAWhatsApp, Proveedor: "ollama/llama3", TokensPrompt: 30, TokensOutput: 10},
+	}
+	for i := range llamadas {
+		llamadas[i].TenantID = tenantID
+		if err := usoIARepo.Registrar(ctx, &llamadas[i]); err != nil {
+			t.Fatalf("Registrar #%d: %v", i, err)
+		}
+		if llamadas[i].ID == uuid.Nil || llamadas[i].Fecha.IsZero() {
+			t.Fatalf("Registrar #%d no retornó id/fecha", i)
+		}
+	}
+
+	desde := time.Now().Add(-time.Hour)
+	hasta := time.Now().Add(time.Hour)
+
+	porOrigen, err := usoIARepo.PorOrigen(ctx, tenantID, desde, hasta)
+	if err != nil {
+		t.Fatalf("PorOrigen: %v", err)
+	}
+	if len(porOrigen) != 2 {
+		t.Fatalf("PorOrigen = %+v, want 2 grupos", porOrigen)
+	}
+	asistente := porOrigen[0] // ORDER BY clave: ASISTENTE < WHATSAPP
+	if asistente.Clave != model.OrigenIAAsistente || asistente.Llamadas != 2 ||
+		asistente.TokensPrompt != 300 || asistente.TokensOutput != 70 {
+		t.Errorf("grupo ASISTENTE = %+v", asistente)
+	}
+
+	porDia, err := usoIARepo.PorDia(ctx, tenantID, desde, hasta)
+	if err != nil {
+		t.Fatalf("PorDia: %v", err)
+	}
+	total := 0
+	for _, g := range porDia {
+		total += g.Llamadas
+	}
+	if total != 3 {
+		t.Errorf("PorDia suma %d llamadas, want 3", total)
+	}
+
+	// Fuera del rango no cuenta
+	antes, err := usoIARepo.PorProveedor(ctx, tenantID, desde.Add(-48*time.Hour), desde)
+	if err != nil {
+		t.Fatalf("PorProveedor: %v", err)
+	}
+	if len(antes) != 0 {
+		t.Errorf("PorProveedor fuera de rango = %+v, want vacío", antes)
+	}
+}
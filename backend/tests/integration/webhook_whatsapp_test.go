@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"libro-reclamaciones/internal/config"
+	"libro-reclamaciones/internal/controller"
+	"libro-reclamaciones/internal/helper"
+	"libro-reclamaciones/internal/model"
+	"libro-reclamaciones/internal/repo"
+	"libro-reclamaciones/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// metaFalso envía webhooks como Meta: POST con el body firmado en X-Hub-Signature-256.
+type metaFalso struct {
+	servidor *httptest.Server
+}
+
+func (m metaFalso) enviar(t *testing.T, cuerpo []byte, firma string) int {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, m.servidor.URL+"/webhook/whatsapp", bytes.NewReader(cuerpo))
+	req.Header.Set("Content-Type", "application/json")
+	if firma != "" {
+		req.Header.Set(service.CabeceraFirmaMeta, firma)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST webhook: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestWebhookWhatsApp_FirmaMeta(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const secreto = "secreto-app-meta"
+
+	webhookService := service.NewWebhookWhatsAppService(secreto, nil, service.NewMensajesProcesadosStoreMemoria(time.Hour))
+	ctrl := controller.NewWhatsAppController(config.WhatsAppConfig{AppSecret: secreto}, nil, webhookService, nil, nil)
+	r := gin.New()
+	r.POST("/webhook/whatsapp", ctrl.RecibirMensajeEntrante)
+	meta := metaFalso{servidor: httptest.NewServer(r)}
+	defer meta.servidor.Close()
+
+	// Solo estados de entrega: no hay mensajes que responder
+	cuerpo := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{"messaging_product":"whatsapp","statuses":[{"id":"wamid.1","status":"read"}]}}]}]}`)
+	alterado := bytes.Replace(cuerpo, []byte(`"read"`), []byte(`"sent"`), 1)
+
+	casos := []struct {
+		nombre string
+		cuerpo []byte
+		firma  string
+		want   int
+	}{
+		{"firmado por Meta", cuerpo, helper.FirmarWebhookMeta(cuerpo, secreto), http.StatusOK},
+		{"sin firma", cuerpo, "", http.StatusUnauthorized},
+		{"otro secreto", cuerpo, helper.FirmarWebhookMeta(cuerpo, "otro-secreto"), http.StatusUnauthorized},
+		{"body alterado", alterado, helper.FirmarWebhookMeta(cuerpo, secreto), http.StatusUnauthorized},
+		{"sin prefijo sha256=", cuerpo, helper.FirmarWebhookMeta(cuerpo, secreto)[len(helper.PrefijoFirmaMeta):], http.StatusUnauthorized},
+	}
+	for _, c := range casos {
+		if got := meta.enviar(t, c.cuerpo, c.firma); got != c.want {
+			t.Errorf("%s: status = %d, want %d", c.nombre, got, c.want)
+		}
+	}
+
+	m := webhookService.Metricas()
+	if m.Aceptados != 1 || m.FirmaAusente != 1 || m.FirmaInvalida != 3 {
+		t.Errorf("Metricas = %+v, want aceptados=1 firma_ausente=1 firma_invalida=3", m)
+	}
+}
+
+func TestWebhookWhatsApp_SinSecretoRechaza(t *testing.T) {
+	webhookService := service.NewWebhookWhatsAppService("", nil, service.NewMensajesProcesadosStoreMemoria(time.Hour))
+	cuerpo := []byte(`{"object":"whatsapp_business_account","entry":[]}`)
+
+	// Sin secreto no hay firma que valide, ni siquiera la de un secreto vacío
+	if err := webhookService.VerificarFirma(context.Background(), cuerpo, helper.FirmarWebhookMeta(cuerpo, ""), nil); err == nil {
+		t.Fatal("VerificarFirma sin secreto = nil, want error")
+	}
+	if m := webhookService.Metricas(); m.SinSecreto != 1 {
+		t.Errorf("SinSecreto = %d, want 1", m.SinSecreto)
+	}
+}
+
+func TestWebhookWhatsApp_MensajeDuplicado(t *testing.T) {
+	webhookService := service.NewWebhookWhatsAppService("s", nil, service.NewMensajesProcesadosStoreMemoria(time.Hour))
+	ctx := context.Background()
+	canal := &service.CanalResuelto{TenantID: uuid.New(), PhoneID: "123"}
+
+	for i, want := range []bool{true, false, false} {
+		nuevo, err := webhookService.RegistrarMensaje(ctx, canal, "wamid.ABC")
+		if err != nil || nuevo != want {
+			t.Fatalf("entrega %d: RegistrarMensaje = %v, %v, want %v", i+1, nuevo, err, want)
+		}
+	}
+	if nuevo, _ := webhookService.RegistrarMensaje(ctx, canal, "wamid.DEF"); !nuevo {
+		t.Error("otro wamid = duplicado")
+	}
+	if m := webhookService.Metricas(); m.Duplicados != 2 {
+		t.Errorf("Duplicados = %d, want 2", m.Duplicados)
+	}
+}
+
+func TestWebhookWhatsApp_SecretoPorCanal(t *testing.T) {
+	if testDB == nil {
+		t.Skip("DB no disponible")
+	}
+
+	canalRepo := repo.NewCanalWhatsAppRepo(testDB, nil)
+	ctx := context.Background()
+	tenantID := uuid.New()
+	phoneNumberID := "test-" + uuid.NewString()[:8]
+	defer testDB.ExecContext(ctx, `DELETE FROM canales_whatsapp WHERE tenant_id = $1`, tenantID)
+	defer testDB.ExecContext(ctx, `DELETE FROM mensajes_whatsapp_procesados WHERE tenant_id = $1`, tenantID)
+
+	canal := &model.CanalWhatsApp{
+		TenantModel:   model.TenantModel{TenantID: tenantID},
+		PhoneNumberID: phoneNumberID,
+		AccessToken:   "token",
+		AppSecret:     "secreto-del-canal",
+		NombreCanal:   "Canal de otra app",
+	}
+	if err := canalRepo.Create(ctx, canal); err != nil {
+		t.Fatalf("Create canal: %v", err)
+	}
+
+	webhookService := service.NewWebhookWhatsAppService("secreto-global", canalRepo, repo.NewMensajeWhatsAppProcesadoRepo(testDB))
+	cuerpo := []byte(`{"entry":[{"changes":[{"value":{"metadata":{"phone_number_id":"` + phoneNumberID + `"}}}]}]}`)
+	ids := []string{phoneNumberID}
+
+	if err := webhookService.VerificarFirma(ctx, cuerpo, helper.FirmarWebhookMeta(cuerpo, "secreto-del-canal"), ids); err != nil {
+		t.Errorf("firma con el secreto del canal = %v", err)
+	}
+	if err := webhookService.VerificarFirma(ctx, cuerpo, helper.FirmarWebhookMeta(cuerpo, "secreto-global"), ids); err != nil {
+		t.Errorf("firma con el secreto global = %v", err)
+	}
+	if err := webhookService.VerificarFirma(ctx, cuerpo, helper.FirmarWebhookMeta(cuerpo, "otro"), ids); err == nil {
+		t.Error("firma con otro secreto = nil")
+	}
+
+	// Idempotencia en BD: el reintento de Meta no se procesa
+	resuelto := &service.CanalResuelto{TenantID: tenantID, PhoneID: phoneNumberID}
+	for i, want := range []bool{true, false} {
+		if nuevo, err := webhookService.RegistrarMensaje(ctx, resuelto, "wamid."+phoneNumberID); err != nil || nuevo != want {
+			t.Fatalf("entrega %d: RegistrarMensaje = %v, %v, want %v", i+1, nuevo, err, want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"libro-reclamaciones/internal/ai"
+	"libro-reclamaciones/internal/service"
+)
+
+func TestMensajeSaliente_Botones(t *testing.T) {
+	msg, err := service.MensajeBotones("¿Qué deseas registrar?",
+		service.OpcionWhatsApp{ID: "tipo_solicitud:RECLAMO", Titulo: "Reclamo"},
+		service.OpcionWhatsApp{ID: "tipo_solicitud:QUEJA", Titulo: "Queja"},
+	)
+	if err != nil {
+		t.Fatalf("MensajeBotones: %v", err)
+	}
+
+	data, _ := json.Marshal(msg.Payload("51987654321"))
+	var payload struct {
+		To          string `json:"to"`
+		Type        string `json:"type"`
+		Interactive struct {
+			Type   string `json:"type"`
+			Body   struct{ Text string }
+			Action struct {
+				Buttons []struct {
+					Type  string `json:"type"`
+					Reply struct{ ID, Title string }
+				} `json:"buttons"`
+			} `json:"action"`
+		} `json:"interactive"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.To != "51987654321" || payload.Type != "interactive" || payload.Interactive.Type != "button" {
+		t.Fatalf("payload = %s", data)
+	}
+	if b := payload.Interactive.Action.Buttons; len(b) != 2 || b[1].Type != "reply" || b[1].Reply.ID != "tipo_solicitud:QUEJA" || b[1].Reply.Title != "Queja" {
+		t.Errorf("botones = %+v", b)
+	}
+	if got := msg.TextoPlano(); !strings.Contains(got, "Reclamo | Queja") {
+		t.Errorf("TextoPlano = %q, want las opciones listadas", got)
+	}
+
+	// Límites de la Cloud API
+	opcion := func(id, titulo string) service.OpcionWhatsApp { return service.OpcionWhatsApp{ID: id, Titulo: titulo} }
+	invalidos := map[string][]service.OpcionWhatsApp{
+		"sin botones":    nil,
+		"cuatro botones": {opcion("a", "A"), opcion("b", "B"), opcion("c", "C"), opcion("d", "D")},
+		"título de 21":   {opcion("a", strings.Repeat("x", 21))},
+		"id repetido":    {opcion("a", "A"), opcion("a", "B")},
+		"título vacío":   {opcion("a", "")},
+	}
+	for nombre, opciones := range invalidos {
+		if _, err := service.MensajeBotones("texto", opciones...); err == nil {
+			t.Errorf("MensajeBotones(%s) sin error", nombre)
+		}
+	}
+	if _, err := service.MensajeBotones("", opcion("a", "A")); err == nil {
+		t.Error("MensajeBotones sin texto no dio error")
+	}
+}
+
+func TestMensajeSaliente_Lista(t *testing.T) {
+	filas := make([]service.OpcionWhatsApp, service.MaxFilasListaWhatsApp)
+	for i := range filas {
+		filas[i] = service.OpcionWhatsApp{ID: "sede:" + string(rune('a'+i)), Titulo: "Sede " + string(rune('A'+i)), Descripcion: "Av. Principal 123"}
+	}
+
+	msg, err := service.MensajeLista("¿En cuál de nuestras sedes ocurrió?", "Ver sedes", filas...)
+	if err != nil {
+		t.Fatalf("MensajeLista: %v", err)
+	}
+	data, _ := json.Marshal(msg.Payload("51987654321"))
+	var payload struct {
+		Interactive struct {
+			Type   string `json:"type"`
+			Action struct {
+				Button   string `json:"button"`
+				Sections []struct {
+					Rows []struct{ ID, Title, Description string } `json:"rows"`
+				} `json:"sections"`
+			} `json:"action"`
+		} `json:"interactive"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	a := payload.Interactive.Action
+	if payload.Interactive.Type != "list" || a.Button != "Ver sedes" || len(a.Sections) != 1 || len(a.Sections[0].Rows) != 10 {
+		t.Fatalf("payload = %s", data)
+	}
+	if fila := a.Sections[0].Rows[0]; fila.ID != "sede:a" || fila.Description != "Av. Principal 123" {
+		t.Errorf("fila = %+v", fila)
+	}
+
+	if _, err := service.MensajeLista("texto", "Ver sedes", append(filas, filas[0])...); err == nil {
+		t.Error("MensajeLista con 11 filas sin error")
+	}
+	if _, err := service.MensajeLista("texto", "", filas[0]); err == nil {
+		t.Error("MensajeLista sin botón sin error")
+	}
+	larga := filas[0]
+	larga.Descripcion = strings.Repeat("x", service.MaxDescripcionFilaWhatsApp+1)
+	if _, err := service.MensajeLista("texto", "Ver sedes", larga); err == nil {
+		t.Error("MensajeLista con descripción larga sin error")
+	}
+
+	// El texto simple conserva el payload de siempre
+	data, _ = json.Marshal(service.MensajeTexto("hola").Payload("51987654321"))
+	if !strings.Contains(string(data), `"text":{"body":"hola"}`) || !strings.Contains(string(data), `"type":"text"`) {
+		t.Errorf("payload texto = %s", data)
+	}
+}
+
+// TestTranscriptor_OpenAI transcribe contra un servidor compatible con Whisper.
+func TestTranscriptor_OpenAI(t *testing.T) {
+	var (
+		archivo, modelo, auth string
+		audio                 []byte
+	)
+	servidor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/transcriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		modelo = r.FormValue("model")
+		f, fh, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		archivo = fh.Filename
+		audio, _ = io.ReadAll(f)
+		if string(audio) == "ruido" {
+			http.Error(w, `{"error":"audio inválido"}`, http.StatusBadRequest)
+			return
+		}
+		if string(audio) == "whisper-1" {
+			w.Write([]byte(`{"text":"Hola","usage":{"type":"duration","seconds":12}}`))
+			return
+		}
+		w.Write([]byte(`{"text":"  Quiero registrar un reclamo por mi pedido.  "}`))
+	}))
+	defer servidor.Close()
+
+	tr, err := ai.NewTranscriptor(ai.GatewayConfig{Provider: "openai", APIKey: "sk-test", Model: "whisper-large-v3", BaseURL: servidor.URL + "/v1/"})
+	if err != nil {
+		t.Fatalf("NewTranscriptor: %v", err)
+	}
+
+	resp, err := tr.Transcribir(context.Background(), []byte("OggS..."), "audio/ogg; codecs=opus")
+	if err != nil {
+		t.Fatalf("Transcribir: %v", err)
+	}
+	if resp.Content != "Quiero registrar un reclamo por mi pedido." {
+		t.Errorf("texto = %q", resp.Content)
+	}
+	// Sin usage en la respuesta el consumo se estima; nunca queda en cero
+	if resp.PromptTokens == 0 || resp.OutputTokens == 0 || resp.Provider != tr.Name() {
+		t.Errorf("uso estimado = %+v", resp)
+	}
+	if auth != "Bearer sk-test" || modelo != "whisper-large-v3" || archivo != "audio.ogg" || string(audio) != "OggS..." {
+		t.Errorf("request: auth=%q modelo=%q archivo=%q audio=%q", auth, modelo, archivo, audio)
+	}
+	if tr.Name() != "openai-compatible/whisper-large-v3" {
+		t.Errorf("Name = %q", tr.Name())
+	}
+
+	if _, err := tr.Transcribir(context.Background(), []byte("ruido"), "audio/mpeg"); err == nil {
+		t.Error("Transcribir con HTTP 400 sin error")
+	}
+	if archivo != "audio.mp3" {
+		t.Errorf("archivo = %q, want audio.mp3", archivo)
+	}
+
+	// Proveedor que cobra por duración: segundos convertidos a tokens
+	if resp, err := tr.Transcribir(context.Background(), []byte("whisper-1"), "audio/ogg"); err != nil || resp.PromptTokens != 120 {
+		t.Errorf("uso por duración = %+v, %v (want 120 tokens de prompt)", resp, err)
+	}
+
+	if _, err := ai.NewTranscriptor(ai.GatewayConfig{Provider: "openai"}); err == nil {
+		t.Error("NewTranscriptor sin API key sin error")
+	}
+	if _, err := ai.NewTranscriptor(ai.GatewayConfig{Provider: "anthropic", APIKey: "x"}); err == nil {
+		t.Error("NewTranscriptor con proveedor sin STT sin error")
+	}
+}